@@ -7,8 +7,10 @@ import (
 	"github.com/adohong4/driving-license/config"
 	"github.com/adohong4/driving-license/internal/server"
 	"github.com/adohong4/driving-license/pkg/db/postgres"
+	"github.com/adohong4/driving-license/pkg/db/redis"
 	"github.com/adohong4/driving-license/pkg/logger"
 	"github.com/adohong4/driving-license/pkg/utils"
+	goredis "github.com/redis/go-redis/v9"
 )
 
 // @title Traffic License REST API
@@ -50,8 +52,16 @@ func main() {
 	defer psqlDB.Close()
 	appLogger.Infof("Postgres connected, Status: %#v", psqlDB.Stats())
 
+	// Connect Redis, only used as stats cache when configured
+	var redisClient *goredis.Client
+	if cfg.Stats.CacheDriver == "redis" {
+		redisClient = redis.NewRedisClient(cfg)
+		defer redisClient.Close()
+		appLogger.Info("Redis connected")
+	}
+
 	// Run Server
-	s := server.NewServer(cfg, psqlDB, appLogger)
+	s := server.NewServer(cfg, psqlDB, redisClient, appLogger)
 	if err := s.Run(); err != nil {
 		log.Fatalf("Server run: %v", err)
 	}
@@ -0,0 +1,219 @@
+// Command plates re-normalizes the plate numbers of vehicle documents, traffic violations, ownerships and transfers
+// to the canonical form of pkg/plate and writes a CSV report of the changed, conflicting and invalid rows.
+//
+//	go run ./cmd/plates            dry run, report only
+//	go run ./cmd/plates -apply     update the rows
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/adohong4/driving-license/config"
+	anchorRepository "github.com/adohong4/driving-license/internal/anchor/repository"
+	"github.com/adohong4/driving-license/internal/models"
+	"github.com/adohong4/driving-license/pkg/db/postgres"
+	"github.com/adohong4/driving-license/pkg/plate"
+	"github.com/adohong4/driving-license/pkg/utils"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// Report statuses
+const (
+	statusUpdated  = "updated"
+	statusPending  = "pending"  // would be updated, dry run
+	statusConflict = "conflict" // active vehicle documents sharing the canonical plate, left as is
+	statusInvalid  = "invalid"  // not a plate pkg/plate can parse, left as is
+)
+
+// Plate columns, vehicle documents must stay unique among active rows.
+// Active is the SQL expression telling whether a row is active, tables without an active column count every row.
+// Anchor is the record type of rows anchored on the blockchain with their plate, a renamed anchored row is re-anchored.
+type plateColumn struct {
+	table  string
+	column string
+	active string
+	unique bool
+	anchor string
+}
+
+var tables = []plateColumn{
+	{table: "vehicle_registration", column: "vehicle_no", active: "active", unique: true, anchor: models.AnchorVehicle},
+	{table: "traffic_violations", column: "vehicle_no", active: "active"},
+	{table: "vehicle_ownerships", column: "vehicle_no", active: "true"},
+	{table: "vehicle_transfers", column: "vehicle_no", active: "true"},
+	{table: "vehicle_transfers", column: "new_vehicle_no", active: "true"},
+}
+
+// Name of the column in logs and the report
+func (c plateColumn) String() string {
+	if c.column == "vehicle_no" {
+		return c.table
+	}
+	return c.table + "." + c.column
+}
+
+type plateRow struct {
+	ID        uuid.UUID `db:"id"`
+	VehicleNo string    `db:"vehicle_no"`
+	Active    bool      `db:"active"`
+	canonical string
+	status    string
+}
+
+func main() {
+	apply := flag.Bool("apply", false, "update the rows, without it only the report is written")
+	out := flag.String("out", "", "report file, stdout by default")
+	flag.Parse()
+
+	configPath := utils.GetConfigPath(os.Getenv("config"))
+
+	cfgFile, err := config.LoadConfig(configPath)
+	if err != nil {
+		log.Fatalf("LoadConfig: %v", err)
+	}
+
+	cfg, err := config.ParseConfig(cfgFile)
+	if err != nil {
+		log.Fatalf("ParseConfig: %v", err)
+	}
+
+	psqlDB, err := postgres.NewPsqlDB(cfg)
+	if err != nil {
+		log.Fatalf("Postgresql init: %v", err)
+	}
+	defer psqlDB.Close()
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			log.Fatalf("Create report: %v", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	report := csv.NewWriter(w)
+	if err = report.Write([]string{"table", "id", "old", "new", "status"}); err != nil {
+		log.Fatalf("Write report: %v", err)
+	}
+
+	ctx := context.Background()
+	for _, t := range tables {
+		rows, err := normalizeTable(ctx, psqlDB, t, *apply)
+		if err != nil {
+			log.Fatalf("%s: %v", t, err)
+		}
+
+		counts := make(map[string]int)
+		for _, r := range rows {
+			counts[r.status]++
+			if err = report.Write([]string{t.String(), r.ID.String(), r.VehicleNo, r.canonical, r.status}); err != nil {
+				log.Fatalf("Write report: %v", err)
+			}
+		}
+		log.Printf("%s: %d updated, %d pending, %d conflicts, %d invalid",
+			t, counts[statusUpdated], counts[statusPending], counts[statusConflict], counts[statusInvalid])
+	}
+
+	report.Flush()
+	if err = report.Error(); err != nil {
+		log.Fatalf("Write report: %v", err)
+	}
+}
+
+// Normalize the plates of a column in one transaction, returns the rows to report
+func normalizeTable(ctx context.Context, db *sqlx.DB, t plateColumn, apply bool) ([]*plateRow, error) {
+	var rows []*plateRow
+	query := fmt.Sprintf("SELECT id, %s AS vehicle_no, %s AS active FROM %s WHERE %s IS NOT NULL ORDER BY created_at, id",
+		t.column, t.active, t.table, t.column)
+	if err := db.SelectContext(ctx, &rows, query); err != nil {
+		return nil, errors.Wrap(err, "normalizeTable.Select")
+	}
+
+	// active rows by canonical plate, a plate held twice can not be merged by renaming
+	holders := make(map[string][]*plateRow)
+	var changed []*plateRow
+	for _, r := range rows {
+		canonical, err := plate.Canonical(r.VehicleNo)
+		if err != nil {
+			r.status = statusInvalid
+			changed = append(changed, r)
+			continue
+		}
+		r.canonical = canonical
+		if r.Active {
+			holders[canonical] = append(holders[canonical], r)
+		}
+		if canonical != r.VehicleNo {
+			changed = append(changed, r)
+		}
+	}
+	if t.unique {
+		for _, held := range holders {
+			if len(held) < 2 {
+				continue
+			}
+			for _, r := range held {
+				if r.status == "" && r.canonical == r.VehicleNo {
+					// the row already holding the canonical plate, reported alongside the conflicting ones
+					changed = append(changed, r)
+				}
+				r.status = statusConflict
+			}
+		}
+	}
+
+	status := statusPending
+	if apply {
+		status = statusUpdated
+	}
+	var updates []*plateRow
+	for _, r := range changed {
+		if r.status == "" {
+			r.status = status
+			updates = append(updates, r)
+		}
+	}
+	if !apply || len(updates) == 0 {
+		return changed, nil
+	}
+
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "normalizeTable.BeginTxx")
+	}
+	defer tx.Rollback()
+
+	query = fmt.Sprintf("UPDATE %s SET %s = $1 WHERE id = $2", t.table, t.column)
+	for _, r := range updates {
+		if t.anchor == "" {
+			if _, err = tx.ExecContext(ctx, query, r.canonical, r.ID); err != nil {
+				return nil, errors.Wrapf(err, "normalizeTable.Update %s", r.ID)
+			}
+			continue
+		}
+
+		var onBlockchain bool
+		if err = tx.GetContext(ctx, &onBlockchain, query+" RETURNING on_blockchain", r.canonical, r.ID); err != nil {
+			return nil, errors.Wrapf(err, "normalizeTable.Update %s", r.ID)
+		}
+		if onBlockchain {
+			if _, err = anchorRepository.QueueReanchorTx(ctx, tx, t.anchor, r.ID); err != nil {
+				return nil, errors.Wrapf(err, "normalizeTable.QueueReanchor %s", r.ID)
+			}
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "normalizeTable.Commit")
+	}
+	return changed, nil
+}
@@ -10,17 +10,20 @@ import (
 
 // App config struct
 type Config struct {
-	Server   ServerConfig
-	Postgres PostgresConfig
-	Redis    RedisConfig
-	MongoDB  MongoDB
-	Cookie   Cookie
-	Store    Store
-	Session  Session
-	Metrics  Metrics
-	Logger   Logger
-	AWS      AWS
-	Jaeger   Jaeger
+	Server       ServerConfig
+	Postgres     PostgresConfig
+	Redis        RedisConfig
+	MongoDB      MongoDB
+	Cookie       Cookie
+	Store        Store
+	Session      Session
+	Metrics      Metrics
+	Logger       Logger
+	AWS          AWS
+	Jaeger       Jaeger
+	Stats        Stats
+	Appointments Appointments
+	Blockchain   Blockchain
 }
 
 // Server config struct
@@ -100,7 +103,9 @@ type Metrics struct {
 
 // Store config
 type Store struct {
-	ImagesFolder string
+	ImagesFolder  string
+	ExportsFolder string
+	ImportsFolder string
 }
 
 // AWS S3
@@ -119,6 +124,42 @@ type Jaeger struct {
 	LogSpans    bool
 }
 
+// Statistics cache config
+type Stats struct {
+	CacheDriver         string // lru | redis
+	CacheSize           int
+	CacheTTL            int // seconds
+	RefreshInterval     int // seconds, refresh views touched by writes
+	FullRefreshInterval int // seconds, refresh every view
+}
+
+// Appointment reminders config
+type Appointments struct {
+	ReminderInterval int // seconds, how often due reminders are sent
+	ReminderLead     int // minutes, how long before the appointment the citizen is reminded
+}
+
+// Blockchain anchoring config
+type Blockchain struct {
+	Driver         string // evm, the chain the worker writes to. Empty disables anchoring
+	Confirmations  int    // blocks on top of the transaction before a record is confirmed
+	PollInterval   int    // seconds, how often the outbox is processed
+	BatchSize      int    // anchors submitted and checked per tick
+	MaxAttempts    int    // submissions before an anchor fails for good
+	RetryBaseDelay int    // seconds, doubled after each failed attempt
+	RetryMaxDelay  int    // seconds
+	ConfirmTimeout int    // seconds, a transaction not mined by then is sent again
+	ScanInterval   int    // seconds, how often anchored records are compared to their anchor
+
+	// Node the anchors are sent to and the transactions sent by clients are verified against, required with a Driver
+	RPCURL           string
+	RPCTimeout       int    // seconds
+	RegistryAddress  string // registry contract the anchors are written to
+	AnchorEventTopic string // topic of the anchor event, hex encoded
+	SenderAddress    string // account unlocked on the node the worker sends from
+	AnchorMethodID   string // 4 byte selector of the anchor method of the registry, hex encoded
+}
+
 // load config file from given path
 func LoadConfig(filename string) (*viper.Viper, error) {
 	v := viper.New()
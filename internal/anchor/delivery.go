@@ -0,0 +1,12 @@
+package anchor
+
+import "github.com/labstack/echo/v4"
+
+type Handlers interface {
+	GetAnchors() echo.HandlerFunc
+	GetAnchorByID() echo.HandlerFunc
+	RetryAnchor() echo.HandlerFunc
+	GetFlags() echo.HandlerFunc
+	ReanchorFlag() echo.HandlerFunc
+	VerifyRecord() echo.HandlerFunc
+}
@@ -0,0 +1,229 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/adohong4/driving-license/config"
+	"github.com/adohong4/driving-license/internal/anchor"
+	"github.com/adohong4/driving-license/pkg/httpErrors"
+	"github.com/adohong4/driving-license/pkg/logger"
+	"github.com/adohong4/driving-license/pkg/utils"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type anchorHandlers struct {
+	cfg      *config.Config
+	anchorUC anchor.UseCase
+	logger   logger.Logger
+}
+
+func NewAnchorHandlers(cfg *config.Config, anchorUC anchor.UseCase, logger logger.Logger) anchor.Handlers {
+	return &anchorHandlers{cfg: cfg, anchorUC: anchorUC, logger: logger}
+}
+
+// GetAnchors godoc
+// @Summary      List blockchain anchors
+// @Description  Admin only. Records queued for the chain by the anchoring worker, filterable by record_type, record_id, status, tx_hash, attempts and dates
+// @Tags         anchor
+// @Produce      json
+// @Param        page        query     int     false  "Page number (default: 1)"
+// @Param        size        query     int     false  "Page size (default: 10)"
+// @Param        sort        query     string  false  "Sort fields, comma separated, - prefix for descending, e.g. -created_at"
+// @Param        status      query     string  false  "pending, submitted, confirmed, failed or skipped"
+// @Param        cursor      query     string  false  "Cursor of the next page, empty for the first one, switches to cursor pagination"
+// @Param        with_total  query     bool    false  "Return an approximate total_count on cursor pages"
+// @Success      200         {object}  models.BlockchainAnchorList
+// @Failure      400         {object}  httpErrors.Problem
+// @Failure      401         {object}  httpErrors.Problem
+// @Failure      403         {object}  httpErrors.Problem
+// @Failure      500         {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /anchors [get]
+func (h *anchorHandlers) GetAnchors() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		pq, err := utils.GetPaginationFromCtx(c)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		list, err := h.anchorUC.GetAnchors(ctx, pq)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, list)
+	}
+}
+
+// GetAnchorByID godoc
+// @Summary      Get a blockchain anchor
+// @Description  Admin only. Status, transaction and last error of an anchor
+// @Tags         anchor
+// @Produce      json
+// @Param        id   path      string  true  "Anchor ID (UUID)"
+// @Success      200  {object}  models.BlockchainAnchor
+// @Failure      400  {object}  httpErrors.Problem
+// @Failure      401  {object}  httpErrors.Problem
+// @Failure      403  {object}  httpErrors.Problem
+// @Failure      404  {object}  httpErrors.Problem
+// @Failure      500  {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /anchors/{id} [get]
+func (h *anchorHandlers) GetAnchorByID() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		a, err := h.anchorUC.GetAnchorByID(ctx, id)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, a)
+	}
+}
+
+// RetryAnchor godoc
+// @Summary      Retry a failed blockchain anchor
+// @Description  Admin only. Queues a failed anchor again with a fresh attempt budget
+// @Tags         anchor
+// @Produce      json
+// @Param        id   path      string  true  "Anchor ID (UUID)"
+// @Success      200  {object}  models.BlockchainAnchor
+// @Failure      400  {object}  httpErrors.Problem
+// @Failure      401  {object}  httpErrors.Problem
+// @Failure      403  {object}  httpErrors.Problem
+// @Failure      404  {object}  httpErrors.Problem
+// @Failure      409  {object}  httpErrors.Problem
+// @Failure      500  {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /anchors/{id}/retry [post]
+func (h *anchorHandlers) RetryAnchor() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		a, err := h.anchorUC.RetryAnchor(ctx, id)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, a)
+	}
+}
+
+// GetFlags godoc
+// @Summary      List integrity flags
+// @Description  Admin only. Records whose canonical hash no longer matches the hash anchored on the chain, found by the integrity scanner. Filterable by record_type, record_id, status and dates
+// @Tags         anchor
+// @Produce      json
+// @Param        page        query     int     false  "Page number (default: 1)"
+// @Param        size        query     int     false  "Page size (default: 10)"
+// @Param        sort        query     string  false  "Sort fields, comma separated, - prefix for descending, e.g. -detected_at"
+// @Param        status      query     string  false  "open or resolved"
+// @Param        cursor      query     string  false  "Cursor of the next page, empty for the first one, switches to cursor pagination"
+// @Param        with_total  query     bool    false  "Return an approximate total_count on cursor pages"
+// @Success      200         {object}  models.IntegrityFlagList
+// @Failure      400         {object}  httpErrors.Problem
+// @Failure      401         {object}  httpErrors.Problem
+// @Failure      403         {object}  httpErrors.Problem
+// @Failure      500         {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /anchors/flags [get]
+func (h *anchorHandlers) GetFlags() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		pq, err := utils.GetPaginationFromCtx(c)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		list, err := h.anchorUC.GetFlags(ctx, pq)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, list)
+	}
+}
+
+// ReanchorFlag godoc
+// @Summary      Re-anchor a flagged record
+// @Description  Admin only. Accepts the change of a flagged record and queues it to be written to the chain again, the flag is resolved once the new anchor is confirmed
+// @Tags         anchor
+// @Produce      json
+// @Param        id   path      string  true  "Flag ID (UUID)"
+// @Success      202  {object}  models.BlockchainAnchor
+// @Failure      400  {object}  httpErrors.Problem
+// @Failure      401  {object}  httpErrors.Problem
+// @Failure      403  {object}  httpErrors.Problem
+// @Failure      404  {object}  httpErrors.Problem
+// @Failure      409  {object}  httpErrors.Problem
+// @Failure      500  {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /anchors/flags/{id}/reanchor [post]
+func (h *anchorHandlers) ReanchorFlag() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		a, err := h.anchorUC.ReanchorFlag(ctx, id)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusAccepted, a)
+	}
+}
+
+// VerifyRecord godoc
+// @Summary      Verify a record against the chain
+// @Description  Public. Canonical hash of the record as stored, hash of its last confirmed anchor and whether they match. Only hashes are returned
+// @Tags         anchor
+// @Produce      json
+// @Param        record_type  path      string  true  "license or vehicle"
+// @Param        id           path      string  true  "Record ID (UUID)"
+// @Success      200          {object}  models.RecordVerification
+// @Failure      400          {object}  httpErrors.Problem
+// @Failure      404          {object}  httpErrors.Problem
+// @Failure      500          {object}  httpErrors.Problem
+// @Router       /verify/{record_type}/{id} [get]
+func (h *anchorHandlers) VerifyRecord() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		v, err := h.anchorUC.VerifyRecord(ctx, c.Param("record_type"), id)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, v)
+	}
+}
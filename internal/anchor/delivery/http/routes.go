@@ -0,0 +1,24 @@
+package http
+
+import (
+	"github.com/adohong4/driving-license/config"
+	"github.com/adohong4/driving-license/internal/anchor"
+	"github.com/adohong4/driving-license/internal/auth"
+	"github.com/adohong4/driving-license/internal/middleware"
+	"github.com/labstack/echo/v4"
+)
+
+var adminRoles = []string{"admin"}
+
+func MapAnchorRoutes(anchorGroup *echo.Group, h anchor.Handlers, mw *middleware.MiddlewareManager, cfg *config.Config, authUC auth.UseCase) {
+	anchorGroup.GET("", h.GetAnchors(), mw.AuthJWTMiddleware(authUC, cfg), mw.RoleBasedAuthMiddleware(adminRoles))
+	anchorGroup.GET("/flags", h.GetFlags(), mw.AuthJWTMiddleware(authUC, cfg), mw.RoleBasedAuthMiddleware(adminRoles))
+	anchorGroup.POST("/flags/:id/reanchor", h.ReanchorFlag(), mw.AuthJWTMiddleware(authUC, cfg), mw.RoleBasedAuthMiddleware(adminRoles))
+	anchorGroup.GET("/:id", h.GetAnchorByID(), mw.AuthJWTMiddleware(authUC, cfg), mw.RoleBasedAuthMiddleware(adminRoles))
+	anchorGroup.POST("/:id/retry", h.RetryAnchor(), mw.AuthJWTMiddleware(authUC, cfg), mw.RoleBasedAuthMiddleware(adminRoles))
+}
+
+// Public tamper evidence of records
+func MapVerifyRoutes(verifyGroup *echo.Group, h anchor.Handlers) {
+	verifyGroup.GET("/:record_type/:id", h.VerifyRecord())
+}
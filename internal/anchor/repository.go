@@ -0,0 +1,35 @@
+package anchor
+
+import (
+	"context"
+	"time"
+
+	"github.com/adohong4/driving-license/internal/models"
+	"github.com/adohong4/driving-license/pkg/chain"
+	"github.com/adohong4/driving-license/pkg/utils"
+	"github.com/google/uuid"
+)
+
+type Repository interface {
+	EnqueueRecords(ctx context.Context, limit int) (int, error)
+	ClaimDue(ctx context.Context, status string, limit int, lease time.Duration) ([]*models.BlockchainAnchor, error)
+	GetLicense(ctx context.Context, id uuid.UUID) (*models.DrivingLicense, error)
+	GetVehicle(ctx context.Context, id uuid.UUID) (*models.VehicleRegistration, error)
+	MarkSubmitted(ctx context.Context, id uuid.UUID, txHash, recordHash string, hashVersion int, next time.Time) error
+	MarkWaiting(ctx context.Context, id uuid.UUID, confirmations int, next time.Time) error
+	UpdateStatus(ctx context.Context, id uuid.UUID, status string, next time.Time, lastError string) error
+	Confirm(ctx context.Context, a *models.BlockchainAnchor, r *chain.Receipt) error
+
+	GetAnchors(ctx context.Context, pq *utils.PaginationQuery) (*models.BlockchainAnchorList, error)
+	GetAnchorByID(ctx context.Context, id uuid.UUID) (*models.BlockchainAnchor, error)
+	Retry(ctx context.Context, id uuid.UUID) (*models.BlockchainAnchor, error)
+	GetLatestConfirmed(ctx context.Context, recordType string, recordID uuid.UUID) (*models.BlockchainAnchor, error)
+	GetConfirmedAfter(ctx context.Context, recordType string, recordID uuid.UUID, limit int) ([]*models.BlockchainAnchor, error)
+
+	FlagRecord(ctx context.Context, a *models.BlockchainAnchor, currentHash string) error
+	ResolveFlag(ctx context.Context, recordType string, recordID uuid.UUID) (bool, error)
+	GetFlags(ctx context.Context, pq *utils.PaginationQuery) (*models.IntegrityFlagList, error)
+	GetFlagByID(ctx context.Context, id uuid.UUID) (*models.IntegrityFlag, error)
+	Reanchor(ctx context.Context, flagID uuid.UUID) (*models.BlockchainAnchor, error)
+	QueueReanchor(ctx context.Context, recordType string, recordID uuid.UUID) (bool, error)
+}
@@ -0,0 +1,276 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/adohong4/driving-license/internal/anchor"
+	"github.com/adohong4/driving-license/internal/models"
+	"github.com/adohong4/driving-license/pkg/chain"
+	"github.com/adohong4/driving-license/pkg/utils"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+type anchorRepo struct {
+	db *sqlx.DB
+}
+
+func NewAnchorRepo(db *sqlx.DB) anchor.Repository {
+	return &anchorRepo{db: db}
+}
+
+// Queue up to limit records of each type, returns the number queued
+func (r *anchorRepo) EnqueueRecords(ctx context.Context, limit int) (int, error) {
+	res, err := r.db.ExecContext(ctx, enqueueRecordsQuery, limit)
+	if err != nil {
+		return 0, errors.Wrap(err, "anchorRepo.EnqueueRecords.ExecContext")
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "anchorRepo.EnqueueRecords.RowsAffected")
+	}
+	return int(n), nil
+}
+
+func (r *anchorRepo) ClaimDue(ctx context.Context, status string, limit int, lease time.Duration) ([]*models.BlockchainAnchor, error) {
+	anchors := []*models.BlockchainAnchor{}
+	if err := r.db.SelectContext(ctx, &anchors, claimDueAnchorsQuery, status, limit, lease.Seconds()); err != nil {
+		return nil, errors.Wrap(err, "anchorRepo.ClaimDue.SelectContext")
+	}
+	return anchors, nil
+}
+
+func (r *anchorRepo) GetLicense(ctx context.Context, id uuid.UUID) (*models.DrivingLicense, error) {
+	dl := &models.DrivingLicense{}
+	if err := r.db.GetContext(ctx, dl, getLicenseQuery, id); err != nil {
+		return nil, errors.Wrap(err, "anchorRepo.GetLicense.GetContext")
+	}
+	return dl, nil
+}
+
+func (r *anchorRepo) GetVehicle(ctx context.Context, id uuid.UUID) (*models.VehicleRegistration, error) {
+	v := &models.VehicleRegistration{}
+	if err := r.db.GetContext(ctx, v, getVehicleQuery, id); err != nil {
+		return nil, errors.Wrap(err, "anchorRepo.GetVehicle.GetContext")
+	}
+	return v, nil
+}
+
+func (r *anchorRepo) MarkSubmitted(ctx context.Context, id uuid.UUID, txHash, recordHash string, hashVersion int, next time.Time) error {
+	if _, err := r.db.ExecContext(ctx, markSubmittedQuery, id, txHash, recordHash, hashVersion, next); err != nil {
+		return errors.Wrap(err, "anchorRepo.MarkSubmitted.ExecContext")
+	}
+	return nil
+}
+
+func (r *anchorRepo) MarkWaiting(ctx context.Context, id uuid.UUID, confirmations int, next time.Time) error {
+	if _, err := r.db.ExecContext(ctx, markWaitingQuery, id, confirmations, next); err != nil {
+		return errors.Wrap(err, "anchorRepo.MarkWaiting.ExecContext")
+	}
+	return nil
+}
+
+func (r *anchorRepo) UpdateStatus(ctx context.Context, id uuid.UUID, status string, next time.Time, lastError string) error {
+	if _, err := r.db.ExecContext(ctx, updateStatusQuery, id, status, next, lastError); err != nil {
+		return errors.Wrap(err, "anchorRepo.UpdateStatus.ExecContext")
+	}
+	return nil
+}
+
+// Confirm the anchor and put its record on the chain in one transaction
+func (r *anchorRepo) Confirm(ctx context.Context, a *models.BlockchainAnchor, receipt *chain.Receipt) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "anchorRepo.Confirm.BeginTxx")
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, confirmAnchorQuery, a.Id, int64(receipt.BlockNumber), int(receipt.Confirmations)); err != nil {
+		return errors.Wrap(err, "anchorRepo.Confirm.confirmAnchor")
+	}
+
+	switch a.RecordType {
+	case models.AnchorLicense:
+		if _, err = tx.ExecContext(ctx, confirmLicenseQuery, a.TxHash, a.RecordID, a.Reanchor); err != nil {
+			return errors.Wrap(err, "anchorRepo.Confirm.confirmLicense")
+		}
+	case models.AnchorVehicle:
+		res, err := tx.ExecContext(ctx, confirmVehicleQuery, a.TxHash, a.RecordID, a.Reanchor)
+		if err != nil {
+			return errors.Wrap(err, "anchorRepo.Confirm.confirmVehicle")
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return errors.Wrap(err, "anchorRepo.Confirm.RowsAffected")
+		} else if n > 0 {
+			if _, err = tx.ExecContext(ctx, confirmOwnershipQuery, a.TxHash, a.RecordID); err != nil {
+				return errors.Wrap(err, "anchorRepo.Confirm.confirmOwnership")
+			}
+		}
+	default:
+		return errors.Errorf("anchorRepo.Confirm: unknown record type %q", a.RecordType)
+	}
+
+	// the record on the chain is the stored one again
+	if a.Reanchor {
+		if _, err = tx.ExecContext(ctx, resolveFlagQuery, a.RecordType, a.RecordID); err != nil {
+			return errors.Wrap(err, "anchorRepo.Confirm.resolveFlag")
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "anchorRepo.Confirm.Commit")
+	}
+	return nil
+}
+
+func (r *anchorRepo) GetAnchors(ctx context.Context, pq *utils.PaginationQuery) (*models.BlockchainAnchorList, error) {
+	lc, err := pq.ListClause(anchorListSpec, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := lc.Total(ctx, r.db, getAnchorsCount, getAnchorsQuery)
+	if err != nil {
+		return nil, errors.Wrap(err, "anchorRepo.GetAnchors.total")
+	}
+
+	list := &models.BlockchainAnchorList{
+		TotalCount: total,
+		TotalPages: utils.GetTotalPage(total, pq.GetSize()),
+		Page:       pq.GetPage(),
+		Size:       pq.GetSize(),
+		HasMore:    utils.GetHasMore(pq.GetPage(), total, pq.GetSize()),
+		Anchors:    []*models.BlockchainAnchor{},
+	}
+
+	if lc.Empty(total) {
+		return list, nil
+	}
+
+	var items []*models.BlockchainAnchor
+	if err := r.db.SelectContext(ctx, &items, lc.Page(getAnchorsQuery), lc.PageArgs(pq)...); err != nil {
+		return nil, errors.Wrap(err, "anchorRepo.GetAnchors.Select")
+	}
+
+	if list.Anchors, list.NextCursor, err = utils.NextCursor(lc, items); err != nil {
+		return nil, errors.Wrap(err, "anchorRepo.GetAnchors.NextCursor")
+	}
+	list.HasMore = lc.HasMore(pq, total, list.NextCursor)
+	return list, nil
+}
+
+func (r *anchorRepo) GetAnchorByID(ctx context.Context, id uuid.UUID) (*models.BlockchainAnchor, error) {
+	a := &models.BlockchainAnchor{}
+	if err := r.db.GetContext(ctx, a, getAnchorByIDQuery, id); err != nil {
+		return nil, errors.Wrap(err, "anchorRepo.GetAnchorByID.GetContext")
+	}
+	return a, nil
+}
+
+// Queue a failed anchor again with a fresh attempt budget
+func (r *anchorRepo) Retry(ctx context.Context, id uuid.UUID) (*models.BlockchainAnchor, error) {
+	a := &models.BlockchainAnchor{}
+	if err := r.db.QueryRowxContext(ctx, retryAnchorQuery, id).StructScan(a); err != nil {
+		return nil, errors.Wrap(err, "anchorRepo.Retry.StructScan")
+	}
+	return a, nil
+}
+
+func (r *anchorRepo) GetLatestConfirmed(ctx context.Context, recordType string, recordID uuid.UUID) (*models.BlockchainAnchor, error) {
+	a := &models.BlockchainAnchor{}
+	if err := r.db.GetContext(ctx, a, getLatestConfirmedQuery, recordType, recordID); err != nil {
+		return nil, errors.Wrap(err, "anchorRepo.GetLatestConfirmed.GetContext")
+	}
+	return a, nil
+}
+
+// Last confirmed anchor of up to limit records after the given record, empty type and nil id start from the first
+func (r *anchorRepo) GetConfirmedAfter(ctx context.Context, recordType string, recordID uuid.UUID, limit int) ([]*models.BlockchainAnchor, error) {
+	anchors := []*models.BlockchainAnchor{}
+	if err := r.db.SelectContext(ctx, &anchors, getConfirmedAfterQuery, recordType, recordID, limit); err != nil {
+		return nil, errors.Wrap(err, "anchorRepo.GetConfirmedAfter.SelectContext")
+	}
+	return anchors, nil
+}
+
+func (r *anchorRepo) FlagRecord(ctx context.Context, a *models.BlockchainAnchor, currentHash string) error {
+	if _, err := r.db.ExecContext(ctx, flagRecordQuery,
+		uuid.New(), a.RecordType, a.RecordID, a.Id, a.RecordHash, currentHash,
+	); err != nil {
+		return errors.Wrap(err, "anchorRepo.FlagRecord.ExecContext")
+	}
+	return nil
+}
+
+// Resolve the open flag of the record, false when there is none
+func (r *anchorRepo) ResolveFlag(ctx context.Context, recordType string, recordID uuid.UUID) (bool, error) {
+	res, err := r.db.ExecContext(ctx, resolveFlagQuery, recordType, recordID)
+	if err != nil {
+		return false, errors.Wrap(err, "anchorRepo.ResolveFlag.ExecContext")
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "anchorRepo.ResolveFlag.RowsAffected")
+	}
+	return n > 0, nil
+}
+
+func (r *anchorRepo) GetFlags(ctx context.Context, pq *utils.PaginationQuery) (*models.IntegrityFlagList, error) {
+	lc, err := pq.ListClause(integrityFlagListSpec, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := lc.Total(ctx, r.db, getFlagsCount, getFlagsQuery)
+	if err != nil {
+		return nil, errors.Wrap(err, "anchorRepo.GetFlags.total")
+	}
+
+	list := &models.IntegrityFlagList{
+		TotalCount: total,
+		TotalPages: utils.GetTotalPage(total, pq.GetSize()),
+		Page:       pq.GetPage(),
+		Size:       pq.GetSize(),
+		HasMore:    utils.GetHasMore(pq.GetPage(), total, pq.GetSize()),
+		Flags:      []*models.IntegrityFlag{},
+	}
+
+	if lc.Empty(total) {
+		return list, nil
+	}
+
+	var items []*models.IntegrityFlag
+	if err := r.db.SelectContext(ctx, &items, lc.Page(getFlagsQuery), lc.PageArgs(pq)...); err != nil {
+		return nil, errors.Wrap(err, "anchorRepo.GetFlags.Select")
+	}
+
+	if list.Flags, list.NextCursor, err = utils.NextCursor(lc, items); err != nil {
+		return nil, errors.Wrap(err, "anchorRepo.GetFlags.NextCursor")
+	}
+	list.HasMore = lc.HasMore(pq, total, list.NextCursor)
+	return list, nil
+}
+
+func (r *anchorRepo) GetFlagByID(ctx context.Context, id uuid.UUID) (*models.IntegrityFlag, error) {
+	f := &models.IntegrityFlag{}
+	if err := r.db.GetContext(ctx, f, getFlagByIDQuery, id); err != nil {
+		return nil, errors.Wrap(err, "anchorRepo.GetFlagByID.GetContext")
+	}
+	return f, nil
+}
+
+// Queue the flagged record to be written to the chain again
+func (r *anchorRepo) Reanchor(ctx context.Context, flagID uuid.UUID) (*models.BlockchainAnchor, error) {
+	a := &models.BlockchainAnchor{}
+	if err := r.db.QueryRowxContext(ctx, reanchorQuery, uuid.New(), flagID).StructScan(a); err != nil {
+		return nil, errors.Wrap(err, "anchorRepo.Reanchor.StructScan")
+	}
+	return a, nil
+}
+
+// Queue the record to be anchored again, false when it is already queued
+func (r *anchorRepo) QueueReanchor(ctx context.Context, recordType string, recordID uuid.UUID) (bool, error) {
+	return QueueReanchorTx(ctx, r.db, recordType, recordID)
+}
@@ -0,0 +1,253 @@
+package repository
+
+import "github.com/adohong4/driving-license/pkg/utils"
+
+// Filters and sorts accepted by the anchor list
+var anchorListSpec = &utils.ListSpec{
+	Fields: map[string]utils.ListField{
+		"record_type":     {Column: "record_type", Type: utils.FilterExact, Sortable: true, Keyset: true},
+		"record_id":       {Column: "record_id", Type: utils.FilterUUID},
+		"status":          {Column: "status", Type: utils.FilterExact, Sortable: true, Keyset: true},
+		"tx_hash":         {Column: "tx_hash", Type: utils.FilterExact},
+		"hash_version":    {Column: "hash_version", Type: utils.FilterNumber},
+		"reanchor":        {Column: "reanchor", Type: utils.FilterBool},
+		"attempts":        {Column: "attempts", Type: utils.FilterNumber, Sortable: true, Keyset: true},
+		"next_attempt_at": {Column: "next_attempt_at", Type: utils.FilterDate, Sortable: true, Keyset: true},
+		"created_at":      {Column: "created_at", Type: utils.FilterDate, Sortable: true, Keyset: true},
+		"updated_at":      {Column: "updated_at", Type: utils.FilterDate, Sortable: true, Keyset: true},
+	},
+	DefaultSort: "-created_at",
+	IDColumn:    "id",
+}
+
+// Filters and sorts accepted by the integrity flag list
+var integrityFlagListSpec = &utils.ListSpec{
+	Fields: map[string]utils.ListField{
+		"record_type": {Column: "record_type", Type: utils.FilterExact, Sortable: true, Keyset: true},
+		"record_id":   {Column: "record_id", Type: utils.FilterUUID},
+		"status":      {Column: "status", Type: utils.FilterExact, Sortable: true, Keyset: true},
+		"detected_at": {Column: "detected_at", Type: utils.FilterDate, Sortable: true, Keyset: true},
+		"checked_at":  {Column: "checked_at", Type: utils.FilterDate, Sortable: true, Keyset: true},
+	},
+	DefaultSort: "-detected_at",
+	IDColumn:    "id",
+}
+
+const (
+	// queue the records not on the chain yet, oldest first, a record has one open anchor at a time
+	enqueueRecordsQuery = `
+	INSERT INTO blockchain_anchors (id, record_type, record_id, status, next_attempt_at, created_at, updated_at)
+	SELECT gen_random_uuid(), r.record_type, r.record_id, 'pending', now(), now(), now()
+	FROM (
+		(SELECT 'license' AS record_type, dl.id AS record_id
+		FROM driver_licenses dl
+		WHERE dl.active = true AND dl.on_blockchain = false
+		  AND NOT EXISTS (
+			SELECT 1 FROM blockchain_anchors a
+			WHERE a.record_type = 'license' AND a.record_id = dl.id AND a.status IN ('pending', 'submitted', 'failed')
+		  )
+		ORDER BY dl.created_at
+		LIMIT $1)
+		UNION ALL
+		(SELECT 'vehicle', vr.id
+		FROM vehicle_registration vr
+		WHERE vr.active = true AND vr.on_blockchain = false
+		  AND NOT EXISTS (
+			SELECT 1 FROM blockchain_anchors a
+			WHERE a.record_type = 'vehicle' AND a.record_id = vr.id AND a.status IN ('pending', 'submitted', 'failed')
+		  )
+		ORDER BY vr.created_at
+		LIMIT $1)
+	) r
+	ON CONFLICT DO NOTHING
+	`
+
+	// due anchors of the status, leased so other instances skip them until the lease ends.
+	// Claiming a pending anchor counts as a submission attempt
+	claimDueAnchorsQuery = `
+	UPDATE blockchain_anchors
+	SET
+		attempts = attempts + CASE WHEN status = 'pending' THEN 1 ELSE 0 END,
+		next_attempt_at = now() + make_interval(secs => $3),
+		updated_at = now()
+	WHERE id IN (
+		SELECT id
+		FROM blockchain_anchors
+		WHERE status = $1 AND next_attempt_at <= now()
+		ORDER BY next_attempt_at
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED
+	)
+	RETURNING *
+	`
+
+	getLicenseQuery = `
+	SELECT *
+	FROM driver_licenses
+	WHERE id = $1 AND active = true
+	`
+
+	getVehicleQuery = `
+	SELECT *
+	FROM vehicle_registration
+	WHERE id = $1 AND active = true
+	`
+
+	markSubmittedQuery = `
+	UPDATE blockchain_anchors
+	SET
+		status = 'submitted',
+		tx_hash = $2,
+		record_hash = $3,
+		hash_version = $4,
+		block_number = NULL,
+		confirmations = 0,
+		last_error = '',
+		submitted_at = now(),
+		next_attempt_at = $5,
+		updated_at = now()
+	WHERE id = $1
+	`
+
+	markWaitingQuery = `
+	UPDATE blockchain_anchors
+	SET confirmations = $2, next_attempt_at = $3, updated_at = now()
+	WHERE id = $1
+	`
+
+	updateStatusQuery = `
+	UPDATE blockchain_anchors
+	SET status = $2, next_attempt_at = $3, last_error = $4, updated_at = now()
+	WHERE id = $1
+	`
+
+	confirmAnchorQuery = `
+	UPDATE blockchain_anchors
+	SET
+		status = 'confirmed',
+		block_number = $2,
+		confirmations = $3,
+		last_error = '',
+		confirmed_at = now(),
+		updated_at = now()
+	WHERE id = $1 AND status = 'submitted'
+	`
+
+	// records confirmed by the client meanwhile keep their transaction, unless re-anchored
+	confirmLicenseQuery = `
+	UPDATE driver_licenses
+	SET on_blockchain = true, blockchain_txhash = $1, version = version + 1, updated_at = now()
+	WHERE id = $2 AND (on_blockchain = false OR $3)
+	`
+
+	confirmVehicleQuery = `
+	UPDATE vehicle_registration
+	SET on_blockchain = true, blockchain_txhash = $1, version = version + 1, updated_at = now()
+	WHERE id = $2 AND (on_blockchain = false OR $3)
+	`
+
+	confirmOwnershipQuery = `
+	UPDATE vehicle_ownerships
+	SET blockchain_txhash = $1
+	WHERE vehicle_id = $2 AND to_date IS NULL
+	`
+
+	getAnchorsCount = `
+	SELECT COUNT(*)
+	FROM blockchain_anchors
+	WHERE true
+	`
+
+	getAnchorsQuery = `
+	SELECT *
+	FROM blockchain_anchors
+	WHERE true
+	`
+
+	getAnchorByIDQuery = `
+	SELECT *
+	FROM blockchain_anchors
+	WHERE id = $1
+	`
+
+	retryAnchorQuery = `
+	UPDATE blockchain_anchors
+	SET status = 'pending', attempts = 0, last_error = '', next_attempt_at = now(), updated_at = now()
+	WHERE id = $1 AND status = 'failed'
+	RETURNING *
+	`
+
+	// anchor of a transaction sent by a client and verified by the server
+	saveConfirmedAnchorQuery = `
+	INSERT INTO blockchain_anchors (id, record_type, record_id, record_hash, hash_version, status, tx_hash, next_attempt_at,
+		submitted_at, confirmed_at, created_at, updated_at)
+	VALUES ($1, $2, $3, $4, $5, 'confirmed', $6, now(), now(), now(), now(), now())
+	`
+
+	getLatestConfirmedQuery = `
+	SELECT *
+	FROM blockchain_anchors
+	WHERE record_type = $1 AND record_id = $2 AND status = 'confirmed'
+	ORDER BY confirmed_at DESC
+	LIMIT 1
+	`
+
+	// last confirmed anchor of each record after the given one, in record order
+	getConfirmedAfterQuery = `
+	SELECT DISTINCT ON (record_type, record_id) *
+	FROM blockchain_anchors
+	WHERE status = 'confirmed' AND (record_type, record_id) > ($1, $2)
+	ORDER BY record_type, record_id, confirmed_at DESC
+	LIMIT $3
+	`
+
+	// open a flag, or refresh the open one
+	flagRecordQuery = `
+	INSERT INTO integrity_flags (id, record_type, record_id, anchor_id, anchored_hash, current_hash, status, detected_at, checked_at)
+	VALUES ($1, $2, $3, $4, $5, $6, 'open', now(), now())
+	ON CONFLICT (record_type, record_id) WHERE status = 'open'
+	DO UPDATE SET anchor_id = EXCLUDED.anchor_id, anchored_hash = EXCLUDED.anchored_hash,
+		current_hash = EXCLUDED.current_hash, checked_at = now()
+	`
+
+	resolveFlagQuery = `
+	UPDATE integrity_flags
+	SET status = 'resolved', checked_at = now(), resolved_at = now()
+	WHERE record_type = $1 AND record_id = $2 AND status = 'open'
+	`
+
+	getFlagsCount = `
+	SELECT COUNT(*)
+	FROM integrity_flags
+	WHERE true
+	`
+
+	getFlagsQuery = `
+	SELECT *
+	FROM integrity_flags
+	WHERE true
+	`
+
+	getFlagByIDQuery = `
+	SELECT *
+	FROM integrity_flags
+	WHERE id = $1
+	`
+
+	// queue the record of an open flag again, no row when it is already queued
+	reanchorQuery = `
+	INSERT INTO blockchain_anchors (id, record_type, record_id, status, reanchor, next_attempt_at, created_at, updated_at)
+	SELECT $1, f.record_type, f.record_id, 'pending', true, now(), now(), now()
+	FROM integrity_flags f
+	WHERE f.id = $2 AND f.status = 'open'
+	ON CONFLICT DO NOTHING
+	RETURNING *
+	`
+
+	// queue a record again, nothing when it is already queued
+	queueReanchorQuery = `
+	INSERT INTO blockchain_anchors (id, record_type, record_id, status, reanchor, next_attempt_at, created_at, updated_at)
+	VALUES ($1, $2, $3, 'pending', true, now(), now(), now())
+	ON CONFLICT DO NOTHING
+	`
+)
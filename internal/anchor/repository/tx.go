@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/adohong4/driving-license/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// Anchor writes joining the transaction of the repository changing the anchored record,
+// the record and its anchor commit or roll back together
+
+// Save the verified transaction of a client as a confirmed anchor of the record
+func SaveConfirmedTx(ctx context.Context, q sqlx.ExecerContext, a *models.BlockchainAnchor) error {
+	if _, err := q.ExecContext(ctx, saveConfirmedAnchorQuery,
+		uuid.New(), a.RecordType, a.RecordID, a.RecordHash, a.HashVersion, a.TxHash,
+	); err != nil {
+		return errors.Wrap(err, "anchorRepo.SaveConfirmedTx.ExecContext")
+	}
+	return nil
+}
+
+// Queue a re-anchor of the record, false when one is already pending
+func QueueReanchorTx(ctx context.Context, q sqlx.ExecerContext, recordType string, recordID uuid.UUID) (bool, error) {
+	res, err := q.ExecContext(ctx, queueReanchorQuery, uuid.New(), recordType, recordID)
+	if err != nil {
+		return false, errors.Wrap(err, "anchorRepo.QueueReanchorTx.ExecContext")
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "anchorRepo.QueueReanchorTx.RowsAffected")
+	}
+	return n > 0, nil
+}
+
+// Queue a re-anchor when a write changed the canonical form of a record already on the blockchain,
+// before is the record locked ahead of the write and after the written one
+func ReanchorChanged(ctx context.Context, q sqlx.ExecerContext, recordType string, recordID uuid.UUID, onBlockchain bool, before, after models.CanonicalRecord) error {
+	if !onBlockchain {
+		return nil
+	}
+	oldHash, err := models.RecordHash(before)
+	if err != nil {
+		return errors.Wrap(err, "anchorRepo.ReanchorChanged.RecordHash")
+	}
+	newHash, err := models.RecordHash(after)
+	if err != nil {
+		return errors.Wrap(err, "anchorRepo.ReanchorChanged.RecordHash")
+	}
+	if oldHash == newHash {
+		return nil
+	}
+	_, err = QueueReanchorTx(ctx, q, recordType, recordID)
+	return err
+}
@@ -0,0 +1,24 @@
+package anchor
+
+import (
+	"context"
+
+	"github.com/adohong4/driving-license/internal/models"
+	"github.com/adohong4/driving-license/pkg/utils"
+	"github.com/google/uuid"
+)
+
+type UseCase interface {
+	GetAnchors(ctx context.Context, pq *utils.PaginationQuery) (*models.BlockchainAnchorList, error)
+	GetAnchorByID(ctx context.Context, id uuid.UUID) (*models.BlockchainAnchor, error)
+	RetryAnchor(ctx context.Context, id uuid.UUID) (*models.BlockchainAnchor, error)
+	VerifyTransaction(ctx context.Context, recordType string, recordID uuid.UUID, record models.CanonicalRecord, txHash string) (string, error)
+	VerifyRecord(ctx context.Context, recordType string, recordID uuid.UUID) (*models.RecordVerification, error)
+
+	GetFlags(ctx context.Context, pq *utils.PaginationQuery) (*models.IntegrityFlagList, error)
+	ReanchorFlag(ctx context.Context, flagID uuid.UUID) (*models.BlockchainAnchor, error)
+	ScanIntegrity(ctx context.Context) (int, error)
+
+	ProcessOutbox(ctx context.Context) error
+	Run(ctx context.Context)
+}
@@ -0,0 +1,396 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/adohong4/driving-license/config"
+	"github.com/adohong4/driving-license/internal/anchor"
+	"github.com/adohong4/driving-license/internal/models"
+	"github.com/adohong4/driving-license/pkg/chain"
+	"github.com/adohong4/driving-license/pkg/httpErrors"
+	"github.com/adohong4/driving-license/pkg/logger"
+	"github.com/adohong4/driving-license/pkg/utils"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultPollInterval   = 5 * time.Second
+	defaultBatchSize      = 50
+	defaultConfirmations  = 3
+	defaultMaxAttempts    = 8
+	defaultRetryBaseDelay = 10 * time.Second
+	defaultRetryMaxDelay  = 30 * time.Minute
+	defaultConfirmTimeout = 10 * time.Minute
+	defaultScanInterval   = time.Hour
+
+	// anchors claimed by a tick are hidden from other instances this long
+	claimLease = time.Minute
+)
+
+type anchorUC struct {
+	cfg      *config.Config
+	repo     anchor.Repository
+	client   chain.ChainClient
+	verifier chain.Verifier
+	logger   logger.Logger
+}
+
+func NewAnchorUseCase(cfg *config.Config, repo anchor.Repository, client chain.ChainClient, verifier chain.Verifier, log logger.Logger) anchor.UseCase {
+	return &anchorUC{cfg: cfg, repo: repo, client: client, verifier: verifier, logger: log}
+}
+
+func (u *anchorUC) GetAnchors(ctx context.Context, pq *utils.PaginationQuery) (*models.BlockchainAnchorList, error) {
+	return u.repo.GetAnchors(ctx, pq)
+}
+
+func (u *anchorUC) GetAnchorByID(ctx context.Context, id uuid.UUID) (*models.BlockchainAnchor, error) {
+	return u.repo.GetAnchorByID(ctx, id)
+}
+
+// Queue a failed anchor again, other statuses conflict
+func (u *anchorUC) RetryAnchor(ctx context.Context, id uuid.UUID) (*models.BlockchainAnchor, error) {
+	a, err := u.repo.Retry(ctx, id)
+	if err == nil {
+		return a, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	if _, err := u.repo.GetAnchorByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return nil, httpErrors.NewRestError(http.StatusConflict, "only failed anchors can be retried", nil)
+}
+
+// Check the transaction sent by a client anchors the record as it is stored, returns the canonical hash of the record
+func (u *anchorUC) VerifyTransaction(ctx context.Context, recordType string, recordID uuid.UUID, record models.CanonicalRecord, txHash string) (string, error) {
+	if u.verifier == nil {
+		return "", httpErrors.NewRestError(http.StatusServiceUnavailable, "blockchain confirmation is disabled", nil)
+	}
+
+	hash, err := models.RecordHash(record)
+	if err != nil {
+		return "", errors.Wrap(err, "anchorUC.VerifyTransaction.RecordHash")
+	}
+
+	err = u.verifier.Verify(ctx, txHash, &chain.Anchor{RecordType: recordType, RecordID: recordID.String(), Hash: hash})
+	switch {
+	case err == nil:
+		return hash, nil
+	case errors.Is(err, chain.ErrTxNotFound):
+		return "", httpErrors.NewRestError(http.StatusBadRequest, httpErrors.ErrTxNotVerified, "transaction not found or not mined yet")
+	case errors.Is(err, chain.ErrTxFailed):
+		return "", httpErrors.NewRestError(http.StatusBadRequest, httpErrors.ErrTxNotVerified, "transaction failed")
+	case errors.Is(err, chain.ErrWrongContract):
+		return "", httpErrors.NewRestError(http.StatusBadRequest, httpErrors.ErrTxNotVerified, "transaction does not target the registry contract")
+	case errors.Is(err, chain.ErrHashMismatch):
+		return "", httpErrors.NewRestError(http.StatusBadRequest, httpErrors.ErrTxNotVerified, "anchored hash does not match the record")
+	}
+	u.logger.Errorf("anchorUC.VerifyTransaction %s: %v", txHash, err)
+	return "", httpErrors.NewRestError(http.StatusServiceUnavailable, "blockchain node unavailable", nil)
+}
+
+// Current hash of the record against the hash of its last confirmed anchor
+func (u *anchorUC) VerifyRecord(ctx context.Context, recordType string, recordID uuid.UUID) (*models.RecordVerification, error) {
+	record, _, err := u.record(ctx, &models.BlockchainAnchor{RecordType: recordType, RecordID: recordID})
+	if err != nil {
+		if errors.Is(err, errUnknownRecordType) {
+			return nil, httpErrors.NewBadRequestError("record type must be license or vehicle")
+		}
+		return nil, err
+	}
+
+	hash, err := models.RecordHash(record)
+	if err != nil {
+		return nil, errors.Wrap(err, "anchorUC.VerifyRecord.RecordHash")
+	}
+
+	v := &models.RecordVerification{
+		RecordType:  recordType,
+		RecordID:    recordID,
+		CurrentHash: hash,
+		CheckedAt:   time.Now(),
+	}
+
+	a, err := u.repo.GetLatestConfirmed(ctx, recordType, recordID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return v, nil
+		}
+		return nil, err
+	}
+	v.Anchored = true
+	v.AnchoredHash = a.RecordHash
+	v.TxHash = a.TxHash
+	v.BlockNumber = a.BlockNumber
+	v.AnchoredAt = a.ConfirmedAt
+	v.Legacy = a.LegacyHash()
+	v.Match = !v.Legacy && a.RecordHash == hash
+	return v, nil
+}
+
+func (u *anchorUC) GetFlags(ctx context.Context, pq *utils.PaginationQuery) (*models.IntegrityFlagList, error) {
+	return u.repo.GetFlags(ctx, pq)
+}
+
+// Write the flagged record to the chain again, its edit was intended
+func (u *anchorUC) ReanchorFlag(ctx context.Context, flagID uuid.UUID) (*models.BlockchainAnchor, error) {
+	a, err := u.repo.Reanchor(ctx, flagID)
+	if err == nil {
+		return a, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	f, err := u.repo.GetFlagByID(ctx, flagID)
+	if err != nil {
+		return nil, err
+	}
+	if f.Status != models.FlagOpen {
+		return nil, httpErrors.NewRestError(http.StatusConflict, "flag already resolved", nil)
+	}
+	return nil, httpErrors.NewRestError(http.StatusConflict, "record already queued for the chain", nil)
+}
+
+// Compare every anchored record to its last confirmed anchor, flags the records edited since and resolves
+// the flags of records matching again. Records anchored with an older hash scheme are anchored again. Returns the number of open flags
+func (u *anchorUC) ScanIntegrity(ctx context.Context) (int, error) {
+	batch := u.batchSize()
+	flagged := 0
+
+	var lastType string
+	var lastID uuid.UUID
+	for {
+		anchors, err := u.repo.GetConfirmedAfter(ctx, lastType, lastID, batch)
+		if err != nil {
+			return flagged, err
+		}
+		for _, a := range anchors {
+			lastType, lastID = a.RecordType, a.RecordID
+			ok, err := u.check(ctx, a)
+			if err != nil {
+				u.logger.Errorf("anchorUC.ScanIntegrity.check %s %s: %v", a.RecordType, a.RecordID, err)
+				continue
+			}
+			if !ok {
+				flagged++
+			}
+		}
+		if len(anchors) < batch {
+			return flagged, nil
+		}
+	}
+}
+
+// Queue new records, submit the due pending anchors and check the receipts of the submitted ones
+func (u *anchorUC) ProcessOutbox(ctx context.Context) error {
+	batch := u.batchSize()
+
+	if _, err := u.repo.EnqueueRecords(ctx, batch); err != nil {
+		return err
+	}
+
+	pending, err := u.repo.ClaimDue(ctx, models.AnchorPending, batch, claimLease)
+	if err != nil {
+		return err
+	}
+	for _, a := range pending {
+		if err := u.submit(ctx, a); err != nil {
+			u.logger.Errorf("anchorUC.ProcessOutbox.submit %s: %v", a.Id, err)
+		}
+	}
+
+	submitted, err := u.repo.ClaimDue(ctx, models.AnchorSubmitted, batch, claimLease)
+	if err != nil {
+		return err
+	}
+	for _, a := range submitted {
+		if err := u.confirm(ctx, a); err != nil {
+			u.logger.Errorf("anchorUC.ProcessOutbox.confirm %s: %v", a.Id, err)
+		}
+	}
+	return nil
+}
+
+// Process the outbox every PollInterval and scan the anchored records every ScanInterval until ctx is done.
+// The outbox is left alone without a chain client
+func (u *anchorUC) Run(ctx context.Context) {
+	ticker := time.NewTicker(secondsOr(u.cfg.Blockchain.PollInterval, defaultPollInterval))
+	defer ticker.Stop()
+	poll := ticker.C
+	if u.client == nil {
+		u.logger.Warn("anchorUC.Run: no blockchain driver configured, records are not anchored")
+		poll = nil
+	}
+
+	scanTicker := time.NewTicker(secondsOr(u.cfg.Blockchain.ScanInterval, defaultScanInterval))
+	defer scanTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-poll:
+			if err := u.ProcessOutbox(ctx); err != nil {
+				u.logger.Errorf("anchorUC.Run.ProcessOutbox: %v", err)
+			}
+		case <-scanTicker.C:
+			flagged, err := u.ScanIntegrity(ctx)
+			if err != nil {
+				u.logger.Errorf("anchorUC.Run.ScanIntegrity: %v", err)
+			}
+			if flagged > 0 {
+				u.logger.Warnf("anchorUC.Run.ScanIntegrity: %d records changed since they were anchored", flagged)
+			}
+		}
+	}
+}
+
+// Send the hash of the record, records confirmed meanwhile are skipped
+func (u *anchorUC) submit(ctx context.Context, a *models.BlockchainAnchor) error {
+	record, onChain, err := u.record(ctx, a)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return u.repo.UpdateStatus(ctx, a.Id, models.AnchorFailed, time.Now(), "record not found")
+		}
+		return u.retry(ctx, a, err)
+	}
+	if onChain && !a.Reanchor {
+		return u.repo.UpdateStatus(ctx, a.Id, models.AnchorSkipped, time.Now(), "")
+	}
+
+	hash, err := models.RecordHash(record)
+	if err != nil {
+		return u.repo.UpdateStatus(ctx, a.Id, models.AnchorFailed, time.Now(), err.Error())
+	}
+
+	txHash, err := u.client.Submit(ctx, &chain.Anchor{RecordType: a.RecordType, RecordID: a.RecordID.String(), Hash: hash})
+	if err != nil {
+		return u.retry(ctx, a, err)
+	}
+
+	next := time.Now().Add(secondsOr(u.cfg.Blockchain.PollInterval, defaultPollInterval))
+	return u.repo.MarkSubmitted(ctx, a.Id, txHash, hash, models.CanonicalVersion, next)
+}
+
+// Confirm the anchor once its transaction has enough blocks on top
+func (u *anchorUC) confirm(ctx context.Context, a *models.BlockchainAnchor) error {
+	next := time.Now().Add(secondsOr(u.cfg.Blockchain.PollInterval, defaultPollInterval))
+
+	receipt, err := u.client.Receipt(ctx, a.TxHash)
+	if err != nil {
+		if !errors.Is(err, chain.ErrTxNotFound) {
+			if waitErr := u.repo.MarkWaiting(ctx, a.Id, a.Confirmations, next); waitErr != nil {
+				return waitErr
+			}
+			return errors.Wrap(err, "anchorUC.confirm.Receipt")
+		}
+		timeout := secondsOr(u.cfg.Blockchain.ConfirmTimeout, defaultConfirmTimeout)
+		if a.SubmittedAt != nil && time.Since(*a.SubmittedAt) > timeout {
+			// dropped by the chain, send it again
+			return u.repo.UpdateStatus(ctx, a.Id, models.AnchorPending, time.Now(), "transaction not mined in time")
+		}
+		return u.repo.MarkWaiting(ctx, a.Id, a.Confirmations, next)
+	}
+
+	if !receipt.Success {
+		return u.retry(ctx, a, errors.New("transaction reverted"))
+	}
+	if int(receipt.Confirmations) < u.confirmations() {
+		return u.repo.MarkWaiting(ctx, a.Id, int(receipt.Confirmations), next)
+	}
+	return u.repo.Confirm(ctx, a, receipt)
+}
+
+// Back off exponentially, the anchor fails for good after MaxAttempts
+func (u *anchorUC) retry(ctx context.Context, a *models.BlockchainAnchor, cause error) error {
+	maxAttempts := u.cfg.Blockchain.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+	if a.Attempts >= maxAttempts {
+		return u.repo.UpdateStatus(ctx, a.Id, models.AnchorFailed, time.Now(), cause.Error())
+	}
+
+	delay := secondsOr(u.cfg.Blockchain.RetryBaseDelay, defaultRetryBaseDelay)
+	maxDelay := secondsOr(u.cfg.Blockchain.RetryMaxDelay, defaultRetryMaxDelay)
+	for i := 1; i < a.Attempts && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	return u.repo.UpdateStatus(ctx, a.Id, models.AnchorPending, time.Now().Add(delay), cause.Error())
+}
+
+// Flag the record when it differs from its anchor, resolve its flag otherwise. False when flagged
+func (u *anchorUC) check(ctx context.Context, a *models.BlockchainAnchor) (bool, error) {
+	record, _, err := u.record(ctx, a)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// deleted records are not checked
+			return true, nil
+		}
+		return false, err
+	}
+	if a.LegacyHash() {
+		// the anchored hash is of another scheme, anchor the record again rather than flag it
+		_, err = u.repo.QueueReanchor(ctx, a.RecordType, a.RecordID)
+		return true, err
+	}
+	hash, err := models.RecordHash(record)
+	if err != nil {
+		return false, err
+	}
+	if hash == a.RecordHash {
+		_, err = u.repo.ResolveFlag(ctx, a.RecordType, a.RecordID)
+		return true, err
+	}
+	return false, u.repo.FlagRecord(ctx, a, hash)
+}
+
+var errUnknownRecordType = errors.New("unknown record type")
+
+// Record of the anchor and whether it is already on the chain
+func (u *anchorUC) record(ctx context.Context, a *models.BlockchainAnchor) (models.CanonicalRecord, bool, error) {
+	switch a.RecordType {
+	case models.AnchorLicense:
+		dl, err := u.repo.GetLicense(ctx, a.RecordID)
+		if err != nil {
+			return nil, false, err
+		}
+		return dl, dl.OnBlockchain, nil
+	case models.AnchorVehicle:
+		v, err := u.repo.GetVehicle(ctx, a.RecordID)
+		if err != nil {
+			return nil, false, err
+		}
+		return v, v.OnBlockchain, nil
+	}
+	return nil, false, errors.Wrapf(errUnknownRecordType, "%q", a.RecordType)
+}
+
+func (u *anchorUC) batchSize() int {
+	if u.cfg.Blockchain.BatchSize > 0 {
+		return u.cfg.Blockchain.BatchSize
+	}
+	return defaultBatchSize
+}
+
+func (u *anchorUC) confirmations() int {
+	if u.cfg.Blockchain.Confirmations > 0 {
+		return u.cfg.Blockchain.Confirmations
+	}
+	return defaultConfirmations
+}
+
+func secondsOr(seconds int, def time.Duration) time.Duration {
+	if seconds <= 0 {
+		return def
+	}
+	return time.Duration(seconds) * time.Second
+}
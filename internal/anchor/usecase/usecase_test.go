@@ -0,0 +1,532 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/adohong4/driving-license/config"
+	"github.com/adohong4/driving-license/internal/models"
+	"github.com/adohong4/driving-license/pkg/chain"
+	"github.com/adohong4/driving-license/pkg/logger"
+	"github.com/adohong4/driving-license/pkg/utils"
+	"github.com/google/uuid"
+)
+
+var errNotImplemented = errors.New("not implemented")
+
+// In-memory outbox, claims follow claimDueAnchorsQuery and confirms follow Confirm
+type fakeRepo struct {
+	mu       sync.Mutex
+	anchors  map[uuid.UUID]*models.BlockchainAnchor
+	licenses map[uuid.UUID]*models.DrivingLicense
+	flags    map[uuid.UUID]string // open flags by record, with the current hash
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{
+		anchors:  make(map[uuid.UUID]*models.BlockchainAnchor),
+		licenses: make(map[uuid.UUID]*models.DrivingLicense),
+		flags:    make(map[uuid.UUID]string),
+	}
+}
+
+// Pending anchor of a new license
+func (r *fakeRepo) addLicense() (*models.DrivingLicense, *models.BlockchainAnchor) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	expiry := "2035-01-02"
+	dl := &models.DrivingLicense{
+		Id:          uuid.New(),
+		Name:        "Nguyen Van A",
+		DOB:         "1990-05-06",
+		IdentityNo:  "001090000001",
+		LicenseNo:   "790123456789",
+		LicenseType: "B2",
+		IssueDate:   "2025-01-02",
+		ExpiryDate:  &expiry,
+		Status:      "active",
+		Nationality: "VN",
+		Point:       12,
+	}
+	a := &models.BlockchainAnchor{
+		Id:            uuid.New(),
+		RecordType:    models.AnchorLicense,
+		RecordID:      dl.Id,
+		Status:        models.AnchorPending,
+		NextAttemptAt: time.Now().Add(-time.Second),
+	}
+	r.licenses[dl.Id] = dl
+	r.anchors[a.Id] = a
+	return dl, a
+}
+
+// Make every anchor due now, as if the poll interval or the backoff had passed
+func (r *fakeRepo) due() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, a := range r.anchors {
+		a.NextAttemptAt = time.Now().Add(-time.Second)
+	}
+}
+
+func (r *fakeRepo) anchor(id uuid.UUID) models.BlockchainAnchor {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return *r.anchors[id]
+}
+
+func (r *fakeRepo) license(id uuid.UUID) models.DrivingLicense {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return *r.licenses[id]
+}
+
+func (r *fakeRepo) EnqueueRecords(ctx context.Context, limit int) (int, error) {
+	return 0, nil
+}
+
+func (r *fakeRepo) ClaimDue(ctx context.Context, status string, limit int, lease time.Duration) ([]*models.BlockchainAnchor, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	claimed := []*models.BlockchainAnchor{}
+	for _, a := range r.anchors {
+		if len(claimed) == limit {
+			break
+		}
+		if a.Status != status || a.NextAttemptAt.After(time.Now()) {
+			continue
+		}
+		if a.Status == models.AnchorPending {
+			a.Attempts++
+		}
+		a.NextAttemptAt = time.Now().Add(lease)
+		c := *a
+		claimed = append(claimed, &c)
+	}
+	return claimed, nil
+}
+
+func (r *fakeRepo) GetLicense(ctx context.Context, id uuid.UUID) (*models.DrivingLicense, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	dl, ok := r.licenses[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	c := *dl
+	return &c, nil
+}
+
+func (r *fakeRepo) GetVehicle(ctx context.Context, id uuid.UUID) (*models.VehicleRegistration, error) {
+	return nil, sql.ErrNoRows
+}
+
+func (r *fakeRepo) MarkSubmitted(ctx context.Context, id uuid.UUID, txHash, recordHash string, hashVersion int, next time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	a := r.anchors[id]
+	a.Status = models.AnchorSubmitted
+	a.TxHash = txHash
+	a.RecordHash = recordHash
+	a.HashVersion = hashVersion
+	a.BlockNumber = nil
+	a.Confirmations = 0
+	a.LastError = ""
+	a.SubmittedAt = &now
+	a.NextAttemptAt = next
+	return nil
+}
+
+func (r *fakeRepo) MarkWaiting(ctx context.Context, id uuid.UUID, confirmations int, next time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	a := r.anchors[id]
+	a.Confirmations = confirmations
+	a.NextAttemptAt = next
+	return nil
+}
+
+func (r *fakeRepo) UpdateStatus(ctx context.Context, id uuid.UUID, status string, next time.Time, lastError string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	a := r.anchors[id]
+	a.Status = status
+	a.NextAttemptAt = next
+	a.LastError = lastError
+	return nil
+}
+
+func (r *fakeRepo) Confirm(ctx context.Context, a *models.BlockchainAnchor, receipt *chain.Receipt) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	block := int64(receipt.BlockNumber)
+	stored := r.anchors[a.Id]
+	stored.Status = models.AnchorConfirmed
+	stored.BlockNumber = &block
+	stored.Confirmations = int(receipt.Confirmations)
+	stored.ConfirmedAt = &now
+	if dl, ok := r.licenses[a.RecordID]; ok && (!dl.OnBlockchain || a.Reanchor) {
+		dl.OnBlockchain = true
+		dl.BlockchainTxHash = a.TxHash
+	}
+	return nil
+}
+
+func (r *fakeRepo) GetAnchors(ctx context.Context, pq *utils.PaginationQuery) (*models.BlockchainAnchorList, error) {
+	return nil, errNotImplemented
+}
+
+func (r *fakeRepo) GetAnchorByID(ctx context.Context, id uuid.UUID) (*models.BlockchainAnchor, error) {
+	return nil, errNotImplemented
+}
+
+func (r *fakeRepo) Retry(ctx context.Context, id uuid.UUID) (*models.BlockchainAnchor, error) {
+	return nil, errNotImplemented
+}
+
+func (r *fakeRepo) GetLatestConfirmed(ctx context.Context, recordType string, recordID uuid.UUID) (*models.BlockchainAnchor, error) {
+	anchors := r.confirmed(recordID)
+	if len(anchors) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return &anchors[len(anchors)-1], nil
+}
+
+func (r *fakeRepo) GetConfirmedAfter(ctx context.Context, recordType string, recordID uuid.UUID, limit int) ([]*models.BlockchainAnchor, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	latest := make(map[uuid.UUID]*models.BlockchainAnchor)
+	for _, a := range r.anchors {
+		if a.Status != models.AnchorConfirmed {
+			continue
+		}
+		if l, ok := latest[a.RecordID]; !ok || a.ConfirmedAt.After(*l.ConfirmedAt) {
+			latest[a.RecordID] = a
+		}
+	}
+	after := recordType + "/" + recordID.String()
+	anchors := []*models.BlockchainAnchor{}
+	for _, a := range latest {
+		if recordType == "" || a.RecordType+"/"+a.RecordID.String() > after {
+			c := *a
+			anchors = append(anchors, &c)
+		}
+	}
+	sort.Slice(anchors, func(i, j int) bool {
+		return anchors[i].RecordType+"/"+anchors[i].RecordID.String() < anchors[j].RecordType+"/"+anchors[j].RecordID.String()
+	})
+	if len(anchors) > limit {
+		anchors = anchors[:limit]
+	}
+	return anchors, nil
+}
+
+func (r *fakeRepo) FlagRecord(ctx context.Context, a *models.BlockchainAnchor, currentHash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.flags[a.RecordID] = currentHash
+	return nil
+}
+
+func (r *fakeRepo) ResolveFlag(ctx context.Context, recordType string, recordID uuid.UUID) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.flags[recordID]
+	delete(r.flags, recordID)
+	return ok, nil
+}
+
+func (r *fakeRepo) GetFlags(ctx context.Context, pq *utils.PaginationQuery) (*models.IntegrityFlagList, error) {
+	return nil, errNotImplemented
+}
+
+func (r *fakeRepo) GetFlagByID(ctx context.Context, id uuid.UUID) (*models.IntegrityFlag, error) {
+	return nil, errNotImplemented
+}
+
+func (r *fakeRepo) Reanchor(ctx context.Context, flagID uuid.UUID) (*models.BlockchainAnchor, error) {
+	return nil, errNotImplemented
+}
+
+func (r *fakeRepo) QueueReanchor(ctx context.Context, recordType string, recordID uuid.UUID) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, a := range r.anchors {
+		if a.RecordID == recordID && (a.Status == models.AnchorPending || a.Status == models.AnchorSubmitted || a.Status == models.AnchorFailed) {
+			return false, nil
+		}
+	}
+	a := &models.BlockchainAnchor{
+		Id:            uuid.New(),
+		RecordType:    recordType,
+		RecordID:      recordID,
+		Status:        models.AnchorPending,
+		Reanchor:      true,
+		NextAttemptAt: time.Now().Add(-time.Second),
+	}
+	r.anchors[a.Id] = a
+	return true, nil
+}
+
+// Confirmed anchors of a record, oldest first
+func (r *fakeRepo) confirmed(recordID uuid.UUID) []models.BlockchainAnchor {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	anchors := []models.BlockchainAnchor{}
+	for _, a := range r.anchors {
+		if a.RecordID == recordID && a.Status == models.AnchorConfirmed {
+			anchors = append(anchors, *a)
+		}
+	}
+	sort.Slice(anchors, func(i, j int) bool { return anchors[i].ConfirmedAt.Before(*anchors[j].ConfirmedAt) })
+	return anchors
+}
+
+func newTestUseCase(bc config.Blockchain, repo *fakeRepo, sim *chain.SimulatedChain) *anchorUC {
+	cfg := &config.Config{Blockchain: bc, Logger: config.Logger{Level: "fatal"}}
+	log := logger.NewApiLogger(cfg)
+	log.InitLogger()
+	return NewAnchorUseCase(cfg, repo, sim, sim, log).(*anchorUC)
+}
+
+func TestProcessOutboxSubmitsAndConfirms(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeRepo()
+	sim := chain.NewSimulatedChain(0) // blocks are only mined by Mine
+	uc := newTestUseCase(config.Blockchain{Confirmations: 3}, repo, sim)
+
+	dl, a := repo.addLicense()
+	wantHash, err := models.RecordHash(dl)
+	if err != nil {
+		t.Fatalf("RecordHash: %v", err)
+	}
+
+	// submit
+	if err := uc.ProcessOutbox(ctx); err != nil {
+		t.Fatalf("ProcessOutbox: %v", err)
+	}
+	got := repo.anchor(a.Id)
+	if got.Status != models.AnchorSubmitted || got.TxHash == "" || got.Attempts != 1 {
+		t.Fatalf("after submit: status %q, tx %q, attempts %d", got.Status, got.TxHash, got.Attempts)
+	}
+	if got.RecordHash != wantHash {
+		t.Fatalf("record hash %q, want %q", got.RecordHash, wantHash)
+	}
+	sent, ok := sim.Transaction(got.TxHash)
+	if !ok || sent.Hash != wantHash || sent.RecordID != dl.Id.String() {
+		t.Fatalf("transaction on the chain %+v, want the hash of the license", sent)
+	}
+	txHash := got.TxHash
+
+	// not mined yet
+	repo.due()
+	if err := uc.ProcessOutbox(ctx); err != nil {
+		t.Fatalf("ProcessOutbox: %v", err)
+	}
+	if got = repo.anchor(a.Id); got.Status != models.AnchorSubmitted || got.Confirmations != 0 {
+		t.Fatalf("before mining: status %q, confirmations %d", got.Status, got.Confirmations)
+	}
+
+	// mined, waiting for confirmations
+	sim.Mine(1)
+	repo.due()
+	if err := uc.ProcessOutbox(ctx); err != nil {
+		t.Fatalf("ProcessOutbox: %v", err)
+	}
+	if got = repo.anchor(a.Id); got.Status != models.AnchorSubmitted || got.Confirmations != 1 {
+		t.Fatalf("after one block: status %q, confirmations %d", got.Status, got.Confirmations)
+	}
+	if stored := repo.license(dl.Id); stored.OnBlockchain || stored.BlockchainTxHash != "" {
+		t.Fatalf("license on the chain before it is confirmed")
+	}
+
+	// confirmed
+	sim.Mine(2)
+	repo.due()
+	if err := uc.ProcessOutbox(ctx); err != nil {
+		t.Fatalf("ProcessOutbox: %v", err)
+	}
+	if got = repo.anchor(a.Id); got.Status != models.AnchorConfirmed || got.Confirmations != 3 || got.BlockNumber == nil {
+		t.Fatalf("after three blocks: status %q, confirmations %d", got.Status, got.Confirmations)
+	}
+	stored := repo.license(dl.Id)
+	if !stored.OnBlockchain || stored.BlockchainTxHash != txHash {
+		t.Fatalf("license on_blockchain %v, blockchain_txhash %q, want true and %q", stored.OnBlockchain, stored.BlockchainTxHash, txHash)
+	}
+	if got.Attempts != 1 {
+		t.Fatalf("attempts %d, want 1", got.Attempts)
+	}
+}
+
+func TestProcessOutboxRetriesWithBackoff(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeRepo()
+	sim := chain.NewSimulatedChain(0)
+	uc := newTestUseCase(config.Blockchain{MaxAttempts: 4, RetryBaseDelay: 10, RetryMaxDelay: 30}, repo, sim)
+	sim.FailSubmits(10)
+
+	dl, a := repo.addLicense()
+
+	// 10s, doubled after each attempt up to the 30s cap
+	for attempt, delay := range []time.Duration{10 * time.Second, 20 * time.Second, 30 * time.Second} {
+		start := time.Now()
+		if err := uc.ProcessOutbox(ctx); err != nil {
+			t.Fatalf("ProcessOutbox: %v", err)
+		}
+		got := repo.anchor(a.Id)
+		if got.Status != models.AnchorPending || got.Attempts != attempt+1 || got.LastError == "" {
+			t.Fatalf("attempt %d: status %q, attempts %d, last error %q", attempt+1, got.Status, got.Attempts, got.LastError)
+		}
+		if wait := got.NextAttemptAt.Sub(start); wait < delay || wait > delay+5*time.Second {
+			t.Fatalf("attempt %d: retried after %v, want %v", attempt+1, wait, delay)
+		}
+
+		// not retried before the backoff ends
+		if err := uc.ProcessOutbox(ctx); err != nil {
+			t.Fatalf("ProcessOutbox: %v", err)
+		}
+		if again := repo.anchor(a.Id); again.Attempts != got.Attempts {
+			t.Fatalf("attempt %d: retried before the backoff ended", attempt+1)
+		}
+		repo.due()
+	}
+
+	// the last attempt fails for good
+	if err := uc.ProcessOutbox(ctx); err != nil {
+		t.Fatalf("ProcessOutbox: %v", err)
+	}
+	got := repo.anchor(a.Id)
+	if got.Status != models.AnchorFailed || got.Attempts != 4 {
+		t.Fatalf("after max attempts: status %q, attempts %d", got.Status, got.Attempts)
+	}
+	repo.due()
+	if err := uc.ProcessOutbox(ctx); err != nil {
+		t.Fatalf("ProcessOutbox: %v", err)
+	}
+	if again := repo.anchor(a.Id); again.Attempts != 4 || again.Status != models.AnchorFailed {
+		t.Fatalf("failed anchor submitted again")
+	}
+	if stored := repo.license(dl.Id); stored.OnBlockchain {
+		t.Fatalf("license on the chain after a failed anchor")
+	}
+}
+
+func TestProcessOutboxRetriesRevertedTransaction(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeRepo()
+	sim := chain.NewSimulatedChain(0)
+	uc := newTestUseCase(config.Blockchain{Confirmations: 1}, repo, sim)
+
+	dl, a := repo.addLicense()
+	if err := uc.ProcessOutbox(ctx); err != nil {
+		t.Fatalf("ProcessOutbox: %v", err)
+	}
+	reverted := repo.anchor(a.Id).TxHash
+	sim.Revert(reverted)
+	sim.Mine(1)
+	repo.due()
+	if err := uc.ProcessOutbox(ctx); err != nil {
+		t.Fatalf("ProcessOutbox: %v", err)
+	}
+	if got := repo.anchor(a.Id); got.Status != models.AnchorPending || got.LastError == "" {
+		t.Fatalf("after revert: status %q, last error %q", got.Status, got.LastError)
+	}
+
+	// sent again and confirmed with the new transaction
+	repo.due()
+	if err := uc.ProcessOutbox(ctx); err != nil {
+		t.Fatalf("ProcessOutbox: %v", err)
+	}
+	sim.Mine(1)
+	repo.due()
+	if err := uc.ProcessOutbox(ctx); err != nil {
+		t.Fatalf("ProcessOutbox: %v", err)
+	}
+	got := repo.anchor(a.Id)
+	if got.Status != models.AnchorConfirmed || got.TxHash == reverted || got.Attempts != 2 {
+		t.Fatalf("after resubmit: status %q, tx %q, attempts %d", got.Status, got.TxHash, got.Attempts)
+	}
+	if stored := repo.license(dl.Id); stored.BlockchainTxHash != got.TxHash {
+		t.Fatalf("blockchain_txhash %q, want %q", stored.BlockchainTxHash, got.TxHash)
+	}
+}
+
+func TestScanIntegrityReanchorsLegacyAnchors(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeRepo()
+	sim := chain.NewSimulatedChain(0)
+	uc := newTestUseCase(config.Blockchain{Confirmations: 1}, repo, sim)
+
+	// anchored with the full record hash, before hash_version
+	dl, a := repo.addLicense()
+	confirmedAt := time.Now().Add(-time.Hour)
+	repo.mu.Lock()
+	legacy := repo.anchors[a.Id]
+	legacy.Status = models.AnchorConfirmed
+	legacy.RecordHash = "0x" + strings.Repeat("ab", 32)
+	legacy.TxHash = "0xlegacy"
+	legacy.ConfirmedAt = &confirmedAt
+	repo.licenses[dl.Id].OnBlockchain = true
+	repo.licenses[dl.Id].BlockchainTxHash = legacy.TxHash
+	repo.mu.Unlock()
+
+	v, err := uc.VerifyRecord(ctx, models.AnchorLicense, dl.Id)
+	if err != nil {
+		t.Fatalf("VerifyRecord: %v", err)
+	}
+	if !v.Legacy || v.Match {
+		t.Fatalf("legacy anchor verified as legacy %v, match %v", v.Legacy, v.Match)
+	}
+
+	flagged, err := uc.ScanIntegrity(ctx)
+	if err != nil {
+		t.Fatalf("ScanIntegrity: %v", err)
+	}
+	if flagged != 0 || len(repo.flags) != 0 {
+		t.Fatalf("legacy anchor flagged, %d flags", flagged)
+	}
+	// queued once
+	if _, err := uc.ScanIntegrity(ctx); err != nil {
+		t.Fatalf("ScanIntegrity: %v", err)
+	}
+
+	// anchored again with the current scheme, though the record is already on the chain
+	if err := uc.ProcessOutbox(ctx); err != nil {
+		t.Fatalf("ProcessOutbox: %v", err)
+	}
+	sim.Mine(1)
+	repo.due()
+	if err := uc.ProcessOutbox(ctx); err != nil {
+		t.Fatalf("ProcessOutbox: %v", err)
+	}
+	anchors := repo.confirmed(dl.Id)
+	if len(anchors) != 2 {
+		t.Fatalf("%d confirmed anchors, want the legacy one and the new one", len(anchors))
+	}
+	current := anchors[1]
+	if current.HashVersion != models.CanonicalVersion || !current.Reanchor {
+		t.Fatalf("new anchor hash version %d, reanchor %v", current.HashVersion, current.Reanchor)
+	}
+	if stored := repo.license(dl.Id); stored.BlockchainTxHash != current.TxHash {
+		t.Fatalf("blockchain_txhash %q, want %q", stored.BlockchainTxHash, current.TxHash)
+	}
+
+	// compared from now on
+	if flagged, err := uc.ScanIntegrity(ctx); err != nil || flagged != 0 {
+		t.Fatalf("ScanIntegrity = %d, %v, want no flag", flagged, err)
+	}
+	repo.mu.Lock()
+	repo.licenses[dl.Id].Name = "Nguyen Van B"
+	repo.mu.Unlock()
+	if flagged, err := uc.ScanIntegrity(ctx); err != nil || flagged != 1 {
+		t.Fatalf("ScanIntegrity after an edit = %d, %v, want one flag", flagged, err)
+	}
+}
@@ -0,0 +1,21 @@
+package appointment
+
+import "github.com/labstack/echo/v4"
+
+type Handlers interface {
+	CreateService() echo.HandlerFunc
+	DeleteService() echo.HandlerFunc
+	GetAgencyServices() echo.HandlerFunc
+	PublishSlots() echo.HandlerFunc
+	GetServiceSlots() echo.HandlerFunc
+	CloseSlot() echo.HandlerFunc
+
+	Book() echo.HandlerFunc
+	Reschedule() echo.HandlerFunc
+	Cancel() echo.HandlerFunc
+	GetMyAppointments() echo.HandlerFunc
+	GetByID() echo.HandlerFunc
+
+	CheckIn() echo.HandlerFunc
+	GetQueue() echo.HandlerFunc
+}
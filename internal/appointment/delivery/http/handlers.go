@@ -0,0 +1,476 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/adohong4/driving-license/config"
+	"github.com/adohong4/driving-license/internal/appointment"
+	"github.com/adohong4/driving-license/internal/models"
+	"github.com/adohong4/driving-license/pkg/httpErrors"
+	"github.com/adohong4/driving-license/pkg/logger"
+	"github.com/adohong4/driving-license/pkg/utils"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type appointmentHandlers struct {
+	cfg    *config.Config
+	apptUC appointment.UseCase
+	logger logger.Logger
+}
+
+func NewAppointmentHandlers(cfg *config.Config, apptUC appointment.UseCase, logger logger.Logger) appointment.Handlers {
+	return &appointmentHandlers{cfg: cfg, apptUC: apptUC, logger: logger}
+}
+
+// CreateService godoc
+// @Summary      Publish an agency service
+// @Description  Officer only. Publishes a service citizens book appointments for at an agency, with the duration of a slot
+// @Tags         appointment
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.CreateServiceRequest  true  "Agency, kind, name and duration"
+// @Success      201      {object}  models.AgencyService
+// @Failure      400      {object}  httpErrors.Problem
+// @Failure      401      {object}  httpErrors.Problem
+// @Failure      403      {object}  httpErrors.Problem
+// @Failure      404      {object}  httpErrors.Problem
+// @Failure      500      {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /appointments/services [post]
+func (h *appointmentHandlers) CreateService() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		req := &models.CreateServiceRequest{}
+		if err := c.Bind(req); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		res, err := h.apptUC.CreateService(ctx, req)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusCreated, res)
+	}
+}
+
+// DeleteService godoc
+// @Summary      Withdraw an agency service
+// @Description  Officer only. The service takes no more bookings, appointments already booked are kept
+// @Tags         appointment
+// @Produce      json
+// @Param        id   path      string  true  "Service ID (UUID)"
+// @Success      200  {object}  models.AgencyService
+// @Failure      400  {object}  httpErrors.Problem
+// @Failure      401  {object}  httpErrors.Problem
+// @Failure      403  {object}  httpErrors.Problem
+// @Failure      404  {object}  httpErrors.Problem
+// @Failure      500  {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /appointments/services/{id} [delete]
+func (h *appointmentHandlers) DeleteService() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		res, err := h.apptUC.DeleteService(ctx, id)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+// GetAgencyServices godoc
+// @Summary      List the services of an agency
+// @Description  Services citizens book appointments for at the agency
+// @Tags         appointment
+// @Produce      json
+// @Param        id   path      string  true  "Agency ID (UUID)"
+// @Success      200  {object}  models.AgencyServiceList
+// @Failure      400  {object}  httpErrors.Problem
+// @Failure      401  {object}  httpErrors.Problem
+// @Failure      500  {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /appointments/agencies/{id}/services [get]
+func (h *appointmentHandlers) GetAgencyServices() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		res, err := h.apptUC.GetAgencyServices(ctx, id)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+// PublishSlots godoc
+// @Summary      Publish a slot calendar
+// @Description  Officer only. Splits the opening hours into slots of the service duration on the weekdays of the date range, up to 92 days. Slots published before are kept, the slots of the range are returned
+// @Tags         appointment
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string  true  "Service ID (UUID)"
+// @Param        request  body      models.PublishSlotsRequest  true  "Date range, weekdays, opening hours and capacity"
+// @Success      201      {object}  models.AppointmentSlotList
+// @Failure      400      {object}  httpErrors.Problem
+// @Failure      401      {object}  httpErrors.Problem
+// @Failure      403      {object}  httpErrors.Problem
+// @Failure      404      {object}  httpErrors.Problem
+// @Failure      500      {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /appointments/services/{id}/slots [post]
+func (h *appointmentHandlers) PublishSlots() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		req := &models.PublishSlotsRequest{}
+		if err = c.Bind(req); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		res, err := h.apptUC.PublishSlots(ctx, id, req)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusCreated, res)
+	}
+}
+
+// GetServiceSlots godoc
+// @Summary      List the slots of a service
+// @Description  Slots with their capacity and booked seats, the next 14 days by default and at most 92 days
+// @Tags         appointment
+// @Produce      json
+// @Param        id    path      string  true  "Service ID (UUID)"
+// @Param        from  query     string  false  "First day, YYYY-MM-DD, today by default"
+// @Param        to    query     string  false  "Last day, YYYY-MM-DD"
+// @Success      200   {object}  models.AppointmentSlotList
+// @Failure      400   {object}  httpErrors.Problem
+// @Failure      401   {object}  httpErrors.Problem
+// @Failure      404   {object}  httpErrors.Problem
+// @Failure      500   {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /appointments/services/{id}/slots [get]
+func (h *appointmentHandlers) GetServiceSlots() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		res, err := h.apptUC.GetServiceSlots(ctx, id, c.QueryParam("from"), c.QueryParam("to"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+// CloseSlot godoc
+// @Summary      Close a slot
+// @Description  Officer only. The slot takes no more bookings, appointments already booked are kept
+// @Tags         appointment
+// @Produce      json
+// @Param        id   path      string  true  "Slot ID (UUID)"
+// @Success      200  {object}  models.AppointmentSlot
+// @Failure      400  {object}  httpErrors.Problem
+// @Failure      401  {object}  httpErrors.Problem
+// @Failure      403  {object}  httpErrors.Problem
+// @Failure      404  {object}  httpErrors.Problem
+// @Failure      409  {object}  httpErrors.Problem
+// @Failure      500  {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /appointments/slots/{id}/close [post]
+func (h *appointmentHandlers) CloseSlot() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		res, err := h.apptUC.CloseSlot(ctx, id)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+// Book godoc
+// @Summary      Book an appointment
+// @Description  Books a seat of an open slot. A citizen holds one appointment per service at a time, a full slot is never overbooked
+// @Tags         User
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.BookAppointmentRequest  true  "Slot and note"
+// @Success      201      {object}  models.Appointment
+// @Failure      400      {object}  httpErrors.Problem
+// @Failure      401      {object}  httpErrors.Problem
+// @Failure      404      {object}  httpErrors.Problem
+// @Failure      409      {object}  httpErrors.Problem
+// @Failure      500      {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /appointments/me [post]
+func (h *appointmentHandlers) Book() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		req := &models.BookAppointmentRequest{}
+		if err := c.Bind(req); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		res, err := h.apptUC.Book(ctx, req)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusCreated, res)
+	}
+}
+
+// Reschedule godoc
+// @Summary      Reschedule an appointment
+// @Description  Moves a booked appointment to another open slot of its service before it starts, the former seat is given back
+// @Tags         User
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string  true  "Appointment ID (UUID)"
+// @Param        request  body      models.RescheduleAppointmentRequest  true  "New slot"
+// @Success      200      {object}  models.Appointment
+// @Failure      400      {object}  httpErrors.Problem
+// @Failure      401      {object}  httpErrors.Problem
+// @Failure      404      {object}  httpErrors.Problem
+// @Failure      409      {object}  httpErrors.Problem
+// @Failure      500      {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /appointments/me/{id}/reschedule [post]
+func (h *appointmentHandlers) Reschedule() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		req := &models.RescheduleAppointmentRequest{}
+		if err = c.Bind(req); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		res, err := h.apptUC.Reschedule(ctx, id, req)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+// Cancel godoc
+// @Summary      Cancel an appointment
+// @Description  Cancels a booked appointment before it starts, its seat is given back
+// @Tags         User
+// @Produce      json
+// @Param        id   path      string  true  "Appointment ID (UUID)"
+// @Success      200  {object}  models.Appointment
+// @Failure      400  {object}  httpErrors.Problem
+// @Failure      401  {object}  httpErrors.Problem
+// @Failure      404  {object}  httpErrors.Problem
+// @Failure      409  {object}  httpErrors.Problem
+// @Failure      500  {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /appointments/me/{id}/cancel [post]
+func (h *appointmentHandlers) Cancel() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		res, err := h.apptUC.Cancel(ctx, id)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+// GetMyAppointments godoc
+// @Summary      List my appointments
+// @Description  Appointments of the current user, latest first. Filterable by agency_id, service_id, service_kind, status and dates
+// @Tags         User
+// @Produce      json
+// @Param        page        query     int     false  "Page number (default: 1)"
+// @Param        size        query     int     false  "Page size (default: 10)"
+// @Param        sort        query     string  false  "Sort fields, comma separated, - prefix for descending, e.g. -starts_at"
+// @Param        status      query     string  false  "booked, cancelled or checked_in"
+// @Param        cursor      query     string  false  "Cursor of the next page, empty for the first one, switches to cursor pagination"
+// @Param        with_total  query     bool    false  "Return an approximate total_count on cursor pages"
+// @Success      200         {object}  models.AppointmentList
+// @Failure      400         {object}  httpErrors.Problem
+// @Failure      401         {object}  httpErrors.Problem
+// @Failure      500         {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /appointments/me [get]
+func (h *appointmentHandlers) GetMyAppointments() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		pq, err := utils.GetPaginationFromCtx(c)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		list, err := h.apptUC.GetMyAppointments(ctx, pq)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, list)
+	}
+}
+
+// GetByID godoc
+// @Summary      Get an appointment
+// @Description  Citizens read their own appointments, officers read any
+// @Tags         appointment
+// @Produce      json
+// @Param        id   path      string  true  "Appointment ID (UUID)"
+// @Success      200  {object}  models.Appointment
+// @Failure      400  {object}  httpErrors.Problem
+// @Failure      401  {object}  httpErrors.Problem
+// @Failure      404  {object}  httpErrors.Problem
+// @Failure      500  {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /appointments/{id} [get]
+func (h *appointmentHandlers) GetByID() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		res, err := h.apptUC.GetByID(ctx, id)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+// CheckIn godoc
+// @Summary      Check a citizen in
+// @Description  Officer only. Checks in a booked appointment on its day
+// @Tags         appointment
+// @Produce      json
+// @Param        id   path      string  true  "Appointment ID (UUID)"
+// @Success      200  {object}  models.Appointment
+// @Failure      400  {object}  httpErrors.Problem
+// @Failure      401  {object}  httpErrors.Problem
+// @Failure      403  {object}  httpErrors.Problem
+// @Failure      404  {object}  httpErrors.Problem
+// @Failure      409  {object}  httpErrors.Problem
+// @Failure      500  {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /appointments/{id}/check-in [post]
+func (h *appointmentHandlers) CheckIn() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		res, err := h.apptUC.CheckIn(ctx, id)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+// GetQueue godoc
+// @Summary      Daily queue of an agency
+// @Description  Officer only. Appointments of the agency on the day in slot order with the waiting and checked in counts, cancelled appointments left out
+// @Tags         appointment
+// @Produce      json
+// @Param        agency_id  query     string  true  "Agency ID (UUID)"
+// @Param        date       query     string  false  "Day, YYYY-MM-DD, today by default"
+// @Success      200        {object}  models.AppointmentQueue
+// @Failure      400        {object}  httpErrors.Problem
+// @Failure      401        {object}  httpErrors.Problem
+// @Failure      403        {object}  httpErrors.Problem
+// @Failure      500        {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /appointments/queue [get]
+func (h *appointmentHandlers) GetQueue() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		agencyID, err := uuid.Parse(c.QueryParam("agency_id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		res, err := h.apptUC.GetQueue(ctx, agencyID, c.QueryParam("date"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
@@ -0,0 +1,29 @@
+package http
+
+import (
+	"github.com/adohong4/driving-license/config"
+	"github.com/adohong4/driving-license/internal/appointment"
+	"github.com/adohong4/driving-license/internal/auth"
+	"github.com/adohong4/driving-license/internal/middleware"
+	"github.com/labstack/echo/v4"
+)
+
+var officerRoles = []string{"admin", "officer"}
+
+func MapAppointmentRoutes(apptGroup *echo.Group, h appointment.Handlers, mw *middleware.MiddlewareManager, cfg *config.Config, authUC auth.UseCase) {
+	apptGroup.POST("/services", h.CreateService(), mw.AuthJWTMiddleware(authUC, cfg), mw.RoleBasedAuthMiddleware(officerRoles))
+	apptGroup.DELETE("/services/:id", h.DeleteService(), mw.AuthJWTMiddleware(authUC, cfg), mw.RoleBasedAuthMiddleware(officerRoles))
+	apptGroup.GET("/agencies/:id/services", h.GetAgencyServices(), mw.AuthJWTMiddleware(authUC, cfg))
+	apptGroup.POST("/services/:id/slots", h.PublishSlots(), mw.AuthJWTMiddleware(authUC, cfg), mw.RoleBasedAuthMiddleware(officerRoles))
+	apptGroup.GET("/services/:id/slots", h.GetServiceSlots(), mw.AuthJWTMiddleware(authUC, cfg))
+	apptGroup.POST("/slots/:id/close", h.CloseSlot(), mw.AuthJWTMiddleware(authUC, cfg), mw.RoleBasedAuthMiddleware(officerRoles))
+
+	apptGroup.GET("/me", h.GetMyAppointments(), mw.AuthJWTMiddleware(authUC, cfg))
+	apptGroup.POST("/me", h.Book(), mw.AuthJWTMiddleware(authUC, cfg))
+	apptGroup.POST("/me/:id/reschedule", h.Reschedule(), mw.AuthJWTMiddleware(authUC, cfg))
+	apptGroup.POST("/me/:id/cancel", h.Cancel(), mw.AuthJWTMiddleware(authUC, cfg))
+
+	apptGroup.GET("/queue", h.GetQueue(), mw.AuthJWTMiddleware(authUC, cfg), mw.RoleBasedAuthMiddleware(officerRoles))
+	apptGroup.GET("/:id", h.GetByID(), mw.AuthJWTMiddleware(authUC, cfg))
+	apptGroup.POST("/:id/check-in", h.CheckIn(), mw.AuthJWTMiddleware(authUC, cfg), mw.RoleBasedAuthMiddleware(officerRoles))
+}
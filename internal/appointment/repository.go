@@ -0,0 +1,34 @@
+package appointment
+
+import (
+	"context"
+	"time"
+
+	"github.com/adohong4/driving-license/internal/models"
+	"github.com/adohong4/driving-license/pkg/utils"
+	"github.com/google/uuid"
+)
+
+type Repository interface {
+	CreateService(ctx context.Context, s *models.AgencyService) (*models.AgencyService, error)
+	GetServiceByID(ctx context.Context, serviceID uuid.UUID) (*models.AgencyService, error)
+	DeleteService(ctx context.Context, serviceID uuid.UUID) (*models.AgencyService, error)
+	GetAgencyServices(ctx context.Context, agencyID uuid.UUID) ([]*models.AgencyService, error)
+
+	CreateSlots(ctx context.Context, slots []*models.AppointmentSlot) (int, error)
+	GetSlotByID(ctx context.Context, slotID uuid.UUID) (*models.AppointmentSlot, error)
+	GetServiceSlots(ctx context.Context, serviceID uuid.UUID, from, to time.Time) ([]*models.AppointmentSlot, error)
+	CloseSlot(ctx context.Context, slotID uuid.UUID) (*models.AppointmentSlot, error)
+
+	Book(ctx context.Context, a *models.Appointment) (*models.Appointment, error)
+	Reschedule(ctx context.Context, a *models.Appointment, slot *models.AppointmentSlot) (*models.Appointment, error)
+	Cancel(ctx context.Context, appointmentID uuid.UUID) (*models.Appointment, error)
+	CheckIn(ctx context.Context, appointmentID, officerID uuid.UUID) (*models.Appointment, error)
+	GetByID(ctx context.Context, appointmentID uuid.UUID) (*models.Appointment, error)
+	GetOpenByService(ctx context.Context, userID, serviceID uuid.UUID) (*models.Appointment, error)
+	GetByUser(ctx context.Context, userID uuid.UUID, pq *utils.PaginationQuery) (*models.AppointmentList, error)
+	GetQueue(ctx context.Context, agencyID uuid.UUID, from, to time.Time) ([]*models.Appointment, error)
+	ClaimDueReminders(ctx context.Context, before time.Time, limit int) ([]*models.Appointment, error)
+
+	GetAgency(ctx context.Context, agencyID uuid.UUID) (*models.GovAgency, error)
+}
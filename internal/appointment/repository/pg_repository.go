@@ -0,0 +1,299 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/adohong4/driving-license/internal/appointment"
+	"github.com/adohong4/driving-license/internal/models"
+	"github.com/adohong4/driving-license/pkg/utils"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+type appointmentRepo struct {
+	db *sqlx.DB
+}
+
+func NewAppointmentRepo(db *sqlx.DB) appointment.Repository {
+	return &appointmentRepo{db: db}
+}
+
+func (r *appointmentRepo) CreateService(ctx context.Context, s *models.AgencyService) (*models.AgencyService, error) {
+	created := &models.AgencyService{}
+	if err := r.db.QueryRowxContext(ctx, createServiceQuery,
+		s.Id, s.AgencyID, s.Kind, s.Name, s.DurationMinutes, s.CreatorId, s.CreatedAt, s.UpdatedAt,
+	).StructScan(created); err != nil {
+		return nil, errors.Wrap(err, "appointmentRepo.CreateService.StructScan")
+	}
+	return created, nil
+}
+
+func (r *appointmentRepo) GetServiceByID(ctx context.Context, serviceID uuid.UUID) (*models.AgencyService, error) {
+	s := &models.AgencyService{}
+	if err := r.db.GetContext(ctx, s, getServiceByID, serviceID); err != nil {
+		return nil, errors.Wrap(err, "appointmentRepo.GetServiceByID.GetContext")
+	}
+	return s, nil
+}
+
+func (r *appointmentRepo) DeleteService(ctx context.Context, serviceID uuid.UUID) (*models.AgencyService, error) {
+	s := &models.AgencyService{}
+	if err := r.db.QueryRowxContext(ctx, deleteServiceQuery, serviceID).StructScan(s); err != nil {
+		return nil, errors.Wrap(err, "appointmentRepo.DeleteService.StructScan")
+	}
+	return s, nil
+}
+
+func (r *appointmentRepo) GetAgencyServices(ctx context.Context, agencyID uuid.UUID) ([]*models.AgencyService, error) {
+	services := []*models.AgencyService{}
+	if err := r.db.SelectContext(ctx, &services, getAgencyServices, agencyID); err != nil {
+		return nil, errors.Wrap(err, "appointmentRepo.GetAgencyServices.SelectContext")
+	}
+	return services, nil
+}
+
+// Insert the slots in one transaction, the number of slots not published before
+func (r *appointmentRepo) CreateSlots(ctx context.Context, slots []*models.AppointmentSlot) (int, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, errors.Wrap(err, "appointmentRepo.CreateSlots.BeginTxx")
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PreparexContext(ctx, createSlotQuery)
+	if err != nil {
+		return 0, errors.Wrap(err, "appointmentRepo.CreateSlots.Preparex")
+	}
+	defer stmt.Close()
+
+	created := 0
+	for _, s := range slots {
+		res, err := stmt.ExecContext(ctx, s.Id, s.ServiceID, s.AgencyID, s.StartsAt, s.EndsAt, s.Capacity, s.Status, s.CreatedAt, s.UpdatedAt)
+		if err != nil {
+			return 0, errors.Wrap(err, "appointmentRepo.CreateSlots.ExecContext")
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return 0, errors.Wrap(err, "appointmentRepo.CreateSlots.RowsAffected")
+		}
+		created += int(n)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, errors.Wrap(err, "appointmentRepo.CreateSlots.Commit")
+	}
+	return created, nil
+}
+
+func (r *appointmentRepo) GetSlotByID(ctx context.Context, slotID uuid.UUID) (*models.AppointmentSlot, error) {
+	s := &models.AppointmentSlot{}
+	if err := r.db.GetContext(ctx, s, getSlotByID, slotID); err != nil {
+		return nil, errors.Wrap(err, "appointmentRepo.GetSlotByID.GetContext")
+	}
+	return s, nil
+}
+
+// Slots of the service starting in [from, to)
+func (r *appointmentRepo) GetServiceSlots(ctx context.Context, serviceID uuid.UUID, from, to time.Time) ([]*models.AppointmentSlot, error) {
+	slots := []*models.AppointmentSlot{}
+	if err := r.db.SelectContext(ctx, &slots, getServiceSlots, serviceID, from, to); err != nil {
+		return nil, errors.Wrap(err, "appointmentRepo.GetServiceSlots.SelectContext")
+	}
+	return slots, nil
+}
+
+func (r *appointmentRepo) CloseSlot(ctx context.Context, slotID uuid.UUID) (*models.AppointmentSlot, error) {
+	s := &models.AppointmentSlot{}
+	if err := r.db.QueryRowxContext(ctx, closeSlotQuery, slotID).StructScan(s); err != nil {
+		return nil, errors.Wrap(err, "appointmentRepo.CloseSlot.StructScan")
+	}
+	return s, nil
+}
+
+// Take a seat of the slot and book it in one transaction.
+// sql.ErrNoRows when the slot is full, closed or already started.
+func (r *appointmentRepo) Book(ctx context.Context, a *models.Appointment) (*models.Appointment, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "appointmentRepo.Book.BeginTxx")
+	}
+	defer tx.Rollback()
+
+	if err = claimSlot(ctx, tx, a.SlotID); err != nil {
+		return nil, errors.Wrap(err, "appointmentRepo.Book.claimSlot")
+	}
+
+	booked := &models.Appointment{}
+	if err = tx.QueryRowxContext(ctx, createAppointmentQuery,
+		a.Id, a.SlotID, a.ServiceID, a.AgencyID, a.AgencyName, a.ServiceKind, a.ServiceName, a.StartsAt,
+		a.UserID, a.IdentityNo, a.FullName, a.Note, a.CreatedAt, a.UpdatedAt,
+	).StructScan(booked); err != nil {
+		return nil, errors.Wrap(err, "appointmentRepo.Book.StructScan")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "appointmentRepo.Book.Commit")
+	}
+	return booked, nil
+}
+
+// Move the appointment to the slot, the seat of its current slot is given back.
+// sql.ErrNoRows when the slot is full, closed or started, or the appointment was moved or closed meanwhile.
+func (r *appointmentRepo) Reschedule(ctx context.Context, a *models.Appointment, slot *models.AppointmentSlot) (*models.Appointment, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "appointmentRepo.Reschedule.BeginTxx")
+	}
+	defer tx.Rollback()
+
+	if err = claimSlot(ctx, tx, slot.Id); err != nil {
+		return nil, errors.Wrap(err, "appointmentRepo.Reschedule.claimSlot")
+	}
+
+	moved := &models.Appointment{}
+	if err = tx.QueryRowxContext(ctx, rescheduleQuery, slot.Id, slot.StartsAt, a.Id, a.SlotID).StructScan(moved); err != nil {
+		return nil, errors.Wrap(err, "appointmentRepo.Reschedule.StructScan")
+	}
+	if _, err = tx.ExecContext(ctx, releaseSlotQuery, a.SlotID); err != nil {
+		return nil, errors.Wrap(err, "appointmentRepo.Reschedule.releaseSlot")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "appointmentRepo.Reschedule.Commit")
+	}
+	return moved, nil
+}
+
+// Cancel the appointment and give its seat back.
+// sql.ErrNoRows when the appointment is no longer booked or already started.
+func (r *appointmentRepo) Cancel(ctx context.Context, appointmentID uuid.UUID) (*models.Appointment, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "appointmentRepo.Cancel.BeginTxx")
+	}
+	defer tx.Rollback()
+
+	cancelled := &models.Appointment{}
+	if err = tx.QueryRowxContext(ctx, cancelAppointmentQuery, appointmentID).StructScan(cancelled); err != nil {
+		return nil, errors.Wrap(err, "appointmentRepo.Cancel.StructScan")
+	}
+	if _, err = tx.ExecContext(ctx, releaseSlotQuery, cancelled.SlotID); err != nil {
+		return nil, errors.Wrap(err, "appointmentRepo.Cancel.releaseSlot")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "appointmentRepo.Cancel.Commit")
+	}
+	return cancelled, nil
+}
+
+// sql.ErrNoRows when the appointment is no longer booked
+func (r *appointmentRepo) CheckIn(ctx context.Context, appointmentID, officerID uuid.UUID) (*models.Appointment, error) {
+	a := &models.Appointment{}
+	if err := r.db.QueryRowxContext(ctx, checkInQuery, officerID, appointmentID).StructScan(a); err != nil {
+		return nil, errors.Wrap(err, "appointmentRepo.CheckIn.StructScan")
+	}
+	return a, nil
+}
+
+func (r *appointmentRepo) GetByID(ctx context.Context, appointmentID uuid.UUID) (*models.Appointment, error) {
+	a := &models.Appointment{}
+	if err := r.db.GetContext(ctx, a, getAppointmentByID, appointmentID); err != nil {
+		return nil, errors.Wrap(err, "appointmentRepo.GetByID.GetContext")
+	}
+	return a, nil
+}
+
+// Booked appointment of the user for the service, nil when there is none
+func (r *appointmentRepo) GetOpenByService(ctx context.Context, userID, serviceID uuid.UUID) (*models.Appointment, error) {
+	a := &models.Appointment{}
+	err := r.db.GetContext(ctx, a, getOpenByService, userID, serviceID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "appointmentRepo.GetOpenByService.GetContext")
+	}
+	return a, nil
+}
+
+func (r *appointmentRepo) GetByUser(ctx context.Context, userID uuid.UUID, pq *utils.PaginationQuery) (*models.AppointmentList, error) {
+	lc, err := pq.ListClause(appointmentListSpec, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := lc.Total(ctx, r.db, getTotalAppointmentsByUser, getAppointmentsByUser, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "appointmentRepo.GetByUser.total")
+	}
+
+	list := &models.AppointmentList{
+		TotalCount:   total,
+		TotalPages:   utils.GetTotalPage(total, pq.GetSize()),
+		Page:         pq.GetPage(),
+		Size:         pq.GetSize(),
+		HasMore:      utils.GetHasMore(pq.GetPage(), total, pq.GetSize()),
+		Appointments: []*models.Appointment{},
+	}
+
+	if lc.Empty(total) {
+		return list, nil
+	}
+
+	var items []*models.Appointment
+	if err := r.db.SelectContext(ctx, &items, lc.Page(getAppointmentsByUser), lc.PageArgs(pq, userID)...); err != nil {
+		return nil, errors.Wrap(err, "appointmentRepo.GetByUser.Select")
+	}
+
+	if list.Appointments, list.NextCursor, err = utils.NextCursor(lc, items); err != nil {
+		return nil, errors.Wrap(err, "appointmentRepo.GetByUser.NextCursor")
+	}
+	list.HasMore = lc.HasMore(pq, total, list.NextCursor)
+	return list, nil
+}
+
+// Appointments of the agency starting in [from, to)
+func (r *appointmentRepo) GetQueue(ctx context.Context, agencyID uuid.UUID, from, to time.Time) ([]*models.Appointment, error) {
+	queue := []*models.Appointment{}
+	if err := r.db.SelectContext(ctx, &queue, getQueue, agencyID, from, to); err != nil {
+		return nil, errors.Wrap(err, "appointmentRepo.GetQueue.SelectContext")
+	}
+	return queue, nil
+}
+
+// Booked appointments starting by the time and not reminded yet, marked as reminded
+func (r *appointmentRepo) ClaimDueReminders(ctx context.Context, before time.Time, limit int) ([]*models.Appointment, error) {
+	due := []*models.Appointment{}
+	if err := r.db.SelectContext(ctx, &due, claimDueRemindersQuery, before, limit); err != nil {
+		return nil, errors.Wrap(err, "appointmentRepo.ClaimDueReminders.SelectContext")
+	}
+	return due, nil
+}
+
+func (r *appointmentRepo) GetAgency(ctx context.Context, agencyID uuid.UUID) (*models.GovAgency, error) {
+	a := &models.GovAgency{}
+	if err := r.db.GetContext(ctx, a, getAgencyQuery, agencyID); err != nil {
+		return nil, errors.Wrap(err, "appointmentRepo.GetAgency.GetContext")
+	}
+	return a, nil
+}
+
+// sql.ErrNoRows when the slot has no seat left
+func claimSlot(ctx context.Context, tx *sqlx.Tx, slotID uuid.UUID) error {
+	res, err := tx.ExecContext(ctx, claimSlotQuery, slotID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
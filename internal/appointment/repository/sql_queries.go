@@ -0,0 +1,145 @@
+package repository
+
+import "github.com/adohong4/driving-license/pkg/utils"
+
+// Filters and sorts accepted by the appointment list of a citizen
+var appointmentListSpec = &utils.ListSpec{
+	Fields: map[string]utils.ListField{
+		"agency_id":    {Column: "a.agency_id", Type: utils.FilterUUID},
+		"service_id":   {Column: "a.service_id", Type: utils.FilterUUID},
+		"service_kind": {Column: "a.service_kind", Type: utils.FilterExact, Sortable: true, Keyset: true},
+		"status":       {Column: "a.status", Type: utils.FilterExact, Sortable: true, Keyset: true},
+		"starts_at":    {Column: "a.starts_at", Type: utils.FilterDate, Sortable: true, Keyset: true},
+		"created_at":   {Column: "a.created_at", Type: utils.FilterDate, Sortable: true, Keyset: true},
+	},
+	DefaultSort: "-starts_at",
+	IDColumn:    "a.id",
+}
+
+const (
+	createServiceQuery = `
+    INSERT INTO agency_services (id, agency_id, kind, name, duration_minutes, creator_id, created_at, updated_at, active)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, true)
+    RETURNING *
+    `
+
+	getServiceByID = `
+    SELECT * FROM agency_services WHERE id = $1 AND active = true
+    `
+
+	deleteServiceQuery = `
+    UPDATE agency_services SET active = false, updated_at = now() WHERE id = $1 AND active = true
+    RETURNING *
+    `
+
+	getAgencyServices = `
+    SELECT * FROM agency_services WHERE agency_id = $1 AND active = true ORDER BY kind, name
+    `
+
+	// slots published before are kept as they are
+	createSlotQuery = `
+    INSERT INTO appointment_slots (id, service_id, agency_id, starts_at, ends_at, capacity, booked, status, created_at, updated_at)
+    VALUES ($1, $2, $3, $4, $5, $6, 0, $7, $8, $9)
+    ON CONFLICT (service_id, starts_at) DO NOTHING
+    `
+
+	getSlotByID = `
+    SELECT * FROM appointment_slots WHERE id = $1
+    `
+
+	getServiceSlots = `
+    SELECT * FROM appointment_slots
+    WHERE service_id = $1 AND starts_at >= $2 AND starts_at < $3
+    ORDER BY starts_at
+    `
+
+	closeSlotQuery = `
+    UPDATE appointment_slots SET status = 'closed', updated_at = now()
+    WHERE id = $1 AND status = 'open'
+    RETURNING *
+    `
+
+	// a seat is taken only while the slot is open, ahead and not full, concurrent bookings of the last seat
+	// are serialized on the row and all but one find the slot full
+	claimSlotQuery = `
+    UPDATE appointment_slots SET booked = booked + 1, updated_at = now()
+    WHERE id = $1 AND status = 'open' AND starts_at > now() AND booked < capacity
+    `
+
+	releaseSlotQuery = `
+    UPDATE appointment_slots SET booked = booked - 1, updated_at = now()
+    WHERE id = $1 AND booked > 0
+    `
+
+	createAppointmentQuery = `
+    INSERT INTO appointments (
+        id, slot_id, service_id, agency_id, agency_name, service_kind, service_name, starts_at,
+        user_id, identity_no, full_name, note, status, created_at, updated_at
+    ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, 'booked', $13, $14)
+    RETURNING *
+    `
+
+	// a rescheduled appointment is reminded again
+	rescheduleQuery = `
+    UPDATE appointments
+    SET slot_id = $1, starts_at = $2, reminded_at = NULL, updated_at = now()
+    WHERE id = $3 AND slot_id = $4 AND status = 'booked'
+    RETURNING *
+    `
+
+	cancelAppointmentQuery = `
+    UPDATE appointments SET status = 'cancelled', updated_at = now()
+    WHERE id = $1 AND status = 'booked' AND starts_at > now()
+    RETURNING *
+    `
+
+	checkInQuery = `
+    UPDATE appointments
+    SET status = 'checked_in', checked_in_at = now(), checked_in_by = $1, updated_at = now()
+    WHERE id = $2 AND status = 'booked'
+    RETURNING *
+    `
+
+	getAppointmentByID = `
+    SELECT * FROM appointments WHERE id = $1
+    `
+
+	getOpenByService = `
+    SELECT * FROM appointments WHERE user_id = $1 AND service_id = $2 AND status = 'booked'
+    `
+
+	getAppointmentsByUser = `
+    SELECT a.*
+    FROM appointments a
+    WHERE a.user_id = $1
+    `
+
+	getTotalAppointmentsByUser = `
+    SELECT COUNT(*)
+    FROM appointments a
+    WHERE a.user_id = $1
+    `
+
+	getQueue = `
+    SELECT * FROM appointments
+    WHERE agency_id = $1 AND starts_at >= $2 AND starts_at < $3 AND status <> 'cancelled'
+    ORDER BY starts_at, created_at
+    `
+
+	// mark the reminders as sent before sending them, concurrent instances skip the rows claimed by another
+	claimDueRemindersQuery = `
+    UPDATE appointments SET reminded_at = now()
+    WHERE id IN (
+        SELECT id FROM appointments
+        WHERE status = 'booked' AND reminded_at IS NULL AND starts_at > now() AND starts_at <= $1
+        ORDER BY starts_at
+        LIMIT $2
+        FOR UPDATE SKIP LOCKED
+    )
+    RETURNING *
+    `
+
+	getAgencyQuery = `
+    SELECT * FROM gov_agencies WHERE id = $1 AND active = true
+    `
+)
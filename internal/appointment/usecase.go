@@ -0,0 +1,30 @@
+package appointment
+
+import (
+	"context"
+
+	"github.com/adohong4/driving-license/internal/models"
+	"github.com/adohong4/driving-license/pkg/utils"
+	"github.com/google/uuid"
+)
+
+type UseCase interface {
+	CreateService(ctx context.Context, req *models.CreateServiceRequest) (*models.AgencyService, error)
+	DeleteService(ctx context.Context, serviceID uuid.UUID) (*models.AgencyService, error)
+	GetAgencyServices(ctx context.Context, agencyID uuid.UUID) (*models.AgencyServiceList, error)
+	PublishSlots(ctx context.Context, serviceID uuid.UUID, req *models.PublishSlotsRequest) (*models.AppointmentSlotList, error)
+	GetServiceSlots(ctx context.Context, serviceID uuid.UUID, from, to string) (*models.AppointmentSlotList, error)
+	CloseSlot(ctx context.Context, slotID uuid.UUID) (*models.AppointmentSlot, error)
+
+	Book(ctx context.Context, req *models.BookAppointmentRequest) (*models.Appointment, error)
+	Reschedule(ctx context.Context, appointmentID uuid.UUID, req *models.RescheduleAppointmentRequest) (*models.Appointment, error)
+	Cancel(ctx context.Context, appointmentID uuid.UUID) (*models.Appointment, error)
+	GetMyAppointments(ctx context.Context, pq *utils.PaginationQuery) (*models.AppointmentList, error)
+	GetByID(ctx context.Context, appointmentID uuid.UUID) (*models.Appointment, error)
+
+	CheckIn(ctx context.Context, appointmentID uuid.UUID) (*models.Appointment, error)
+	GetQueue(ctx context.Context, agencyID uuid.UUID, date string) (*models.AppointmentQueue, error)
+
+	SendReminders(ctx context.Context) (int, error)
+	Run(ctx context.Context)
+}
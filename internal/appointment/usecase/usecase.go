@@ -0,0 +1,516 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/adohong4/driving-license/config"
+	"github.com/adohong4/driving-license/internal/appointment"
+	"github.com/adohong4/driving-license/internal/models"
+	"github.com/adohong4/driving-license/internal/notification"
+	"github.com/adohong4/driving-license/pkg/httpErrors"
+	"github.com/adohong4/driving-license/pkg/logger"
+	"github.com/adohong4/driving-license/pkg/utils"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// Notification code of reminders, rendered from the i18n catalogs
+const (
+	notiAppointmentReminder = "appointment_reminder"
+
+	notiTypeAppointment = "appointment"
+)
+
+const (
+	defaultReminderInterval = 5 * time.Minute
+	defaultReminderLead     = 24 * time.Hour
+
+	// reminders claimed per batch
+	reminderBatch = 100
+
+	// days of slots listed when no range is given, and at most
+	defaultSlotDays = 14
+	maxSlotDays     = 92
+)
+
+// Roles allowed to run agency calendars and check citizens in
+var officerRoles = map[string]bool{"admin": true, "officer": true}
+
+type appointmentUC struct {
+	cfg      *config.Config
+	apptRepo appointment.Repository
+	notiUC   notification.UseCase
+	logger   logger.Logger
+}
+
+func NewAppointmentUseCase(cfg *config.Config, apptRepo appointment.Repository, notiUC notification.UseCase, logger logger.Logger) appointment.UseCase {
+	return &appointmentUC{cfg: cfg, apptRepo: apptRepo, notiUC: notiUC, logger: logger}
+}
+
+// An officer publishes a service of an agency
+func (u *appointmentUC) CreateService(ctx context.Context, req *models.CreateServiceRequest) (*models.AgencyService, error) {
+	user, err := u.officerFromCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = utils.ValidateStruct(ctx, req); err != nil {
+		return nil, httpErrors.NewBadRequestError(errors.WithMessage(err, "appointmentUC.CreateService.ValidateStruct"))
+	}
+
+	agency, err := u.apptRepo.GetAgency(ctx, req.AgencyID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, httpErrors.NewRestError(http.StatusNotFound, "agency not found", nil)
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	return u.apptRepo.CreateService(ctx, &models.AgencyService{
+		Id:              uuid.New(),
+		AgencyID:        agency.Id,
+		Kind:            req.Kind,
+		Name:            strings.TrimSpace(req.Name),
+		DurationMinutes: req.DurationMinutes,
+		CreatorId:       user.Id,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	})
+}
+
+// An officer withdraws a service, appointments already booked are kept
+func (u *appointmentUC) DeleteService(ctx context.Context, serviceID uuid.UUID) (*models.AgencyService, error) {
+	if _, err := u.officerFromCtx(ctx); err != nil {
+		return nil, err
+	}
+	return u.apptRepo.DeleteService(ctx, serviceID)
+}
+
+func (u *appointmentUC) GetAgencyServices(ctx context.Context, agencyID uuid.UUID) (*models.AgencyServiceList, error) {
+	services, err := u.apptRepo.GetAgencyServices(ctx, agencyID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.AgencyServiceList{Services: services}, nil
+}
+
+// An officer publishes a calendar of slots of a service, slots published before are kept
+func (u *appointmentUC) PublishSlots(ctx context.Context, serviceID uuid.UUID, req *models.PublishSlotsRequest) (*models.AppointmentSlotList, error) {
+	if _, err := u.officerFromCtx(ctx); err != nil {
+		return nil, err
+	}
+	if err := utils.ValidateStruct(ctx, req); err != nil {
+		return nil, httpErrors.NewBadRequestError(errors.WithMessage(err, "appointmentUC.PublishSlots.ValidateStruct"))
+	}
+
+	service, err := u.apptRepo.GetServiceByID(ctx, serviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	slots, err := req.Slots(service, now)
+	if err != nil {
+		return nil, httpErrors.NewBadRequestError(err.Error())
+	}
+	if _, err = u.apptRepo.CreateSlots(ctx, slots); err != nil {
+		return nil, err
+	}
+
+	published, err := u.apptRepo.GetServiceSlots(ctx, serviceID, slots[0].StartsAt, slots[len(slots)-1].EndsAt)
+	if err != nil {
+		return nil, err
+	}
+	return &models.AppointmentSlotList{Slots: published}, nil
+}
+
+// Slots of a service between two dates, the next two weeks by default
+func (u *appointmentUC) GetServiceSlots(ctx context.Context, serviceID uuid.UUID, from, to string) (*models.AppointmentSlotList, error) {
+	start, end, err := slotRange(from, to, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if _, err = u.apptRepo.GetServiceByID(ctx, serviceID); err != nil {
+		return nil, err
+	}
+
+	slots, err := u.apptRepo.GetServiceSlots(ctx, serviceID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return &models.AppointmentSlotList{Slots: slots}, nil
+}
+
+// An officer closes a slot to new bookings, appointments already booked are kept
+func (u *appointmentUC) CloseSlot(ctx context.Context, slotID uuid.UUID) (*models.AppointmentSlot, error) {
+	if _, err := u.officerFromCtx(ctx); err != nil {
+		return nil, err
+	}
+	if _, err := u.apptRepo.GetSlotByID(ctx, slotID); err != nil {
+		return nil, err
+	}
+
+	slot, err := u.apptRepo.CloseSlot(ctx, slotID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, httpErrors.NewRestError(http.StatusConflict, "the slot is already closed", nil)
+		}
+		return nil, err
+	}
+	return slot, nil
+}
+
+// The citizen books a seat of a slot, one appointment per service at a time
+func (u *appointmentUC) Book(ctx context.Context, req *models.BookAppointmentRequest) (*models.Appointment, error) {
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return nil, httpErrors.NewUnauthorizedError(errors.WithMessage(err, "appointmentUC.Book.GetUserFromCtx"))
+	}
+	if err = utils.ValidateStruct(ctx, req); err != nil {
+		return nil, httpErrors.NewBadRequestError(errors.WithMessage(err, "appointmentUC.Book.ValidateStruct"))
+	}
+	if user.IdentityNo == "" {
+		return nil, httpErrors.NewBadRequestError("user identity number is missing")
+	}
+
+	slot, service, err := u.bookableSlot(ctx, req.SlotID)
+	if err != nil {
+		return nil, err
+	}
+
+	open, err := u.apptRepo.GetOpenByService(ctx, user.Id, service.Id)
+	if err != nil {
+		return nil, err
+	}
+	if open != nil {
+		return nil, httpErrors.NewRestError(http.StatusConflict,
+			fmt.Sprintf("you already have an appointment %s for this service, reschedule it instead", open.Id), nil)
+	}
+
+	agency, err := u.apptRepo.GetAgency(ctx, service.AgencyID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	booked, err := u.apptRepo.Book(ctx, &models.Appointment{
+		Id:          uuid.New(),
+		SlotID:      slot.Id,
+		ServiceID:   service.Id,
+		AgencyID:    agency.Id,
+		AgencyName:  agency.Name,
+		ServiceKind: service.Kind,
+		ServiceName: service.Name,
+		StartsAt:    slot.StartsAt,
+		UserID:      user.Id,
+		IdentityNo:  user.IdentityNo,
+		FullName:    user.FullName,
+		Note:        strings.TrimSpace(req.Note),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, slotFullError()
+		}
+		return nil, err
+	}
+	return booked, nil
+}
+
+// The citizen moves an appointment to another slot of its service before it starts
+func (u *appointmentUC) Reschedule(ctx context.Context, appointmentID uuid.UUID, req *models.RescheduleAppointmentRequest) (*models.Appointment, error) {
+	a, err := u.ownAppointment(ctx, appointmentID)
+	if err != nil {
+		return nil, err
+	}
+	if err = utils.ValidateStruct(ctx, req); err != nil {
+		return nil, httpErrors.NewBadRequestError(errors.WithMessage(err, "appointmentUC.Reschedule.ValidateStruct"))
+	}
+	if a.Status != models.AppointmentBooked {
+		return nil, appointmentStateError(a)
+	}
+	if !a.StartsAt.After(time.Now()) {
+		return nil, httpErrors.NewRestError(http.StatusConflict, "the appointment already started", nil)
+	}
+	if req.SlotID == a.SlotID {
+		return nil, httpErrors.NewBadRequestError("the appointment is already in this slot")
+	}
+
+	slot, _, err := u.bookableSlot(ctx, req.SlotID)
+	if err != nil {
+		return nil, err
+	}
+	if slot.ServiceID != a.ServiceID {
+		return nil, httpErrors.NewBadRequestError("the slot belongs to another service")
+	}
+
+	moved, err := u.apptRepo.Reschedule(ctx, a, slot)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, httpErrors.NewRestError(http.StatusConflict, "the slot is full or the appointment changed meanwhile", nil)
+		}
+		return nil, err
+	}
+	return moved, nil
+}
+
+// The citizen cancels an appointment before it starts
+func (u *appointmentUC) Cancel(ctx context.Context, appointmentID uuid.UUID) (*models.Appointment, error) {
+	a, err := u.ownAppointment(ctx, appointmentID)
+	if err != nil {
+		return nil, err
+	}
+	if a.Status != models.AppointmentBooked {
+		return nil, appointmentStateError(a)
+	}
+
+	cancelled, err := u.apptRepo.Cancel(ctx, appointmentID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, httpErrors.NewRestError(http.StatusConflict, "the appointment is no longer booked or already started", nil)
+		}
+		return nil, err
+	}
+	return cancelled, nil
+}
+
+// Appointments of the current user
+func (u *appointmentUC) GetMyAppointments(ctx context.Context, pq *utils.PaginationQuery) (*models.AppointmentList, error) {
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return nil, httpErrors.NewUnauthorizedError(err)
+	}
+	return u.apptRepo.GetByUser(ctx, user.Id, pq)
+}
+
+// Appointment of the citizen, officers read any appointment
+func (u *appointmentUC) GetByID(ctx context.Context, appointmentID uuid.UUID) (*models.Appointment, error) {
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return nil, httpErrors.NewUnauthorizedError(errors.WithMessage(err, "appointmentUC.GetByID.GetUserFromCtx"))
+	}
+
+	a, err := u.apptRepo.GetByID(ctx, appointmentID)
+	if err != nil {
+		return nil, err
+	}
+	if !isOfficer(user) && a.UserID != user.Id {
+		return nil, httpErrors.NewRestError(http.StatusNotFound, "appointment not found", nil)
+	}
+	return a, nil
+}
+
+// Staff check the citizen in on the day of the appointment
+func (u *appointmentUC) CheckIn(ctx context.Context, appointmentID uuid.UUID) (*models.Appointment, error) {
+	user, err := u.officerFromCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	a, err := u.apptRepo.GetByID(ctx, appointmentID)
+	if err != nil {
+		return nil, err
+	}
+	if a.Status != models.AppointmentBooked {
+		return nil, appointmentStateError(a)
+	}
+	if a.StartsAt.In(time.Local).Format(time.DateOnly) != time.Now().Format(time.DateOnly) {
+		return nil, httpErrors.NewBadRequestError(fmt.Sprintf("the appointment is on %s, citizens check in on the day",
+			a.StartsAt.In(time.Local).Format(time.DateOnly)))
+	}
+
+	checkedIn, err := u.apptRepo.CheckIn(ctx, appointmentID, user.Id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, httpErrors.NewRestError(http.StatusConflict, "the appointment is no longer booked", nil)
+		}
+		return nil, err
+	}
+	return checkedIn, nil
+}
+
+// Appointments of an agency on a day, today by default
+func (u *appointmentUC) GetQueue(ctx context.Context, agencyID uuid.UUID, date string) (*models.AppointmentQueue, error) {
+	if _, err := u.officerFromCtx(ctx); err != nil {
+		return nil, err
+	}
+
+	day := time.Now()
+	if date != "" {
+		var err error
+		if day, err = time.ParseInLocation(time.DateOnly, date, time.Local); err != nil {
+			return nil, httpErrors.NewBadRequestError("date must be in YYYY-MM-DD format")
+		}
+	}
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.Local)
+
+	queue, err := u.apptRepo.GetQueue(ctx, agencyID, start, start.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	return models.NewAppointmentQueue(agencyID, start.Format(time.DateOnly), queue), nil
+}
+
+// Remind the citizens whose appointment starts within the reminder lead, the number of reminders sent.
+// Reminders are claimed before they are sent, a failed notification is logged and not retried.
+func (u *appointmentUC) SendReminders(ctx context.Context) (int, error) {
+	lead := defaultReminderLead
+	if u.cfg.Appointments.ReminderLead > 0 {
+		lead = time.Duration(u.cfg.Appointments.ReminderLead) * time.Minute
+	}
+
+	sent := 0
+	for {
+		due, err := u.apptRepo.ClaimDueReminders(ctx, time.Now().Add(lead), reminderBatch)
+		if err != nil {
+			return sent, err
+		}
+		for _, a := range due {
+			if u.remind(ctx, a) {
+				sent++
+			}
+		}
+		if len(due) < reminderBatch {
+			return sent, nil
+		}
+	}
+}
+
+// Send reminders every ReminderInterval until ctx is done
+func (u *appointmentUC) Run(ctx context.Context) {
+	interval := defaultReminderInterval
+	if u.cfg.Appointments.ReminderInterval > 0 {
+		interval = time.Duration(u.cfg.Appointments.ReminderInterval) * time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := u.SendReminders(ctx); err != nil {
+				u.logger.Errorf("appointmentUC.Run.SendReminders: %v", err)
+			}
+		}
+	}
+}
+
+func (u *appointmentUC) remind(ctx context.Context, a *models.Appointment) bool {
+	n := &models.Notification{
+		Code:       notiAppointmentReminder,
+		Type:       notiTypeAppointment,
+		Target:     "personal",
+		TargetUser: a.IdentityNo,
+		Status:     "unread",
+		Params: models.NotificationParams{
+			"appointment_id": a.Id.String(),
+			"agency_name":    a.AgencyName,
+			"service_name":   a.ServiceName,
+			"date":           a.StartsAt.In(time.Local).Format(time.DateOnly),
+			"time":           a.StartsAt.In(time.Local).Format("15:04"),
+		},
+	}
+	if _, err := u.notiUC.CreateNotification(ctx, n); err != nil {
+		u.logger.Errorf("appointmentUC.remind %s: %v", a.Id, err)
+		return false
+	}
+	return true
+}
+
+// Slot open for booking and its service
+func (u *appointmentUC) bookableSlot(ctx context.Context, slotID uuid.UUID) (*models.AppointmentSlot, *models.AgencyService, error) {
+	slot, err := u.apptRepo.GetSlotByID(ctx, slotID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, httpErrors.NewRestError(http.StatusNotFound, "slot not found", nil)
+		}
+		return nil, nil, err
+	}
+	if !slot.Bookable(time.Now()) {
+		return nil, nil, slotFullError()
+	}
+
+	service, err := u.apptRepo.GetServiceByID(ctx, slot.ServiceID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, httpErrors.NewRestError(http.StatusConflict, "the service is no longer offered", nil)
+		}
+		return nil, nil, err
+	}
+	return slot, service, nil
+}
+
+// Appointment of the current user, other users' appointments are not found
+func (u *appointmentUC) ownAppointment(ctx context.Context, appointmentID uuid.UUID) (*models.Appointment, error) {
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return nil, httpErrors.NewUnauthorizedError(err)
+	}
+
+	a, err := u.apptRepo.GetByID(ctx, appointmentID)
+	if err != nil {
+		return nil, err
+	}
+	if a.UserID != user.Id {
+		return nil, httpErrors.NewRestError(http.StatusNotFound, "appointment not found", nil)
+	}
+	return a, nil
+}
+
+func (u *appointmentUC) officerFromCtx(ctx context.Context) (*models.User, error) {
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return nil, httpErrors.NewUnauthorizedError(err)
+	}
+	if !isOfficer(user) {
+		return nil, httpErrors.NewForbiddenError(httpErrors.PermissionDenied)
+	}
+	return user, nil
+}
+
+// [from, to] dates as a time range, from today for the default number of days when empty
+func slotRange(from, to string, now time.Time) (time.Time, time.Time, error) {
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	if from != "" {
+		var err error
+		if start, err = time.ParseInLocation(time.DateOnly, from, time.Local); err != nil {
+			return start, start, httpErrors.NewBadRequestError("from must be in YYYY-MM-DD format")
+		}
+	}
+	end := start.AddDate(0, 0, defaultSlotDays)
+	if to != "" {
+		last, err := time.ParseInLocation(time.DateOnly, to, time.Local)
+		if err != nil {
+			return start, start, httpErrors.NewBadRequestError("to must be in YYYY-MM-DD format")
+		}
+		end = last.AddDate(0, 0, 1)
+	}
+	if !end.After(start) {
+		return start, end, httpErrors.NewBadRequestError("to must not be before from")
+	}
+	if end.Sub(start) > maxSlotDays*24*time.Hour {
+		return start, end, httpErrors.NewBadRequestError(fmt.Sprintf("slots are listed for at most %d days", maxSlotDays))
+	}
+	return start, end, nil
+}
+
+func isOfficer(user *models.User) bool {
+	return user.Role != nil && officerRoles[*user.Role]
+}
+
+func slotFullError() error {
+	return httpErrors.NewRestError(http.StatusConflict, "the slot is full, closed or already started", nil)
+}
+
+func appointmentStateError(a *models.Appointment) error {
+	return httpErrors.NewRestError(http.StatusConflict, fmt.Sprintf("the appointment is %s", a.Status), nil)
+}
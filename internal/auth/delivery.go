@@ -12,6 +12,7 @@ type Handlers interface {
 	Delete() echo.HandlerFunc
 	FindByIdentityNO() echo.HandlerFunc
 	GetUsers() echo.HandlerFunc
+	ExportUsers() echo.HandlerFunc
 	GetMe() echo.HandlerFunc
 	GetIdentityAndNameByWallet() echo.HandlerFunc
 	CheckWalletLinked() echo.HandlerFunc
@@ -6,6 +6,8 @@ import (
 
 	"github.com/adohong4/driving-license/config"
 	"github.com/adohong4/driving-license/internal/auth"
+	exportjob "github.com/adohong4/driving-license/internal/export_job"
+	exportHttp "github.com/adohong4/driving-license/internal/export_job/delivery/http"
 	"github.com/adohong4/driving-license/internal/models"
 	"github.com/adohong4/driving-license/pkg/httpErrors"
 	"github.com/adohong4/driving-license/pkg/logger"
@@ -17,13 +19,14 @@ import (
 
 // Auth handlers
 type authHandlers struct {
-	cfg    *config.Config
-	authUC auth.UseCase
-	logger logger.Logger
+	cfg         *config.Config
+	authUC      auth.UseCase
+	exportJobUC exportjob.UseCase
+	logger      logger.Logger
 }
 
-func NewAuthHandlers(cfg *config.Config, authUC auth.UseCase, log logger.Logger) auth.Handlers {
-	return &authHandlers{cfg: cfg, authUC: authUC, logger: log}
+func NewAuthHandlers(cfg *config.Config, authUC auth.UseCase, exportJobUC exportjob.UseCase, log logger.Logger) auth.Handlers {
+	return &authHandlers{cfg: cfg, authUC: authUC, exportJobUC: exportJobUC, logger: log}
 }
 
 // CreateUser godoc
@@ -34,8 +37,8 @@ func NewAuthHandlers(cfg *config.Config, authUC auth.UseCase, log logger.Logger)
 // @Produce      json
 // @Param        user  body      models.User  true  "User information"
 // @Success      200   {object}  models.UserWithToken
-// @Failure      400   {object}  httpErrors.RestError  "Invalid input"
-// @Failure      500   {object}  httpErrors.RestError  "Server error"
+// @Failure      400   {object}  httpErrors.Problem    "Invalid input"
+// @Failure      500   {object}  httpErrors.Problem    "Server error"
 // @Router       /auth/create [post]
 // @Security     BearerAuth
 func (h *authHandlers) CreateUser() echo.HandlerFunc {
@@ -79,9 +82,9 @@ func (h *authHandlers) CreateUser() echo.HandlerFunc {
 // @Produce      json
 // @Param        request  body      models.User  true  "Login credentials"
 // @Success      200      {object}  models.UserWithToken
-// @Failure      400      {object}  httpErrors.RestError  "Invalid request"
-// @Failure      401      {object}  httpErrors.RestError  "Invalid credentials"
-// @Failure      500      {object}  httpErrors.RestError
+// @Failure      400      {object}  httpErrors.Problem    "Invalid request"
+// @Failure      401      {object}  httpErrors.Problem    "Invalid credentials"
+// @Failure      500      {object}  httpErrors.Problem
 // @Router       /auth/login [post]
 func (h *authHandlers) Login() echo.HandlerFunc {
 	type Login struct {
@@ -138,7 +141,7 @@ func (h *authHandlers) ConnectWallet() echo.HandlerFunc {
 // @Accept 		 json
 // @Produce      json
 // @Success      200  {string}  string  "ok"
-// @Failure      401  {object}  httpErrors.RestError
+// @Failure      401  {object}  httpErrors.Problem
 // @Router       /auth/logout [post]
 // @Security     BearerAuth
 func (h *authHandlers) Logout() echo.HandlerFunc {
@@ -158,10 +161,10 @@ func (h *authHandlers) Logout() echo.HandlerFunc {
 // @Param        id    path     string       true  "User ID (UUID)"
 // @Param        user  body     models.User  true  "Updated user data"
 // @Success      200   {object}  models.User
-// @Failure      400   {object}  httpErrors.RestError
-// @Failure      403   {object}  httpErrors.RestError  "Forbidden"
-// @Failure      404   {object}  httpErrors.RestError  "User not found"
-// @Failure      500   {object}  httpErrors.RestError
+// @Failure      400   {object}  httpErrors.Problem
+// @Failure      403   {object}  httpErrors.Problem    "Forbidden"
+// @Failure      404   {object}  httpErrors.Problem    "User not found"
+// @Failure      500   {object}  httpErrors.Problem
 // @Router       /auth/{id} [put]
 // @Security     BearerAuth
 func (h *authHandlers) Update() echo.HandlerFunc {
@@ -199,9 +202,9 @@ func (h *authHandlers) Update() echo.HandlerFunc {
 // @Produce      json
 // @Param        id   path      string  true  "User ID (UUID)"
 // @Success      200  {object}  models.User
-// @Failure      400  {object}  httpErrors.RestError  "Invalid ID"
-// @Failure      404  {object}  httpErrors.RestError  "User not found"
-// @Failure      500  {object}  httpErrors.RestError
+// @Failure      400  {object}  httpErrors.Problem    "Invalid ID"
+// @Failure      404  {object}  httpErrors.Problem    "User not found"
+// @Failure      500  {object}  httpErrors.Problem
 // @Router       /auth/{id} [get]
 // @Security     BearerAuth
 func (h *authHandlers) GetUserByID() echo.HandlerFunc {
@@ -232,10 +235,10 @@ func (h *authHandlers) GetUserByID() echo.HandlerFunc {
 // @Param        id       path      string  true   "User ID (UUID)"
 // @Param        version  query     int     false  "Optimistic lock version (optional)"
 // @Success      200      {object}  object{message=string}  "User deleted successfully"
-// @Failure      400      {object}  httpErrors.RestError
-// @Failure      403      {object}  httpErrors.RestError  "Forbidden"
-// @Failure      409      {object}  httpErrors.RestError  "Conflict (version mismatch)"
-// @Failure      500      {object}  httpErrors.RestError
+// @Failure      400      {object}  httpErrors.Problem
+// @Failure      403      {object}  httpErrors.Problem    "Forbidden"
+// @Failure      409      {object}  httpErrors.Problem    "Conflict (version mismatch)"
+// @Failure      500      {object}  httpErrors.Problem
 // @Router       /auth/{id} [delete]
 // @Security     BearerAuth
 func (h *authHandlers) Delete() echo.HandlerFunc {
@@ -287,10 +290,11 @@ func (h *authHandlers) Delete() echo.HandlerFunc {
 // @Param        identity_no  query     string  true   "Identity number (full or partial)"
 // @Param        page         query     int     false  "Page number"      default(1)
 // @Param        size         query     int     false  "Page size"        default(10)
+// @Param        sort         query     string  false  "Sort fields, comma separated, - prefix for descending, e.g. -created_at"
 // @Param        orderBy      query     string  false  "Sort field"
 // @Success      200          {object}  models.UsersList
-// @Failure      400          {object}  httpErrors.RestError
-// @Failure      500          {object}  httpErrors.RestError
+// @Failure      400          {object}  httpErrors.Problem
+// @Failure      500          {object}  httpErrors.Problem
 // @Router       /auth/find [get]
 // @Security     BearerAuth
 func (h *authHandlers) FindByIdentityNO() echo.HandlerFunc {
@@ -318,6 +322,36 @@ func (h *authHandlers) FindByIdentityNO() echo.HandlerFunc {
 	}
 }
 
+// ExportUsers godoc
+// @Summary      Export users
+// @Description  Exports users as CSV or XLSX with the same identity number filter, list filters and sort as find. Large exports run as a background job and return 202 with the job.
+// @Tags         Auth
+// @Produce      text/csv
+// @Produce      application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param        identity_no  query     string  false  "Identity number or role (partial)"
+// @Param        sort         query     string  false  "Sort fields, comma separated, - prefix for descending, e.g. -created_at"
+// @Param        format       query     string  false  "csv or xlsx, defaults to the Accept header or csv"
+// @Param        lang         query     string  false  "Header language vi or en, defaults to Accept-Language or vi"
+// @Param        async        query     bool    false  "Always run as a background job"
+// @Success      200          {file}    file
+// @Success      202          {object}  models.ExportJob
+// @Failure      400          {object}  httpErrors.Problem
+// @Failure      401          {object}  httpErrors.Problem
+// @Router       /auth/export [get]
+// @Security     JWT
+func (h *authHandlers) ExportUsers() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		eq, err := utils.GetExportQueryFromCtx(c, "identity_no")
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(httpErrors.NewBadRequestError(err.Error())))
+		}
+
+		src := h.authUC.ExportUsers(c.Request().Context(), eq)
+		return exportHttp.Respond(c, h.exportJobUC, eq, src, h.logger)
+	}
+}
+
 // GetUsers godoc
 // @Summary      List all users (paginated)
 // @Description  Retrieve a paginated list of all users (admin only)
@@ -325,11 +359,12 @@ func (h *authHandlers) FindByIdentityNO() echo.HandlerFunc {
 // @Produce      json
 // @Param        page     query     int     false  "Page number"  default(1)
 // @Param        size     query     int     false  "Page size"    default(10)
+// @Param        sort     query     string  false  "Sort fields, comma separated, - prefix for descending, e.g. -created_at"
 // @Param        orderBy  query     string  false  "Sort field"
 // @Success      200      {object}  models.UsersList
-// @Failure      400      {object}  httpErrors.RestError
-// @Failure      403      {object}  httpErrors.RestError  "Forbidden"
-// @Failure      500      {object}  httpErrors.RestError
+// @Failure      400      {object}  httpErrors.Problem
+// @Failure      403      {object}  httpErrors.Problem    "Forbidden"
+// @Failure      500      {object}  httpErrors.Problem
 // @Router       /auth/all [get]
 // @Security     BearerAuth
 func (h *authHandlers) GetUsers() echo.HandlerFunc {
@@ -358,8 +393,8 @@ func (h *authHandlers) GetUsers() echo.HandlerFunc {
 // @Tags         Auth
 // @Produce      json
 // @Success      200  {object}  models.User
-// @Failure      401  {object}  httpErrors.RestError  "Unauthorized"
-// @Failure      500  {object}  httpErrors.RestError
+// @Failure      401  {object}  httpErrors.Problem    "Unauthorized"
+// @Failure      500  {object}  httpErrors.Problem
 // @Router       /auth/me [get]
 // @Security     BearerAuth
 func (h *authHandlers) GetMe() echo.HandlerFunc {
@@ -381,9 +416,9 @@ func (h *authHandlers) GetMe() echo.HandlerFunc {
 // @Produce      json
 // @Param        user_address  query     string  true  "Wallet address (Ethereum address)"
 // @Success      200  {object}  map[string]string  "identity_no and full_name"
-// @Failure      400  {object}  httpErrors.RestError  "Invalid wallet address"
-// @Failure      404  {object}  httpErrors.RestError  "User not found"
-// @Failure      500  {object}  httpErrors.RestError
+// @Failure      400  {object}  httpErrors.Problem    "Invalid wallet address"
+// @Failure      404  {object}  httpErrors.Problem    "User not found"
+// @Failure      500  {object}  httpErrors.Problem
 // @Router       /auth/wallet-info [get]
 func (h *authHandlers) GetIdentityAndNameByWallet() echo.HandlerFunc {
 	return func(c echo.Context) error {
@@ -413,8 +448,8 @@ func (h *authHandlers) GetIdentityAndNameByWallet() echo.HandlerFunc {
 // @Produce      json
 // @Param        identity_no  query     string  true  "Identity number (CCCD)"
 // @Success      200  {object}  map[string]bool  "linked: true/false"
-// @Failure      400  {object}  httpErrors.RestError
-// @Failure      500  {object}  httpErrors.RestError
+// @Failure      400  {object}  httpErrors.Problem
+// @Failure      500  {object}  httpErrors.Problem
 // @Router       /auth/check-wallet [get]
 func (h *authHandlers) CheckWalletLinked() echo.HandlerFunc {
 	return func(c echo.Context) error {
@@ -444,9 +479,9 @@ func (h *authHandlers) CheckWalletLinked() echo.HandlerFunc {
 // @Produce      json
 // @Param        request  body      models.User  true  "Link wallet request"
 // @Success      200  {object}  map[string]string  "success message"
-// @Failure      400  {object}  httpErrors.RestError
-// @Failure      404  {object}  httpErrors.RestError  "User not found"
-// @Failure      500  {object}  httpErrors.RestError
+// @Failure      400  {object}  httpErrors.Problem
+// @Failure      404  {object}  httpErrors.Problem    "User not found"
+// @Failure      500  {object}  httpErrors.Problem
 // @Router       /auth/link-wallet [post]
 // @Security     BearerAuth
 func (h *authHandlers) LinkWallet() echo.HandlerFunc {
@@ -480,9 +515,9 @@ func (h *authHandlers) LinkWallet() echo.HandlerFunc {
 // @Produce      json
 // @Param        identity_no  query     string  true  "Identity number (CCCD)"
 // @Success      200  {object}  map[string]string  "success message"
-// @Failure      400  {object}  httpErrors.RestError
-// @Failure      404  {object}  httpErrors.RestError  "User not found or no wallet linked"
-// @Failure      500  {object}  httpErrors.RestError
+// @Failure      400  {object}  httpErrors.Problem
+// @Failure      404  {object}  httpErrors.Problem    "User not found or no wallet linked"
+// @Failure      500  {object}  httpErrors.Problem
 // @Router       /auth/unlink-wallet [post]
 // @Security     BearerAuth
 func (h *authHandlers) UnlinkWallet() echo.HandlerFunc {
@@ -16,6 +16,7 @@ func MapAuthRoutes(authGroup *echo.Group, h auth.Handlers, mw *middleware.Middle
 	authGroup.GET("/find/", h.FindByIdentityNO())
 	authGroup.DELETE("/delete/:id", h.Delete(), mw.AuthJWTMiddleware(authUC, cfg))
 	authGroup.GET("/all", h.GetUsers())
+	authGroup.GET("/export", h.ExportUsers(), mw.AuthJWTMiddleware(authUC, cfg))
 	authGroup.GET("/:id", h.GetUserByID())
 	authGroup.GET("/me", h.GetMe(), mw.AuthJWTMiddleware(authUC, cfg))
 
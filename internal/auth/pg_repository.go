@@ -15,6 +15,8 @@ type Repository interface {
 	GetUserById(ctx context.Context, id uuid.UUID) (*models.User, error)
 	FindByIdentityNO(ctx context.Context, identity string, query *utils.PaginationQuery) (*models.UsersList, error)
 	GetUsers(ctx context.Context, pq *utils.PaginationQuery) (*models.UsersList, error)
+	CountUsers(ctx context.Context, identity string, query *utils.PaginationQuery) (int, error)
+	StreamUsers(ctx context.Context, identity string, query *utils.PaginationQuery, fn func(user *models.User) error) error
 	FindByIdentity(ctx context.Context, user *models.User) (*models.User, error)
 	FindByUserAddress(ctx context.Context, user *models.User) (*models.User, error)
 	GetUserIdentityAndNameByAddress(ctx context.Context, userAddress string) (identityNo, fullName string, err error)
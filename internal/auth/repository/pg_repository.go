@@ -72,9 +72,55 @@ func (r *authRepo) GetUserById(ctx context.Context, id uuid.UUID) (*models.User,
 	return user, nil
 }
 
+func (r *authRepo) CountUsers(ctx context.Context, identity string, query *utils.PaginationQuery) (int, error) {
+	lc, err := query.ListClause(userListSpec, 1, "identity_no")
+	if err != nil {
+		return 0, err
+	}
+
+	var totalCount int
+	if err := r.db.GetContext(ctx, &totalCount, lc.Count(getTotalCount), lc.Args(identity)...); err != nil {
+		return 0, errors.Wrap(err, "authRepo.CountUsers.GetContext")
+	}
+	return totalCount, nil
+}
+
+func (r *authRepo) StreamUsers(ctx context.Context, identity string, query *utils.PaginationQuery, fn func(user *models.User) error) error {
+	lc, err := query.ListClause(userListSpec, 1, "identity_no")
+	if err != nil {
+		return err
+	}
+
+	rows, err := r.db.QueryxContext(ctx, lc.All(exportUsers), lc.Args(identity)...)
+	if err != nil {
+		return errors.Wrap(err, "authRepo.StreamUsers.QueryxContext")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		user := &models.User{}
+		if err = rows.StructScan(user); err != nil {
+			return errors.Wrap(err, "authRepo.StreamUsers.StructScan")
+		}
+		if err = fn(user); err != nil {
+			return err
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return errors.Wrap(err, "authRepo.StreamUsers.rows.Err")
+	}
+	return nil
+}
+
 func (r *authRepo) FindByIdentityNO(ctx context.Context, identity string, query *utils.PaginationQuery) (*models.UsersList, error) {
+	lc, err := query.ListClause(userListSpec, 1, "identity_no")
+	if err != nil {
+		return nil, err
+	}
+
 	var totalCount int
-	if err := r.db.GetContext(ctx, &totalCount, getTotalCount, identity); err != nil {
+	if err := r.db.GetContext(ctx, &totalCount, lc.Count(getTotalCount), lc.Args(identity)...); err != nil {
 		return nil, errors.Wrap(err, "authRepo.FindByIdentityNO.GetContext.totalCount")
 	}
 
@@ -90,7 +136,7 @@ func (r *authRepo) FindByIdentityNO(ctx context.Context, identity string, query
 	}
 
 	var users []*models.User
-	if err := r.db.SelectContext(ctx, &users, findUsers, identity, query.GetOffset(), query.GetLimit()); err != nil {
+	if err := r.db.SelectContext(ctx, &users, lc.Page(findUsers), lc.PageArgs(query, identity)...); err != nil {
 		return nil, errors.Wrap(err, "authRepo.FindByIdentityNO.SelectContext")
 	}
 
@@ -105,8 +151,13 @@ func (r *authRepo) FindByIdentityNO(ctx context.Context, identity string, query
 }
 
 func (r *authRepo) GetUsers(ctx context.Context, pq *utils.PaginationQuery) (*models.UsersList, error) {
+	lc, err := pq.ListClause(userListSpec, 0)
+	if err != nil {
+		return nil, err
+	}
+
 	var totalCount int
-	if err := r.db.GetContext(ctx, &totalCount, getTotal); err != nil {
+	if err := r.db.GetContext(ctx, &totalCount, lc.Count(getTotal), lc.Args()...); err != nil {
 		return nil, errors.Wrap(err, "authRepo.GetUsers.GetContext.totalCount")
 	}
 
@@ -122,7 +173,7 @@ func (r *authRepo) GetUsers(ctx context.Context, pq *utils.PaginationQuery) (*mo
 	}
 
 	var users []*models.User
-	if err := r.db.SelectContext(ctx, &users, getUsers, pq.GetOrderBy(), pq.GetOffset(), pq.GetLimit()); err != nil {
+	if err := r.db.SelectContext(ctx, &users, lc.Page(getUsers), lc.PageArgs(pq)...); err != nil {
 		return nil, errors.Wrap(err, "authRepo.GetUsers.SelectContext")
 	}
 
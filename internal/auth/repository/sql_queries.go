@@ -1,5 +1,24 @@
 package repository
 
+import "github.com/adohong4/driving-license/pkg/utils"
+
+// Filters and sorts accepted by the user lists, filter and sort clauses are appended to the queries below
+var userListSpec = &utils.ListSpec{
+	Fields: map[string]utils.ListField{
+		"identity_no":        {Column: "identity_no", Type: utils.FilterText, Sortable: true},
+		"full_name":          {Column: "full_name", Type: utils.FilterText, Sortable: true},
+		"user_address":       {Column: "user_address", Type: utils.FilterExact},
+		"gender":             {Column: "gender", Type: utils.FilterExact},
+		"nationality":        {Column: "nationality", Type: utils.FilterExact, Sortable: true},
+		"place_of_origin":    {Column: "place_of_origin", Type: utils.FilterText},
+		"place_of_residence": {Column: "place_of_residence", Type: utils.FilterText},
+		"role":               {Column: "role", Type: utils.FilterExact, Sortable: true},
+		"created_at":         {Column: "created_at", Type: utils.FilterDate, Sortable: true},
+		"updated_at":         {Column: "updated_at", Type: utils.FilterDate, Sortable: true},
+	},
+	DefaultSort: "identity_no,role",
+}
+
 const (
 	createUserQuery = `
         INSERT INTO users (
@@ -56,9 +75,14 @@ const (
         SELECT *
         FROM users 
         WHERE active = true 
-        AND (identity_no ILIKE '%' || $1 || '%' OR role ILIKE '%' || $1 || '%')
-        ORDER BY identity_no, role
-        OFFSET $2 LIMIT $3`
+        AND (identity_no ILIKE '%' || $1 || '%' OR role ILIKE '%' || $1 || '%')`
+
+	// Export, same filter as findUsers without pagination
+	exportUsers = `
+        SELECT *
+        FROM users 
+        WHERE active = true 
+        AND (identity_no ILIKE '%' || $1 || '%' OR role ILIKE '%' || $1 || '%')`
 
 	getTotal = `
         SELECT COUNT(id) 
@@ -68,9 +92,7 @@ const (
 	getUsers = `
         SELECT *
         FROM users 
-        WHERE active = true 
-        ORDER BY COALESCE(NULLIF($1, ''), identity_no), role
-        OFFSET $2 LIMIT $3`
+        WHERE active = true`
 
 	findUserByIdentity = `
         SELECT *
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/adohong4/driving-license/internal/models"
+	"github.com/adohong4/driving-license/pkg/export"
 	"github.com/adohong4/driving-license/pkg/utils"
 	"github.com/google/uuid"
 )
@@ -15,6 +16,7 @@ type UseCase interface {
 	GetByID(ctx context.Context, Id uuid.UUID) (*models.User, error)
 	FindByIdentity(ctx context.Context, identity string, query *utils.PaginationQuery) (*models.UsersList, error)
 	GetUsers(ctx context.Context, pq *utils.PaginationQuery) (*models.UsersList, error)
+	ExportUsers(ctx context.Context, eq *utils.ExportQuery) *export.Source
 	Login(ctx context.Context, user *models.User) (*models.UserWithToken, error)
 	ConnectWallet(ctx context.Context, user *models.User) (*models.UserWithToken, error)
 	GetIdentityAndNameByWallet(ctx context.Context, walletAddress string) (identityNo, fullName string, err error)
@@ -9,6 +9,7 @@ import (
 	"github.com/adohong4/driving-license/config"
 	"github.com/adohong4/driving-license/internal/auth"
 	"github.com/adohong4/driving-license/internal/models"
+	"github.com/adohong4/driving-license/pkg/export"
 	"github.com/adohong4/driving-license/pkg/httpErrors"
 	"github.com/adohong4/driving-license/pkg/logger"
 	"github.com/adohong4/driving-license/pkg/utils"
@@ -67,6 +68,10 @@ func (u *authUC) Update(ctx context.Context, user *models.User) (*models.User, e
 		return nil, httpErrors.NewBadRequestError(errors.Wrap(err, "authUC.Update.PrepareUpdate"))
 	}
 
+	if err := utils.ValidateStruct(ctx, user); err != nil {
+		return nil, httpErrors.NewBadRequestError(errors.WithMessage(err, "authUC.Update.ValidateStruct"))
+	}
+
 	updatedUser, err := u.authRepo.Update(ctx, user)
 	if err != nil {
 		return nil, err
@@ -103,6 +108,22 @@ func (u *authUC) GetUsers(ctx context.Context, pq *utils.PaginationQuery) (*mode
 	return u.authRepo.GetUsers(ctx, pq)
 }
 
+// Export source for the user list, filtered like FindByIdentityNO
+func (u *authUC) ExportUsers(ctx context.Context, eq *utils.ExportQuery) *export.Source {
+	return &export.Source{
+		Name:    "users",
+		Columns: models.UserExportColumns,
+		Count: func(ctx context.Context) (int, error) {
+			return u.authRepo.CountUsers(ctx, eq.Search, eq.ListQuery())
+		},
+		Rows: func(ctx context.Context, fn func(row []string) error) error {
+			return u.authRepo.StreamUsers(ctx, eq.Search, eq.ListQuery(), func(user *models.User) error {
+				return fn(user.ExportRow())
+			})
+		},
+	}
+}
+
 // Login user, return user model with jwt token
 func (u *authUC) Login(ctx context.Context, user *models.User) (*models.UserWithToken, error) {
 	foundUser, err := u.authRepo.FindByIdentity(ctx, user)
@@ -0,0 +1,7 @@
+package dashboard
+
+import "github.com/labstack/echo/v4"
+
+type Handlers interface {
+	GetMyDashboard() echo.HandlerFunc
+}
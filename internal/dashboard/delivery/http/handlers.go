@@ -0,0 +1,46 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/adohong4/driving-license/config"
+	"github.com/adohong4/driving-license/internal/dashboard"
+	"github.com/adohong4/driving-license/pkg/httpErrors"
+	"github.com/adohong4/driving-license/pkg/logger"
+	"github.com/adohong4/driving-license/pkg/utils"
+	"github.com/labstack/echo/v4"
+)
+
+type dashboardHandlers struct {
+	cfg         *config.Config
+	dashboardUC dashboard.UseCase
+	logger      logger.Logger
+}
+
+func NewDashboardHandlers(cfg *config.Config, dashboardUC dashboard.UseCase, logger logger.Logger) dashboard.Handlers {
+	return &dashboardHandlers{cfg: cfg, dashboardUC: dashboardUC, logger: logger}
+}
+
+// GetMyDashboard godoc
+// @Summary      Get my dashboard
+// @Description  Profile, licenses with points, vehicles with inspection and insurance validity, unpaid violations with totals and the unread notification count of the current user in one call. Sections that fail or time out are null and listed in errors
+// @Tags         Dashboard
+// @Produce      json
+// @Success      200  {object}  models.Dashboard
+// @Failure      401  {object}  httpErrors.Problem
+// @Failure      500  {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /me/dashboard [get]
+func (h *dashboardHandlers) GetMyDashboard() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		d, err := h.dashboardUC.GetMyDashboard(ctx)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, d)
+	}
+}
@@ -0,0 +1,13 @@
+package http
+
+import (
+	"github.com/adohong4/driving-license/config"
+	"github.com/adohong4/driving-license/internal/auth"
+	"github.com/adohong4/driving-license/internal/dashboard"
+	"github.com/adohong4/driving-license/internal/middleware"
+	"github.com/labstack/echo/v4"
+)
+
+func MapDashboardRoutes(meGroup *echo.Group, h dashboard.Handlers, mw *middleware.MiddlewareManager, cfg *config.Config, authUC auth.UseCase) {
+	meGroup.GET("/dashboard", h.GetMyDashboard(), mw.AuthJWTMiddleware(authUC, cfg))
+}
@@ -0,0 +1,16 @@
+package dashboard
+
+import (
+	"context"
+	"time"
+
+	"github.com/adohong4/driving-license/internal/models"
+	"github.com/google/uuid"
+)
+
+type Repository interface {
+	GetLicensesByIdentityNo(ctx context.Context, identityNo string) ([]*models.DrivingLicense, error)
+	GetVehiclesByUserID(ctx context.Context, userID uuid.UUID) ([]*models.VehicleRegistration, error)
+	GetUnpaidViolationsByUserID(ctx context.Context, userID uuid.UUID, limit int) (*models.DashboardViolations, error)
+	GetUnreadNotificationCount(ctx context.Context, since time.Time, identityNo string) (int, error)
+}
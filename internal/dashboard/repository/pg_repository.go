@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/adohong4/driving-license/internal/dashboard"
+	"github.com/adohong4/driving-license/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+type dashboardRepo struct {
+	db *sqlx.DB
+}
+
+func NewDashboardRepo(db *sqlx.DB) dashboard.Repository {
+	return &dashboardRepo{db: db}
+}
+
+func (r *dashboardRepo) GetLicensesByIdentityNo(ctx context.Context, identityNo string) ([]*models.DrivingLicense, error) {
+	licenses := make([]*models.DrivingLicense, 0)
+	if err := r.db.SelectContext(ctx, &licenses, getLicensesByIdentityNo, identityNo); err != nil {
+		return nil, errors.Wrap(err, "dashboardRepo.GetLicensesByIdentityNo.SelectContext")
+	}
+	return licenses, nil
+}
+
+func (r *dashboardRepo) GetVehiclesByUserID(ctx context.Context, userID uuid.UUID) ([]*models.VehicleRegistration, error) {
+	vehicles := make([]*models.VehicleRegistration, 0)
+	if err := r.db.SelectContext(ctx, &vehicles, getVehiclesByUserID, userID); err != nil {
+		return nil, errors.Wrap(err, "dashboardRepo.GetVehiclesByUserID.SelectContext")
+	}
+	return vehicles, nil
+}
+
+// Totals of all unpaid violations and the latest limit of them
+func (r *dashboardRepo) GetUnpaidViolationsByUserID(ctx context.Context, userID uuid.UUID, limit int) (*models.DashboardViolations, error) {
+	v := &models.DashboardViolations{}
+	if err := r.db.GetContext(ctx, v, getUnpaidViolationTotals, userID); err != nil {
+		return nil, errors.Wrap(err, "dashboardRepo.GetUnpaidViolationsByUserID.GetContext")
+	}
+
+	v.Items = make([]*models.TrafficViolation, 0, limit)
+	if err := r.db.SelectContext(ctx, &v.Items, getUnpaidViolations, userID, limit); err != nil {
+		return nil, errors.Wrap(err, "dashboardRepo.GetUnpaidViolationsByUserID.SelectContext")
+	}
+	return v, nil
+}
+
+func (r *dashboardRepo) GetUnreadNotificationCount(ctx context.Context, since time.Time, identityNo string) (int, error) {
+	var count int
+	if err := r.db.GetContext(ctx, &count, getUnreadNotificationCount, since, identityNo); err != nil {
+		return 0, errors.Wrap(err, "dashboardRepo.GetUnreadNotificationCount.GetContext")
+	}
+	return count, nil
+}
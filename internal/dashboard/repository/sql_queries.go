@@ -0,0 +1,93 @@
+package repository
+
+const (
+	getLicensesByIdentityNo = `
+        SELECT
+            id, full_name, avatar, dob, identity_no, owner_address, owner_city, license_no,
+            issue_date, expiry_date, status, license_type, authority_id, issuing_authority,
+            nationality, point, wallet_address, on_blockchain, blockchain_txhash,
+            version, creator_id, modifier_id, created_at, updated_at, active
+        FROM driver_licenses
+        WHERE identity_no = $1 AND active = true
+        ORDER BY issue_date DESC
+    `
+
+	// vehicles owned through the citizen's licenses, as GET /vehicle/me
+	getVehiclesByUserID = `
+    SELECT
+        vr.id,
+        vr.owner_id,
+        vr.brand,
+        vr.type_vehicle,
+        vr.vehicle_no,
+        vr.color_plate,
+        vr.chassis_no,
+        vr.engine_no,
+        vr.color_vehicle,
+        COALESCE(dl.full_name, vr.owner_name) AS owner_name,
+        vr.seats,
+        vr.issue_date,
+        vr.expiry_date,
+        vr.issuer,
+        vr.registration_code,
+        vr.registration_date,
+        vr.registration_place,
+        vr.status,
+        vr.version,
+        vr.creator_id,
+        vr.modifier_id,
+        vr.updated_at,
+        vr.created_at,
+        vr.on_blockchain,
+        vr.blockchain_txhash,
+        vr.active,
+        vr.stolen,
+        vr.seized
+    FROM vehicle_registration vr
+    INNER JOIN driver_licenses dl ON vr.owner_id = dl.id AND dl.active = true
+    INNER JOIN users u ON dl.identity_no = u.identity_no AND u.active = true
+    WHERE u.id = $1
+      AND vr.active = true
+    ORDER BY vr.created_at DESC
+    `
+
+	// violations that are neither processed nor cancelled on vehicles the citizen owned on the violation date
+	unpaidViolationsCTE = `
+    WITH unpaid AS (
+        SELECT tv.*
+        FROM traffic_violations tv
+        INNER JOIN vehicle_ownerships o ON o.vehicle_no = tv.vehicle_no
+            AND o.from_date <= tv.date AND (o.to_date IS NULL OR tv.date < o.to_date)
+        INNER JOIN users u ON u.active = true AND (o.owner_id = u.id OR (u.identity_no <> '' AND o.identity_no = u.identity_no))
+        WHERE u.id = $1
+          AND tv.active = true
+          AND LOWER(COALESCE(tv.status, '')) NOT IN ('processed', 'cancelled')
+    )
+    `
+
+	getUnpaidViolationTotals = unpaidViolationsCTE + `
+    SELECT
+        COUNT(*)                                                  AS count,
+        COUNT(*) FILTER (WHERE expiry_date < CURRENT_DATE)        AS overdue,
+        COALESCE(SUM(fine_amount), 0)::bigint                     AS fine_amount,
+        COALESCE(SUM(points), 0)::int                             AS points
+    FROM unpaid
+    `
+
+	getUnpaidViolations = unpaidViolationsCTE + `
+    SELECT * FROM unpaid
+    ORDER BY date DESC
+    LIMIT $2
+    `
+
+	// only personal notifications carry a read state
+	getUnreadNotificationCount = `
+        SELECT COUNT(*)
+        FROM notifications
+        WHERE active = true
+          AND created_at > $1
+          AND target = 'personal'
+          AND target_user = $2
+          AND status <> 'read'
+    `
+)
@@ -0,0 +1,11 @@
+package dashboard
+
+import (
+	"context"
+
+	"github.com/adohong4/driving-license/internal/models"
+)
+
+type UseCase interface {
+	GetMyDashboard(ctx context.Context) (*models.Dashboard, error)
+}
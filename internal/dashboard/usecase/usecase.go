@@ -0,0 +1,141 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/adohong4/driving-license/config"
+	"github.com/adohong4/driving-license/internal/auth"
+	"github.com/adohong4/driving-license/internal/dashboard"
+	"github.com/adohong4/driving-license/internal/models"
+	"github.com/adohong4/driving-license/pkg/httpErrors"
+	"github.com/adohong4/driving-license/pkg/logger"
+	"github.com/adohong4/driving-license/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+const (
+	// each section gets its own deadline, a slow one does not hold back the others
+	sectionTimeout = 3 * time.Second
+	// latest unpaid violations listed, the totals cover all of them
+	violationItems = 5
+)
+
+type dashboardUC struct {
+	cfg           *config.Config
+	dashboardRepo dashboard.Repository
+	authUC        auth.UseCase
+	logger        logger.Logger
+}
+
+func NewDashboardUseCase(cfg *config.Config, dashboardRepo dashboard.Repository, authUC auth.UseCase, logger logger.Logger) dashboard.UseCase {
+	return &dashboardUC{cfg: cfg, dashboardRepo: dashboardRepo, authUC: authUC, logger: logger}
+}
+
+type section struct {
+	name string
+	load func(ctx context.Context) error
+}
+
+// Dashboard of the current user, sections are loaded concurrently and a failed section is reported instead of failing the whole
+func (u *dashboardUC) GetMyDashboard(ctx context.Context) (*models.Dashboard, error) {
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return nil, httpErrors.NewUnauthorizedError(err)
+	}
+
+	d := &models.Dashboard{GeneratedAt: time.Now()}
+	sections := []section{
+		{name: models.DashboardSectionProfile, load: func(ctx context.Context) error {
+			profile, err := u.authUC.GetByID(ctx, user.Id)
+			if err != nil {
+				return err
+			}
+			d.Profile = profile
+			return nil
+		}},
+		{name: models.DashboardSectionLicenses, load: func(ctx context.Context) error {
+			if user.IdentityNo == "" {
+				d.Licenses = []*models.DrivingLicense{}
+				return nil
+			}
+			licenses, err := u.dashboardRepo.GetLicensesByIdentityNo(ctx, user.IdentityNo)
+			if err != nil {
+				return err
+			}
+			d.Licenses = licenses
+			return nil
+		}},
+		{name: models.DashboardSectionVehicles, load: func(ctx context.Context) error {
+			vehicles, err := u.dashboardRepo.GetVehiclesByUserID(ctx, user.Id)
+			if err != nil {
+				return err
+			}
+			d.Vehicles = make([]*models.DashboardVehicle, 0, len(vehicles))
+			for _, v := range vehicles {
+				d.Vehicles = append(d.Vehicles, models.NewDashboardVehicle(v, d.GeneratedAt))
+			}
+			return nil
+		}},
+		{name: models.DashboardSectionViolations, load: func(ctx context.Context) error {
+			violations, err := u.dashboardRepo.GetUnpaidViolationsByUserID(ctx, user.Id, violationItems)
+			if err != nil {
+				return err
+			}
+			d.Violations = violations
+			return nil
+		}},
+		{name: models.DashboardSectionNotifications, load: func(ctx context.Context) error {
+			if user.IdentityNo == "" {
+				d.Notifications = &models.DashboardNotifications{}
+				return nil
+			}
+			unread, err := u.dashboardRepo.GetUnreadNotificationCount(ctx, user.CreatedAt, user.IdentityNo)
+			if err != nil {
+				return err
+			}
+			d.Notifications = &models.DashboardNotifications{Unread: unread}
+			return nil
+		}},
+	}
+
+	// every section writes its own field, the errors are kept in section order
+	sectionErrs := make([]*models.DashboardSectionError, len(sections))
+	var wg sync.WaitGroup
+	for i, s := range sections {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sectionErrs[i] = u.loadSection(ctx, s)
+		}()
+	}
+	wg.Wait()
+
+	for _, se := range sectionErrs {
+		if se != nil {
+			d.Errors = append(d.Errors, *se)
+		}
+	}
+	if len(d.Errors) == len(sections) {
+		return nil, httpErrors.NewInternalServerError(errors.New("dashboard: all sections failed"))
+	}
+	return d, nil
+}
+
+// Load a section within its timeout, nil when it succeeded
+func (u *dashboardUC) loadSection(ctx context.Context, s section) *models.DashboardSectionError {
+	ctx, cancel := context.WithTimeout(ctx, sectionTimeout)
+	defer cancel()
+
+	err := s.load(ctx)
+	if err == nil {
+		return nil
+	}
+	u.logger.Errorf("dashboardUC.GetMyDashboard.%s: %v", s.name, err)
+
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return &models.DashboardSectionError{Section: s.name, Code: models.DashboardErrTimeout}
+	}
+	return &models.DashboardSectionError{Section: s.name, Code: models.DashboardErrUnavailable}
+}
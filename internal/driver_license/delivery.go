@@ -12,11 +12,17 @@ type Handlers interface {
 	GetDriverLicenseById() echo.HandlerFunc
 	GetDriverLicenseByWalletAddress() echo.HandlerFunc
 	SearchByLicenseNo() echo.HandlerFunc
+	ExportDriverLicenses() echo.HandlerFunc
+	ImportDriverLicenses() echo.HandlerFunc
 	GetStatusDistribution() echo.HandlerFunc
 	GetLicenseTypeDistribution() echo.HandlerFunc
 	GetLicenseTypeStatusDistribution() echo.HandlerFunc
 	GetCityStatusDistribution() echo.HandlerFunc
+	GetLicensesIssuedSeries() echo.HandlerFunc
 
 	GetMyDrivingLicenses() echo.HandlerFunc
 	GetMyDrivingLicenseDetail() echo.HandlerFunc
+	GetMyLicenseHolder() echo.HandlerFunc
+
+	AddEndorsement() echo.HandlerFunc
 }
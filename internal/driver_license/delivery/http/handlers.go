@@ -5,6 +5,10 @@ import (
 
 	"github.com/adohong4/driving-license/config"
 	driverlicense "github.com/adohong4/driving-license/internal/driver_license"
+	exportjob "github.com/adohong4/driving-license/internal/export_job"
+	exportHttp "github.com/adohong4/driving-license/internal/export_job/delivery/http"
+	importjob "github.com/adohong4/driving-license/internal/import_job"
+	importHttp "github.com/adohong4/driving-license/internal/import_job/delivery/http"
 	"github.com/adohong4/driving-license/internal/models"
 	"github.com/adohong4/driving-license/pkg/httpErrors"
 	"github.com/adohong4/driving-license/pkg/logger"
@@ -16,11 +20,13 @@ import (
 type DriverLicenseHandlers struct {
 	cfg             *config.Config
 	DriverLicenseUC driverlicense.UseCase
+	exportJobUC     exportjob.UseCase
+	importJobUC     importjob.UseCase
 	logger          logger.Logger
 }
 
-func NewDriverLicenseHandlers(cfg *config.Config, DriverLicenseUC driverlicense.UseCase, logger logger.Logger) driverlicense.Handlers {
-	return &DriverLicenseHandlers{cfg: cfg, DriverLicenseUC: DriverLicenseUC, logger: logger}
+func NewDriverLicenseHandlers(cfg *config.Config, DriverLicenseUC driverlicense.UseCase, exportJobUC exportjob.UseCase, importJobUC importjob.UseCase, logger logger.Logger) driverlicense.Handlers {
+	return &DriverLicenseHandlers{cfg: cfg, DriverLicenseUC: DriverLicenseUC, exportJobUC: exportJobUC, importJobUC: importJobUC, logger: logger}
 }
 
 // @Summary Create a new driving license
@@ -30,8 +36,8 @@ func NewDriverLicenseHandlers(cfg *config.Config, DriverLicenseUC driverlicense.
 // @Produce json
 // @Param driving_license body models.DrivingLicense true "Driving License object"
 // @Success 201 {object} models.DrivingLicense
-// @Failure 400 {object} httpErrors.RestError
-// @Failure 500 {object} httpErrors.RestError
+// @Failure 400 {object} httpErrors.Problem
+// @Failure 500 {object} httpErrors.Problem
 // @Security JWT
 // @Router /licenses/create [post]
 func (h *DriverLicenseHandlers) CreateDriverLicense() echo.HandlerFunc {
@@ -60,8 +66,8 @@ func (h *DriverLicenseHandlers) CreateDriverLicense() echo.HandlerFunc {
 // @Param id path string true "Driving License ID"
 // @Param driving_license body models.DrivingLicense true "Driving License object"
 // @Success 200 {object} models.DrivingLicense
-// @Failure 400 {object} httpErrors.RestError
-// @Failure 500 {object} httpErrors.RestError
+// @Failure 400 {object} httpErrors.Problem
+// @Failure 500 {object} httpErrors.Problem
 // @Security JWT
 // @Router /licenses/{id} [put]
 func (h *DriverLicenseHandlers) UpdateDriverLicense() echo.HandlerFunc {
@@ -91,15 +97,18 @@ func (h *DriverLicenseHandlers) UpdateDriverLicense() echo.HandlerFunc {
 }
 
 // @Summary Confirm blockchain storage
-// @Description Update blockchain transaction hash and set on_blockchain to true
+// @Description Update blockchain transaction hash and set on_blockchain to true. The transaction must be mined, succeed, target the registry contract and anchor the hash of the stored license, otherwise the code is tx_not_verified
 // @Tags DrivingLicense
 // @Accept json
 // @Produce json
 // @Param id path string true "Driving License ID"
 // @Param request body http.ConfirmBlockchainRequest true "Blockchain confirmation details"
 // @Success 200 {object} models.DrivingLicense
-// @Failure 400 {object} httpErrors.RestError
-// @Failure 500 {object} httpErrors.RestError
+// @Failure 400 {object} httpErrors.Problem
+// @Failure 404 {object} httpErrors.Problem
+// @Failure 409 {object} httpErrors.Problem
+// @Failure 500 {object} httpErrors.Problem
+// @Failure 503 {object} httpErrors.Problem
 // @Security JWT
 // @Router /driver-license/{id}/confirm-blockchain [put]
 func (h *DriverLicenseHandlers) ConfirmBlockchainStorage() echo.HandlerFunc {
@@ -146,8 +155,8 @@ func (h *DriverLicenseHandlers) ConfirmBlockchainStorage() echo.HandlerFunc {
 // @Param id path string true "Driving License ID"
 // @Param request body http.AddWalletRequest true "Wallet address details"
 // @Success 200 {object} models.DrivingLicense
-// @Failure 400 {object} httpErrors.RestError
-// @Failure 500 {object} httpErrors.RestError
+// @Failure 400 {object} httpErrors.Problem
+// @Failure 500 {object} httpErrors.Problem
 // @Security JWT
 // @Router /driver-license/{id}/add-wallet [put]
 func (h *DriverLicenseHandlers) AddWalletAddress() echo.HandlerFunc {
@@ -193,8 +202,8 @@ func (h *DriverLicenseHandlers) AddWalletAddress() echo.HandlerFunc {
 // @Param id path string true "Driving License ID"
 // @Param driving_license body models.DrivingLicense true "Driving License object (for modifier)"
 // @Success 200 {object} models.DrivingLicense
-// @Failure 400 {object} httpErrors.RestError
-// @Failure 500 {object} httpErrors.RestError
+// @Failure 400 {object} httpErrors.Problem
+// @Failure 500 {object} httpErrors.Problem
 // @Security JWT
 // @Router /licenses/{id} [delete]
 func (h *DriverLicenseHandlers) DeleteDriverLicense() echo.HandlerFunc {
@@ -229,9 +238,11 @@ func (h *DriverLicenseHandlers) DeleteDriverLicense() echo.HandlerFunc {
 // @Produce json
 // @Param page query int false "Page number"
 // @Param size query int false "Page size"
+// @Param sort query string false "Sort fields, comma separated, - prefix for descending, e.g. -created_at"
 // @Success 200 {object} models.DrivingLicenseList
-// @Failure 400 {object} httpErrors.RestError
-// @Failure 500 {object} httpErrors.RestError
+// @Failure 400 {object} httpErrors.Problem
+// @Failure 500 {object} httpErrors.Problem
+// @Security JWT
 // @Router /licenses/getAll [get]
 func (h *DriverLicenseHandlers) GetDriverLicense() echo.HandlerFunc {
 	return func(c echo.Context) error {
@@ -258,9 +269,10 @@ func (h *DriverLicenseHandlers) GetDriverLicense() echo.HandlerFunc {
 // @Produce json
 // @Param id path string true "Driving License ID"
 // @Success 200 {object} models.DrivingLicense
-// @Failure 400 {object} httpErrors.RestError
-// @Failure 404 {object} httpErrors.RestError
-// @Failure 500 {object} httpErrors.RestError
+// @Failure 400 {object} httpErrors.Problem
+// @Failure 404 {object} httpErrors.Problem
+// @Failure 500 {object} httpErrors.Problem
+// @Security JWT
 // @Router /licenses/{id} [get]
 func (h *DriverLicenseHandlers) GetDriverLicenseById() echo.HandlerFunc {
 	return func(c echo.Context) error {
@@ -286,9 +298,9 @@ func (h *DriverLicenseHandlers) GetDriverLicenseById() echo.HandlerFunc {
 // @Produce json
 // @Param address path string true "Driving License Wallet Address"
 // @Success 200 {object} models.DrivingLicense
-// @Failure 400 {object} httpErrors.RestError
-// @Failure 404 {object} httpErrors.RestError
-// @Failure 500 {object} httpErrors.RestError
+// @Failure 400 {object} httpErrors.Problem
+// @Failure 404 {object} httpErrors.Problem
+// @Failure 500 {object} httpErrors.Problem
 // @Router /licenses/{address} [get]
 func (h *DriverLicenseHandlers) GetDriverLicenseByWalletAddress() echo.HandlerFunc {
 	return func(c echo.Context) error {
@@ -311,6 +323,57 @@ func (h *DriverLicenseHandlers) GetDriverLicenseByWalletAddress() echo.HandlerFu
 	}
 }
 
+// @Summary Export driving licenses
+// @Description Export driving licenses as CSV or XLSX with the same license number filter, list filters and sort as search. Large exports run as a background job and return 202 with the job
+// @Tags DrivingLicense
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param license_no query string false "License number to search"
+// @Param sort query string false "Sort fields, comma separated, - prefix for descending, e.g. -created_at"
+// @Param format query string false "csv or xlsx, defaults to the Accept header or csv"
+// @Param lang query string false "Header language vi or en, defaults to Accept-Language or vi"
+// @Param async query bool false "Always run as a background job"
+// @Success 200 {file} file
+// @Success 202 {object} models.ExportJob
+// @Failure 400 {object} httpErrors.Problem
+// @Failure 401 {object} httpErrors.Problem
+// @Security JWT
+// @Router /licenses/export [get]
+func (h *DriverLicenseHandlers) ExportDriverLicenses() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		eq, err := utils.GetExportQueryFromCtx(c, "license_no")
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(httpErrors.NewBadRequestError(err.Error())))
+		}
+
+		src := h.DriverLicenseUC.ExportDriverLicenses(c.Request().Context(), eq)
+		return exportHttp.Respond(c, h.exportJobUC, eq, src, h.logger)
+	}
+}
+
+// @Summary Import driving licenses
+// @Description Bulk import driving licenses from a CSV or XLSX file. Headers may be column keys or the vi/en export headers. Rows are validated like create and valid rows are inserted in batched transactions, with dry_run only the validation report is produced. Returns 202 with the import job
+// @Tags DrivingLicense
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "CSV or XLSX file"
+// @Param dry_run query bool false "Validate only, nothing is inserted"
+// @Param format query string false "csv or xlsx, defaults to the file extension"
+// @Param lang query string false "Error file language vi or en, defaults to Accept-Language or vi"
+// @Success 202 {object} models.ImportJob
+// @Failure 400 {object} httpErrors.Problem
+// @Failure 401 {object} httpErrors.Problem
+// @Failure 413 {object} httpErrors.Problem
+// @Security JWT
+// @Router /licenses/import [post]
+func (h *DriverLicenseHandlers) ImportDriverLicenses() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		target := h.DriverLicenseUC.ImportDriverLicenses(c.Request().Context())
+		return importHttp.Respond(c, h.importJobUC, target, h.logger)
+	}
+}
+
 // @Summary Search driving licenses by license number
 // @Description Search for driving licenses by license number with pagination
 // @Tags DrivingLicense
@@ -318,9 +381,11 @@ func (h *DriverLicenseHandlers) GetDriverLicenseByWalletAddress() echo.HandlerFu
 // @Param license_no query string true "License number to search"
 // @Param page query int false "Page number"
 // @Param size query int false "Page size"
+// @Param sort query string false "Sort fields, comma separated, - prefix for descending, e.g. -created_at"
 // @Success 200 {object} models.DrivingLicenseList
-// @Failure 400 {object} httpErrors.RestError
-// @Failure 500 {object} httpErrors.RestError
+// @Failure 400 {object} httpErrors.Problem
+// @Failure 500 {object} httpErrors.Problem
+// @Security JWT
 // @Router /licenses/search [get]
 func (h *DriverLicenseHandlers) SearchByLicenseNo() echo.HandlerFunc {
 	return func(c echo.Context) error {
@@ -347,18 +412,20 @@ func (h *DriverLicenseHandlers) SearchByLicenseNo() echo.HandlerFunc {
 // @Tags DrivingLicense
 // @Produce json
 // @Success 200 {object} models.StatusDistributionResponse
-// @Failure 500 {object} httpErrors.RestError
+// @Header 200 {string} X-Stats-As-Of "Time the statistic data was computed (RFC3339)"
+// @Failure 500 {object} httpErrors.Problem
 // @Router /licenses/stats/status [get]
 func (h *DriverLicenseHandlers) GetStatusDistribution() echo.HandlerFunc {
 	return func(c echo.Context) error {
 		ctx := c.Request().Context()
 
-		dist, err := h.DriverLicenseUC.GetStatusDistribution(ctx)
+		dist, asOf, err := h.DriverLicenseUC.GetStatusDistribution(ctx)
 		if err != nil {
 			utils.LogResponseError(c, h.logger, err)
 			return c.JSON(httpErrors.ErrorResponse(err))
 		}
 
+		utils.SetStatsAsOf(c, asOf)
 		return c.JSON(http.StatusOK, dist)
 	}
 }
@@ -368,18 +435,20 @@ func (h *DriverLicenseHandlers) GetStatusDistribution() echo.HandlerFunc {
 // @Tags DrivingLicense
 // @Produce json
 // @Success 200 {object} models.LicenseTypeDistributionResponse
-// @Failure 500 {object} httpErrors.RestError
+// @Header 200 {string} X-Stats-As-Of "Time the statistic data was computed (RFC3339)"
+// @Failure 500 {object} httpErrors.Problem
 // @Router /licenses/stats/license-type [get]
 func (h *DriverLicenseHandlers) GetLicenseTypeDistribution() echo.HandlerFunc {
 	return func(c echo.Context) error {
 		ctx := c.Request().Context()
 
-		dist, err := h.DriverLicenseUC.GetLicenseTypeDistribution(ctx)
+		dist, asOf, err := h.DriverLicenseUC.GetLicenseTypeDistribution(ctx)
 		if err != nil {
 			utils.LogResponseError(c, h.logger, err)
 			return c.JSON(httpErrors.ErrorResponse(err))
 		}
 
+		utils.SetStatsAsOf(c, asOf)
 		return c.JSON(http.StatusOK, dist)
 	}
 }
@@ -389,18 +458,20 @@ func (h *DriverLicenseHandlers) GetLicenseTypeDistribution() echo.HandlerFunc {
 // @Tags DrivingLicense
 // @Produce json
 // @Success 200 {object} models.LicenseTypeDetailDistributionResponse
-// @Failure 500 {object} httpErrors.RestError
+// @Header 200 {string} X-Stats-As-Of "Time the statistic data was computed (RFC3339)"
+// @Failure 500 {object} httpErrors.Problem
 // @Router /licenses/stats/license-type-detail [get]
 func (h *DriverLicenseHandlers) GetLicenseTypeStatusDistribution() echo.HandlerFunc {
 	return func(c echo.Context) error {
 		ctx := c.Request().Context()
 
-		dist, err := h.DriverLicenseUC.GetLicenseTypeStatusDistribution(ctx)
+		dist, asOf, err := h.DriverLicenseUC.GetLicenseTypeStatusDistribution(ctx)
 		if err != nil {
 			utils.LogResponseError(c, h.logger, err)
 			return c.JSON(httpErrors.ErrorResponse(err))
 		}
 
+		utils.SetStatsAsOf(c, asOf)
 		return c.JSON(http.StatusOK, dist)
 	}
 }
@@ -410,32 +481,70 @@ func (h *DriverLicenseHandlers) GetLicenseTypeStatusDistribution() echo.HandlerF
 // @Tags DrivingLicense
 // @Produce json
 // @Success 200 {object} models.CityDetailDistributionResponse
-// @Failure 500 {object} httpErrors.RestError
+// @Header 200 {string} X-Stats-As-Of "Time the statistic data was computed (RFC3339)"
+// @Failure 500 {object} httpErrors.Problem
 // @Router /licenses/stats/city-detail [get]
 func (h *DriverLicenseHandlers) GetCityStatusDistribution() echo.HandlerFunc {
 	return func(c echo.Context) error {
 		ctx := c.Request().Context()
 
-		dist, err := h.DriverLicenseUC.GetCityStatusDistribution(ctx)
+		dist, asOf, err := h.DriverLicenseUC.GetCityStatusDistribution(ctx)
 		if err != nil {
 			utils.LogResponseError(c, h.logger, err)
 			return c.JSON(httpErrors.ErrorResponse(err))
 		}
 
+		utils.SetStatsAsOf(c, asOf)
 		return c.JSON(http.StatusOK, dist)
 	}
 }
 
+// @Summary Get licenses issued over time
+// @Description Get number of driving licenses issued per day, week or month
+// @Tags DrivingLicense
+// @Produce json
+// @Param from query string false "Start date (RFC3339 or YYYY-MM-DD)"
+// @Param to query string false "End date, exclusive (RFC3339 or YYYY-MM-DD, default: end of the current period)"
+// @Param group_by query string false "day, week or month" default(month)
+// @Param city query string false "Owner city"
+// @Param agency_id query string false "Issuing agency ID"
+// @Param license_type query string false "License type (A1, B2, ...)"
+// @Success 200 {object} models.TimeSeries
+// @Header 200 {string} X-Stats-As-Of "Time the statistic data was computed (RFC3339)"
+// @Failure 400 {object} httpErrors.Problem
+// @Failure 500 {object} httpErrors.Problem
+// @Router /licenses/stats/series [get]
+func (h *DriverLicenseHandlers) GetLicensesIssuedSeries() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		sq, err := utils.GetStatsQueryFromCtx(c)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(httpErrors.NewBadRequestError(err.Error())))
+		}
+
+		series, asOf, err := h.DriverLicenseUC.GetLicensesIssuedSeries(ctx, sq)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		utils.SetStatsAsOf(c, asOf)
+		return c.JSON(http.StatusOK, series)
+	}
+}
+
 // @Summary Get my driving licenses
 // @Description Get list of driving licenses belonging to the current authenticated user (by identity_no)
 // @Tags DrivingLicense, Me
 // @Produce json
 // @Param page query int false "Page number" default(1)
 // @Param size query int false "Page size" default(10)
+// @Param sort query string false "Sort fields, comma separated, - prefix for descending, e.g. -created_at"
 // @Success 200 {object} models.DrivingLicenseList
-// @Failure 400 {object} httpErrors.RestError
-// @Failure 401 {object} httpErrors.RestError
-// @Failure 500 {object} httpErrors.RestError
+// @Failure 400 {object} httpErrors.Problem
+// @Failure 401 {object} httpErrors.Problem
+// @Failure 500 {object} httpErrors.Problem
 // @Security JWT
 // @Router /licenses/me/licenses [get]
 func (h *DriverLicenseHandlers) GetMyDrivingLicenses() echo.HandlerFunc {
@@ -471,10 +580,10 @@ func (h *DriverLicenseHandlers) GetMyDrivingLicenses() echo.HandlerFunc {
 // @Param license_no query string false "License number"
 // @Param id query string false "License ID (UUID)"
 // @Success 200 {object} models.DrivingLicense
-// @Failure 400 {object} httpErrors.RestError
-// @Failure 401 {object} httpErrors.RestError
-// @Failure 404 {object} httpErrors.RestError
-// @Failure 500 {object} httpErrors.RestError
+// @Failure 400 {object} httpErrors.Problem
+// @Failure 401 {object} httpErrors.Problem
+// @Failure 404 {object} httpErrors.Problem
+// @Failure 500 {object} httpErrors.Problem
 // @Security JWT
 // @Router /licenses/me/licenses/detail [get]
 func (h *DriverLicenseHandlers) GetMyDrivingLicenseDetail() echo.HandlerFunc {
@@ -491,9 +600,7 @@ func (h *DriverLicenseHandlers) GetMyDrivingLicenseDetail() echo.HandlerFunc {
 		idStr := c.QueryParam("id")
 
 		if licenseNo == "" && idStr == "" {
-			return c.JSON(http.StatusBadRequest, map[string]string{
-				"error": "either license_no or id is required",
-			})
+			return c.JSON(httpErrors.ErrorResponse(httpErrors.NewRestError(http.StatusBadRequest, "either license_no or id is required", nil)))
 		}
 
 		var dl *models.DrivingLicense
@@ -515,15 +622,85 @@ func (h *DriverLicenseHandlers) GetMyDrivingLicenseDetail() echo.HandlerFunc {
 		}
 
 		if dl == nil {
-			return c.JSON(http.StatusNotFound, map[string]string{
-				"error": "driving license not found or does not belong to you",
-			})
+			return c.JSON(httpErrors.ErrorResponse(httpErrors.NewRestError(http.StatusNotFound, "driving license not found or does not belong to you", nil)))
 		}
 
 		return c.JSON(http.StatusOK, dl)
 	}
 }
 
+// @Summary Get my license holder card
+// @Description The current user's latest license card with every class held on the cards still in force, one entry per class
+// @Tags DrivingLicense, Me
+// @Produce json
+// @Success 200 {object} models.LicenseHolder
+// @Failure 400 {object} httpErrors.Problem
+// @Failure 401 {object} httpErrors.Problem
+// @Failure 404 {object} httpErrors.Problem
+// @Failure 500 {object} httpErrors.Problem
+// @Security JWT
+// @Router /licenses/me/holder [get]
+func (h *DriverLicenseHandlers) GetMyLicenseHolder() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		user, err := utils.GetUserFromCtx(ctx)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(httpErrors.NewUnauthorizedError(err)))
+		}
+
+		holder, err := h.DriverLicenseUC.GetMyLicenseHolder(ctx, user.IdentityNo)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, holder)
+	}
+}
+
+// @Summary Endorse a driving license for a class
+// @Description Officer only. Adds a class to the license card, the holder must hold one of the prerequisite classes (e.g. B2 before C) and be old enough for the class. The expiry defaults to the one of the class
+// @Tags DrivingLicense
+// @Accept json
+// @Produce json
+// @Param id path string true "Driving License ID"
+// @Param request body models.AddEndorsementRequest true "Class, issue and expiry dates"
+// @Success 201 {object} models.LicenseEndorsement
+// @Failure 400 {object} httpErrors.Problem
+// @Failure 401 {object} httpErrors.Problem
+// @Failure 403 {object} httpErrors.Problem
+// @Failure 404 {object} httpErrors.Problem
+// @Failure 409 {object} httpErrors.Problem
+// @Failure 500 {object} httpErrors.Problem
+// @Security JWT
+// @Router /licenses/{id}/endorsements [post]
+func (h *DriverLicenseHandlers) AddEndorsement() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		licenseID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		req := &models.AddEndorsementRequest{}
+		if err = c.Bind(req); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		e, err := h.DriverLicenseUC.AddEndorsement(ctx, licenseID, req)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusCreated, e)
+	}
+}
+
 // Confirm Blockchain Request
 type ConfirmBlockchainRequest struct {
 	BlockchainTxHash string `json:"blockchain_txhash" validate:"required"`
@@ -14,15 +14,21 @@ func MapDriverLicenseRoutes(driverLicenseGroup *echo.Group, h driverlicense.Hand
 	driverLicenseGroup.PUT("/:id/confirm-blockchain", h.ConfirmBlockchainStorage(), mw.AuthJWTMiddleware(authUC, cfg))
 	driverLicenseGroup.PUT("/:id/add-wallet", h.AddWalletAddress(), mw.AuthJWTMiddleware(authUC, cfg))
 	driverLicenseGroup.DELETE("/:id", h.DeleteDriverLicense(), mw.AuthJWTMiddleware(authUC, cfg))
-	driverLicenseGroup.GET("/:id", h.GetDriverLicenseById())
+	driverLicenseGroup.GET("/:id", h.GetDriverLicenseById(), mw.AuthJWTMiddleware(authUC, cfg))
 	driverLicenseGroup.GET("/blockchain/:address", h.GetDriverLicenseByWalletAddress())
-	driverLicenseGroup.GET("/getAll", h.GetDriverLicense())
-	driverLicenseGroup.GET("/search", h.SearchByLicenseNo())
-	driverLicenseGroup.GET("/stats/status", h.GetStatusDistribution())
-	driverLicenseGroup.GET("/stats/license-type", h.GetLicenseTypeDistribution())
-	driverLicenseGroup.GET("/stats/license-type-detail", h.GetLicenseTypeStatusDistribution())
-	driverLicenseGroup.GET("/stats/city-detail", h.GetCityStatusDistribution())
+	driverLicenseGroup.GET("/getAll", h.GetDriverLicense(), mw.AuthJWTMiddleware(authUC, cfg))
+	driverLicenseGroup.GET("/search", h.SearchByLicenseNo(), mw.AuthJWTMiddleware(authUC, cfg))
+	driverLicenseGroup.GET("/export", h.ExportDriverLicenses(), mw.AuthJWTMiddleware(authUC, cfg))
+	driverLicenseGroup.POST("/import", h.ImportDriverLicenses(), mw.AuthJWTMiddleware(authUC, cfg))
+	driverLicenseGroup.GET("/stats/status", h.GetStatusDistribution(), mw.OptionalAuthJWTMiddleware(authUC, cfg))
+	driverLicenseGroup.GET("/stats/license-type", h.GetLicenseTypeDistribution(), mw.OptionalAuthJWTMiddleware(authUC, cfg))
+	driverLicenseGroup.GET("/stats/license-type-detail", h.GetLicenseTypeStatusDistribution(), mw.OptionalAuthJWTMiddleware(authUC, cfg))
+	driverLicenseGroup.GET("/stats/city-detail", h.GetCityStatusDistribution(), mw.OptionalAuthJWTMiddleware(authUC, cfg))
+	driverLicenseGroup.GET("/stats/series", h.GetLicensesIssuedSeries(), mw.OptionalAuthJWTMiddleware(authUC, cfg))
 
 	driverLicenseGroup.GET("/me", h.GetMyDrivingLicenses(), mw.AuthJWTMiddleware(authUC, cfg))
 	driverLicenseGroup.GET("/me/detail", h.GetMyDrivingLicenseDetail(), mw.AuthJWTMiddleware(authUC, cfg))
+	driverLicenseGroup.GET("/me/holder", h.GetMyLicenseHolder(), mw.AuthJWTMiddleware(authUC, cfg))
+
+	driverLicenseGroup.POST("/:id/endorsements", h.AddEndorsement(), mw.AuthJWTMiddleware(authUC, cfg))
 }
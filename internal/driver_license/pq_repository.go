@@ -11,7 +11,7 @@ import (
 type Repository interface {
 	CreateDriverLicense(ctx context.Context, dl *models.DrivingLicense) (*models.DrivingLicense, error)
 	UpdateDriverLicense(ctx context.Context, dl *models.DrivingLicense) (*models.DrivingLicense, error)
-	ConfirmBlockchainStorage(ctx context.Context, dl *models.DrivingLicense) (*models.DrivingLicense, error)
+	ConfirmBlockchainStorage(ctx context.Context, dl *models.DrivingLicense, a *models.BlockchainAnchor) (*models.DrivingLicense, error)
 	UpdateWalletAddress(ctx context.Context, dl *models.DrivingLicense) (*models.DrivingLicense, error)
 	DeleteDriverLicense(ctx context.Context, dl *models.DrivingLicense) (*models.DrivingLicense, error)
 	GetDriverLicense(ctx context.Context, pq *utils.PaginationQuery) (*models.DrivingLicenseList, error)
@@ -19,11 +19,19 @@ type Repository interface {
 	GetDriverLicenseByWalletAddress(ctx context.Context, address string) (*models.DrivingLicense, error)
 	GetDriverLicenseByLicenseNO(ctx context.Context, address string) (*models.DrivingLicense, error)
 	SearchByLicenseNo(ctx context.Context, lno string, query *utils.PaginationQuery) (*models.DrivingLicenseList, error)
+	CountDriverLicenses(ctx context.Context, lno string, query *utils.PaginationQuery) (int, error)
+	StreamDriverLicenses(ctx context.Context, lno string, query *utils.PaginationQuery, fn func(dl *models.DrivingLicense) error) error
+	CreateDriverLicenses(ctx context.Context, dls []*models.DrivingLicense) error
 	FindLicenseNO(ctx context.Context, dl *models.DrivingLicense) (*models.DrivingLicense, error)
 	GetStatusDistribution(ctx context.Context) (*models.StatusDistributionResponse, error)
 	GetLicenseTypeDistribution(ctx context.Context) (*models.LicenseTypeDistributionResponse, error)
 	GetLicenseTypeStatusDistribution(ctx context.Context) (*models.LicenseTypeDetailDistributionResponse, error)
 	GetCityStatusDistribution(ctx context.Context) (*models.CityDetailDistributionResponse, error)
+	GetLicensesIssuedSeries(ctx context.Context, sq *utils.StatsQuery) ([]*models.TimeSeriesPoint, error)
 
 	GetDrivingLicensesByIdentityNo(ctx context.Context, identityNo string, pq *utils.PaginationQuery) (*models.DrivingLicenseList, error)
+	GetHolderLicenses(ctx context.Context, identityNo string) ([]*models.DrivingLicense, error)
+
+	AddEndorsement(ctx context.Context, e *models.LicenseEndorsement) (*models.LicenseEndorsement, error)
+	GetEndorsements(ctx context.Context, licenseIDs ...uuid.UUID) ([]*models.LicenseEndorsement, error)
 }
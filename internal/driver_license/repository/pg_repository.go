@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"sort"
 
+	anchorRepository "github.com/adohong4/driving-license/internal/anchor/repository"
 	driverlicense "github.com/adohong4/driving-license/internal/driver_license"
 	"github.com/adohong4/driving-license/internal/models"
 	"github.com/adohong4/driving-license/pkg/utils"
@@ -21,9 +22,20 @@ func NewDriverLicenseRepo(db *sqlx.DB) driverlicense.Repository {
 	return &DriverLicenseRepo{db: db}
 }
 
+// Create the license card endorsed for its class
 func (r *DriverLicenseRepo) CreateDriverLicense(ctx context.Context, dl *models.DrivingLicense) (*models.DrivingLicense, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "DriverLicenseRepo.CreateDriverLicense.BeginTxx")
+	}
+	defer tx.Rollback()
+
+	if err = utils.CheckJurisdiction(ctx, tx, dl.OwnerCity, nil); err != nil {
+		return nil, errors.Wrap(err, "DriverLicenseRepo.CreateDriverLicense")
+	}
+
 	d := &models.DrivingLicense{}
-	if err := r.db.QueryRowxContext(ctx, createDriverLicenseQuery,
+	if err = tx.QueryRowxContext(ctx, createDriverLicenseQuery,
 		dl.Id, dl.Name, dl.Avatar, dl.DOB, dl.IdentityNo, dl.OwnerAddress, dl.OwnerCity, dl.LicenseNo,
 		dl.IssueDate, dl.ExpiryDate, dl.Status, dl.LicenseType, dl.AuthorityId, dl.IssuingAuthority,
 		dl.Nationality, dl.Point, dl.WalletAddress, dl.OnBlockchain, dl.BlockchainTxHash,
@@ -31,28 +43,115 @@ func (r *DriverLicenseRepo) CreateDriverLicense(ctx context.Context, dl *models.
 	).StructScan(d); err != nil {
 		return nil, errors.Wrap(err, "DriverLicenseRepo.CreateDriverLicense.StructScan")
 	}
+
+	if d.LicenseType != "" {
+		e, err := createEndorsement(ctx, tx, models.NewCardEndorsement(d))
+		if err != nil {
+			return nil, errors.Wrap(err, "DriverLicenseRepo.CreateDriverLicense")
+		}
+		d.Endorsements = []*models.LicenseEndorsement{e}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "DriverLicenseRepo.CreateDriverLicense.Commit")
+	}
 	return d, nil
 }
 
+// Insert licenses in a single transaction, nothing is inserted when one row fails
+func (r *DriverLicenseRepo) CreateDriverLicenses(ctx context.Context, dls []*models.DrivingLicense) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "DriverLicenseRepo.CreateDriverLicenses.BeginTxx")
+	}
+	defer tx.Rollback()
+
+	for _, dl := range dls {
+		if err = utils.CheckJurisdiction(ctx, tx, dl.OwnerCity, nil); err != nil {
+			return errors.Wrapf(err, "DriverLicenseRepo.CreateDriverLicenses %s", dl.LicenseNo)
+		}
+		if _, err = tx.ExecContext(ctx, createDriverLicenseQuery,
+			dl.Id, dl.Name, dl.Avatar, dl.DOB, dl.IdentityNo, dl.OwnerAddress, dl.OwnerCity, dl.LicenseNo,
+			dl.IssueDate, dl.ExpiryDate, dl.Status, dl.LicenseType, dl.AuthorityId, dl.IssuingAuthority,
+			dl.Nationality, dl.Point, dl.WalletAddress, dl.OnBlockchain, dl.BlockchainTxHash,
+			dl.Version, dl.CreatorId, dl.ModifierId, dl.CreatedAt, dl.UpdatedAt, dl.Active,
+		); err != nil {
+			return errors.Wrapf(err, "DriverLicenseRepo.CreateDriverLicenses.ExecContext %s", dl.LicenseNo)
+		}
+		if dl.LicenseType == "" {
+			continue
+		}
+		if _, err = createEndorsement(ctx, tx, models.NewCardEndorsement(dl)); err != nil {
+			return errors.Wrapf(err, "DriverLicenseRepo.CreateDriverLicenses %s", dl.LicenseNo)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "DriverLicenseRepo.CreateDriverLicenses.Commit")
+	}
+	return nil
+}
+
+// Licenses outside the jurisdiction of the caller are not found, nor can a license be moved out of it
 func (r *DriverLicenseRepo) UpdateDriverLicense(ctx context.Context, dl *models.DrivingLicense) (*models.DrivingLicense, error) {
+	if dl.OwnerCity != "" {
+		if err := utils.CheckJurisdiction(ctx, r.db, dl.OwnerCity, nil); err != nil {
+			return nil, errors.Wrap(err, "DriverLicenseRepo.UpdateDriverLicense")
+		}
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "DriverLicenseRepo.UpdateDriverLicense.BeginTxx")
+	}
+	defer tx.Rollback()
+
+	before := &models.DrivingLicense{}
+	if err = tx.GetContext(ctx, before, getDriverLicenseForUpdateQuery, dl.Id); err != nil {
+		return nil, errors.Wrap(err, "DriverLicenseRepo.UpdateDriverLicense.getDriverLicenseForUpdate")
+	}
+
 	d := &models.DrivingLicense{}
-	if err := r.db.QueryRowxContext(ctx, updateDriverLicenseQuery,
+	if err = tx.QueryRowxContext(ctx, updateDriverLicenseQuery,
 		dl.Name, dl.Avatar, dl.DOB, dl.IdentityNo, dl.OwnerAddress, dl.OwnerCity,
 		dl.LicenseNo, dl.IssueDate, dl.ExpiryDate, dl.Status, dl.LicenseType,
-		dl.Nationality, dl.Point, dl.ModifierId, dl.UpdatedAt, dl.Id,
+		dl.Nationality, dl.Point, dl.ModifierId, dl.UpdatedAt, dl.Id, utils.JurisdictionFromCtx(ctx),
 	).StructScan(d); err != nil {
 		return nil, errors.Wrap(err, "DriverLicenseRepo.UpdateDriverLicense.StructScan")
 	}
+	// the anchored hash no longer matches a corrected license
+	if err = anchorRepository.ReanchorChanged(ctx, tx, models.AnchorLicense, d.Id, before.OnBlockchain, before, d); err != nil {
+		return nil, errors.Wrap(err, "DriverLicenseRepo.UpdateDriverLicense")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "DriverLicenseRepo.UpdateDriverLicense.Commit")
+	}
 	return d, nil
 }
 
-func (r *DriverLicenseRepo) ConfirmBlockchainStorage(ctx context.Context, dl *models.DrivingLicense) (*models.DrivingLicense, error) {
+// Confirm the license at the version its transaction was verified against, together with its anchor.
+// sql.ErrNoRows when the license changed or was confirmed in the meantime
+func (r *DriverLicenseRepo) ConfirmBlockchainStorage(ctx context.Context, dl *models.DrivingLicense, a *models.BlockchainAnchor) (*models.DrivingLicense, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "DriverLicenseRepo.ConfirmBlockchainStorage.BeginTxx")
+	}
+	defer tx.Rollback()
+
 	d := &models.DrivingLicense{}
-	if err := r.db.QueryRowxContext(ctx, updateBlockchainConfirmationQuery,
-		dl.BlockchainTxHash, dl.OnBlockchain, dl.ModifierId, dl.UpdatedAt, dl.Id,
+	if err = tx.QueryRowxContext(ctx, updateBlockchainConfirmationQuery,
+		dl.BlockchainTxHash, dl.OnBlockchain, dl.ModifierId, dl.UpdatedAt, dl.Id, dl.Version,
 	).StructScan(d); err != nil {
 		return nil, errors.Wrap(err, "DriverLicenseRepo.ConfirmBlockchainStorage.StructScan")
 	}
+	if err = anchorRepository.SaveConfirmedTx(ctx, tx, a); err != nil {
+		return nil, errors.Wrap(err, "DriverLicenseRepo.ConfirmBlockchainStorage")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "DriverLicenseRepo.ConfirmBlockchainStorage.Commit")
+	}
 	return d, nil
 }
 
@@ -68,15 +167,21 @@ func (r *DriverLicenseRepo) UpdateWalletAddress(ctx context.Context, dl *models.
 
 func (r *DriverLicenseRepo) DeleteDriverLicense(ctx context.Context, dl *models.DrivingLicense) (*models.DrivingLicense, error) {
 	d := &models.DrivingLicense{}
-	if err := r.db.QueryRowxContext(ctx, deleteDriverLicenseQuery, dl.ModifierId, dl.UpdatedAt, dl.Id).StructScan(d); err != nil {
+	if err := r.db.QueryRowxContext(ctx, deleteDriverLicenseQuery, dl.ModifierId, dl.UpdatedAt, dl.Id, utils.JurisdictionFromCtx(ctx)).StructScan(d); err != nil {
 		return nil, errors.Wrap(err, "DriverLicenseRepo.DeleteDriverLicense.StructScan")
 	}
 	return d, nil
 }
 
 func (r *DriverLicenseRepo) GetDriverLicense(ctx context.Context, pq *utils.PaginationQuery) (*models.DrivingLicenseList, error) {
+	lc, err := pq.ListClause(driverLicenseListSpec, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	agency := utils.JurisdictionFromCtx(ctx)
 	var totalCount int
-	if err := r.db.GetContext(ctx, &totalCount, getTotalCount); err != nil {
+	if err := r.db.GetContext(ctx, &totalCount, lc.Count(getTotalCount), lc.Args(agency)...); err != nil {
 		return nil, errors.Wrap(err, "DriverLicenseRepo.GetDriverLicense.GetContext.totalCount")
 	}
 
@@ -92,7 +197,7 @@ func (r *DriverLicenseRepo) GetDriverLicense(ctx context.Context, pq *utils.Pagi
 	}
 
 	var NewDriverLicense = make([]*models.DrivingLicense, 0, pq.GetSize())
-	rows, err := r.db.QueryxContext(ctx, getDriverLicense, pq.GetOffset(), pq.GetLimit())
+	rows, err := r.db.QueryxContext(ctx, lc.Page(getDriverLicense), lc.PageArgs(pq, agency)...)
 	if err != nil {
 		return nil, errors.Wrap(err, "DriverLicenseRepo.GetDriverLicense.NewDriverLicense")
 	}
@@ -122,7 +227,7 @@ func (r *DriverLicenseRepo) GetDriverLicense(ctx context.Context, pq *utils.Pagi
 
 func (r *DriverLicenseRepo) GetDriverLicenseById(ctx context.Context, Id uuid.UUID) (*models.DrivingLicense, error) {
 	d := &models.DrivingLicense{}
-	if err := r.db.GetContext(ctx, d, getDriverLicenseByIdQuery, Id); err != nil {
+	if err := r.db.GetContext(ctx, d, getDriverLicenseByIdQuery, Id, utils.JurisdictionFromCtx(ctx)); err != nil {
 		return nil, errors.Wrap(err, "DriverLicenseRepo.GetDriverLicenseById.GetContext")
 	}
 	return d, nil
@@ -138,15 +243,21 @@ func (r *DriverLicenseRepo) GetDriverLicenseByWalletAddress(ctx context.Context,
 
 func (r *DriverLicenseRepo) GetDriverLicenseByLicenseNO(ctx context.Context, address string) (*models.DrivingLicense, error) {
 	d := &models.DrivingLicense{}
-	if err := r.db.GetContext(ctx, d, getDriverLicenseByLicenseNOQuery, address); err != nil {
+	if err := r.db.GetContext(ctx, d, getDriverLicenseByLicenseNOQuery, address, utils.JurisdictionFromCtx(ctx)); err != nil {
 		return nil, errors.Wrap(err, "DriverLicenseRepo.GetDriverLicenseByLicenseNO.GetContext")
 	}
 	return d, nil
 }
 
 func (r *DriverLicenseRepo) SearchByLicenseNo(ctx context.Context, lno string, query *utils.PaginationQuery) (*models.DrivingLicenseList, error) {
+	lc, err := query.ListClause(driverLicenseListSpec.WithDefaultSort("license_no"), 2, "license_no")
+	if err != nil {
+		return nil, err
+	}
+
+	agency := utils.JurisdictionFromCtx(ctx)
 	var totalCount int
-	if err := r.db.GetContext(ctx, &totalCount, findLicenseNOCount, lno); err != nil {
+	if err := r.db.GetContext(ctx, &totalCount, lc.Count(findLicenseNOCount), lc.Args(lno, agency)...); err != nil {
 		return nil, errors.Wrap(err, "DriverLicenseRepo.SearchByLicenseNo.GetContext.totalCount")
 	}
 
@@ -162,7 +273,7 @@ func (r *DriverLicenseRepo) SearchByLicenseNo(ctx context.Context, lno string, q
 	}
 
 	var NewDriverLicense = make([]*models.DrivingLicense, 0, query.GetSize())
-	rows, err := r.db.QueryxContext(ctx, searchByLicenseNo, lno, query.GetOffset(), query.GetLimit())
+	rows, err := r.db.QueryxContext(ctx, lc.Page(searchByLicenseNo), lc.PageArgs(query, lno, agency)...)
 	if err != nil {
 		return nil, errors.Wrap(err, "DriverLicenseRepo.SearchByLicenseNo.NewDriverLicense")
 	}
@@ -190,6 +301,47 @@ func (r *DriverLicenseRepo) SearchByLicenseNo(ctx context.Context, lno string, q
 	}, nil
 }
 
+func (r *DriverLicenseRepo) CountDriverLicenses(ctx context.Context, lno string, query *utils.PaginationQuery) (int, error) {
+	lc, err := query.ListClause(driverLicenseListSpec.WithDefaultSort("license_no"), 2, "license_no")
+	if err != nil {
+		return 0, err
+	}
+
+	var totalCount int
+	if err := r.db.GetContext(ctx, &totalCount, lc.Count(findLicenseNOCount), lc.Args(lno, utils.JurisdictionFromCtx(ctx))...); err != nil {
+		return 0, errors.Wrap(err, "DriverLicenseRepo.CountDriverLicenses.GetContext")
+	}
+	return totalCount, nil
+}
+
+func (r *DriverLicenseRepo) StreamDriverLicenses(ctx context.Context, lno string, query *utils.PaginationQuery, fn func(dl *models.DrivingLicense) error) error {
+	lc, err := query.ListClause(driverLicenseListSpec.WithDefaultSort("license_no"), 2, "license_no")
+	if err != nil {
+		return err
+	}
+
+	rows, err := r.db.QueryxContext(ctx, lc.All(exportDriverLicenses), lc.Args(lno, utils.JurisdictionFromCtx(ctx))...)
+	if err != nil {
+		return errors.Wrap(err, "DriverLicenseRepo.StreamDriverLicenses.QueryxContext")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		n := &models.DrivingLicense{}
+		if err = rows.StructScan(n); err != nil {
+			return errors.Wrap(err, "DriverLicenseRepo.StreamDriverLicenses.StructScan")
+		}
+		if err = fn(n); err != nil {
+			return err
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return errors.Wrap(err, "DriverLicenseRepo.StreamDriverLicenses.rows.Err")
+	}
+	return nil
+}
+
 func (r *DriverLicenseRepo) FindLicenseNO(ctx context.Context, dl *models.DrivingLicense) (*models.DrivingLicense, error) {
 	d := &models.DrivingLicense{}
 	err := r.db.QueryRowxContext(ctx, findLicenseNO, dl.LicenseNo).StructScan(d)
@@ -208,7 +360,7 @@ func (r *DriverLicenseRepo) GetStatusDistribution(ctx context.Context) (*models.
 		Count  int    `db:"count"`
 	}
 
-	if err := r.db.SelectContext(ctx, &items, getStatusDistributionQuery); err != nil {
+	if err := r.db.SelectContext(ctx, &items, getStatusDistributionQuery, utils.JurisdictionFromCtx(ctx)); err != nil {
 		return nil, errors.Wrap(err, "DriverLicenseRepo.GetStatusDistribution.SelectContext")
 	}
 
@@ -234,7 +386,7 @@ func (r *DriverLicenseRepo) GetLicenseTypeDistribution(ctx context.Context) (*mo
 		Count       int    `db:"count"`
 	}
 
-	if err := r.db.SelectContext(ctx, &items, getLicenseTypeDistributionQuery); err != nil {
+	if err := r.db.SelectContext(ctx, &items, getLicenseTypeDistributionQuery, utils.JurisdictionFromCtx(ctx)); err != nil {
 		return nil, errors.Wrap(err, "DriverLicenseRepo.GetLicenseTypeDistribution.SelectContext")
 	}
 
@@ -262,7 +414,7 @@ func (r *DriverLicenseRepo) GetLicenseTypeStatusDistribution(ctx context.Context
 	}
 
 	var rows []row
-	if err := r.db.SelectContext(ctx, &rows, getLicenseTypeStatusDistributionQuery); err != nil {
+	if err := r.db.SelectContext(ctx, &rows, getLicenseTypeStatusDistributionQuery, utils.JurisdictionFromCtx(ctx)); err != nil {
 		return nil, errors.Wrap(err, "DriverLicenseRepo.GetLicenseTypeStatusDistribution.SelectContext")
 	}
 
@@ -314,7 +466,7 @@ func (r *DriverLicenseRepo) GetCityStatusDistribution(ctx context.Context) (*mod
 	}
 
 	var rows []row
-	if err := r.db.SelectContext(ctx, &rows, getCityStatusDistributionQuery); err != nil {
+	if err := r.db.SelectContext(ctx, &rows, getCityStatusDistributionQuery, utils.JurisdictionFromCtx(ctx)); err != nil {
 		return nil, errors.Wrap(err, "DriverLicenseRepo.GetCityStatusDistribution.SelectContext")
 	}
 
@@ -357,9 +509,24 @@ func (r *DriverLicenseRepo) GetCityStatusDistribution(ctx context.Context) (*mod
 	}, nil
 }
 
+func (r *DriverLicenseRepo) GetLicensesIssuedSeries(ctx context.Context, sq *utils.StatsQuery) ([]*models.TimeSeriesPoint, error) {
+	var points []*models.TimeSeriesPoint
+	if err := r.db.SelectContext(ctx, &points, getLicensesIssuedSeriesQuery,
+		sq.GroupBy, sq.From, sq.To, sq.City, sq.AgencyID, sq.LicenseType, utils.JurisdictionFromCtx(ctx),
+	); err != nil {
+		return nil, errors.Wrap(err, "DriverLicenseRepo.GetLicensesIssuedSeries.SelectContext")
+	}
+	return points, nil
+}
+
 func (r *DriverLicenseRepo) GetDrivingLicensesByIdentityNo(ctx context.Context, identityNo string, pq *utils.PaginationQuery) (*models.DrivingLicenseList, error) {
+	lc, err := pq.ListClause(driverLicenseListSpec.WithDefaultSort("-updated_at,-created_at"), 1, "identity_no")
+	if err != nil {
+		return nil, err
+	}
+
 	var totalCount int
-	if err := r.db.GetContext(ctx, &totalCount, getTotalCountByIdentityNo, identityNo); err != nil {
+	if err := r.db.GetContext(ctx, &totalCount, lc.Count(getTotalCountByIdentityNo), lc.Args(identityNo)...); err != nil {
 		return nil, errors.Wrap(err, "DriverLicenseRepo.GetDrivingLicensesByIdentityNo.totalCount")
 	}
 
@@ -375,7 +542,7 @@ func (r *DriverLicenseRepo) GetDrivingLicensesByIdentityNo(ctx context.Context,
 	}
 
 	var licenses []*models.DrivingLicense
-	rows, err := r.db.QueryxContext(ctx, getDrivingLicensesByIdentityNo, identityNo, pq.GetOffset(), pq.GetLimit())
+	rows, err := r.db.QueryxContext(ctx, lc.Page(getDrivingLicensesByIdentityNo), lc.PageArgs(pq, identityNo)...)
 	if err != nil {
 		return nil, errors.Wrap(err, "DriverLicenseRepo.GetDrivingLicensesByIdentityNo.query")
 	}
@@ -402,3 +569,48 @@ func (r *DriverLicenseRepo) GetDrivingLicensesByIdentityNo(ctx context.Context,
 		DrivingLicense: licenses,
 	}, nil
 }
+
+func (r *DriverLicenseRepo) AddEndorsement(ctx context.Context, e *models.LicenseEndorsement) (*models.LicenseEndorsement, error) {
+	created, err := createEndorsement(ctx, r.db, e)
+	if err != nil {
+		return nil, errors.Wrap(err, "DriverLicenseRepo.AddEndorsement")
+	}
+	return created, nil
+}
+
+// Active endorsements of the cards
+func (r *DriverLicenseRepo) GetEndorsements(ctx context.Context, licenseIDs ...uuid.UUID) ([]*models.LicenseEndorsement, error) {
+	endorsements := []*models.LicenseEndorsement{}
+	if len(licenseIDs) == 0 {
+		return endorsements, nil
+	}
+
+	query, args, err := sqlx.In(getEndorsementsQuery, licenseIDs)
+	if err != nil {
+		return nil, errors.Wrap(err, "DriverLicenseRepo.GetEndorsements.In")
+	}
+	if err = r.db.SelectContext(ctx, &endorsements, r.db.Rebind(query), args...); err != nil {
+		return nil, errors.Wrap(err, "DriverLicenseRepo.GetEndorsements.SelectContext")
+	}
+	return endorsements, nil
+}
+
+// Cards of the citizen still in force, the latest issued first
+func (r *DriverLicenseRepo) GetHolderLicenses(ctx context.Context, identityNo string) ([]*models.DrivingLicense, error) {
+	licenses := []*models.DrivingLicense{}
+	if err := r.db.SelectContext(ctx, &licenses, getHolderLicensesQuery, identityNo); err != nil {
+		return nil, errors.Wrap(err, "DriverLicenseRepo.GetHolderLicenses.SelectContext")
+	}
+	return licenses, nil
+}
+
+func createEndorsement(ctx context.Context, q sqlx.QueryerContext, e *models.LicenseEndorsement) (*models.LicenseEndorsement, error) {
+	created := &models.LicenseEndorsement{}
+	if err := q.QueryRowxContext(ctx, createEndorsementQuery,
+		e.Id, e.LicenseID, e.LicenseType, e.IssueDate, e.ExpiryDate, e.Status, e.AuthorityId, e.CreatorId,
+		e.CreatedAt, e.UpdatedAt, e.Active,
+	).StructScan(created); err != nil {
+		return nil, errors.Wrap(err, "createEndorsement.StructScan")
+	}
+	return created, nil
+}
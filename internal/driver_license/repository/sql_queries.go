@@ -1,5 +1,7 @@
 package repository
 
+import "github.com/adohong4/driving-license/pkg/utils"
+
 const (
 	createDriverLicenseQuery = `
 	INSERT INTO driver_licenses (
@@ -35,7 +37,7 @@ const (
     		modifier_id         = COALESCE($14, modifier_id),
     		version             = version + 1,
     		updated_at          = $15
-		WHERE id = $16
+		WHERE id = $16 AND f_in_jurisdiction($17, owner_city)
 		RETURNING *
 	`
 
@@ -47,7 +49,7 @@ const (
         modifier_id = COALESCE($3, modifier_id),
         version = version + 1,
         updated_at = $4
-    WHERE id = $5
+    WHERE id = $5 AND version = $6 AND on_blockchain = false
     RETURNING *
     `
 
@@ -69,14 +71,22 @@ const (
 		version = version + 1,
 		modifier_id = $1,
 		updated_at = $2
-	WHERE id = $3
+	WHERE id = $3 AND f_in_jurisdiction($4, owner_city)
 	RETURNING *
 	`
 
 	getDriverLicenseByIdQuery = `
 	SELECT *
 	FROM driver_licenses
-	WHERE id = $1 AND active = true
+	WHERE id = $1 AND active = true AND f_in_jurisdiction($2, owner_city)
+	`
+
+	// license before an update, locked until its re-anchor is queued
+	getDriverLicenseForUpdateQuery = `
+	SELECT *
+	FROM driver_licenses
+	WHERE id = $1
+	FOR UPDATE
 	`
 
 	getDriverLicenseByWalletAddressQuery = `
@@ -88,13 +98,13 @@ const (
 	getDriverLicenseByLicenseNOQuery = `
 	SELECT *
 	FROM driver_licenses
-	WHERE license_no = $1 AND active = true
+	WHERE license_no = $1 AND active = true AND f_in_jurisdiction($2, owner_city)
 	`
 
 	getTotalCount = `
 	SELECT COUNT(id)
 	FROM driver_licenses
-	WHERE active = true
+	WHERE active = true AND f_in_jurisdiction($1, owner_city)
 	`
 
 	findLicenseNOCount = `
@@ -102,14 +112,13 @@ const (
 		FROM driver_licenses
 		WHERE active = true
 		AND license_no ILIKE '%' || $1 || '%'
+		AND f_in_jurisdiction($2, owner_city)
 	`
 
 	searchByLicenseNo = `
     SELECT * 
     FROM driver_licenses
-    WHERE license_no ILIKE '%' || $1 || '%' AND active = true	
-    ORDER BY license_no
-    OFFSET $2 LIMIT $3
+    WHERE license_no ILIKE '%' || $1 || '%' AND active = true AND f_in_jurisdiction($2, owner_city)
 `
 
 	getDriverLicense = `
@@ -118,8 +127,17 @@ const (
 		nationality, point, wallet_address, on_blockchain, blockchain_txhash, 
 		version, creator_id, modifier_id, created_at, updated_at, active
 	FROM driver_licenses
-	WHERE active = true
-	ORDER BY updated_at, created_at OFFSET $1 LIMIT $2
+	WHERE active = true AND f_in_jurisdiction($1, owner_city)
+	`
+
+	// Export, same filter as searchByLicenseNo without pagination
+	exportDriverLicenses = `
+	SELECT id, full_name, dob, identity_no, owner_address, owner_city, license_no, 
+		issue_date, expiry_date, status, license_type, authority_id, issuing_authority,
+		nationality, point, wallet_address, on_blockchain, blockchain_txhash, 
+		version, creator_id, modifier_id, created_at, updated_at, active
+	FROM driver_licenses
+	WHERE license_no ILIKE '%' || $1 || '%' AND active = true AND f_in_jurisdiction($2, owner_city)
 	`
 
 	findLicenseNO = `
@@ -128,19 +146,20 @@ const (
 	WHERE license_no = $1 AND active = true
 	`
 
-	// Statistic
+	// Statistic, read from mv_license_stats (see migrations), cards by status and city, endorsements by class.
+	// Counts are limited to the jurisdiction of the caller's agency, rolled up from the agencies below it
 	getStatusDistributionQuery = `
-        SELECT status, COUNT(*) as count
-        FROM driver_licenses
-        WHERE active = true
+        SELECT status, SUM(count)::int as count
+        FROM mv_license_stats
+        WHERE source = 'license' AND f_in_jurisdiction($1, owner_city)
         GROUP BY status
         ORDER BY count DESC
     `
 
 	getLicenseTypeDistributionQuery = `
-        SELECT license_type, COUNT(*) as count
-        FROM driver_licenses
-        WHERE active = true AND license_type IS NOT NULL AND license_type != ''
+        SELECT license_type, SUM(count)::int as count
+        FROM mv_license_stats
+        WHERE source = 'endorsement' AND f_in_jurisdiction($1, owner_city)
         GROUP BY license_type
         ORDER BY count DESC
     `
@@ -149,11 +168,9 @@ const (
         SELECT 
             license_type,
             status,
-            COUNT(*) as count
-        FROM driver_licenses
-        WHERE active = true 
-          AND license_type IS NOT NULL 
-          AND license_type != ''
+            SUM(count)::int as count
+        FROM mv_license_stats
+        WHERE source = 'endorsement' AND f_in_jurisdiction($1, owner_city)
         GROUP BY license_type, status
         ORDER BY license_type, 
                  count DESC,
@@ -162,15 +179,32 @@ const (
 
 	getCityStatusDistributionQuery = `
         SELECT 
-            COALESCE(owner_city, 'Không xác định') as owner_city,
+            owner_city,
             status,
-            COUNT(*) as count
-        FROM driver_licenses
-        WHERE active = true
+            SUM(count)::int as count
+        FROM mv_license_stats
+        WHERE source = 'license' AND f_in_jurisdiction($1, owner_city)
         GROUP BY owner_city, status
         ORDER BY count DESC, owner_city, status
     `
 
+	getLicensesIssuedSeriesQuery = `
+        SELECT
+            date_trunc($1, issue_date::timestamp) as period,
+            COUNT(*) as count,
+            0 as amount
+        FROM driver_licenses
+        WHERE active = true
+          AND issue_date IS NOT NULL
+          AND issue_date::timestamp >= $2 AND issue_date::timestamp < $3
+          AND ($4 = '' OR owner_city = $4)
+          AND ($5::uuid IS NULL OR authority_id = $5)
+          AND ($6 = '' OR license_type = $6)
+          AND f_in_jurisdiction($7, owner_city)
+        GROUP BY period
+        ORDER BY period
+    `
+
 	getDrivingLicensesByIdentityNo = `
         SELECT 
             id, full_name, avatar, dob, identity_no, owner_address, owner_city, license_no, 
@@ -179,8 +213,29 @@ const (
             version, creator_id, modifier_id, created_at, updated_at, active
         FROM driver_licenses
         WHERE identity_no = $1 AND active = true
-        ORDER BY updated_at DESC, created_at DESC
-        OFFSET $2 LIMIT $3
+    `
+
+	createEndorsementQuery = `
+    INSERT INTO license_endorsements (
+        id, license_id, license_type, issue_date, expiry_date, status, authority_id, creator_id, created_at, updated_at, active
+    ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+    RETURNING *
+    `
+
+	// sqlx.In expands the ids
+	getEndorsementsQuery = `
+    SELECT *
+    FROM license_endorsements
+    WHERE license_id IN (?) AND active = true
+    ORDER BY issue_date, license_type
+    `
+
+	// cards of a citizen still in force, replaced ones are left out
+	getHolderLicensesQuery = `
+    SELECT *
+    FROM driver_licenses
+    WHERE identity_no = $1 AND active = true AND COALESCE(status, '') <> 'superseded'
+    ORDER BY issue_date DESC, created_at DESC
     `
 
 	getTotalCountByIdentityNo = `
@@ -189,3 +244,26 @@ const (
         WHERE identity_no = $1 AND active = true
     `
 )
+
+// Filters and sorts accepted by the license lists, filter and sort clauses are appended to the queries above
+var driverLicenseListSpec = &utils.ListSpec{
+	Fields: map[string]utils.ListField{
+		"license_no":        {Column: "license_no", Type: utils.FilterText, Sortable: true},
+		"full_name":         {Column: "full_name", Type: utils.FilterText, Sortable: true},
+		"identity_no":       {Column: "identity_no", Type: utils.FilterExact},
+		"status":            {Column: "status", Type: utils.FilterExact, Sortable: true},
+		"license_type":      {Column: "license_type", Type: utils.FilterExact, Sortable: true},
+		"owner_city":        {Column: "owner_city", Type: utils.FilterExact, Sortable: true},
+		"nationality":       {Column: "nationality", Type: utils.FilterExact},
+		"authority_id":      {Column: "authority_id", Type: utils.FilterUUID},
+		"issuing_authority": {Column: "issuing_authority", Type: utils.FilterText},
+		"on_blockchain":     {Column: "on_blockchain", Type: utils.FilterBool},
+		"point":             {Column: "point", Type: utils.FilterNumber, Sortable: true},
+		"dob":               {Column: "dob", Type: utils.FilterDate, Sortable: true},
+		"issue_date":        {Column: "issue_date", Type: utils.FilterDate, Sortable: true},
+		"expiry_date":       {Column: "expiry_date", Type: utils.FilterDate, Sortable: true},
+		"created_at":        {Column: "created_at", Type: utils.FilterDate, Sortable: true},
+		"updated_at":        {Column: "updated_at", Type: utils.FilterDate, Sortable: true},
+	},
+	DefaultSort: "updated_at,created_at",
+}
@@ -2,8 +2,11 @@ package driverlicense
 
 import (
 	"context"
+	"time"
 
 	"github.com/adohong4/driving-license/internal/models"
+	"github.com/adohong4/driving-license/pkg/export"
+	"github.com/adohong4/driving-license/pkg/importer"
 	"github.com/adohong4/driving-license/pkg/utils"
 	"github.com/google/uuid"
 )
@@ -19,12 +22,18 @@ type UseCase interface {
 	GetDriverLicenseByWalletAddress(ctx context.Context, address string) (*models.DrivingLicense, error)
 	GetDriverLicenseByLicenseNO(ctx context.Context, address string) (*models.DrivingLicense, error)
 	SearchByLicenseNo(ctx context.Context, lno string, query *utils.PaginationQuery) (*models.DrivingLicenseList, error)
-	GetStatusDistribution(ctx context.Context) (*models.StatusDistributionResponse, error)
-	GetLicenseTypeDistribution(ctx context.Context) (*models.LicenseTypeDistributionResponse, error)
-	GetLicenseTypeStatusDistribution(ctx context.Context) (*models.LicenseTypeDetailDistributionResponse, error)
-	GetCityStatusDistribution(ctx context.Context) (*models.CityDetailDistributionResponse, error)
+	ExportDriverLicenses(ctx context.Context, eq *utils.ExportQuery) *export.Source
+	ImportDriverLicenses(ctx context.Context) *importer.Target
+	GetStatusDistribution(ctx context.Context) (*models.StatusDistributionResponse, time.Time, error)
+	GetLicenseTypeDistribution(ctx context.Context) (*models.LicenseTypeDistributionResponse, time.Time, error)
+	GetLicenseTypeStatusDistribution(ctx context.Context) (*models.LicenseTypeDetailDistributionResponse, time.Time, error)
+	GetCityStatusDistribution(ctx context.Context) (*models.CityDetailDistributionResponse, time.Time, error)
+	GetLicensesIssuedSeries(ctx context.Context, sq *utils.StatsQuery) (*models.TimeSeries, time.Time, error)
 
 	GetMyDrivingLicenses(ctx context.Context, identityNo string, pq *utils.PaginationQuery) (*models.DrivingLicenseList, error)
 	GetMyDrivingLicenseById(ctx context.Context, identityNo string, id uuid.UUID) (*models.DrivingLicense, error)
 	GetMyDrivingLicenseByLicenseNo(ctx context.Context, identityNo, licenseNo string) (*models.DrivingLicense, error)
+	GetMyLicenseHolder(ctx context.Context, identityNo string) (*models.LicenseHolder, error)
+
+	AddEndorsement(ctx context.Context, licenseID uuid.UUID, req *models.AddEndorsementRequest) (*models.LicenseEndorsement, error)
 }
@@ -3,27 +3,41 @@ package usecase
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/adohong4/driving-license/config"
+	"github.com/adohong4/driving-license/internal/anchor"
 	driverlicense "github.com/adohong4/driving-license/internal/driver_license"
 	"github.com/adohong4/driving-license/internal/models"
+	"github.com/adohong4/driving-license/internal/stats"
+	"github.com/adohong4/driving-license/pkg/export"
 	"github.com/adohong4/driving-license/pkg/httpErrors"
+	"github.com/adohong4/driving-license/pkg/importer"
 	"github.com/adohong4/driving-license/pkg/logger"
 	"github.com/adohong4/driving-license/pkg/utils"
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
 )
 
+// Roles allowed to endorse licenses for other classes
+var officerRoles = map[string]bool{"admin": true, "officer": true}
+
+// Statuses of cards that can no longer be endorsed
+var closedLicenseStatuses = map[string]bool{"revoke": true, "revoked": true, models.LicenseSuperseded: true}
+
 type DriverLicenseUC struct {
 	cfg               *config.Config
 	DriverLicenseRepo driverlicense.Repository
+	statsUC           stats.UseCase
+	anchorUC          anchor.UseCase
 	logger            logger.Logger
 }
 
-func NewDriverLicenseUseCase(cfg *config.Config, DriverLicenseRepo driverlicense.Repository, logger logger.Logger) driverlicense.UseCase {
-	return &DriverLicenseUC{cfg: cfg, DriverLicenseRepo: DriverLicenseRepo, logger: logger}
+func NewDriverLicenseUseCase(cfg *config.Config, DriverLicenseRepo driverlicense.Repository, statsUC stats.UseCase, anchorUC anchor.UseCase, logger logger.Logger) driverlicense.UseCase {
+	return &DriverLicenseUC{cfg: cfg, DriverLicenseRepo: DriverLicenseRepo, statsUC: statsUC, anchorUC: anchorUC, logger: logger}
 }
 
 func (u *DriverLicenseUC) CreateDriverLicense(ctx context.Context, dl *models.DrivingLicense) (*models.DrivingLicense, error) {
@@ -54,6 +68,7 @@ func (u *DriverLicenseUC) CreateDriverLicense(ctx context.Context, dl *models.Dr
 	if err != nil {
 		return nil, err
 	}
+	u.statsUC.MarkDirty(stats.DomainLicenses)
 
 	return n, nil
 }
@@ -78,6 +93,7 @@ func (u *DriverLicenseUC) UpdateDriverLicense(ctx context.Context, dl *models.Dr
 	if err != nil {
 		return nil, err
 	}
+	u.statsUC.MarkDirty(stats.DomainLicenses)
 
 	return updatedLicense, nil
 }
@@ -88,15 +104,42 @@ func (u *DriverLicenseUC) ConfirmBlockchainStorage(ctx context.Context, dl *mode
 		return nil, httpErrors.NewUnauthorizedError(errors.WithMessage(err, "DriverLicenseUC.ConfirmBlockchainStorage.GetUserFromCtx"))
 	}
 
+	dl.BlockchainTxHash = strings.TrimSpace(dl.BlockchainTxHash)
+	if dl.BlockchainTxHash == "" {
+		return nil, httpErrors.NewBadRequestError(errors.New("BlockchainTxHash is required"))
+	}
+
+	// the transaction must anchor the license as it is stored
+	current, err := u.DriverLicenseRepo.GetDriverLicenseById(ctx, dl.Id)
+	if err != nil {
+		return nil, err
+	}
+	if current.OnBlockchain {
+		if strings.EqualFold(current.BlockchainTxHash, dl.BlockchainTxHash) {
+			return current, nil
+		}
+		return nil, httpErrors.NewRestError(http.StatusConflict, "license already on the blockchain", nil)
+	}
+	recordHash, err := u.anchorUC.VerifyTransaction(ctx, models.AnchorLicense, current.Id, current, dl.BlockchainTxHash)
+	if err != nil {
+		return nil, err
+	}
+
 	dl.ModifierId = &user.Id
 	dl.OnBlockchain = true
+	dl.Version = current.Version
 	dl.UpdatedAt = time.Now()
 
-	if dl.BlockchainTxHash == "" {
-		return nil, httpErrors.NewBadRequestError(errors.New("BlockchainTxHash is required"))
+	updatedLicense, err := u.DriverLicenseRepo.ConfirmBlockchainStorage(ctx, dl, &models.BlockchainAnchor{
+		RecordType:  models.AnchorLicense,
+		RecordID:    current.Id,
+		RecordHash:  recordHash,
+		HashVersion: models.CanonicalVersion,
+		TxHash:      dl.BlockchainTxHash,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, httpErrors.NewRestError(http.StatusConflict, "license changed or was confirmed since the transaction was verified", nil)
 	}
-
-	updatedLicense, err := u.DriverLicenseRepo.ConfirmBlockchainStorage(ctx, dl)
 	if err != nil {
 		return nil, err
 	}
@@ -145,6 +188,7 @@ func (u *DriverLicenseUC) DeleteDriverLicense(ctx context.Context, dl *models.Dr
 	if err != nil {
 		return nil, err
 	}
+	u.statsUC.MarkDirty(stats.DomainLicenses)
 
 	return updatedLicense, nil
 }
@@ -158,6 +202,9 @@ func (u *DriverLicenseUC) GetDriverLicenseById(ctx context.Context, Id uuid.UUID
 	if err != nil {
 		return nil, err
 	}
+	if err = u.withEndorsements(ctx, n); err != nil {
+		return nil, err
+	}
 	return n, nil
 }
 
@@ -181,24 +228,294 @@ func (u *DriverLicenseUC) SearchByLicenseNo(ctx context.Context, lno string, que
 	return u.DriverLicenseRepo.SearchByLicenseNo(ctx, lno, query)
 }
 
-func (u *DriverLicenseUC) GetStatusDistribution(ctx context.Context) (*models.StatusDistributionResponse, error) {
-	return u.DriverLicenseRepo.GetStatusDistribution(ctx)
+// Import target for licenses, rows pass the same checks as CreateDriverLicense
+func (u *DriverLicenseUC) ImportDriverLicenses(ctx context.Context) *importer.Target {
+	seen := make(map[string]bool)
+
+	return &importer.Target{
+		Name:     "licenses",
+		Columns:  models.DrivingLicenseImportColumns,
+		Required: models.DrivingLicenseImportRequired,
+		Validate: func(ctx context.Context, row importer.Row) (any, error) {
+			dl, err := drivingLicenseFromRow(row)
+			if err != nil {
+				return nil, err
+			}
+
+			if seen[dl.LicenseNo] {
+				return nil, importer.NewFieldError("license_no", "duplicated in file")
+			}
+			existsLicenseNO, err := u.DriverLicenseRepo.FindLicenseNO(ctx, dl)
+			if err != nil {
+				return nil, errors.Wrap(err, "DriverLicenseUC.ImportDriverLicenses.FindLicenseNO")
+			}
+			if existsLicenseNO != nil {
+				return nil, importer.NewFieldError("license_no", httpErrors.ErrLicenseAlreadyExists)
+			}
+
+			if err = dl.PrepareCreate(); err != nil {
+				return nil, err
+			}
+
+			user, err := utils.GetUserFromCtx(ctx)
+			if err != nil {
+				return nil, errors.WithMessage(err, "DriverLicenseUC.ImportDriverLicenses.GetUserFromCtx")
+			}
+			dl.CreatorId = user.Id
+
+			if err = utils.ValidateStruct(ctx, dl); err != nil {
+				return nil, err
+			}
+
+			seen[dl.LicenseNo] = true
+			return dl, nil
+		},
+		Commit: func(ctx context.Context, records []any) error {
+			dls := make([]*models.DrivingLicense, 0, len(records))
+			for _, r := range records {
+				dls = append(dls, r.(*models.DrivingLicense))
+			}
+			if err := u.DriverLicenseRepo.CreateDriverLicenses(ctx, dls); err != nil {
+				return err
+			}
+			u.statsUC.MarkDirty(stats.DomainLicenses)
+			return nil
+		},
+	}
+}
+
+func drivingLicenseFromRow(row importer.Row) (*models.DrivingLicense, error) {
+	for _, key := range models.DrivingLicenseImportRequired {
+		if row.Get(key) == "" {
+			return nil, importer.NewFieldError(key, "is required")
+		}
+	}
+
+	dl := &models.DrivingLicense{
+		Name:             row.Get("full_name"),
+		IdentityNo:       row.Get("identity_no"),
+		OwnerAddress:     row.Get("owner_address"),
+		OwnerCity:        row.Get("owner_city"),
+		LicenseNo:        row.Get("license_no"),
+		LicenseType:      row.Get("license_type"),
+		IssuingAuthority: row.Get("issuing_authority"),
+		Nationality:      row.Get("nationality"),
+		Status:           row.Get("status"),
+	}
+
+	var err error
+	if dl.IssueDate, err = importer.ParseDate(row.Get("issue_date")); err != nil {
+		return nil, importer.NewFieldError("issue_date", err.Error())
+	}
+	if v := row.Get("dob"); v != "" {
+		if dl.DOB, err = importer.ParseDate(v); err != nil {
+			return nil, importer.NewFieldError("dob", err.Error())
+		}
+	}
+	if v := row.Get("expiry_date"); v != "" {
+		expiry, err := importer.ParseDate(v)
+		if err != nil {
+			return nil, importer.NewFieldError("expiry_date", err.Error())
+		}
+		dl.ExpiryDate = &expiry
+	}
+	if v := row.Get("authority_id"); v != "" {
+		if dl.AuthorityId, err = uuid.Parse(v); err != nil {
+			return nil, importer.NewFieldError("authority_id", "invalid UUID")
+		}
+	}
+	return dl, nil
+}
+
+// Export source for the license list, filtered like SearchByLicenseNo
+func (u *DriverLicenseUC) ExportDriverLicenses(ctx context.Context, eq *utils.ExportQuery) *export.Source {
+	return &export.Source{
+		Name:    "licenses",
+		Columns: models.DrivingLicenseExportColumns,
+		Count: func(ctx context.Context) (int, error) {
+			return u.DriverLicenseRepo.CountDriverLicenses(ctx, eq.Search, eq.ListQuery())
+		},
+		Rows: func(ctx context.Context, fn func(row []string) error) error {
+			return u.DriverLicenseRepo.StreamDriverLicenses(ctx, eq.Search, eq.ListQuery(), func(dl *models.DrivingLicense) error {
+				return fn(dl.ExportRow())
+			})
+		},
+	}
+}
+
+func (u *DriverLicenseUC) GetStatusDistribution(ctx context.Context) (*models.StatusDistributionResponse, time.Time, error) {
+	return stats.CachedView(ctx, u.statsUC, stats.DomainLicenses, "status", u.DriverLicenseRepo.GetStatusDistribution)
 }
 
-func (u *DriverLicenseUC) GetLicenseTypeDistribution(ctx context.Context) (*models.LicenseTypeDistributionResponse, error) {
-	return u.DriverLicenseRepo.GetLicenseTypeDistribution(ctx)
+func (u *DriverLicenseUC) GetLicenseTypeDistribution(ctx context.Context) (*models.LicenseTypeDistributionResponse, time.Time, error) {
+	return stats.CachedView(ctx, u.statsUC, stats.DomainLicenses, "license-type", u.DriverLicenseRepo.GetLicenseTypeDistribution)
 }
 
-func (u *DriverLicenseUC) GetLicenseTypeStatusDistribution(ctx context.Context) (*models.LicenseTypeDetailDistributionResponse, error) {
-	return u.DriverLicenseRepo.GetLicenseTypeStatusDistribution(ctx)
+func (u *DriverLicenseUC) GetLicenseTypeStatusDistribution(ctx context.Context) (*models.LicenseTypeDetailDistributionResponse, time.Time, error) {
+	return stats.CachedView(ctx, u.statsUC, stats.DomainLicenses, "license-type-detail", u.DriverLicenseRepo.GetLicenseTypeStatusDistribution)
 }
 
-func (u *DriverLicenseUC) GetCityStatusDistribution(ctx context.Context) (*models.CityDetailDistributionResponse, error) {
-	return u.DriverLicenseRepo.GetCityStatusDistribution(ctx)
+func (u *DriverLicenseUC) GetCityStatusDistribution(ctx context.Context) (*models.CityDetailDistributionResponse, time.Time, error) {
+	return stats.CachedView(ctx, u.statsUC, stats.DomainLicenses, "city-detail", u.DriverLicenseRepo.GetCityStatusDistribution)
+}
+
+func (u *DriverLicenseUC) GetLicensesIssuedSeries(ctx context.Context, sq *utils.StatsQuery) (*models.TimeSeries, time.Time, error) {
+	if sq.ViolationType != "" {
+		return nil, time.Time{}, httpErrors.NewBadRequestError("violation_type filter is not supported for licenses")
+	}
+
+	return stats.CachedLive(ctx, u.statsUC, stats.DomainLicenses, "series:"+sq.CacheKey(), func(ctx context.Context) (*models.TimeSeries, error) {
+		points, err := u.DriverLicenseRepo.GetLicensesIssuedSeries(ctx, sq)
+		if err != nil {
+			return nil, err
+		}
+		return utils.BuildTimeSeries("licenses_issued", sq, points, false), nil
+	})
 }
 
 func (u *DriverLicenseUC) GetMyDrivingLicenses(ctx context.Context, identityNo string, pq *utils.PaginationQuery) (*models.DrivingLicenseList, error) {
-	return u.DriverLicenseRepo.GetDrivingLicensesByIdentityNo(ctx, identityNo, pq)
+	list, err := u.DriverLicenseRepo.GetDrivingLicensesByIdentityNo(ctx, identityNo, pq)
+	if err != nil {
+		return nil, err
+	}
+	if err = u.withEndorsements(ctx, list.DrivingLicense...); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// License card of the citizen with every class held on the cards still in force
+func (u *DriverLicenseUC) GetMyLicenseHolder(ctx context.Context, identityNo string) (*models.LicenseHolder, error) {
+	if identityNo == "" {
+		return nil, httpErrors.NewBadRequestError("user identity number is missing")
+	}
+
+	licenses, err := u.DriverLicenseRepo.GetHolderLicenses(ctx, identityNo)
+	if err != nil {
+		return nil, err
+	}
+	if len(licenses) == 0 {
+		return nil, httpErrors.NewRestError(http.StatusNotFound, "the citizen holds no driving license", nil)
+	}
+	if err = u.withEndorsements(ctx, licenses...); err != nil {
+		return nil, err
+	}
+	return models.NewLicenseHolder(licenses), nil
+}
+
+// An officer endorses a card for another class. The holder must be old enough for the class and already hold
+// one of its prerequisite classes on one of the cards in force, a class is held at most once.
+func (u *DriverLicenseUC) AddEndorsement(ctx context.Context, licenseID uuid.UUID, req *models.AddEndorsementRequest) (*models.LicenseEndorsement, error) {
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return nil, httpErrors.NewUnauthorizedError(errors.WithMessage(err, "DriverLicenseUC.AddEndorsement.GetUserFromCtx"))
+	}
+	if user.Role == nil || !officerRoles[*user.Role] {
+		return nil, httpErrors.NewForbiddenError(httpErrors.PermissionDenied)
+	}
+	if err = utils.ValidateStruct(ctx, req); err != nil {
+		return nil, httpErrors.NewBadRequestError(errors.WithMessage(err, "DriverLicenseUC.AddEndorsement.ValidateStruct"))
+	}
+
+	card, err := u.DriverLicenseRepo.GetDriverLicenseById(ctx, licenseID)
+	if err != nil {
+		return nil, err
+	}
+	if closedLicenseStatuses[strings.ToLower(card.Status)] {
+		return nil, httpErrors.NewRestError(http.StatusConflict, fmt.Sprintf("the license is %s", card.Status), nil)
+	}
+
+	licenses, err := u.DriverLicenseRepo.GetHolderLicenses(ctx, card.IdentityNo)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uuid.UUID, 0, len(licenses))
+	for _, dl := range licenses {
+		ids = append(ids, dl.Id)
+	}
+	endorsements, err := u.DriverLicenseRepo.GetEndorsements(ctx, ids...)
+	if err != nil {
+		return nil, err
+	}
+
+	licenseType := strings.ToUpper(strings.TrimSpace(req.LicenseType))
+	var held []string
+	for _, e := range endorsements {
+		// a revoked class can be endorsed again on another card
+		if e.LicenseType == licenseType && (e.Valid() || e.LicenseID == card.Id) {
+			return nil, httpErrors.NewRestError(http.StatusConflict, fmt.Sprintf("the holder is already endorsed for class %s", licenseType), nil)
+		}
+		if e.Valid() {
+			held = append(held, e.LicenseType)
+		}
+	}
+	if !models.MeetsPrerequisites(licenseType, held) {
+		return nil, httpErrors.NewBadRequestError(fmt.Sprintf("class %s requires one of the classes %s",
+			licenseType, strings.Join(models.ClassPrerequisites(licenseType), ", ")))
+	}
+
+	now := time.Now()
+	e := &models.LicenseEndorsement{
+		Id:          uuid.New(),
+		LicenseID:   card.Id,
+		LicenseType: licenseType,
+		IssueDate:   req.IssueDate,
+		Status:      "active",
+		AuthorityId: &card.AuthorityId,
+		CreatorId:   &user.Id,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		Active:      true,
+	}
+	if e.IssueDate == "" {
+		e.IssueDate = now.Format(time.DateOnly)
+	}
+	issued, _ := time.Parse(time.DateOnly, e.IssueDate)
+	if issued.After(now) {
+		return nil, httpErrors.NewBadRequestError("the issue date cannot be in the future")
+	}
+	if age, ok := utils.DrivingAge(licenseType); ok && len(card.DOB) >= len(time.DateOnly) {
+		if dob, err := time.Parse(time.DateOnly, card.DOB[:len(time.DateOnly)]); err == nil && dob.AddDate(age, 0, 0).After(issued) {
+			return nil, httpErrors.NewBadRequestError(fmt.Sprintf("the holder must be at least %d to drive class %s", age, licenseType))
+		}
+	}
+	e.ExpiryDate = models.LicenseExpiry(licenseType, issued)
+	if req.ExpiryDate != "" {
+		e.ExpiryDate = &req.ExpiryDate
+	}
+
+	created, err := u.DriverLicenseRepo.AddEndorsement(ctx, e)
+	if err != nil {
+		return nil, err
+	}
+	u.statsUC.MarkDirty(stats.DomainLicenses)
+
+	return created, nil
+}
+
+// Attach the endorsements of the cards
+func (u *DriverLicenseUC) withEndorsements(ctx context.Context, licenses ...*models.DrivingLicense) error {
+	byCard := make(map[uuid.UUID]*models.DrivingLicense, len(licenses))
+	ids := make([]uuid.UUID, 0, len(licenses))
+	for _, dl := range licenses {
+		if dl == nil {
+			continue
+		}
+		dl.Endorsements = []*models.LicenseEndorsement{}
+		byCard[dl.Id] = dl
+		ids = append(ids, dl.Id)
+	}
+
+	endorsements, err := u.DriverLicenseRepo.GetEndorsements(ctx, ids...)
+	if err != nil {
+		return err
+	}
+	for _, e := range endorsements {
+		if dl, ok := byCard[e.LicenseID]; ok {
+			dl.Endorsements = append(dl.Endorsements, e)
+		}
+	}
+	return nil
 }
 
 func (u *DriverLicenseUC) GetMyDrivingLicenseById(ctx context.Context, identityNo string, id uuid.UUID) (*models.DrivingLicense, error) {
@@ -209,6 +526,9 @@ func (u *DriverLicenseUC) GetMyDrivingLicenseById(ctx context.Context, identityN
 	if dl == nil || dl.IdentityNo != identityNo {
 		return nil, nil
 	}
+	if err = u.withEndorsements(ctx, dl); err != nil {
+		return nil, err
+	}
 	return dl, nil
 }
 
@@ -220,5 +540,8 @@ func (u *DriverLicenseUC) GetMyDrivingLicenseByLicenseNo(ctx context.Context, id
 	if dl == nil || dl.IdentityNo != identityNo {
 		return nil, nil
 	}
+	if err = u.withEndorsements(ctx, dl); err != nil {
+		return nil, err
+	}
 	return dl, nil
 }
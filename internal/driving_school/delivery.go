@@ -0,0 +1,22 @@
+package drivingSchool
+
+import "github.com/labstack/echo/v4"
+
+type Handlers interface {
+	Enroll() echo.HandlerFunc
+	Withdraw() echo.HandlerFunc
+	IssueLicense() echo.HandlerFunc
+	GetEnrollment() echo.HandlerFunc
+	GetEnrollments() echo.HandlerFunc
+	GetMyEnrollments() echo.HandlerFunc
+
+	CreateSession() echo.HandlerFunc
+	CancelSession() echo.HandlerFunc
+	GetSession() echo.HandlerFunc
+	GetSessions() echo.HandlerFunc
+	GetSessionBookings() echo.HandlerFunc
+
+	BookSession() echo.HandlerFunc
+	CancelBooking() echo.HandlerFunc
+	RecordResult() echo.HandlerFunc
+}
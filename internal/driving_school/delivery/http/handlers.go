@@ -0,0 +1,521 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/adohong4/driving-license/config"
+	drivingSchool "github.com/adohong4/driving-license/internal/driving_school"
+	"github.com/adohong4/driving-license/internal/models"
+	"github.com/adohong4/driving-license/pkg/httpErrors"
+	"github.com/adohong4/driving-license/pkg/logger"
+	"github.com/adohong4/driving-license/pkg/utils"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type drivingSchoolHandlers struct {
+	cfg      *config.Config
+	schoolUC drivingSchool.UseCase
+	logger   logger.Logger
+}
+
+func NewDrivingSchoolHandlers(cfg *config.Config, schoolUC drivingSchool.UseCase, logger logger.Logger) drivingSchool.Handlers {
+	return &drivingSchoolHandlers{cfg: cfg, schoolUC: schoolUC, logger: logger}
+}
+
+// Enroll godoc
+// @Summary      Enroll a learner at a driving school
+// @Description  Officer only. Enrolls a learner at a training agency for a class, the learner must be old enough to drive it and is enrolled once per class at a time
+// @Tags         driving-school
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.EnrollRequest  true  "School, learner and class"
+// @Success      201      {object}  models.SchoolEnrollment
+// @Failure      400      {object}  httpErrors.Problem
+// @Failure      401      {object}  httpErrors.Problem
+// @Failure      403      {object}  httpErrors.Problem
+// @Failure      404      {object}  httpErrors.Problem
+// @Failure      409      {object}  httpErrors.Problem
+// @Failure      500      {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /school/enrollments [post]
+func (h *drivingSchoolHandlers) Enroll() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		req := &models.EnrollRequest{}
+		if err := c.Bind(req); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		res, err := h.schoolUC.Enroll(ctx, req)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusCreated, res)
+	}
+}
+
+// Withdraw godoc
+// @Summary      Withdraw an enrollment
+// @Description  Officer only. Withdraws an open enrollment, the seats it still holds are cancelled
+// @Tags         driving-school
+// @Produce      json
+// @Param        id   path      string  true  "Enrollment ID (UUID)"
+// @Success      200  {object}  models.SchoolEnrollment
+// @Failure      400  {object}  httpErrors.Problem
+// @Failure      401  {object}  httpErrors.Problem
+// @Failure      403  {object}  httpErrors.Problem
+// @Failure      404  {object}  httpErrors.Problem
+// @Failure      409  {object}  httpErrors.Problem
+// @Failure      500  {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /school/enrollments/{id}/withdraw [post]
+func (h *drivingSchoolHandlers) Withdraw() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		res, err := h.schoolUC.Withdraw(ctx, id)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+// IssueLicense godoc
+// @Summary      Issue the license of an enrollment
+// @Description  Officer only. Issues the pending license of a learner who passed both exams when it could not be issued along with the result
+// @Tags         driving-school
+// @Produce      json
+// @Param        id   path      string  true  "Enrollment ID (UUID)"
+// @Success      201  {object}  models.ExamResult
+// @Failure      400  {object}  httpErrors.Problem
+// @Failure      401  {object}  httpErrors.Problem
+// @Failure      403  {object}  httpErrors.Problem
+// @Failure      404  {object}  httpErrors.Problem
+// @Failure      409  {object}  httpErrors.Problem
+// @Failure      500  {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /school/enrollments/{id}/license [post]
+func (h *drivingSchoolHandlers) IssueLicense() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		res, err := h.schoolUC.IssueLicense(ctx, id)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusCreated, res)
+	}
+}
+
+// GetEnrollment godoc
+// @Summary      Get an enrollment
+// @Description  Learners read their own enrollments, officers read any
+// @Tags         driving-school
+// @Produce      json
+// @Param        id   path      string  true  "Enrollment ID (UUID)"
+// @Success      200  {object}  models.SchoolEnrollment
+// @Failure      400  {object}  httpErrors.Problem
+// @Failure      401  {object}  httpErrors.Problem
+// @Failure      404  {object}  httpErrors.Problem
+// @Failure      500  {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /school/enrollments/{id} [get]
+func (h *drivingSchoolHandlers) GetEnrollment() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		res, err := h.schoolUC.GetEnrollmentByID(ctx, id)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+// GetEnrollments godoc
+// @Summary      List enrollments
+// @Description  Officer only. Filterable by school_id, identity_no, full_name, license_type, status and dates
+// @Tags         driving-school
+// @Produce      json
+// @Param        page        query     int     false  "Page number (default: 1)"
+// @Param        size        query     int     false  "Page size (default: 10)"
+// @Param        sort        query     string  false  "Sort fields, comma separated, - prefix for descending, e.g. -created_at"
+// @Param        status      query     string  false  "enrolled, licensed or withdrawn"
+// @Param        school_id   query     string  false  "Driving school ID (UUID)"
+// @Param        cursor      query     string  false  "Cursor of the next page, empty for the first one, switches to cursor pagination"
+// @Param        with_total  query     bool    false  "Return an approximate total_count on cursor pages"
+// @Success      200         {object}  models.SchoolEnrollmentList
+// @Failure      400         {object}  httpErrors.Problem
+// @Failure      401         {object}  httpErrors.Problem
+// @Failure      403         {object}  httpErrors.Problem
+// @Failure      500         {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /school/enrollments [get]
+func (h *drivingSchoolHandlers) GetEnrollments() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		pq, err := utils.GetPaginationFromCtx(c)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		list, err := h.schoolUC.GetEnrollments(ctx, pq)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, list)
+	}
+}
+
+// GetMyEnrollments godoc
+// @Summary      List my enrollments
+// @Description  Driving school enrollments of the current user
+// @Tags         driving-school
+// @Produce      json
+// @Param        page        query     int     false  "Page number (default: 1)"
+// @Param        size        query     int     false  "Page size (default: 10)"
+// @Param        sort        query     string  false  "Sort fields, comma separated, - prefix for descending, e.g. -created_at"
+// @Param        cursor      query     string  false  "Cursor of the next page, empty for the first one, switches to cursor pagination"
+// @Param        with_total  query     bool    false  "Return an approximate total_count on cursor pages"
+// @Success      200         {object}  models.SchoolEnrollmentList
+// @Failure      400         {object}  httpErrors.Problem
+// @Failure      401         {object}  httpErrors.Problem
+// @Failure      500         {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /school/enrollments/me [get]
+func (h *drivingSchoolHandlers) GetMyEnrollments() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		pq, err := utils.GetPaginationFromCtx(c)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		list, err := h.schoolUC.GetMyEnrollments(ctx, pq)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, list)
+	}
+}
+
+// CreateSession godoc
+// @Summary      Schedule an exam session
+// @Description  Officer only. Schedules a theory or practical exam session of a class at an agency, starting in the future
+// @Tags         driving-school
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.CreateSessionRequest  true  "Agency, exam, class, start and capacity"
+// @Success      201      {object}  models.ExamSession
+// @Failure      400      {object}  httpErrors.Problem
+// @Failure      401      {object}  httpErrors.Problem
+// @Failure      403      {object}  httpErrors.Problem
+// @Failure      404      {object}  httpErrors.Problem
+// @Failure      500      {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /school/sessions [post]
+func (h *drivingSchoolHandlers) CreateSession() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		req := &models.CreateSessionRequest{}
+		if err := c.Bind(req); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		res, err := h.schoolUC.CreateSession(ctx, req)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusCreated, res)
+	}
+}
+
+// CancelSession godoc
+// @Summary      Cancel an exam session
+// @Description  Officer only. Cancels a session before it starts along with every seat booked in it
+// @Tags         driving-school
+// @Produce      json
+// @Param        id   path      string  true  "Session ID (UUID)"
+// @Success      200  {object}  models.ExamSession
+// @Failure      400  {object}  httpErrors.Problem
+// @Failure      401  {object}  httpErrors.Problem
+// @Failure      403  {object}  httpErrors.Problem
+// @Failure      404  {object}  httpErrors.Problem
+// @Failure      409  {object}  httpErrors.Problem
+// @Failure      500  {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /school/sessions/{id}/cancel [post]
+func (h *drivingSchoolHandlers) CancelSession() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		res, err := h.schoolUC.CancelSession(ctx, id)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+// GetSession godoc
+// @Summary      Get an exam session
+// @Description  Session with its capacity and booked seats
+// @Tags         driving-school
+// @Produce      json
+// @Param        id   path      string  true  "Session ID (UUID)"
+// @Success      200  {object}  models.ExamSession
+// @Failure      400  {object}  httpErrors.Problem
+// @Failure      401  {object}  httpErrors.Problem
+// @Failure      404  {object}  httpErrors.Problem
+// @Failure      500  {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /school/sessions/{id} [get]
+func (h *drivingSchoolHandlers) GetSession() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		res, err := h.schoolUC.GetSessionByID(ctx, id)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+// GetSessions godoc
+// @Summary      List exam sessions
+// @Description  Filterable by agency_id, kind, license_type, status and dates, sorted by start by default
+// @Tags         driving-school
+// @Produce      json
+// @Param        page          query     int     false  "Page number (default: 1)"
+// @Param        size          query     int     false  "Page size (default: 10)"
+// @Param        sort          query     string  false  "Sort fields, comma separated, - prefix for descending, e.g. -created_at"
+// @Param        kind          query     string  false  "theory or practical"
+// @Param        license_type  query     string  false  "License class"
+// @Param        agency_id     query     string  false  "Agency ID (UUID)"
+// @Param        cursor        query     string  false  "Cursor of the next page, empty for the first one, switches to cursor pagination"
+// @Param        with_total    query     bool    false  "Return an approximate total_count on cursor pages"
+// @Success      200           {object}  models.ExamSessionList
+// @Failure      400           {object}  httpErrors.Problem
+// @Failure      401           {object}  httpErrors.Problem
+// @Failure      500           {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /school/sessions [get]
+func (h *drivingSchoolHandlers) GetSessions() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		pq, err := utils.GetPaginationFromCtx(c)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		list, err := h.schoolUC.GetSessions(ctx, pq)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, list)
+	}
+}
+
+// GetSessionBookings godoc
+// @Summary      List the candidates of a session
+// @Description  Officer only. Seats of the session in booking order with their results
+// @Tags         driving-school
+// @Produce      json
+// @Param        id   path      string  true  "Session ID (UUID)"
+// @Success      200  {object}  models.ExamBookingList
+// @Failure      400  {object}  httpErrors.Problem
+// @Failure      401  {object}  httpErrors.Problem
+// @Failure      403  {object}  httpErrors.Problem
+// @Failure      404  {object}  httpErrors.Problem
+// @Failure      500  {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /school/sessions/{id}/bookings [get]
+func (h *drivingSchoolHandlers) GetSessionBookings() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		res, err := h.schoolUC.GetSessionBookings(ctx, id)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+// BookSession godoc
+// @Summary      Book a seat in an exam session
+// @Description  The learner, or an officer, books a seat for an enrollment of the class of the session. The practical exam is booked once the theory one is passed and an exam is booked once at a time
+// @Tags         driving-school
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string  true  "Session ID (UUID)"
+// @Param        request  body      models.BookSessionRequest  true  "Enrollment"
+// @Success      201      {object}  models.ExamBooking
+// @Failure      400      {object}  httpErrors.Problem
+// @Failure      401      {object}  httpErrors.Problem
+// @Failure      404      {object}  httpErrors.Problem
+// @Failure      409      {object}  httpErrors.Problem
+// @Failure      500      {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /school/sessions/{id}/bookings [post]
+func (h *drivingSchoolHandlers) BookSession() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		req := &models.BookSessionRequest{}
+		if err = c.Bind(req); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		res, err := h.schoolUC.BookSession(ctx, id, req)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusCreated, res)
+	}
+}
+
+// CancelBooking godoc
+// @Summary      Cancel a booking
+// @Description  The learner, or an officer, cancels a seat before the session starts and the seat is given back
+// @Tags         driving-school
+// @Produce      json
+// @Param        id   path      string  true  "Booking ID (UUID)"
+// @Success      200  {object}  models.ExamBooking
+// @Failure      400  {object}  httpErrors.Problem
+// @Failure      401  {object}  httpErrors.Problem
+// @Failure      404  {object}  httpErrors.Problem
+// @Failure      409  {object}  httpErrors.Problem
+// @Failure      500  {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /school/bookings/{id}/cancel [post]
+func (h *drivingSchoolHandlers) CancelBooking() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		res, err := h.schoolUC.CancelBooking(ctx, id)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+// RecordResult godoc
+// @Summary      Record an exam result
+// @Description  Officer only. Records the result of a seat once the session started and notifies the learner. When it completes both exams a pending license is issued by the agency of the session
+// @Tags         driving-school
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string  true  "Booking ID (UUID)"
+// @Param        request  body      models.ExamResultRequest  true  "Result, score and note"
+// @Success      200      {object}  models.ExamResult
+// @Failure      400      {object}  httpErrors.Problem
+// @Failure      401      {object}  httpErrors.Problem
+// @Failure      403      {object}  httpErrors.Problem
+// @Failure      404      {object}  httpErrors.Problem
+// @Failure      409      {object}  httpErrors.Problem
+// @Failure      500      {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /school/bookings/{id}/result [post]
+func (h *drivingSchoolHandlers) RecordResult() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		req := &models.ExamResultRequest{}
+		if err = c.Bind(req); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		res, err := h.schoolUC.RecordResult(ctx, id, req)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
@@ -0,0 +1,30 @@
+package http
+
+import (
+	"github.com/adohong4/driving-license/config"
+	"github.com/adohong4/driving-license/internal/auth"
+	drivingSchool "github.com/adohong4/driving-license/internal/driving_school"
+	"github.com/adohong4/driving-license/internal/middleware"
+	"github.com/labstack/echo/v4"
+)
+
+var officerRoles = []string{"admin", "officer"}
+
+func MapDrivingSchoolRoutes(schoolGroup *echo.Group, h drivingSchool.Handlers, mw *middleware.MiddlewareManager, cfg *config.Config, authUC auth.UseCase) {
+	schoolGroup.POST("/enrollments", h.Enroll(), mw.AuthJWTMiddleware(authUC, cfg), mw.RoleBasedAuthMiddleware(officerRoles))
+	schoolGroup.GET("/enrollments", h.GetEnrollments(), mw.AuthJWTMiddleware(authUC, cfg), mw.RoleBasedAuthMiddleware(officerRoles))
+	schoolGroup.GET("/enrollments/me", h.GetMyEnrollments(), mw.AuthJWTMiddleware(authUC, cfg))
+	schoolGroup.GET("/enrollments/:id", h.GetEnrollment(), mw.AuthJWTMiddleware(authUC, cfg))
+	schoolGroup.POST("/enrollments/:id/withdraw", h.Withdraw(), mw.AuthJWTMiddleware(authUC, cfg), mw.RoleBasedAuthMiddleware(officerRoles))
+	schoolGroup.POST("/enrollments/:id/license", h.IssueLicense(), mw.AuthJWTMiddleware(authUC, cfg), mw.RoleBasedAuthMiddleware(officerRoles))
+
+	schoolGroup.POST("/sessions", h.CreateSession(), mw.AuthJWTMiddleware(authUC, cfg), mw.RoleBasedAuthMiddleware(officerRoles))
+	schoolGroup.GET("/sessions", h.GetSessions(), mw.AuthJWTMiddleware(authUC, cfg))
+	schoolGroup.GET("/sessions/:id", h.GetSession(), mw.AuthJWTMiddleware(authUC, cfg))
+	schoolGroup.POST("/sessions/:id/cancel", h.CancelSession(), mw.AuthJWTMiddleware(authUC, cfg), mw.RoleBasedAuthMiddleware(officerRoles))
+	schoolGroup.GET("/sessions/:id/bookings", h.GetSessionBookings(), mw.AuthJWTMiddleware(authUC, cfg), mw.RoleBasedAuthMiddleware(officerRoles))
+	schoolGroup.POST("/sessions/:id/bookings", h.BookSession(), mw.AuthJWTMiddleware(authUC, cfg))
+
+	schoolGroup.POST("/bookings/:id/cancel", h.CancelBooking(), mw.AuthJWTMiddleware(authUC, cfg))
+	schoolGroup.POST("/bookings/:id/result", h.RecordResult(), mw.AuthJWTMiddleware(authUC, cfg), mw.RoleBasedAuthMiddleware(officerRoles))
+}
@@ -0,0 +1,39 @@
+package drivingSchool
+
+import (
+	"context"
+	"time"
+
+	"github.com/adohong4/driving-license/internal/models"
+	"github.com/adohong4/driving-license/pkg/utils"
+	"github.com/google/uuid"
+)
+
+type Repository interface {
+	CreateEnrollment(ctx context.Context, e *models.SchoolEnrollment) (*models.SchoolEnrollment, error)
+	GetEnrollmentByID(ctx context.Context, enrollmentID uuid.UUID) (*models.SchoolEnrollment, error)
+	GetOpenEnrollment(ctx context.Context, identityNo, licenseType string) (*models.SchoolEnrollment, error)
+	WithdrawEnrollment(ctx context.Context, enrollmentID uuid.UUID) (*models.SchoolEnrollment, error)
+	ClaimEnrollmentIssue(ctx context.Context, enrollmentID uuid.UUID, lease time.Duration) (*models.SchoolEnrollment, error)
+	ReleaseEnrollmentIssue(ctx context.Context, enrollmentID uuid.UUID) error
+	SetEnrollmentLicense(ctx context.Context, enrollmentID, licenseID uuid.UUID) (*models.SchoolEnrollment, error)
+	GetEnrollments(ctx context.Context, pq *utils.PaginationQuery) (*models.SchoolEnrollmentList, error)
+	GetEnrollmentsByIdentity(ctx context.Context, identityNo string, pq *utils.PaginationQuery) (*models.SchoolEnrollmentList, error)
+
+	CreateSession(ctx context.Context, s *models.ExamSession) (*models.ExamSession, error)
+	GetSessionByID(ctx context.Context, sessionID uuid.UUID) (*models.ExamSession, error)
+	CancelSession(ctx context.Context, sessionID uuid.UUID) (*models.ExamSession, error)
+	GetSessions(ctx context.Context, pq *utils.PaginationQuery) (*models.ExamSessionList, error)
+
+	BookSession(ctx context.Context, b *models.ExamBooking) (*models.ExamBooking, error)
+	GetBookingByID(ctx context.Context, bookingID uuid.UUID) (*models.ExamBooking, error)
+	GetOpenBooking(ctx context.Context, enrollmentID uuid.UUID, kind string) (*models.ExamBooking, error)
+	GetPassedBooking(ctx context.Context, enrollmentID uuid.UUID, kind string) (*models.ExamBooking, error)
+	CancelBooking(ctx context.Context, bookingID uuid.UUID) (*models.ExamBooking, error)
+	RecordResult(ctx context.Context, b *models.ExamBooking) (*models.ExamBooking, *models.SchoolEnrollment, error)
+	GetSessionBookings(ctx context.Context, sessionID uuid.UUID) ([]*models.ExamBooking, error)
+
+	GetAgency(ctx context.Context, agencyID uuid.UUID) (*models.GovAgency, error)
+	GetUserIDByIdentity(ctx context.Context, identityNo string) (*uuid.UUID, error)
+	LicenseNoTaken(ctx context.Context, licenseNo string) (bool, error)
+}
@@ -0,0 +1,389 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	drivingSchool "github.com/adohong4/driving-license/internal/driving_school"
+	"github.com/adohong4/driving-license/internal/models"
+	"github.com/adohong4/driving-license/pkg/utils"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+type drivingSchoolRepo struct {
+	db *sqlx.DB
+}
+
+func NewDrivingSchoolRepo(db *sqlx.DB) drivingSchool.Repository {
+	return &drivingSchoolRepo{db: db}
+}
+
+func (r *drivingSchoolRepo) CreateEnrollment(ctx context.Context, e *models.SchoolEnrollment) (*models.SchoolEnrollment, error) {
+	created := &models.SchoolEnrollment{}
+	if err := r.db.QueryRowxContext(ctx, createEnrollmentQuery,
+		e.Id, e.SchoolID, e.SchoolName, e.LearnerID, e.IdentityNo, e.FullName, e.DOB, e.LicenseType, e.Status,
+		e.CreatorId, e.CreatedAt, e.UpdatedAt,
+	).StructScan(created); err != nil {
+		return nil, errors.Wrap(err, "drivingSchoolRepo.CreateEnrollment.StructScan")
+	}
+	return created, nil
+}
+
+func (r *drivingSchoolRepo) GetEnrollmentByID(ctx context.Context, enrollmentID uuid.UUID) (*models.SchoolEnrollment, error) {
+	e := &models.SchoolEnrollment{}
+	if err := r.db.GetContext(ctx, e, getEnrollmentByID, enrollmentID); err != nil {
+		return nil, errors.Wrap(err, "drivingSchoolRepo.GetEnrollmentByID.GetContext")
+	}
+	return e, nil
+}
+
+// Open enrollment of the learner for the class, nil when there is none
+func (r *drivingSchoolRepo) GetOpenEnrollment(ctx context.Context, identityNo, licenseType string) (*models.SchoolEnrollment, error) {
+	e := &models.SchoolEnrollment{}
+	err := r.db.GetContext(ctx, e, getOpenEnrollment, identityNo, licenseType)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "drivingSchoolRepo.GetOpenEnrollment.GetContext")
+	}
+	return e, nil
+}
+
+// Withdraw the learner and cancel the seats still booked.
+// sql.ErrNoRows when the enrollment is no longer open.
+func (r *drivingSchoolRepo) WithdrawEnrollment(ctx context.Context, enrollmentID uuid.UUID) (*models.SchoolEnrollment, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "drivingSchoolRepo.WithdrawEnrollment.BeginTxx")
+	}
+	defer tx.Rollback()
+
+	withdrawn := &models.SchoolEnrollment{}
+	if err = tx.QueryRowxContext(ctx, withdrawEnrollmentQuery, enrollmentID).StructScan(withdrawn); err != nil {
+		return nil, errors.Wrap(err, "drivingSchoolRepo.WithdrawEnrollment.StructScan")
+	}
+
+	var bookings []*models.ExamBooking
+	if err = tx.SelectContext(ctx, &bookings, cancelEnrollmentBookingsQuery, enrollmentID); err != nil {
+		return nil, errors.Wrap(err, "drivingSchoolRepo.WithdrawEnrollment.cancelBookings")
+	}
+	for _, b := range bookings {
+		if _, err = tx.ExecContext(ctx, releaseSeatQuery, b.SessionID); err != nil {
+			return nil, errors.Wrap(err, "drivingSchoolRepo.WithdrawEnrollment.releaseSeat")
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "drivingSchoolRepo.WithdrawEnrollment.Commit")
+	}
+	return withdrawn, nil
+}
+
+// Link the license issued to the learner, sql.ErrNoRows when it is already linked or the enrollment is not complete
+// Lease the enrollment to issue its license, sql.ErrNoRows when it does not await one or another caller holds it
+func (r *drivingSchoolRepo) ClaimEnrollmentIssue(ctx context.Context, enrollmentID uuid.UUID, lease time.Duration) (*models.SchoolEnrollment, error) {
+	e := &models.SchoolEnrollment{}
+	if err := r.db.QueryRowxContext(ctx, claimEnrollmentIssueQuery, enrollmentID, lease.Seconds()).StructScan(e); err != nil {
+		return nil, errors.Wrap(err, "drivingSchoolRepo.ClaimEnrollmentIssue.StructScan")
+	}
+	return e, nil
+}
+
+func (r *drivingSchoolRepo) ReleaseEnrollmentIssue(ctx context.Context, enrollmentID uuid.UUID) error {
+	if _, err := r.db.ExecContext(ctx, releaseEnrollmentIssueQuery, enrollmentID); err != nil {
+		return errors.Wrap(err, "drivingSchoolRepo.ReleaseEnrollmentIssue.ExecContext")
+	}
+	return nil
+}
+
+func (r *drivingSchoolRepo) SetEnrollmentLicense(ctx context.Context, enrollmentID, licenseID uuid.UUID) (*models.SchoolEnrollment, error) {
+	e := &models.SchoolEnrollment{}
+	if err := r.db.QueryRowxContext(ctx, setEnrollmentLicenseQuery, licenseID, enrollmentID).StructScan(e); err != nil {
+		return nil, errors.Wrap(err, "drivingSchoolRepo.SetEnrollmentLicense.StructScan")
+	}
+	return e, nil
+}
+
+func (r *drivingSchoolRepo) GetEnrollments(ctx context.Context, pq *utils.PaginationQuery) (*models.SchoolEnrollmentList, error) {
+	return r.listEnrollments(ctx, pq, getTotalEnrollments, getEnrollments)
+}
+
+// Enrollments of the learner
+func (r *drivingSchoolRepo) GetEnrollmentsByIdentity(ctx context.Context, identityNo string, pq *utils.PaginationQuery) (*models.SchoolEnrollmentList, error) {
+	return r.listEnrollments(ctx, pq, getTotalEnrollmentsByIdentity, getEnrollmentsByIdentity, identityNo)
+}
+
+func (r *drivingSchoolRepo) listEnrollments(ctx context.Context, pq *utils.PaginationQuery, countQuery, listQuery string, base ...interface{}) (*models.SchoolEnrollmentList, error) {
+	lc, err := pq.ListClause(enrollmentListSpec, len(base))
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := lc.Total(ctx, r.db, countQuery, listQuery, base...)
+	if err != nil {
+		return nil, errors.Wrap(err, "drivingSchoolRepo.listEnrollments.total")
+	}
+
+	list := &models.SchoolEnrollmentList{
+		TotalCount:  total,
+		TotalPages:  utils.GetTotalPage(total, pq.GetSize()),
+		Page:        pq.GetPage(),
+		Size:        pq.GetSize(),
+		HasMore:     utils.GetHasMore(pq.GetPage(), total, pq.GetSize()),
+		Enrollments: []*models.SchoolEnrollment{},
+	}
+
+	if lc.Empty(total) {
+		return list, nil
+	}
+
+	var items []*models.SchoolEnrollment
+	if err := r.db.SelectContext(ctx, &items, lc.Page(listQuery), lc.PageArgs(pq, base...)...); err != nil {
+		return nil, errors.Wrap(err, "drivingSchoolRepo.listEnrollments.Select")
+	}
+
+	if list.Enrollments, list.NextCursor, err = utils.NextCursor(lc, items); err != nil {
+		return nil, errors.Wrap(err, "drivingSchoolRepo.listEnrollments.NextCursor")
+	}
+	list.HasMore = lc.HasMore(pq, total, list.NextCursor)
+	return list, nil
+}
+
+func (r *drivingSchoolRepo) CreateSession(ctx context.Context, s *models.ExamSession) (*models.ExamSession, error) {
+	created := &models.ExamSession{}
+	if err := r.db.QueryRowxContext(ctx, createSessionQuery,
+		s.Id, s.AgencyID, s.AgencyName, s.Kind, s.LicenseType, s.StartsAt, s.Location, s.Capacity, s.Status,
+		s.CreatorId, s.CreatedAt, s.UpdatedAt,
+	).StructScan(created); err != nil {
+		return nil, errors.Wrap(err, "drivingSchoolRepo.CreateSession.StructScan")
+	}
+	return created, nil
+}
+
+func (r *drivingSchoolRepo) GetSessionByID(ctx context.Context, sessionID uuid.UUID) (*models.ExamSession, error) {
+	s := &models.ExamSession{}
+	if err := r.db.GetContext(ctx, s, getSessionByID, sessionID); err != nil {
+		return nil, errors.Wrap(err, "drivingSchoolRepo.GetSessionByID.GetContext")
+	}
+	return s, nil
+}
+
+// Cancel the session and every seat booked in it.
+// sql.ErrNoRows when the session is already cancelled or started.
+func (r *drivingSchoolRepo) CancelSession(ctx context.Context, sessionID uuid.UUID) (*models.ExamSession, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "drivingSchoolRepo.CancelSession.BeginTxx")
+	}
+	defer tx.Rollback()
+
+	cancelled := &models.ExamSession{}
+	if err = tx.QueryRowxContext(ctx, cancelSessionQuery, sessionID).StructScan(cancelled); err != nil {
+		return nil, errors.Wrap(err, "drivingSchoolRepo.CancelSession.StructScan")
+	}
+	if _, err = tx.ExecContext(ctx, cancelSessionBookingsQuery, sessionID); err != nil {
+		return nil, errors.Wrap(err, "drivingSchoolRepo.CancelSession.cancelBookings")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "drivingSchoolRepo.CancelSession.Commit")
+	}
+	return cancelled, nil
+}
+
+func (r *drivingSchoolRepo) GetSessions(ctx context.Context, pq *utils.PaginationQuery) (*models.ExamSessionList, error) {
+	lc, err := pq.ListClause(sessionListSpec, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := lc.Total(ctx, r.db, getTotalSessions, getSessions)
+	if err != nil {
+		return nil, errors.Wrap(err, "drivingSchoolRepo.GetSessions.total")
+	}
+
+	list := &models.ExamSessionList{
+		TotalCount: total,
+		TotalPages: utils.GetTotalPage(total, pq.GetSize()),
+		Page:       pq.GetPage(),
+		Size:       pq.GetSize(),
+		HasMore:    utils.GetHasMore(pq.GetPage(), total, pq.GetSize()),
+		Sessions:   []*models.ExamSession{},
+	}
+
+	if lc.Empty(total) {
+		return list, nil
+	}
+
+	var items []*models.ExamSession
+	if err := r.db.SelectContext(ctx, &items, lc.Page(getSessions), lc.PageArgs(pq)...); err != nil {
+		return nil, errors.Wrap(err, "drivingSchoolRepo.GetSessions.Select")
+	}
+
+	if list.Sessions, list.NextCursor, err = utils.NextCursor(lc, items); err != nil {
+		return nil, errors.Wrap(err, "drivingSchoolRepo.GetSessions.NextCursor")
+	}
+	list.HasMore = lc.HasMore(pq, total, list.NextCursor)
+	return list, nil
+}
+
+// Take a seat in the session and book it in one transaction.
+// sql.ErrNoRows when the session is full, cancelled or already started.
+func (r *drivingSchoolRepo) BookSession(ctx context.Context, b *models.ExamBooking) (*models.ExamBooking, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "drivingSchoolRepo.BookSession.BeginTxx")
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, claimSeatQuery, b.SessionID)
+	if err != nil {
+		return nil, errors.Wrap(err, "drivingSchoolRepo.BookSession.claimSeat")
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return nil, errors.Wrap(err, "drivingSchoolRepo.BookSession.RowsAffected")
+	}
+	if n == 0 {
+		return nil, errors.Wrap(sql.ErrNoRows, "drivingSchoolRepo.BookSession.claimSeat")
+	}
+
+	booked := &models.ExamBooking{}
+	if err = tx.QueryRowxContext(ctx, createBookingQuery,
+		b.Id, b.SessionID, b.EnrollmentID, b.Kind, b.CreatedAt, b.UpdatedAt,
+	).StructScan(booked); err != nil {
+		return nil, errors.Wrap(err, "drivingSchoolRepo.BookSession.StructScan")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "drivingSchoolRepo.BookSession.Commit")
+	}
+	return booked, nil
+}
+
+func (r *drivingSchoolRepo) GetBookingByID(ctx context.Context, bookingID uuid.UUID) (*models.ExamBooking, error) {
+	b := &models.ExamBooking{}
+	if err := r.db.GetContext(ctx, b, getBookingByID, bookingID); err != nil {
+		return nil, errors.Wrap(err, "drivingSchoolRepo.GetBookingByID.GetContext")
+	}
+	return b, nil
+}
+
+// Seat the enrollment holds for the exam, nil when there is none
+func (r *drivingSchoolRepo) GetOpenBooking(ctx context.Context, enrollmentID uuid.UUID, kind string) (*models.ExamBooking, error) {
+	b := &models.ExamBooking{}
+	err := r.db.GetContext(ctx, b, getOpenBooking, enrollmentID, kind)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "drivingSchoolRepo.GetOpenBooking.GetContext")
+	}
+	return b, nil
+}
+
+// Latest pass of the enrollment in the exam
+func (r *drivingSchoolRepo) GetPassedBooking(ctx context.Context, enrollmentID uuid.UUID, kind string) (*models.ExamBooking, error) {
+	b := &models.ExamBooking{}
+	if err := r.db.GetContext(ctx, b, getPassedBooking, enrollmentID, kind); err != nil {
+		return nil, errors.Wrap(err, "drivingSchoolRepo.GetPassedBooking.GetContext")
+	}
+	return b, nil
+}
+
+// Cancel the booking and give its seat back.
+// sql.ErrNoRows when the booking is no longer open or its session started.
+func (r *drivingSchoolRepo) CancelBooking(ctx context.Context, bookingID uuid.UUID) (*models.ExamBooking, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "drivingSchoolRepo.CancelBooking.BeginTxx")
+	}
+	defer tx.Rollback()
+
+	cancelled := &models.ExamBooking{}
+	if err = tx.QueryRowxContext(ctx, cancelBookingQuery, bookingID).StructScan(cancelled); err != nil {
+		return nil, errors.Wrap(err, "drivingSchoolRepo.CancelBooking.StructScan")
+	}
+	if _, err = tx.ExecContext(ctx, releaseSeatQuery, cancelled.SessionID); err != nil {
+		return nil, errors.Wrap(err, "drivingSchoolRepo.CancelBooking.releaseSeat")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "drivingSchoolRepo.CancelBooking.Commit")
+	}
+	return cancelled, nil
+}
+
+// Record the result of a booked seat and, on a pass, the exam passed by the enrollment.
+// sql.ErrNoRows when the result was already recorded.
+func (r *drivingSchoolRepo) RecordResult(ctx context.Context, b *models.ExamBooking) (*models.ExamBooking, *models.SchoolEnrollment, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "drivingSchoolRepo.RecordResult.BeginTxx")
+	}
+	defer tx.Rollback()
+
+	recorded := &models.ExamBooking{}
+	if err = tx.QueryRowxContext(ctx, recordResultQuery,
+		b.Status, b.Score, b.Note, b.ExaminerID, b.Id,
+	).StructScan(recorded); err != nil {
+		return nil, nil, errors.Wrap(err, "drivingSchoolRepo.RecordResult.StructScan")
+	}
+
+	e := &models.SchoolEnrollment{}
+	if recorded.Status == models.BookingPassed {
+		err = tx.QueryRowxContext(ctx, passExamQuery, recorded.Kind, recorded.EnrollmentID).StructScan(e)
+	} else {
+		err = tx.GetContext(ctx, e, getEnrollmentByID, recorded.EnrollmentID)
+	}
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "drivingSchoolRepo.RecordResult.enrollment")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, nil, errors.Wrap(err, "drivingSchoolRepo.RecordResult.Commit")
+	}
+	return recorded, e, nil
+}
+
+func (r *drivingSchoolRepo) GetSessionBookings(ctx context.Context, sessionID uuid.UUID) ([]*models.ExamBooking, error) {
+	bookings := []*models.ExamBooking{}
+	if err := r.db.SelectContext(ctx, &bookings, getSessionBookings, sessionID); err != nil {
+		return nil, errors.Wrap(err, "drivingSchoolRepo.GetSessionBookings.SelectContext")
+	}
+	return bookings, nil
+}
+
+func (r *drivingSchoolRepo) GetAgency(ctx context.Context, agencyID uuid.UUID) (*models.GovAgency, error) {
+	a := &models.GovAgency{}
+	if err := r.db.GetContext(ctx, a, getAgencyQuery, agencyID); err != nil {
+		return nil, errors.Wrap(err, "drivingSchoolRepo.GetAgency.GetContext")
+	}
+	return a, nil
+}
+
+// Account of the learner, nil when the learner has not signed up yet
+func (r *drivingSchoolRepo) GetUserIDByIdentity(ctx context.Context, identityNo string) (*uuid.UUID, error) {
+	var id uuid.UUID
+	err := r.db.GetContext(ctx, &id, getUserIDByIdentity, identityNo)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "drivingSchoolRepo.GetUserIDByIdentity.GetContext")
+	}
+	return &id, nil
+}
+
+// Whether an active license holds the number
+func (r *drivingSchoolRepo) LicenseNoTaken(ctx context.Context, licenseNo string) (bool, error) {
+	var taken bool
+	if err := r.db.GetContext(ctx, &taken, licenseNoTaken, licenseNo); err != nil {
+		return false, errors.Wrap(err, "drivingSchoolRepo.LicenseNoTaken.GetContext")
+	}
+	return taken, nil
+}
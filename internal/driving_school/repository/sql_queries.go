@@ -0,0 +1,245 @@
+package repository
+
+import "github.com/adohong4/driving-license/pkg/utils"
+
+// Filters and sorts accepted by the enrollment lists, filter, sort and cursor clauses are appended to the queries below
+var enrollmentListSpec = &utils.ListSpec{
+	Fields: map[string]utils.ListField{
+		"school_id":    {Column: "se.school_id", Type: utils.FilterUUID},
+		"identity_no":  {Column: "se.identity_no", Type: utils.FilterExact},
+		"full_name":    {Column: "se.full_name", Type: utils.FilterText, Sortable: true, Keyset: true},
+		"license_type": {Column: "se.license_type", Type: utils.FilterExact, Sortable: true, Keyset: true},
+		"status":       {Column: "se.status", Type: utils.FilterExact, Sortable: true, Keyset: true},
+		"created_at":   {Column: "se.created_at", Type: utils.FilterDate, Sortable: true, Keyset: true},
+		"updated_at":   {Column: "se.updated_at", Type: utils.FilterDate, Sortable: true, Keyset: true},
+	},
+	DefaultSort: "-created_at",
+	IDColumn:    "se.id",
+}
+
+// Filters and sorts accepted by the session list
+var sessionListSpec = &utils.ListSpec{
+	Fields: map[string]utils.ListField{
+		"agency_id":    {Column: "es.agency_id", Type: utils.FilterUUID},
+		"kind":         {Column: "es.kind", Type: utils.FilterExact, Sortable: true, Keyset: true},
+		"license_type": {Column: "es.license_type", Type: utils.FilterExact, Sortable: true, Keyset: true},
+		"status":       {Column: "es.status", Type: utils.FilterExact, Sortable: true, Keyset: true},
+		"starts_at":    {Column: "es.starts_at", Type: utils.FilterDate, Sortable: true, Keyset: true},
+		"created_at":   {Column: "es.created_at", Type: utils.FilterDate, Sortable: true, Keyset: true},
+	},
+	DefaultSort: "starts_at",
+	IDColumn:    "es.id",
+}
+
+const (
+	createEnrollmentQuery = `
+    INSERT INTO school_enrollments (
+        id, school_id, school_name, learner_id, identity_no, full_name, dob, license_type, status,
+        creator_id, created_at, updated_at
+    ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+    RETURNING *
+    `
+
+	getEnrollmentByID = `
+    SELECT * FROM school_enrollments WHERE id = $1
+    `
+
+	getOpenEnrollment = `
+    SELECT * FROM school_enrollments
+    WHERE identity_no = $1 AND license_type = $2 AND status = 'enrolled'
+    `
+
+	withdrawEnrollmentQuery = `
+    UPDATE school_enrollments
+    SET status = 'withdrawn', updated_at = now()
+    WHERE id = $1 AND status = 'enrolled'
+    RETURNING *
+    `
+
+	cancelEnrollmentBookingsQuery = `
+    UPDATE exam_bookings
+    SET status = 'cancelled', updated_at = now()
+    WHERE enrollment_id = $1 AND status = 'booked'
+    RETURNING *
+    `
+
+	// the license is linked once, when both exams are passed
+	// one caller issues the license, the lease ends when a caller died before linking it
+	claimEnrollmentIssueQuery = `
+    UPDATE school_enrollments
+    SET issuing_at = now(), updated_at = now()
+    WHERE id = $1
+      AND status = 'enrolled'
+      AND license_id IS NULL
+      AND theory_passed_at IS NOT NULL
+      AND practical_passed_at IS NOT NULL
+      AND (issuing_at IS NULL OR issuing_at < now() - make_interval(secs => $2))
+    RETURNING *
+    `
+
+	releaseEnrollmentIssueQuery = `
+    UPDATE school_enrollments
+    SET issuing_at = NULL, updated_at = now()
+    WHERE id = $1 AND license_id IS NULL
+    `
+
+	setEnrollmentLicenseQuery = `
+    UPDATE school_enrollments
+    SET status = 'licensed', license_id = $1, issuing_at = NULL, updated_at = now()
+    WHERE id = $2
+      AND status = 'enrolled'
+      AND license_id IS NULL
+      AND issuing_at IS NOT NULL
+      AND theory_passed_at IS NOT NULL
+      AND practical_passed_at IS NOT NULL
+    RETURNING *
+    `
+
+	// a pass is kept, retaking a passed exam does not move its date
+	passExamQuery = `
+    UPDATE school_enrollments
+    SET theory_passed_at    = CASE WHEN $1 = 'theory' THEN COALESCE(theory_passed_at, now()) ELSE theory_passed_at END,
+        practical_passed_at = CASE WHEN $1 = 'practical' THEN COALESCE(practical_passed_at, now()) ELSE practical_passed_at END,
+        updated_at = now()
+    WHERE id = $2
+    RETURNING *
+    `
+
+	getEnrollments = `
+    SELECT se.*
+    FROM school_enrollments se
+    WHERE 1 = 1
+    `
+
+	getTotalEnrollments = `
+    SELECT COUNT(*)
+    FROM school_enrollments se
+    WHERE 1 = 1
+    `
+
+	getEnrollmentsByIdentity = `
+    SELECT se.*
+    FROM school_enrollments se
+    WHERE se.identity_no = $1
+    `
+
+	getTotalEnrollmentsByIdentity = `
+    SELECT COUNT(*)
+    FROM school_enrollments se
+    WHERE se.identity_no = $1
+    `
+
+	createSessionQuery = `
+    INSERT INTO exam_sessions (
+        id, agency_id, agency_name, kind, license_type, starts_at, location, capacity, booked, status,
+        creator_id, created_at, updated_at
+    ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, 0, $9, $10, $11, $12)
+    RETURNING *
+    `
+
+	getSessionByID = `
+    SELECT * FROM exam_sessions WHERE id = $1
+    `
+
+	// sessions are cancelled before they start
+	cancelSessionQuery = `
+    UPDATE exam_sessions
+    SET status = 'cancelled', booked = 0, updated_at = now()
+    WHERE id = $1 AND status = 'scheduled' AND starts_at > now()
+    RETURNING *
+    `
+
+	cancelSessionBookingsQuery = `
+    UPDATE exam_bookings
+    SET status = 'cancelled', updated_at = now()
+    WHERE session_id = $1 AND status = 'booked'
+    `
+
+	getSessions = `
+    SELECT es.*
+    FROM exam_sessions es
+    WHERE 1 = 1
+    `
+
+	getTotalSessions = `
+    SELECT COUNT(*)
+    FROM exam_sessions es
+    WHERE 1 = 1
+    `
+
+	// a seat is taken only while the session is open and not full
+	claimSeatQuery = `
+    UPDATE exam_sessions
+    SET booked = booked + 1, updated_at = now()
+    WHERE id = $1 AND status = 'scheduled' AND starts_at > now() AND booked < capacity
+    `
+
+	releaseSeatQuery = `
+    UPDATE exam_sessions
+    SET booked = booked - 1, updated_at = now()
+    WHERE id = $1 AND booked > 0
+    `
+
+	createBookingQuery = `
+    INSERT INTO exam_bookings (id, session_id, enrollment_id, kind, status, created_at, updated_at)
+    VALUES ($1, $2, $3, $4, 'booked', $5, $6)
+    RETURNING *
+    `
+
+	getBookingByID = `
+    SELECT * FROM exam_bookings WHERE id = $1
+    `
+
+	getOpenBooking = `
+    SELECT * FROM exam_bookings
+    WHERE enrollment_id = $1 AND kind = $2 AND status = 'booked'
+    `
+
+	getPassedBooking = `
+    SELECT * FROM exam_bookings
+    WHERE enrollment_id = $1 AND kind = $2 AND status = 'passed'
+    ORDER BY recorded_at DESC
+    LIMIT 1
+    `
+
+	// seats of sessions that already started are not given back
+	cancelBookingQuery = `
+    UPDATE exam_bookings eb
+    SET status = 'cancelled', updated_at = now()
+    FROM exam_sessions es
+    WHERE eb.id = $1 AND eb.status = 'booked' AND es.id = eb.session_id AND es.starts_at > now()
+    RETURNING eb.*
+    `
+
+	recordResultQuery = `
+    UPDATE exam_bookings
+    SET status = $1,
+        score = $2,
+        note = $3,
+        examiner_id = $4,
+        recorded_at = now(),
+        updated_at = now()
+    WHERE id = $5 AND status = 'booked'
+    RETURNING *
+    `
+
+	getSessionBookings = `
+    SELECT * FROM exam_bookings
+    WHERE session_id = $1
+    ORDER BY created_at
+    `
+
+	getAgencyQuery = `
+    SELECT * FROM gov_agencies WHERE id = $1 AND active = true
+    `
+
+	getUserIDByIdentity = `
+    SELECT id FROM users WHERE identity_no = $1 AND active = true LIMIT 1
+    `
+
+	licenseNoTaken = `
+    SELECT EXISTS (
+        SELECT 1 FROM driver_licenses WHERE license_no = $1 AND active = true
+    )
+    `
+)
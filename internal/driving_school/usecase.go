@@ -0,0 +1,28 @@
+package drivingSchool
+
+import (
+	"context"
+
+	"github.com/adohong4/driving-license/internal/models"
+	"github.com/adohong4/driving-license/pkg/utils"
+	"github.com/google/uuid"
+)
+
+type UseCase interface {
+	Enroll(ctx context.Context, req *models.EnrollRequest) (*models.SchoolEnrollment, error)
+	Withdraw(ctx context.Context, enrollmentID uuid.UUID) (*models.SchoolEnrollment, error)
+	IssueLicense(ctx context.Context, enrollmentID uuid.UUID) (*models.ExamResult, error)
+	GetEnrollmentByID(ctx context.Context, enrollmentID uuid.UUID) (*models.SchoolEnrollment, error)
+	GetEnrollments(ctx context.Context, pq *utils.PaginationQuery) (*models.SchoolEnrollmentList, error)
+	GetMyEnrollments(ctx context.Context, pq *utils.PaginationQuery) (*models.SchoolEnrollmentList, error)
+
+	CreateSession(ctx context.Context, req *models.CreateSessionRequest) (*models.ExamSession, error)
+	CancelSession(ctx context.Context, sessionID uuid.UUID) (*models.ExamSession, error)
+	GetSessionByID(ctx context.Context, sessionID uuid.UUID) (*models.ExamSession, error)
+	GetSessions(ctx context.Context, pq *utils.PaginationQuery) (*models.ExamSessionList, error)
+	GetSessionBookings(ctx context.Context, sessionID uuid.UUID) (*models.ExamBookingList, error)
+
+	BookSession(ctx context.Context, sessionID uuid.UUID, req *models.BookSessionRequest) (*models.ExamBooking, error)
+	CancelBooking(ctx context.Context, bookingID uuid.UUID) (*models.ExamBooking, error)
+	RecordResult(ctx context.Context, bookingID uuid.UUID, req *models.ExamResultRequest) (*models.ExamResult, error)
+}
@@ -0,0 +1,592 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/adohong4/driving-license/config"
+	driverlicense "github.com/adohong4/driving-license/internal/driver_license"
+	drivingSchool "github.com/adohong4/driving-license/internal/driving_school"
+	"github.com/adohong4/driving-license/internal/models"
+	"github.com/adohong4/driving-license/internal/notification"
+	"github.com/adohong4/driving-license/pkg/httpErrors"
+	"github.com/adohong4/driving-license/pkg/logger"
+	"github.com/adohong4/driving-license/pkg/utils"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// Notification codes of exam results, rendered from the i18n catalogs as exam_<kind>_<passed|failed>
+const (
+	notiLicenseIssued = "school_license_issued"
+
+	notiTypeExam = "driving_school"
+)
+
+// Tries to draw a license number nobody holds
+const licenseNoAttempts = 5
+
+// Lease of an enrollment while its license is issued, another caller may issue it after that
+const issueLease = 5 * time.Minute
+
+// Roles allowed to run schools and exam sessions
+var officerRoles = map[string]bool{"admin": true, "officer": true}
+
+type drivingSchoolUC struct {
+	cfg        *config.Config
+	schoolRepo drivingSchool.Repository
+	dlUC       driverlicense.UseCase
+	notiUC     notification.UseCase
+	logger     logger.Logger
+}
+
+func NewDrivingSchoolUseCase(cfg *config.Config, schoolRepo drivingSchool.Repository, dlUC driverlicense.UseCase, notiUC notification.UseCase, logger logger.Logger) drivingSchool.UseCase {
+	return &drivingSchoolUC{cfg: cfg, schoolRepo: schoolRepo, dlUC: dlUC, notiUC: notiUC, logger: logger}
+}
+
+// An officer enrolls a learner at a training agency for a class
+func (u *drivingSchoolUC) Enroll(ctx context.Context, req *models.EnrollRequest) (*models.SchoolEnrollment, error) {
+	user, err := u.officerFromCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = utils.ValidateStruct(ctx, req); err != nil {
+		return nil, httpErrors.NewBadRequestError(errors.WithMessage(err, "drivingSchoolUC.Enroll.ValidateStruct"))
+	}
+
+	school, err := u.getAgency(ctx, req.SchoolID)
+	if err != nil {
+		return nil, err
+	}
+	if !isTrainingAgency(school) {
+		return nil, httpErrors.NewBadRequestError(fmt.Sprintf("%s is not a driving school", school.Name))
+	}
+
+	identityNo := strings.TrimSpace(req.IdentityNo)
+	open, err := u.schoolRepo.GetOpenEnrollment(ctx, identityNo, req.LicenseType)
+	if err != nil {
+		return nil, err
+	}
+	if open != nil {
+		return nil, httpErrors.NewRestError(http.StatusConflict,
+			fmt.Sprintf("the learner is already enrolled for class %s at %s", open.LicenseType, open.SchoolName), nil)
+	}
+
+	learnerID, err := u.schoolRepo.GetUserIDByIdentity(ctx, identityNo)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return u.schoolRepo.CreateEnrollment(ctx, &models.SchoolEnrollment{
+		Id:          uuid.New(),
+		SchoolID:    school.Id,
+		SchoolName:  school.Name,
+		LearnerID:   learnerID,
+		IdentityNo:  identityNo,
+		FullName:    strings.TrimSpace(req.FullName),
+		DOB:         req.DOB,
+		LicenseType: req.LicenseType,
+		Status:      models.EnrollmentEnrolled,
+		CreatorId:   user.Id,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	})
+}
+
+// An officer withdraws an open enrollment, the seats it still holds are given back
+func (u *drivingSchoolUC) Withdraw(ctx context.Context, enrollmentID uuid.UUID) (*models.SchoolEnrollment, error) {
+	if _, err := u.officerFromCtx(ctx); err != nil {
+		return nil, err
+	}
+
+	e, err := u.schoolRepo.GetEnrollmentByID(ctx, enrollmentID)
+	if err != nil {
+		return nil, err
+	}
+	if e.Status != models.EnrollmentEnrolled {
+		return nil, enrollmentStateError(e)
+	}
+
+	withdrawn, err := u.schoolRepo.WithdrawEnrollment(ctx, enrollmentID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, httpErrors.NewRestError(http.StatusConflict, "the enrollment is no longer open", nil)
+		}
+		return nil, err
+	}
+	return withdrawn, nil
+}
+
+// An officer issues the license of a learner who passed both exams when it could not be issued with the result
+func (u *drivingSchoolUC) IssueLicense(ctx context.Context, enrollmentID uuid.UUID) (*models.ExamResult, error) {
+	if _, err := u.officerFromCtx(ctx); err != nil {
+		return nil, err
+	}
+
+	e, err := u.schoolRepo.GetEnrollmentByID(ctx, enrollmentID)
+	if err != nil {
+		return nil, err
+	}
+	if !e.AwaitsLicense() {
+		return nil, httpErrors.NewRestError(http.StatusConflict, "the enrollment does not await a license", nil)
+	}
+
+	booking, err := u.lastPracticalPass(ctx, e)
+	if err != nil {
+		return nil, err
+	}
+	session, err := u.schoolRepo.GetSessionByID(ctx, booking.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	e, license, err := u.issueLicense(ctx, e, session)
+	if err != nil {
+		return nil, err
+	}
+	return &models.ExamResult{Booking: booking, Enrollment: e, License: license}, nil
+}
+
+// Enrollment of the learner, officers read any enrollment
+func (u *drivingSchoolUC) GetEnrollmentByID(ctx context.Context, enrollmentID uuid.UUID) (*models.SchoolEnrollment, error) {
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return nil, httpErrors.NewUnauthorizedError(errors.WithMessage(err, "drivingSchoolUC.GetEnrollmentByID.GetUserFromCtx"))
+	}
+
+	e, err := u.schoolRepo.GetEnrollmentByID(ctx, enrollmentID)
+	if err != nil {
+		return nil, err
+	}
+	if !isOfficer(user) && !ownsEnrollment(user, e) {
+		return nil, httpErrors.NewRestError(http.StatusNotFound, "enrollment not found", nil)
+	}
+	return e, nil
+}
+
+func (u *drivingSchoolUC) GetEnrollments(ctx context.Context, pq *utils.PaginationQuery) (*models.SchoolEnrollmentList, error) {
+	if _, err := u.officerFromCtx(ctx); err != nil {
+		return nil, err
+	}
+	return u.schoolRepo.GetEnrollments(ctx, pq)
+}
+
+// Enrollments of the current user
+func (u *drivingSchoolUC) GetMyEnrollments(ctx context.Context, pq *utils.PaginationQuery) (*models.SchoolEnrollmentList, error) {
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return nil, httpErrors.NewUnauthorizedError(err)
+	}
+	if user.IdentityNo == "" {
+		return nil, httpErrors.NewBadRequestError("user identity number is missing")
+	}
+	return u.schoolRepo.GetEnrollmentsByIdentity(ctx, user.IdentityNo, pq)
+}
+
+// An officer schedules an exam session at an agency
+func (u *drivingSchoolUC) CreateSession(ctx context.Context, req *models.CreateSessionRequest) (*models.ExamSession, error) {
+	user, err := u.officerFromCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = utils.ValidateStruct(ctx, req); err != nil {
+		return nil, httpErrors.NewBadRequestError(errors.WithMessage(err, "drivingSchoolUC.CreateSession.ValidateStruct"))
+	}
+
+	now := time.Now()
+	if !req.StartsAt.After(now) {
+		return nil, httpErrors.NewBadRequestError("the session must start in the future")
+	}
+
+	agency, err := u.getAgency(ctx, req.AgencyID)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.schoolRepo.CreateSession(ctx, &models.ExamSession{
+		Id:          uuid.New(),
+		AgencyID:    agency.Id,
+		AgencyName:  agency.Name,
+		Kind:        req.Kind,
+		LicenseType: req.LicenseType,
+		StartsAt:    req.StartsAt,
+		Location:    strings.TrimSpace(req.Location),
+		Capacity:    req.Capacity,
+		Status:      models.SessionScheduled,
+		CreatorId:   user.Id,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	})
+}
+
+// An officer cancels a session before it starts, every seat booked in it is cancelled
+func (u *drivingSchoolUC) CancelSession(ctx context.Context, sessionID uuid.UUID) (*models.ExamSession, error) {
+	if _, err := u.officerFromCtx(ctx); err != nil {
+		return nil, err
+	}
+
+	if _, err := u.schoolRepo.GetSessionByID(ctx, sessionID); err != nil {
+		return nil, err
+	}
+
+	cancelled, err := u.schoolRepo.CancelSession(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, httpErrors.NewRestError(http.StatusConflict, "the session is already cancelled or started", nil)
+		}
+		return nil, err
+	}
+	return cancelled, nil
+}
+
+func (u *drivingSchoolUC) GetSessionByID(ctx context.Context, sessionID uuid.UUID) (*models.ExamSession, error) {
+	return u.schoolRepo.GetSessionByID(ctx, sessionID)
+}
+
+func (u *drivingSchoolUC) GetSessions(ctx context.Context, pq *utils.PaginationQuery) (*models.ExamSessionList, error) {
+	return u.schoolRepo.GetSessions(ctx, pq)
+}
+
+// Candidates of a session, for the examiners
+func (u *drivingSchoolUC) GetSessionBookings(ctx context.Context, sessionID uuid.UUID) (*models.ExamBookingList, error) {
+	if _, err := u.officerFromCtx(ctx); err != nil {
+		return nil, err
+	}
+	if _, err := u.schoolRepo.GetSessionByID(ctx, sessionID); err != nil {
+		return nil, err
+	}
+
+	bookings, err := u.schoolRepo.GetSessionBookings(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.ExamBookingList{Bookings: bookings}, nil
+}
+
+// The learner, or an officer for them, books a seat in a session of the class of the enrollment.
+// The practical exam is booked once the theory one is passed, an exam is booked once at a time.
+func (u *drivingSchoolUC) BookSession(ctx context.Context, sessionID uuid.UUID, req *models.BookSessionRequest) (*models.ExamBooking, error) {
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return nil, httpErrors.NewUnauthorizedError(errors.WithMessage(err, "drivingSchoolUC.BookSession.GetUserFromCtx"))
+	}
+	if err = utils.ValidateStruct(ctx, req); err != nil {
+		return nil, httpErrors.NewBadRequestError(errors.WithMessage(err, "drivingSchoolUC.BookSession.ValidateStruct"))
+	}
+
+	e, err := u.schoolRepo.GetEnrollmentByID(ctx, req.EnrollmentID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, httpErrors.NewRestError(http.StatusNotFound, "enrollment not found", nil)
+		}
+		return nil, err
+	}
+	if !isOfficer(user) && !ownsEnrollment(user, e) {
+		return nil, httpErrors.NewRestError(http.StatusNotFound, "enrollment not found", nil)
+	}
+	if e.Status != models.EnrollmentEnrolled {
+		return nil, enrollmentStateError(e)
+	}
+
+	session, err := u.schoolRepo.GetSessionByID(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.LicenseType != e.LicenseType {
+		return nil, httpErrors.NewBadRequestError(fmt.Sprintf("the session examines class %s, the learner is enrolled for %s",
+			session.LicenseType, e.LicenseType))
+	}
+	if e.Passed(session.Kind) {
+		return nil, httpErrors.NewRestError(http.StatusConflict, fmt.Sprintf("the %s exam is already passed", session.Kind), nil)
+	}
+	if session.Kind == models.ExamPractical && !e.Passed(models.ExamTheory) {
+		return nil, httpErrors.NewBadRequestError("the theory exam must be passed before booking the practical exam")
+	}
+
+	open, err := u.schoolRepo.GetOpenBooking(ctx, e.Id, session.Kind)
+	if err != nil {
+		return nil, err
+	}
+	if open != nil {
+		return nil, httpErrors.NewRestError(http.StatusConflict,
+			fmt.Sprintf("the learner already holds a seat for the %s exam in session %s", session.Kind, open.SessionID), nil)
+	}
+
+	now := time.Now()
+	booked, err := u.schoolRepo.BookSession(ctx, &models.ExamBooking{
+		Id:           uuid.New(),
+		SessionID:    session.Id,
+		EnrollmentID: e.Id,
+		Kind:         session.Kind,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, httpErrors.NewRestError(http.StatusConflict, "the session is full, cancelled or already started", nil)
+		}
+		return nil, err
+	}
+	return booked, nil
+}
+
+// The learner, or an officer for them, cancels a seat before the session starts
+func (u *drivingSchoolUC) CancelBooking(ctx context.Context, bookingID uuid.UUID) (*models.ExamBooking, error) {
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return nil, httpErrors.NewUnauthorizedError(errors.WithMessage(err, "drivingSchoolUC.CancelBooking.GetUserFromCtx"))
+	}
+
+	b, err := u.schoolRepo.GetBookingByID(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if !isOfficer(user) {
+		e, err := u.schoolRepo.GetEnrollmentByID(ctx, b.EnrollmentID)
+		if err != nil {
+			return nil, err
+		}
+		if !ownsEnrollment(user, e) {
+			return nil, httpErrors.NewRestError(http.StatusNotFound, "booking not found", nil)
+		}
+	}
+	if b.Status != models.BookingBooked {
+		return nil, bookingStateError(b)
+	}
+
+	cancelled, err := u.schoolRepo.CancelBooking(ctx, bookingID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, httpErrors.NewRestError(http.StatusConflict, "the booking is no longer open or its session already started", nil)
+		}
+		return nil, err
+	}
+	return cancelled, nil
+}
+
+// An examiner records the result of a seat once the session started. The learner is notified and, when the
+// result completes both exams, a pending license is issued by the agency of the session.
+func (u *drivingSchoolUC) RecordResult(ctx context.Context, bookingID uuid.UUID, req *models.ExamResultRequest) (*models.ExamResult, error) {
+	user, err := u.officerFromCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = utils.ValidateStruct(ctx, req); err != nil {
+		return nil, httpErrors.NewBadRequestError(errors.WithMessage(err, "drivingSchoolUC.RecordResult.ValidateStruct"))
+	}
+	if req.Result == models.BookingAbsent && req.Score != nil {
+		return nil, httpErrors.NewBadRequestError("an absent candidate has no score")
+	}
+
+	b, err := u.schoolRepo.GetBookingByID(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if b.Status != models.BookingBooked {
+		return nil, bookingStateError(b)
+	}
+	session, err := u.schoolRepo.GetSessionByID(ctx, b.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.StartsAt.After(time.Now()) {
+		return nil, httpErrors.NewBadRequestError("results are recorded once the session started")
+	}
+
+	b.Status = req.Result
+	b.Score = req.Score
+	b.Note = strings.TrimSpace(req.Note)
+	b.ExaminerID = &user.Id
+
+	recorded, e, err := u.schoolRepo.RecordResult(ctx, b)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, httpErrors.NewRestError(http.StatusConflict, "the result of the booking is already recorded", nil)
+		}
+		return nil, err
+	}
+	result := &models.ExamResult{Booking: recorded, Enrollment: e}
+	u.notifyResult(ctx, e, recorded)
+
+	if e.AwaitsLicense() {
+		// the result is saved, a failed issue is retried through IssueLicense
+		if result.Enrollment, result.License, err = u.issueLicense(ctx, e, session); err != nil {
+			u.logger.Errorf("drivingSchoolUC.RecordResult.issueLicense %s: %v", e.Id, err)
+			result.Enrollment = e
+		}
+	}
+	return result, nil
+}
+
+// Issue the pending license of the learner through the driver license use case and link it to the enrollment.
+// The enrollment is claimed first so concurrent callers do not issue a second license
+func (u *drivingSchoolUC) issueLicense(ctx context.Context, e *models.SchoolEnrollment, session *models.ExamSession) (*models.SchoolEnrollment, *models.DrivingLicense, error) {
+	e, err := u.schoolRepo.ClaimEnrollmentIssue(ctx, e.Id, issueLease)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, httpErrors.NewRestError(http.StatusConflict, "the license of the enrollment is already issued or being issued", nil)
+		}
+		return nil, nil, err
+	}
+
+	license, err := u.createLicense(ctx, e, session)
+	if err != nil {
+		if releaseErr := u.schoolRepo.ReleaseEnrollmentIssue(ctx, e.Id); releaseErr != nil {
+			u.logger.Errorf("drivingSchoolUC.issueLicense.ReleaseEnrollmentIssue %s: %v", e.Id, releaseErr)
+		}
+		return nil, nil, err
+	}
+
+	licensed, err := u.schoolRepo.SetEnrollmentLicense(ctx, e.Id, license.Id)
+	if err != nil {
+		// withdrawn meanwhile or the link failed, the license is not left behind without its enrollment
+		if _, delErr := u.dlUC.DeleteDriverLicense(ctx, license); delErr != nil {
+			u.logger.Errorf("drivingSchoolUC.issueLicense.DeleteDriverLicense %s: %v", license.Id, delErr)
+		}
+		if releaseErr := u.schoolRepo.ReleaseEnrollmentIssue(ctx, e.Id); releaseErr != nil {
+			u.logger.Errorf("drivingSchoolUC.issueLicense.ReleaseEnrollmentIssue %s: %v", e.Id, releaseErr)
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, httpErrors.NewRestError(http.StatusConflict, "the enrollment no longer awaits a license", nil)
+		}
+		return nil, nil, err
+	}
+
+	u.notify(ctx, notiLicenseIssued, licensed, models.NotificationParams{
+		"license_id": license.Id.String(),
+		"license_no": license.LicenseNo,
+	})
+	return licensed, license, nil
+}
+
+// Pending license of the claimed enrollment, issued by the agency of the session
+func (u *drivingSchoolUC) createLicense(ctx context.Context, e *models.SchoolEnrollment, session *models.ExamSession) (*models.DrivingLicense, error) {
+	agency, err := u.getAgency(ctx, session.AgencyID)
+	if err != nil {
+		return nil, err
+	}
+	licenseNo, err := u.newLicenseNo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return u.dlUC.CreateDriverLicense(ctx, e.License(licenseNo, agency))
+}
+
+// Passed practical exam that completed the enrollment
+func (u *drivingSchoolUC) lastPracticalPass(ctx context.Context, e *models.SchoolEnrollment) (*models.ExamBooking, error) {
+	b, err := u.schoolRepo.GetPassedBooking(ctx, e.Id, models.ExamPractical)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, httpErrors.NewRestError(http.StatusConflict, "the practical exam result of the enrollment is missing", nil)
+		}
+		return nil, err
+	}
+	return b, nil
+}
+
+// Random 12 digit license number no active license holds
+func (u *drivingSchoolUC) newLicenseNo(ctx context.Context) (string, error) {
+	limit := big.NewInt(1_000_000_000_000)
+	for i := 0; i < licenseNoAttempts; i++ {
+		n, err := rand.Int(rand.Reader, limit)
+		if err != nil {
+			return "", errors.Wrap(err, "drivingSchoolUC.newLicenseNo.rand")
+		}
+		licenseNo := fmt.Sprintf("%012d", n)
+		taken, err := u.schoolRepo.LicenseNoTaken(ctx, licenseNo)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return licenseNo, nil
+		}
+	}
+	return "", errors.New("drivingSchoolUC.newLicenseNo: no free license number")
+}
+
+func (u *drivingSchoolUC) getAgency(ctx context.Context, agencyID uuid.UUID) (*models.GovAgency, error) {
+	agency, err := u.schoolRepo.GetAgency(ctx, agencyID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, httpErrors.NewRestError(http.StatusNotFound, "agency not found", nil)
+		}
+		return nil, err
+	}
+	return agency, nil
+}
+
+func (u *drivingSchoolUC) officerFromCtx(ctx context.Context) (*models.User, error) {
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return nil, httpErrors.NewUnauthorizedError(err)
+	}
+	if !isOfficer(user) {
+		return nil, httpErrors.NewForbiddenError(httpErrors.PermissionDenied)
+	}
+	return user, nil
+}
+
+// Tell the learner the result, absent candidates get the failed notification with the examiner's note
+func (u *drivingSchoolUC) notifyResult(ctx context.Context, e *models.SchoolEnrollment, b *models.ExamBooking) {
+	outcome := "failed"
+	if b.Status == models.BookingPassed {
+		outcome = "passed"
+	}
+	params := models.NotificationParams{
+		"booking_id": b.Id.String(),
+		"session_id": b.SessionID.String(),
+		"note":       b.Note,
+		"score":      "-",
+	}
+	if b.Score != nil {
+		params["score"] = fmt.Sprint(*b.Score)
+	}
+	u.notify(ctx, fmt.Sprintf("exam_%s_%s", b.Kind, outcome), e, params)
+}
+
+// The result is already saved so a failure is only logged
+func (u *drivingSchoolUC) notify(ctx context.Context, code string, e *models.SchoolEnrollment, params models.NotificationParams) {
+	params["enrollment_id"] = e.Id.String()
+	params["license_type"] = e.LicenseType
+	params["school_name"] = e.SchoolName
+	n := &models.Notification{
+		Code:       code,
+		Type:       notiTypeExam,
+		Target:     "personal",
+		TargetUser: e.IdentityNo,
+		Status:     "unread",
+		Params:     params,
+	}
+	if _, err := u.notiUC.CreateNotification(ctx, n); err != nil {
+		u.logger.Errorf("drivingSchoolUC.notify %s %s: %v", code, e.Id, err)
+	}
+}
+
+// Agencies whose type mentions training run driving schools
+func isTrainingAgency(a *models.GovAgency) bool {
+	t := strings.ToLower(a.Type)
+	return strings.Contains(t, "đào tạo") || strings.Contains(t, "training")
+}
+
+func isOfficer(user *models.User) bool {
+	return user.Role != nil && officerRoles[*user.Role]
+}
+
+func ownsEnrollment(user *models.User, e *models.SchoolEnrollment) bool {
+	return user.IdentityNo != "" && user.IdentityNo == e.IdentityNo
+}
+
+func enrollmentStateError(e *models.SchoolEnrollment) error {
+	return httpErrors.NewRestError(http.StatusConflict, fmt.Sprintf("the enrollment is %s", e.Status), nil)
+}
+
+func bookingStateError(b *models.ExamBooking) error {
+	return httpErrors.NewRestError(http.StatusConflict, fmt.Sprintf("the booking is %s", b.Status), nil)
+}
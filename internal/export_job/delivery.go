@@ -0,0 +1,8 @@
+package exportjob
+
+import "github.com/labstack/echo/v4"
+
+type Handlers interface {
+	GetJob() echo.HandlerFunc
+	Download() echo.HandlerFunc
+}
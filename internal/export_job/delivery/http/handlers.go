@@ -0,0 +1,125 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/adohong4/driving-license/config"
+	exportjob "github.com/adohong4/driving-license/internal/export_job"
+	"github.com/adohong4/driving-license/internal/models"
+	"github.com/adohong4/driving-license/pkg/export"
+	"github.com/adohong4/driving-license/pkg/httpErrors"
+	"github.com/adohong4/driving-license/pkg/logger"
+	"github.com/adohong4/driving-license/pkg/utils"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type exportJobHandlers struct {
+	cfg         *config.Config
+	exportJobUC exportjob.UseCase
+	logger      logger.Logger
+}
+
+func NewExportJobHandlers(cfg *config.Config, exportJobUC exportjob.UseCase, logger logger.Logger) exportjob.Handlers {
+	return &exportJobHandlers{cfg: cfg, exportJobUC: exportJobUC, logger: logger}
+}
+
+// Respond streams the export in the response, or queues a job and answers 202 when the export is large.
+// Shared by the export endpoints of every list module.
+func Respond(c echo.Context, exportJobUC exportjob.UseCase, eq *utils.ExportQuery, src *export.Source, log logger.Logger) error {
+	ctx := c.Request().Context()
+
+	async, err := exportJobUC.ShouldRunAsync(ctx, eq, src)
+	if err != nil {
+		utils.LogResponseError(c, log, err)
+		return c.JSON(httpErrors.ErrorResponse(err))
+	}
+
+	if async {
+		job, err := exportJobUC.Start(ctx, eq, src)
+		if err != nil {
+			utils.LogResponseError(c, log, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+		c.Response().Header().Set(echo.HeaderLocation, "/v1/api/exports/"+job.Id.String())
+		return c.JSON(http.StatusAccepted, job)
+	}
+
+	c.Response().Header().Set(echo.HeaderContentType, export.ContentType(eq.Format))
+	c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+export.FileName(src.Name, eq.Format)+`"`)
+	c.Response().WriteHeader(http.StatusOK)
+
+	// headers are already sent, a failure can only be logged
+	if err = exportJobUC.Write(ctx, eq, src, c.Response()); err != nil {
+		utils.LogResponseError(c, log, err)
+	}
+	return nil
+}
+
+// GetJob godoc
+// @Summary      Get export job
+// @Description  Returns status of an async export job, download_url is set when the file is ready
+// @Tags         Export
+// @Produce      json
+// @Param        id   path      string  true  "Export job ID"
+// @Success      200  {object}  models.ExportJob
+// @Failure      401  {object}  httpErrors.Problem
+// @Failure      404  {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /exports/{id} [get]
+func (h *exportJobHandlers) GetJob() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		job, err := h.exportJobUC.GetJob(ctx, id)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, job)
+	}
+}
+
+// Download godoc
+// @Summary      Download export file
+// @Description  Downloads the file of a finished export job
+// @Tags         Export
+// @Produce      text/csv
+// @Produce      application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param        id   path      string  true  "Export job ID"
+// @Success      200  {file}    file
+// @Failure      401  {object}  httpErrors.Problem
+// @Failure      404  {object}  httpErrors.Problem
+// @Failure      409  {object}  httpErrors.Problem    "Export not finished"
+// @Security     JWT
+// @Router       /exports/{id}/download [get]
+func (h *exportJobHandlers) Download() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		job, err := h.exportJobUC.GetJob(ctx, id)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+		if job.Status != models.ExportJobDone {
+			return c.JSON(httpErrors.ErrorResponse(httpErrors.NewRestError(http.StatusConflict, "export is "+job.Status, nil)))
+		}
+
+		c.Response().Header().Set(echo.HeaderContentType, export.ContentType(job.Format))
+		return c.Attachment(job.FilePath, export.FileName(job.Entity, job.Format))
+	}
+}
@@ -0,0 +1,14 @@
+package http
+
+import (
+	"github.com/adohong4/driving-license/config"
+	"github.com/adohong4/driving-license/internal/auth"
+	exportjob "github.com/adohong4/driving-license/internal/export_job"
+	"github.com/adohong4/driving-license/internal/middleware"
+	"github.com/labstack/echo/v4"
+)
+
+func MapExportJobRoutes(exportGroup *echo.Group, h exportjob.Handlers, mw *middleware.MiddlewareManager, cfg *config.Config, authUC auth.UseCase) {
+	exportGroup.GET("/:id", h.GetJob(), mw.AuthJWTMiddleware(authUC, cfg))
+	exportGroup.GET("/:id/download", h.Download(), mw.AuthJWTMiddleware(authUC, cfg))
+}
@@ -0,0 +1,14 @@
+package exportjob
+
+import (
+	"context"
+
+	"github.com/adohong4/driving-license/internal/models"
+	"github.com/google/uuid"
+)
+
+type Repository interface {
+	CreateJob(ctx context.Context, job *models.ExportJob) (*models.ExportJob, error)
+	UpdateJob(ctx context.Context, job *models.ExportJob) (*models.ExportJob, error)
+	GetJobById(ctx context.Context, id uuid.UUID) (*models.ExportJob, error)
+}
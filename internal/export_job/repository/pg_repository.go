@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+
+	exportjob "github.com/adohong4/driving-license/internal/export_job"
+	"github.com/adohong4/driving-license/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+type exportJobRepo struct {
+	db *sqlx.DB
+}
+
+func NewExportJobRepo(db *sqlx.DB) exportjob.Repository {
+	return &exportJobRepo{db: db}
+}
+
+func (r *exportJobRepo) CreateJob(ctx context.Context, job *models.ExportJob) (*models.ExportJob, error) {
+	j := &models.ExportJob{}
+	if err := r.db.QueryRowxContext(ctx, createJobQuery,
+		job.Id, job.UserId, job.Entity, job.Format, job.Lang, job.Search, job.Status,
+	).StructScan(j); err != nil {
+		return nil, errors.Wrap(err, "exportJobRepo.CreateJob.StructScan")
+	}
+	return j, nil
+}
+
+func (r *exportJobRepo) UpdateJob(ctx context.Context, job *models.ExportJob) (*models.ExportJob, error) {
+	j := &models.ExportJob{}
+	if err := r.db.QueryRowxContext(ctx, updateJobQuery,
+		job.Id, job.Status, job.RowCount, job.FilePath, job.Error, job.FinishedAt,
+	).StructScan(j); err != nil {
+		return nil, errors.Wrap(err, "exportJobRepo.UpdateJob.StructScan")
+	}
+	return j, nil
+}
+
+func (r *exportJobRepo) GetJobById(ctx context.Context, id uuid.UUID) (*models.ExportJob, error) {
+	j := &models.ExportJob{}
+	if err := r.db.GetContext(ctx, j, getJobByIdQuery, id); err != nil {
+		return nil, errors.Wrap(err, "exportJobRepo.GetJobById.GetContext")
+	}
+	return j, nil
+}
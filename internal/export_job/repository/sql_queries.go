@@ -0,0 +1,22 @@
+package repository
+
+const (
+	createJobQuery = `
+	INSERT INTO export_jobs (id, user_id, entity, format, lang, search, status, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, now())
+	RETURNING *
+	`
+
+	updateJobQuery = `
+	UPDATE export_jobs
+	SET status = $2, row_count = $3, file_path = $4, error = $5, finished_at = $6
+	WHERE id = $1
+	RETURNING *
+	`
+
+	getJobByIdQuery = `
+	SELECT *
+	FROM export_jobs
+	WHERE id = $1
+	`
+)
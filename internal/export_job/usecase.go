@@ -0,0 +1,18 @@
+package exportjob
+
+import (
+	"context"
+	"io"
+
+	"github.com/adohong4/driving-license/internal/models"
+	"github.com/adohong4/driving-license/pkg/export"
+	"github.com/adohong4/driving-license/pkg/utils"
+	"github.com/google/uuid"
+)
+
+type UseCase interface {
+	ShouldRunAsync(ctx context.Context, eq *utils.ExportQuery, src *export.Source) (bool, error)
+	Write(ctx context.Context, eq *utils.ExportQuery, src *export.Source, w io.Writer) error
+	Start(ctx context.Context, eq *utils.ExportQuery, src *export.Source) (*models.ExportJob, error)
+	GetJob(ctx context.Context, id uuid.UUID) (*models.ExportJob, error)
+}
@@ -0,0 +1,160 @@
+package usecase
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/adohong4/driving-license/config"
+	exportjob "github.com/adohong4/driving-license/internal/export_job"
+	"github.com/adohong4/driving-license/internal/models"
+	"github.com/adohong4/driving-license/pkg/export"
+	"github.com/adohong4/driving-license/pkg/httpErrors"
+	"github.com/adohong4/driving-license/pkg/logger"
+	"github.com/adohong4/driving-license/pkg/utils"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+const (
+	asyncRowThreshold = 5000 // larger exports run as background jobs
+	maxRunningJobs    = 2
+	jobTimeout        = 30 * time.Minute
+	defaultExportsDir = "driving-license-exports"
+)
+
+type exportJobUC struct {
+	cfg           *config.Config
+	exportJobRepo exportjob.Repository
+	logger        logger.Logger
+	slots         chan struct{}
+}
+
+func NewExportJobUseCase(cfg *config.Config, exportJobRepo exportjob.Repository, logger logger.Logger) exportjob.UseCase {
+	return &exportJobUC{cfg: cfg, exportJobRepo: exportJobRepo, logger: logger, slots: make(chan struct{}, maxRunningJobs)}
+}
+
+// Large exports and explicit async requests run as jobs
+func (u *exportJobUC) ShouldRunAsync(ctx context.Context, eq *utils.ExportQuery, src *export.Source) (bool, error) {
+	if eq.Async {
+		return true, nil
+	}
+	count, err := src.Count(ctx)
+	if err != nil {
+		return false, err
+	}
+	return count > asyncRowThreshold, nil
+}
+
+// Stream the export synchronously
+func (u *exportJobUC) Write(ctx context.Context, eq *utils.ExportQuery, src *export.Source, w io.Writer) error {
+	if _, err := export.Write(ctx, src, eq.Format, eq.Lang, w); err != nil {
+		return errors.Wrap(err, "exportJobUC.Write")
+	}
+	return nil
+}
+
+// Create a job and write the export file in the background
+func (u *exportJobUC) Start(ctx context.Context, eq *utils.ExportQuery, src *export.Source) (*models.ExportJob, error) {
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return nil, httpErrors.NewUnauthorizedError(errors.WithMessage(err, "exportJobUC.Start.GetUserFromCtx"))
+	}
+
+	job, err := u.exportJobRepo.CreateJob(ctx, &models.ExportJob{
+		Id:     uuid.New(),
+		UserId: user.Id,
+		Entity: src.Name,
+		Format: eq.Format,
+		Lang:   eq.Lang,
+		Search: eq.Search,
+		Status: models.ExportJobPending,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// the job outlives the request
+	jobCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), jobTimeout)
+	go func() {
+		defer cancel()
+		u.run(jobCtx, job, src)
+	}()
+
+	job.DownloadURL = downloadURL(job)
+	return job, nil
+}
+
+func (u *exportJobUC) GetJob(ctx context.Context, id uuid.UUID) (*models.ExportJob, error) {
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return nil, httpErrors.NewUnauthorizedError(errors.WithMessage(err, "exportJobUC.GetJob.GetUserFromCtx"))
+	}
+
+	job, err := u.exportJobRepo.GetJobById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.UserId != user.Id {
+		return nil, httpErrors.NewNotFoundError("export job not found")
+	}
+
+	job.DownloadURL = downloadURL(job)
+	return job, nil
+}
+
+func (u *exportJobUC) run(ctx context.Context, job *models.ExportJob, src *export.Source) {
+	u.slots <- struct{}{}
+	defer func() { <-u.slots }()
+
+	job.Status = models.ExportJobRunning
+	if _, err := u.exportJobRepo.UpdateJob(ctx, job); err != nil {
+		u.logger.Errorf("exportJobUC.run.UpdateJob %s: %v", job.Id, err)
+	}
+
+	path, count, err := u.writeFile(ctx, job, src)
+	job.Status, job.RowCount, job.FilePath = models.ExportJobDone, count, path
+	if err != nil {
+		u.logger.Errorf("exportJobUC.run.writeFile %s: %v", job.Id, err)
+		job.Status, job.Error = models.ExportJobFailed, err.Error()
+	}
+
+	finishedAt := time.Now()
+	job.FinishedAt = &finishedAt
+	if _, err = u.exportJobRepo.UpdateJob(ctx, job); err != nil {
+		u.logger.Errorf("exportJobUC.run.UpdateJob %s: %v", job.Id, err)
+	}
+}
+
+func (u *exportJobUC) writeFile(ctx context.Context, job *models.ExportJob, src *export.Source) (string, int, error) {
+	dir := u.cfg.Store.ExportsFolder
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), defaultExportsDir)
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return "", 0, errors.Wrap(err, "exportJobUC.writeFile.MkdirAll")
+	}
+
+	path := filepath.Join(dir, job.Id.String()+"."+job.Format)
+	f, err := os.Create(path)
+	if err != nil {
+		return "", 0, errors.Wrap(err, "exportJobUC.writeFile.Create")
+	}
+	defer f.Close()
+
+	count, err := export.Write(ctx, src, job.Format, job.Lang, f)
+	if err != nil {
+		_ = os.Remove(path)
+		return "", count, err
+	}
+	return path, count, nil
+}
+
+func downloadURL(job *models.ExportJob) string {
+	if job.Status != models.ExportJobDone {
+		return ""
+	}
+	return "/v1/api/exports/" + job.Id.String() + "/download"
+}
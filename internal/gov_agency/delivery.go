@@ -10,4 +10,23 @@ type Handlers interface {
 	GetAllGovAgency() echo.HandlerFunc
 	SearchByName() echo.HandlerFunc
 	ConnectWallet() echo.HandlerFunc
+
+	// Hierarchy and jurisdiction
+	MoveGovAgency() echo.HandlerFunc
+	GetChildAgencies() echo.HandlerFunc
+	GetAgencyTree() echo.HandlerFunc
+	GetJurisdiction() echo.HandlerFunc
+	AddJurisdiction() echo.HandlerFunc
+	DeleteJurisdiction() echo.HandlerFunc
+
+	// Staff
+	InviteStaff() echo.HandlerFunc
+	UpdateStaff() echo.HandlerFunc
+	DeactivateStaff() echo.HandlerFunc
+	GetStaff() echo.HandlerFunc
+	GetMyMembership() echo.HandlerFunc
+	GetMyInvitations() echo.HandlerFunc
+	AcceptInvitation() echo.HandlerFunc
+	DeclineInvitation() echo.HandlerFunc
+	GetStaffActions() echo.HandlerFunc
 }
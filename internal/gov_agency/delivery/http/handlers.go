@@ -153,6 +153,7 @@ func (h GovAgencyHandlers) GetByID() echo.HandlerFunc {
 // @Produce json
 // @Param page query int false "page number" Format(page)
 // @Param size query int false "number of elements per page" Format(size)
+// @Param sort query string false "Sort fields, comma separated, - prefix for descending, e.g. -created_at"
 // @Param orderBy query int false "filter name" Format(orderBy)
 // @Success 200 {object} models.GovAgency
 // @Router /agency/getAll [get]
@@ -183,6 +184,7 @@ func (h GovAgencyHandlers) GetAllGovAgency() echo.HandlerFunc {
 // @Produce json
 // @Param page query int false "page number" Format(page)
 // @Param size query int false "number of elements per page" Format(size)
+// @Param sort query string false "Sort fields, comma separated, - prefix for descending, e.g. -created_at"
 // @Param orderBy query int false "filter name" Format(orderBy)
 // @Success 200 {object} models.GovAgency
 // @Router /agency/search [get]
@@ -229,3 +231,535 @@ func (h *GovAgencyHandlers) ConnectWallet() echo.HandlerFunc {
 		return c.JSON(http.StatusOK, userWithToken)
 	}
 }
+
+// MoveGovAgency godoc
+// @Summary      Move an agency in the hierarchy
+// @Description  Admin only. Sets the parent and level of the agency, the parent sits one level above (ministry > department > district) and agencies below stay one level down
+// @Tags         Goverment Agency
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                    true  "Agency ID (UUID)"
+// @Param        request  body      models.MoveAgencyRequest  true  "Parent and level"
+// @Success      200      {object}  models.GovAgency
+// @Failure      400      {object}  httpErrors.Problem
+// @Failure      401      {object}  httpErrors.Problem
+// @Failure      403      {object}  httpErrors.Problem
+// @Failure      404      {object}  httpErrors.Problem
+// @Failure      409      {object}  httpErrors.Problem
+// @Failure      500      {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /agency/{id}/parent [put]
+func (h GovAgencyHandlers) MoveGovAgency() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		req := &models.MoveAgencyRequest{}
+		if err = c.Bind(req); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		res, err := h.GovAgencyUC.MoveGovAgency(ctx, id, req)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+// GetChildAgencies godoc
+// @Summary      List the agencies directly below an agency
+// @Tags         Goverment Agency
+// @Produce      json
+// @Param        id   path      string  true  "Agency ID (UUID)"
+// @Success      200  {array}   models.GovAgency
+// @Failure      400  {object}  httpErrors.Problem
+// @Failure      404  {object}  httpErrors.Problem
+// @Failure      500  {object}  httpErrors.Problem
+// @Router       /agency/{id}/children [get]
+func (h GovAgencyHandlers) GetChildAgencies() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		res, err := h.GovAgencyUC.GetChildAgencies(ctx, id)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+// GetAgencyTree godoc
+// @Summary      Get the agency tree below an agency
+// @Description  The agency with every active agency below it, nested by parent
+// @Tags         Goverment Agency
+// @Produce      json
+// @Param        id   path      string  true  "Agency ID (UUID)"
+// @Success      200  {object}  models.AgencyNode
+// @Failure      400  {object}  httpErrors.Problem
+// @Failure      404  {object}  httpErrors.Problem
+// @Failure      500  {object}  httpErrors.Problem
+// @Router       /agency/{id}/tree [get]
+func (h GovAgencyHandlers) GetAgencyTree() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		res, err := h.GovAgencyUC.GetAgencyTree(ctx, id)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+// GetJurisdiction godoc
+// @Summary      Get the jurisdiction of an agency
+// @Description  Areas assigned to the agency and the effective areas including the agencies below it, ministries are national
+// @Tags         Goverment Agency
+// @Produce      json
+// @Param        id   path      string  true  "Agency ID (UUID)"
+// @Success      200  {object}  models.AgencyJurisdictionView
+// @Failure      400  {object}  httpErrors.Problem
+// @Failure      404  {object}  httpErrors.Problem
+// @Failure      500  {object}  httpErrors.Problem
+// @Router       /agency/{id}/jurisdiction [get]
+func (h GovAgencyHandlers) GetJurisdiction() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		res, err := h.GovAgencyUC.GetJurisdiction(ctx, id)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+// AddJurisdiction godoc
+// @Summary      Add a jurisdiction area to an agency
+// @Description  Admin only. An empty district covers the whole province, the area lies in the jurisdiction of the parent agency
+// @Tags         Goverment Agency
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                         true  "Agency ID (UUID)"
+// @Param        request  body      models.AddJurisdictionRequest  true  "Province and district"
+// @Success      201      {object}  models.AgencyJurisdiction
+// @Failure      400      {object}  httpErrors.Problem
+// @Failure      401      {object}  httpErrors.Problem
+// @Failure      403      {object}  httpErrors.Problem
+// @Failure      404      {object}  httpErrors.Problem
+// @Failure      500      {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /agency/{id}/jurisdiction [post]
+func (h GovAgencyHandlers) AddJurisdiction() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		req := &models.AddJurisdictionRequest{}
+		if err = c.Bind(req); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		res, err := h.GovAgencyUC.AddJurisdiction(ctx, id, req)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusCreated, res)
+	}
+}
+
+// DeleteJurisdiction godoc
+// @Summary      Remove a jurisdiction area
+// @Description  Admin only
+// @Tags         Goverment Agency
+// @Produce      json
+// @Param        area_id  path      string  true  "Area ID (UUID)"
+// @Success      200      {object}  models.AgencyJurisdiction
+// @Failure      400      {object}  httpErrors.Problem
+// @Failure      401      {object}  httpErrors.Problem
+// @Failure      403      {object}  httpErrors.Problem
+// @Failure      404      {object}  httpErrors.Problem
+// @Failure      500      {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /agency/jurisdiction/{area_id} [delete]
+func (h GovAgencyHandlers) DeleteJurisdiction() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		areaID, err := uuid.Parse(c.Param("area_id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		res, err := h.GovAgencyUC.DeleteJurisdiction(ctx, areaID)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+// InviteStaff godoc
+// @Summary      Invite a user to the staff of an agency
+// @Description  Admins and the heads and managers of the agency. The user joins once the invitation is accepted, heads are appointed by admins and heads only
+// @Tags         Goverment Agency
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                     true  "Agency ID (UUID)"
+// @Param        request  body      models.InviteStaffRequest  true  "User, position and role (head, manager or clerk)"
+// @Success      201      {object}  models.AgencyStaff
+// @Failure      400      {object}  httpErrors.Problem
+// @Failure      401      {object}  httpErrors.Problem
+// @Failure      403      {object}  httpErrors.Problem
+// @Failure      404      {object}  httpErrors.Problem
+// @Failure      409      {object}  httpErrors.Problem
+// @Failure      500      {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /agency/{id}/staff [post]
+func (h GovAgencyHandlers) InviteStaff() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		req := &models.InviteStaffRequest{}
+		if err = c.Bind(req); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		res, err := h.GovAgencyUC.InviteStaff(ctx, id, req)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusCreated, res)
+	}
+}
+
+// UpdateStaff godoc
+// @Summary      Change the position or role of a staff member
+// @Description  Admins and the heads and managers of the agency, empty fields are kept
+// @Tags         Goverment Agency
+// @Accept       json
+// @Produce      json
+// @Param        id        path      string                     true  "Agency ID (UUID)"
+// @Param        staff_id  path      string                     true  "Staff ID (UUID)"
+// @Param        request   body      models.UpdateStaffRequest  true  "Position and role"
+// @Success      200       {object}  models.AgencyStaff
+// @Failure      400       {object}  httpErrors.Problem
+// @Failure      401       {object}  httpErrors.Problem
+// @Failure      403       {object}  httpErrors.Problem
+// @Failure      404       {object}  httpErrors.Problem
+// @Failure      409       {object}  httpErrors.Problem
+// @Failure      500       {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /agency/{id}/staff/{staff_id} [put]
+func (h GovAgencyHandlers) UpdateStaff() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+		staffID, err := uuid.Parse(c.Param("staff_id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		req := &models.UpdateStaffRequest{}
+		if err = c.Bind(req); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		res, err := h.GovAgencyUC.UpdateStaff(ctx, id, staffID, req)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+// DeactivateStaff godoc
+// @Summary      Deactivate a staff member or cancel an invitation
+// @Description  Admins and the heads and managers of the agency. The user no longer works on the records of the agency
+// @Tags         Goverment Agency
+// @Produce      json
+// @Param        id        path      string  true  "Agency ID (UUID)"
+// @Param        staff_id  path      string  true  "Staff ID (UUID)"
+// @Success      200       {object}  models.AgencyStaff
+// @Failure      400       {object}  httpErrors.Problem
+// @Failure      401       {object}  httpErrors.Problem
+// @Failure      403       {object}  httpErrors.Problem
+// @Failure      404       {object}  httpErrors.Problem
+// @Failure      409       {object}  httpErrors.Problem
+// @Failure      500       {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /agency/{id}/staff/{staff_id}/deactivate [put]
+func (h GovAgencyHandlers) DeactivateStaff() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+		staffID, err := uuid.Parse(c.Param("staff_id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		res, err := h.GovAgencyUC.DeactivateStaff(ctx, id, staffID)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+// GetStaff godoc
+// @Summary      List the staff of an agency
+// @Description  Admins and the members of the agency. Filterable by full_name, position, role, status and dates
+// @Tags         Goverment Agency
+// @Produce      json
+// @Param        id          path      string  true   "Agency ID (UUID)"
+// @Param        page        query     int     false  "Page number (default: 1)"
+// @Param        size        query     int     false  "Page size (default: 10)"
+// @Param        sort        query     string  false  "Sort fields, comma separated, - prefix for descending, e.g. -invited_at"
+// @Param        status      query     string  false  "invited, active, declined or inactive"
+// @Param        role        query     string  false  "head, manager or clerk"
+// @Param        cursor      query     string  false  "Cursor of the next page, empty for the first one, switches to cursor pagination"
+// @Param        with_total  query     bool    false  "Return an approximate total_count on cursor pages"
+// @Success      200         {object}  models.AgencyStaffList
+// @Failure      400         {object}  httpErrors.Problem
+// @Failure      401         {object}  httpErrors.Problem
+// @Failure      403         {object}  httpErrors.Problem
+// @Failure      500         {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /agency/{id}/staff [get]
+func (h GovAgencyHandlers) GetStaff() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+		pq, err := utils.GetPaginationFromCtx(c)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		res, err := h.GovAgencyUC.GetStaff(ctx, id, pq)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+// GetMyMembership godoc
+// @Summary      Get my agency membership
+// @Description  Active membership of the current user, the agency whose records the user works on
+// @Tags         Goverment Agency
+// @Produce      json
+// @Success      200  {object}  models.AgencyStaff
+// @Failure      401  {object}  httpErrors.Problem
+// @Failure      404  {object}  httpErrors.Problem
+// @Failure      500  {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /agency/staff/me [get]
+func (h GovAgencyHandlers) GetMyMembership() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		res, err := h.GovAgencyUC.GetMyMembership(ctx)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+// GetMyInvitations godoc
+// @Summary      List my agency invitations
+// @Description  Open invitations of the current user, latest first
+// @Tags         Goverment Agency
+// @Produce      json
+// @Success      200  {array}   models.AgencyStaff
+// @Failure      401  {object}  httpErrors.Problem
+// @Failure      500  {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /agency/staff/invitations [get]
+func (h GovAgencyHandlers) GetMyInvitations() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		res, err := h.GovAgencyUC.GetMyInvitations(ctx)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+// AcceptInvitation godoc
+// @Summary      Accept an agency invitation
+// @Description  The current user joins the agency, a user is an active member of one agency at a time
+// @Tags         Goverment Agency
+// @Produce      json
+// @Param        staff_id  path      string  true  "Staff ID (UUID) of the invitation"
+// @Success      200       {object}  models.AgencyStaff
+// @Failure      400       {object}  httpErrors.Problem
+// @Failure      401       {object}  httpErrors.Problem
+// @Failure      404       {object}  httpErrors.Problem
+// @Failure      409       {object}  httpErrors.Problem
+// @Failure      500       {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /agency/staff/invitations/{staff_id}/accept [put]
+func (h GovAgencyHandlers) AcceptInvitation() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		staffID, err := uuid.Parse(c.Param("staff_id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		res, err := h.GovAgencyUC.AcceptInvitation(ctx, staffID)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+// DeclineInvitation godoc
+// @Summary      Decline an agency invitation
+// @Tags         Goverment Agency
+// @Produce      json
+// @Param        staff_id  path      string  true  "Staff ID (UUID) of the invitation"
+// @Success      200       {object}  models.AgencyStaff
+// @Failure      400       {object}  httpErrors.Problem
+// @Failure      401       {object}  httpErrors.Problem
+// @Failure      404       {object}  httpErrors.Problem
+// @Failure      500       {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /agency/staff/invitations/{staff_id}/decline [put]
+func (h GovAgencyHandlers) DeclineInvitation() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		staffID, err := uuid.Parse(c.Param("staff_id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		res, err := h.GovAgencyUC.DeclineInvitation(ctx, staffID)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+// GetStaffActions godoc
+// @Summary      List the actions of the staff of an agency
+// @Description  Admins and the heads and managers of the agency. Writes made by the staff members, latest first. Filterable by staff_id, user_id, action, resource_id, status and created_at
+// @Tags         Goverment Agency
+// @Produce      json
+// @Param        id          path      string  true   "Agency ID (UUID)"
+// @Param        page        query     int     false  "Page number (default: 1)"
+// @Param        size        query     int     false  "Page size (default: 10)"
+// @Param        staff_id    query     string  false  "Staff ID (UUID)"
+// @Param        cursor      query     string  false  "Cursor of the next page, empty for the first one, switches to cursor pagination"
+// @Param        with_total  query     bool    false  "Return an approximate total_count on cursor pages"
+// @Success      200         {object}  models.StaffActionList
+// @Failure      400         {object}  httpErrors.Problem
+// @Failure      401         {object}  httpErrors.Problem
+// @Failure      403         {object}  httpErrors.Problem
+// @Failure      500         {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /agency/{id}/actions [get]
+func (h GovAgencyHandlers) GetStaffActions() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+		pq, err := utils.GetPaginationFromCtx(c)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		res, err := h.GovAgencyUC.GetStaffActions(ctx, id, pq)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
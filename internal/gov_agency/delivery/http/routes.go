@@ -1,11 +1,17 @@
 package http
 
 import (
+	"github.com/adohong4/driving-license/config"
+	"github.com/adohong4/driving-license/internal/auth"
 	govagency "github.com/adohong4/driving-license/internal/gov_agency"
+	"github.com/adohong4/driving-license/internal/middleware"
 	"github.com/labstack/echo/v4"
 )
 
-func MapGovAgencyRoutes(GovAgencyGroup *echo.Group, h govagency.Handlers) {
+// The agency hierarchy is managed by admins
+var adminRoles = []string{"admin"}
+
+func MapGovAgencyRoutes(GovAgencyGroup *echo.Group, h govagency.Handlers, mw *middleware.MiddlewareManager, cfg *config.Config, authUC auth.UseCase) {
 	GovAgencyGroup.POST("/create", h.CreateGovAgency())
 	GovAgencyGroup.PUT("/:id", h.UpdateGovAgency())
 	GovAgencyGroup.DELETE("/:id", h.DeleteGovAgency())
@@ -13,4 +19,23 @@ func MapGovAgencyRoutes(GovAgencyGroup *echo.Group, h govagency.Handlers) {
 	GovAgencyGroup.GET("/getAll", h.GetAllGovAgency())
 	GovAgencyGroup.GET("/search", h.SearchByName())
 	GovAgencyGroup.POST("/connect-wallet", h.ConnectWallet())
+
+	// Hierarchy and jurisdiction
+	GovAgencyGroup.GET("/:id/children", h.GetChildAgencies())
+	GovAgencyGroup.GET("/:id/tree", h.GetAgencyTree())
+	GovAgencyGroup.GET("/:id/jurisdiction", h.GetJurisdiction())
+	GovAgencyGroup.PUT("/:id/parent", h.MoveGovAgency(), mw.AuthJWTMiddleware(authUC, cfg), mw.RoleBasedAuthMiddleware(adminRoles))
+	GovAgencyGroup.POST("/:id/jurisdiction", h.AddJurisdiction(), mw.AuthJWTMiddleware(authUC, cfg), mw.RoleBasedAuthMiddleware(adminRoles))
+	GovAgencyGroup.DELETE("/jurisdiction/:area_id", h.DeleteJurisdiction(), mw.AuthJWTMiddleware(authUC, cfg), mw.RoleBasedAuthMiddleware(adminRoles))
+
+	// Staff, managed by admins and the heads and managers of the agency (checked in the use case)
+	GovAgencyGroup.GET("/staff/me", h.GetMyMembership(), mw.AuthJWTMiddleware(authUC, cfg))
+	GovAgencyGroup.GET("/staff/invitations", h.GetMyInvitations(), mw.AuthJWTMiddleware(authUC, cfg))
+	GovAgencyGroup.PUT("/staff/invitations/:staff_id/accept", h.AcceptInvitation(), mw.AuthJWTMiddleware(authUC, cfg))
+	GovAgencyGroup.PUT("/staff/invitations/:staff_id/decline", h.DeclineInvitation(), mw.AuthJWTMiddleware(authUC, cfg))
+	GovAgencyGroup.GET("/:id/staff", h.GetStaff(), mw.AuthJWTMiddleware(authUC, cfg))
+	GovAgencyGroup.POST("/:id/staff", h.InviteStaff(), mw.AuthJWTMiddleware(authUC, cfg))
+	GovAgencyGroup.PUT("/:id/staff/:staff_id", h.UpdateStaff(), mw.AuthJWTMiddleware(authUC, cfg))
+	GovAgencyGroup.PUT("/:id/staff/:staff_id/deactivate", h.DeactivateStaff(), mw.AuthJWTMiddleware(authUC, cfg))
+	GovAgencyGroup.GET("/:id/actions", h.GetStaffActions(), mw.AuthJWTMiddleware(authUC, cfg))
 }
@@ -16,4 +16,29 @@ type Repository interface {
 	GetGovAgencyByID(ctx context.Context, Id uuid.UUID) (*models.GovAgency, error)
 	SearchByName(ctx context.Context, name string, query *utils.PaginationQuery) (*models.GovAgencyList, error)
 	FindAgencyByUserAddress(ctx context.Context, g *models.GovAgency) (*models.GovAgency, error)
+
+	// Hierarchy and jurisdiction
+	MoveGovAgency(ctx context.Context, id uuid.UUID, req *models.MoveAgencyRequest) (*models.GovAgency, error)
+	GetChildAgencies(ctx context.Context, id uuid.UUID) ([]*models.GovAgency, error)
+	GetAgencySubtree(ctx context.Context, id uuid.UUID) ([]*models.GovAgency, error)
+	AddJurisdiction(ctx context.Context, j *models.AgencyJurisdiction) (*models.AgencyJurisdiction, error)
+	DeleteJurisdiction(ctx context.Context, id uuid.UUID) (*models.AgencyJurisdiction, error)
+	GetJurisdiction(ctx context.Context, agencyID uuid.UUID) ([]*models.AgencyJurisdiction, error)
+	GetEffectiveJurisdiction(ctx context.Context, agencyID uuid.UUID) ([]*models.JurisdictionArea, error)
+	InJurisdiction(ctx context.Context, agencyID uuid.UUID, province, district string) (bool, error)
+
+	// Staff
+	GetUserByID(ctx context.Context, userID uuid.UUID) (*models.User, error)
+	InviteStaff(ctx context.Context, s *models.AgencyStaff) (*models.AgencyStaff, error)
+	UpdateStaff(ctx context.Context, agencyID, staffID uuid.UUID, req *models.UpdateStaffRequest) (*models.AgencyStaff, error)
+	DeactivateStaff(ctx context.Context, agencyID, staffID, by uuid.UUID) (*models.AgencyStaff, error)
+	AnswerInvitation(ctx context.Context, staffID, userID uuid.UUID, status string) (*models.AgencyStaff, error)
+	GetStaffByID(ctx context.Context, staffID uuid.UUID) (*models.AgencyStaff, error)
+	GetActiveStaffByUser(ctx context.Context, userID uuid.UUID) (*models.AgencyStaff, error)
+	GetOpenStaff(ctx context.Context, agencyID, userID uuid.UUID) (*models.AgencyStaff, error)
+	GetInvitationsByUser(ctx context.Context, userID uuid.UUID) ([]*models.AgencyStaff, error)
+	FindStaffByUserAddress(ctx context.Context, address string) (*models.AgencyStaff, error)
+	GetStaffByAgency(ctx context.Context, agencyID uuid.UUID, pq *utils.PaginationQuery) (*models.AgencyStaffList, error)
+	CreateStaffAction(ctx context.Context, a *models.StaffAction) error
+	GetStaffActions(ctx context.Context, agencyID uuid.UUID, pq *utils.PaginationQuery) (*models.StaffActionList, error)
 }
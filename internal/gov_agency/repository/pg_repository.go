@@ -24,7 +24,7 @@ func NewGovAgencyRepo(db *sqlx.DB) govagency.Repository {
 func (r *GovAgencyRepo) CreateGovAgency(ctx context.Context, gov *models.GovAgency) (*models.GovAgency, error) {
 	g := &models.GovAgency{}
 	if err := r.db.QueryRowxContext(ctx, createGovAgencyQuery,
-		gov.Id, gov.Name, gov.UserAddress, gov.Address, gov.City, gov.Type, gov.Phone, gov.Email, gov.Status,
+		gov.Id, gov.Name, gov.UserAddress, gov.Address, gov.City, gov.Type, gov.Phone, gov.Email, gov.Status, gov.ParentID, gov.Level,
 		gov.Version, gov.CreatedAt, gov.UpdatedAt, gov.Active,
 	).StructScan(g); err != nil {
 		return nil, errors.Wrap(err, "GovAgencyRepo.CreateGovAgency.StructScan")
@@ -51,8 +51,13 @@ func (r *GovAgencyRepo) DeleteGovAgency(ctx context.Context, gov *models.GovAgen
 }
 
 func (r *GovAgencyRepo) GetGovAgency(ctx context.Context, pq *utils.PaginationQuery) (*models.GovAgencyList, error) {
+	lc, err := pq.ListClause(govAgencyListSpec, 0)
+	if err != nil {
+		return nil, err
+	}
+
 	var totalCount int
-	if err := r.db.GetContext(ctx, &totalCount, getTotalGovAgencyCount); err != nil {
+	if err := r.db.GetContext(ctx, &totalCount, lc.Count(getTotalGovAgencyCount), lc.Args()...); err != nil {
 		return nil, errors.Wrap(err, "GovAgencyRepo.GetGovAgency.GetContext.totalCount")
 	}
 
@@ -68,7 +73,7 @@ func (r *GovAgencyRepo) GetGovAgency(ctx context.Context, pq *utils.PaginationQu
 	}
 
 	var NewGovAgency = make([]*models.GovAgency, 0, pq.GetSize())
-	rows, err := r.db.QueryxContext(ctx, getAllGovAgency, pq.GetOffset(), pq.GetLimit())
+	rows, err := r.db.QueryxContext(ctx, lc.Page(getAllGovAgency), lc.PageArgs(pq)...)
 	if err != nil {
 		return nil, errors.Wrap(err, "GovAgencyRepo.GetGovAgency.NewGovAgency")
 	}
@@ -105,8 +110,13 @@ func (r *GovAgencyRepo) GetGovAgencyByID(ctx context.Context, Id uuid.UUID) (*mo
 }
 
 func (r *GovAgencyRepo) SearchByName(ctx context.Context, name string, query *utils.PaginationQuery) (*models.GovAgencyList, error) {
+	lc, err := query.ListClause(govAgencyListSpec.WithDefaultSort("name"), 1, "name")
+	if err != nil {
+		return nil, err
+	}
+
 	var totalCount int
-	if err := r.db.GetContext(ctx, &totalCount, searchGovAgencyByNameCount, name); err != nil {
+	if err := r.db.GetContext(ctx, &totalCount, lc.Count(searchGovAgencyByNameCount), lc.Args(name)...); err != nil {
 		return nil, errors.Wrap(err, "GovAgencyRepo.SearchByName.GetContext.totalCount")
 	}
 
@@ -122,7 +132,7 @@ func (r *GovAgencyRepo) SearchByName(ctx context.Context, name string, query *ut
 	}
 
 	var NewGovAgency = make([]*models.GovAgency, 0, query.GetSize())
-	rows, err := r.db.QueryxContext(ctx, searchGovAgencyByName, name, query.GetOffset(), query.GetLimit())
+	rows, err := r.db.QueryxContext(ctx, lc.Page(searchGovAgencyByName), lc.PageArgs(query, name)...)
 	if err != nil {
 		return nil, errors.Wrap(err, "GovAgencyRepo.SearchByName.NewGovAgency")
 	}
@@ -161,3 +171,270 @@ func (r *GovAgencyRepo) FindAgencyByUserAddress(ctx context.Context, g *models.G
 	}
 	return foundAgency, nil
 }
+
+// Set the parent and level of the agency
+func (r *GovAgencyRepo) MoveGovAgency(ctx context.Context, id uuid.UUID, req *models.MoveAgencyRequest) (*models.GovAgency, error) {
+	g := &models.GovAgency{}
+	if err := r.db.QueryRowxContext(ctx, moveGovAgencyQuery, req.ParentID, req.Level, id).StructScan(g); err != nil {
+		return nil, errors.Wrap(err, "GovAgencyRepo.MoveGovAgency.StructScan")
+	}
+	return g, nil
+}
+
+// Agencies directly below the agency
+func (r *GovAgencyRepo) GetChildAgencies(ctx context.Context, id uuid.UUID) ([]*models.GovAgency, error) {
+	agencies := []*models.GovAgency{}
+	if err := r.db.SelectContext(ctx, &agencies, getChildAgenciesQuery, id); err != nil {
+		return nil, errors.Wrap(err, "GovAgencyRepo.GetChildAgencies.SelectContext")
+	}
+	return agencies, nil
+}
+
+// The agency and every agency below it, parents first
+func (r *GovAgencyRepo) GetAgencySubtree(ctx context.Context, id uuid.UUID) ([]*models.GovAgency, error) {
+	agencies := []*models.GovAgency{}
+	if err := r.db.SelectContext(ctx, &agencies, getAgencySubtreeQuery, id); err != nil {
+		return nil, errors.Wrap(err, "GovAgencyRepo.GetAgencySubtree.SelectContext")
+	}
+	return agencies, nil
+}
+
+func (r *GovAgencyRepo) AddJurisdiction(ctx context.Context, j *models.AgencyJurisdiction) (*models.AgencyJurisdiction, error) {
+	created := &models.AgencyJurisdiction{}
+	if err := r.db.QueryRowxContext(ctx, createJurisdictionQuery,
+		j.Id, j.AgencyID, j.Province, j.District, j.CreatorId, j.CreatedAt,
+	).StructScan(created); err != nil {
+		return nil, errors.Wrap(err, "GovAgencyRepo.AddJurisdiction.StructScan")
+	}
+	return created, nil
+}
+
+func (r *GovAgencyRepo) DeleteJurisdiction(ctx context.Context, id uuid.UUID) (*models.AgencyJurisdiction, error) {
+	deleted := &models.AgencyJurisdiction{}
+	if err := r.db.QueryRowxContext(ctx, deleteJurisdictionQuery, id).StructScan(deleted); err != nil {
+		return nil, errors.Wrap(err, "GovAgencyRepo.DeleteJurisdiction.StructScan")
+	}
+	return deleted, nil
+}
+
+// Areas assigned to the agency itself
+func (r *GovAgencyRepo) GetJurisdiction(ctx context.Context, agencyID uuid.UUID) ([]*models.AgencyJurisdiction, error) {
+	areas := []*models.AgencyJurisdiction{}
+	if err := r.db.SelectContext(ctx, &areas, getJurisdictionQuery, agencyID); err != nil {
+		return nil, errors.Wrap(err, "GovAgencyRepo.GetJurisdiction.SelectContext")
+	}
+	return areas, nil
+}
+
+// Areas of the agency and of the agencies below it
+func (r *GovAgencyRepo) GetEffectiveJurisdiction(ctx context.Context, agencyID uuid.UUID) ([]*models.JurisdictionArea, error) {
+	areas := []*models.JurisdictionArea{}
+	if err := r.db.SelectContext(ctx, &areas, getEffectiveJurisdictionQuery, agencyID); err != nil {
+		return nil, errors.Wrap(err, "GovAgencyRepo.GetEffectiveJurisdiction.SelectContext")
+	}
+	return areas, nil
+}
+
+// Whether the area lies in the jurisdiction of the agency, an empty district stands for the whole province
+func (r *GovAgencyRepo) InJurisdiction(ctx context.Context, agencyID uuid.UUID, province, district string) (bool, error) {
+	var in bool
+	if err := r.db.GetContext(ctx, &in, inJurisdictionQuery, agencyID, province, district); err != nil {
+		return false, errors.Wrap(err, "GovAgencyRepo.InJurisdiction.GetContext")
+	}
+	return in, nil
+}
+
+func (r *GovAgencyRepo) GetUserByID(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	u := &models.User{}
+	if err := r.db.GetContext(ctx, u, getUserQuery, userID); err != nil {
+		return nil, errors.Wrap(err, "GovAgencyRepo.GetUserByID.GetContext")
+	}
+	return u, nil
+}
+
+func (r *GovAgencyRepo) InviteStaff(ctx context.Context, s *models.AgencyStaff) (*models.AgencyStaff, error) {
+	invited := &models.AgencyStaff{}
+	if err := r.db.QueryRowxContext(ctx, inviteStaffQuery,
+		s.Id, s.AgencyID, s.UserID, s.Position, s.Role, s.InviterID, s.InvitedAt,
+	).StructScan(invited); err != nil {
+		return nil, errors.Wrap(err, "GovAgencyRepo.InviteStaff.StructScan")
+	}
+	return invited, nil
+}
+
+func (r *GovAgencyRepo) UpdateStaff(ctx context.Context, agencyID, staffID uuid.UUID, req *models.UpdateStaffRequest) (*models.AgencyStaff, error) {
+	s := &models.AgencyStaff{}
+	if err := r.db.QueryRowxContext(ctx, updateStaffQuery, req.Position, req.Role, staffID, agencyID).StructScan(s); err != nil {
+		return nil, errors.Wrap(err, "GovAgencyRepo.UpdateStaff.StructScan")
+	}
+	return s, nil
+}
+
+// Deactivate the member, the user leaves the agency in the same transaction
+func (r *GovAgencyRepo) DeactivateStaff(ctx context.Context, agencyID, staffID, by uuid.UUID) (*models.AgencyStaff, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "GovAgencyRepo.DeactivateStaff.BeginTxx")
+	}
+	defer tx.Rollback()
+
+	s := &models.AgencyStaff{}
+	if err = tx.QueryRowxContext(ctx, deactivateStaffQuery, by, staffID, agencyID).StructScan(s); err != nil {
+		return nil, errors.Wrap(err, "GovAgencyRepo.DeactivateStaff.StructScan")
+	}
+	if _, err = tx.ExecContext(ctx, clearUserAgencyQuery, s.UserID, s.AgencyID); err != nil {
+		return nil, errors.Wrap(err, "GovAgencyRepo.DeactivateStaff.clearUserAgency")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "GovAgencyRepo.DeactivateStaff.Commit")
+	}
+	return s, nil
+}
+
+// Accept or decline an invitation of the user, an accepting user joins the agency in the same transaction
+func (r *GovAgencyRepo) AnswerInvitation(ctx context.Context, staffID, userID uuid.UUID, status string) (*models.AgencyStaff, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "GovAgencyRepo.AnswerInvitation.BeginTxx")
+	}
+	defer tx.Rollback()
+
+	s := &models.AgencyStaff{}
+	if err = tx.QueryRowxContext(ctx, answerInvitationQuery, staffID, userID, status).StructScan(s); err != nil {
+		return nil, errors.Wrap(err, "GovAgencyRepo.AnswerInvitation.StructScan")
+	}
+	if status == models.StaffActive {
+		if _, err = tx.ExecContext(ctx, setUserAgencyQuery, s.AgencyID, s.UserID); err != nil {
+			return nil, errors.Wrap(err, "GovAgencyRepo.AnswerInvitation.setUserAgency")
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "GovAgencyRepo.AnswerInvitation.Commit")
+	}
+	return s, nil
+}
+
+func (r *GovAgencyRepo) GetStaffByID(ctx context.Context, staffID uuid.UUID) (*models.AgencyStaff, error) {
+	s := &models.AgencyStaff{}
+	if err := r.db.GetContext(ctx, s, getStaffByIDQuery, staffID); err != nil {
+		return nil, errors.Wrap(err, "GovAgencyRepo.GetStaffByID.GetContext")
+	}
+	return s, nil
+}
+
+func (r *GovAgencyRepo) GetActiveStaffByUser(ctx context.Context, userID uuid.UUID) (*models.AgencyStaff, error) {
+	s := &models.AgencyStaff{}
+	if err := r.db.GetContext(ctx, s, getActiveStaffByUserQuery, userID); err != nil {
+		return nil, errors.Wrap(err, "GovAgencyRepo.GetActiveStaffByUser.GetContext")
+	}
+	return s, nil
+}
+
+// Invited or active membership of the user in the agency
+func (r *GovAgencyRepo) GetOpenStaff(ctx context.Context, agencyID, userID uuid.UUID) (*models.AgencyStaff, error) {
+	s := &models.AgencyStaff{}
+	if err := r.db.GetContext(ctx, s, getOpenStaffQuery, agencyID, userID); err != nil {
+		return nil, errors.Wrap(err, "GovAgencyRepo.GetOpenStaff.GetContext")
+	}
+	return s, nil
+}
+
+func (r *GovAgencyRepo) GetInvitationsByUser(ctx context.Context, userID uuid.UUID) ([]*models.AgencyStaff, error) {
+	invitations := []*models.AgencyStaff{}
+	if err := r.db.SelectContext(ctx, &invitations, getInvitationsByUserQuery, userID); err != nil {
+		return nil, errors.Wrap(err, "GovAgencyRepo.GetInvitationsByUser.SelectContext")
+	}
+	return invitations, nil
+}
+
+func (r *GovAgencyRepo) FindStaffByUserAddress(ctx context.Context, address string) (*models.AgencyStaff, error) {
+	s := &models.AgencyStaff{}
+	if err := r.db.GetContext(ctx, s, findStaffByUserAddressQuery, address); err != nil {
+		return nil, errors.Wrap(err, "GovAgencyRepo.FindStaffByUserAddress.GetContext")
+	}
+	return s, nil
+}
+
+func (r *GovAgencyRepo) GetStaffByAgency(ctx context.Context, agencyID uuid.UUID, pq *utils.PaginationQuery) (*models.AgencyStaffList, error) {
+	lc, err := pq.ListClause(agencyStaffListSpec, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := lc.Total(ctx, r.db, getStaffByAgencyCount, getStaffByAgencyQuery, agencyID)
+	if err != nil {
+		return nil, errors.Wrap(err, "GovAgencyRepo.GetStaffByAgency.total")
+	}
+
+	list := &models.AgencyStaffList{
+		TotalCount: total,
+		TotalPages: utils.GetTotalPage(total, pq.GetSize()),
+		Page:       pq.GetPage(),
+		Size:       pq.GetSize(),
+		HasMore:    utils.GetHasMore(pq.GetPage(), total, pq.GetSize()),
+		Staff:      []*models.AgencyStaff{},
+	}
+
+	if lc.Empty(total) {
+		return list, nil
+	}
+
+	var items []*models.AgencyStaff
+	if err := r.db.SelectContext(ctx, &items, lc.Page(getStaffByAgencyQuery), lc.PageArgs(pq, agencyID)...); err != nil {
+		return nil, errors.Wrap(err, "GovAgencyRepo.GetStaffByAgency.Select")
+	}
+
+	if list.Staff, list.NextCursor, err = utils.NextCursor(lc, items); err != nil {
+		return nil, errors.Wrap(err, "GovAgencyRepo.GetStaffByAgency.NextCursor")
+	}
+	list.HasMore = lc.HasMore(pq, total, list.NextCursor)
+	return list, nil
+}
+
+// Keep the action of the user, nothing is kept for users who are not active staff
+func (r *GovAgencyRepo) CreateStaffAction(ctx context.Context, a *models.StaffAction) error {
+	if _, err := r.db.ExecContext(ctx, createStaffActionQuery,
+		a.Id, a.UserID, a.Action, a.ResourceID, a.Status, a.RequestID, a.CreatedAt,
+	); err != nil {
+		return errors.Wrap(err, "GovAgencyRepo.CreateStaffAction.ExecContext")
+	}
+	return nil
+}
+
+func (r *GovAgencyRepo) GetStaffActions(ctx context.Context, agencyID uuid.UUID, pq *utils.PaginationQuery) (*models.StaffActionList, error) {
+	lc, err := pq.ListClause(staffActionListSpec, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := lc.Total(ctx, r.db, getStaffActionsCount, getStaffActionsQuery, agencyID)
+	if err != nil {
+		return nil, errors.Wrap(err, "GovAgencyRepo.GetStaffActions.total")
+	}
+
+	list := &models.StaffActionList{
+		TotalCount: total,
+		TotalPages: utils.GetTotalPage(total, pq.GetSize()),
+		Page:       pq.GetPage(),
+		Size:       pq.GetSize(),
+		HasMore:    utils.GetHasMore(pq.GetPage(), total, pq.GetSize()),
+		Actions:    []*models.StaffAction{},
+	}
+
+	if lc.Empty(total) {
+		return list, nil
+	}
+
+	var items []*models.StaffAction
+	if err := r.db.SelectContext(ctx, &items, lc.Page(getStaffActionsQuery), lc.PageArgs(pq, agencyID)...); err != nil {
+		return nil, errors.Wrap(err, "GovAgencyRepo.GetStaffActions.Select")
+	}
+
+	if list.Actions, list.NextCursor, err = utils.NextCursor(lc, items); err != nil {
+		return nil, errors.Wrap(err, "GovAgencyRepo.GetStaffActions.NextCursor")
+	}
+	list.HasMore = lc.HasMore(pq, total, list.NextCursor)
+	return list, nil
+}
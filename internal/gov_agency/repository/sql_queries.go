@@ -1,14 +1,63 @@
 package repository
 
+import "github.com/adohong4/driving-license/pkg/utils"
+
+// Filters and sorts accepted by the agency lists, filter and sort clauses are appended to the queries below
+var govAgencyListSpec = &utils.ListSpec{
+	Fields: map[string]utils.ListField{
+		"name":         {Column: "name", Type: utils.FilterText, Sortable: true},
+		"user_address": {Column: "user_address", Type: utils.FilterExact},
+		"address":      {Column: "address", Type: utils.FilterText},
+		"city":         {Column: "city", Type: utils.FilterExact, Sortable: true},
+		"type":         {Column: "type", Type: utils.FilterExact, Sortable: true},
+		"phone":        {Column: "phone", Type: utils.FilterText},
+		"email":        {Column: "email", Type: utils.FilterText},
+		"status":       {Column: "status", Type: utils.FilterExact, Sortable: true},
+		"parent_id":    {Column: "parent_id", Type: utils.FilterUUID},
+		"level":        {Column: "level", Type: utils.FilterExact, Sortable: true},
+		"created_at":   {Column: "created_at", Type: utils.FilterDate, Sortable: true},
+		"updated_at":   {Column: "updated_at", Type: utils.FilterDate, Sortable: true},
+	},
+	DefaultSort: "updated_at,created_at",
+}
+
+// Filters and sorts accepted by the staff list of an agency
+var agencyStaffListSpec = &utils.ListSpec{
+	Fields: map[string]utils.ListField{
+		"full_name":  {Column: "u.full_name", Type: utils.FilterText, Sortable: true, Keyset: true},
+		"position":   {Column: "s.position", Type: utils.FilterText, Sortable: true, Keyset: true},
+		"role":       {Column: "s.role", Type: utils.FilterExact, Sortable: true, Keyset: true},
+		"status":     {Column: "s.status", Type: utils.FilterExact, Sortable: true, Keyset: true},
+		"invited_at": {Column: "s.invited_at", Type: utils.FilterDate, Sortable: true, Keyset: true},
+		"joined_at":  {Column: "s.joined_at", Type: utils.FilterDate, Sortable: true},
+	},
+	DefaultSort: "-invited_at",
+	IDColumn:    "s.id",
+}
+
+// Filters and sorts accepted by the staff action log of an agency
+var staffActionListSpec = &utils.ListSpec{
+	Fields: map[string]utils.ListField{
+		"staff_id":    {Column: "a.staff_id", Type: utils.FilterUUID},
+		"user_id":     {Column: "a.user_id", Type: utils.FilterUUID},
+		"action":      {Column: "a.action", Type: utils.FilterText},
+		"resource_id": {Column: "a.resource_id", Type: utils.FilterExact},
+		"status":      {Column: "a.status", Type: utils.FilterNumber},
+		"created_at":  {Column: "a.created_at", Type: utils.FilterDate, Sortable: true, Keyset: true},
+	},
+	DefaultSort: "-created_at",
+	IDColumn:    "a.id",
+}
+
 const (
 	createGovAgencyQuery = `
 	INSERT INTO gov_agencies (
-		id, name, user_address, address, city, type, phone, email, status, 
+		id, name, user_address, address, city, type, phone, email, status, parent_id, level,
 		version, created_at, updated_at, active
 	) VALUES (
-		$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+		$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15
 	) RETURNING 
-		id, name, user_address, address, city, type, phone, email, status, 
+		id, name, user_address, address, city, type, phone, email, status, parent_id, level,
 		version, created_at, updated_at, active
 	`
 
@@ -61,17 +110,14 @@ const (
 	searchGovAgencyByName = `
 	SELECT * 
 	FROM gov_agencies
-	WHERE name ILIKE '%' || $1 || '%' AND active = true	
-	ORDER BY name
-	OFFSET $2 LIMIT $3
+	WHERE name ILIKE '%' || $1 || '%' AND active = true
 	`
 
 	getAllGovAgency = `
-	SELECT id, user_address, name, address, city, type, phone, email, status, 
+	SELECT id, user_address, name, address, city, type, phone, email, status, parent_id, level,
 		version, updated_at, created_at, active
 	FROM gov_agencies
 	WHERE active = true
-	ORDER BY updated_at, created_at OFFSET $1 LIMIT $2
 	`
 
 	findGovAgencyByName = `
@@ -85,4 +131,203 @@ const (
 	FROM gov_agencies
 	WHERE user_address = $1 AND active = true 
 	`
+
+	//-----HIERARCHY-------------
+	moveGovAgencyQuery = `
+	UPDATE gov_agencies
+	SET
+		parent_id = $1,
+		level = $2,
+		version = version + 1,
+		updated_at = now()
+	WHERE id = $3 AND active = true
+	RETURNING *
+	`
+
+	getChildAgenciesQuery = `
+	SELECT *
+	FROM gov_agencies
+	WHERE parent_id = $1 AND active = true
+	ORDER BY name
+	`
+
+	// the root and every active agency below it, parents first
+	getAgencySubtreeQuery = `
+	WITH RECURSIVE tree AS (
+		SELECT g.*, 0 AS depth FROM gov_agencies g WHERE g.id = $1 AND g.active = true
+		UNION ALL
+		SELECT g.*, t.depth + 1 FROM gov_agencies g JOIN tree t ON g.parent_id = t.id WHERE g.active = true
+	)
+	SELECT id, name, user_address, address, city, type, phone, email, status, parent_id, level,
+		version, created_at, updated_at, active
+	FROM tree
+	ORDER BY depth, name
+	`
+
+	createJurisdictionQuery = `
+	INSERT INTO agency_jurisdictions (id, agency_id, province, district, creator_id, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	RETURNING *
+	`
+
+	deleteJurisdictionQuery = `
+	DELETE FROM agency_jurisdictions
+	WHERE id = $1
+	RETURNING *
+	`
+
+	getJurisdictionQuery = `
+	SELECT *
+	FROM agency_jurisdictions
+	WHERE agency_id = $1
+	ORDER BY province, district
+	`
+
+	getEffectiveJurisdictionQuery = `
+	SELECT province, district
+	FROM f_jurisdiction($1)
+	ORDER BY province, district
+	`
+
+	inJurisdictionQuery = `SELECT f_in_jurisdiction($1, $2, $3)`
+
+	//-----STAFF-------------
+	// members with the user and agency they belong to, read from a staff CTE or the table
+	staffViewColumns = `
+	s.*, u.full_name, u.identity_no, u.user_address, g.name AS agency_name
+	`
+
+	staffViewJoin = `
+	JOIN users u ON u.id = s.user_id
+	JOIN gov_agencies g ON g.id = s.agency_id
+	`
+
+	getStaffQuery = `
+	SELECT` + staffViewColumns + `
+	FROM agency_staff s` + staffViewJoin
+
+	inviteStaffQuery = `
+	WITH s AS (
+		INSERT INTO agency_staff (id, agency_id, user_id, position, role, status, inviter_id, invited_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, 'invited', $6, $7, $7, $7)
+		RETURNING *
+	)
+	SELECT` + staffViewColumns + `FROM s` + staffViewJoin
+
+	updateStaffQuery = `
+	WITH s AS (
+		UPDATE agency_staff
+		SET
+			position = COALESCE(NULLIF($1, ''), position),
+			role = COALESCE(NULLIF($2, ''), role),
+			version = version + 1,
+			updated_at = now()
+		WHERE id = $3 AND agency_id = $4 AND status IN ('invited', 'active')
+		RETURNING *
+	)
+	SELECT` + staffViewColumns + `FROM s` + staffViewJoin
+
+	// cancels open invitations too
+	deactivateStaffQuery = `
+	WITH s AS (
+		UPDATE agency_staff
+		SET
+			status = 'inactive',
+			deactivated_by = $1,
+			deactivated_at = now(),
+			version = version + 1,
+			updated_at = now()
+		WHERE id = $2 AND agency_id = $3 AND status IN ('invited', 'active')
+		RETURNING *
+	)
+	SELECT` + staffViewColumns + `FROM s` + staffViewJoin
+
+	// answer an invitation of the user, $3 is active or declined
+	answerInvitationQuery = `
+	WITH s AS (
+		UPDATE agency_staff
+		SET
+			status = $3,
+			joined_at = CASE WHEN $3 = 'active' THEN now() END,
+			version = version + 1,
+			updated_at = now()
+		WHERE id = $1 AND user_id = $2 AND status = 'invited'
+		RETURNING *
+	)
+	SELECT` + staffViewColumns + `FROM s` + staffViewJoin
+
+	getStaffByIDQuery = getStaffQuery + `
+	WHERE s.id = $1
+	`
+
+	getActiveStaffByUserQuery = getStaffQuery + `
+	WHERE s.user_id = $1 AND s.status = 'active'
+	`
+
+	getOpenStaffQuery = getStaffQuery + `
+	WHERE s.agency_id = $1 AND s.user_id = $2 AND s.status IN ('invited', 'active')
+	`
+
+	getInvitationsByUserQuery = getStaffQuery + `
+	WHERE s.user_id = $1 AND s.status = 'invited' AND g.active = true
+	ORDER BY s.invited_at DESC
+	`
+
+	// staff wallets sign in to the agency they are active in
+	findStaffByUserAddressQuery = getStaffQuery + `
+	WHERE u.user_address = $1 AND s.status = 'active' AND u.active = true AND g.active = true
+	`
+
+	getStaffByAgencyQuery = getStaffQuery + `
+	WHERE s.agency_id = $1
+	`
+
+	getStaffByAgencyCount = `
+	SELECT COUNT(*)
+	FROM agency_staff s
+	JOIN users u ON u.id = s.user_id
+	WHERE s.agency_id = $1
+	`
+
+	getUserQuery = `
+	SELECT *
+	FROM users
+	WHERE id = $1 AND active = true
+	`
+
+	// users.agency_id follows the active membership of the user
+	setUserAgencyQuery = `
+	UPDATE users
+	SET agency_id = $1, updated_at = now(), version = version + 1
+	WHERE id = $2
+	`
+
+	// a cancelled invitation leaves the agency the user works for untouched
+	clearUserAgencyQuery = `
+	UPDATE users
+	SET agency_id = NULL, updated_at = now(), version = version + 1
+	WHERE id = $1 AND agency_id = $2
+	`
+
+	// the action is kept only when the user is an active member of an agency
+	createStaffActionQuery = `
+	INSERT INTO agency_staff_actions (id, agency_id, staff_id, user_id, action, resource_id, status, request_id, created_at)
+	SELECT $1, s.agency_id, s.id, s.user_id, $3, $4, $5, $6, $7
+	FROM agency_staff s
+	WHERE s.user_id = $2 AND s.status = 'active'
+	`
+
+	getStaffActionsQuery = `
+	SELECT a.*, u.full_name, s.position
+	FROM agency_staff_actions a
+	JOIN agency_staff s ON s.id = a.staff_id
+	JOIN users u ON u.id = a.user_id
+	WHERE a.agency_id = $1
+	`
+
+	getStaffActionsCount = `
+	SELECT COUNT(*)
+	FROM agency_staff_actions a
+	WHERE a.agency_id = $1
+	`
 )
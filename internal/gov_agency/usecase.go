@@ -16,4 +16,24 @@ type UseCase interface {
 	GetGovAgencyByID(ctx context.Context, Id uuid.UUID) (*models.GovAgency, error)
 	SearchByName(ctx context.Context, name string, query *utils.PaginationQuery) (*models.GovAgencyList, error)
 	ConnectWallet(ctx context.Context, g *models.GovAgency) (*models.AgencyWithToken, error)
+
+	// Hierarchy and jurisdiction
+	MoveGovAgency(ctx context.Context, id uuid.UUID, req *models.MoveAgencyRequest) (*models.GovAgency, error)
+	GetChildAgencies(ctx context.Context, id uuid.UUID) ([]*models.GovAgency, error)
+	GetAgencyTree(ctx context.Context, id uuid.UUID) (*models.AgencyNode, error)
+	GetJurisdiction(ctx context.Context, id uuid.UUID) (*models.AgencyJurisdictionView, error)
+	AddJurisdiction(ctx context.Context, id uuid.UUID, req *models.AddJurisdictionRequest) (*models.AgencyJurisdiction, error)
+	DeleteJurisdiction(ctx context.Context, areaID uuid.UUID) (*models.AgencyJurisdiction, error)
+
+	// Staff
+	InviteStaff(ctx context.Context, agencyID uuid.UUID, req *models.InviteStaffRequest) (*models.AgencyStaff, error)
+	UpdateStaff(ctx context.Context, agencyID, staffID uuid.UUID, req *models.UpdateStaffRequest) (*models.AgencyStaff, error)
+	DeactivateStaff(ctx context.Context, agencyID, staffID uuid.UUID) (*models.AgencyStaff, error)
+	GetStaff(ctx context.Context, agencyID uuid.UUID, pq *utils.PaginationQuery) (*models.AgencyStaffList, error)
+	GetMyMembership(ctx context.Context) (*models.AgencyStaff, error)
+	GetMyInvitations(ctx context.Context) ([]*models.AgencyStaff, error)
+	AcceptInvitation(ctx context.Context, staffID uuid.UUID) (*models.AgencyStaff, error)
+	DeclineInvitation(ctx context.Context, staffID uuid.UUID) (*models.AgencyStaff, error)
+	GetStaffActions(ctx context.Context, agencyID uuid.UUID, pq *utils.PaginationQuery) (*models.StaffActionList, error)
+	RecordStaffAction(ctx context.Context, a *models.StaffAction) error
 }
@@ -2,10 +2,16 @@ package usecase
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
 
 	"github.com/adohong4/driving-license/config"
 	govagency "github.com/adohong4/driving-license/internal/gov_agency"
 	"github.com/adohong4/driving-license/internal/models"
+	"github.com/adohong4/driving-license/internal/notification"
 	"github.com/adohong4/driving-license/pkg/httpErrors"
 	"github.com/adohong4/driving-license/pkg/logger"
 	"github.com/adohong4/driving-license/pkg/utils"
@@ -13,14 +19,22 @@ import (
 	"github.com/pkg/errors"
 )
 
+// Notification code of staff invitations, rendered from the i18n catalogs
+const (
+	notiStaffInvited = "agency_staff_invited"
+
+	notiTypeAgencyStaff = "agency_staff"
+)
+
 type GovAgencyUC struct {
 	cfg           *config.Config
 	GovAgencyRepo govagency.Repository
+	notiUC        notification.UseCase
 	logger        logger.Logger
 }
 
-func NewGovAgencyUseCase(cfg *config.Config, GovAgencyRepo govagency.Repository, logger logger.Logger) govagency.UseCase {
-	return &GovAgencyUC{cfg: cfg, GovAgencyRepo: GovAgencyRepo, logger: logger}
+func NewGovAgencyUseCase(cfg *config.Config, GovAgencyRepo govagency.Repository, notiUC notification.UseCase, logger logger.Logger) govagency.UseCase {
+	return &GovAgencyUC{cfg: cfg, GovAgencyRepo: GovAgencyRepo, notiUC: notiUC, logger: logger}
 }
 
 func (u *GovAgencyUC) CreateGovAgency(ctx context.Context, gov *models.GovAgency) (*models.GovAgency, error) {
@@ -32,6 +46,10 @@ func (u *GovAgencyUC) CreateGovAgency(ctx context.Context, gov *models.GovAgency
 		return nil, httpErrors.NewBadRequestError(errors.Wrap(err, "GovAgencyUC.CreateGovAgency.ValidateStruct"))
 	}
 
+	if err := u.checkParent(ctx, nil, gov.ParentID, gov.Level); err != nil {
+		return nil, err
+	}
+
 	n, err := u.GovAgencyRepo.CreateGovAgency(ctx, gov)
 	if err != nil {
 		return nil, err
@@ -89,19 +107,428 @@ func (u *GovAgencyUC) SearchByName(ctx context.Context, name string, query *util
 	return u.GovAgencyRepo.SearchByName(ctx, name, query)
 }
 
+// The wallet of an agency signs in as the agency, the wallet of a staff member as the member in the
+// context of the agency they are active in
 func (u *GovAgencyUC) ConnectWallet(ctx context.Context, g *models.GovAgency) (*models.AgencyWithToken, error) {
+	g.UserAddress = strings.TrimSpace(g.UserAddress)
+	if g.UserAddress == "" {
+		return nil, httpErrors.NewRestError(http.StatusBadRequest, "user_address is required", nil)
+	}
+
 	foundAgency, err := u.GovAgencyRepo.FindAgencyByUserAddress(ctx, g)
 	if err != nil {
 		return nil, err
 	}
-	token, err := utils.GenerateJWTTokenFromAgencyAddress(foundAgency, u.cfg)
+	if foundAgency != nil {
+		token, err := utils.GenerateJWTTokenFromAgencyAddress(foundAgency, u.cfg)
+		if err != nil {
+			return nil, httpErrors.NewInternalServerError(errors.Wrap(err, "authUC.ConnectWallet.GenerateJWTToken"))
+		}
+
+		return &models.AgencyWithToken{
+			GovAgency: foundAgency,
+			Token:     token,
+		}, nil
+	}
+
+	staff, err := u.GovAgencyRepo.FindStaffByUserAddress(ctx, g.UserAddress)
+	if err != nil {
+		return nil, err
+	}
+	agency, err := u.GovAgencyRepo.GetGovAgencyByID(ctx, staff.AgencyID)
+	if err != nil {
+		return nil, err
+	}
+
+	// the user token, the agency context is read from the active membership of the user on each request
+	token, err := utils.GenerateJWTTokenFromUserAddress(&models.User{
+		Id:          staff.UserID,
+		UserAddress: staff.UserAddress,
+		IdentityNo:  staff.IdentityNo,
+	}, u.cfg)
 	if err != nil {
-		return nil, httpErrors.NewInternalServerError(errors.Wrap(err, "authUC.ConnectWallet.GenerateJWTToken"))
+		return nil, httpErrors.NewInternalServerError(errors.Wrap(err, "GovAgencyUC.ConnectWallet.GenerateJWTTokenFromUserAddress"))
 	}
 
 	return &models.AgencyWithToken{
-		GovAgency: foundAgency,
+		GovAgency: agency,
+		Staff:     staff,
 		Token:     token,
 	}, nil
+}
+
+// Move the agency under another parent, agencies below it must stay one level down
+func (u *GovAgencyUC) MoveGovAgency(ctx context.Context, id uuid.UUID, req *models.MoveAgencyRequest) (*models.GovAgency, error) {
+	if err := utils.ValidateStruct(ctx, req); err != nil {
+		return nil, httpErrors.NewBadRequestError(errors.Wrap(err, "GovAgencyUC.MoveGovAgency.ValidateStruct"))
+	}
+
+	if _, err := u.GovAgencyRepo.GetGovAgencyByID(ctx, id); err != nil {
+		return nil, err
+	}
+	if err := u.checkParent(ctx, &id, req.ParentID, req.Level); err != nil {
+		return nil, err
+	}
+
+	children, err := u.GovAgencyRepo.GetChildAgencies(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	for _, child := range children {
+		if child.Level != models.ChildLevel(req.Level) {
+			return nil, httpErrors.NewRestError(http.StatusConflict,
+				fmt.Sprintf("agency %s below is not a %s level agency", child.Name, models.ChildLevel(req.Level)), nil)
+		}
+	}
+
+	return u.GovAgencyRepo.MoveGovAgency(ctx, id, req)
+}
+
+func (u *GovAgencyUC) GetChildAgencies(ctx context.Context, id uuid.UUID) ([]*models.GovAgency, error) {
+	if _, err := u.GovAgencyRepo.GetGovAgencyByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return u.GovAgencyRepo.GetChildAgencies(ctx, id)
+}
+
+func (u *GovAgencyUC) GetAgencyTree(ctx context.Context, id uuid.UUID) (*models.AgencyNode, error) {
+	agencies, err := u.GovAgencyRepo.GetAgencySubtree(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	tree := models.NewAgencyTree(id, agencies)
+	if tree == nil {
+		return nil, httpErrors.NewNotFoundError(httpErrors.NotFound)
+	}
+	return tree, nil
+}
+
+// Own areas of the agency and the areas rolled up from the agencies below
+func (u *GovAgencyUC) GetJurisdiction(ctx context.Context, id uuid.UUID) (*models.AgencyJurisdictionView, error) {
+	agency, err := u.GovAgencyRepo.GetGovAgencyByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	areas, err := u.GovAgencyRepo.GetJurisdiction(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	effective, err := u.GovAgencyRepo.GetEffectiveJurisdiction(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.AgencyJurisdictionView{
+		AgencyID:  agency.Id,
+		Level:     agency.Level,
+		National:  agency.Level == models.AgencyMinistry,
+		Areas:     areas,
+		Effective: effective,
+	}, nil
+}
+
+// Add an area to the agency, the area lies in the jurisdiction of the parent unless the parent is a ministry
+func (u *GovAgencyUC) AddJurisdiction(ctx context.Context, id uuid.UUID, req *models.AddJurisdictionRequest) (*models.AgencyJurisdiction, error) {
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return nil, httpErrors.NewUnauthorizedError(errors.WithMessage(err, "GovAgencyUC.AddJurisdiction.GetUserFromCtx"))
+	}
+
+	req.Province, req.District = strings.TrimSpace(req.Province), strings.TrimSpace(req.District)
+	if err = utils.ValidateStruct(ctx, req); err != nil {
+		return nil, httpErrors.NewBadRequestError(errors.Wrap(err, "GovAgencyUC.AddJurisdiction.ValidateStruct"))
+	}
+
+	agency, err := u.GovAgencyRepo.GetGovAgencyByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if agency.Level == models.AgencyMinistry {
+		return nil, httpErrors.NewRestError(http.StatusBadRequest, "a ministry is national and has no jurisdiction areas", nil)
+	}
+
+	if agency.ParentID != nil {
+		parent, err := u.GovAgencyRepo.GetGovAgencyByID(ctx, *agency.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		if parent.Level != models.AgencyMinistry {
+			in, err := u.GovAgencyRepo.InJurisdiction(ctx, parent.Id, req.Province, req.District)
+			if err != nil {
+				return nil, err
+			}
+			if !in {
+				return nil, httpErrors.NewRestError(http.StatusBadRequest, "the area is outside the jurisdiction of the parent agency", nil)
+			}
+		}
+	}
+
+	return u.GovAgencyRepo.AddJurisdiction(ctx, &models.AgencyJurisdiction{
+		Id:        uuid.New(),
+		AgencyID:  agency.Id,
+		Province:  req.Province,
+		District:  req.District,
+		CreatorId: user.Id,
+		CreatedAt: time.Now(),
+	})
+}
+
+func (u *GovAgencyUC) DeleteJurisdiction(ctx context.Context, areaID uuid.UUID) (*models.AgencyJurisdiction, error) {
+	return u.GovAgencyRepo.DeleteJurisdiction(ctx, areaID)
+}
+
+// Invite a user to the staff of the agency, the user joins once the invitation is accepted
+func (u *GovAgencyUC) InviteStaff(ctx context.Context, agencyID uuid.UUID, req *models.InviteStaffRequest) (*models.AgencyStaff, error) {
+	user, member, err := u.staffManager(ctx, agencyID)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Prepare()
+	if err = utils.ValidateStruct(ctx, req); err != nil {
+		return nil, httpErrors.NewBadRequestError(errors.Wrap(err, "GovAgencyUC.InviteStaff.ValidateStruct"))
+	}
+	if !canAssign(member, req.Role) {
+		return nil, httpErrors.NewForbiddenError(httpErrors.PermissionDenied)
+	}
+
+	agency, err := u.GovAgencyRepo.GetGovAgencyByID(ctx, agencyID)
+	if err != nil {
+		return nil, err
+	}
+	invitee, err := u.GovAgencyRepo.GetUserByID(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = u.GovAgencyRepo.GetOpenStaff(ctx, agencyID, req.UserID); err == nil {
+		return nil, httpErrors.NewRestError(http.StatusConflict, "the user is already invited to or a member of the agency", nil)
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	staff, err := u.GovAgencyRepo.InviteStaff(ctx, &models.AgencyStaff{
+		Id:        uuid.New(),
+		AgencyID:  agencyID,
+		UserID:    req.UserID,
+		Position:  req.Position,
+		Role:      req.Role,
+		InviterID: user.Id,
+		InvitedAt: time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
 
+	u.notifyInvitation(ctx, invitee, agency, staff)
+	return staff, nil
+}
+
+func (u *GovAgencyUC) UpdateStaff(ctx context.Context, agencyID, staffID uuid.UUID, req *models.UpdateStaffRequest) (*models.AgencyStaff, error) {
+	_, member, err := u.staffManager(ctx, agencyID)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Position = strings.TrimSpace(req.Position)
+	if err = utils.ValidateStruct(ctx, req); err != nil {
+		return nil, httpErrors.NewBadRequestError(errors.Wrap(err, "GovAgencyUC.UpdateStaff.ValidateStruct"))
+	}
+
+	target, err := u.openStaff(ctx, agencyID, staffID)
+	if err != nil {
+		return nil, err
+	}
+	if !canAssign(member, target.Role) || !canAssign(member, req.Role) {
+		return nil, httpErrors.NewForbiddenError(httpErrors.PermissionDenied)
+	}
+
+	return u.GovAgencyRepo.UpdateStaff(ctx, agencyID, staffID, req)
+}
+
+// Deactivate a member or cancel an invitation, the user no longer works on the records of the agency
+func (u *GovAgencyUC) DeactivateStaff(ctx context.Context, agencyID, staffID uuid.UUID) (*models.AgencyStaff, error) {
+	user, member, err := u.staffManager(ctx, agencyID)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := u.openStaff(ctx, agencyID, staffID)
+	if err != nil {
+		return nil, err
+	}
+	if !canAssign(member, target.Role) {
+		return nil, httpErrors.NewForbiddenError(httpErrors.PermissionDenied)
+	}
+
+	return u.GovAgencyRepo.DeactivateStaff(ctx, agencyID, staffID, user.Id)
+}
+
+// Staff of the agency, listed to admins and the members of the agency
+func (u *GovAgencyUC) GetStaff(ctx context.Context, agencyID uuid.UUID, pq *utils.PaginationQuery) (*models.AgencyStaffList, error) {
+	if _, _, err := u.agencyMember(ctx, agencyID); err != nil {
+		return nil, err
+	}
+	return u.GovAgencyRepo.GetStaffByAgency(ctx, agencyID, pq)
+}
+
+// Active membership of the caller
+func (u *GovAgencyUC) GetMyMembership(ctx context.Context) (*models.AgencyStaff, error) {
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return nil, httpErrors.NewUnauthorizedError(errors.WithMessage(err, "GovAgencyUC.GetMyMembership.GetUserFromCtx"))
+	}
+	return u.GovAgencyRepo.GetActiveStaffByUser(ctx, user.Id)
+}
+
+func (u *GovAgencyUC) GetMyInvitations(ctx context.Context) ([]*models.AgencyStaff, error) {
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return nil, httpErrors.NewUnauthorizedError(errors.WithMessage(err, "GovAgencyUC.GetMyInvitations.GetUserFromCtx"))
+	}
+	return u.GovAgencyRepo.GetInvitationsByUser(ctx, user.Id)
+}
+
+// Join the agency of the invitation, a user works for one agency at a time
+func (u *GovAgencyUC) AcceptInvitation(ctx context.Context, staffID uuid.UUID) (*models.AgencyStaff, error) {
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return nil, httpErrors.NewUnauthorizedError(errors.WithMessage(err, "GovAgencyUC.AcceptInvitation.GetUserFromCtx"))
+	}
+
+	current, err := u.GovAgencyRepo.GetActiveStaffByUser(ctx, user.Id)
+	if err == nil {
+		return nil, httpErrors.NewRestError(http.StatusConflict,
+			fmt.Sprintf("already a member of %s, leave it before joining another agency", current.AgencyName), nil)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	return u.GovAgencyRepo.AnswerInvitation(ctx, staffID, user.Id, models.StaffActive)
+}
+
+func (u *GovAgencyUC) DeclineInvitation(ctx context.Context, staffID uuid.UUID) (*models.AgencyStaff, error) {
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return nil, httpErrors.NewUnauthorizedError(errors.WithMessage(err, "GovAgencyUC.DeclineInvitation.GetUserFromCtx"))
+	}
+	return u.GovAgencyRepo.AnswerInvitation(ctx, staffID, user.Id, models.StaffDeclined)
+}
+
+// Writes made by the staff of the agency, listed to admins and the heads and managers of the agency
+func (u *GovAgencyUC) GetStaffActions(ctx context.Context, agencyID uuid.UUID, pq *utils.PaginationQuery) (*models.StaffActionList, error) {
+	if _, _, err := u.staffManager(ctx, agencyID); err != nil {
+		return nil, err
+	}
+	return u.GovAgencyRepo.GetStaffActions(ctx, agencyID, pq)
+}
+
+// Keep a write of the user, kept only when the user is an active staff member
+func (u *GovAgencyUC) RecordStaffAction(ctx context.Context, a *models.StaffAction) error {
+	a.Id = uuid.New()
+	a.CreatedAt = time.Now()
+	return u.GovAgencyRepo.CreateStaffAction(ctx, a)
+}
+
+// Caller acting for the agency, admins or the active members of the agency. The member is nil for admins
+func (u *GovAgencyUC) agencyMember(ctx context.Context, agencyID uuid.UUID) (*models.User, *models.AgencyStaff, error) {
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return nil, nil, httpErrors.NewUnauthorizedError(errors.WithMessage(err, "GovAgencyUC.agencyMember.GetUserFromCtx"))
+	}
+	if user.Role != nil && *user.Role == "admin" {
+		return user, nil, nil
+	}
+
+	member, err := u.GovAgencyRepo.GetActiveStaffByUser(ctx, user.Id)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && member.AgencyID != agencyID) {
+		return nil, nil, httpErrors.NewForbiddenError(httpErrors.PermissionDenied)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, member, nil
+}
+
+// Caller managing the staff of the agency, admins or the active heads and managers of the agency
+func (u *GovAgencyUC) staffManager(ctx context.Context, agencyID uuid.UUID) (*models.User, *models.AgencyStaff, error) {
+	user, member, err := u.agencyMember(ctx, agencyID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if member != nil && !member.ManagesStaff() {
+		return nil, nil, httpErrors.NewForbiddenError(httpErrors.PermissionDenied)
+	}
+	return user, member, nil
+}
+
+// Invited or active member of the agency
+func (u *GovAgencyUC) openStaff(ctx context.Context, agencyID, staffID uuid.UUID) (*models.AgencyStaff, error) {
+	target, err := u.GovAgencyRepo.GetStaffByID(ctx, staffID)
+	if err != nil {
+		return nil, err
+	}
+	if target.AgencyID != agencyID {
+		return nil, httpErrors.NewNotFoundError(httpErrors.NotFound)
+	}
+	if target.Status != models.StaffInvited && target.Status != models.StaffActive {
+		return nil, httpErrors.NewRestError(http.StatusConflict, fmt.Sprintf("the membership is %s", target.Status), nil)
+	}
+	return target, nil
+}
+
+// Heads are appointed and dismissed by admins and heads, managers handle managers and clerks.
+// The member is nil for admins
+func canAssign(member *models.AgencyStaff, role string) bool {
+	return member == nil || member.Role == models.StaffRoleHead || role != models.StaffRoleHead
+}
+
+// Tell the invited user, the invitation is already saved so a failure is only logged
+func (u *GovAgencyUC) notifyInvitation(ctx context.Context, invitee *models.User, agency *models.GovAgency, s *models.AgencyStaff) {
+	if invitee.IdentityNo == "" {
+		return
+	}
+
+	n := &models.Notification{
+		Code:       notiStaffInvited,
+		Type:       notiTypeAgencyStaff,
+		Target:     "personal",
+		TargetUser: invitee.IdentityNo,
+		Status:     "unread",
+		Params: models.NotificationParams{
+			"staff_id":    s.Id.String(),
+			"agency_name": agency.Name,
+			"position":    s.Position,
+			"role":        s.Role,
+		},
+	}
+	if _, err := u.notiUC.CreateNotification(ctx, n); err != nil {
+		u.logger.Errorf("GovAgencyUC.notifyInvitation %s: %v", s.Id, err)
+	}
+}
+
+// The parent sits one level above, ministries are roots. Agencies without a parent are allowed at any level
+// for the agencies registered before the hierarchy
+func (u *GovAgencyUC) checkParent(ctx context.Context, id, parentID *uuid.UUID, level string) error {
+	if parentID == nil {
+		return nil
+	}
+	if level == models.AgencyMinistry {
+		return httpErrors.NewRestError(http.StatusBadRequest, "a ministry has no parent agency", nil)
+	}
+	if id != nil && *id == *parentID {
+		return httpErrors.NewRestError(http.StatusBadRequest, "an agency can not be its own parent", nil)
+	}
+
+	parent, err := u.GovAgencyRepo.GetGovAgencyByID(ctx, *parentID)
+	if err != nil {
+		return err
+	}
+	if models.ChildLevel(parent.Level) != level {
+		return httpErrors.NewRestError(http.StatusBadRequest,
+			fmt.Sprintf("a %s level agency can not be placed under a %s level agency", level, parent.Level), nil)
+	}
+	return nil
 }
@@ -0,0 +1,8 @@
+package importjob
+
+import "github.com/labstack/echo/v4"
+
+type Handlers interface {
+	GetJob() echo.HandlerFunc
+	DownloadErrors() echo.HandlerFunc
+}
@@ -0,0 +1,134 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/adohong4/driving-license/config"
+	importjob "github.com/adohong4/driving-license/internal/import_job"
+	"github.com/adohong4/driving-license/internal/models"
+	"github.com/adohong4/driving-license/pkg/export"
+	"github.com/adohong4/driving-license/pkg/httpErrors"
+	"github.com/adohong4/driving-license/pkg/importer"
+	"github.com/adohong4/driving-license/pkg/logger"
+	"github.com/adohong4/driving-license/pkg/utils"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+const maxUploadSize = 20 << 20 // 20 MB
+
+type importJobHandlers struct {
+	cfg         *config.Config
+	importJobUC importjob.UseCase
+	logger      logger.Logger
+}
+
+func NewImportJobHandlers(cfg *config.Config, importJobUC importjob.UseCase, logger logger.Logger) importjob.Handlers {
+	return &importJobHandlers{cfg: cfg, importJobUC: importJobUC, logger: logger}
+}
+
+// Respond reads the uploaded "file" form field and answers 202 with the queued import job.
+// Shared by the import endpoints of every module.
+func Respond(c echo.Context, importJobUC importjob.UseCase, target *importer.Target, log logger.Logger) error {
+	fh, err := c.FormFile("file")
+	if err != nil {
+		utils.LogResponseError(c, log, err)
+		return c.JSON(httpErrors.ErrorResponse(httpErrors.NewBadRequestError("file is required")))
+	}
+	if fh.Size > maxUploadSize {
+		return c.JSON(httpErrors.ErrorResponse(httpErrors.NewRestError(http.StatusRequestEntityTooLarge, "file is larger than 20 MB", nil)))
+	}
+
+	iq, err := utils.GetImportQueryFromCtx(c, fh.Filename)
+	if err != nil {
+		utils.LogResponseError(c, log, err)
+		return c.JSON(httpErrors.ErrorResponse(httpErrors.NewBadRequestError(err.Error())))
+	}
+
+	file, err := fh.Open()
+	if err != nil {
+		utils.LogResponseError(c, log, err)
+		return c.JSON(httpErrors.ErrorResponse(err))
+	}
+	defer file.Close()
+
+	job, err := importJobUC.Start(c.Request().Context(), iq, file, target)
+	if err != nil {
+		utils.LogResponseError(c, log, err)
+		return c.JSON(httpErrors.ErrorResponse(err))
+	}
+
+	c.Response().Header().Set(echo.HeaderLocation, "/v1/api/imports/"+job.Id.String())
+	return c.JSON(http.StatusAccepted, job)
+}
+
+// GetJob godoc
+// @Summary      Get import job
+// @Description  Returns status and row counts of an import job, error_file_url is set when rows were rejected
+// @Tags         Import
+// @Produce      json
+// @Param        id   path      string  true  "Import job ID"
+// @Success      200  {object}  models.ImportJob
+// @Failure      401  {object}  httpErrors.Problem
+// @Failure      404  {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /imports/{id} [get]
+func (h *importJobHandlers) GetJob() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		job, err := h.importJobUC.GetJob(ctx, id)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, job)
+	}
+}
+
+// DownloadErrors godoc
+// @Summary      Download import error file
+// @Description  Downloads the rejected rows of an import job with the row number, field and error message
+// @Tags         Import
+// @Produce      text/csv
+// @Produce      application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param        id   path      string  true  "Import job ID"
+// @Success      200  {file}    file
+// @Failure      401  {object}  httpErrors.Problem
+// @Failure      404  {object}  httpErrors.Problem    "Job not found or no rejected rows"
+// @Failure      409  {object}  httpErrors.Problem    "Import not finished"
+// @Security     JWT
+// @Router       /imports/{id}/errors [get]
+func (h *importJobHandlers) DownloadErrors() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		job, err := h.importJobUC.GetJob(ctx, id)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+		if job.Status == models.ImportJobPending || job.Status == models.ImportJobRunning {
+			return c.JSON(httpErrors.ErrorResponse(httpErrors.NewRestError(http.StatusConflict, "import is not finished", nil)))
+		}
+		if job.ErrorFilePath == "" {
+			return c.JSON(httpErrors.ErrorResponse(httpErrors.NewNotFoundError("import has no rejected rows")))
+		}
+
+		c.Response().Header().Set(echo.HeaderContentType, export.ContentType(job.Format))
+		return c.Attachment(job.ErrorFilePath, export.FileName(job.Entity+"-errors", job.Format))
+	}
+}
@@ -0,0 +1,14 @@
+package http
+
+import (
+	"github.com/adohong4/driving-license/config"
+	"github.com/adohong4/driving-license/internal/auth"
+	importjob "github.com/adohong4/driving-license/internal/import_job"
+	"github.com/adohong4/driving-license/internal/middleware"
+	"github.com/labstack/echo/v4"
+)
+
+func MapImportJobRoutes(importGroup *echo.Group, h importjob.Handlers, mw *middleware.MiddlewareManager, cfg *config.Config, authUC auth.UseCase) {
+	importGroup.GET("/:id", h.GetJob(), mw.AuthJWTMiddleware(authUC, cfg))
+	importGroup.GET("/:id/errors", h.DownloadErrors(), mw.AuthJWTMiddleware(authUC, cfg))
+}
@@ -0,0 +1,14 @@
+package importjob
+
+import (
+	"context"
+
+	"github.com/adohong4/driving-license/internal/models"
+	"github.com/google/uuid"
+)
+
+type Repository interface {
+	CreateJob(ctx context.Context, job *models.ImportJob) (*models.ImportJob, error)
+	UpdateJob(ctx context.Context, job *models.ImportJob) (*models.ImportJob, error)
+	GetJobById(ctx context.Context, id uuid.UUID) (*models.ImportJob, error)
+}
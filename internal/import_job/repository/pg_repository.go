@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+
+	importjob "github.com/adohong4/driving-license/internal/import_job"
+	"github.com/adohong4/driving-license/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+type importJobRepo struct {
+	db *sqlx.DB
+}
+
+func NewImportJobRepo(db *sqlx.DB) importjob.Repository {
+	return &importJobRepo{db: db}
+}
+
+func (r *importJobRepo) CreateJob(ctx context.Context, job *models.ImportJob) (*models.ImportJob, error) {
+	j := &models.ImportJob{}
+	if err := r.db.QueryRowxContext(ctx, createJobQuery,
+		job.Id, job.UserId, job.Entity, job.Format, job.Lang, job.FileName, job.DryRun, job.Status, job.FilePath,
+	).StructScan(j); err != nil {
+		return nil, errors.Wrap(err, "importJobRepo.CreateJob.StructScan")
+	}
+	return j, nil
+}
+
+func (r *importJobRepo) UpdateJob(ctx context.Context, job *models.ImportJob) (*models.ImportJob, error) {
+	j := &models.ImportJob{}
+	if err := r.db.QueryRowxContext(ctx, updateJobQuery,
+		job.Id, job.Status, job.TotalRows, job.ValidRows, job.ImportedRows, job.FailedRows,
+		job.ErrorFilePath, job.Error, job.FinishedAt,
+	).StructScan(j); err != nil {
+		return nil, errors.Wrap(err, "importJobRepo.UpdateJob.StructScan")
+	}
+	return j, nil
+}
+
+func (r *importJobRepo) GetJobById(ctx context.Context, id uuid.UUID) (*models.ImportJob, error) {
+	j := &models.ImportJob{}
+	if err := r.db.GetContext(ctx, j, getJobByIdQuery, id); err != nil {
+		return nil, errors.Wrap(err, "importJobRepo.GetJobById.GetContext")
+	}
+	return j, nil
+}
@@ -0,0 +1,23 @@
+package repository
+
+const (
+	createJobQuery = `
+	INSERT INTO import_jobs (id, user_id, entity, format, lang, file_name, dry_run, status, file_path, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, now())
+	RETURNING *
+	`
+
+	updateJobQuery = `
+	UPDATE import_jobs
+	SET status = $2, total_rows = $3, valid_rows = $4, imported_rows = $5, failed_rows = $6,
+		error_file_path = $7, error = $8, finished_at = $9
+	WHERE id = $1
+	RETURNING *
+	`
+
+	getJobByIdQuery = `
+	SELECT *
+	FROM import_jobs
+	WHERE id = $1
+	`
+)
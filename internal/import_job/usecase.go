@@ -0,0 +1,16 @@
+package importjob
+
+import (
+	"context"
+	"io"
+
+	"github.com/adohong4/driving-license/internal/models"
+	"github.com/adohong4/driving-license/pkg/importer"
+	"github.com/adohong4/driving-license/pkg/utils"
+	"github.com/google/uuid"
+)
+
+type UseCase interface {
+	Start(ctx context.Context, iq *utils.ImportQuery, file io.Reader, target *importer.Target) (*models.ImportJob, error)
+	GetJob(ctx context.Context, id uuid.UUID) (*models.ImportJob, error)
+}
@@ -0,0 +1,194 @@
+package usecase
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/adohong4/driving-license/config"
+	importjob "github.com/adohong4/driving-license/internal/import_job"
+	"github.com/adohong4/driving-license/internal/models"
+	"github.com/adohong4/driving-license/pkg/export"
+	"github.com/adohong4/driving-license/pkg/httpErrors"
+	"github.com/adohong4/driving-license/pkg/importer"
+	"github.com/adohong4/driving-license/pkg/logger"
+	"github.com/adohong4/driving-license/pkg/utils"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+const (
+	maxRunningJobs    = 2
+	jobTimeout        = 30 * time.Minute
+	defaultImportsDir = "driving-license-imports"
+)
+
+type importJobUC struct {
+	cfg           *config.Config
+	importJobRepo importjob.Repository
+	logger        logger.Logger
+	slots         chan struct{}
+}
+
+func NewImportJobUseCase(cfg *config.Config, importJobRepo importjob.Repository, logger logger.Logger) importjob.UseCase {
+	return &importJobUC{cfg: cfg, importJobRepo: importJobRepo, logger: logger, slots: make(chan struct{}, maxRunningJobs)}
+}
+
+// Store the upload, create a job and import it in the background
+func (u *importJobUC) Start(ctx context.Context, iq *utils.ImportQuery, file io.Reader, target *importer.Target) (*models.ImportJob, error) {
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return nil, httpErrors.NewUnauthorizedError(errors.WithMessage(err, "importJobUC.Start.GetUserFromCtx"))
+	}
+
+	job := &models.ImportJob{
+		Id:       uuid.New(),
+		UserId:   user.Id,
+		Entity:   target.Name,
+		Format:   iq.Format,
+		Lang:     iq.Lang,
+		FileName: iq.FileName,
+		DryRun:   iq.DryRun,
+		Status:   models.ImportJobPending,
+	}
+
+	if job.FilePath, err = u.saveUpload(job, file); err != nil {
+		return nil, err
+	}
+
+	job, err = u.importJobRepo.CreateJob(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+
+	// the job outlives the request
+	jobCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), jobTimeout)
+	go func() {
+		defer cancel()
+		u.run(jobCtx, job, target)
+	}()
+
+	return job, nil
+}
+
+func (u *importJobUC) GetJob(ctx context.Context, id uuid.UUID) (*models.ImportJob, error) {
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return nil, httpErrors.NewUnauthorizedError(errors.WithMessage(err, "importJobUC.GetJob.GetUserFromCtx"))
+	}
+
+	job, err := u.importJobRepo.GetJobById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.UserId != user.Id {
+		return nil, httpErrors.NewNotFoundError("import job not found")
+	}
+
+	job.ErrorFileURL = errorFileURL(job)
+	return job, nil
+}
+
+func (u *importJobUC) run(ctx context.Context, job *models.ImportJob, target *importer.Target) {
+	u.slots <- struct{}{}
+	defer func() { <-u.slots }()
+
+	job.Status = models.ImportJobRunning
+	if _, err := u.importJobRepo.UpdateJob(ctx, job); err != nil {
+		u.logger.Errorf("importJobUC.run.UpdateJob %s: %v", job.Id, err)
+	}
+
+	res, errPath, err := u.process(ctx, job, target)
+	job.Status, job.ErrorFilePath = models.ImportJobDone, errPath
+	job.TotalRows, job.ValidRows, job.ImportedRows, job.FailedRows = res.TotalRows, res.ValidRows, res.ImportedRows, res.FailedRows
+	if err != nil {
+		u.logger.Errorf("importJobUC.run.process %s: %v", job.Id, err)
+		job.Status, job.Error = models.ImportJobFailed, err.Error()
+	}
+
+	// the upload is not needed once processed
+	if err = os.Remove(job.FilePath); err != nil {
+		u.logger.Warnf("importJobUC.run.Remove %s: %v", job.Id, err)
+	}
+
+	finishedAt := time.Now()
+	job.FinishedAt = &finishedAt
+	if _, err = u.importJobRepo.UpdateJob(ctx, job); err != nil {
+		u.logger.Errorf("importJobUC.run.UpdateJob %s: %v", job.Id, err)
+	}
+}
+
+// Import the stored upload and write rejected rows to the error file, the error file is removed when empty
+func (u *importJobUC) process(ctx context.Context, job *models.ImportJob, target *importer.Target) (importer.Result, string, error) {
+	rr, err := importer.Open(job.FilePath, job.Format)
+	if err != nil {
+		return importer.Result{}, "", errors.Wrap(err, "importJobUC.process.Open")
+	}
+	defer rr.Close()
+
+	errPath := filepath.Join(u.dir(), job.Id.String()+".errors."+job.Format)
+	f, err := os.Create(errPath)
+	if err != nil {
+		return importer.Result{}, "", errors.Wrap(err, "importJobUC.process.Create")
+	}
+	defer f.Close()
+
+	ew, err := export.NewRowWriter(job.Format, f)
+	if err != nil {
+		return importer.Result{}, "", err
+	}
+	if err = ew.WriteRow(export.Headers(importer.ErrorColumns, job.Lang)); err != nil {
+		return importer.Result{}, "", errors.Wrap(err, "importJobUC.process.WriteHeader")
+	}
+
+	errCount := 0
+	res, err := importer.Run(ctx, target, rr, job.DryRun, importer.DefaultBatchSize, func(re importer.RowError) error {
+		errCount++
+		return ew.WriteRow(re.Strings())
+	})
+	if cerr := ew.Close(); cerr != nil && err == nil {
+		err = errors.Wrap(cerr, "importJobUC.process.Close")
+	}
+
+	if errCount == 0 {
+		_ = os.Remove(errPath)
+		errPath = ""
+	}
+	return res, errPath, err
+}
+
+func (u *importJobUC) saveUpload(job *models.ImportJob, file io.Reader) (string, error) {
+	dir := u.dir()
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return "", errors.Wrap(err, "importJobUC.saveUpload.MkdirAll")
+	}
+
+	path := filepath.Join(dir, job.Id.String()+"."+job.Format)
+	f, err := os.Create(path)
+	if err != nil {
+		return "", errors.Wrap(err, "importJobUC.saveUpload.Create")
+	}
+	defer f.Close()
+
+	if _, err = io.Copy(f, file); err != nil {
+		_ = os.Remove(path)
+		return "", errors.Wrap(err, "importJobUC.saveUpload.Copy")
+	}
+	return path, nil
+}
+
+func (u *importJobUC) dir() string {
+	if u.cfg.Store.ImportsFolder != "" {
+		return u.cfg.Store.ImportsFolder
+	}
+	return filepath.Join(os.TempDir(), defaultImportsDir)
+}
+
+func errorFileURL(job *models.ImportJob) string {
+	if job.ErrorFilePath == "" {
+		return ""
+	}
+	return "/v1/api/imports/" + job.Id.String() + "/errors"
+}
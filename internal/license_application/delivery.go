@@ -0,0 +1,13 @@
+package licenseApplication
+
+import "github.com/labstack/echo/v4"
+
+type Handlers interface {
+	Submit() echo.HandlerFunc
+	Approve() echo.HandlerFunc
+	Reject() echo.HandlerFunc
+	Cancel() echo.HandlerFunc
+	GetByID() echo.HandlerFunc
+	GetApplications() echo.HandlerFunc
+	GetMyApplications() echo.HandlerFunc
+}
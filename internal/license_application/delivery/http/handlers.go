@@ -0,0 +1,289 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/adohong4/driving-license/config"
+	licenseApplication "github.com/adohong4/driving-license/internal/license_application"
+	"github.com/adohong4/driving-license/internal/models"
+	"github.com/adohong4/driving-license/pkg/httpErrors"
+	"github.com/adohong4/driving-license/pkg/logger"
+	"github.com/adohong4/driving-license/pkg/utils"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type licenseApplicationHandlers struct {
+	cfg    *config.Config
+	appUC  licenseApplication.UseCase
+	logger logger.Logger
+}
+
+func NewLicenseApplicationHandlers(cfg *config.Config, appUC licenseApplication.UseCase, logger logger.Logger) licenseApplication.Handlers {
+	return &licenseApplicationHandlers{cfg: cfg, appUC: appUC, logger: logger}
+}
+
+// Submit godoc
+// @Summary      Submit a license application
+// @Description  The holder applies to renew, upgrade or reissue a license with the required documents. Renewal opens 90 days before the expiry date, upgrades need an exam result and reissues of damaged licenses the damaged license. A license has at most one pending application
+// @Tags         license-application
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.SubmitApplicationRequest  true  "Kind, license and documents"
+// @Success      201      {object}  models.LicenseApplication
+// @Failure      400      {object}  httpErrors.Problem
+// @Failure      401      {object}  httpErrors.Problem
+// @Failure      404      {object}  httpErrors.Problem
+// @Failure      409      {object}  httpErrors.Problem
+// @Failure      500      {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /licenses/applications [post]
+func (h *licenseApplicationHandlers) Submit() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		req := &models.SubmitApplicationRequest{}
+		if err := c.Bind(req); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		a, err := h.appUC.SubmitApplication(ctx, req)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusCreated, a)
+	}
+}
+
+// Approve godoc
+// @Summary      Approve a license application
+// @Description  Officer only. Supersedes the current license and issues its successor, linked to it, under the given number. The expiry date defaults to the one of the class and the applicant is notified
+// @Tags         license-application
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string  true  "Application ID (UUID)"
+// @Param        request  body      models.ApproveApplicationRequest  true  "New license number and expiry date"
+// @Success      200      {object}  models.LicenseApplication
+// @Failure      400      {object}  httpErrors.Problem
+// @Failure      401      {object}  httpErrors.Problem
+// @Failure      403      {object}  httpErrors.Problem
+// @Failure      404      {object}  httpErrors.Problem
+// @Failure      409      {object}  httpErrors.Problem
+// @Failure      500      {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /licenses/applications/{id}/approve [post]
+func (h *licenseApplicationHandlers) Approve() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		applicationID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		req := &models.ApproveApplicationRequest{}
+		if err = c.Bind(req); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		a, err := h.appUC.ApproveApplication(ctx, applicationID, req)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, a)
+	}
+}
+
+// Reject godoc
+// @Summary      Reject a license application
+// @Description  Officer only. Rejects a pending application with a note, the applicant is notified
+// @Tags         license-application
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string  true  "Application ID (UUID)"
+// @Param        request  body      models.ApplicationNoteRequest  true  "Note"
+// @Success      200      {object}  models.LicenseApplication
+// @Failure      400      {object}  httpErrors.Problem
+// @Failure      401      {object}  httpErrors.Problem
+// @Failure      403      {object}  httpErrors.Problem
+// @Failure      404      {object}  httpErrors.Problem
+// @Failure      409      {object}  httpErrors.Problem
+// @Failure      500      {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /licenses/applications/{id}/reject [post]
+func (h *licenseApplicationHandlers) Reject() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		applicationID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		req := &models.ApplicationNoteRequest{}
+		if err = c.Bind(req); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		a, err := h.appUC.RejectApplication(ctx, applicationID, req)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, a)
+	}
+}
+
+// Cancel godoc
+// @Summary      Cancel a license application
+// @Description  The applicant withdraws a pending application
+// @Tags         license-application
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string  true  "Application ID (UUID)"
+// @Param        request  body      models.ApplicationNoteRequest  false  "Note"
+// @Success      200      {object}  models.LicenseApplication
+// @Failure      400      {object}  httpErrors.Problem
+// @Failure      401      {object}  httpErrors.Problem
+// @Failure      404      {object}  httpErrors.Problem
+// @Failure      409      {object}  httpErrors.Problem
+// @Failure      500      {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /licenses/applications/{id}/cancel [post]
+func (h *licenseApplicationHandlers) Cancel() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		applicationID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		req := &models.ApplicationNoteRequest{}
+		if err = c.Bind(req); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		a, err := h.appUC.CancelApplication(ctx, applicationID, req)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, a)
+	}
+}
+
+// GetByID godoc
+// @Summary      Get a license application
+// @Description  Applicants read their own applications, officers read any
+// @Tags         license-application
+// @Produce      json
+// @Param        id   path      string  true  "Application ID (UUID)"
+// @Success      200  {object}  models.LicenseApplication
+// @Failure      400  {object}  httpErrors.Problem
+// @Failure      401  {object}  httpErrors.Problem
+// @Failure      404  {object}  httpErrors.Problem
+// @Failure      500  {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /licenses/applications/{id} [get]
+func (h *licenseApplicationHandlers) GetByID() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		applicationID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		a, err := h.appUC.GetApplicationByID(ctx, applicationID)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, a)
+	}
+}
+
+// GetApplications godoc
+// @Summary      List license applications
+// @Description  Officer only. Filterable by kind, status, license_id, license_no, identity_no, license_type, requested_license_type, agency_id, reviewer_id and dates
+// @Tags         license-application
+// @Produce      json
+// @Param        page        query     int     false  "Page number (default: 1)"
+// @Param        size        query     int     false  "Page size (default: 10)"
+// @Param        sort        query     string  false  "Sort fields, comma separated, - prefix for descending, e.g. -created_at"
+// @Param        status      query     string  false  "pending, approved, rejected or cancelled"
+// @Param        agency_id   query     string  false  "Reviewing agency ID (UUID)"
+// @Param        cursor      query     string  false  "Cursor of the next page, empty for the first one, switches to cursor pagination"
+// @Param        with_total  query     bool    false  "Return an approximate total_count on cursor pages"
+// @Success      200         {object}  models.LicenseApplicationList
+// @Failure      400         {object}  httpErrors.Problem
+// @Failure      401         {object}  httpErrors.Problem
+// @Failure      403         {object}  httpErrors.Problem
+// @Failure      500         {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /licenses/applications [get]
+func (h *licenseApplicationHandlers) GetApplications() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		pq, err := utils.GetPaginationFromCtx(c)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		list, err := h.appUC.GetApplications(ctx, pq)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, list)
+	}
+}
+
+// GetMyApplications godoc
+// @Summary      List my license applications
+// @Description  Applications the current user submitted
+// @Tags         User
+// @Produce      json
+// @Param        page        query     int     false  "Page number (default: 1)"
+// @Param        size        query     int     false  "Page size (default: 10)"
+// @Param        sort        query     string  false  "Sort fields, comma separated, - prefix for descending, e.g. -created_at"
+// @Param        cursor      query     string  false  "Cursor of the next page, empty for the first one, switches to cursor pagination"
+// @Param        with_total  query     bool    false  "Return an approximate total_count on cursor pages"
+// @Success      200         {object}  models.LicenseApplicationList
+// @Failure      400         {object}  httpErrors.Problem
+// @Failure      401         {object}  httpErrors.Problem
+// @Failure      500         {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /licenses/applications/me [get]
+func (h *licenseApplicationHandlers) GetMyApplications() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		pq, err := utils.GetPaginationFromCtx(c)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		list, err := h.appUC.GetMyApplications(ctx, pq)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, list)
+	}
+}
@@ -0,0 +1,21 @@
+package http
+
+import (
+	"github.com/adohong4/driving-license/config"
+	"github.com/adohong4/driving-license/internal/auth"
+	licenseApplication "github.com/adohong4/driving-license/internal/license_application"
+	"github.com/adohong4/driving-license/internal/middleware"
+	"github.com/labstack/echo/v4"
+)
+
+var officerRoles = []string{"admin", "officer"}
+
+func MapLicenseApplicationRoutes(appGroup *echo.Group, h licenseApplication.Handlers, mw *middleware.MiddlewareManager, cfg *config.Config, authUC auth.UseCase) {
+	appGroup.POST("", h.Submit(), mw.AuthJWTMiddleware(authUC, cfg))
+	appGroup.GET("", h.GetApplications(), mw.AuthJWTMiddleware(authUC, cfg), mw.RoleBasedAuthMiddleware(officerRoles))
+	appGroup.GET("/me", h.GetMyApplications(), mw.AuthJWTMiddleware(authUC, cfg))
+	appGroup.GET("/:id", h.GetByID(), mw.AuthJWTMiddleware(authUC, cfg))
+	appGroup.POST("/:id/approve", h.Approve(), mw.AuthJWTMiddleware(authUC, cfg), mw.RoleBasedAuthMiddleware(officerRoles))
+	appGroup.POST("/:id/reject", h.Reject(), mw.AuthJWTMiddleware(authUC, cfg), mw.RoleBasedAuthMiddleware(officerRoles))
+	appGroup.POST("/:id/cancel", h.Cancel(), mw.AuthJWTMiddleware(authUC, cfg))
+}
@@ -0,0 +1,22 @@
+package licenseApplication
+
+import (
+	"context"
+
+	"github.com/adohong4/driving-license/internal/models"
+	"github.com/adohong4/driving-license/pkg/utils"
+	"github.com/google/uuid"
+)
+
+type Repository interface {
+	CreateApplication(ctx context.Context, a *models.LicenseApplication) (*models.LicenseApplication, error)
+	GetApplicationByID(ctx context.Context, applicationID uuid.UUID) (*models.LicenseApplication, error)
+	GetPendingByLicense(ctx context.Context, licenseID uuid.UUID) (*models.LicenseApplication, error)
+	CloseApplication(ctx context.Context, a *models.LicenseApplication) (*models.LicenseApplication, error)
+	ApproveApplication(ctx context.Context, a *models.LicenseApplication, license *models.DrivingLicense) (*models.LicenseApplication, *models.DrivingLicense, error)
+	GetApplications(ctx context.Context, pq *utils.PaginationQuery) (*models.LicenseApplicationList, error)
+	GetApplicationsByIdentity(ctx context.Context, identityNo string, pq *utils.PaginationQuery) (*models.LicenseApplicationList, error)
+
+	GetLicense(ctx context.Context, licenseID uuid.UUID) (*models.DrivingLicense, error)
+	LicenseNoTaken(ctx context.Context, licenseNo string) (bool, error)
+}
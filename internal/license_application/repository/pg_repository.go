@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	anchorRepository "github.com/adohong4/driving-license/internal/anchor/repository"
+	licenseApplication "github.com/adohong4/driving-license/internal/license_application"
+	"github.com/adohong4/driving-license/internal/models"
+	"github.com/adohong4/driving-license/pkg/utils"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+type licenseApplicationRepo struct {
+	db *sqlx.DB
+}
+
+func NewLicenseApplicationRepo(db *sqlx.DB) licenseApplication.Repository {
+	return &licenseApplicationRepo{db: db}
+}
+
+func (r *licenseApplicationRepo) CreateApplication(ctx context.Context, a *models.LicenseApplication) (*models.LicenseApplication, error) {
+	created := &models.LicenseApplication{}
+	if err := r.db.QueryRowxContext(ctx, createApplicationQuery,
+		a.Id, a.Kind, a.LicenseID, a.LicenseNo, a.ApplicantID, a.IdentityNo, a.FullName, a.LicenseType,
+		a.RequestedLicenseType, a.ReissueCause, a.Note, a.Documents, a.AgencyID, a.Status, a.CreatedAt, a.UpdatedAt,
+	).StructScan(created); err != nil {
+		return nil, errors.Wrap(err, "licenseApplicationRepo.CreateApplication.StructScan")
+	}
+	return created, nil
+}
+
+func (r *licenseApplicationRepo) GetApplicationByID(ctx context.Context, applicationID uuid.UUID) (*models.LicenseApplication, error) {
+	a := &models.LicenseApplication{}
+	if err := r.db.GetContext(ctx, a, getApplicationByID, applicationID); err != nil {
+		return nil, errors.Wrap(err, "licenseApplicationRepo.GetApplicationByID.GetContext")
+	}
+	return a, nil
+}
+
+// Pending application of the license, nil when there is none
+func (r *licenseApplicationRepo) GetPendingByLicense(ctx context.Context, licenseID uuid.UUID) (*models.LicenseApplication, error) {
+	a := &models.LicenseApplication{}
+	err := r.db.GetContext(ctx, a, getPendingByLicense, licenseID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "licenseApplicationRepo.GetPendingByLicense.GetContext")
+	}
+	return a, nil
+}
+
+func (r *licenseApplicationRepo) CloseApplication(ctx context.Context, a *models.LicenseApplication) (*models.LicenseApplication, error) {
+	closed := &models.LicenseApplication{}
+	if err := r.db.QueryRowxContext(ctx, closeApplicationQuery,
+		a.Status, a.ReviewNote, a.ReviewerID, a.Id,
+	).StructScan(closed); err != nil {
+		return nil, errors.Wrap(err, "licenseApplicationRepo.CloseApplication.StructScan")
+	}
+	return closed, nil
+}
+
+// Supersede the current license, issue its successor and approve the application in one transaction.
+// sql.ErrNoRows when the application is no longer pending or the license was revoked or replaced meanwhile.
+func (r *licenseApplicationRepo) ApproveApplication(ctx context.Context, a *models.LicenseApplication, l *models.DrivingLicense) (*models.LicenseApplication, *models.DrivingLicense, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "licenseApplicationRepo.ApproveApplication.BeginTxx")
+	}
+	defer tx.Rollback()
+
+	var onBlockchain bool
+	if err = tx.GetContext(ctx, &onBlockchain, supersedeLicenseQuery, a.ReviewerID, a.LicenseID); err != nil {
+		return nil, nil, errors.Wrap(err, "licenseApplicationRepo.ApproveApplication.Supersede")
+	}
+	if onBlockchain {
+		if _, err = anchorRepository.QueueReanchorTx(ctx, tx, models.AnchorLicense, a.LicenseID); err != nil {
+			return nil, nil, errors.Wrap(err, "licenseApplicationRepo.ApproveApplication")
+		}
+	}
+
+	issued := &models.DrivingLicense{}
+	if err = tx.QueryRowxContext(ctx, createSuccessorLicenseQuery,
+		l.Id, l.Name, l.Avatar, l.DOB, l.IdentityNo, l.OwnerAddress, l.OwnerCity, l.LicenseNo,
+		l.IssueDate, l.ExpiryDate, l.Status, l.LicenseType, l.AuthorityId, l.IssuingAuthority,
+		l.Nationality, l.Point, l.WalletAddress, l.OnBlockchain, l.BlockchainTxHash,
+		l.Version, l.CreatorId, l.ModifierId, l.CreatedAt, l.UpdatedAt, l.Active, l.PreviousLicense,
+	).StructScan(issued); err != nil {
+		return nil, nil, errors.Wrap(err, "licenseApplicationRepo.ApproveApplication.CreateLicense")
+	}
+
+	// a reissue keeps every class as it was, a renewal or an upgrade endorses the class from today
+	renewed := ""
+	if a.Kind != models.ApplicationReissue {
+		renewed = issued.LicenseType
+	}
+	if _, err = tx.ExecContext(ctx, copyEndorsementsQuery, issued.Id, a.LicenseID, renewed); err != nil {
+		return nil, nil, errors.Wrap(err, "licenseApplicationRepo.ApproveApplication.copyEndorsements")
+	}
+	if renewed != "" {
+		e := models.NewCardEndorsement(issued)
+		if _, err = tx.ExecContext(ctx, createEndorsementQuery,
+			e.Id, e.LicenseID, e.LicenseType, e.IssueDate, e.ExpiryDate, e.Status, e.AuthorityId, e.CreatorId,
+			e.CreatedAt, e.UpdatedAt, e.Active,
+		); err != nil {
+			return nil, nil, errors.Wrap(err, "licenseApplicationRepo.ApproveApplication.createEndorsement")
+		}
+	}
+
+	approved := &models.LicenseApplication{}
+	if err = tx.QueryRowxContext(ctx, approveApplicationQuery,
+		a.ReviewNote, a.ReviewerID, issued.Id, a.Id,
+	).StructScan(approved); err != nil {
+		return nil, nil, errors.Wrap(err, "licenseApplicationRepo.ApproveApplication.StructScan")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, nil, errors.Wrap(err, "licenseApplicationRepo.ApproveApplication.Commit")
+	}
+	return approved, issued, nil
+}
+
+func (r *licenseApplicationRepo) GetApplications(ctx context.Context, pq *utils.PaginationQuery) (*models.LicenseApplicationList, error) {
+	return r.list(ctx, pq, getTotalApplications, getApplications)
+}
+
+// Applications the citizen submitted
+func (r *licenseApplicationRepo) GetApplicationsByIdentity(ctx context.Context, identityNo string, pq *utils.PaginationQuery) (*models.LicenseApplicationList, error) {
+	return r.list(ctx, pq, getTotalApplicationsByIdentity, getApplicationsByIdentity, identityNo)
+}
+
+func (r *licenseApplicationRepo) list(ctx context.Context, pq *utils.PaginationQuery, countQuery, listQuery string, base ...interface{}) (*models.LicenseApplicationList, error) {
+	lc, err := pq.ListClause(applicationListSpec, len(base))
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := lc.Total(ctx, r.db, countQuery, listQuery, base...)
+	if err != nil {
+		return nil, errors.Wrap(err, "licenseApplicationRepo.list.total")
+	}
+
+	list := &models.LicenseApplicationList{
+		TotalCount:   total,
+		TotalPages:   utils.GetTotalPage(total, pq.GetSize()),
+		Page:         pq.GetPage(),
+		Size:         pq.GetSize(),
+		HasMore:      utils.GetHasMore(pq.GetPage(), total, pq.GetSize()),
+		Applications: []*models.LicenseApplication{},
+	}
+
+	if lc.Empty(total) {
+		return list, nil
+	}
+
+	var items []*models.LicenseApplication
+	if err := r.db.SelectContext(ctx, &items, lc.Page(listQuery), lc.PageArgs(pq, base...)...); err != nil {
+		return nil, errors.Wrap(err, "licenseApplicationRepo.list.Select")
+	}
+
+	if list.Applications, list.NextCursor, err = utils.NextCursor(lc, items); err != nil {
+		return nil, errors.Wrap(err, "licenseApplicationRepo.list.NextCursor")
+	}
+	list.HasMore = lc.HasMore(pq, total, list.NextCursor)
+	return list, nil
+}
+
+func (r *licenseApplicationRepo) GetLicense(ctx context.Context, licenseID uuid.UUID) (*models.DrivingLicense, error) {
+	l := &models.DrivingLicense{}
+	if err := r.db.GetContext(ctx, l, getLicenseByID, licenseID); err != nil {
+		return nil, errors.Wrap(err, "licenseApplicationRepo.GetLicense.GetContext")
+	}
+	return l, nil
+}
+
+// Whether an active license holds the number
+func (r *licenseApplicationRepo) LicenseNoTaken(ctx context.Context, licenseNo string) (bool, error) {
+	var taken bool
+	if err := r.db.GetContext(ctx, &taken, licenseNoTaken, licenseNo); err != nil {
+		return false, errors.Wrap(err, "licenseApplicationRepo.LicenseNoTaken.GetContext")
+	}
+	return taken, nil
+}
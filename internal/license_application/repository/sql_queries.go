@@ -0,0 +1,144 @@
+package repository
+
+import "github.com/adohong4/driving-license/pkg/utils"
+
+// Filters and sorts accepted by the application lists, filter, sort and cursor clauses are appended to the queries below
+var applicationListSpec = &utils.ListSpec{
+	Fields: map[string]utils.ListField{
+		"kind":                   {Column: "la.kind", Type: utils.FilterExact, Sortable: true, Keyset: true},
+		"status":                 {Column: "la.status", Type: utils.FilterExact, Sortable: true, Keyset: true},
+		"license_id":             {Column: "la.license_id", Type: utils.FilterUUID},
+		"license_no":             {Column: "la.license_no", Type: utils.FilterText, Sortable: true, Keyset: true},
+		"identity_no":            {Column: "la.identity_no", Type: utils.FilterExact},
+		"license_type":           {Column: "la.license_type", Type: utils.FilterExact, Sortable: true, Keyset: true},
+		"requested_license_type": {Column: "la.requested_license_type", Type: utils.FilterExact},
+		"agency_id":              {Column: "la.agency_id", Type: utils.FilterUUID},
+		"reviewer_id":            {Column: "la.reviewer_id", Type: utils.FilterUUID},
+		"decided_at":             {Column: "la.decided_at", Type: utils.FilterDate, Sortable: true},
+		"created_at":             {Column: "la.created_at", Type: utils.FilterDate, Sortable: true, Keyset: true},
+		"updated_at":             {Column: "la.updated_at", Type: utils.FilterDate, Sortable: true, Keyset: true},
+	},
+	DefaultSort: "-updated_at,-created_at",
+	IDColumn:    "la.id",
+}
+
+const (
+	createApplicationQuery = `
+    INSERT INTO license_applications (
+        id, kind, license_id, license_no, applicant_id, identity_no, full_name, license_type,
+        requested_license_type, reissue_cause, note, documents, agency_id, status, created_at, updated_at
+    ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+    RETURNING *
+    `
+
+	getApplicationByID = `
+    SELECT * FROM license_applications WHERE id = $1
+    `
+
+	getPendingByLicense = `
+    SELECT * FROM license_applications
+    WHERE license_id = $1 AND status = 'pending'
+    `
+
+	// rejection by an officer or cancellation by the applicant, only pending applications are closed
+	closeApplicationQuery = `
+    UPDATE license_applications
+    SET status = $1,
+        review_note = $2,
+        reviewer_id = $3,
+        decided_at = now(),
+        updated_at = now()
+    WHERE id = $4 AND status = 'pending'
+    RETURNING *
+    `
+
+	// the replaced license stays active so vehicles owned through it keep their owner,
+	// returns whether it was anchored since its anchor no longer matches the new status
+	supersedeLicenseQuery = `
+    UPDATE driver_licenses
+    SET status = 'superseded',
+        modifier_id = $1,
+        version = version + 1,
+        updated_at = now()
+    WHERE id = $2
+      AND active = true
+      AND status NOT IN ('revoke', 'revoked', 'superseded')
+    RETURNING on_blockchain
+    `
+
+	createSuccessorLicenseQuery = `
+    INSERT INTO driver_licenses (
+        id, full_name, avatar, dob, identity_no, owner_address, owner_city, license_no,
+        issue_date, expiry_date, status, license_type, authority_id, issuing_authority,
+        nationality, point, wallet_address, on_blockchain, blockchain_txhash,
+        version, creator_id, modifier_id, created_at, updated_at, active, previous_license_id
+    ) VALUES (
+        $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14,
+        $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26
+    )
+    RETURNING *
+    `
+
+	// the successor keeps the classes of the replaced card, but the one the application renews or adds
+	copyEndorsementsQuery = `
+    INSERT INTO license_endorsements (
+        id, license_id, license_type, issue_date, expiry_date, status, authority_id, creator_id, created_at, updated_at, active
+    )
+    SELECT md5($1::text || le.id::text)::uuid, $1, le.license_type, le.issue_date, le.expiry_date, le.status,
+           le.authority_id, le.creator_id, now(), now(), true
+    FROM license_endorsements le
+    WHERE le.license_id = $2 AND le.active = true AND le.license_type <> $3
+    `
+
+	createEndorsementQuery = `
+    INSERT INTO license_endorsements (
+        id, license_id, license_type, issue_date, expiry_date, status, authority_id, creator_id, created_at, updated_at, active
+    ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+    `
+
+	approveApplicationQuery = `
+    UPDATE license_applications
+    SET status = 'approved',
+        review_note = $1,
+        reviewer_id = $2,
+        new_license_id = $3,
+        decided_at = now(),
+        updated_at = now()
+    WHERE id = $4 AND status = 'pending'
+    RETURNING *
+    `
+
+	getLicenseByID = `
+    SELECT * FROM driver_licenses WHERE id = $1 AND active = true
+    `
+
+	licenseNoTaken = `
+    SELECT EXISTS (
+        SELECT 1 FROM driver_licenses WHERE license_no = $1 AND active = true
+    )
+    `
+
+	getApplications = `
+    SELECT la.*
+    FROM license_applications la
+    WHERE 1 = 1
+    `
+
+	getTotalApplications = `
+    SELECT COUNT(*)
+    FROM license_applications la
+    WHERE 1 = 1
+    `
+
+	getApplicationsByIdentity = `
+    SELECT la.*
+    FROM license_applications la
+    WHERE la.identity_no = $1
+    `
+
+	getTotalApplicationsByIdentity = `
+    SELECT COUNT(*)
+    FROM license_applications la
+    WHERE la.identity_no = $1
+    `
+)
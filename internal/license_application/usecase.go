@@ -0,0 +1,19 @@
+package licenseApplication
+
+import (
+	"context"
+
+	"github.com/adohong4/driving-license/internal/models"
+	"github.com/adohong4/driving-license/pkg/utils"
+	"github.com/google/uuid"
+)
+
+type UseCase interface {
+	SubmitApplication(ctx context.Context, req *models.SubmitApplicationRequest) (*models.LicenseApplication, error)
+	ApproveApplication(ctx context.Context, applicationID uuid.UUID, req *models.ApproveApplicationRequest) (*models.LicenseApplication, error)
+	RejectApplication(ctx context.Context, applicationID uuid.UUID, req *models.ApplicationNoteRequest) (*models.LicenseApplication, error)
+	CancelApplication(ctx context.Context, applicationID uuid.UUID, req *models.ApplicationNoteRequest) (*models.LicenseApplication, error)
+	GetApplicationByID(ctx context.Context, applicationID uuid.UUID) (*models.LicenseApplication, error)
+	GetApplications(ctx context.Context, pq *utils.PaginationQuery) (*models.LicenseApplicationList, error)
+	GetMyApplications(ctx context.Context, pq *utils.PaginationQuery) (*models.LicenseApplicationList, error)
+}
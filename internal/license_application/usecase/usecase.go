@@ -0,0 +1,383 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/adohong4/driving-license/config"
+	licenseApplication "github.com/adohong4/driving-license/internal/license_application"
+	"github.com/adohong4/driving-license/internal/models"
+	"github.com/adohong4/driving-license/internal/notification"
+	"github.com/adohong4/driving-license/internal/stats"
+	"github.com/adohong4/driving-license/pkg/httpErrors"
+	"github.com/adohong4/driving-license/pkg/logger"
+	"github.com/adohong4/driving-license/pkg/utils"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// Notification codes of the review, rendered from the i18n catalogs
+const (
+	notiApplicationApproved = "license_application_approved"
+	notiApplicationRejected = "license_application_rejected"
+
+	notiTypeApplication = "license_application"
+)
+
+// Days before the expiry date a license can be renewed
+const renewalWindowDays = 90
+
+// Roles allowed to review applications and to read every application
+var officerRoles = map[string]bool{"admin": true, "officer": true}
+
+// Statuses of licenses that can no longer be renewed, upgraded or reissued
+var closedLicenseStatuses = map[string]bool{"revoke": true, "revoked": true, models.LicenseSuperseded: true}
+
+type licenseApplicationUC struct {
+	cfg     *config.Config
+	appRepo licenseApplication.Repository
+	statsUC stats.UseCase
+	notiUC  notification.UseCase
+	logger  logger.Logger
+}
+
+func NewLicenseApplicationUseCase(cfg *config.Config, appRepo licenseApplication.Repository, statsUC stats.UseCase, notiUC notification.UseCase, logger logger.Logger) licenseApplication.UseCase {
+	return &licenseApplicationUC{cfg: cfg, appRepo: appRepo, statsUC: statsUC, notiUC: notiUC, logger: logger}
+}
+
+// The holder of a license applies to renew, upgrade or reissue it, the issuing agency reviews the application
+func (u *licenseApplicationUC) SubmitApplication(ctx context.Context, req *models.SubmitApplicationRequest) (*models.LicenseApplication, error) {
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return nil, httpErrors.NewUnauthorizedError(errors.WithMessage(err, "licenseApplicationUC.SubmitApplication.GetUserFromCtx"))
+	}
+	if err = utils.ValidateStruct(ctx, req); err != nil {
+		return nil, httpErrors.NewBadRequestError(errors.WithMessage(err, "licenseApplicationUC.SubmitApplication.ValidateStruct"))
+	}
+	if user.IdentityNo == "" {
+		return nil, httpErrors.NewBadRequestError("user identity number is missing")
+	}
+
+	license, err := u.appRepo.GetLicense(ctx, req.LicenseID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, httpErrors.NewRestError(http.StatusNotFound, "license not found", nil)
+		}
+		return nil, err
+	}
+	if license.IdentityNo != user.IdentityNo {
+		return nil, httpErrors.NewRestError(http.StatusNotFound, "license not found", nil)
+	}
+	if closedLicenseStatuses[strings.ToLower(license.Status)] {
+		return nil, httpErrors.NewRestError(http.StatusConflict, fmt.Sprintf("the license is %s", license.Status), nil)
+	}
+
+	pending, err := u.appRepo.GetPendingByLicense(ctx, license.Id)
+	if err != nil {
+		return nil, err
+	}
+	if pending != nil {
+		return nil, httpErrors.NewRestError(http.StatusConflict, fmt.Sprintf("the license already has a pending application %s", pending.Id), nil)
+	}
+
+	a := &models.LicenseApplication{
+		Kind:        req.Kind,
+		LicenseID:   license.Id,
+		LicenseNo:   license.LicenseNo,
+		ApplicantID: user.Id,
+		IdentityNo:  user.IdentityNo,
+		FullName:    user.FullName,
+		LicenseType: license.LicenseType,
+		Note:        req.Note,
+		Documents:   req.Documents,
+		AgencyID:    license.AuthorityId,
+	}
+
+	switch req.Kind {
+	case models.ApplicationRenewal:
+		if err = checkRenewable(license, time.Now()); err != nil {
+			return nil, err
+		}
+	case models.ApplicationUpgrade:
+		if err = checkUpgrade(license, req.RequestedLicenseType, user.DateOfBirth); err != nil {
+			return nil, err
+		}
+		a.RequestedLicenseType = req.RequestedLicenseType
+	case models.ApplicationReissue:
+		a.ReissueCause = req.ReissueCause
+	}
+
+	if err = a.PrepareCreate(); err != nil {
+		return nil, httpErrors.NewBadRequestError(errors.Wrap(err, "licenseApplicationUC.SubmitApplication.PrepareCreate"))
+	}
+	if missing := a.MissingDocuments(); len(missing) > 0 {
+		return nil, httpErrors.NewBadRequestError(fmt.Sprintf("missing documents: %s", strings.Join(missing, ", ")))
+	}
+
+	return u.appRepo.CreateApplication(ctx, a)
+}
+
+// An officer approves a pending application. The current license is superseded and its successor, linked to it,
+// is issued today under the given number with the requested class, the points of the holder carry over.
+func (u *licenseApplicationUC) ApproveApplication(ctx context.Context, applicationID uuid.UUID, req *models.ApproveApplicationRequest) (*models.LicenseApplication, error) {
+	user, err := u.officerFromCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = utils.ValidateStruct(ctx, req); err != nil {
+		return nil, httpErrors.NewBadRequestError(errors.WithMessage(err, "licenseApplicationUC.ApproveApplication.ValidateStruct"))
+	}
+
+	a, err := u.appRepo.GetApplicationByID(ctx, applicationID)
+	if err != nil {
+		return nil, err
+	}
+	if a.Status != models.ApplicationPending {
+		return nil, applicationStateError(a)
+	}
+
+	current, err := u.appRepo.GetLicense(ctx, a.LicenseID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, httpErrors.NewRestError(http.StatusConflict, "the license of the application no longer exists", nil)
+		}
+		return nil, err
+	}
+
+	licenseNo := strings.TrimSpace(req.LicenseNo)
+	taken, err := u.appRepo.LicenseNoTaken(ctx, licenseNo)
+	if err != nil {
+		return nil, err
+	}
+	if taken {
+		return nil, httpErrors.NewRestErrorWithMessage(http.StatusBadRequest, httpErrors.ErrLicenseAlreadyExists, nil)
+	}
+
+	now := time.Now()
+	successor := *current
+	if err = successor.PrepareCreate(); err != nil {
+		return nil, httpErrors.NewBadRequestError(errors.Wrap(err, "licenseApplicationUC.ApproveApplication.PrepareCreate"))
+	}
+	successor.LicenseNo = licenseNo
+	successor.LicenseType = a.RequestedLicenseType
+	successor.IssueDate = now.Format(time.DateOnly)
+	successor.ExpiryDate = models.LicenseExpiry(successor.LicenseType, now)
+	if req.ExpiryDate != "" {
+		successor.ExpiryDate = &req.ExpiryDate
+	}
+	successor.Status = "active"
+	successor.Point = current.Point
+	successor.CreatorId = user.Id
+	successor.ModifierId = nil
+	successor.PreviousLicense = &current.Id
+
+	a.ReviewerID = &user.Id
+	a.ReviewNote = strings.TrimSpace(req.Note)
+
+	approved, issued, err := u.appRepo.ApproveApplication(ctx, a, &successor)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, httpErrors.NewRestError(http.StatusConflict,
+				"the application is no longer pending or the license was revoked or replaced", nil)
+		}
+		return nil, err
+	}
+	u.statsUC.MarkDirty(stats.DomainLicenses)
+
+	u.notify(ctx, notiApplicationApproved, approved, issued)
+	return approved, nil
+}
+
+// An officer rejects a pending application with a note
+func (u *licenseApplicationUC) RejectApplication(ctx context.Context, applicationID uuid.UUID, req *models.ApplicationNoteRequest) (*models.LicenseApplication, error) {
+	user, err := u.officerFromCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = utils.ValidateStruct(ctx, req); err != nil {
+		return nil, httpErrors.NewBadRequestError(errors.WithMessage(err, "licenseApplicationUC.RejectApplication.ValidateStruct"))
+	}
+	if strings.TrimSpace(req.Note) == "" {
+		return nil, httpErrors.NewBadRequestError("a note is required to reject an application")
+	}
+
+	a, err := u.appRepo.GetApplicationByID(ctx, applicationID)
+	if err != nil {
+		return nil, err
+	}
+	if a.Status != models.ApplicationPending {
+		return nil, applicationStateError(a)
+	}
+	a.Status = models.ApplicationRejected
+	a.ReviewNote = strings.TrimSpace(req.Note)
+	a.ReviewerID = &user.Id
+
+	rejected, err := u.closeApplication(ctx, a)
+	if err != nil {
+		return nil, err
+	}
+
+	u.notify(ctx, notiApplicationRejected, rejected, nil)
+	return rejected, nil
+}
+
+// The applicant withdraws a pending application
+func (u *licenseApplicationUC) CancelApplication(ctx context.Context, applicationID uuid.UUID, req *models.ApplicationNoteRequest) (*models.LicenseApplication, error) {
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return nil, httpErrors.NewUnauthorizedError(errors.WithMessage(err, "licenseApplicationUC.CancelApplication.GetUserFromCtx"))
+	}
+	if err = utils.ValidateStruct(ctx, req); err != nil {
+		return nil, httpErrors.NewBadRequestError(errors.WithMessage(err, "licenseApplicationUC.CancelApplication.ValidateStruct"))
+	}
+
+	a, err := u.appRepo.GetApplicationByID(ctx, applicationID)
+	if err != nil {
+		return nil, err
+	}
+	if a.ApplicantID != user.Id {
+		return nil, httpErrors.NewRestError(http.StatusNotFound, "application not found", nil)
+	}
+	if a.Status != models.ApplicationPending {
+		return nil, applicationStateError(a)
+	}
+	a.Status = models.ApplicationCancelled
+	a.ReviewNote = strings.TrimSpace(req.Note)
+
+	return u.closeApplication(ctx, a)
+}
+
+// Application of the applicant, officers read any application
+func (u *licenseApplicationUC) GetApplicationByID(ctx context.Context, applicationID uuid.UUID) (*models.LicenseApplication, error) {
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return nil, httpErrors.NewUnauthorizedError(errors.WithMessage(err, "licenseApplicationUC.GetApplicationByID.GetUserFromCtx"))
+	}
+
+	a, err := u.appRepo.GetApplicationByID(ctx, applicationID)
+	if err != nil {
+		return nil, err
+	}
+	if !isOfficer(user) && a.ApplicantID != user.Id {
+		return nil, httpErrors.NewRestError(http.StatusNotFound, "application not found", nil)
+	}
+	return a, nil
+}
+
+func (u *licenseApplicationUC) GetApplications(ctx context.Context, pq *utils.PaginationQuery) (*models.LicenseApplicationList, error) {
+	if _, err := u.officerFromCtx(ctx); err != nil {
+		return nil, err
+	}
+	return u.appRepo.GetApplications(ctx, pq)
+}
+
+// Applications the current user submitted
+func (u *licenseApplicationUC) GetMyApplications(ctx context.Context, pq *utils.PaginationQuery) (*models.LicenseApplicationList, error) {
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return nil, httpErrors.NewUnauthorizedError(err)
+	}
+	if user.IdentityNo == "" {
+		return nil, httpErrors.NewBadRequestError("user identity number is missing")
+	}
+	return u.appRepo.GetApplicationsByIdentity(ctx, user.IdentityNo, pq)
+}
+
+func (u *licenseApplicationUC) closeApplication(ctx context.Context, a *models.LicenseApplication) (*models.LicenseApplication, error) {
+	closed, err := u.appRepo.CloseApplication(ctx, a)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, httpErrors.NewRestError(http.StatusConflict, "the application is no longer pending", nil)
+		}
+		return nil, err
+	}
+	return closed, nil
+}
+
+func (u *licenseApplicationUC) officerFromCtx(ctx context.Context) (*models.User, error) {
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return nil, httpErrors.NewUnauthorizedError(err)
+	}
+	if !isOfficer(user) {
+		return nil, httpErrors.NewForbiddenError(httpErrors.PermissionDenied)
+	}
+	return user, nil
+}
+
+// Tell the applicant about the review, the application is already saved so a failure is only logged
+func (u *licenseApplicationUC) notify(ctx context.Context, code string, a *models.LicenseApplication, issued *models.DrivingLicense) {
+	params := models.NotificationParams{
+		"application_id": a.Id.String(),
+		"kind":           a.Kind,
+		"license_no":     a.LicenseNo,
+		"license_type":   a.RequestedLicenseType,
+		"note":           a.ReviewNote,
+	}
+	if issued != nil {
+		params["new_license_id"] = issued.Id.String()
+		params["new_license_no"] = issued.LicenseNo
+	}
+	n := &models.Notification{
+		Code:       code,
+		Type:       notiTypeApplication,
+		Target:     "personal",
+		TargetUser: a.IdentityNo,
+		Status:     "unread",
+		Params:     params,
+	}
+	if _, err := u.notiUC.CreateNotification(ctx, n); err != nil {
+		u.logger.Errorf("licenseApplicationUC.notify %s %s: %v", code, a.Id, err)
+	}
+}
+
+// Licenses are renewed from the renewal window before their expiry date on, classes without one are never renewed
+func checkRenewable(l *models.DrivingLicense, now time.Time) error {
+	if l.ExpiryDate == nil || len(*l.ExpiryDate) < len(time.DateOnly) {
+		return httpErrors.NewBadRequestError("the license does not expire and needs no renewal")
+	}
+	expiry, err := time.Parse(time.DateOnly, (*l.ExpiryDate)[:len(time.DateOnly)])
+	if err != nil {
+		return httpErrors.NewBadRequestError(errors.Wrap(err, "licenseApplicationUC.checkRenewable.Parse"))
+	}
+	if now.AddDate(0, 0, renewalWindowDays).Before(expiry) {
+		return httpErrors.NewBadRequestError(fmt.Sprintf("the license can be renewed from %d days before it expires on %s",
+			renewalWindowDays, expiry.Format(time.DateOnly)))
+	}
+	return nil
+}
+
+// The requested class must follow the current one and the holder must be old enough to drive it
+func checkUpgrade(l *models.DrivingLicense, requested, userDOB string) error {
+	if !models.CanUpgrade(l.LicenseType, requested) {
+		return httpErrors.NewBadRequestError(fmt.Sprintf("a %s license cannot be upgraded to %s", l.LicenseType, requested))
+	}
+
+	dob := strings.TrimSpace(userDOB)
+	if dob == "" {
+		dob = strings.TrimSpace(l.DOB)
+	}
+	if len(dob) < len(time.DateOnly) {
+		return nil
+	}
+	born, err := time.Parse(time.DateOnly, dob[:len(time.DateOnly)])
+	if err != nil {
+		return nil
+	}
+	if age, ok := utils.DrivingAge(requested); ok && born.AddDate(age, 0, 0).After(time.Now()) {
+		return httpErrors.NewBadRequestError(fmt.Sprintf("the holder must be at least %d to drive class %s", age, requested))
+	}
+	return nil
+}
+
+func isOfficer(user *models.User) bool {
+	return user.Role != nil && officerRoles[*user.Role]
+}
+
+func applicationStateError(a *models.LicenseApplication) error {
+	return httpErrors.NewRestError(http.StatusConflict, fmt.Sprintf("the application is %s", a.Status), nil)
+}
@@ -42,6 +42,28 @@ func (mw *MiddlewareManager) AuthJWTMiddleware(authUC auth.UseCase, cfg *config.
 	}
 }
 
+// OptionalAuthJWTMiddleware sets the user of a valid bearer token in context and lets anonymous requests through.
+// Only for public aggregates: anonymous callers get national figures, agency staff the figures of their jurisdiction.
+// Routes reading records use AuthJWTMiddleware so that dropping the token does not lift the jurisdiction
+func (mw *MiddlewareManager) OptionalAuthJWTMiddleware(authUC auth.UseCase, cfg *config.Config) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			bearerHeader := c.Request().Header.Get("Authorization")
+			if !strings.HasPrefix(bearerHeader, "Bearer ") {
+				return next(c)
+			}
+
+			tokenString := strings.TrimPrefix(bearerHeader, "Bearer ")
+			if err := mw.validateJWTToken(tokenString, authUC, c, cfg); err != nil {
+				mw.logger.Errorf("OptionalAuthJWTMiddleware RequestID: %s, Error: %s", utils.GetRequestId(c), err.Error())
+				return c.JSON(http.StatusUnauthorized, httpErrors.NewUnauthorizedError(err.Error()))
+			}
+
+			return next(c)
+		}
+	}
+}
+
 // AdminMiddleware checks if user is admin
 func (mw *MiddlewareManager) AdminMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"net/http"
+
+	govagency "github.com/adohong4/driving-license/internal/gov_agency"
+	"github.com/adohong4/driving-license/internal/models"
+	"github.com/adohong4/driving-license/pkg/utils"
+	"github.com/labstack/echo/v4"
+)
+
+// StaffActionMiddleware keeps the successful writes of agency staff, the staff member acting for the agency.
+// The user is set by the auth middleware of the route, reads and failed requests are not kept
+func (mw *MiddlewareManager) StaffActionMiddleware(agencyUC govagency.UseCase) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			err := next(c)
+
+			req := c.Request()
+			switch req.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				return err
+			}
+			status := c.Response().Status
+			if err != nil || status >= http.StatusBadRequest {
+				return err
+			}
+			user, ok := c.Get("user").(*models.User)
+			if !ok || user.AgencyID == nil {
+				return err
+			}
+
+			if recErr := agencyUC.RecordStaffAction(req.Context(), &models.StaffAction{
+				UserID:     user.Id,
+				Action:     req.Method + " " + c.Path(),
+				ResourceID: resourceID(c),
+				Status:     status,
+				RequestID:  utils.GetRequestId(c),
+			}); recErr != nil {
+				mw.logger.Errorf("StaffActionMiddleware RequestID: %s, UserID: %s, Error: %s", utils.GetRequestId(c), user.Id.String(), recErr.Error())
+			}
+			return err
+		}
+	}
+}
+
+// The id path param of the route, or its first param
+func resourceID(c echo.Context) string {
+	if id := c.Param("id"); id != "" {
+		return id
+	}
+	if values := c.ParamValues(); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
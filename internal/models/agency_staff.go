@@ -0,0 +1,111 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Roles of the staff within an agency, heads and managers manage the staff of their agency
+const (
+	StaffRoleHead    = "head"
+	StaffRoleManager = "manager"
+	StaffRoleClerk   = "clerk"
+)
+
+// Membership statuses, invited > active > inactive or invited > declined
+const (
+	StaffInvited  = "invited"
+	StaffActive   = "active"
+	StaffDeclined = "declined"
+	StaffInactive = "inactive"
+)
+
+// Member of the staff of an agency
+type AgencyStaff struct {
+	Id            uuid.UUID  `json:"id" db:"id"`
+	AgencyID      uuid.UUID  `json:"agency_id" db:"agency_id"`
+	UserID        uuid.UUID  `json:"user_id" db:"user_id"`
+	Position      string     `json:"position" db:"position"` // Chức vụ
+	Role          string     `json:"role" db:"role"`         // head, manager, clerk
+	Status        string     `json:"status" db:"status"`     // invited, active, declined, inactive
+	InviterID     uuid.UUID  `json:"inviter_id" db:"inviter_id"`
+	InvitedAt     time.Time  `json:"invited_at" db:"invited_at"`
+	JoinedAt      *time.Time `json:"joined_at" db:"joined_at"`
+	DeactivatedBy *uuid.UUID `json:"deactivated_by" db:"deactivated_by"`
+	DeactivatedAt *time.Time `json:"deactivated_at" db:"deactivated_at"`
+	Version       int        `json:"version" db:"version"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+
+	// Read from the user and the agency
+	FullName    string  `json:"full_name" db:"full_name"`
+	IdentityNo  string  `json:"identity_no" db:"identity_no"`
+	UserAddress *string `json:"user_address" db:"user_address"`
+	AgencyName  string  `json:"agency_name" db:"agency_name"`
+}
+
+// Whether the member manages the staff of the agency
+func (s *AgencyStaff) ManagesStaff() bool {
+	return s.Status == StaffActive && (s.Role == StaffRoleHead || s.Role == StaffRoleManager)
+}
+
+// Invite a user to the staff of an agency
+type InviteStaffRequest struct {
+	UserID   uuid.UUID `json:"user_id" validate:"required"`
+	Position string    `json:"position" validate:"lte=100"`
+	Role     string    `json:"role" validate:"omitempty,oneof=head manager clerk"`
+}
+
+func (r *InviteStaffRequest) Prepare() {
+	r.Position = strings.TrimSpace(r.Position)
+	if r.Role == "" {
+		r.Role = StaffRoleClerk
+	}
+}
+
+// Change the position or the role of a member, empty fields are kept
+type UpdateStaffRequest struct {
+	Position string `json:"position" validate:"lte=100"`
+	Role     string `json:"role" validate:"omitempty,oneof=head manager clerk"`
+}
+
+// All Agency Staff response
+type AgencyStaffList struct {
+	TotalCount int            `json:"total_count"`
+	TotalPages int            `json:"total_pages"`
+	Page       int            `json:"page"`
+	Size       int            `json:"size"`
+	HasMore    bool           `json:"has_more"`
+	Staff      []*AgencyStaff `json:"staff"`
+	NextCursor string         `json:"next_cursor,omitempty"` // cursor of the next page, set on cursor pages
+}
+
+// Write made by a staff member on behalf of the agency
+type StaffAction struct {
+	Id         uuid.UUID `json:"id" db:"id"`
+	AgencyID   uuid.UUID `json:"agency_id" db:"agency_id"`
+	StaffID    uuid.UUID `json:"staff_id" db:"staff_id"`
+	UserID     uuid.UUID `json:"user_id" db:"user_id"`
+	Action     string    `json:"action" db:"action"` // method and route
+	ResourceID string    `json:"resource_id" db:"resource_id"`
+	Status     int       `json:"status" db:"status"` // response status
+	RequestID  string    `json:"request_id" db:"request_id"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+
+	// Read from the staff member
+	FullName string `json:"full_name" db:"full_name"`
+	Position string `json:"position" db:"position"`
+}
+
+// All Staff Action response
+type StaffActionList struct {
+	TotalCount int            `json:"total_count"`
+	TotalPages int            `json:"total_pages"`
+	Page       int            `json:"page"`
+	Size       int            `json:"size"`
+	HasMore    bool           `json:"has_more"`
+	Actions    []*StaffAction `json:"actions"`
+	NextCursor string         `json:"next_cursor,omitempty"` // cursor of the next page, set on cursor pages
+}
@@ -0,0 +1,260 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/adohong4/driving-license/pkg/i18n"
+	"github.com/google/uuid"
+)
+
+// Kinds of services booked at agencies
+const (
+	ServiceInspection    = "inspection"
+	ServiceLicensePickup = "license_pickup"
+	ServiceRegistration  = "registration"
+)
+
+// Slot statuses, closed slots take no more bookings
+const (
+	SlotOpen   = "open"
+	SlotClosed = "closed"
+)
+
+// Appointment statuses, a booked appointment ends checked in or cancelled
+const (
+	AppointmentBooked    = "booked"
+	AppointmentCancelled = "cancelled"
+	AppointmentCheckedIn = "checked_in"
+)
+
+// Days of calendar published at once
+const maxCalendarDays = 92
+
+// Service an agency books appointments for
+type AgencyService struct {
+	Id              uuid.UUID `json:"id" db:"id"`
+	AgencyID        uuid.UUID `json:"agency_id" db:"agency_id"`
+	Kind            string    `json:"kind" db:"kind"`                         // inspection, license_pickup, registration
+	Name            string    `json:"name" db:"name"`                         // Tên dịch vụ
+	DurationMinutes int       `json:"duration_minutes" db:"duration_minutes"` // Thời lượng mỗi lượt
+	CreatorId       uuid.UUID `json:"creator_id" db:"creator_id"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
+	Active          bool      `json:"active" db:"active"`
+	KindLabel       string    `json:"kind_label,omitempty" db:"-"` // Loại dịch vụ theo ngôn ngữ yêu cầu
+}
+
+func (s *AgencyService) Localize(lang string) {
+	s.KindLabel = i18n.Label(lang, i18n.ServiceKind, s.Kind)
+}
+
+// Services of an agency
+type AgencyServiceList struct {
+	Services []*AgencyService `json:"services"`
+}
+
+func (l *AgencyServiceList) Localize(lang string) {
+	for _, s := range l.Services {
+		s.Localize(lang)
+	}
+}
+
+// Officer's service of an agency
+type CreateServiceRequest struct {
+	AgencyID        uuid.UUID `json:"agency_id" validate:"required"`
+	Kind            string    `json:"kind" validate:"required,oneof=inspection license_pickup registration"`
+	Name            string    `json:"name" validate:"required,max=255"`
+	DurationMinutes int       `json:"duration_minutes" validate:"required,min=5,max=480"`
+}
+
+// Time slot of a service
+type AppointmentSlot struct {
+	Id        uuid.UUID `json:"id" db:"id"`
+	ServiceID uuid.UUID `json:"service_id" db:"service_id"`
+	AgencyID  uuid.UUID `json:"agency_id" db:"agency_id"`
+	StartsAt  time.Time `json:"starts_at" db:"starts_at"`
+	EndsAt    time.Time `json:"ends_at" db:"ends_at"`
+	Capacity  int       `json:"capacity" db:"capacity"` // Số lượt
+	Booked    int       `json:"booked" db:"booked"`     // Số lượt đã đặt
+	Status    string    `json:"status" db:"status"`     // open, closed
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Whether the slot still takes bookings at the time
+func (s *AppointmentSlot) Bookable(now time.Time) bool {
+	return s.Status == SlotOpen && s.StartsAt.After(now) && s.Booked < s.Capacity
+}
+
+// Slots of a service
+type AppointmentSlotList struct {
+	Slots []*AppointmentSlot `json:"slots"`
+}
+
+// Officer's calendar of slots of a service, opening hours split into slots of the service duration on the weekdays
+// of the date range. Weekdays count from Sunday (0), Monday to Friday when empty.
+type PublishSlotsRequest struct {
+	From     string `json:"from" validate:"required,isodate"`
+	To       string `json:"to" validate:"required,isodate"`
+	Weekdays []int  `json:"weekdays" validate:"omitempty,dive,min=0,max=6"`
+	OpensAt  string `json:"opens_at" validate:"required,datetime=15:04"`
+	ClosesAt string `json:"closes_at" validate:"required,datetime=15:04"`
+	Capacity int    `json:"capacity" validate:"required,min=1,max=500"`
+}
+
+// Slots of the calendar starting after now, in the local time of the server
+func (r *PublishSlotsRequest) Slots(s *AgencyService, now time.Time) ([]*AppointmentSlot, error) {
+	from, err := time.ParseInLocation(time.DateOnly, r.From, time.Local)
+	if err != nil {
+		return nil, err
+	}
+	to, err := time.ParseInLocation(time.DateOnly, r.To, time.Local)
+	if err != nil {
+		return nil, err
+	}
+	if to.Before(from) {
+		return nil, errors.New("the calendar ends before it starts")
+	}
+	if to.Sub(from) > maxCalendarDays*24*time.Hour {
+		return nil, fmt.Errorf("a calendar spans at most %d days", maxCalendarDays)
+	}
+	opens, err := time.Parse("15:04", r.OpensAt)
+	if err != nil {
+		return nil, err
+	}
+	closes, err := time.Parse("15:04", r.ClosesAt)
+	if err != nil {
+		return nil, err
+	}
+	if !closes.After(opens) {
+		return nil, errors.New("the office closes before it opens")
+	}
+
+	weekdays := map[time.Weekday]bool{}
+	for _, d := range r.Weekdays {
+		weekdays[time.Weekday(d)] = true
+	}
+	if len(weekdays) == 0 {
+		for d := time.Monday; d <= time.Friday; d++ {
+			weekdays[d] = true
+		}
+	}
+
+	step := time.Duration(s.DurationMinutes) * time.Minute
+	var slots []*AppointmentSlot
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		if !weekdays[day.Weekday()] {
+			continue
+		}
+		start := time.Date(day.Year(), day.Month(), day.Day(), opens.Hour(), opens.Minute(), 0, 0, time.Local)
+		end := time.Date(day.Year(), day.Month(), day.Day(), closes.Hour(), closes.Minute(), 0, 0, time.Local)
+		for t := start; !t.Add(step).After(end); t = t.Add(step) {
+			if !t.After(now) {
+				continue
+			}
+			slots = append(slots, &AppointmentSlot{
+				Id:        uuid.New(),
+				ServiceID: s.Id,
+				AgencyID:  s.AgencyID,
+				StartsAt:  t,
+				EndsAt:    t.Add(step),
+				Capacity:  r.Capacity,
+				Status:    SlotOpen,
+				CreatedAt: now,
+				UpdatedAt: now,
+			})
+		}
+	}
+	if len(slots) == 0 {
+		return nil, errors.New("the calendar has no slot in the future")
+	}
+	return slots, nil
+}
+
+// Appointment of a citizen at an agency
+type Appointment struct {
+	Id          uuid.UUID  `json:"id" db:"id"`
+	SlotID      uuid.UUID  `json:"slot_id" db:"slot_id"`
+	ServiceID   uuid.UUID  `json:"service_id" db:"service_id"`
+	AgencyID    uuid.UUID  `json:"agency_id" db:"agency_id"`
+	AgencyName  string     `json:"agency_name" db:"agency_name"`
+	ServiceKind string     `json:"service_kind" db:"service_kind"`
+	ServiceName string     `json:"service_name" db:"service_name"`
+	StartsAt    time.Time  `json:"starts_at" db:"starts_at"` // Giờ hẹn
+	UserID      uuid.UUID  `json:"user_id" db:"user_id"`
+	IdentityNo  string     `json:"identity_no" db:"identity_no"`
+	FullName    string     `json:"full_name" db:"full_name"`
+	Note        string     `json:"note" db:"note"`
+	Status      string     `json:"status" db:"status"`           // booked, cancelled, checked_in
+	RemindedAt  *time.Time `json:"reminded_at" db:"reminded_at"` // Đã nhắc lịch
+	CheckedInAt *time.Time `json:"checked_in_at" db:"checked_in_at"`
+	CheckedInBy *uuid.UUID `json:"checked_in_by" db:"checked_in_by"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+	StatusLabel string     `json:"status_label,omitempty" db:"-"`       // Trạng thái theo ngôn ngữ yêu cầu
+	KindLabel   string     `json:"service_kind_label,omitempty" db:"-"` // Loại dịch vụ theo ngôn ngữ yêu cầu
+}
+
+func (a *Appointment) Localize(lang string) {
+	a.StatusLabel = i18n.Label(lang, i18n.AppointmentStatus, a.Status)
+	a.KindLabel = i18n.Label(lang, i18n.ServiceKind, a.ServiceKind)
+}
+
+// All appointment response
+type AppointmentList struct {
+	TotalCount   int            `json:"total_count"`
+	TotalPages   int            `json:"total_pages"`
+	Page         int            `json:"page"`
+	Size         int            `json:"size"`
+	HasMore      bool           `json:"has_more"`
+	Appointments []*Appointment `json:"appointments"`
+	NextCursor   string         `json:"next_cursor,omitempty"` // cursor of the next page, set on cursor pages
+}
+
+func (l *AppointmentList) Localize(lang string) {
+	for _, a := range l.Appointments {
+		a.Localize(lang)
+	}
+}
+
+// Appointments of an agency on a day, in slot order, cancelled ones left out
+type AppointmentQueue struct {
+	AgencyID     uuid.UUID      `json:"agency_id"`
+	Date         string         `json:"date"`
+	Total        int            `json:"total"`
+	Waiting      int            `json:"waiting"`
+	CheckedIn    int            `json:"checked_in"`
+	Appointments []*Appointment `json:"appointments"`
+}
+
+func NewAppointmentQueue(agencyID uuid.UUID, date string, appointments []*Appointment) *AppointmentQueue {
+	q := &AppointmentQueue{AgencyID: agencyID, Date: date, Total: len(appointments), Appointments: appointments}
+	for _, a := range appointments {
+		switch a.Status {
+		case AppointmentBooked:
+			q.Waiting++
+		case AppointmentCheckedIn:
+			q.CheckedIn++
+		}
+	}
+	return q
+}
+
+func (q *AppointmentQueue) Localize(lang string) {
+	for _, a := range q.Appointments {
+		a.Localize(lang)
+	}
+}
+
+// Citizen's booking of a slot
+type BookAppointmentRequest struct {
+	SlotID uuid.UUID `json:"slot_id" validate:"required"`
+	Note   string    `json:"note" validate:"omitempty,max=500"`
+}
+
+// Move of an appointment to another slot of its service
+type RescheduleAppointmentRequest struct {
+	SlotID uuid.UUID `json:"slot_id" validate:"required"`
+}
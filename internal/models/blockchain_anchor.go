@@ -0,0 +1,106 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Records written to the chain
+const (
+	AnchorLicense = "license"
+	AnchorVehicle = "vehicle"
+)
+
+// Anchor statuses, pending > submitted > confirmed. Failed anchors are retried by an admin,
+// skipped ones belong to records confirmed by the client meanwhile
+const (
+	AnchorPending   = "pending"
+	AnchorSubmitted = "submitted"
+	AnchorConfirmed = "confirmed"
+	AnchorFailed    = "failed"
+	AnchorSkipped   = "skipped"
+)
+
+// Record queued for the chain by the anchoring worker
+type BlockchainAnchor struct {
+	Id            uuid.UUID  `json:"id" db:"id"`
+	RecordType    string     `json:"record_type" db:"record_type"` // license, vehicle
+	RecordID      uuid.UUID  `json:"record_id" db:"record_id"`
+	RecordHash    string     `json:"record_hash" db:"record_hash"`   // Mã băm của hồ sơ đã gửi
+	HashVersion   int        `json:"hash_version" db:"hash_version"` // canonical version of record_hash, 0 for legacy anchors
+	Status        string     `json:"status" db:"status"`             // pending, submitted, confirmed, failed, skipped
+	TxHash        string     `json:"tx_hash" db:"tx_hash"`
+	BlockNumber   *int64     `json:"block_number" db:"block_number"`
+	Confirmations int        `json:"confirmations" db:"confirmations"`
+	Attempts      int        `json:"attempts" db:"attempts"`
+	NextAttemptAt time.Time  `json:"next_attempt_at" db:"next_attempt_at"`
+	LastError     string     `json:"last_error" db:"last_error"`
+	SubmittedAt   *time.Time `json:"submitted_at" db:"submitted_at"`
+	ConfirmedAt   *time.Time `json:"confirmed_at" db:"confirmed_at"`
+	Reanchor      bool       `json:"reanchor" db:"reanchor"` // written again after the record changed on purpose
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// Record hash computed before the current canonical form, it cannot be compared to the record
+func (a *BlockchainAnchor) LegacyHash() bool {
+	return a.HashVersion < CanonicalVersion
+}
+
+// All Blockchain Anchor response
+type BlockchainAnchorList struct {
+	TotalCount int                 `json:"total_count"`
+	TotalPages int                 `json:"total_pages"`
+	Page       int                 `json:"page"`
+	Size       int                 `json:"size"`
+	HasMore    bool                `json:"has_more"`
+	Anchors    []*BlockchainAnchor `json:"anchors"`
+	NextCursor string              `json:"next_cursor,omitempty"` // cursor of the next page, set on cursor pages
+}
+
+// Integrity flag statuses
+const (
+	FlagOpen     = "open"
+	FlagResolved = "resolved"
+)
+
+// Record whose canonical hash no longer matches the hash anchored on the chain
+type IntegrityFlag struct {
+	Id           uuid.UUID  `json:"id" db:"id"`
+	RecordType   string     `json:"record_type" db:"record_type"`
+	RecordID     uuid.UUID  `json:"record_id" db:"record_id"`
+	AnchorID     uuid.UUID  `json:"anchor_id" db:"anchor_id"`         // last confirmed anchor of the record
+	AnchoredHash string     `json:"anchored_hash" db:"anchored_hash"` // hash on the chain
+	CurrentHash  string     `json:"current_hash" db:"current_hash"`   // hash of the record when last checked
+	Status       string     `json:"status" db:"status"`               // open, resolved
+	DetectedAt   time.Time  `json:"detected_at" db:"detected_at"`
+	CheckedAt    time.Time  `json:"checked_at" db:"checked_at"`
+	ResolvedAt   *time.Time `json:"resolved_at" db:"resolved_at"`
+}
+
+// All Integrity Flag response
+type IntegrityFlagList struct {
+	TotalCount int              `json:"total_count"`
+	TotalPages int              `json:"total_pages"`
+	Page       int              `json:"page"`
+	Size       int              `json:"size"`
+	HasMore    bool             `json:"has_more"`
+	Flags      []*IntegrityFlag `json:"flags"`
+	NextCursor string           `json:"next_cursor,omitempty"` // cursor of the next page, set on cursor pages
+}
+
+// Public tamper evidence of a record, hashes only
+type RecordVerification struct {
+	RecordType   string     `json:"record_type"`
+	RecordID     uuid.UUID  `json:"record_id"`
+	CurrentHash  string     `json:"current_hash"`  // canonical hash of the record as stored
+	AnchoredHash string     `json:"anchored_hash"` // hash of the last confirmed anchor, empty when never anchored
+	TxHash       string     `json:"tx_hash"`
+	BlockNumber  *int64     `json:"block_number"`
+	AnchoredAt   *time.Time `json:"anchored_at"`
+	Anchored     bool       `json:"anchored"`
+	Match        bool       `json:"match"`  // the record is unchanged since it was anchored
+	Legacy       bool       `json:"legacy"` // anchored with an older hash scheme, the record is queued to be anchored again
+	CheckedAt    time.Time  `json:"checked_at"`
+}
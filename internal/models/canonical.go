@@ -0,0 +1,113 @@
+package models
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Version of the canonical form, written into every canonical document so the form can evolve.
+// Adding, removing or reformatting a field changes every hash and needs a new version
+const CanonicalVersion = 1
+
+// Record with a canonical form, the document hashed and anchored on the chain.
+// Only the allowlisted fields are part of it, operational fields such as points, wallet,
+// blockchain state, versions and timestamps change without the record changing
+type CanonicalRecord interface {
+	Canonical() map[string]interface{}
+}
+
+// Canonical form of the license, its identity and entitlement
+func (d *DrivingLicense) Canonical() map[string]interface{} {
+	return map[string]interface{}{
+		"v":                   CanonicalVersion,
+		"type":                AnchorLicense,
+		"id":                  d.Id.String(),
+		"full_name":           strings.TrimSpace(d.Name),
+		"dob":                 canonicalDate(d.DOB),
+		"identity_no":         strings.TrimSpace(d.IdentityNo),
+		"license_no":          strings.TrimSpace(d.LicenseNo),
+		"license_type":        strings.TrimSpace(d.LicenseType),
+		"issue_date":          canonicalDate(d.IssueDate),
+		"expiry_date":         canonicalDatePtr(d.ExpiryDate),
+		"authority_id":        d.AuthorityId.String(),
+		"issuing_authority":   strings.TrimSpace(d.IssuingAuthority),
+		"nationality":         strings.TrimSpace(d.Nationality),
+		"status":              strings.TrimSpace(d.Status),
+		"previous_license_id": canonicalUUIDPtr(d.PreviousLicense),
+	}
+}
+
+// Canonical form of the vehicle document, the vehicle, its plate and its owner
+func (v *VehicleRegistration) Canonical() map[string]interface{} {
+	return map[string]interface{}{
+		"v":             CanonicalVersion,
+		"type":          AnchorVehicle,
+		"id":            v.ID.String(),
+		"owner_id":      canonicalUUIDPtr(v.OwnerID),
+		"owner_name":    strings.TrimSpace(v.OwnerName),
+		"vehicle_no":    strings.TrimSpace(v.VehiclePlateNo),
+		"color_plate":   strings.TrimSpace(v.ColorPlate),
+		"brand":         strings.TrimSpace(v.Brand),
+		"type_vehicle":  strings.TrimSpace(v.TypeVehicle),
+		"color_vehicle": strings.TrimSpace(v.ColorVehicle),
+		"chassis_no":    strings.TrimSpace(v.ChassisNo),
+		"engine_no":     strings.TrimSpace(v.EngineNo),
+		"seats":         v.Seats,
+		"issue_date":    canonicalDate(v.IssueDate),
+		"issuer":        strings.TrimSpace(v.Issuer),
+		"status":        strings.TrimSpace(v.Status),
+	}
+}
+
+// Deterministic JSON of the canonical form: keys sorted, no insignificant whitespace, no HTML escaping
+func CanonicalJSON(r CanonicalRecord) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(r.Canonical()); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// Hex encoded sha256 of the canonical JSON, the only hash written to the chain and compared to it.
+// The worker, the verification of client transactions and the integrity scan all hash through it
+func RecordHash(r CanonicalRecord) (string, error) {
+	raw, err := CanonicalJSON(r)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Dates as YYYY-MM-DD, date columns read as timestamps keep their day
+func canonicalDate(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= len(time.DateOnly) {
+		if _, err := time.Parse(time.DateOnly, s[:len(time.DateOnly)]); err == nil {
+			return s[:len(time.DateOnly)]
+		}
+	}
+	return s
+}
+
+func canonicalDatePtr(s *string) interface{} {
+	if s == nil {
+		return nil
+	}
+	return canonicalDate(*s)
+}
+
+func canonicalUUIDPtr(id *uuid.UUID) interface{} {
+	if id == nil {
+		return nil
+	}
+	return id.String()
+}
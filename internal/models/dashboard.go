@@ -0,0 +1,101 @@
+package models
+
+import (
+	"time"
+
+	"github.com/adohong4/driving-license/pkg/i18n"
+)
+
+// Dashboard sections
+const (
+	DashboardSectionProfile       = "profile"
+	DashboardSectionLicenses      = "licenses"
+	DashboardSectionVehicles      = "vehicles"
+	DashboardSectionViolations    = "violations"
+	DashboardSectionNotifications = "notifications"
+)
+
+// Codes of the sections that could not be loaded
+const (
+	DashboardErrTimeout     = "timeout"
+	DashboardErrUnavailable = "unavailable"
+)
+
+// Validity of a vehicle's inspection or insurance
+const (
+	ValidityValid   = "valid"
+	ValidityExpired = "expired"
+	ValidityPending = "pending"
+	ValidityUnknown = "unknown"
+)
+
+// Home screen of a citizen, sections that failed are null and listed in errors
+type Dashboard struct {
+	Profile       *User                   `json:"profile"`
+	Licenses      []*DrivingLicense       `json:"licenses"`
+	Vehicles      []*DashboardVehicle     `json:"vehicles"`
+	Violations    *DashboardViolations    `json:"violations"`
+	Notifications *DashboardNotifications `json:"notifications"`
+	Errors        []DashboardSectionError `json:"errors,omitempty"`
+	GeneratedAt   time.Time               `json:"generated_at"`
+}
+
+func (d *Dashboard) Localize(lang string) {
+	for _, l := range d.Licenses {
+		l.Localize(lang)
+	}
+	for _, v := range d.Vehicles {
+		v.Localize(lang)
+	}
+	if d.Violations != nil {
+		for _, t := range d.Violations.Items {
+			t.Localize(lang)
+		}
+	}
+	for i, e := range d.Errors {
+		d.Errors[i].Message = i18n.T(lang, "dashboard."+e.Code)
+	}
+}
+
+// Section of the dashboard that could not be loaded
+type DashboardSectionError struct {
+	Section string `json:"section"`
+	Code    string `json:"code"` // timeout, unavailable
+	Message string `json:"message"`
+}
+
+// Vehicle of the citizen with the validity of its inspection and insurance
+type DashboardVehicle struct {
+	*VehicleRegistration
+	InspectionStatus string `json:"inspection_status"` // valid, expired, pending
+	InsuranceStatus  string `json:"insurance_status"`  // unknown until insurance records are kept
+}
+
+// Inspection validity by the expiry date, pending until the vehicle has been inspected.
+// The registry keeps no insurance records yet, so insurance is unknown.
+func NewDashboardVehicle(v *VehicleRegistration, now time.Time) *DashboardVehicle {
+	dv := &DashboardVehicle{VehicleRegistration: v, InspectionStatus: ValidityPending, InsuranceStatus: ValidityUnknown}
+	if v.ExpiryDate == nil || v.RegistrationDate == nil || len(*v.ExpiryDate) < len(time.DateOnly) {
+		return dv
+	}
+	// dates compare as YYYY-MM-DD strings
+	if (*v.ExpiryDate)[:len(time.DateOnly)] < now.Format(time.DateOnly) {
+		dv.InspectionStatus = ValidityExpired
+	} else {
+		dv.InspectionStatus = ValidityValid
+	}
+	return dv
+}
+
+// Unpaid violations on the citizen's vehicles, items are the most recent ones
+type DashboardViolations struct {
+	Count      int                 `json:"count" db:"count"`
+	Overdue    int                 `json:"overdue" db:"overdue"`
+	FineAmount int64               `json:"fine_amount" db:"fine_amount"` // VND
+	Points     int                 `json:"points" db:"points"`
+	Items      []*TrafficViolation `json:"items" db:"-"`
+}
+
+type DashboardNotifications struct {
+	Unread int `json:"unread"`
+}
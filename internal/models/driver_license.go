@@ -4,6 +4,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/adohong4/driving-license/pkg/i18n"
 	"github.com/google/uuid"
 )
 
@@ -11,19 +12,19 @@ type DrivingLicense struct {
 	Id               uuid.UUID  `json:"id" db:"id" validate:"required"`
 	Name             string     `json:"full_name" db:"full_name"`
 	Avatar           string     `json:"avatar" db:"avatar"`
-	DOB              string     `json:"dob" db:"dob"`                     // Ngày sinh
-	IdentityNo       string     `json:"identity_no" db:"identity_no"`     // Căn cước công dân
-	OwnerAddress     string     `json:"owner_address" db:"owner_address"` // Địa chỉ
+	DOB              string     `json:"dob" db:"dob" validate:"omitempty,birthdate"`                           // Ngày sinh
+	IdentityNo       string     `json:"identity_no" db:"identity_no" validate:"omitempty,cccd,cccd_birth=DOB"` // Căn cước công dân
+	OwnerAddress     string     `json:"owner_address" db:"owner_address"`                                      // Địa chỉ
 	OwnerCity        string     `json:"owner_city" db:"owner_city"`
-	LicenseNo        string     `json:"license_no" db:"license_no"`               // Số bằng lái
-	IssueDate        string     `json:"issue_date" db:"issue_date"`               // Ngày cấp
-	ExpiryDate       *string    `json:"expiry_date" db:"expiry_date"`             // Ngày hết hạn (có thời hạn, vô thời hạn)
-	Status           string     `json:"status" db:"status"`                       // Trạng thái (pending: chờ đợi, expired: hết hạn, active: hoạt động, pause: tạm dừng (point = 0), revoke: thu hồi)
-	LicenseType      string     `json:"license_type" db:"license_type"`           // Loại bằng lái (A1, B1, B2, ...)
-	AuthorityId      uuid.UUID  `json:"authority_id" db:"authority_id"`           // Mã nơi cấp
-	IssuingAuthority string     `json:"issuing_authority" db:"issuing_authority"` // Nơi cấp
-	Nationality      string     `json:"nationality" db:"nationality"`             // Quốc tịch (Việt Nam, Hàn Quốc, ....)
-	Point            int        `json:"point" db:"point"`                         // Điểm bằng lái xe (0 < point < 12)
+	LicenseNo        string     `json:"license_no" db:"license_no"`                                                                 // Số bằng lái
+	IssueDate        string     `json:"issue_date" db:"issue_date" validate:"omitempty,isodate"`                                    // Ngày cấp
+	ExpiryDate       *string    `json:"expiry_date" db:"expiry_date"`                                                               // Ngày hết hạn (có thời hạn, vô thời hạn)
+	Status           string     `json:"status" db:"status"`                                                                         // Trạng thái (pending: chờ đợi, expired: hết hạn, active: hoạt động, pause: tạm dừng (point = 0), revoke: thu hồi)
+	LicenseType      string     `json:"license_type" db:"license_type" validate:"omitempty,license_type,driving_age=DOB IssueDate"` // Loại bằng lái (A1, B1, B2, ...)
+	AuthorityId      uuid.UUID  `json:"authority_id" db:"authority_id"`                                                             // Mã nơi cấp
+	IssuingAuthority string     `json:"issuing_authority" db:"issuing_authority"`                                                   // Nơi cấp
+	Nationality      string     `json:"nationality" db:"nationality" validate:"omitempty,nationality"`                              // Quốc tịch (Việt Nam, Hàn Quốc, ....)
+	Point            int        `json:"point" db:"point"`                                                                           // Điểm bằng lái xe (0 < point < 12)
 	WalletAddress    string     `json:"wallet_address" db:"wallet_address"`
 	OnBlockchain     bool       `json:"on_blockchain" db:"on_blockchain"`         // Trạng thái lưu ở blockchain (lưa/ chưa lưu)
 	BlockchainTxHash string     `json:"blockchain_txhash" db:"blockchain_txhash"` // Mã lưu ở blockchain
@@ -33,6 +34,10 @@ type DrivingLicense struct {
 	CreatedAt        time.Time  `json:"created_at" db:"created_at"`               // Thời gian tạo
 	UpdatedAt        time.Time  `json:"updated_at" db:"updated_at"`               // Thời gian cập nhật
 	Active           bool       `json:"active" db:"active"`
+	PreviousLicense  *uuid.UUID `json:"previous_license_id" db:"previous_license_id"` // Bằng được thay thế khi cấp đổi, nâng hạng, cấp lại
+	StatusLabel      string     `json:"status_label,omitempty" db:"-"`                // Trạng thái theo ngôn ngữ yêu cầu
+
+	Endorsements []*LicenseEndorsement `json:"endorsements,omitempty" db:"-"` // Các hạng của thẻ
 }
 
 // Prepare the driver license for creation
@@ -40,6 +45,9 @@ func (d *DrivingLicense) PrepareCreate() error {
 	d.IdentityNo = strings.TrimSpace(d.IdentityNo)
 	d.LicenseNo = strings.TrimSpace(d.LicenseNo)
 	d.LicenseType = strings.TrimSpace(d.LicenseType)
+	d.DOB = strings.TrimSpace(d.DOB)
+	d.IssueDate = strings.TrimSpace(d.IssueDate)
+	d.Nationality = strings.TrimSpace(d.Nationality)
 
 	d.Id = uuid.New()
 	d.Point = 12
@@ -50,6 +58,7 @@ func (d *DrivingLicense) PrepareCreate() error {
 	d.UpdatedAt = time.Now()
 	d.Active = true
 	d.Version = 1
+	d.Endorsements = nil
 	return nil
 }
 
@@ -58,11 +67,22 @@ func (d *DrivingLicense) PrepareUpdate() error {
 	d.IdentityNo = strings.TrimSpace(d.IdentityNo)
 	d.LicenseNo = strings.TrimSpace(d.LicenseNo)
 	d.LicenseType = strings.TrimSpace(d.LicenseType)
+	d.DOB = strings.TrimSpace(d.DOB)
+	d.IssueDate = strings.TrimSpace(d.IssueDate)
+	d.Nationality = strings.TrimSpace(d.Nationality)
 
 	d.UpdatedAt = time.Now()
 	return nil
 }
 
+// Set the labels of the response language
+func (d *DrivingLicense) Localize(lang string) {
+	d.StatusLabel = i18n.Label(lang, i18n.LicenseStatus, d.Status)
+	for _, e := range d.Endorsements {
+		e.Localize(lang)
+	}
+}
+
 // All driver license response
 type DrivingLicenseList struct {
 	TotalCount     int               `json:"total_count"`
@@ -73,6 +93,12 @@ type DrivingLicenseList struct {
 	DrivingLicense []*DrivingLicense `json:"driver_licenses"`
 }
 
+func (l *DrivingLicenseList) Localize(lang string) {
+	for _, d := range l.DrivingLicense {
+		d.Localize(lang)
+	}
+}
+
 // Status Distribution Item
 type StatusDistributionItem struct {
 	Status string `json:"status"`
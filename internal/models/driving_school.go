@@ -0,0 +1,242 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/adohong4/driving-license/pkg/i18n"
+	"github.com/google/uuid"
+)
+
+// Enrollment statuses, learners sit the exams while enrolled and are licensed once both are passed
+const (
+	EnrollmentEnrolled  = "enrolled"
+	EnrollmentLicensed  = "licensed"
+	EnrollmentWithdrawn = "withdrawn"
+)
+
+// Kinds of exams, the practical exam is taken after the theory one is passed
+const (
+	ExamTheory    = "theory"
+	ExamPractical = "practical"
+)
+
+// Exam session statuses
+const (
+	SessionScheduled = "scheduled"
+	SessionCancelled = "cancelled"
+)
+
+// Booking statuses, a booked seat ends with a result or a cancellation
+const (
+	BookingBooked    = "booked"
+	BookingCancelled = "cancelled"
+	BookingPassed    = "passed"
+	BookingFailed    = "failed"
+	BookingAbsent    = "absent"
+)
+
+// Learner of a driving school
+type SchoolEnrollment struct {
+	Id                uuid.UUID  `json:"id" db:"id"`
+	SchoolID          uuid.UUID  `json:"school_id" db:"school_id"`                     // Cơ sở đào tạo
+	SchoolName        string     `json:"school_name" db:"school_name"`                 // Tên cơ sở đào tạo
+	LearnerID         *uuid.UUID `json:"learner_id" db:"learner_id"`                   // Học viên (user), khi đã có tài khoản
+	IdentityNo        string     `json:"identity_no" db:"identity_no"`                 // CCCD học viên
+	FullName          string     `json:"full_name" db:"full_name"`                     // Họ tên học viên
+	DOB               string     `json:"dob" db:"dob"`                                 // Ngày sinh
+	LicenseType       string     `json:"license_type" db:"license_type"`               // Hạng đào tạo
+	Status            string     `json:"status" db:"status"`                           // enrolled, licensed, withdrawn
+	TheoryPassedAt    *time.Time `json:"theory_passed_at" db:"theory_passed_at"`       // Đạt lý thuyết
+	PracticalPassedAt *time.Time `json:"practical_passed_at" db:"practical_passed_at"` // Đạt thực hành
+	LicenseID         *uuid.UUID `json:"license_id" db:"license_id"`                   // Bằng được cấp khi đạt cả hai phần
+	IssuingAt         *time.Time `json:"issuing_at,omitempty" db:"issuing_at"`         // Đang cấp bằng, giữ chỗ đến khi liên kết
+	CreatorId         uuid.UUID  `json:"creator_id" db:"creator_id"`
+	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at" db:"updated_at"`
+	StatusLabel       string     `json:"status_label,omitempty" db:"-"` // Trạng thái theo ngôn ngữ yêu cầu
+}
+
+// Whether the learner passed the exam
+func (e *SchoolEnrollment) Passed(kind string) bool {
+	if kind == ExamTheory {
+		return e.TheoryPassedAt != nil
+	}
+	return e.PracticalPassedAt != nil
+}
+
+// Whether both exams are passed and the license is still to be issued
+func (e *SchoolEnrollment) AwaitsLicense() bool {
+	return e.Status == EnrollmentEnrolled && e.TheoryPassedAt != nil && e.PracticalPassedAt != nil && e.LicenseID == nil
+}
+
+// Pending license of a learner who passed both exams, issued by the agency of the practical exam
+func (e *SchoolEnrollment) License(licenseNo string, agency *GovAgency) *DrivingLicense {
+	return &DrivingLicense{
+		Name:             e.FullName,
+		DOB:              dateOnly(e.DOB),
+		IdentityNo:       e.IdentityNo,
+		LicenseNo:        licenseNo,
+		IssueDate:        time.Now().Format(time.DateOnly),
+		ExpiryDate:       LicenseExpiry(e.LicenseType, time.Now()),
+		Status:           "pending",
+		LicenseType:      e.LicenseType,
+		AuthorityId:      agency.Id,
+		IssuingAuthority: agency.Name,
+		OwnerCity:        agency.City,
+	}
+}
+
+func (e *SchoolEnrollment) Localize(lang string) {
+	e.StatusLabel = i18n.Label(lang, i18n.EnrollmentStatus, e.Status)
+}
+
+// All enrollment response
+type SchoolEnrollmentList struct {
+	TotalCount  int                 `json:"total_count"`
+	TotalPages  int                 `json:"total_pages"`
+	Page        int                 `json:"page"`
+	Size        int                 `json:"size"`
+	HasMore     bool                `json:"has_more"`
+	Enrollments []*SchoolEnrollment `json:"enrollments"`
+	NextCursor  string              `json:"next_cursor,omitempty"` // cursor of the next page, set on cursor pages
+}
+
+func (l *SchoolEnrollmentList) Localize(lang string) {
+	for _, e := range l.Enrollments {
+		e.Localize(lang)
+	}
+}
+
+// School's enrollment of a learner, who must be old enough for the class by the enrollment
+type EnrollRequest struct {
+	SchoolID    uuid.UUID `json:"school_id" validate:"required"`
+	IdentityNo  string    `json:"identity_no" validate:"required,cccd,cccd_birth=DOB"`
+	FullName    string    `json:"full_name" validate:"required,max=255"`
+	DOB         string    `json:"dob" validate:"required,birthdate"`
+	LicenseType string    `json:"license_type" validate:"required,license_type,driving_age=DOB"`
+}
+
+// Exam session at an agency
+type ExamSession struct {
+	Id          uuid.UUID `json:"id" db:"id"`
+	AgencyID    uuid.UUID `json:"agency_id" db:"agency_id"`       // Trung tâm sát hạch
+	AgencyName  string    `json:"agency_name" db:"agency_name"`   // Tên trung tâm
+	Kind        string    `json:"kind" db:"kind"`                 // theory, practical
+	LicenseType string    `json:"license_type" db:"license_type"` // Hạng sát hạch
+	StartsAt    time.Time `json:"starts_at" db:"starts_at"`       // Thời gian bắt đầu
+	Location    string    `json:"location" db:"location"`         // Phòng thi, sân sát hạch
+	Capacity    int       `json:"capacity" db:"capacity"`         // Số chỗ
+	Booked      int       `json:"booked" db:"booked"`             // Số chỗ đã đặt
+	Status      string    `json:"status" db:"status"`             // scheduled, cancelled
+	CreatorId   uuid.UUID `json:"creator_id" db:"creator_id"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	KindLabel   string    `json:"kind_label,omitempty" db:"-"` // Phần thi theo ngôn ngữ yêu cầu
+}
+
+// Seats left in the session
+func (s *ExamSession) Available() int {
+	if s.Booked >= s.Capacity {
+		return 0
+	}
+	return s.Capacity - s.Booked
+}
+
+func (s *ExamSession) Localize(lang string) {
+	s.KindLabel = i18n.Label(lang, i18n.ExamKind, s.Kind)
+}
+
+// All exam session response
+type ExamSessionList struct {
+	TotalCount int            `json:"total_count"`
+	TotalPages int            `json:"total_pages"`
+	Page       int            `json:"page"`
+	Size       int            `json:"size"`
+	HasMore    bool           `json:"has_more"`
+	Sessions   []*ExamSession `json:"sessions"`
+	NextCursor string         `json:"next_cursor,omitempty"` // cursor of the next page, set on cursor pages
+}
+
+func (l *ExamSessionList) Localize(lang string) {
+	for _, s := range l.Sessions {
+		s.Localize(lang)
+	}
+}
+
+// Officer's exam session, starting in the future
+type CreateSessionRequest struct {
+	AgencyID    uuid.UUID `json:"agency_id" validate:"required"`
+	Kind        string    `json:"kind" validate:"required,oneof=theory practical"`
+	LicenseType string    `json:"license_type" validate:"required,license_type"`
+	StartsAt    time.Time `json:"starts_at" validate:"required"`
+	Location    string    `json:"location" validate:"omitempty,max=255"`
+	Capacity    int       `json:"capacity" validate:"required,min=1,max=1000"`
+}
+
+// Seat of a candidate in an exam session
+type ExamBooking struct {
+	Id           uuid.UUID  `json:"id" db:"id"`
+	SessionID    uuid.UUID  `json:"session_id" db:"session_id"`
+	EnrollmentID uuid.UUID  `json:"enrollment_id" db:"enrollment_id"`
+	Kind         string     `json:"kind" db:"kind"`               // theory, practical
+	Status       string     `json:"status" db:"status"`           // booked, cancelled, passed, failed, absent
+	Score        *int       `json:"score" db:"score"`             // Điểm
+	Note         string     `json:"note" db:"note"`               // Ghi chú của giám khảo
+	ExaminerID   *uuid.UUID `json:"examiner_id" db:"examiner_id"` // Giám khảo ghi nhận kết quả
+	RecordedAt   *time.Time `json:"recorded_at" db:"recorded_at"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
+	StatusLabel  string     `json:"status_label,omitempty" db:"-"` // Trạng thái theo ngôn ngữ yêu cầu
+}
+
+func (b *ExamBooking) Localize(lang string) {
+	b.StatusLabel = i18n.Label(lang, i18n.BookingStatus, b.Status)
+}
+
+// Candidates of a session, in booking order
+type ExamBookingList struct {
+	Bookings []*ExamBooking `json:"bookings"`
+}
+
+func (l *ExamBookingList) Localize(lang string) {
+	for _, b := range l.Bookings {
+		b.Localize(lang)
+	}
+}
+
+// Booking of a seat for an enrollment
+type BookSessionRequest struct {
+	EnrollmentID uuid.UUID `json:"enrollment_id" validate:"required"`
+}
+
+// Examiner's result of a booked seat
+type ExamResultRequest struct {
+	Result string `json:"result" validate:"required,oneof=passed failed absent"`
+	Score  *int   `json:"score" validate:"omitempty,min=0,max=100"`
+	Note   string `json:"note" validate:"omitempty,max=500"`
+}
+
+// Result of an exam with the license issued when it completed the enrollment
+type ExamResult struct {
+	Booking    *ExamBooking      `json:"booking"`
+	Enrollment *SchoolEnrollment `json:"enrollment"`
+	License    *DrivingLicense   `json:"license,omitempty"`
+}
+
+func (r *ExamResult) Localize(lang string) {
+	r.Booking.Localize(lang)
+	r.Enrollment.Localize(lang)
+	if r.License != nil {
+		r.License.Localize(lang)
+	}
+}
+
+// YYYY-MM-DD part of a date read from the database
+func dateOnly(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) > len(time.DateOnly) {
+		return s[:len(time.DateOnly)]
+	}
+	return s
+}
@@ -0,0 +1,139 @@
+package models
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/adohong4/driving-license/pkg/export"
+	"github.com/google/uuid"
+)
+
+// Export job status
+const (
+	ExportJobPending = "pending"
+	ExportJobRunning = "running"
+	ExportJobDone    = "done"
+	ExportJobFailed  = "failed"
+)
+
+// Async export job
+type ExportJob struct {
+	Id          uuid.UUID  `json:"id" db:"id"`
+	UserId      uuid.UUID  `json:"user_id" db:"user_id"`
+	Entity      string     `json:"entity" db:"entity"` // licenses, vehicles, violations, users
+	Format      string     `json:"format" db:"format"`
+	Lang        string     `json:"lang" db:"lang"`
+	Search      string     `json:"search" db:"search"`
+	Status      string     `json:"status" db:"status"`
+	RowCount    int        `json:"row_count" db:"row_count"`
+	FilePath    string     `json:"-" db:"file_path"`
+	Error       string     `json:"error,omitempty" db:"error"`
+	DownloadURL string     `json:"download_url,omitempty" db:"-"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty" db:"finished_at"`
+}
+
+const exportDateLayout = "2006-01-02 15:04:05"
+
+var DrivingLicenseExportColumns = []export.Column{
+	{Key: "license_no", VI: "Số GPLX", EN: "License No"},
+	{Key: "full_name", VI: "Họ và tên", EN: "Full name"},
+	{Key: "dob", VI: "Ngày sinh", EN: "Date of birth"},
+	{Key: "identity_no", VI: "Số CCCD", EN: "Identity No"},
+	{Key: "owner_address", VI: "Địa chỉ", EN: "Address"},
+	{Key: "owner_city", VI: "Tỉnh/Thành phố", EN: "City"},
+	{Key: "license_type", VI: "Hạng", EN: "License type"},
+	{Key: "issue_date", VI: "Ngày cấp", EN: "Issue date"},
+	{Key: "expiry_date", VI: "Ngày hết hạn", EN: "Expiry date"},
+	{Key: "issuing_authority", VI: "Nơi cấp", EN: "Issuing authority"},
+	{Key: "nationality", VI: "Quốc tịch", EN: "Nationality"},
+	{Key: "point", VI: "Điểm", EN: "Points"},
+	{Key: "status", VI: "Trạng thái", EN: "Status"},
+	{Key: "on_blockchain", VI: "Đã lưu blockchain", EN: "On blockchain"},
+}
+
+func (d *DrivingLicense) ExportRow() []string {
+	return []string{
+		d.LicenseNo, d.Name, d.DOB, d.IdentityNo, d.OwnerAddress, d.OwnerCity, d.LicenseType,
+		d.IssueDate, strOrEmpty(d.ExpiryDate), d.IssuingAuthority, d.Nationality,
+		strconv.Itoa(d.Point), d.Status, strconv.FormatBool(d.OnBlockchain),
+	}
+}
+
+var VehicleRegistrationExportColumns = []export.Column{
+	{Key: "vehicle_no", VI: "Biển số", EN: "Plate No"},
+	{Key: "owner_name", VI: "Chủ xe", EN: "Owner"},
+	{Key: "brand", VI: "Nhãn hiệu", EN: "Brand"},
+	{Key: "type_vehicle", VI: "Loại phương tiện", EN: "Vehicle type"},
+	{Key: "color_vehicle", VI: "Màu xe", EN: "Color"},
+	{Key: "color_plate", VI: "Màu biển", EN: "Plate color"},
+	{Key: "chassis_no", VI: "Số khung", EN: "Chassis No"},
+	{Key: "engine_no", VI: "Số máy", EN: "Engine No"},
+	{Key: "seats", VI: "Số chỗ ngồi", EN: "Seats"},
+	{Key: "issue_date", VI: "Ngày cấp", EN: "Issue date"},
+	{Key: "issuer", VI: "Nơi cấp", EN: "Issuer"},
+	{Key: "registration_date", VI: "Ngày đăng kiểm", EN: "Inspection date"},
+	{Key: "expiry_date", VI: "Hạn đăng kiểm", EN: "Inspection expiry"},
+	{Key: "status", VI: "Tình trạng", EN: "Status"},
+}
+
+func (v *VehicleRegistration) ExportRow() []string {
+	seats := ""
+	if v.Seats != nil {
+		seats = strconv.Itoa(*v.Seats)
+	}
+	return []string{
+		v.VehiclePlateNo, v.OwnerName, v.Brand, v.TypeVehicle, v.ColorVehicle, v.ColorPlate,
+		v.ChassisNo, v.EngineNo, seats, v.IssueDate, v.Issuer,
+		strOrEmpty(v.RegistrationDate), strOrEmpty(v.ExpiryDate), v.Status,
+	}
+}
+
+var TrafficViolationExportColumns = []export.Column{
+	{Key: "vehicle_no", VI: "Biển số", EN: "Plate No"},
+	{Key: "date", VI: "Thời gian vi phạm", EN: "Violation time"},
+	{Key: "type", VI: "Loại vi phạm", EN: "Violation type"},
+	{Key: "address", VI: "Địa điểm", EN: "Location"},
+	{Key: "district", VI: "Quận/Huyện", EN: "District"},
+	{Key: "province", VI: "Tỉnh/Thành phố", EN: "Province"},
+	{Key: "description", VI: "Mô tả", EN: "Description"},
+	{Key: "points", VI: "Điểm trừ", EN: "Points deducted"},
+	{Key: "fine_amount", VI: "Số tiền phạt (VND)", EN: "Fine amount (VND)"},
+	{Key: "expiry_date", VI: "Hạn nộp phạt", EN: "Payment due"},
+	{Key: "status", VI: "Trạng thái", EN: "Status"},
+}
+
+func (t *TrafficViolation) ExportRow() []string {
+	return []string{
+		t.VehiclePlateNo, t.Date.Format(exportDateLayout), t.Type, t.Address, t.District, t.Province,
+		t.Description, strconv.Itoa(t.Points), strconv.FormatInt(t.FineAmount, 10),
+		t.ExpiryDate.Format(exportDateLayout), t.Status,
+	}
+}
+
+var UserExportColumns = []export.Column{
+	{Key: "identity_no", VI: "Số CCCD", EN: "Identity No"},
+	{Key: "full_name", VI: "Họ và tên", EN: "Full name"},
+	{Key: "date_of_birth", VI: "Ngày sinh", EN: "Date of birth"},
+	{Key: "gender", VI: "Giới tính", EN: "Gender"},
+	{Key: "nationality", VI: "Quốc tịch", EN: "Nationality"},
+	{Key: "place_of_origin", VI: "Quê quán", EN: "Place of origin"},
+	{Key: "place_of_residence", VI: "Nơi thường trú", EN: "Place of residence"},
+	{Key: "role", VI: "Vai trò", EN: "Role"},
+	{Key: "user_address", VI: "Địa chỉ ví", EN: "Wallet address"},
+	{Key: "created_at", VI: "Ngày tạo", EN: "Created at"},
+}
+
+func (u *User) ExportRow() []string {
+	return []string{
+		u.IdentityNo, u.FullName, u.DateOfBirth, u.Gender, u.Nationality, u.PlaceOfOrigin,
+		u.PlaceOfResidence, strOrEmpty(u.Role), strOrEmpty(u.UserAddress), u.CreatedAt.Format(exportDateLayout),
+	}
+}
+
+func strOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
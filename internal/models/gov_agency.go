@@ -7,21 +7,41 @@ import (
 	"github.com/google/uuid"
 )
 
+// Levels of the agency tree, ministry > provincial department > district office
+const (
+	AgencyMinistry   = "ministry"
+	AgencyDepartment = "department"
+	AgencyDistrict   = "district"
+)
+
+// Level of the agencies directly below the level, empty for district offices
+func ChildLevel(level string) string {
+	switch level {
+	case AgencyMinistry:
+		return AgencyDepartment
+	case AgencyDepartment:
+		return AgencyDistrict
+	}
+	return ""
+}
+
 // Gov Agency model
 type GovAgency struct {
-	Id          uuid.UUID `json:"id" db:"id"`
-	Name        string    `json:"name" db:"name"`
-	UserAddress string    `json:"user_address" db:"user_address"`
-	Address     string    `json:"address" db:"address"`
-	City        string    `json:"city" db:"city"`
-	Type        string    `json:"type" db:"type"` // Đào tạo/đăng kiểm/cấp giấy tờ/...
-	Phone       string    `json:"phone" db:"phone"`
-	Email       string    `json:"email" db:"email"`
-	Status      string    `json:"status" db:"status"`
-	Version     int       `json:"version" db:"version"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
-	Active      bool      `json:"active" db:"active"`
+	Id          uuid.UUID  `json:"id" db:"id"`
+	Name        string     `json:"name" db:"name"`
+	UserAddress string     `json:"user_address" db:"user_address"`
+	Address     string     `json:"address" db:"address"`
+	City        string     `json:"city" db:"city"`
+	Type        string     `json:"type" db:"type"` // Đào tạo/đăng kiểm/cấp giấy tờ/...
+	Phone       string     `json:"phone" db:"phone"`
+	Email       string     `json:"email" db:"email"`
+	Status      string     `json:"status" db:"status"`
+	ParentID    *uuid.UUID `json:"parent_id" db:"parent_id"`                                                 // Cơ quan cấp trên
+	Level       string     `json:"level" db:"level" validate:"omitempty,oneof=ministry department district"` // ministry, department, district
+	Version     int        `json:"version" db:"version"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+	Active      bool       `json:"active" db:"active"`
 }
 
 // Prepare the Gov Agency for creation
@@ -29,6 +49,10 @@ func (f *GovAgency) PrepareCreate() error {
 	f.Phone = strings.TrimSpace(f.Phone)
 	f.Email = strings.TrimSpace(f.Email)
 
+	if f.Level == "" {
+		f.Level = AgencyDistrict
+	}
+
 	f.Id = uuid.New()
 	f.CreatedAt = time.Now()
 	f.UpdatedAt = time.Now()
@@ -58,6 +82,68 @@ type GovAgencyList struct {
 
 // Find gov agency query
 type AgencyWithToken struct {
-	GovAgency *GovAgency `json:"gov_agencies"`
-	Token     string     `json:"token"`
+	GovAgency *GovAgency   `json:"gov_agencies"`
+	Staff     *AgencyStaff `json:"staff,omitempty"` // set when a staff wallet signs in
+	Token     string       `json:"token"`
+}
+
+// Agency with the agencies below it
+type AgencyNode struct {
+	*GovAgency
+	Children []*AgencyNode `json:"children"`
+}
+
+// Tree of the agencies below the root, agencies are listed parents first
+func NewAgencyTree(root uuid.UUID, agencies []*GovAgency) *AgencyNode {
+	nodes := make(map[uuid.UUID]*AgencyNode, len(agencies))
+	for _, a := range agencies {
+		nodes[a.Id] = &AgencyNode{GovAgency: a, Children: []*AgencyNode{}}
+	}
+	for _, a := range agencies {
+		if a.Id == root || a.ParentID == nil {
+			continue
+		}
+		if parent, ok := nodes[*a.ParentID]; ok {
+			parent.Children = append(parent.Children, nodes[a.Id])
+		}
+	}
+	return nodes[root]
+}
+
+// Move an agency in the tree, a nil parent makes it a root
+type MoveAgencyRequest struct {
+	ParentID *uuid.UUID `json:"parent_id"`
+	Level    string     `json:"level" validate:"required,oneof=ministry department district"`
+}
+
+// Area an agency is responsible for, an empty district covers the whole province
+type AgencyJurisdiction struct {
+	Id        uuid.UUID `json:"id" db:"id"`
+	AgencyID  uuid.UUID `json:"agency_id" db:"agency_id"`
+	Province  string    `json:"province" db:"province"`
+	District  string    `json:"district" db:"district"`
+	CreatorId uuid.UUID `json:"creator_id" db:"creator_id"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// Add an area to the jurisdiction of an agency
+type AddJurisdictionRequest struct {
+	Province string `json:"province" validate:"required,lte=100"`
+	District string `json:"district" validate:"lte=100"`
+}
+
+// Jurisdiction area with the areas of the agencies below rolled up
+type JurisdictionArea struct {
+	Province string `json:"province" db:"province"`
+	District string `json:"district" db:"district"`
+}
+
+// Areas of an agency, its own and the effective ones including the agencies below.
+// Ministries are national, their records are not bounded by areas
+type AgencyJurisdictionView struct {
+	AgencyID  uuid.UUID             `json:"agency_id"`
+	Level     string                `json:"level"`
+	National  bool                  `json:"national"`
+	Areas     []*AgencyJurisdiction `json:"areas"`
+	Effective []*JurisdictionArea   `json:"effective"`
 }
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"time"
+
+	"github.com/adohong4/driving-license/pkg/export"
+	"github.com/google/uuid"
+)
+
+// Import job status
+const (
+	ImportJobPending = "pending"
+	ImportJobRunning = "running"
+	ImportJobDone    = "done"
+	ImportJobFailed  = "failed"
+)
+
+// Bulk import job, a dry run only validates the rows
+type ImportJob struct {
+	Id            uuid.UUID  `json:"id" db:"id"`
+	UserId        uuid.UUID  `json:"user_id" db:"user_id"`
+	Entity        string     `json:"entity" db:"entity"` // licenses, vehicles
+	Format        string     `json:"format" db:"format"`
+	Lang          string     `json:"lang" db:"lang"`
+	FileName      string     `json:"file_name" db:"file_name"`
+	DryRun        bool       `json:"dry_run" db:"dry_run"`
+	Status        string     `json:"status" db:"status"`
+	TotalRows     int        `json:"total_rows" db:"total_rows"`
+	ValidRows     int        `json:"valid_rows" db:"valid_rows"`
+	ImportedRows  int        `json:"imported_rows" db:"imported_rows"`
+	FailedRows    int        `json:"failed_rows" db:"failed_rows"`
+	FilePath      string     `json:"-" db:"file_path"`
+	ErrorFilePath string     `json:"-" db:"error_file_path"`
+	Error         string     `json:"error,omitempty" db:"error"`
+	ErrorFileURL  string     `json:"error_file_url,omitempty" db:"-"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	FinishedAt    *time.Time `json:"finished_at,omitempty" db:"finished_at"`
+}
+
+// Columns accepted by the license import, headers match the export
+var DrivingLicenseImportColumns = []export.Column{
+	{Key: "license_no", VI: "Số GPLX", EN: "License No"},
+	{Key: "full_name", VI: "Họ và tên", EN: "Full name"},
+	{Key: "dob", VI: "Ngày sinh", EN: "Date of birth"},
+	{Key: "identity_no", VI: "Số CCCD", EN: "Identity No"},
+	{Key: "owner_address", VI: "Địa chỉ", EN: "Address"},
+	{Key: "owner_city", VI: "Tỉnh/Thành phố", EN: "City"},
+	{Key: "license_type", VI: "Hạng", EN: "License type"},
+	{Key: "issue_date", VI: "Ngày cấp", EN: "Issue date"},
+	{Key: "expiry_date", VI: "Ngày hết hạn", EN: "Expiry date"},
+	{Key: "authority_id", VI: "Mã cơ quan cấp", EN: "Authority ID"},
+	{Key: "issuing_authority", VI: "Nơi cấp", EN: "Issuing authority"},
+	{Key: "nationality", VI: "Quốc tịch", EN: "Nationality"},
+	{Key: "status", VI: "Trạng thái", EN: "Status"},
+}
+
+var DrivingLicenseImportRequired = []string{"license_no", "full_name", "identity_no", "license_type", "issue_date"}
+
+// Columns accepted by the vehicle import, headers match the export
+var VehicleRegistrationImportColumns = []export.Column{
+	{Key: "vehicle_no", VI: "Biển số", EN: "Plate No"},
+	{Key: "owner_id", VI: "Mã chủ xe", EN: "Owner ID"},
+	{Key: "owner_name", VI: "Chủ xe", EN: "Owner"},
+	{Key: "brand", VI: "Nhãn hiệu", EN: "Brand"},
+	{Key: "type_vehicle", VI: "Loại phương tiện", EN: "Vehicle type"},
+	{Key: "color_vehicle", VI: "Màu xe", EN: "Color"},
+	{Key: "color_plate", VI: "Màu biển", EN: "Plate color"},
+	{Key: "chassis_no", VI: "Số khung", EN: "Chassis No"},
+	{Key: "engine_no", VI: "Số máy", EN: "Engine No"},
+	{Key: "seats", VI: "Số chỗ ngồi", EN: "Seats"},
+	{Key: "issue_date", VI: "Ngày cấp", EN: "Issue date"},
+	{Key: "issuer", VI: "Nơi cấp", EN: "Issuer"},
+	{Key: "registration_code", VI: "Mã tem", EN: "Inspection code"},
+	{Key: "registration_date", VI: "Ngày đăng kiểm", EN: "Inspection date"},
+	{Key: "expiry_date", VI: "Hạn đăng kiểm", EN: "Inspection expiry"},
+	{Key: "registration_place", VI: "Nơi đăng kiểm", EN: "Inspection place"},
+	{Key: "status", VI: "Tình trạng", EN: "Status"},
+}
+
+var VehicleRegistrationImportRequired = []string{"vehicle_no", "brand", "type_vehicle", "chassis_no", "engine_no"}
@@ -0,0 +1,204 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/adohong4/driving-license/pkg/i18n"
+	"github.com/google/uuid"
+)
+
+// Kinds of license applications
+const (
+	ApplicationRenewal = "renewal" // new expiry date, same class
+	ApplicationUpgrade = "upgrade" // higher class, e.g. B1 to B2
+	ApplicationReissue = "reissue" // replacement of a lost or damaged license
+)
+
+// Application statuses, reviewed by the issuing agency
+const (
+	ApplicationPending   = "pending"
+	ApplicationApproved  = "approved"
+	ApplicationRejected  = "rejected"
+	ApplicationCancelled = "cancelled"
+)
+
+// Status of a license replaced by the one issued on an approved application
+const LicenseSuperseded = "superseded"
+
+// Documents attached to an application
+const (
+	DocIdentityCard      = "identity_card"      // CCCD
+	DocHealthCertificate = "health_certificate" // Giấy khám sức khỏe
+	DocPhoto             = "photo"              // Ảnh chân dung
+	DocExamResult        = "exam_result"        // Kết quả sát hạch hạng mới
+	DocDamagedLicense    = "damaged_license"    // Bằng bị hỏng
+)
+
+// Documents each kind of application requires
+var requiredDocuments = map[string][]string{
+	ApplicationRenewal: {DocIdentityCard, DocHealthCertificate, DocPhoto},
+	ApplicationUpgrade: {DocIdentityCard, DocHealthCertificate, DocPhoto, DocExamResult},
+	ApplicationReissue: {DocIdentityCard, DocPhoto},
+}
+
+// Whether a license of the class can be upgraded to the other one, the upgrades follow the class prerequisites
+func CanUpgrade(from, to string) bool {
+	return !strings.EqualFold(from, to) && len(ClassPrerequisites(to)) > 0 && MeetsPrerequisites(to, []string{from})
+}
+
+// Default expiry of a license issued on the date, nil for the classes without one:
+// motorcycles never expire, B1, B2 and B after ten years and the other classes after five
+func LicenseExpiry(licenseType string, issued time.Time) *string {
+	var years int
+	switch strings.ToUpper(licenseType) {
+	case "A1", "A2", "A3", "A":
+		return nil
+	case "A4", "B1", "B2", "B":
+		years = 10
+	default:
+		years = 5
+	}
+	expiry := issued.AddDate(years, 0, 0).Format(time.DateOnly)
+	return &expiry
+}
+
+// Citizen's application to renew, upgrade or reissue a driving license
+type LicenseApplication struct {
+	Id                   uuid.UUID            `json:"id" db:"id"`
+	Kind                 string               `json:"kind" db:"kind"`                                     // renewal, upgrade, reissue
+	LicenseID            uuid.UUID            `json:"license_id" db:"license_id"`                         // Bằng hiện tại
+	LicenseNo            string               `json:"license_no" db:"license_no"`                         // Số bằng hiện tại
+	ApplicantID          uuid.UUID            `json:"applicant_id" db:"applicant_id"`                     // Người nộp (user)
+	IdentityNo           string               `json:"identity_no" db:"identity_no"`                       // CCCD người nộp
+	FullName             string               `json:"full_name" db:"full_name"`                           // Họ tên người nộp
+	LicenseType          string               `json:"license_type" db:"license_type"`                     // Hạng hiện tại
+	RequestedLicenseType string               `json:"requested_license_type" db:"requested_license_type"` // Hạng đề nghị, như hạng hiện tại trừ khi nâng hạng
+	ReissueCause         string               `json:"reissue_cause" db:"reissue_cause"`                   // lost, damaged khi cấp lại
+	Note                 string               `json:"note" db:"note"`                                     // Ghi chú của người nộp
+	Documents            ApplicationDocuments `json:"documents" db:"documents"`                           // Hồ sơ kèm theo
+	AgencyID             uuid.UUID            `json:"agency_id" db:"agency_id"`                           // Cơ quan cấp bằng xét duyệt
+	Status               string               `json:"status" db:"status"`                                 // pending, approved, rejected, cancelled
+	ReviewerID           *uuid.UUID           `json:"reviewer_id" db:"reviewer_id"`                       // Cán bộ xét duyệt
+	ReviewNote           string               `json:"review_note" db:"review_note"`                       // Ý kiến xét duyệt, lý do từ chối
+	NewLicenseID         *uuid.UUID           `json:"new_license_id" db:"new_license_id"`                 // Bằng được cấp khi phê duyệt
+	DecidedAt            *time.Time           `json:"decided_at" db:"decided_at"`
+	CreatedAt            time.Time            `json:"created_at" db:"created_at"`
+	UpdatedAt            time.Time            `json:"updated_at" db:"updated_at"`
+	KindLabel            string               `json:"kind_label,omitempty" db:"-"`   // Loại hồ sơ theo ngôn ngữ yêu cầu
+	StatusLabel          string               `json:"status_label,omitempty" db:"-"` // Trạng thái theo ngôn ngữ yêu cầu
+}
+
+// Prepare the application for creation
+func (a *LicenseApplication) PrepareCreate() error {
+	a.Note = strings.TrimSpace(a.Note)
+	a.RequestedLicenseType = strings.ToUpper(strings.TrimSpace(a.RequestedLicenseType))
+	if a.RequestedLicenseType == "" {
+		a.RequestedLicenseType = a.LicenseType
+	}
+	if a.Documents == nil {
+		a.Documents = ApplicationDocuments{}
+	}
+
+	a.Id = uuid.New()
+	a.Status = ApplicationPending
+	a.CreatedAt = time.Now()
+	a.UpdatedAt = time.Now()
+	return nil
+}
+
+// Required documents the application lacks
+func (a *LicenseApplication) MissingDocuments() []string {
+	required := requiredDocuments[a.Kind]
+	if a.Kind == ApplicationReissue && a.ReissueCause == "damaged" {
+		required = append(append([]string(nil), required...), DocDamagedLicense)
+	}
+
+	attached := make(map[string]bool, len(a.Documents))
+	for _, d := range a.Documents {
+		attached[d.Type] = true
+	}
+	var missing []string
+	for _, doc := range required {
+		if !attached[doc] {
+			missing = append(missing, doc)
+		}
+	}
+	return missing
+}
+
+func (a *LicenseApplication) Localize(lang string) {
+	a.KindLabel = i18n.Label(lang, i18n.ApplicationKind, a.Kind)
+	a.StatusLabel = i18n.Label(lang, i18n.ApplicationStatus, a.Status)
+}
+
+// Document attached to an application, stored by the client and referenced by url
+type ApplicationDocument struct {
+	Type string `json:"type" validate:"required,oneof=identity_card health_certificate photo exam_result damaged_license"`
+	URL  string `json:"url" validate:"required,url,max=500"`
+}
+
+// Documents of an application, stored as jsonb
+type ApplicationDocuments []ApplicationDocument
+
+func (d ApplicationDocuments) Value() (driver.Value, error) {
+	if d == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(d)
+}
+
+func (d *ApplicationDocuments) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*d = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, d)
+	case string:
+		return json.Unmarshal([]byte(v), d)
+	}
+	return fmt.Errorf("ApplicationDocuments.Scan: unsupported type %T", src)
+}
+
+// All license application response
+type LicenseApplicationList struct {
+	TotalCount   int                   `json:"total_count"`
+	TotalPages   int                   `json:"total_pages"`
+	Page         int                   `json:"page"`
+	Size         int                   `json:"size"`
+	HasMore      bool                  `json:"has_more"`
+	Applications []*LicenseApplication `json:"applications"`
+	NextCursor   string                `json:"next_cursor,omitempty"` // cursor of the next page, set on cursor pages
+}
+
+func (l *LicenseApplicationList) Localize(lang string) {
+	for _, a := range l.Applications {
+		a.Localize(lang)
+	}
+}
+
+// Citizen's application, the requested class is required to upgrade and the cause to reissue
+type SubmitApplicationRequest struct {
+	Kind                 string                `json:"kind" validate:"required,oneof=renewal upgrade reissue"`
+	LicenseID            uuid.UUID             `json:"license_id" validate:"required"`
+	RequestedLicenseType string                `json:"requested_license_type" validate:"required_if=Kind upgrade,omitempty,license_type"`
+	ReissueCause         string                `json:"reissue_cause" validate:"required_if=Kind reissue,omitempty,oneof=lost damaged"`
+	Note                 string                `json:"note" validate:"omitempty,max=500"`
+	Documents            []ApplicationDocument `json:"documents" validate:"required,min=1,max=20,dive"`
+}
+
+// Officer's approval, the new license gets its own number and, unless given, the default expiry of its class
+type ApproveApplicationRequest struct {
+	LicenseNo  string `json:"license_no" validate:"required,max=50"`
+	ExpiryDate string `json:"expiry_date" validate:"omitempty,isodate"`
+	Note       string `json:"note" validate:"omitempty,max=500"`
+}
+
+// Note of a rejection or cancellation
+type ApplicationNoteRequest struct {
+	Note string `json:"note" validate:"omitempty,max=500"`
+}
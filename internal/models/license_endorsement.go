@@ -0,0 +1,159 @@
+package models
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/adohong4/driving-license/pkg/i18n"
+	"github.com/google/uuid"
+)
+
+// Classes a citizen must already hold to be endorsed for a class, any one of them is enough.
+// Classes missing from the map have no prerequisite.
+var classPrerequisites = map[string][]string{
+	"A2":  {"A1"},
+	"A":   {"A1"},
+	"B2":  {"B1"},
+	"C":   {"B2", "C1"},
+	"D":   {"B2", "C", "D2"},
+	"E":   {"C", "D"},
+	"FB2": {"B2"},
+	"FC":  {"C"},
+	"FD":  {"D"},
+	"FE":  {"E"},
+	"C1":  {"B"},
+	"D1":  {"B", "C1"},
+	"D2":  {"D1"},
+	"BE":  {"B"},
+	"C1E": {"C1"},
+	"CE":  {"C"},
+	"D1E": {"D1"},
+	"D2E": {"D2"},
+	"DE":  {"D"},
+}
+
+// Classes of which one must be held before the class, nil when the class has no prerequisite
+func ClassPrerequisites(licenseType string) []string {
+	return classPrerequisites[strings.ToUpper(strings.TrimSpace(licenseType))]
+}
+
+// Whether one of the held classes is a prerequisite of the class
+func MeetsPrerequisites(licenseType string, held []string) bool {
+	required := ClassPrerequisites(licenseType)
+	if len(required) == 0 {
+		return true
+	}
+	for _, h := range held {
+		for _, r := range required {
+			if strings.EqualFold(h, r) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Class a license card is endorsed for
+type LicenseEndorsement struct {
+	Id          uuid.UUID  `json:"id" db:"id"`
+	LicenseID   uuid.UUID  `json:"license_id" db:"license_id"`     // Thẻ bằng lái
+	LicenseType string     `json:"license_type" db:"license_type"` // Hạng (A1, B2, ...)
+	IssueDate   string     `json:"issue_date" db:"issue_date"`     // Ngày cấp hạng
+	ExpiryDate  *string    `json:"expiry_date" db:"expiry_date"`   // Ngày hết hạn, nil khi vô thời hạn
+	Status      string     `json:"status" db:"status"`             // active, expired, pause, revoke
+	AuthorityId *uuid.UUID `json:"authority_id" db:"authority_id"` // Nơi cấp hạng
+	CreatorId   *uuid.UUID `json:"creator_id" db:"creator_id"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+	Active      bool       `json:"active" db:"active"`
+	StatusLabel string     `json:"status_label,omitempty" db:"-"` // Trạng thái theo ngôn ngữ yêu cầu
+}
+
+// Endorsement of the card's own class, issued with the card
+func NewCardEndorsement(dl *DrivingLicense) *LicenseEndorsement {
+	e := &LicenseEndorsement{
+		Id:          uuid.New(),
+		LicenseID:   dl.Id,
+		LicenseType: strings.ToUpper(strings.TrimSpace(dl.LicenseType)),
+		IssueDate:   dl.IssueDate,
+		ExpiryDate:  dl.ExpiryDate,
+		Status:      dl.Status,
+		CreatedAt:   dl.CreatedAt,
+		UpdatedAt:   dl.UpdatedAt,
+		Active:      true,
+	}
+	if e.IssueDate == "" {
+		e.IssueDate = dl.CreatedAt.Format(time.DateOnly)
+	}
+	if e.Status == "" {
+		e.Status = "active"
+	}
+	if dl.AuthorityId != uuid.Nil {
+		e.AuthorityId = &dl.AuthorityId
+	}
+	if dl.CreatorId != uuid.Nil {
+		e.CreatorId = &dl.CreatorId
+	}
+	return e
+}
+
+// Whether the endorsement entitles the holder to drive its class
+func (e *LicenseEndorsement) Valid() bool {
+	return e.Active && e.Status == "active"
+}
+
+func (e *LicenseEndorsement) Localize(lang string) {
+	e.StatusLabel = i18n.Label(lang, i18n.LicenseStatus, e.Status)
+}
+
+// Officer's endorsement of a card for another class, the expiry defaults to the one of the class
+type AddEndorsementRequest struct {
+	LicenseType string `json:"license_type" validate:"required,license_type"`
+	IssueDate   string `json:"issue_date" validate:"omitempty,isodate"`
+	ExpiryDate  string `json:"expiry_date" validate:"omitempty,isodate"`
+}
+
+// Citizen's license card with every class the citizen holds
+type LicenseHolder struct {
+	IdentityNo   string                `json:"identity_no"`
+	FullName     string                `json:"full_name"`
+	DOB          string                `json:"dob"`
+	Card         *DrivingLicense       `json:"card"`         // Thẻ hiện hành, cấp gần nhất
+	Endorsements []*LicenseEndorsement `json:"endorsements"` // Một hạng một dòng, trên mọi thẻ còn hiệu lực
+	Licenses     []*DrivingLicense     `json:"licenses"`     // Các thẻ còn hiệu lực của công dân
+}
+
+// Holder of the licenses, the card is the latest issued one and a class held on several cards is listed once,
+// from the card that endorsed it last
+func NewLicenseHolder(licenses []*DrivingLicense) *LicenseHolder {
+	h := &LicenseHolder{Endorsements: []*LicenseEndorsement{}, Licenses: licenses}
+	byClass := make(map[string]*LicenseEndorsement)
+	for _, dl := range licenses {
+		if h.Card == nil || dl.IssueDate > h.Card.IssueDate {
+			h.Card = dl
+		}
+		for _, e := range dl.Endorsements {
+			if cur, ok := byClass[e.LicenseType]; !ok || e.IssueDate > cur.IssueDate {
+				byClass[e.LicenseType] = e
+			}
+		}
+	}
+	if h.Card != nil {
+		h.IdentityNo, h.FullName, h.DOB = h.Card.IdentityNo, h.Card.Name, h.Card.DOB
+	}
+	for _, e := range byClass {
+		h.Endorsements = append(h.Endorsements, e)
+	}
+	sort.Slice(h.Endorsements, func(i, j int) bool { return h.Endorsements[i].LicenseType < h.Endorsements[j].LicenseType })
+	return h
+}
+
+func (h *LicenseHolder) Localize(lang string) {
+	for _, dl := range h.Licenses {
+		dl.Localize(lang)
+	}
+	for _, e := range h.Endorsements {
+		e.Localize(lang)
+	}
+}
@@ -1,21 +1,26 @@
 package models
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
 	"strings"
 	"time"
 
+	"github.com/adohong4/driving-license/pkg/i18n"
 	"github.com/google/uuid"
 )
 
 type Notification struct {
-	Id         uuid.UUID `json:"id" db:"id" validate:"required"`
-	Code       string    `json:"code" db:"code"`
-	Title      string    `json:"title" db:"title"`
-	Content    string    `json:"content" db:"content"`
-	Type       string    `json:"type" db:"type"`
-	Target     string    `json:"target" db:"target"`           // Đối tượng nhận (type: all/personal/group)
-	TargetUser string    `json:"target_user" db:"target_user"` // CCCD
-	Status     string    `json:"status" db:"status"`           // if user -> status = unread, if all --> status = success
+	Id         uuid.UUID          `json:"id" db:"id" validate:"required"`
+	Code       string             `json:"code" db:"code"`
+	Title      string             `json:"title" db:"title"`
+	Content    string             `json:"content" db:"content"`
+	Type       string             `json:"type" db:"type"`
+	Target     string             `json:"target" db:"target"`           // Đối tượng nhận (type: all/personal/group)
+	TargetUser string             `json:"target_user" db:"target_user"` // CCCD
+	Params     NotificationParams `json:"params,omitempty" db:"params"` // Giá trị của mẫu thông báo theo code
+	Status     string             `json:"status" db:"status"`           // if user -> status = unread, if all --> status = success
 
 	CreatorId  uuid.UUID  `json:"creator_id" db:"creator_id"`
 	ModifierID *uuid.UUID `json:"modifier_id" db:"modifier_id"`
@@ -28,6 +33,11 @@ func (n *Notification) PrepareCreate() error {
 	n.Title = strings.TrimSpace(n.Title)
 	n.Content = strings.TrimSpace(n.Content)
 	n.Target = strings.TrimSpace(n.Target)
+	if n.Params == nil {
+		n.Params = NotificationParams{}
+	}
+	// templated notifications are stored rendered in the default language, read in the reader's one
+	n.Localize(i18n.Default)
 
 	n.Id = uuid.New()
 	n.CreatedAt = time.Now()
@@ -45,6 +55,44 @@ func (n *Notification) PrepareUpdate() error {
 	return nil
 }
 
+// Whether the code names a notification template of the i18n catalogs
+func (n *Notification) Templated() bool {
+	return n.Code != "" && i18n.Has("notification."+n.Code+".title")
+}
+
+// Render the title and content of a templated notification in the response language
+func (n *Notification) Localize(lang string) {
+	if !n.Templated() {
+		return
+	}
+	key := "notification." + n.Code
+	n.Title = i18n.Render(lang, key+".title", n.Params)
+	n.Content = i18n.Render(lang, key+".content", n.Params)
+}
+
+// Placeholder values of a notification template, stored as jsonb
+type NotificationParams map[string]string
+
+func (p NotificationParams) Value() (driver.Value, error) {
+	if p == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(p)
+}
+
+func (p *NotificationParams) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*p = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, p)
+	case string:
+		return json.Unmarshal([]byte(v), p)
+	}
+	return fmt.Errorf("NotificationParams.Scan: unsupported type %T", src)
+}
+
 type NotificationList struct {
 	TotalCount   int             `json:"total_count"`
 	TotalPages   int             `json:"total_pages"`
@@ -53,3 +101,9 @@ type NotificationList struct {
 	HasMore      bool            `json:"has_more"`
 	Notification []*Notification `json:"notifications"`
 }
+
+func (l *NotificationList) Localize(lang string) {
+	for _, n := range l.Notification {
+		n.Localize(lang)
+	}
+}
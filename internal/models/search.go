@@ -0,0 +1,37 @@
+package models
+
+import "github.com/google/uuid"
+
+// Searchable entity types
+const (
+	SearchTypeCitizen = "citizen"
+	SearchTypeLicense = "license"
+	SearchTypeVehicle = "vehicle"
+)
+
+var SearchTypes = []string{SearchTypeCitizen, SearchTypeLicense, SearchTypeVehicle}
+
+// Unified search params
+type SearchQuery struct {
+	Term  string   `json:"q"`
+	Types []string `json:"types"`
+	Limit int      `json:"limit"`
+}
+
+// Search hit, URL links to the entity
+type SearchHit struct {
+	Type     string    `json:"type" db:"type"`
+	Id       uuid.UUID `json:"id" db:"id"`
+	Title    string    `json:"title" db:"title"`       // name, license or plate number
+	Subtitle string    `json:"subtitle" db:"subtitle"` // CCCD, holder or owner
+	Matched  string    `json:"matched" db:"matched"`   // field that matched the term best
+	Score    float64   `json:"score" db:"score"`
+	URL      string    `json:"url" db:"-"`
+}
+
+// Ranked search hits
+type SearchResult struct {
+	Query string       `json:"q"`
+	Types []string     `json:"types"`
+	Hits  []*SearchHit `json:"hits"`
+}
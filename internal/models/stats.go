@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Grouped statistic row
+type TimeSeriesPoint struct {
+	Period time.Time `json:"period" db:"period"`
+	Count  int64     `json:"count" db:"count"`
+	Amount int64     `json:"amount" db:"amount"` // Tổng tiền (VND), chỉ dùng cho vi phạm/tiền phạt
+}
+
+// Time-series statistic response, arrays are aligned with labels for charting
+type TimeSeries struct {
+	Metric      string    `json:"metric"`
+	GroupBy     string    `json:"group_by"`
+	From        time.Time `json:"from"`
+	To          time.Time `json:"to"`
+	Labels      []string  `json:"labels"`
+	Counts      []int64   `json:"counts"`
+	Amounts     []int64   `json:"amounts,omitempty"`
+	Total       int64     `json:"total"`
+	TotalAmount int64     `json:"total_amount,omitempty"`
+}
+
+// Materialized view refresh log row
+type StatsRefresh struct {
+	ViewName    string    `json:"view_name" db:"view_name"`
+	RefreshedAt time.Time `json:"refreshed_at" db:"refreshed_at"`
+}
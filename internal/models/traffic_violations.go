@@ -4,6 +4,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/adohong4/driving-license/pkg/geo"
+	"github.com/adohong4/driving-license/pkg/i18n"
+	"github.com/adohong4/driving-license/pkg/plate"
 	"github.com/google/uuid"
 )
 
@@ -13,9 +16,13 @@ type TrafficViolation struct {
 	Date           time.Time  `json:"date" db:"date"`             // Ngày vi phạm
 	Type           string     `json:"type" db:"type"`             // Loại vi phạm
 	Address        string     `json:"address" db:"address"`
-	Description    string     `json:"description" db:"description"` // Mô tả vi phạm
-	Points         int        `json:"points" db:"points"`           // Số điểm bị trừ
-	FineAmount     int64      `json:"fine_amount" db:"fine_amount"` // Số tiền phạt (VND)
+	Latitude       *float64   `json:"latitude,omitempty" db:"latitude" validate:"omitempty,latitude"`    // Vĩ độ
+	Longitude      *float64   `json:"longitude,omitempty" db:"longitude" validate:"omitempty,longitude"` // Kinh độ
+	Province       string     `json:"province" db:"province"`                                            // Tỉnh/thành phố (chuẩn hóa)
+	District       string     `json:"district" db:"district"`                                            // Quận/huyện (chuẩn hóa)
+	Description    string     `json:"description" db:"description"`                                      // Mô tả vi phạm
+	Points         int        `json:"points" db:"points"`                                                // Số điểm bị trừ
+	FineAmount     int64      `json:"fine_amount" db:"fine_amount"`                                      // Số tiền phạt (VND)
 	ExpiryDate     time.Time  `json:"expiry_date" db:"expiry_date"`
 	Status         string     `json:"status" db:"status"`           // Trạng thái (đã xử lý/chưa xử lý/hủy vi phạm)
 	Version        int        `json:"version" db:"version"`         // Phiên bản, tự động tăng
@@ -24,14 +31,34 @@ type TrafficViolation struct {
 	CreatedAt      time.Time  `json:"created_at" db:"created_at"`   // Thời gian tạo
 	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`   // Thời gian cập nhật
 	Active         bool       `json:"active" db:"active"`
+	TypeLabel      string     `json:"type_label,omitempty" db:"-"`   // Loại vi phạm theo ngôn ngữ yêu cầu
+	StatusLabel    string     `json:"status_label,omitempty" db:"-"` // Trạng thái theo ngôn ngữ yêu cầu
+}
+
+// Canonical plate number of the violating vehicle, an empty plate is left as is
+func (t *TrafficViolation) NormalizePlate() error {
+	t.VehiclePlateNo = strings.TrimSpace(t.VehiclePlateNo)
+	if t.VehiclePlateNo == "" {
+		return nil
+	}
+	canonical, err := plate.Canonical(t.VehiclePlateNo)
+	if err != nil {
+		return err
+	}
+	t.VehiclePlateNo = canonical
+	return nil
 }
 
 // Prepare the traffic violation for creation
 func (t *TrafficViolation) PrepareCreate() error {
-	t.VehiclePlateNo = strings.TrimSpace(t.VehiclePlateNo)
+	if err := t.NormalizePlate(); err != nil {
+		return err
+	}
 	t.Type = strings.TrimSpace(t.Type)
 	t.Description = strings.TrimSpace(t.Description)
 	t.Status = strings.TrimSpace(t.Status)
+	t.Province = geo.NormalizeAdminArea(t.Province)
+	t.District = geo.NormalizeAdminArea(t.District)
 
 	t.Id = uuid.New()
 	t.CreatedAt = time.Now()
@@ -43,15 +70,25 @@ func (t *TrafficViolation) PrepareCreate() error {
 
 // Prepare the traffic violation for updating
 func (t *TrafficViolation) PrepareUpdate() error {
-	t.VehiclePlateNo = strings.TrimSpace(t.VehiclePlateNo)
+	if err := t.NormalizePlate(); err != nil {
+		return err
+	}
 	t.Type = strings.TrimSpace(t.Type)
 	t.Description = strings.TrimSpace(t.Description)
 	t.Status = strings.TrimSpace(t.Status)
+	t.Province = geo.NormalizeAdminArea(t.Province)
+	t.District = geo.NormalizeAdminArea(t.District)
 
 	t.UpdatedAt = time.Now()
 	return nil
 }
 
+// Set the labels of the response language
+func (t *TrafficViolation) Localize(lang string) {
+	t.TypeLabel = i18n.Label(lang, i18n.ViolationType, t.Type)
+	t.StatusLabel = i18n.Label(lang, i18n.ViolationStatus, t.Status)
+}
+
 // All traffic violation response
 type TrafficViolationList struct {
 	TotalCount       int                 `json:"total_count"`
@@ -60,6 +97,13 @@ type TrafficViolationList struct {
 	Size             int                 `json:"size"`
 	HasMore          bool                `json:"has_more"`
 	TrafficViolation []*TrafficViolation `json:"traffic_violation"`
+	NextCursor       string              `json:"next_cursor,omitempty"` // cursor of the next page, set on cursor pages
+}
+
+func (l *TrafficViolationList) Localize(lang string) {
+	for _, t := range l.TrafficViolation {
+		t.Localize(lang)
+	}
 }
 
 type TrafficViolationStats struct {
@@ -78,3 +122,76 @@ type TrafficViolationStatusStats struct {
 	NotOverdueCount   int64  `json:"not_overdue_count" db:"not_overdue_count"`
 	NotOverdueAmount  int64  `json:"not_overdue_amount" db:"not_overdue_amount"`
 }
+
+// Hotspot analytics filter
+type ViolationHotspotQuery struct {
+	From     *time.Time       `json:"from,omitempty"`
+	To       *time.Time       `json:"to,omitempty"`
+	Type     string           `json:"type,omitempty"`
+	Province string           `json:"province,omitempty"`
+	BBox     *geo.BoundingBox `json:"bbox,omitempty"`
+	CellSize float64          `json:"cell_size,omitempty"` // Kích thước ô lưới (độ)
+	Limit    int              `json:"limit,omitempty"`
+}
+
+// Plate read by a road camera, a stolen vehicle raises the same alert as a violation
+type VehicleDetection struct {
+	VehiclePlateNo string    `json:"vehicle_no" validate:"required"`
+	CameraID       string    `json:"camera_id" validate:"required,max=100"`
+	DetectedAt     time.Time `json:"detected_at"` // now when omitted
+	Address        string    `json:"address" validate:"max=500"`
+	Latitude       *float64  `json:"latitude,omitempty" validate:"omitempty,latitude"`
+	Longitude      *float64  `json:"longitude,omitempty" validate:"omitempty,longitude"`
+	Province       string    `json:"province" validate:"max=100"`
+	District       string    `json:"district" validate:"max=100"`
+	Stolen         bool      `json:"stolen"` // set by the server, officers are being alerted
+}
+
+// Canonical plate and area of the detection
+func (d *VehicleDetection) Prepare() error {
+	d.VehiclePlateNo = strings.TrimSpace(d.VehiclePlateNo)
+	if d.VehiclePlateNo != "" {
+		canonical, err := plate.Canonical(d.VehiclePlateNo)
+		if err != nil {
+			return err
+		}
+		d.VehiclePlateNo = canonical
+	}
+	d.CameraID = strings.TrimSpace(d.CameraID)
+	d.Address = strings.TrimSpace(d.Address)
+	d.Province = geo.NormalizeAdminArea(d.Province)
+	d.District = geo.NormalizeAdminArea(d.District)
+	if d.DetectedAt.IsZero() {
+		d.DetectedAt = time.Now()
+	}
+	d.Stolen = false
+	return nil
+}
+
+// Violation count per district
+type DistrictViolationCount struct {
+	Province        string   `db:"province"`
+	District        string   `db:"district"`
+	Count           int64    `db:"count"`
+	TotalFineAmount int64    `db:"total_fine_amount"`
+	Latitude        *float64 `db:"latitude"`  // Tọa độ trung bình
+	Longitude       *float64 `db:"longitude"` // Tọa độ trung bình
+}
+
+// Violation count per heatmap grid cell
+type ViolationGridCell struct {
+	LatIdx int   `db:"lat_idx"`
+	LngIdx int   `db:"lng_idx"`
+	Count  int64 `db:"count"`
+}
+
+// Violation count per road segment (normalized address)
+type RoadSegmentViolationCount struct {
+	Address   string   `db:"address"`
+	Province  string   `db:"province"`
+	District  string   `db:"district"`
+	Type      string   `db:"type"`
+	Count     int64    `db:"count"`
+	Latitude  *float64 `db:"latitude"`
+	Longitude *float64 `db:"longitude"`
+}
@@ -8,15 +8,16 @@ import (
 )
 
 type User struct {
-	Id               uuid.UUID `json:"id" db:"id" validate:"required"`
-	UserAddress      *string   `json:"user_address" db:"user_address"`
-	IdentityNo       string    `json:"identity_no" db:"identity_no"` // CCCD
-	FullName         string    `json:"full_name" db:"full_name"`
-	DateOfBirth      string    `json:"date_of_birth" db:"date_of_birth"`
-	Gender           string    `json:"gender" db:"gender"`
-	Nationality      string    `json:"nationality" db:"nationality"`
-	PlaceOfOrigin    string    `json:"place_of_origin" db:"place_of_origin"`
-	PlaceOfResidence string    `json:"place_of_residence" db:"place_of_residence"`
+	Id               uuid.UUID  `json:"id" db:"id" validate:"required"`
+	UserAddress      *string    `json:"user_address" db:"user_address"`
+	IdentityNo       string     `json:"identity_no" db:"identity_no" validate:"omitempty,cccd,cccd_birth=DateOfBirth,cccd_gender=Gender"` // CCCD
+	FullName         string     `json:"full_name" db:"full_name"`
+	DateOfBirth      string     `json:"date_of_birth" db:"date_of_birth" validate:"omitempty,birthdate"`
+	Gender           string     `json:"gender" db:"gender" validate:"omitempty,gender"`
+	Nationality      string     `json:"nationality" db:"nationality" validate:"omitempty,nationality"`
+	PlaceOfOrigin    string     `json:"place_of_origin" db:"place_of_origin"`
+	PlaceOfResidence string     `json:"place_of_residence" db:"place_of_residence"`
+	AgencyID         *uuid.UUID `json:"agency_id" db:"agency_id"` // Cơ quan công tác, giới hạn địa bàn quản lý
 
 	Active     bool       `json:"active" db:"active"`
 	Role       *string    `json:"role,omitempty" db:"role" validate:"omitempty,lte=20"` // Vai trò (admin, user, etc.)
@@ -30,6 +31,9 @@ type User struct {
 // Prepare user for register
 func (u *User) PrepareCreate() error {
 	u.IdentityNo = strings.TrimSpace(u.IdentityNo)
+	u.DateOfBirth = strings.TrimSpace(u.DateOfBirth)
+	u.Gender = strings.TrimSpace(u.Gender)
+	u.Nationality = strings.TrimSpace(u.Nationality)
 
 	u.Id = uuid.New()
 	u.CreatedAt = time.Now()
@@ -42,6 +46,9 @@ func (u *User) PrepareCreate() error {
 // PrepareUpdate prepares a user for update
 func (u *User) PrepareUpdate() error {
 	u.IdentityNo = strings.TrimSpace(u.IdentityNo)
+	u.DateOfBirth = strings.TrimSpace(u.DateOfBirth)
+	u.Gender = strings.TrimSpace(u.Gender)
+	u.Nationality = strings.TrimSpace(u.Nationality)
 
 	u.UpdatedAt = time.Now()
 	u.Version++
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/adohong4/driving-license/pkg/i18n"
+	"github.com/google/uuid"
+)
+
+// Lifecycle operations of a vehicle
+const (
+	VehicleDeregistered = "deregistered"
+	VehicleStolen       = "stolen"
+	VehicleRecovered    = "recovered"
+	VehicleSeized       = "seized"
+	VehicleReleased     = "released"
+)
+
+// Causes of a deregistration
+const (
+	DeregisterScrapped = "scrapped"
+	DeregisterExported = "exported"
+)
+
+// Lifecycle operation recorded by an officer on behalf of an agency
+type VehicleEvent struct {
+	Id             uuid.UUID `json:"id" db:"id"`
+	VehicleID      uuid.UUID `json:"vehicle_id" db:"vehicle_id"`
+	VehiclePlateNo string    `json:"vehicle_no" db:"vehicle_no"`
+	Event          string    `json:"event" db:"event"`             // deregistered, stolen, recovered, seized, released
+	Cause          string    `json:"cause" db:"cause"`             // scrapped, exported on deregistrations
+	Reason         string    `json:"reason" db:"reason"`           // Lý do
+	EventDate      time.Time `json:"event_date" db:"event_date"`   // Ngày xảy ra
+	AgencyID       uuid.UUID `json:"agency_id" db:"agency_id"`     // Cơ quan thực hiện
+	AgencyName     string    `json:"agency_name" db:"agency_name"` // Tên cơ quan
+	OfficerID      uuid.UUID `json:"officer_id" db:"officer_id"`   // Cán bộ ghi nhận
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	EventLabel     string    `json:"event_label,omitempty" db:"-"` // Thao tác theo ngôn ngữ yêu cầu
+	CauseLabel     string    `json:"cause_label,omitempty" db:"-"` // Nguyên nhân theo ngôn ngữ yêu cầu
+}
+
+func (e *VehicleEvent) Localize(lang string) {
+	e.EventLabel = i18n.Label(lang, i18n.VehicleEvent, e.Event)
+	if e.Cause != "" {
+		e.CauseLabel = i18n.Label(lang, i18n.DeregisterCause, e.Cause)
+	}
+}
+
+// Lifecycle events of a vehicle, latest first
+type VehicleEventList struct {
+	Events []*VehicleEvent `json:"events"`
+}
+
+func (l *VehicleEventList) Localize(lang string) {
+	for _, e := range l.Events {
+		e.Localize(lang)
+	}
+}
+
+// Request of a lifecycle operation, the date defaults to today and the cause is required to deregister
+type VehicleEventRequest struct {
+	Cause    string    `json:"cause" validate:"omitempty,oneof=scrapped exported"`
+	Reason   string    `json:"reason" validate:"required,max=500"`
+	Date     string    `json:"date" validate:"omitempty,isodate"`
+	AgencyID uuid.UUID `json:"agency_id" validate:"required"`
+}
+
+// Event of the request on the vehicle
+func (r *VehicleEventRequest) Event(v *VehicleRegistration, event string, officerID uuid.UUID) *VehicleEvent {
+	date := time.Now()
+	if d, err := time.Parse(time.DateOnly, strings.TrimSpace(r.Date)); err == nil {
+		date = d
+	}
+	return &VehicleEvent{
+		Id:             uuid.New(),
+		VehicleID:      v.ID,
+		VehiclePlateNo: v.VehiclePlateNo,
+		Event:          event,
+		Cause:          r.Cause,
+		Reason:         strings.TrimSpace(r.Reason),
+		EventDate:      date,
+		AgencyID:       r.AgencyID,
+		OfficerID:      officerID,
+		CreatedAt:      time.Now(),
+	}
+}
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/adohong4/driving-license/pkg/i18n"
+	"github.com/google/uuid"
+)
+
+// How an owner came to own the vehicle
+const (
+	OwnershipRegistration = "registration" // first owner, registered the vehicle
+	OwnershipTransfer     = "transfer"     // approved ownership transfer
+	OwnershipCorrection   = "correction"   // owner changed by editing the vehicle document
+)
+
+// One owner in the chain of title of a vehicle, ToDate is nil for the current owner
+type VehicleOwnership struct {
+	Id               uuid.UUID  `json:"id" db:"id"`
+	VehicleID        uuid.UUID  `json:"vehicle_id" db:"vehicle_id"`
+	OwnerID          *uuid.UUID `json:"owner_id" db:"owner_id"`                   // ID chủ sở hữu
+	OwnerName        string     `json:"owner_name" db:"owner_name"`               // Chủ xe
+	IdentityNo       string     `json:"identity_no" db:"identity_no"`             // CCCD chủ xe
+	VehiclePlateNo   string     `json:"vehicle_no" db:"vehicle_no"`               // Biển số trong thời gian sở hữu
+	FromDate         time.Time  `json:"from_date" db:"from_date"`                 // Bắt đầu sở hữu
+	ToDate           *time.Time `json:"to_date" db:"to_date"`                     // Kết thúc sở hữu, null nếu là chủ hiện tại
+	Reason           string     `json:"reason" db:"reason"`                       // registration, transfer, correction
+	TransferID       *uuid.UUID `json:"transfer_id" db:"transfer_id"`             // Hồ sơ sang tên
+	BlockchainTxHash string     `json:"blockchain_txhash" db:"blockchain_txhash"` // Mã lưu ở blockchain
+	CreatorId        *uuid.UUID `json:"creator_id" db:"creator_id"`
+	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
+	ReasonLabel      string     `json:"reason_label,omitempty" db:"-"` // Lý do theo ngôn ngữ yêu cầu
+}
+
+// Ownership of the registering owner, from the issue date of the document when it is a date and from now otherwise
+func NewRegistrationOwnership(v *VehicleRegistration) *VehicleOwnership {
+	from := v.CreatedAt
+	if from.IsZero() {
+		from = time.Now()
+	}
+	if d := strings.TrimSpace(v.IssueDate); len(d) >= len(time.DateOnly) {
+		if t, err := time.Parse(time.DateOnly, d[:len(time.DateOnly)]); err == nil {
+			from = t
+		}
+	}
+	o := &VehicleOwnership{
+		Id:               uuid.New(),
+		VehicleID:        v.ID,
+		OwnerID:          v.OwnerID,
+		OwnerName:        v.OwnerName,
+		VehiclePlateNo:   v.VehiclePlateNo,
+		FromDate:         from,
+		Reason:           OwnershipRegistration,
+		BlockchainTxHash: v.BlockchainTxHash,
+		CreatedAt:        time.Now(),
+	}
+	if v.CreatorId != uuid.Nil {
+		o.CreatorId = &v.CreatorId
+	}
+	return o
+}
+
+func (o *VehicleOwnership) Localize(lang string) {
+	o.ReasonLabel = i18n.Label(lang, i18n.OwnershipReason, o.Reason)
+}
+
+// Every owner of a vehicle, oldest first
+type ChainOfTitle struct {
+	Vehicle *VehicleRegistration `json:"vehicle"`
+	Owners  []*VehicleOwnership  `json:"owners"`
+}
+
+func (c *ChainOfTitle) Localize(lang string) {
+	if c.Vehicle != nil {
+		c.Vehicle.Localize(lang)
+	}
+	for _, o := range c.Owners {
+		o.Localize(lang)
+	}
+}
+
+// Owner of the vehicle at the time of a violation
+type ViolationOwner struct {
+	Violation *TrafficViolation `json:"violation"`
+	Owner     *VehicleOwnership `json:"owner"` // null when no recorded owner held the plate on the violation date
+}
+
+func (v *ViolationOwner) Localize(lang string) {
+	if v.Violation != nil {
+		v.Violation.Localize(lang)
+	}
+	if v.Owner != nil {
+		v.Owner.Localize(lang)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/adohong4/driving-license/pkg/i18n"
+	"github.com/adohong4/driving-license/pkg/plate"
 	"github.com/google/uuid"
 )
 
@@ -34,11 +36,31 @@ type VehicleRegistration struct {
 	CreatedAt         time.Time  `json:"created_at" db:"created_at"`                 // Thời gian tạo
 	UpdatedAt         time.Time  `json:"updated_at" db:"updated_at"`                 // Thời gian cập nhật
 	Active            bool       `json:"active" db:"active"`
+	Stolen            bool       `json:"stolen" db:"stolen"`                   // Đang bị báo mất cắp
+	Seized            bool       `json:"seized" db:"seized"`                   // Đang bị tạm giữ
+	DeregisteredAt    *time.Time `json:"deregistered_at" db:"deregistered_at"` // Ngày thu hồi đăng ký
+	TypeVehicleLabel  string     `json:"type_vehicle_label,omitempty" db:"-"`  // Loại phương tiện theo ngôn ngữ yêu cầu
+}
+
+// Canonical plate number, e.g. "30a 12345" becomes "30A-123.45". An empty plate is left as is
+func (v *VehicleRegistration) NormalizePlate() error {
+	v.VehiclePlateNo = strings.TrimSpace(v.VehiclePlateNo)
+	if v.VehiclePlateNo == "" {
+		return nil
+	}
+	canonical, err := plate.Canonical(v.VehiclePlateNo)
+	if err != nil {
+		return err
+	}
+	v.VehiclePlateNo = canonical
+	return nil
 }
 
 // Prepare the vehicle document for creation
 func (v *VehicleRegistration) PrepareCreate() error {
-	v.VehiclePlateNo = strings.TrimSpace(v.VehiclePlateNo) // eliminate spaces at the begininge and the end
+	if err := v.NormalizePlate(); err != nil {
+		return err
+	}
 	v.ChassisNo = strings.TrimSpace(v.ChassisNo)
 	v.EngineNo = strings.TrimSpace(v.EngineNo)
 	v.Brand = strings.TrimSpace(v.Brand)
@@ -60,7 +82,9 @@ func (v *VehicleRegistration) PrepareCreate() error {
 
 // Prepare the vehicle document for updating
 func (v *VehicleRegistration) PrepareUpdate() error {
-	v.VehiclePlateNo = strings.TrimSpace(v.VehiclePlateNo)
+	if err := v.NormalizePlate(); err != nil {
+		return err
+	}
 	v.ChassisNo = strings.TrimSpace(v.ChassisNo)
 	v.EngineNo = strings.TrimSpace(v.EngineNo)
 	v.Brand = strings.TrimSpace(v.Brand)
@@ -75,6 +99,11 @@ func (v *VehicleRegistration) PrepareUpdate() error {
 	return nil
 }
 
+// Set the labels of the response language
+func (v *VehicleRegistration) Localize(lang string) {
+	v.TypeVehicleLabel = i18n.Label(lang, i18n.VehicleType, v.TypeVehicle)
+}
+
 // All vehicle document response
 type VehicleRegistrationList struct {
 	TotalCount      int                    `json:"total_count"`
@@ -83,6 +112,13 @@ type VehicleRegistrationList struct {
 	Size            int                    `json:"size"`
 	HasMore         bool                   `json:"has_more"`
 	VehicleDocument []*VehicleRegistration `json:"vehicle_registration"`
+	NextCursor      string                 `json:"next_cursor,omitempty"` // cursor of the next page, set on cursor pages
+}
+
+func (l *VehicleRegistrationList) Localize(lang string) {
+	for _, v := range l.VehicleDocument {
+		v.Localize(lang)
+	}
 }
 
 // Confirm Blockchain Request
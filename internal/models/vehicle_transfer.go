@@ -0,0 +1,118 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/adohong4/driving-license/pkg/i18n"
+	"github.com/google/uuid"
+)
+
+// Transfer statuses, initiated by the seller, accepted by the buyer and approved or rejected by an officer
+const (
+	TransferInitiated = "initiated"
+	TransferAccepted  = "accepted"
+	TransferApproved  = "approved"
+	TransferRejected  = "rejected"
+	TransferCancelled = "cancelled"
+)
+
+// Ownership transfer of a registered vehicle
+type VehicleTransfer struct {
+	Id               uuid.UUID  `json:"id" db:"id"`
+	VehicleID        uuid.UUID  `json:"vehicle_id" db:"vehicle_id"`
+	VehiclePlateNo   string     `json:"vehicle_no" db:"vehicle_no"`                 // Biển số lúc khởi tạo
+	SellerID         uuid.UUID  `json:"seller_id" db:"seller_id"`                   // Người bán (user)
+	SellerOwnerID    *uuid.UUID `json:"seller_owner_id" db:"seller_owner_id"`       // Chủ sở hữu cũ của phương tiện
+	SellerName       string     `json:"seller_name" db:"seller_name"`               // Tên người bán
+	SellerIdentityNo string     `json:"seller_identity_no" db:"seller_identity_no"` // CCCD người bán
+	BuyerIdentityNo  string     `json:"buyer_identity_no" db:"buyer_identity_no"`   // CCCD người mua
+	BuyerID          *uuid.UUID `json:"buyer_id" db:"buyer_id"`                     // Người mua (user), khi đã chấp nhận
+	BuyerOwnerID     *uuid.UUID `json:"buyer_owner_id" db:"buyer_owner_id"`         // Chủ sở hữu mới của phương tiện
+	BuyerName        string     `json:"buyer_name" db:"buyer_name"`                 // Tên người mua
+	NewPlateNo       *string    `json:"new_vehicle_no" db:"new_vehicle_no"`         // Biển số mới, nếu được cấp
+	Status           string     `json:"status" db:"status"`                         // initiated, accepted, approved, rejected, cancelled
+	Note             string     `json:"note" db:"note"`                             // Ghi chú của người bán
+	Reason           string     `json:"reason" db:"reason"`                         // Lý do từ chối/hủy
+	OfficerID        *uuid.UUID `json:"officer_id" db:"officer_id"`                 // Cán bộ phê duyệt
+	AcceptedAt       *time.Time `json:"accepted_at" db:"accepted_at"`
+	DecidedAt        *time.Time `json:"decided_at" db:"decided_at"`
+	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at" db:"updated_at"`
+	StatusLabel      string     `json:"status_label,omitempty" db:"-"` // Trạng thái theo ngôn ngữ yêu cầu
+}
+
+// Prepare the transfer for creation
+func (t *VehicleTransfer) PrepareCreate() error {
+	t.BuyerIdentityNo = strings.TrimSpace(t.BuyerIdentityNo)
+	t.Note = strings.TrimSpace(t.Note)
+
+	t.Id = uuid.New()
+	t.Status = TransferInitiated
+	t.CreatedAt = time.Now()
+	t.UpdatedAt = time.Now()
+	return nil
+}
+
+// Whether the transfer still awaits the buyer or the officer
+func (t *VehicleTransfer) Open() bool {
+	return t.Status == TransferInitiated || t.Status == TransferAccepted
+}
+
+func (t *VehicleTransfer) Localize(lang string) {
+	t.StatusLabel = i18n.Label(lang, i18n.TransferStatus, t.Status)
+}
+
+// All vehicle transfer response
+type VehicleTransferList struct {
+	TotalCount int                `json:"total_count"`
+	TotalPages int                `json:"total_pages"`
+	Page       int                `json:"page"`
+	Size       int                `json:"size"`
+	HasMore    bool               `json:"has_more"`
+	Transfers  []*VehicleTransfer `json:"transfers"`
+	NextCursor string             `json:"next_cursor,omitempty"` // cursor of the next page, set on cursor pages
+}
+
+func (l *VehicleTransferList) Localize(lang string) {
+	for _, t := range l.Transfers {
+		t.Localize(lang)
+	}
+}
+
+// Seller's request to transfer a vehicle
+type InitiateTransferRequest struct {
+	VehicleID       uuid.UUID `json:"vehicle_id" validate:"required"`
+	BuyerIdentityNo string    `json:"buyer_identity_no" validate:"required,cccd"`
+	Note            string    `json:"note" validate:"omitempty,max=500"`
+}
+
+// Buyer's acceptance, the identity number must be the one the seller named
+type AcceptTransferRequest struct {
+	IdentityNo string `json:"identity_no" validate:"required,cccd"`
+}
+
+// Officer's approval, a new plate is issued when given
+type ApproveTransferRequest struct {
+	NewPlateNo string `json:"new_vehicle_no"`
+}
+
+// Reason of a rejection or cancellation
+type TransferReasonRequest struct {
+	Reason string `json:"reason" validate:"omitempty,max=500"`
+}
+
+// What keeps a vehicle from being transferred
+type TransferBlockers struct {
+	UnpaidViolations  int     `json:"unpaid_violations" db:"unpaid_violations"`
+	UnpaidFines       int64   `json:"unpaid_fines" db:"unpaid_fines"`
+	InspectionExpiry  *string `json:"inspection_expiry" db:"inspection_expiry"`
+	InspectionExpired bool    `json:"inspection_expired" db:"inspection_expired"` // vehicles without an inspection date are not blocked
+	Stolen            bool    `json:"stolen" db:"stolen"`
+	Seized            bool    `json:"seized" db:"seized"`
+}
+
+// Whether nothing blocks the transfer
+func (b *TransferBlockers) Clear() bool {
+	return b.UnpaidViolations == 0 && !b.InspectionExpired && !b.Stolen && !b.Seized
+}
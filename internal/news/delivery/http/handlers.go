@@ -30,7 +30,7 @@ func NewsHandlers(cfg *config.Config, newsUC news.UseCase, log logger.Logger) ne
 // @Produce json
 // @Param news body models.News true "News object"
 // @Success 201 {object} models.News
-// @Failure 400,401,500 {object} httpErrors.RestError
+// @Failure 400,401,500 {object} httpErrors.Problem
 // @Security JWT
 // @Router /news [post]
 func (h *newsHandlers) Create() echo.HandlerFunc {
@@ -56,7 +56,7 @@ func (h *newsHandlers) Create() echo.HandlerFunc {
 // @Param id path string true "News ID"
 // @Param news body models.News true "News object"
 // @Success 200 {object} models.News
-// @Failure 400,401,500 {object} httpErrors.RestError
+// @Failure 400,401,500 {object} httpErrors.Problem
 // @Security JWT
 // @Router /news/{id} [put]
 func (h *newsHandlers) Update() echo.HandlerFunc {
@@ -86,7 +86,7 @@ func (h *newsHandlers) Update() echo.HandlerFunc {
 // @Produce json
 // @Param id path string true "News ID"
 // @Success 200 {object} models.News
-// @Failure 401,500 {object} httpErrors.RestError
+// @Failure 401,500 {object} httpErrors.Problem
 // @Security JWT
 // @Router /news/{id} [delete]
 func (h *newsHandlers) Delete() echo.HandlerFunc {
@@ -121,7 +121,7 @@ func (h *newsHandlers) Delete() echo.HandlerFunc {
 // @Produce json
 // @Param id path string true "News ID"
 // @Success 200 {object} models.News
-// @Failure 404,500 {object} httpErrors.RestError
+// @Failure 404,500 {object} httpErrors.Problem
 // @Router /news/{id} [get]
 func (h *newsHandlers) FindById() echo.HandlerFunc {
 	return func(c echo.Context) error {
@@ -144,6 +144,7 @@ func (h *newsHandlers) FindById() echo.HandlerFunc {
 // @Produce json
 // @Param page query int false "Page" default(1)
 // @Param size query int false "Size" default(10)
+// @Param sort query string false "Sort fields, comma separated, - prefix for descending, e.g. -created_at"
 // @Success 200 {object} models.NewsList
 // @Router /news [get]
 func (h *newsHandlers) FindAll() echo.HandlerFunc {
@@ -65,13 +65,18 @@ func (r *newsRepo) FindById(ctx context.Context, id uuid.UUID) (*models.News, er
 }
 
 func (r *newsRepo) FindAll(ctx context.Context, pq *utils.PaginationQuery) (*models.NewsList, error) {
+	lc, err := pq.ListClause(newsListSpec, 0)
+	if err != nil {
+		return nil, err
+	}
+
 	var total int
-	if err := r.db.GetContext(ctx, &total, getTotalCountQuery); err != nil {
+	if err := r.db.GetContext(ctx, &total, lc.Count(getTotalCountQuery), lc.Args()...); err != nil {
 		return nil, errors.Wrap(err, "newsRepo.FindAll.total")
 	}
 
 	var items []*models.News
-	err := r.db.SelectContext(ctx, &items, getAllNewsQuery, pq.GetOffset(), pq.GetLimit())
+	err = r.db.SelectContext(ctx, &items, lc.Page(getAllNewsQuery), lc.PageArgs(pq)...)
 	if err != nil {
 		return nil, errors.Wrap(err, "newsRepo.FindAll.select")
 	}
@@ -1,5 +1,23 @@
 package repository
 
+import "github.com/adohong4/driving-license/pkg/utils"
+
+// Filters and sorts accepted by the news list, filter and sort clauses are appended to the queries below
+var newsListSpec = &utils.ListSpec{
+	Fields: map[string]utils.ListField{
+		"code":       {Column: "code", Type: utils.FilterExact},
+		"title":      {Column: "title", Type: utils.FilterText, Sortable: true},
+		"category":   {Column: "category", Type: utils.FilterExact, Sortable: true},
+		"author":     {Column: "author", Type: utils.FilterText, Sortable: true},
+		"type":       {Column: "type", Type: utils.FilterExact, Sortable: true},
+		"status":     {Column: "status", Type: utils.FilterExact, Sortable: true},
+		"view":       {Column: "view", Type: utils.FilterNumber, Sortable: true},
+		"created_at": {Column: "created_at", Type: utils.FilterDate, Sortable: true},
+		"updated_at": {Column: "updated_at", Type: utils.FilterDate, Sortable: true},
+	},
+	DefaultSort: "-created_at",
+}
+
 const (
 	createNewsQuery = `
         INSERT INTO news (
@@ -53,8 +71,6 @@ const (
 	getAllNewsQuery = `
         SELECT * FROM news
         WHERE active = true
-        ORDER BY created_at DESC
-        OFFSET $1 LIMIT $2
     `
 
 	getTotalCountQuery = `
@@ -30,9 +30,9 @@ func NewNotificationHandlers(cfg *config.Config, notificationUC notification.Use
 // @Produce      json
 // @Param        notification  body      models.Notification  true  "Notification data"
 // @Success      200           {object}  models.Notification
-// @Failure      400           {object}  httpErrors.RestError  "Invalid request body or validation error"
-// @Failure      401           {object}  httpErrors.RestError  "Unauthorized"
-// @Failure      500           {object}  httpErrors.RestError  "Internal server error"
+// @Failure      400           {object}  httpErrors.Problem    "Invalid request body or validation error"
+// @Failure      401           {object}  httpErrors.Problem    "Unauthorized"
+// @Failure      500           {object}  httpErrors.Problem    "Internal server error"
 // @Security     JWT
 // @Router       /noti/create [post]
 func (h *notificationHandlers) CreateNotification() echo.HandlerFunc {
@@ -78,10 +78,10 @@ func (h *notificationHandlers) CreateNotification() echo.HandlerFunc {
 // @Param        id            path      string               true  "Notification ID (UUID)"
 // @Param        notification  body      models.Notification  true  "Updated notification data"
 // @Success      200           {object}  models.Notification
-// @Failure      400           {object}  httpErrors.RestError
-// @Failure      401           {object}  httpErrors.RestError
-// @Failure      404           {object}  httpErrors.RestError  "Notification not found"
-// @Failure      500           {object}  httpErrors.RestError
+// @Failure      400           {object}  httpErrors.Problem
+// @Failure      401           {object}  httpErrors.Problem
+// @Failure      404           {object}  httpErrors.Problem    "Notification not found"
+// @Failure      500           {object}  httpErrors.Problem
 // @Security     JWT
 // @Router       /noti/{id} [put]
 func (h *notificationHandlers) UpdateNotification() echo.HandlerFunc {
@@ -117,10 +117,10 @@ func (h *notificationHandlers) UpdateNotification() echo.HandlerFunc {
 // @Produce      json
 // @Param        id  path      string  true  "Notification ID (UUID)"
 // @Success      200  {object}  models.Notification
-// @Failure      400  {object}  httpErrors.RestError
-// @Failure      401  {object}  httpErrors.RestError
-// @Failure      404  {object}  httpErrors.RestError
-// @Failure      500  {object}  httpErrors.RestError
+// @Failure      400  {object}  httpErrors.Problem
+// @Failure      401  {object}  httpErrors.Problem
+// @Failure      404  {object}  httpErrors.Problem
+// @Failure      500  {object}  httpErrors.Problem
 // @Security     JWT
 // @Router       /noti/{id} [delete]
 func (h *notificationHandlers) DeleteNotification() echo.HandlerFunc {
@@ -155,9 +155,10 @@ func (h *notificationHandlers) DeleteNotification() echo.HandlerFunc {
 // @Produce      json
 // @Param        page  query     int  false  "Page number (default: 1)"
 // @Param        size  query     int  false  "Page size (default: 10)"
+// @Param        sort  query     string false  "Sort fields, comma separated, - prefix for descending, e.g. -created_at"
 // @Success      200   {object}  models.NotificationList
-// @Failure      400   {object}  httpErrors.RestError
-// @Failure      500   {object}  httpErrors.RestError
+// @Failure      400   {object}  httpErrors.Problem
+// @Failure      500   {object}  httpErrors.Problem
 // @Router       /noti/getAll [get]
 func (h *notificationHandlers) GetNotification() echo.HandlerFunc {
 	return func(c echo.Context) error {
@@ -184,9 +185,9 @@ func (h *notificationHandlers) GetNotification() echo.HandlerFunc {
 // @Produce      json
 // @Param        id   path      string  true  "Notification ID (UUID)"
 // @Success      200  {object}  models.Notification
-// @Failure      400  {object}  httpErrors.RestError
-// @Failure      404  {object}  httpErrors.RestError
-// @Failure      500  {object}  httpErrors.RestError
+// @Failure      400  {object}  httpErrors.Problem
+// @Failure      404  {object}  httpErrors.Problem
+// @Failure      500  {object}  httpErrors.Problem
 // @Router       /noti/{id} [get]
 func (h *notificationHandlers) GetNotificationById() echo.HandlerFunc {
 	return func(c echo.Context) error {
@@ -214,9 +215,10 @@ func (h *notificationHandlers) GetNotificationById() echo.HandlerFunc {
 // @Param        title  query     string  true   "Title keyword to search"
 // @Param        page   query     int     false  "Page number (default: 1)"
 // @Param        size   query     int     false  "Page size (default: 10)"
+// @Param        sort   query     string  false  "Sort fields, comma separated, - prefix for descending, e.g. -created_at"
 // @Success      200    {object}  models.NotificationList
-// @Failure      400    {object}  httpErrors.RestError
-// @Failure      500    {object}  httpErrors.RestError
+// @Failure      400    {object}  httpErrors.Problem
+// @Failure      500    {object}  httpErrors.Problem
 // @Router       /noti/search [get]
 func (h *notificationHandlers) SearchNotificationByTitle() echo.HandlerFunc {
 	return func(c echo.Context) error {
@@ -244,10 +246,11 @@ func (h *notificationHandlers) SearchNotificationByTitle() echo.HandlerFunc {
 // @Produce      json
 // @Param        page  query     int     false  "Page number (default: 1)"
 // @Param        size  query     int     false  "Page size (default: 10)"
+// @Param        sort  query     string  false  "Sort fields, comma separated, - prefix for descending, e.g. -created_at"
 // @Success      200   {object}  models.NotificationList
-// @Failure      400   {object}  httpErrors.RestError
-// @Failure      401   {object}  httpErrors.RestError
-// @Failure      500   {object}  httpErrors.RestError
+// @Failure      400   {object}  httpErrors.Problem
+// @Failure      401   {object}  httpErrors.Problem
+// @Failure      500   {object}  httpErrors.Problem
 // @Security     JWT
 // @Router       /noti/me [get]
 func (h *notificationHandlers) GetMyNotifications() echo.HandlerFunc {
@@ -275,11 +278,11 @@ func (h *notificationHandlers) GetMyNotifications() echo.HandlerFunc {
 // @Produce      json
 // @Param        id    path      string  true   "Notification ID"
 // @Success      200   {object}  models.Notification
-// @Failure      400   {object}  httpErrors.RestError
-// @Failure      401   {object}  httpErrors.RestError
-// @Failure      403   {object}  httpErrors.RestError
-// @Failure      404   {object}  httpErrors.RestError
-// @Failure      500   {object}  httpErrors.RestError
+// @Failure      400   {object}  httpErrors.Problem
+// @Failure      401   {object}  httpErrors.Problem
+// @Failure      403   {object}  httpErrors.Problem
+// @Failure      404   {object}  httpErrors.Problem
+// @Failure      500   {object}  httpErrors.Problem
 // @Security     JWT
 // @Router       /noti/me/{id} [get]
 func (h *notificationHandlers) GetMyNotificationByID() echo.HandlerFunc {
@@ -11,6 +11,7 @@ import (
 
 type Repository interface {
 	CreateNotification(ctx context.Context, db *models.Notification) (*models.Notification, error)
+	CreateOfficerNotifications(ctx context.Context, db *models.Notification, areas []*models.JurisdictionArea) (int64, error)
 	UpdateNotification(ctx context.Context, db *models.Notification) (*models.Notification, error)
 	DeleteNotification(ctx context.Context, db *models.Notification) (*models.Notification, error)
 	GetNotification(ctx context.Context, pq *utils.PaginationQuery) (*models.NotificationList, error)
@@ -3,6 +3,8 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/adohong4/driving-license/internal/models"
@@ -24,13 +26,38 @@ func NewNotificationRepo(db *sqlx.DB) notification.Repository {
 func (r *notificationRepo) CreateNotification(ctx context.Context, db *models.Notification) (*models.Notification, error) {
 	n := &models.Notification{}
 	if err := r.db.QueryRowxContext(ctx, createNotificationQuery,
-		db.Id, db.Code, db.Title, db.Content, db.Type, db.Target, db.TargetUser, db.Status, db.CreatorId, db.CreatedAt, db.UpdatedAt, db.Active,
+		db.Id, db.Code, db.Title, db.Content, db.Type, db.Target, db.TargetUser, db.Params, db.Status, db.CreatorId, db.CreatedAt, db.UpdatedAt, db.Active,
 	).StructScan(n); err != nil {
 		return nil, errors.Wrap(err, "notificationRepo.CreateNotification.StructScan")
 	}
 	return n, nil
 }
 
+// Notify every officer whose agency covers one of the areas in a single insert, returns the number of officers notified.
+// Areas without a district cover the whole province
+func (r *notificationRepo) CreateOfficerNotifications(ctx context.Context, db *models.Notification, areas []*models.JurisdictionArea) (int64, error) {
+	if len(areas) == 0 {
+		return 0, nil
+	}
+
+	args := []interface{}{db.Code, db.Title, db.Content, db.Type, db.Target, db.Params, db.Status, db.CreatorId, db.CreatedAt}
+	conds := make([]string, 0, len(areas))
+	for _, a := range areas {
+		args = append(args, a.Province, a.District)
+		conds = append(conds, fmt.Sprintf("f_in_jurisdiction(u.agency_id, $%d, NULLIF($%d, ''))", len(args)-1, len(args)))
+	}
+
+	res, err := r.db.ExecContext(ctx, createOfficerNotificationsQuery+" AND ("+strings.Join(conds, " OR ")+")", args...)
+	if err != nil {
+		return 0, errors.Wrap(err, "notificationRepo.CreateOfficerNotifications.ExecContext")
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "notificationRepo.CreateOfficerNotifications.RowsAffected")
+	}
+	return n, nil
+}
+
 func (r *notificationRepo) UpdateNotification(ctx context.Context, db *models.Notification) (*models.Notification, error) {
 	n := &models.Notification{}
 	if err := r.db.QueryRowxContext(ctx, updateNotificationQuery,
@@ -52,8 +79,13 @@ func (r *notificationRepo) DeleteNotification(ctx context.Context, db *models.No
 }
 
 func (r *notificationRepo) GetNotification(ctx context.Context, pq *utils.PaginationQuery) (*models.NotificationList, error) {
+	lc, err := pq.ListClause(notificationListSpec.WithDefaultSort("updated_at,created_at"), 0)
+	if err != nil {
+		return nil, err
+	}
+
 	var totalCount int
-	if err := r.db.GetContext(ctx, &totalCount, getTotalCount); err != nil {
+	if err := r.db.GetContext(ctx, &totalCount, lc.Count(getTotalCount), lc.Args()...); err != nil {
 		return nil, errors.Wrap(err, "NotificationRepo.GetNotification.GetContext.totalCount")
 	}
 
@@ -69,7 +101,7 @@ func (r *notificationRepo) GetNotification(ctx context.Context, pq *utils.Pagina
 	}
 
 	var newNotifications = make([]*models.Notification, 0, pq.GetSize())
-	rows, err := r.db.QueryxContext(ctx, getNotification, pq.GetOffset(), pq.GetLimit())
+	rows, err := r.db.QueryxContext(ctx, lc.Page(getNotification), lc.PageArgs(pq)...)
 	if err != nil {
 		return nil, errors.Wrap(err, "NotificationRepo.GetNotification.QueryxContext")
 	}
@@ -105,8 +137,13 @@ func (r *notificationRepo) GetNotificationByID(ctx context.Context, ID uuid.UUID
 }
 
 func (r *notificationRepo) SearchNotificationByTitle(ctx context.Context, title string, pq *utils.PaginationQuery) (*models.NotificationList, error) {
+	lc, err := pq.ListClause(notificationListSpec, 1, "title")
+	if err != nil {
+		return nil, err
+	}
+
 	var totalCount int
-	if err := r.db.GetContext(ctx, &totalCount, findByTitleCount, title); err != nil {
+	if err := r.db.GetContext(ctx, &totalCount, lc.Count(findByTitleCount), lc.Args(title)...); err != nil {
 		return nil, errors.Wrap(err, "NotificationRepo.SearchNotificationByTitle.GetContext.totalCount")
 	}
 
@@ -122,7 +159,7 @@ func (r *notificationRepo) SearchNotificationByTitle(ctx context.Context, title
 	}
 
 	var NewNotifications = make([]*models.Notification, 0, pq.GetSize())
-	rows, err := r.db.QueryxContext(ctx, searchByTitleQuery, title, pq.GetOffset(), pq.GetLimit())
+	rows, err := r.db.QueryxContext(ctx, lc.Page(searchByTitleQuery), lc.PageArgs(pq, title)...)
 	if err != nil {
 		return nil, errors.Wrap(err, "NotificationRepo.SearchNotificationByTitle.QueryxContext")
 	}
@@ -151,8 +188,13 @@ func (r *notificationRepo) SearchNotificationByTitle(ctx context.Context, title
 }
 
 func (r *notificationRepo) GetNotificationsForUser(ctx context.Context, userCreatedAt time.Time, identityNo string, pq *utils.PaginationQuery) (*models.NotificationList, error) {
+	lc, err := pq.ListClause(notificationListSpec, 2, "target", "target_user")
+	if err != nil {
+		return nil, err
+	}
+
 	var totalCount int
-	if err := r.db.GetContext(ctx, &totalCount, getTotalNotificationsForUserCount, userCreatedAt, identityNo); err != nil {
+	if err := r.db.GetContext(ctx, &totalCount, lc.Count(getTotalNotificationsForUserCount), lc.Args(userCreatedAt, identityNo)...); err != nil {
 		return nil, errors.Wrap(err, "notificationRepo.GetNotificationsForUser.totalCount")
 	}
 
@@ -168,7 +210,7 @@ func (r *notificationRepo) GetNotificationsForUser(ctx context.Context, userCrea
 	}
 
 	var notifications []*models.Notification
-	rows, err := r.db.QueryxContext(ctx, getNotificationsForUser, userCreatedAt, identityNo, pq.GetOffset(), pq.GetLimit())
+	rows, err := r.db.QueryxContext(ctx, lc.Page(getNotificationsForUser), lc.PageArgs(pq, userCreatedAt, identityNo)...)
 	if err != nil {
 		return nil, errors.Wrap(err, "notificationRepo.GetNotificationsForUser.QueryxContext")
 	}
@@ -1,13 +1,41 @@
 package repository
 
+import "github.com/adohong4/driving-license/pkg/utils"
+
+// Filters and sorts accepted by the notification lists, filter and sort clauses are appended to the queries below
+var notificationListSpec = &utils.ListSpec{
+	Fields: map[string]utils.ListField{
+		"code":        {Column: "code", Type: utils.FilterExact},
+		"title":       {Column: "title", Type: utils.FilterText, Sortable: true},
+		"type":        {Column: "type", Type: utils.FilterExact, Sortable: true},
+		"target":      {Column: "target", Type: utils.FilterExact, Sortable: true},
+		"target_user": {Column: "target_user", Type: utils.FilterExact},
+		"status":      {Column: "status", Type: utils.FilterExact, Sortable: true},
+		"created_at":  {Column: "created_at", Type: utils.FilterDate, Sortable: true},
+		"updated_at":  {Column: "updated_at", Type: utils.FilterDate, Sortable: true},
+	},
+	DefaultSort: "-created_at",
+}
+
 const (
 	createNotificationQuery = `
 	INSERT INTO notifications (
-		id, code, title, content, type, target, target_user, status, creator_id, created_at, updated_at, active
+		id, code, title, content, type, target, target_user, params, status, creator_id, created_at, updated_at, active
 	)VALUES (
-		$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+		$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+	)
+	RETURNING id, code, title, content, type, target, target_user, params, status, creator_id, created_at, updated_at, active
+	`
+
+	// one personal notification per active officer, conditions on the agency of the officer are appended
+	createOfficerNotificationsQuery = `
+	INSERT INTO notifications (
+		id, code, title, content, type, target, target_user, params, status, creator_id, created_at, updated_at, active
 	)
-	RETURNING id, code, title, content, type, target, target_user, status, creator_id, created_at, updated_at, active
+	SELECT DISTINCT ON (u.identity_no)
+		gen_random_uuid(), $1, $2, $3, $4, $5, u.identity_no, $6::jsonb, $7, $8::uuid, $9::timestamptz, $9::timestamptz, true
+	FROM users u
+	WHERE u.role IN ('admin', 'officer') AND u.active = true AND u.identity_no <> ''
 	`
 
 	updateNotificationQuery = `
@@ -24,7 +52,7 @@ const (
 			version = version + 1,
 			updated_at = $9
 		WHERE id = $10
-		RETURNING id, code, title, content, type, target, target_user, status, creator_id, created_at, updated_at, active
+		RETURNING id, code, title, content, type, target, target_user, params, status, creator_id, created_at, updated_at, active
 	`
 
 	deleteNotificationQuery = `
@@ -35,11 +63,11 @@ const (
 		modifier_id = $1,
 		updated_at = $2
 	WHERE id = $1 
-	RETURNING id, code, title, content, type, target, target_user, status, creator_id, created_at, updated_at, active
+	RETURNING id, code, title, content, type, target, target_user, params, status, creator_id, created_at, updated_at, active
 	`
 
 	getNotificationByIdQuery = `
-	SELECT id, code, title, content, type, target, target_user, status, creator_id, created_at, updated_at, active
+	SELECT id, code, title, content, type, target, target_user, params, status, creator_id, created_at, updated_at, active
 	FROM notifications
 	WHERE id = $1 AND active = true
 	`
@@ -51,10 +79,9 @@ const (
 	`
 
 	searchByTitleQuery = `
-	SELECT id, code, title, content, type, target, target_user, status, creator_id, created_at, updated_at, active
+	SELECT id, code, title, content, type, target, target_user, params, status, creator_id, created_at, updated_at, active
 	FROM notifications
-	WHERE active = true AND title ILIKE '%' || $1 || '%'
-	ORDER BY created_at DESC`
+	WHERE active = true AND title ILIKE '%' || $1 || '%'`
 
 	findByTitleCount = `
 	SELECT COUNT(*)
@@ -69,14 +96,13 @@ const (
 	`
 
 	getNotification = `
-	SELECT id, code, title, content, type, target, target_user, status, creator_id, created_at, updated_at, active
+	SELECT id, code, title, content, type, target, target_user, params, status, creator_id, created_at, updated_at, active
 	FROM notifications
 	WHERE active = true
-	ORDER BY updated_at, created_at OFFSET $1 LIMIT $2
 	`
 
 	getNotificationsForUser = `
-        SELECT id, code, title, content, type, target, target_user, status, creator_id, created_at, updated_at, active
+        SELECT id, code, title, content, type, target, target_user, params, status, creator_id, created_at, updated_at, active
         FROM notifications
         WHERE active = true
           AND created_at > $1  -- sau thời điểm user tạo tài khoản
@@ -84,8 +110,6 @@ const (
             target = 'all' 
             OR (target = 'personal' AND target_user = $2)
           )
-        ORDER BY created_at DESC
-        OFFSET $3 LIMIT $4
     `
 
 	getTotalNotificationsForUserCount = `
@@ -107,6 +131,6 @@ const (
           AND target = 'personal' 
           AND target_user = $2
           AND active = true
-        RETURNING id, code, title, content, type, target, target_user, status, creator_id, created_at, updated_at, active
+        RETURNING id, code, title, content, type, target, target_user, params, status, creator_id, created_at, updated_at, active
     `
 )
@@ -49,6 +49,24 @@ func (n *notificationUC) CreateNotification(ctx context.Context, db *models.Noti
 	return notificationResult, nil
 }
 
+// Personal notification to the officers whose agency covers one of the areas, officers of no agency
+// and of the ministry cover every area
+func (n *notificationUC) NotifyOfficers(ctx context.Context, db *models.Notification, areas []*models.JurisdictionArea) (int64, error) {
+	db.Target = "personal"
+	db.Status = "unread"
+	if err := db.PrepareCreate(); err != nil {
+		return 0, httpErrors.NewBadRequestError(errors.Wrap(err, "notificationUC.NotifyOfficers.PrepareCreate"))
+	}
+
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return 0, httpErrors.NewUnauthorizedError(errors.WithMessage(err, "notificationUC.NotifyOfficers.GetUserFromCtx"))
+	}
+	db.CreatorId = user.Id
+
+	return n.notificationRepo.CreateOfficerNotifications(ctx, db, areas)
+}
+
 func (n *notificationUC) UpdateNotification(ctx context.Context, db *models.Notification) (*models.Notification, error) {
 	user, err := utils.GetUserFromCtx(ctx)
 	if err != nil {
@@ -0,0 +1,70 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/adohong4/driving-license/config"
+	"github.com/adohong4/driving-license/internal/models"
+	"github.com/adohong4/driving-license/internal/search"
+	"github.com/adohong4/driving-license/pkg/httpErrors"
+	"github.com/adohong4/driving-license/pkg/logger"
+	"github.com/adohong4/driving-license/pkg/utils"
+	"github.com/labstack/echo/v4"
+)
+
+type searchHandlers struct {
+	cfg      *config.Config
+	searchUC search.UseCase
+	logger   logger.Logger
+}
+
+func NewSearchHandlers(cfg *config.Config, searchUC search.UseCase, logger logger.Logger) search.Handlers {
+	return &searchHandlers{cfg: cfg, searchUC: searchUC, logger: logger}
+}
+
+// Search godoc
+// @Summary      Search citizens, licenses and vehicles
+// @Description  Full-text and fuzzy search over names, CCCD, license numbers, plates, chassis and engine numbers, diacritics are ignored
+// @Tags         Search
+// @Produce      json
+// @Param        q      query     string  true   "Search term, 2 to 100 characters"
+// @Param        types  query     string  false  "Comma separated types: citizen, license, vehicle (default: all)"
+// @Param        limit  query     int     false  "Max hits (default: 20, max: 50)"
+// @Success      200    {object}  models.SearchResult
+// @Failure      400    {object}  httpErrors.Problem
+// @Failure      401    {object}  httpErrors.Problem
+// @Failure      500    {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /search [get]
+func (h *searchHandlers) Search() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		sq := &models.SearchQuery{Term: c.QueryParam("q")}
+		if types := c.QueryParam("types"); types != "" {
+			for _, t := range strings.Split(types, ",") {
+				if t = strings.TrimSpace(t); t != "" {
+					sq.Types = append(sq.Types, t)
+				}
+			}
+		}
+		if limit := c.QueryParam("limit"); limit != "" {
+			n, err := strconv.Atoi(limit)
+			if err != nil {
+				utils.LogResponseError(c, h.logger, err)
+				return c.JSON(httpErrors.ErrorResponse(httpErrors.NewBadRequestError(err.Error())))
+			}
+			sq.Limit = n
+		}
+
+		result, err := h.searchUC.Search(ctx, sq)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, result)
+	}
+}
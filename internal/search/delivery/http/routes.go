@@ -0,0 +1,13 @@
+package http
+
+import (
+	"github.com/adohong4/driving-license/config"
+	"github.com/adohong4/driving-license/internal/auth"
+	"github.com/adohong4/driving-license/internal/middleware"
+	"github.com/adohong4/driving-license/internal/search"
+	"github.com/labstack/echo/v4"
+)
+
+func MapSearchRoutes(searchGroup *echo.Group, h search.Handlers, mw *middleware.MiddlewareManager, cfg *config.Config, authUC auth.UseCase) {
+	searchGroup.GET("", h.Search(), mw.AuthJWTMiddleware(authUC, cfg))
+}
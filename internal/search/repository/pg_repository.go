@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"strings"
+
+	"github.com/adohong4/driving-license/internal/models"
+	"github.com/adohong4/driving-license/internal/search"
+	"github.com/adohong4/driving-license/pkg/utils"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+type searchRepo struct {
+	db *sqlx.DB
+}
+
+func NewSearchRepo(db *sqlx.DB) search.Repository {
+	return &searchRepo{db: db}
+}
+
+// Ranked hits of the requested types, each type is searched in its own branch of one query
+func (r *searchRepo) Search(ctx context.Context, sq *models.SearchQuery) ([]*models.SearchHit, error) {
+	branches := make([]string, 0, len(sq.Types))
+	for _, t := range sq.Types {
+		if q, ok := searchQueries[t]; ok {
+			branches = append(branches, q)
+		}
+	}
+	if len(branches) == 0 {
+		return []*models.SearchHit{}, nil
+	}
+
+	query := searchTermCTE + strings.Join(branches, " UNION ALL ") + searchOrderBy
+	hits := make([]*models.SearchHit, 0, sq.Limit)
+	if err := r.db.SelectContext(ctx, &hits, query, sq.Term, sq.Limit, utils.JurisdictionFromCtx(ctx)); err != nil {
+		return nil, errors.Wrap(err, "searchRepo.Search.SelectContext")
+	}
+	return hits, nil
+}
@@ -0,0 +1,90 @@
+package repository
+
+import "github.com/adohong4/driving-license/internal/models"
+
+// The search documents match the indexes of migrations/000005_search. Records are scoped to the jurisdiction $3
+// like their own repositories, citizens by the area of their licenses
+const (
+	// $1 search term, $2 limit, $3 jurisdiction of the caller
+	searchTermCTE = `
+    WITH q AS (
+        SELECT f_unaccent(lower($1)) AS term,
+               regexp_replace(f_unaccent(lower($1)), '[^a-z0-9]', '', 'g') AS compact,
+               plainto_tsquery('simple', f_unaccent(lower($1))) AS tsq
+    )
+    `
+
+	searchCitizens = `
+    (SELECT 'citizen' AS type, u.id, u.full_name AS title, u.identity_no AS subtitle,
+        CASE WHEN q.compact <> '' AND lower(u.identity_no) LIKE '%' || q.compact || '%' THEN 'identity_no'
+             ELSE 'full_name' END AS matched,
+        GREATEST(
+            CASE WHEN lower(u.identity_no) = q.compact THEN 1 ELSE 0 END,
+            ts_rank(to_tsvector('simple', f_search_doc(u.full_name, u.identity_no)), q.tsq),
+            word_similarity(q.term, f_search_doc(u.full_name, u.identity_no))
+        )::float8 AS score
+    FROM users u, q
+    WHERE u.active = true
+      AND ($3::uuid IS NULL OR EXISTS (
+          SELECT 1 FROM driver_licenses dl
+          WHERE dl.identity_no = u.identity_no AND dl.active = true AND f_in_jurisdiction($3, dl.owner_city)
+      ))
+      AND (to_tsvector('simple', f_search_doc(u.full_name, u.identity_no)) @@ q.tsq
+           OR q.term <% f_search_doc(u.full_name, u.identity_no))
+    ORDER BY score DESC
+    LIMIT $2)
+    `
+
+	searchLicenses = `
+    (SELECT 'license' AS type, dl.id, dl.license_no AS title, dl.full_name AS subtitle,
+        CASE WHEN q.compact <> '' AND regexp_replace(lower(dl.license_no), '[^a-z0-9]', '', 'g') LIKE '%' || q.compact || '%' THEN 'license_no'
+             WHEN q.compact <> '' AND lower(dl.identity_no) LIKE '%' || q.compact || '%' THEN 'identity_no'
+             ELSE 'full_name' END AS matched,
+        GREATEST(
+            CASE WHEN regexp_replace(lower(dl.license_no), '[^a-z0-9]', '', 'g') = q.compact
+                   OR lower(dl.identity_no) = q.compact THEN 1 ELSE 0 END,
+            ts_rank(to_tsvector('simple', f_search_doc(dl.license_no, dl.full_name, dl.identity_no)), q.tsq),
+            word_similarity(q.term, f_search_doc(dl.license_no, dl.full_name, dl.identity_no))
+        )::float8 AS score
+    FROM driver_licenses dl, q
+    WHERE dl.active = true
+      AND f_in_jurisdiction($3, dl.owner_city)
+      AND (to_tsvector('simple', f_search_doc(dl.license_no, dl.full_name, dl.identity_no)) @@ q.tsq
+           OR q.term <% f_search_doc(dl.license_no, dl.full_name, dl.identity_no))
+    ORDER BY score DESC
+    LIMIT $2)
+    `
+
+	searchVehicles = `
+    (SELECT 'vehicle' AS type, vr.id, vr.vehicle_no AS title, COALESCE(vr.owner_name, '') AS subtitle,
+        CASE WHEN q.compact <> '' AND regexp_replace(lower(vr.vehicle_no), '[^a-z0-9]', '', 'g') LIKE '%' || q.compact || '%' THEN 'vehicle_no'
+             WHEN q.compact <> '' AND lower(vr.chassis_no) LIKE '%' || q.compact || '%' THEN 'chassis_no'
+             WHEN q.compact <> '' AND lower(vr.engine_no) LIKE '%' || q.compact || '%' THEN 'engine_no'
+             ELSE 'owner_name' END AS matched,
+        GREATEST(
+            CASE WHEN regexp_replace(lower(vr.vehicle_no), '[^a-z0-9]', '', 'g') = q.compact
+                   OR lower(vr.chassis_no) = q.compact OR lower(vr.engine_no) = q.compact THEN 1 ELSE 0 END,
+            ts_rank(to_tsvector('simple', f_search_doc(vr.vehicle_no, regexp_replace(vr.vehicle_no, '[^A-Za-z0-9]', '', 'g'), vr.chassis_no, vr.engine_no, vr.owner_name)), q.tsq),
+            word_similarity(q.term, f_search_doc(vr.vehicle_no, regexp_replace(vr.vehicle_no, '[^A-Za-z0-9]', '', 'g'), vr.chassis_no, vr.engine_no, vr.owner_name))
+        )::float8 AS score
+    FROM vehicle_registration vr, q
+    WHERE vr.active = true
+      AND f_in_jurisdiction($3, f_vehicle_province(vr.owner_id, vr.registration_place))
+      AND (to_tsvector('simple', f_search_doc(vr.vehicle_no, regexp_replace(vr.vehicle_no, '[^A-Za-z0-9]', '', 'g'), vr.chassis_no, vr.engine_no, vr.owner_name)) @@ q.tsq
+           OR q.term <% f_search_doc(vr.vehicle_no, regexp_replace(vr.vehicle_no, '[^A-Za-z0-9]', '', 'g'), vr.chassis_no, vr.engine_no, vr.owner_name))
+    ORDER BY score DESC
+    LIMIT $2)
+    `
+
+	searchOrderBy = `
+    ORDER BY score DESC, title
+    LIMIT $2
+    `
+)
+
+// Query of each searchable type
+var searchQueries = map[string]string{
+	models.SearchTypeCitizen: searchCitizens,
+	models.SearchTypeLicense: searchLicenses,
+	models.SearchTypeVehicle: searchVehicles,
+}
@@ -0,0 +1,11 @@
+package search
+
+import (
+	"context"
+
+	"github.com/adohong4/driving-license/internal/models"
+)
+
+type UseCase interface {
+	Search(ctx context.Context, sq *models.SearchQuery) (*models.SearchResult, error)
+}
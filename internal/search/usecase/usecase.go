@@ -0,0 +1,71 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/adohong4/driving-license/config"
+	"github.com/adohong4/driving-license/internal/models"
+	"github.com/adohong4/driving-license/internal/search"
+	"github.com/adohong4/driving-license/pkg/httpErrors"
+	"github.com/adohong4/driving-license/pkg/logger"
+)
+
+const (
+	minTermLength = 2
+	maxTermLength = 100
+	defaultLimit  = 20
+	maxLimit      = 50
+)
+
+// Entity links of the hits
+var hitURLs = map[string]string{
+	models.SearchTypeCitizen: "/v1/api/auth/",
+	models.SearchTypeLicense: "/v1/api/licenses/",
+	models.SearchTypeVehicle: "/v1/api/vehicle/",
+}
+
+type searchUC struct {
+	cfg        *config.Config
+	searchRepo search.Repository
+	logger     logger.Logger
+}
+
+func NewSearchUseCase(cfg *config.Config, searchRepo search.Repository, logger logger.Logger) search.UseCase {
+	return &searchUC{cfg: cfg, searchRepo: searchRepo, logger: logger}
+}
+
+// Search citizens, licenses and vehicles, hits of all types are ranked together
+func (u *searchUC) Search(ctx context.Context, sq *models.SearchQuery) (*models.SearchResult, error) {
+	sq.Term = strings.Join(strings.Fields(sq.Term), " ")
+	if n := utf8.RuneCountInString(sq.Term); n < minTermLength || n > maxTermLength {
+		return nil, httpErrors.NewBadRequestError("q must be 2 to 100 characters")
+	}
+
+	if len(sq.Types) == 0 {
+		sq.Types = models.SearchTypes
+	}
+	for _, t := range sq.Types {
+		if _, ok := hitURLs[t]; !ok {
+			return nil, httpErrors.NewBadRequestError("unknown type " + t + ", allowed: " + strings.Join(models.SearchTypes, ", "))
+		}
+	}
+
+	switch {
+	case sq.Limit <= 0:
+		sq.Limit = defaultLimit
+	case sq.Limit > maxLimit:
+		sq.Limit = maxLimit
+	}
+
+	hits, err := u.searchRepo.Search(ctx, sq)
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range hits {
+		h.URL = hitURLs[h.Type] + h.Id.String()
+	}
+
+	return &models.SearchResult{Query: sq.Term, Types: sq.Types, Hits: hits}, nil
+}
@@ -1,7 +1,10 @@
 package server
 
 import (
+	"context"
+	"fmt"
 	"net/http"
+	"time"
 
 	_ "github.com/adohong4/driving-license/docs"
 	echoSwagger "github.com/swaggo/echo-swagger"
@@ -34,14 +37,56 @@ import (
 	notiRepository "github.com/adohong4/driving-license/internal/notification/repository"
 	notiUseCase "github.com/adohong4/driving-license/internal/notification/usecase"
 
+	exportJobHttp "github.com/adohong4/driving-license/internal/export_job/delivery/http"
+	exportJobRepository "github.com/adohong4/driving-license/internal/export_job/repository"
+	exportJobUseCase "github.com/adohong4/driving-license/internal/export_job/usecase"
+
+	importJobHttp "github.com/adohong4/driving-license/internal/import_job/delivery/http"
+	importJobRepository "github.com/adohong4/driving-license/internal/import_job/repository"
+	importJobUseCase "github.com/adohong4/driving-license/internal/import_job/usecase"
+
+	searchHttp "github.com/adohong4/driving-license/internal/search/delivery/http"
+	searchRepository "github.com/adohong4/driving-license/internal/search/repository"
+	searchUseCase "github.com/adohong4/driving-license/internal/search/usecase"
+
+	vehicleTransferHttp "github.com/adohong4/driving-license/internal/vehicle_transfer/delivery/http"
+	vehicleTransferRepository "github.com/adohong4/driving-license/internal/vehicle_transfer/repository"
+	vehicleTransferUseCase "github.com/adohong4/driving-license/internal/vehicle_transfer/usecase"
+
+	licenseApplicationHttp "github.com/adohong4/driving-license/internal/license_application/delivery/http"
+	licenseApplicationRepository "github.com/adohong4/driving-license/internal/license_application/repository"
+	licenseApplicationUseCase "github.com/adohong4/driving-license/internal/license_application/usecase"
+
+	drivingSchoolHttp "github.com/adohong4/driving-license/internal/driving_school/delivery/http"
+	drivingSchoolRepository "github.com/adohong4/driving-license/internal/driving_school/repository"
+	drivingSchoolUseCase "github.com/adohong4/driving-license/internal/driving_school/usecase"
+
+	appointmentHttp "github.com/adohong4/driving-license/internal/appointment/delivery/http"
+	appointmentRepository "github.com/adohong4/driving-license/internal/appointment/repository"
+	appointmentUseCase "github.com/adohong4/driving-license/internal/appointment/usecase"
+
+	dashboardHttp "github.com/adohong4/driving-license/internal/dashboard/delivery/http"
+	dashboardRepository "github.com/adohong4/driving-license/internal/dashboard/repository"
+	dashboardUseCase "github.com/adohong4/driving-license/internal/dashboard/usecase"
+
+	anchorHttp "github.com/adohong4/driving-license/internal/anchor/delivery/http"
+	anchorRepository "github.com/adohong4/driving-license/internal/anchor/repository"
+	anchorUseCase "github.com/adohong4/driving-license/internal/anchor/usecase"
+
+	statsRepository "github.com/adohong4/driving-license/internal/stats/repository"
+	statsUseCase "github.com/adohong4/driving-license/internal/stats/usecase"
+
 	apiMiddlewares "github.com/adohong4/driving-license/internal/middleware"
+	"github.com/adohong4/driving-license/pkg/cache"
+	"github.com/adohong4/driving-license/pkg/chain"
+	"github.com/adohong4/driving-license/pkg/db/redis"
 	"github.com/adohong4/driving-license/pkg/utils"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 )
 
-// Map Server Handler
-func (s *Server) MapHandlers(e *echo.Echo) error {
+// Map Server Handler, background workers stop when ctx is done
+func (s *Server) MapHandlers(ctx context.Context, e *echo.Echo) error {
 	// Init Repositories
 	aRepo := authRepository.NewAuthRepository(s.db)
 	gRepo := govAgencyRepo.NewGovAgencyRepo(s.db)
@@ -50,39 +95,93 @@ func (s *Server) MapHandlers(e *echo.Echo) error {
 	tRepo := trafficVioRepository.NewTrafficViolationRepo(s.db)
 	newsRepo := newsRepository.NewNewsRepo(s.db)
 	notiRepo := notiRepository.NewNotificationRepo(s.db)
+	statsRepo := statsRepository.NewStatsRepo(s.db)
+	exportJobRepo := exportJobRepository.NewExportJobRepo(s.db)
+	importJobRepo := importJobRepository.NewImportJobRepo(s.db)
+	searchRepo := searchRepository.NewSearchRepo(s.db)
+	dashboardRepo := dashboardRepository.NewDashboardRepo(s.db)
+	vehicleTransferRepo := vehicleTransferRepository.NewVehicleTransferRepo(s.db)
+	licenseApplicationRepo := licenseApplicationRepository.NewLicenseApplicationRepo(s.db)
+	drivingSchoolRepo := drivingSchoolRepository.NewDrivingSchoolRepo(s.db)
+	appointmentRepo := appointmentRepository.NewAppointmentRepo(s.db)
+	anchorRepo := anchorRepository.NewAnchorRepo(s.db)
+
+	// Stats cache, redis when configured and in-process LRU otherwise
+	statsCache := cache.NewLRUCache(s.cfg.Stats.CacheSize)
+	if s.redisClient != nil {
+		statsCache = redis.NewRedisCache(s.redisClient, "driving-license:")
+	}
+
+	chainClient, err := s.newChainClient()
+	if err != nil {
+		return err
+	}
+	chainVerifier, err := s.newChainVerifier()
+	if err != nil {
+		return err
+	}
 
 	// Init Usecase
+	statsUC := statsUseCase.NewStatsUseCase(s.cfg, statsRepo, statsCache, s.logger)
+	exportJobUC := exportJobUseCase.NewExportJobUseCase(s.cfg, exportJobRepo, s.logger)
+	importJobUC := importJobUseCase.NewImportJobUseCase(s.cfg, importJobRepo, s.logger)
 	authUC := authUseCase.NewAuthUseCase(s.cfg, aRepo, s.logger)
-	goAgenUC := govAgencyUC.NewGovAgencyUseCase(s.cfg, gRepo, s.logger)
-	dlUC := driverLicenseUseCase.NewDriverLicenseUseCase(s.cfg, dRepo, s.logger)
-	vReUC := vehicleReqUseCase.NewVehicleRegUseCase(s.cfg, vReRepo, s.logger)
-	tUC := trafficVioUseCase.NewTrafficViolationUseCase(s.cfg, tRepo, s.logger)
+	anchorUC := anchorUseCase.NewAnchorUseCase(s.cfg, anchorRepo, chainClient, chainVerifier, s.logger)
+	dlUC := driverLicenseUseCase.NewDriverLicenseUseCase(s.cfg, dRepo, statsUC, anchorUC, s.logger)
+	vReUC := vehicleReqUseCase.NewVehicleRegUseCase(s.cfg, vReRepo, statsUC, anchorUC, s.logger)
 	newsUC := newsUseCase.NewNewsUseCase(s.cfg, newsRepo, s.logger)
 	notiUC := notiUseCase.NewNotificationUseCase(s.cfg, notiRepo, s.logger)
+	goAgenUC := govAgencyUC.NewGovAgencyUseCase(s.cfg, gRepo, notiUC, s.logger)
+	tUC := trafficVioUseCase.NewTrafficViolationUseCase(s.cfg, tRepo, statsUC, notiUC, s.logger)
+	searchUC := searchUseCase.NewSearchUseCase(s.cfg, searchRepo, s.logger)
+	dashboardUC := dashboardUseCase.NewDashboardUseCase(s.cfg, dashboardRepo, authUC, s.logger)
+	vehicleTransferUC := vehicleTransferUseCase.NewVehicleTransferUseCase(s.cfg, vehicleTransferRepo, notiUC, s.logger)
+	licenseApplicationUC := licenseApplicationUseCase.NewLicenseApplicationUseCase(s.cfg, licenseApplicationRepo, statsUC, notiUC, s.logger)
+	drivingSchoolUC := drivingSchoolUseCase.NewDrivingSchoolUseCase(s.cfg, drivingSchoolRepo, dlUC, notiUC, s.logger)
+	appointmentUC := appointmentUseCase.NewAppointmentUseCase(s.cfg, appointmentRepo, notiUC, s.logger)
 
 	// Init Handler
-	authHandlers := authHttp.NewAuthHandlers(s.cfg, authUC, s.logger)
+	authHandlers := authHttp.NewAuthHandlers(s.cfg, authUC, exportJobUC, s.logger)
 	govAgencyHandlers := govAgencyHttp.NewGovAgencyHandlers(s.cfg, goAgenUC, s.logger)
-	driverLicenseHandlers := driverLicenseHttp.NewDriverLicenseHandlers(s.cfg, dlUC, s.logger)
-	vehiclerReqHandlers := vehicleRegHttp.NewVehicleReqHandlers(s.cfg, vReUC, s.logger)
-	trafficVioHandlers := trafficVioHttp.NewTrafficViolationHandlers(s.cfg, tUC, s.logger)
+	driverLicenseHandlers := driverLicenseHttp.NewDriverLicenseHandlers(s.cfg, dlUC, exportJobUC, importJobUC, s.logger)
+	vehiclerReqHandlers := vehicleRegHttp.NewVehicleReqHandlers(s.cfg, vReUC, exportJobUC, importJobUC, s.logger)
+	trafficVioHandlers := trafficVioHttp.NewTrafficViolationHandlers(s.cfg, tUC, exportJobUC, s.logger)
 	newsHandlers := newsHttp.NewsHandlers(s.cfg, newsUC, s.logger)
 	notiHandlers := notiHttp.NewNotificationHandlers(s.cfg, notiUC, s.logger)
+	exportJobHandlers := exportJobHttp.NewExportJobHandlers(s.cfg, exportJobUC, s.logger)
+	importJobHandlers := importJobHttp.NewImportJobHandlers(s.cfg, importJobUC, s.logger)
+	searchHandlers := searchHttp.NewSearchHandlers(s.cfg, searchUC, s.logger)
+	dashboardHandlers := dashboardHttp.NewDashboardHandlers(s.cfg, dashboardUC, s.logger)
+	vehicleTransferHandlers := vehicleTransferHttp.NewVehicleTransferHandlers(s.cfg, vehicleTransferUC, s.logger)
+	licenseApplicationHandlers := licenseApplicationHttp.NewLicenseApplicationHandlers(s.cfg, licenseApplicationUC, s.logger)
+	drivingSchoolHandlers := drivingSchoolHttp.NewDrivingSchoolHandlers(s.cfg, drivingSchoolUC, s.logger)
+	appointmentHandlers := appointmentHttp.NewAppointmentHandlers(s.cfg, appointmentUC, s.logger)
+	anchorHandlers := anchorHttp.NewAnchorHandlers(s.cfg, anchorUC, s.logger)
+
+	// Background workers
+	go statsUC.Run(ctx)
+	go appointmentUC.Run(ctx)
+	go anchorUC.Run(ctx)
 
 	mw := apiMiddlewares.NewMiddlewareManager(authUC, s.cfg, []string{"*"}, s.logger)
 
+	// error responses as problem+json, localized responses
+	e.JSONSerializer = utils.JSONSerializer{}
+	e.HTTPErrorHandler = utils.HTTPErrorHandler
+
 	// middleware
 	e.Use(mw.RequestLoggerMiddleware)
 	e.Use(middleware.Recover())
 	e.Use(middleware.RequestID())
+	e.Use(mw.StaffActionMiddleware(goAgenUC))
 
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
 		AllowOrigins:     []string{"http://localhost:3000", "http://localhost:3001"},
 		AllowMethods:     []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete, http.MethodOptions, http.MethodPatch, http.MethodHead},
-		AllowHeaders:     []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization, "X-CSRF-Token"}, // Add "X-CSRF-Token" so you have CSRF middleware
-		ExposeHeaders:    []string{"Content-Length", "X-CSRF-Token"},                                                                       // If need expose add header
-		AllowCredentials: true,                                                                                                             // Acceptance send cookie/credentials
-		MaxAge:           86400,                                                                                                            // 24 giờ,
+		AllowHeaders:     []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization, "X-CSRF-Token"},      // Add "X-CSRF-Token" so you have CSRF middleware
+		ExposeHeaders:    []string{"Content-Length", "X-CSRF-Token", utils.HeaderStatsAsOf, echo.HeaderContentDisposition, echo.HeaderLocation}, // If need expose add header
+		AllowCredentials: true,                                                                                                                  // Acceptance send cookie/credentials
+		MaxAge:           86400,                                                                                                                 // 24 giờ,
 	}))
 
 	//Swagger
@@ -111,14 +210,34 @@ func (s *Server) MapHandlers(e *echo.Echo) error {
 	trafficVioGroup := v1.Group("/traffic")
 	newsGroup := v1.Group("/news")
 	notiGroup := v1.Group("/noti")
+	exportGroup := v1.Group("/exports")
+	importGroup := v1.Group("/imports")
+	searchGroup := v1.Group("/search")
+	meGroup := v1.Group("/me")
+	vehicleTransferGroup := v1.Group("/vehicle/transfers")
+	licenseApplicationGroup := v1.Group("/licenses/applications")
+	drivingSchoolGroup := v1.Group("/school")
+	appointmentGroup := v1.Group("/appointments")
+	anchorGroup := v1.Group("/anchors")
+	verifyGroup := v1.Group("/verify")
 
 	authHttp.MapAuthRoutes(authGroup, authHandlers, mw, s.cfg, authUC)
-	govAgencyHttp.MapGovAgencyRoutes(goAgencyGroup, govAgencyHandlers)
+	govAgencyHttp.MapGovAgencyRoutes(goAgencyGroup, govAgencyHandlers, mw, s.cfg, authUC)
 	driverLicenseHttp.MapDriverLicenseRoutes(driverLicenseGroup, driverLicenseHandlers, mw, s.cfg, authUC)
 	vehicleRegHttp.MapVehicleRegistrationRoutes(vehicleReqGroup, vehiclerReqHandlers, mw, s.cfg, authUC)
 	trafficVioHttp.MapTrafficViolationRoutes(trafficVioGroup, trafficVioHandlers, mw, s.cfg, authUC)
 	newsHttp.MapNewsRoutes(newsGroup, newsHandlers, mw, authUC, s.cfg)
 	notiHttp.MapNotificationRoutes(notiGroup, notiHandlers, mw, s.cfg, authUC)
+	exportJobHttp.MapExportJobRoutes(exportGroup, exportJobHandlers, mw, s.cfg, authUC)
+	importJobHttp.MapImportJobRoutes(importGroup, importJobHandlers, mw, s.cfg, authUC)
+	searchHttp.MapSearchRoutes(searchGroup, searchHandlers, mw, s.cfg, authUC)
+	dashboardHttp.MapDashboardRoutes(meGroup, dashboardHandlers, mw, s.cfg, authUC)
+	vehicleTransferHttp.MapVehicleTransferRoutes(vehicleTransferGroup, vehicleTransferHandlers, mw, s.cfg, authUC)
+	licenseApplicationHttp.MapLicenseApplicationRoutes(licenseApplicationGroup, licenseApplicationHandlers, mw, s.cfg, authUC)
+	drivingSchoolHttp.MapDrivingSchoolRoutes(drivingSchoolGroup, drivingSchoolHandlers, mw, s.cfg, authUC)
+	appointmentHttp.MapAppointmentRoutes(appointmentGroup, appointmentHandlers, mw, s.cfg, authUC)
+	anchorHttp.MapAnchorRoutes(anchorGroup, anchorHandlers, mw, s.cfg, authUC)
+	anchorHttp.MapVerifyRoutes(verifyGroup, anchorHandlers)
 
 	health.GET("", func(c echo.Context) error {
 		s.logger.Infof("Health check request id: %s", utils.GetRequestId(c))
@@ -127,3 +246,41 @@ func (s *Server) MapHandlers(e *echo.Echo) error {
 
 	return nil
 }
+
+// Chain client of the configured driver, nil when anchoring is disabled
+func (s *Server) newChainClient() (chain.ChainClient, error) {
+	bc := s.cfg.Blockchain
+	switch bc.Driver {
+	case "":
+		return nil, nil
+	case chain.DriverEVM:
+		if bc.RPCURL == "" || bc.RegistryAddress == "" || bc.SenderAddress == "" || bc.AnchorMethodID == "" {
+			return nil, fmt.Errorf("the evm blockchain driver requires RPCURL, RegistryAddress, SenderAddress and AnchorMethodID")
+		}
+		return chain.NewEVMSubmitter(s.newRPCClient(), bc.RegistryAddress, bc.SenderAddress, bc.AnchorMethodID), nil
+	case chain.DriverSimulated:
+		return nil, fmt.Errorf("the simulated blockchain driver is only available in tests, use the evm driver with a local node")
+	}
+	return nil, fmt.Errorf("unknown blockchain driver %q", bc.Driver)
+}
+
+// Verifier of the transactions sent by clients, always the EVM node. Nil when confirmation is disabled
+func (s *Server) newChainVerifier() (chain.Verifier, error) {
+	bc := s.cfg.Blockchain
+	if bc.Driver == "" && bc.RPCURL == "" {
+		return nil, nil
+	}
+	if bc.RPCURL == "" || bc.RegistryAddress == "" {
+		return nil, fmt.Errorf("blockchain confirmation requires RPCURL and RegistryAddress, leave Driver and RPCURL empty to disable it")
+	}
+	return chain.NewEVMVerifier(s.newRPCClient(), bc.RegistryAddress, bc.AnchorEventTopic), nil
+}
+
+// JSON-RPC client of the configured node
+func (s *Server) newRPCClient() chain.EVMClient {
+	timeout := time.Duration(s.cfg.Blockchain.RPCTimeout) * time.Second
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return chain.NewRPCClient(s.cfg.Blockchain.RPCURL, timeout)
+}
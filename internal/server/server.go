@@ -12,6 +12,7 @@ import (
 	"github.com/adohong4/driving-license/pkg/logger"
 	"github.com/jmoiron/sqlx"
 	"github.com/labstack/echo/v4"
+	"github.com/redis/go-redis/v9"
 )
 
 const (
@@ -20,14 +21,15 @@ const (
 )
 
 type Server struct {
-	echo   *echo.Echo
-	cfg    *config.Config
-	db     *sqlx.DB
-	logger logger.Logger
+	echo        *echo.Echo
+	cfg         *config.Config
+	db          *sqlx.DB
+	redisClient *redis.Client // optional
+	logger      logger.Logger
 }
 
-func NewServer(cfg *config.Config, db *sqlx.DB, logger logger.Logger) *Server {
-	return &Server{echo: echo.New(), cfg: cfg, db: db, logger: logger}
+func NewServer(cfg *config.Config, db *sqlx.DB, redisClient *redis.Client, logger logger.Logger) *Server {
+	return &Server{echo: echo.New(), cfg: cfg, db: db, redisClient: redisClient, logger: logger}
 }
 
 func (s *Server) Run() error {
@@ -38,7 +40,10 @@ func (s *Server) Run() error {
 		MaxHeaderBytes: maxHeaderBytes,
 	}
 
-	if err := s.MapHandlers(s.echo); err != nil {
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	if err := s.MapHandlers(workersCtx, s.echo); err != nil {
 		return err
 	}
 
@@ -0,0 +1,30 @@
+package stats
+
+import (
+	"context"
+	"time"
+
+	"github.com/adohong4/driving-license/pkg/cache"
+	"github.com/adohong4/driving-license/pkg/utils"
+)
+
+// Key of an aggregate as seen by the caller, aggregates are scoped to the jurisdiction of the caller
+func cacheKey(ctx context.Context, domain, key string) string {
+	scope := "all"
+	if agency := utils.JurisdictionFromCtx(ctx); agency != nil {
+		scope = agency.String()
+	}
+	return "stats:" + domain + ":" + scope + ":" + key
+}
+
+// Read-through for aggregates served from the domain materialized view, as of its last refresh
+func CachedView[T any](ctx context.Context, uc UseCase, domain, key string, load func(ctx context.Context) (T, error)) (T, time.Time, error) {
+	asOf := func() time.Time { return uc.RefreshedAt(domain) }
+	return cache.ReadThrough(ctx, uc.Cache(), cacheKey(ctx, domain, key), uc.CacheTTL(), asOf, load)
+}
+
+// Read-through for aggregates queried live from the domain tables, as of the query time
+func CachedLive[T any](ctx context.Context, uc UseCase, domain, key string, load func(ctx context.Context) (T, error)) (T, time.Time, error) {
+	asOf := func() time.Time { return time.Now().UTC() }
+	return cache.ReadThrough(ctx, uc.Cache(), cacheKey(ctx, domain, key), uc.CacheTTL(), asOf, load)
+}
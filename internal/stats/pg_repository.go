@@ -0,0 +1,13 @@
+package stats
+
+import (
+	"context"
+	"time"
+
+	"github.com/adohong4/driving-license/internal/models"
+)
+
+type Repository interface {
+	RefreshView(ctx context.Context, view string) (time.Time, error)
+	GetRefreshLog(ctx context.Context) ([]*models.StatsRefresh, error)
+}
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/adohong4/driving-license/internal/models"
+	"github.com/adohong4/driving-license/internal/stats"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+type statsRepo struct {
+	db *sqlx.DB
+}
+
+func NewStatsRepo(db *sqlx.DB) stats.Repository {
+	return &statsRepo{db: db}
+}
+
+func (r *statsRepo) RefreshView(ctx context.Context, view string) (time.Time, error) {
+	if _, err := r.db.ExecContext(ctx, refreshViewQuery+view); err != nil {
+		return time.Time{}, errors.Wrap(err, "statsRepo.RefreshView.ExecContext")
+	}
+
+	var refreshedAt time.Time
+	if err := r.db.QueryRowxContext(ctx, upsertRefreshLogQuery, view).Scan(&refreshedAt); err != nil {
+		return time.Time{}, errors.Wrap(err, "statsRepo.RefreshView.upsertRefreshLog")
+	}
+	return refreshedAt, nil
+}
+
+func (r *statsRepo) GetRefreshLog(ctx context.Context) ([]*models.StatsRefresh, error) {
+	var items []*models.StatsRefresh
+	if err := r.db.SelectContext(ctx, &items, getRefreshLogQuery); err != nil {
+		return nil, errors.Wrap(err, "statsRepo.GetRefreshLog.SelectContext")
+	}
+	return items, nil
+}
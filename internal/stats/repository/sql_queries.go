@@ -0,0 +1,18 @@
+package repository
+
+const (
+	// view name is appended from a fixed whitelist, never from user input
+	refreshViewQuery = `REFRESH MATERIALIZED VIEW CONCURRENTLY `
+
+	upsertRefreshLogQuery = `
+	INSERT INTO stats_refresh_log (view_name, refreshed_at)
+	VALUES ($1, now())
+	ON CONFLICT (view_name) DO UPDATE SET refreshed_at = EXCLUDED.refreshed_at
+	RETURNING refreshed_at
+	`
+
+	getRefreshLogQuery = `
+	SELECT view_name, refreshed_at
+	FROM stats_refresh_log
+	`
+)
@@ -0,0 +1,24 @@
+package stats
+
+import (
+	"context"
+	"time"
+
+	"github.com/adohong4/driving-license/pkg/cache"
+)
+
+// Statistic domains, each one is backed by a materialized view
+const (
+	DomainLicenses   = "licenses"
+	DomainVehicles   = "vehicles"
+	DomainViolations = "violations"
+)
+
+type UseCase interface {
+	MarkDirty(domain string)
+	RefreshedAt(domain string) time.Time
+	Refresh(ctx context.Context, domains ...string) error
+	Run(ctx context.Context)
+	Cache() cache.Cache
+	CacheTTL() time.Duration
+}
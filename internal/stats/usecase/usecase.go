@@ -0,0 +1,172 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/adohong4/driving-license/config"
+	"github.com/adohong4/driving-license/internal/stats"
+	"github.com/adohong4/driving-license/pkg/cache"
+	"github.com/adohong4/driving-license/pkg/logger"
+)
+
+const (
+	defaultCacheTTL            = 5 * time.Minute
+	defaultRefreshInterval     = 30 * time.Second
+	defaultFullRefreshInterval = time.Hour
+)
+
+// Materialized view of every statistic domain
+var domainViews = map[string]string{
+	stats.DomainLicenses:   "mv_license_stats",
+	stats.DomainVehicles:   "mv_vehicle_stats",
+	stats.DomainViolations: "mv_violation_stats",
+}
+
+// Statistic refresher and read-through cache
+type statsUC struct {
+	cfg       *config.Config
+	statsRepo stats.Repository
+	cache     cache.Cache
+	logger    logger.Logger
+
+	mu          sync.Mutex
+	dirty       map[string]bool
+	refreshedAt map[string]time.Time
+}
+
+func NewStatsUseCase(cfg *config.Config, statsRepo stats.Repository, c cache.Cache, logger logger.Logger) stats.UseCase {
+	return &statsUC{
+		cfg:         cfg,
+		statsRepo:   statsRepo,
+		cache:       c,
+		logger:      logger,
+		dirty:       make(map[string]bool),
+		refreshedAt: make(map[string]time.Time),
+	}
+}
+
+func (u *statsUC) Cache() cache.Cache {
+	return u.cache
+}
+
+func (u *statsUC) CacheTTL() time.Duration {
+	return secondsOr(u.cfg.Stats.CacheTTL, defaultCacheTTL)
+}
+
+// Mark domain as changed, its view is refreshed on the next tick
+func (u *statsUC) MarkDirty(domain string) {
+	if _, ok := domainViews[domain]; !ok {
+		return
+	}
+	u.mu.Lock()
+	u.dirty[domain] = true
+	u.mu.Unlock()
+}
+
+func (u *statsUC) RefreshedAt(domain string) time.Time {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.refreshedAt[domain]
+}
+
+// Refresh the views of given domains, all domains when none given
+func (u *statsUC) Refresh(ctx context.Context, domains ...string) error {
+	if len(domains) == 0 {
+		for domain := range domainViews {
+			domains = append(domains, domain)
+		}
+	}
+
+	var firstErr error
+	for _, domain := range domains {
+		view, ok := domainViews[domain]
+		if !ok {
+			continue
+		}
+
+		refreshedAt, err := u.statsRepo.RefreshView(ctx, view)
+		if err != nil {
+			u.MarkDirty(domain)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+
+		u.mu.Lock()
+		u.refreshedAt[domain] = refreshedAt
+		u.mu.Unlock()
+
+		if err = u.cache.DeletePrefix(ctx, "stats:"+domain+":"); err != nil {
+			u.logger.Warnf("StatsUC.Refresh.DeletePrefix %s: %v", domain, err)
+		}
+	}
+	return firstErr
+}
+
+// Refresh dirty views every RefreshInterval and all views every FullRefreshInterval, until ctx is done
+func (u *statsUC) Run(ctx context.Context) {
+	u.loadRefreshLog(ctx)
+
+	ticker := time.NewTicker(secondsOr(u.cfg.Stats.RefreshInterval, defaultRefreshInterval))
+	defer ticker.Stop()
+	fullTicker := time.NewTicker(secondsOr(u.cfg.Stats.FullRefreshInterval, defaultFullRefreshInterval))
+	defer fullTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if domains := u.takeDirty(); len(domains) > 0 {
+				if err := u.Refresh(ctx, domains...); err != nil {
+					u.logger.Errorf("StatsUC.Run.Refresh: %v", err)
+				}
+			}
+		case <-fullTicker.C:
+			u.takeDirty()
+			if err := u.Refresh(ctx); err != nil {
+				u.logger.Errorf("StatsUC.Run.Refresh: %v", err)
+			}
+		}
+	}
+}
+
+func (u *statsUC) loadRefreshLog(ctx context.Context) {
+	items, err := u.statsRepo.GetRefreshLog(ctx)
+	if err != nil {
+		u.logger.Errorf("StatsUC.loadRefreshLog: %v", err)
+		return
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	for domain, view := range domainViews {
+		for _, item := range items {
+			if item.ViewName == view {
+				u.refreshedAt[domain] = item.RefreshedAt
+			}
+		}
+	}
+}
+
+func (u *statsUC) takeDirty() []string {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	domains := make([]string, 0, len(u.dirty))
+	for domain := range u.dirty {
+		domains = append(domains, domain)
+	}
+	u.dirty = make(map[string]bool)
+	return domains
+}
+
+func secondsOr(seconds int, def time.Duration) time.Duration {
+	if seconds <= 0 {
+		return def
+	}
+	return time.Duration(seconds) * time.Second
+}
@@ -9,10 +9,19 @@ type Handlers interface {
 	GetTrafficViolationById() echo.HandlerFunc
 	GetAllTrafficViolation() echo.HandlerFunc
 	SearchTrafficViolation() echo.HandlerFunc
+	ExportTrafficViolations() echo.HandlerFunc
 	GetTrafficViolationStats() echo.HandlerFunc
 	GetTrafficViolationStatusStats() echo.HandlerFunc
+	GetViolationSeries() echo.HandlerFunc
+	GetFinesCollectedSeries() echo.HandlerFunc
 	GetMyViolations() echo.HandlerFunc
 	GetViolationsByMyVehicle() echo.HandlerFunc
 	GetMyTrafficViolationByID() echo.HandlerFunc
 	GetViolationsByMyLicense() echo.HandlerFunc
+	GetViolationOwner() echo.HandlerFunc
+	ReportDetection() echo.HandlerFunc
+
+	GetDistrictHotspots() echo.HandlerFunc
+	GetViolationHeatmap() echo.HandlerFunc
+	GetTopRoadSegments() echo.HandlerFunc
 }
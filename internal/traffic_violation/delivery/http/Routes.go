@@ -10,13 +10,21 @@ import (
 
 func MapTrafficViolationRoutes(trafficViolationGroup *echo.Group, h trafficviolation.Handlers, mw *middleware.MiddlewareManager, cfg *config.Config, authUC auth.UseCase) {
 	trafficViolationGroup.POST("/create", h.CreateTrafficViolation(), mw.AuthJWTMiddleware(authUC, cfg))
+	trafficViolationGroup.POST("/detections", h.ReportDetection(), mw.AuthJWTMiddleware(authUC, cfg))
 	trafficViolationGroup.PUT("/:id", h.UpdateTrafficViolation(), mw.AuthJWTMiddleware(authUC, cfg))
 	trafficViolationGroup.DELETE("/:id", h.DeleteTrafficViolation(), mw.AuthJWTMiddleware(authUC, cfg))
-	trafficViolationGroup.GET("/:id", h.GetTrafficViolationById())
-	trafficViolationGroup.GET("/getAll", h.GetAllTrafficViolation())
-	trafficViolationGroup.GET("/search", h.SearchTrafficViolation())
-	trafficViolationGroup.GET("/stats", h.GetTrafficViolationStats())
-	trafficViolationGroup.GET("/stats/status", h.GetTrafficViolationStatusStats())
+	trafficViolationGroup.GET("/:id", h.GetTrafficViolationById(), mw.AuthJWTMiddleware(authUC, cfg))
+	trafficViolationGroup.GET("/:id/owner", h.GetViolationOwner(), mw.AuthJWTMiddleware(authUC, cfg))
+	trafficViolationGroup.GET("/getAll", h.GetAllTrafficViolation(), mw.AuthJWTMiddleware(authUC, cfg))
+	trafficViolationGroup.GET("/search", h.SearchTrafficViolation(), mw.AuthJWTMiddleware(authUC, cfg))
+	trafficViolationGroup.GET("/export", h.ExportTrafficViolations(), mw.AuthJWTMiddleware(authUC, cfg))
+	trafficViolationGroup.GET("/stats", h.GetTrafficViolationStats(), mw.OptionalAuthJWTMiddleware(authUC, cfg))
+	trafficViolationGroup.GET("/stats/status", h.GetTrafficViolationStatusStats(), mw.OptionalAuthJWTMiddleware(authUC, cfg))
+	trafficViolationGroup.GET("/stats/series", h.GetViolationSeries(), mw.OptionalAuthJWTMiddleware(authUC, cfg))
+	trafficViolationGroup.GET("/stats/fines/series", h.GetFinesCollectedSeries(), mw.OptionalAuthJWTMiddleware(authUC, cfg))
+	trafficViolationGroup.GET("/hotspots/districts", h.GetDistrictHotspots(), mw.OptionalAuthJWTMiddleware(authUC, cfg))
+	trafficViolationGroup.GET("/hotspots/heatmap", h.GetViolationHeatmap(), mw.OptionalAuthJWTMiddleware(authUC, cfg))
+	trafficViolationGroup.GET("/hotspots/roads", h.GetTopRoadSegments(), mw.OptionalAuthJWTMiddleware(authUC, cfg))
 
 	// === USER-SPECIFIC ROUTES (protected) ===
 	trafficViolationGroup.GET("/me", h.GetMyViolations(), mw.AuthJWTMiddleware(authUC, cfg))
@@ -2,10 +2,14 @@ package http
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/adohong4/driving-license/config"
+	exportjob "github.com/adohong4/driving-license/internal/export_job"
+	exportHttp "github.com/adohong4/driving-license/internal/export_job/delivery/http"
 	"github.com/adohong4/driving-license/internal/models"
 	trafficviolation "github.com/adohong4/driving-license/internal/traffic_violation"
+	"github.com/adohong4/driving-license/pkg/geo"
 	"github.com/adohong4/driving-license/pkg/httpErrors"
 	"github.com/adohong4/driving-license/pkg/logger"
 	"github.com/adohong4/driving-license/pkg/utils"
@@ -16,11 +20,12 @@ import (
 type TrafficViolationHandlers struct {
 	cfg                *config.Config
 	TrafficViolationUC trafficviolation.UseCase
+	exportJobUC        exportjob.UseCase
 	logger             logger.Logger
 }
 
-func NewTrafficViolationHandlers(cfg *config.Config, TrafficViolationUC trafficviolation.UseCase, logger logger.Logger) trafficviolation.Handlers {
-	return &TrafficViolationHandlers{cfg: cfg, TrafficViolationUC: TrafficViolationUC, logger: logger}
+func NewTrafficViolationHandlers(cfg *config.Config, TrafficViolationUC trafficviolation.UseCase, exportJobUC exportjob.UseCase, logger logger.Logger) trafficviolation.Handlers {
+	return &TrafficViolationHandlers{cfg: cfg, TrafficViolationUC: TrafficViolationUC, exportJobUC: exportJobUC, logger: logger}
 }
 
 // @Summary      Create a new traffic violation record
@@ -30,9 +35,9 @@ func NewTrafficViolationHandlers(cfg *config.Config, TrafficViolationUC trafficv
 // @Produce      json
 // @Param        violation  body      models.TrafficViolation  true  "Traffic violation data"
 // @Success      201        {object}  models.TrafficViolation
-// @Failure      400        {object}  httpErrors.RestError  "Invalid request or validation error"
-// @Failure      401        {object}  httpErrors.RestError  "Unauthorized"
-// @Failure      500        {object}  httpErrors.RestError  "Internal server error"
+// @Failure      400        {object}  httpErrors.Problem    "Invalid request or validation error"
+// @Failure      401        {object}  httpErrors.Problem    "Unauthorized"
+// @Failure      500        {object}  httpErrors.Problem    "Internal server error"
 // @Security     JWT
 // @Router       /traffic/create [post]
 func (h *TrafficViolationHandlers) CreateTrafficViolation() echo.HandlerFunc {
@@ -61,10 +66,10 @@ func (h *TrafficViolationHandlers) CreateTrafficViolation() echo.HandlerFunc {
 // @Param        id         path      string                   true  "Traffic Violation ID (UUID)"
 // @Param        violation  body      models.TrafficViolation  true  "Updated violation data"
 // @Success      200        {object}  models.TrafficViolation
-// @Failure      400        {object}  httpErrors.RestError
-// @Failure      401        {object}  httpErrors.RestError
-// @Failure      404        {object}  httpErrors.RestError  "Violation not found"
-// @Failure      500        {object}  httpErrors.RestError
+// @Failure      400        {object}  httpErrors.Problem
+// @Failure      401        {object}  httpErrors.Problem
+// @Failure      404        {object}  httpErrors.Problem    "Violation not found"
+// @Failure      500        {object}  httpErrors.Problem
 // @Security     JWT
 // @Router       /traffic/{id} [put]
 func (h *TrafficViolationHandlers) UpdateTrafficViolation() echo.HandlerFunc {
@@ -100,10 +105,10 @@ func (h *TrafficViolationHandlers) UpdateTrafficViolation() echo.HandlerFunc {
 // @Produce      json
 // @Param        id  path      string  true  "Traffic Violation ID (UUID)"
 // @Success      200  {object}  models.TrafficViolation
-// @Failure      400  {object}  httpErrors.RestError
-// @Failure      401  {object}  httpErrors.RestError
-// @Failure      404  {object}  httpErrors.RestError
-// @Failure      500  {object}  httpErrors.RestError
+// @Failure      400  {object}  httpErrors.Problem
+// @Failure      401  {object}  httpErrors.Problem
+// @Failure      404  {object}  httpErrors.Problem
+// @Failure      500  {object}  httpErrors.Problem
 // @Security     JWT
 // @Router       /traffic/{id} [delete]
 func (h *TrafficViolationHandlers) DeleteTrafficViolation() echo.HandlerFunc {
@@ -138,9 +143,10 @@ func (h *TrafficViolationHandlers) DeleteTrafficViolation() echo.HandlerFunc {
 // @Produce      json
 // @Param        id   path      string  true  "Traffic Violation ID (UUID)"
 // @Success      200  {object}  models.TrafficViolation
-// @Failure      400  {object}  httpErrors.RestError
-// @Failure      404  {object}  httpErrors.RestError  "Violation not found"
-// @Failure      500  {object}  httpErrors.RestError
+// @Failure      400  {object}  httpErrors.Problem
+// @Failure      404  {object}  httpErrors.Problem    "Violation not found"
+// @Failure      500  {object}  httpErrors.Problem
+// @Security     JWT
 // @Router       /traffic/{id} [get]
 func (h *TrafficViolationHandlers) GetTrafficViolationById() echo.HandlerFunc {
 	return func(c echo.Context) error {
@@ -166,9 +172,13 @@ func (h *TrafficViolationHandlers) GetTrafficViolationById() echo.HandlerFunc {
 // @Produce      json
 // @Param        page  query     int  false  "Page number (default: 1)"
 // @Param        size  query     int  false  "Page size (default: 10)"
+// @Param        sort  query     string false  "Sort fields, comma separated, - prefix for descending, e.g. -created_at"
+// @Param        cursor  query     string false  "Cursor of the next page, empty for the first one, switches to cursor pagination"
+// @Param        with_total query     bool   false  "Return an approximate total_count on cursor pages"
 // @Success      200   {object}  models.TrafficViolationList
-// @Failure      400   {object}  httpErrors.RestError
-// @Failure      500   {object}  httpErrors.RestError
+// @Failure      400   {object}  httpErrors.Problem
+// @Failure      500   {object}  httpErrors.Problem
+// @Security     JWT
 // @Router       /traffic/getAll [get]
 func (h *TrafficViolationHandlers) GetAllTrafficViolation() echo.HandlerFunc {
 	return func(c echo.Context) error {
@@ -189,6 +199,35 @@ func (h *TrafficViolationHandlers) GetAllTrafficViolation() echo.HandlerFunc {
 	}
 }
 
+// @Summary      Export traffic violations
+// @Description  Exports violations as CSV or XLSX with the same plate number filter, list filters and sort as search. Large exports run as a background job and return 202 with the job.
+// @Tags         traffic-violation
+// @Produce      text/csv
+// @Produce      application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param        vehicle_no  query     string  false  "Vehicle plate number (partial)"
+// @Param        sort        query     string  false  "Sort fields, comma separated, - prefix for descending, e.g. -created_at"
+// @Param        format      query     string  false  "csv or xlsx, defaults to the Accept header or csv"
+// @Param        lang        query     string  false  "Header language vi or en, defaults to Accept-Language or vi"
+// @Param        async       query     bool    false  "Always run as a background job"
+// @Success      200         {file}    file
+// @Success      202         {object}  models.ExportJob
+// @Failure      400         {object}  httpErrors.Problem
+// @Failure      401         {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /traffic/export [get]
+func (h *TrafficViolationHandlers) ExportTrafficViolations() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		eq, err := utils.GetExportQueryFromCtx(c, "vehicle_no")
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(httpErrors.NewBadRequestError(err.Error())))
+		}
+
+		src := h.TrafficViolationUC.ExportTrafficViolations(c.Request().Context(), eq)
+		return exportHttp.Respond(c, h.exportJobUC, eq, src, h.logger)
+	}
+}
+
 // @Summary      Search traffic violations by vehicle plate number
 // @Description  Searches for violations containing the given plate number (partial, case-insensitive).
 // @Tags         traffic-violation
@@ -196,9 +235,13 @@ func (h *TrafficViolationHandlers) GetAllTrafficViolation() echo.HandlerFunc {
 // @Param        vehicle_no  query     string  true   "Vehicle plate number (partial)"
 // @Param        page        query     int     false  "Page number"
 // @Param        size        query     int     false  "Page size"
+// @Param        sort        query     string  false  "Sort fields, comma separated, - prefix for descending, e.g. -created_at"
+// @Param        cursor      query     string  false  "Cursor of the next page, empty for the first one, switches to cursor pagination"
+// @Param        with_total  query     bool    false  "Return an approximate total_count on cursor pages"
 // @Success      200         {object}  models.TrafficViolationList
-// @Failure      400         {object}  httpErrors.RestError  "Missing vehicle_no"
-// @Failure      500         {object}  httpErrors.RestError
+// @Failure      400         {object}  httpErrors.Problem    "Missing vehicle_no"
+// @Failure      500         {object}  httpErrors.Problem
+// @Security     JWT
 // @Router       /traffic/search [get]
 func (h *TrafficViolationHandlers) SearchTrafficViolation() echo.HandlerFunc {
 	return func(c echo.Context) error {
@@ -225,18 +268,20 @@ func (h *TrafficViolationHandlers) SearchTrafficViolation() echo.HandlerFunc {
 // @Tags         traffic-violation
 // @Produce      json
 // @Success      200  {object}  models.TrafficViolationStats
-// @Failure      500  {object}  httpErrors.RestError
+// @Header       200  {string}  X-Stats-As-Of  "Time the statistic data was computed (RFC3339)"
+// @Failure      500  {object}  httpErrors.Problem
 // @Router       /traffic/stats [get]
 func (h *TrafficViolationHandlers) GetTrafficViolationStats() echo.HandlerFunc {
 	return func(c echo.Context) error {
 		ctx := c.Request().Context()
 
-		stats, err := h.TrafficViolationUC.GetTrafficViolationStats(ctx)
+		stats, asOf, err := h.TrafficViolationUC.GetTrafficViolationStats(ctx)
 		if err != nil {
 			utils.LogResponseError(c, h.logger, err)
 			return c.JSON(httpErrors.ErrorResponse(err))
 		}
 
+		utils.SetStatsAsOf(c, asOf)
 		return c.JSON(http.StatusOK, stats)
 	}
 }
@@ -246,30 +291,103 @@ func (h *TrafficViolationHandlers) GetTrafficViolationStats() echo.HandlerFunc {
 // @Tags         traffic-violation
 // @Produce      json
 // @Success      200  {array}   models.TrafficViolationStatusStats
-// @Failure      500  {object}   httpErrors.RestError
+// @Header       200  {string}  X-Stats-As-Of  "Time the statistic data was computed (RFC3339)"
+// @Failure      500  {object}   httpErrors.Problem
 // @Router       /traffic-violation/stats/status [get]
 func (h *TrafficViolationHandlers) GetTrafficViolationStatusStats() echo.HandlerFunc {
 	return func(c echo.Context) error {
 		ctx := c.Request().Context()
 
-		stats, err := h.TrafficViolationUC.GetTrafficViolationStatusStats(ctx)
+		stats, asOf, err := h.TrafficViolationUC.GetTrafficViolationStatusStats(ctx)
 		if err != nil {
 			utils.LogResponseError(c, h.logger, err)
 			return c.JSON(httpErrors.ErrorResponse(err))
 		}
 
+		utils.SetStatsAsOf(c, asOf)
 		return c.JSON(http.StatusOK, stats)
 	}
 }
 
+// @Summary      Traffic violations over time
+// @Description  Returns number of violations and total fine amount per day, week or month
+// @Tags         traffic-violation
+// @Produce      json
+// @Param        from            query     string  false  "Start date (RFC3339 or YYYY-MM-DD)"
+// @Param        to              query     string  false  "End date, exclusive (RFC3339 or YYYY-MM-DD, default: end of the current period)"
+// @Param        group_by        query     string  false  "day, week or month (default: month)"
+// @Param        city            query     string  false  "Province / city"
+// @Param        violation_type  query     string  false  "Violation type"
+// @Success      200  {object}  models.TimeSeries
+// @Header       200  {string}  X-Stats-As-Of  "Time the statistic data was computed (RFC3339)"
+// @Failure      400  {object}  httpErrors.Problem
+// @Failure      500  {object}  httpErrors.Problem
+// @Router       /traffic-violation/stats/series [get]
+func (h *TrafficViolationHandlers) GetViolationSeries() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		sq, err := utils.GetStatsQueryFromCtx(c)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(httpErrors.NewBadRequestError(err.Error())))
+		}
+
+		series, asOf, err := h.TrafficViolationUC.GetViolationSeries(ctx, sq)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		utils.SetStatsAsOf(c, asOf)
+		return c.JSON(http.StatusOK, series)
+	}
+}
+
+// @Summary      Fines collected over time
+// @Description  Returns number and amount of paid (completed) fines per day, week or month
+// @Tags         traffic-violation
+// @Produce      json
+// @Param        from            query     string  false  "Start date (RFC3339 or YYYY-MM-DD)"
+// @Param        to              query     string  false  "End date, exclusive (RFC3339 or YYYY-MM-DD, default: end of the current period)"
+// @Param        group_by        query     string  false  "day, week or month (default: month)"
+// @Param        city            query     string  false  "Province / city"
+// @Param        violation_type  query     string  false  "Violation type"
+// @Success      200  {object}  models.TimeSeries
+// @Header       200  {string}  X-Stats-As-Of  "Time the statistic data was computed (RFC3339)"
+// @Failure      400  {object}  httpErrors.Problem
+// @Failure      500  {object}  httpErrors.Problem
+// @Router       /traffic-violation/stats/fines/series [get]
+func (h *TrafficViolationHandlers) GetFinesCollectedSeries() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		sq, err := utils.GetStatsQueryFromCtx(c)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(httpErrors.NewBadRequestError(err.Error())))
+		}
+
+		series, asOf, err := h.TrafficViolationUC.GetFinesCollectedSeries(ctx, sq)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		utils.SetStatsAsOf(c, asOf)
+		return c.JSON(http.StatusOK, series)
+	}
+}
+
 // @Summary      Get all traffic violations of authenticated user
 // @Description  Returns paginated list of traffic violations on vehicles owned by current user
 // @Tags         User
 // @Produce      json
 // @Param        page  query     int  false  "Page number"
 // @Param        size  query     int  false  "Page size"
+// @Param        sort  query     string false  "Sort fields, comma separated, - prefix for descending, e.g. -created_at"
+// @Param        cursor  query     string false  "Cursor of the next page, empty for the first one, switches to cursor pagination"
+// @Param        with_total query     bool   false  "Return an approximate total_count on cursor pages"
 // @Success      200   {object}  models.TrafficViolationList
-// @Failure      401   {object}  httpErrors.RestError
+// @Failure      401   {object}  httpErrors.Problem
 // @Security     JWT
 // @Router       /traffic/me [get]
 func (h *TrafficViolationHandlers) GetMyViolations() echo.HandlerFunc {
@@ -297,10 +415,13 @@ func (h *TrafficViolationHandlers) GetMyViolations() echo.HandlerFunc {
 // @Param        vehicle_id  path  string  true  "Vehicle Registration ID"
 // @Param        page        query int     false "Page number"
 // @Param        size        query int     false "Page size"
+// @Param        sort        query string  false "Sort fields, comma separated, - prefix for descending, e.g. -created_at"
+// @Param        cursor      query string  false "Cursor of the next page, empty for the first one, switches to cursor pagination"
+// @Param        with_total  query bool    false "Return an approximate total_count on cursor pages"
 // @Success      200         {object}  models.TrafficViolationList
-// @Failure      400         {object}  httpErrors.RestError
-// @Failure      401         {object}  httpErrors.RestError
-// @Failure      404         {object}  httpErrors.RestError
+// @Failure      400         {object}  httpErrors.Problem
+// @Failure      401         {object}  httpErrors.Problem
+// @Failure      404         {object}  httpErrors.Problem
 // @Security     JWT
 // @Router       /traffic/me/{vehicle_id}/vehicle [get]
 func (h *TrafficViolationHandlers) GetViolationsByMyVehicle() echo.HandlerFunc {
@@ -332,10 +453,10 @@ func (h *TrafficViolationHandlers) GetViolationsByMyVehicle() echo.HandlerFunc {
 // @Produce      json
 // @Param        id    path      string  true  "Traffic Violation ID (UUID)"
 // @Success      200   {object}  models.TrafficViolation
-// @Failure      400   {object}  httpErrors.RestError
-// @Failure      401   {object}  httpErrors.RestError
-// @Failure      404   {object}  httpErrors.RestError
-// @Failure      500   {object}  httpErrors.RestError
+// @Failure      400   {object}  httpErrors.Problem
+// @Failure      401   {object}  httpErrors.Problem
+// @Failure      404   {object}  httpErrors.Problem
+// @Failure      500   {object}  httpErrors.Problem
 // @Security     JWT
 // @Router       /traffic/me/{id} [get]
 func (h *TrafficViolationHandlers) GetMyTrafficViolationByID() echo.HandlerFunc {
@@ -357,15 +478,80 @@ func (h *TrafficViolationHandlers) GetMyTrafficViolationByID() echo.HandlerFunc
 	}
 }
 
+// @Summary      Get the owner of the vehicle on the violation date
+// @Description  Returns the violation with the owner of the vehicle when it was recorded, from the vehicle ownership history. Officers only
+// @Tags         traffic-violation
+// @Produce      json
+// @Param        id    path      string  true  "Traffic Violation ID (UUID)"
+// @Success      200   {object}  models.ViolationOwner
+// @Failure      400   {object}  httpErrors.Problem
+// @Failure      401   {object}  httpErrors.Problem
+// @Failure      403   {object}  httpErrors.Problem
+// @Failure      404   {object}  httpErrors.Problem
+// @Failure      500   {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /traffic/{id}/owner [get]
+func (h *TrafficViolationHandlers) GetViolationOwner() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		violationID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		owner, err := h.TrafficViolationUC.GetViolationOwner(ctx, violationID)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, owner)
+	}
+}
+
+// @Summary      Report a camera detection
+// @Description  Records a plate read by a road camera. When the vehicle is reported stolen the officers whose jurisdiction covers the vehicle or the camera are alerted in the background. Officers only
+// @Tags         traffic-violation
+// @Accept       json
+// @Produce      json
+// @Param        detection  body      models.VehicleDetection  true  "Plate, camera and place of the detection"
+// @Success      202        {object}  models.VehicleDetection
+// @Failure      400        {object}  httpErrors.Problem
+// @Failure      401        {object}  httpErrors.Problem
+// @Failure      403        {object}  httpErrors.Problem
+// @Failure      500        {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /traffic/detections [post]
+func (h *TrafficViolationHandlers) ReportDetection() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		d := &models.VehicleDetection{}
+		if err := c.Bind(d); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		detection, err := h.TrafficViolationUC.ReportDetection(c.Request().Context(), d)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+		return c.JSON(http.StatusAccepted, detection)
+	}
+}
+
 // @Summary      Get traffic violations related to user's driving license
 // @Description  Returns violations on vehicles owned by the person whose driving license has the same wallet address
 // @Tags         User
 // @Produce      json
 // @Param        page  query     int  false  "Page number"
 // @Param        size  query     int  false  "Page size"
+// @Param        sort  query     string false  "Sort fields, comma separated, - prefix for descending, e.g. -created_at"
+// @Param        cursor  query     string false  "Cursor of the next page, empty for the first one, switches to cursor pagination"
+// @Param        with_total query     bool   false  "Return an approximate total_count on cursor pages"
 // @Success      200   {object}  models.TrafficViolationList
-// @Failure      400   {object}  httpErrors.RestError
-// @Failure      401   {object}  httpErrors.RestError
+// @Failure      400   {object}  httpErrors.Problem
+// @Failure      401   {object}  httpErrors.Problem
 // @Security     JWT
 // @Router       /traffic/violation [get]
 func (h *TrafficViolationHandlers) GetViolationsByMyLicense() echo.HandlerFunc {
@@ -385,3 +571,136 @@ func (h *TrafficViolationHandlers) GetViolationsByMyLicense() echo.HandlerFunc {
 		return c.JSON(http.StatusOK, list)
 	}
 }
+
+// @Summary      Violation hotspots by district
+// @Description  Returns violation counts per province/district as a GeoJSON FeatureCollection of points (average location).
+// @Tags         traffic-violation
+// @Produce      json
+// @Param        from      query     string  false  "Start of time window (RFC3339 or YYYY-MM-DD)"
+// @Param        to        query     string  false  "End of time window, exclusive (RFC3339 or YYYY-MM-DD)"
+// @Param        type      query     string  false  "Violation type"
+// @Param        province  query     string  false  "Province"
+// @Success      200       {object}  geo.FeatureCollection
+// @Failure      400       {object}  httpErrors.Problem
+// @Failure      500       {object}  httpErrors.Problem
+// @Router       /traffic/hotspots/districts [get]
+func (h *TrafficViolationHandlers) GetDistrictHotspots() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		hq, err := getHotspotQueryFromCtx(c)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(httpErrors.NewBadRequestError(err.Error())))
+		}
+
+		fc, err := h.TrafficViolationUC.GetDistrictHotspots(ctx, hq)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, fc)
+	}
+}
+
+// @Summary      Violation heatmap
+// @Description  Returns a grid-based heatmap of violations inside a bounding box and time window as GeoJSON polygons.
+// @Tags         traffic-violation
+// @Produce      json
+// @Param        bbox  query     string   true   "Bounding box minLng,minLat,maxLng,maxLat"
+// @Param        cell  query     number   false  "Grid cell size in degrees, at least 0.0001 (default: 0.01)"
+// @Param        from  query     string   false  "Start of time window (default: 30 days before to)"
+// @Param        to    query     string   false  "End of time window, exclusive (default: now)"
+// @Param        type  query     string   false  "Violation type"
+// @Success      200   {object}  geo.FeatureCollection
+// @Failure      400   {object}  httpErrors.Problem
+// @Failure      500   {object}  httpErrors.Problem
+// @Router       /traffic/hotspots/heatmap [get]
+func (h *TrafficViolationHandlers) GetViolationHeatmap() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		hq, err := getHotspotQueryFromCtx(c)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(httpErrors.NewBadRequestError(err.Error())))
+		}
+
+		fc, err := h.TrafficViolationUC.GetViolationHeatmap(ctx, hq)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, fc)
+	}
+}
+
+// @Summary      Top road segments by violations
+// @Description  Returns the top N road segments (normalized address) ranked by violation count, optionally for one violation type.
+// @Tags         traffic-violation
+// @Produce      json
+// @Param        type      query     string  false  "Violation type"
+// @Param        limit     query     int     false  "Number of segments (default: 10, max: 100)"
+// @Param        from      query     string  false  "Start of time window"
+// @Param        to        query     string  false  "End of time window, exclusive"
+// @Param        province  query     string  false  "Province"
+// @Success      200       {object}  geo.FeatureCollection
+// @Failure      400       {object}  httpErrors.Problem
+// @Failure      500       {object}  httpErrors.Problem
+// @Router       /traffic/hotspots/roads [get]
+func (h *TrafficViolationHandlers) GetTopRoadSegments() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		hq, err := getHotspotQueryFromCtx(c)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(httpErrors.NewBadRequestError(err.Error())))
+		}
+
+		fc, err := h.TrafficViolationUC.GetTopRoadSegments(ctx, hq)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, fc)
+	}
+}
+
+// Read hotspot filters from query params
+func getHotspotQueryFromCtx(c echo.Context) (*models.ViolationHotspotQuery, error) {
+	hq := &models.ViolationHotspotQuery{
+		Type:     c.QueryParam("type"),
+		Province: c.QueryParam("province"),
+	}
+
+	var err error
+	if hq.From, err = utils.ParseTimeParam(c.QueryParam("from")); err != nil {
+		return nil, err
+	}
+	if hq.To, err = utils.ParseTimeParam(c.QueryParam("to")); err != nil {
+		return nil, err
+	}
+
+	if bbox := c.QueryParam("bbox"); bbox != "" {
+		box, err := geo.ParseBoundingBox(bbox)
+		if err != nil {
+			return nil, err
+		}
+		hq.BBox = &box
+	}
+
+	if cell := c.QueryParam("cell"); cell != "" {
+		if hq.CellSize, err = strconv.ParseFloat(cell, 64); err != nil {
+			return nil, err
+		}
+	}
+
+	if limit := c.QueryParam("limit"); limit != "" {
+		if hq.Limit, err = strconv.Atoi(limit); err != nil {
+			return nil, err
+		}
+	}
+
+	return hq, nil
+}
@@ -15,12 +15,22 @@ type Repository interface {
 	GetTrafficViolationById(ctx context.Context, Id uuid.UUID) (*models.TrafficViolation, error)
 	GetAllTrafficViolation(ctx context.Context, pq *utils.PaginationQuery) (*models.TrafficViolationList, error)
 	SearchTrafficViolation(ctx context.Context, vpn string, query *utils.PaginationQuery) (*models.TrafficViolationList, error)
+	CountTrafficViolations(ctx context.Context, vpn string, query *utils.PaginationQuery) (int, error)
+	StreamTrafficViolations(ctx context.Context, vpn string, query *utils.PaginationQuery, fn func(tv *models.TrafficViolation) error) error
 	GetTrafficViolationStats(ctx context.Context) (*models.TrafficViolationStats, error)
 	GetTrafficViolationStatusStats(ctx context.Context) ([]*models.TrafficViolationStatusStats, error)
+	GetViolationSeries(ctx context.Context, sq *utils.StatsQuery) ([]*models.TimeSeriesPoint, error)
+	GetFinesCollectedSeries(ctx context.Context, sq *utils.StatsQuery) ([]*models.TimeSeriesPoint, error)
 	GetViolationsByVehiclePlateNo(ctx context.Context, plateNo string, pq *utils.PaginationQuery) (*models.TrafficViolationList, error)
 	GetMyViolationsByOwnerID(ctx context.Context, ownerID uuid.UUID, pq *utils.PaginationQuery) (*models.TrafficViolationList, error)
 	GetMyViolationsByWallet(ctx context.Context, wallet string, pq *utils.PaginationQuery) (*models.TrafficViolationList, error)
 	GetVehiclePlateNoIfOwned(ctx context.Context, vehicleID, ownerID uuid.UUID) (string, error)
 	GetTrafficViolationByIDAndOwnerID(ctx context.Context, violationID, ownerID uuid.UUID) (*models.TrafficViolation, error)
 	GetViolationsByLicenseWallet(ctx context.Context, wallet string, pq *utils.PaginationQuery) (*models.TrafficViolationList, error)
+	GetViolationOwner(ctx context.Context, violationID uuid.UUID) (*models.VehicleOwnership, error)
+	GetStolenVehicleProvince(ctx context.Context, plateNo string) (string, error)
+
+	GetViolationCountByDistrict(ctx context.Context, hq *models.ViolationHotspotQuery) ([]*models.DistrictViolationCount, error)
+	GetViolationHeatmap(ctx context.Context, hq *models.ViolationHotspotQuery) ([]*models.ViolationGridCell, error)
+	GetTopRoadSegments(ctx context.Context, hq *models.ViolationHotspotQuery) ([]*models.RoadSegmentViolationCount, error)
 }
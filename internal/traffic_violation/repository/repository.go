@@ -21,10 +21,14 @@ func NewTrafficViolationRepo(db *sqlx.DB) trafficviolation.Repository {
 }
 
 func (r *TrafficViolationRepo) CreateTrafficViolation(ctx context.Context, tv *models.TrafficViolation) (*models.TrafficViolation, error) {
+	if err := utils.CheckJurisdiction(ctx, r.db, tv.Province, &tv.District); err != nil {
+		return nil, err
+	}
+
 	t := &models.TrafficViolation{}
 	if err := r.db.QueryRowxContext(ctx, createTrafficViolationQuery,
-		tv.Id, tv.VehiclePlateNo, tv.Date, tv.Type, tv.Address, tv.Description, tv.Points, tv.FineAmount, tv.ExpiryDate,
-		tv.Status, tv.Version, tv.CreatorId, tv.ModifierId, tv.CreatedAt, tv.UpdatedAt, tv.Active,
+		tv.Id, tv.VehiclePlateNo, tv.Date, tv.Type, tv.Address, tv.Latitude, tv.Longitude, tv.Province, tv.District,
+		tv.Description, tv.Points, tv.FineAmount, tv.ExpiryDate, tv.Status, tv.Version, tv.CreatorId, tv.ModifierId, tv.CreatedAt, tv.UpdatedAt, tv.Active,
 	).StructScan(t); err != nil {
 		return nil, errors.Wrap(err, "TrafficViolationRepo.CreateTrafficViolation.StructScan")
 	}
@@ -32,10 +36,17 @@ func (r *TrafficViolationRepo) CreateTrafficViolation(ctx context.Context, tv *m
 }
 
 func (r *TrafficViolationRepo) UpdateTrafficViolation(ctx context.Context, tv *models.TrafficViolation) (*models.TrafficViolation, error) {
+	// the current area is checked by the query, a moved violation must also land inside the jurisdiction
+	if tv.Province != "" {
+		if err := utils.CheckJurisdiction(ctx, r.db, tv.Province, &tv.District); err != nil {
+			return nil, err
+		}
+	}
+
 	t := &models.TrafficViolation{}
 	if err := r.db.QueryRowxContext(ctx, updateTrafficViolationQuery,
-		tv.VehiclePlateNo, tv.Date, tv.Type, tv.Address, tv.Description, tv.Points, tv.FineAmount, tv.ExpiryDate,
-		tv.Status, tv.ModifierId, tv.UpdatedAt, tv.Id,
+		tv.VehiclePlateNo, tv.Date, tv.Type, tv.Address, tv.Latitude, tv.Longitude, tv.Province, tv.District,
+		tv.Description, tv.Points, tv.FineAmount, tv.ExpiryDate, tv.Status, tv.ModifierId, tv.UpdatedAt, tv.Id, utils.JurisdictionFromCtx(ctx),
 	).StructScan(t); err != nil {
 		return nil, errors.Wrap(err, "TrafficViolationRepo.UpdateTrafficViolation.StructScan")
 	}
@@ -44,7 +55,7 @@ func (r *TrafficViolationRepo) UpdateTrafficViolation(ctx context.Context, tv *m
 
 func (r *TrafficViolationRepo) DeleteTrafficViolation(ctx context.Context, tv *models.TrafficViolation) (*models.TrafficViolation, error) {
 	t := &models.TrafficViolation{}
-	if err := r.db.QueryRowxContext(ctx, deleteTrafficViolationQuery, tv.ModifierId, tv.UpdatedAt, tv.Id).StructScan(t); err != nil {
+	if err := r.db.QueryRowxContext(ctx, deleteTrafficViolationQuery, tv.ModifierId, tv.UpdatedAt, tv.Id, utils.JurisdictionFromCtx(ctx)).StructScan(t); err != nil {
 		return nil, errors.Wrap(err, "TrafficViolationRepo.DeleteTrafficViolation.StructScan")
 	}
 	return t, nil
@@ -52,19 +63,25 @@ func (r *TrafficViolationRepo) DeleteTrafficViolation(ctx context.Context, tv *m
 
 func (r *TrafficViolationRepo) GetTrafficViolationById(ctx context.Context, Id uuid.UUID) (*models.TrafficViolation, error) {
 	t := &models.TrafficViolation{}
-	if err := r.db.GetContext(ctx, t, getTrafficViolationByIdQuery, Id); err != nil {
+	if err := r.db.GetContext(ctx, t, getTrafficViolationByIdQuery, Id, utils.JurisdictionFromCtx(ctx)); err != nil {
 		return nil, errors.Wrap(err, "TrafficViolationRepo.DeleteTrafficViolation.StructScan")
 	}
 	return t, nil
 }
 
 func (r *TrafficViolationRepo) GetAllTrafficViolation(ctx context.Context, pq *utils.PaginationQuery) (*models.TrafficViolationList, error) {
-	var totalCount int
-	if err := r.db.GetContext(ctx, &totalCount, getTrafficViolationTotalCount); err != nil {
+	lc, err := pq.ListClause(trafficViolationListSpec.WithDefaultSort("updated_at,created_at"), 1)
+	if err != nil {
+		return nil, err
+	}
+	agency := utils.JurisdictionFromCtx(ctx)
+
+	totalCount, err := lc.Total(ctx, r.db, getTrafficViolationTotalCount, getTrafficViolationQuery, agency)
+	if err != nil {
 		return nil, errors.Wrap(err, "TrafficViolationRepo.GetAllTrafficViolation.GetContext.totalCount")
 	}
 
-	if totalCount == 0 {
+	if lc.Empty(totalCount) {
 		return &models.TrafficViolationList{
 			TotalCount:       totalCount,
 			TotalPages:       utils.GetTotalPage(totalCount, pq.GetSize()),
@@ -76,7 +93,7 @@ func (r *TrafficViolationRepo) GetAllTrafficViolation(ctx context.Context, pq *u
 	}
 
 	var NewTrafficViolation = make([]*models.TrafficViolation, 0, pq.GetSize())
-	rows, err := r.db.QueryxContext(ctx, getTrafficViolationQuery, pq.GetOffset(), pq.GetLimit())
+	rows, err := r.db.QueryxContext(ctx, lc.Page(getTrafficViolationQuery), lc.PageArgs(pq, agency)...)
 	if err != nil {
 		return nil, errors.Wrap(err, "TrafficViolationRepo.GetAllTrafficViolation.NewTrafficViolation")
 	}
@@ -94,23 +111,76 @@ func (r *TrafficViolationRepo) GetAllTrafficViolation(ctx context.Context, pq *u
 		return nil, errors.Wrap(err, "TrafficViolationRepo.rows.err")
 	}
 
+	NewTrafficViolation, nextCursor, err := utils.NextCursor(lc, NewTrafficViolation)
+	if err != nil {
+		return nil, errors.Wrap(err, "TrafficViolationRepo.GetAllTrafficViolation.NextCursor")
+	}
+
 	return &models.TrafficViolationList{
 		TotalCount:       totalCount,
 		TotalPages:       utils.GetTotalPage(totalCount, pq.GetSize()),
 		Page:             pq.GetPage(),
 		Size:             pq.GetSize(),
-		HasMore:          utils.GetHasMore(pq.GetPage(), totalCount, pq.GetSize()),
+		HasMore:          lc.HasMore(pq, totalCount, nextCursor),
+		NextCursor:       nextCursor,
 		TrafficViolation: NewTrafficViolation,
 	}, nil
 }
 
-func (r *TrafficViolationRepo) SearchTrafficViolation(ctx context.Context, vpn string, query *utils.PaginationQuery) (*models.TrafficViolationList, error) {
+func (r *TrafficViolationRepo) CountTrafficViolations(ctx context.Context, vpn string, query *utils.PaginationQuery) (int, error) {
+	lc, err := query.ListClause(trafficViolationListSpec.WithDefaultSort("vehicle_no,-date"), 2, "vehicle_no")
+	if err != nil {
+		return 0, err
+	}
+
 	var totalCount int
-	if err := r.db.GetContext(ctx, &totalCount, findVehiclePlateNoCount, vpn); err != nil {
+	if err := r.db.GetContext(ctx, &totalCount, lc.Count(findVehiclePlateNoCount), lc.Args(vpn, utils.JurisdictionFromCtx(ctx))...); err != nil {
+		return 0, errors.Wrap(err, "TrafficViolationRepo.CountTrafficViolations.GetContext")
+	}
+	return totalCount, nil
+}
+
+func (r *TrafficViolationRepo) StreamTrafficViolations(ctx context.Context, vpn string, query *utils.PaginationQuery, fn func(tv *models.TrafficViolation) error) error {
+	lc, err := query.ListClause(trafficViolationListSpec.WithDefaultSort("vehicle_no,-date"), 2, "vehicle_no")
+	if err != nil {
+		return err
+	}
+
+	rows, err := r.db.QueryxContext(ctx, lc.All(exportTrafficViolations), lc.Args(vpn, utils.JurisdictionFromCtx(ctx))...)
+	if err != nil {
+		return errors.Wrap(err, "TrafficViolationRepo.StreamTrafficViolations.QueryxContext")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		n := &models.TrafficViolation{}
+		if err = rows.StructScan(n); err != nil {
+			return errors.Wrap(err, "TrafficViolationRepo.StreamTrafficViolations.StructScan")
+		}
+		if err = fn(n); err != nil {
+			return err
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return errors.Wrap(err, "TrafficViolationRepo.StreamTrafficViolations.rows.Err")
+	}
+	return nil
+}
+
+func (r *TrafficViolationRepo) SearchTrafficViolation(ctx context.Context, vpn string, query *utils.PaginationQuery) (*models.TrafficViolationList, error) {
+	lc, err := query.ListClause(trafficViolationListSpec.WithDefaultSort("vehicle_no"), 2, "vehicle_no")
+	if err != nil {
+		return nil, err
+	}
+	agency := utils.JurisdictionFromCtx(ctx)
+
+	totalCount, err := lc.Total(ctx, r.db, findVehiclePlateNoCount, searchByVehicleNo, vpn, agency)
+	if err != nil {
 		return nil, errors.Wrap(err, "TrafficViolationRepo.GetAllTrafficViolation.GetContext.totalCount")
 	}
 
-	if totalCount == 0 {
+	if lc.Empty(totalCount) {
 		return &models.TrafficViolationList{
 			TotalCount:       totalCount,
 			TotalPages:       utils.GetTotalPage(totalCount, query.GetSize()),
@@ -122,7 +192,7 @@ func (r *TrafficViolationRepo) SearchTrafficViolation(ctx context.Context, vpn s
 	}
 
 	var NewTrafficViolation = make([]*models.TrafficViolation, 0, query.GetSize())
-	rows, err := r.db.QueryxContext(ctx, searchByVehicleNo, vpn, query.GetOffset(), query.GetLimit())
+	rows, err := r.db.QueryxContext(ctx, lc.Page(searchByVehicleNo), lc.PageArgs(query, vpn, agency)...)
 	if err != nil {
 		return nil, errors.Wrap(err, "TrafficViolationRepo.GetAllTrafficViolation.NewTrafficViolation")
 	}
@@ -140,19 +210,25 @@ func (r *TrafficViolationRepo) SearchTrafficViolation(ctx context.Context, vpn s
 		return nil, errors.Wrap(err, "TrafficViolationRepo.rows.err")
 	}
 
+	NewTrafficViolation, nextCursor, err := utils.NextCursor(lc, NewTrafficViolation)
+	if err != nil {
+		return nil, errors.Wrap(err, "TrafficViolationRepo.SearchTrafficViolation.NextCursor")
+	}
+
 	return &models.TrafficViolationList{
 		TotalCount:       totalCount,
 		TotalPages:       utils.GetTotalPage(totalCount, query.GetSize()),
 		Page:             query.GetPage(),
 		Size:             query.GetSize(),
-		HasMore:          utils.GetHasMore(query.GetPage(), totalCount, query.GetSize()),
+		HasMore:          lc.HasMore(query, totalCount, nextCursor),
+		NextCursor:       nextCursor,
 		TrafficViolation: NewTrafficViolation,
 	}, nil
 }
 
 func (r *TrafficViolationRepo) GetTrafficViolationStats(ctx context.Context) (*models.TrafficViolationStats, error) {
 	var stats models.TrafficViolationStats
-	err := r.db.GetContext(ctx, &stats, getTrafficViolationStatsQuery)
+	err := r.db.GetContext(ctx, &stats, getTrafficViolationStatsQuery, utils.JurisdictionFromCtx(ctx))
 	if err != nil {
 		return nil, errors.Wrap(err, "TrafficViolationRepo.GetTrafficViolationStats")
 	}
@@ -162,7 +238,7 @@ func (r *TrafficViolationRepo) GetTrafficViolationStats(ctx context.Context) (*m
 func (r *TrafficViolationRepo) GetTrafficViolationStatusStats(ctx context.Context) ([]*models.TrafficViolationStatusStats, error) {
 	var stats []*models.TrafficViolationStatusStats
 
-	err := r.db.SelectContext(ctx, &stats, getTrafficViolationStatusStatsQuery)
+	err := r.db.SelectContext(ctx, &stats, getTrafficViolationStatusStatsQuery, utils.JurisdictionFromCtx(ctx))
 	if err != nil {
 		return nil, errors.Wrap(err, "TrafficViolationRepo.GetTrafficViolationStatusStats.SelectContext")
 	}
@@ -170,9 +246,34 @@ func (r *TrafficViolationRepo) GetTrafficViolationStatusStats(ctx context.Contex
 	return stats, nil
 }
 
+func (r *TrafficViolationRepo) GetViolationSeries(ctx context.Context, sq *utils.StatsQuery) ([]*models.TimeSeriesPoint, error) {
+	var points []*models.TimeSeriesPoint
+	if err := r.db.SelectContext(ctx, &points, getViolationSeriesQuery,
+		sq.GroupBy, sq.From, sq.To, sq.City, sq.ViolationType, utils.JurisdictionFromCtx(ctx),
+	); err != nil {
+		return nil, errors.Wrap(err, "TrafficViolationRepo.GetViolationSeries.SelectContext")
+	}
+	return points, nil
+}
+
+func (r *TrafficViolationRepo) GetFinesCollectedSeries(ctx context.Context, sq *utils.StatsQuery) ([]*models.TimeSeriesPoint, error) {
+	var points []*models.TimeSeriesPoint
+	if err := r.db.SelectContext(ctx, &points, getFinesCollectedSeriesQuery,
+		sq.GroupBy, sq.From, sq.To, sq.City, sq.ViolationType, utils.JurisdictionFromCtx(ctx),
+	); err != nil {
+		return nil, errors.Wrap(err, "TrafficViolationRepo.GetFinesCollectedSeries.SelectContext")
+	}
+	return points, nil
+}
+
 func (r *TrafficViolationRepo) GetViolationsByVehiclePlateNo(ctx context.Context, plateNo string, pq *utils.PaginationQuery) (*models.TrafficViolationList, error) {
-	var total int
-	if err := r.db.GetContext(ctx, &total, getTotalByPlateNo, plateNo); err != nil {
+	lc, err := pq.ListClause(trafficViolationListSpec, 1, "vehicle_no")
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := lc.Total(ctx, r.db, getTotalByPlateNo, getViolationsByPlateNo, plateNo)
+	if err != nil {
 		return nil, errors.Wrap(err, "GetViolationsByVehiclePlateNo.total")
 	}
 
@@ -185,22 +286,30 @@ func (r *TrafficViolationRepo) GetViolationsByVehiclePlateNo(ctx context.Context
 		TrafficViolation: []*models.TrafficViolation{},
 	}
 
-	if total == 0 {
+	if lc.Empty(total) {
 		return list, nil
 	}
 
 	var items []*models.TrafficViolation
-	if err := r.db.SelectContext(ctx, &items, getViolationsByPlateNo, plateNo, pq.GetOffset(), pq.GetLimit()); err != nil {
+	if err := r.db.SelectContext(ctx, &items, lc.Page(getViolationsByPlateNo), lc.PageArgs(pq, plateNo)...); err != nil {
 		return nil, errors.Wrap(err, "GetViolationsByVehiclePlateNo.Select")
 	}
 
-	list.TrafficViolation = items
+	if list.TrafficViolation, list.NextCursor, err = utils.NextCursor(lc, items); err != nil {
+		return nil, errors.Wrap(err, "GetViolationsByVehiclePlateNo.NextCursor")
+	}
+	list.HasMore = lc.HasMore(pq, total, list.NextCursor)
 	return list, nil
 }
 
 func (r *TrafficViolationRepo) GetMyViolationsByOwnerID(ctx context.Context, ownerID uuid.UUID, pq *utils.PaginationQuery) (*models.TrafficViolationList, error) {
-	var total int
-	if err := r.db.GetContext(ctx, &total, getTotalViolationsByOwnerID, ownerID); err != nil {
+	lc, err := pq.ListClause(trafficViolationListSpec, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := lc.Total(ctx, r.db, getTotalViolationsByOwnerID, getViolationsByOwnerID, ownerID)
+	if err != nil {
 		return nil, errors.Wrap(err, "GetMyViolationsByOwnerID.total")
 	}
 
@@ -213,22 +322,30 @@ func (r *TrafficViolationRepo) GetMyViolationsByOwnerID(ctx context.Context, own
 		TrafficViolation: []*models.TrafficViolation{},
 	}
 
-	if total == 0 {
+	if lc.Empty(total) {
 		return list, nil
 	}
 
 	var items []*models.TrafficViolation
-	if err := r.db.SelectContext(ctx, &items, getViolationsByOwnerID, ownerID, pq.GetOffset(), pq.GetLimit()); err != nil {
+	if err := r.db.SelectContext(ctx, &items, lc.Page(getViolationsByOwnerID), lc.PageArgs(pq, ownerID)...); err != nil {
 		return nil, errors.Wrap(err, "GetMyViolationsByOwnerID.Select")
 	}
 
-	list.TrafficViolation = items
+	if list.TrafficViolation, list.NextCursor, err = utils.NextCursor(lc, items); err != nil {
+		return nil, errors.Wrap(err, "GetMyViolationsByOwnerID.NextCursor")
+	}
+	list.HasMore = lc.HasMore(pq, total, list.NextCursor)
 	return list, nil
 }
 
 func (r *TrafficViolationRepo) GetMyViolationsByWallet(ctx context.Context, wallet string, pq *utils.PaginationQuery) (*models.TrafficViolationList, error) {
-	var total int
-	if err := r.db.GetContext(ctx, &total, getTotalViolationsByWallet, wallet); err != nil {
+	lc, err := pq.ListClause(trafficViolationListSpec, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := lc.Total(ctx, r.db, getTotalViolationsByWallet, getViolationsByWallet, wallet)
+	if err != nil {
 		return nil, errors.Wrap(err, "GetMyViolationsByWallet.total")
 	}
 
@@ -241,16 +358,19 @@ func (r *TrafficViolationRepo) GetMyViolationsByWallet(ctx context.Context, wall
 		TrafficViolation: []*models.TrafficViolation{},
 	}
 
-	if total == 0 {
+	if lc.Empty(total) {
 		return list, nil
 	}
 
 	var items []*models.TrafficViolation
-	if err := r.db.SelectContext(ctx, &items, getViolationsByWallet, wallet, pq.GetOffset(), pq.GetLimit()); err != nil {
+	if err := r.db.SelectContext(ctx, &items, lc.Page(getViolationsByWallet), lc.PageArgs(pq, wallet)...); err != nil {
 		return nil, errors.Wrap(err, "GetMyViolationsByWallet.Select")
 	}
 
-	list.TrafficViolation = items
+	if list.TrafficViolation, list.NextCursor, err = utils.NextCursor(lc, items); err != nil {
+		return nil, errors.Wrap(err, "GetMyViolationsByWallet.NextCursor")
+	}
+	list.HasMore = lc.HasMore(pq, total, list.NextCursor)
 	return list, nil
 }
 
@@ -270,7 +390,7 @@ func (r *TrafficViolationRepo) GetVehiclePlateNoIfOwned(ctx context.Context, veh
 
 func (r *TrafficViolationRepo) GetTrafficViolationByIDAndOwnerID(ctx context.Context, violationID, ownerID uuid.UUID) (*models.TrafficViolation, error) {
 	v := &models.TrafficViolation{}
-	err := r.db.GetContext(ctx, v, getTrafficViolationByIDAndOwner, violationID, ownerID)
+	err := r.db.GetContext(ctx, v, getTrafficViolationByIDAndOwner, ownerID, violationID)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -280,9 +400,36 @@ func (r *TrafficViolationRepo) GetTrafficViolationByIDAndOwnerID(ctx context.Con
 	return v, nil
 }
 
+// Owner of the vehicle on the violation date, nil when no recorded owner held the plate then
+func (r *TrafficViolationRepo) GetViolationOwner(ctx context.Context, violationID uuid.UUID) (*models.VehicleOwnership, error) {
+	o := &models.VehicleOwnership{}
+	err := r.db.GetContext(ctx, o, getViolationOwner, violationID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "TrafficViolationRepo.GetViolationOwner.GetContext")
+	}
+	return o, nil
+}
+
+// Whether an active vehicle with the plate is reported stolen
+func (r *TrafficViolationRepo) GetStolenVehicleProvince(ctx context.Context, plateNo string) (string, error) {
+	var province string
+	if err := r.db.GetContext(ctx, &province, getStolenVehicleProvince, plateNo); err != nil {
+		return "", errors.Wrap(err, "TrafficViolationRepo.GetStolenVehicleProvince.GetContext")
+	}
+	return province, nil
+}
+
 func (r *TrafficViolationRepo) GetViolationsByLicenseWallet(ctx context.Context, wallet string, pq *utils.PaginationQuery) (*models.TrafficViolationList, error) {
-	var total int
-	if err := r.db.GetContext(ctx, &total, getTotalViolationsByLicenseWallet, wallet); err != nil {
+	lc, err := pq.ListClause(trafficViolationListSpec, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := lc.Total(ctx, r.db, getTotalViolationsByLicenseWallet, getViolationsByLicenseWallet, wallet)
+	if err != nil {
 		return nil, errors.Wrap(err, "GetViolationsByLicenseWallet.total")
 	}
 
@@ -295,15 +442,48 @@ func (r *TrafficViolationRepo) GetViolationsByLicenseWallet(ctx context.Context,
 		TrafficViolation: []*models.TrafficViolation{},
 	}
 
-	if total == 0 {
+	if lc.Empty(total) {
 		return list, nil
 	}
 
 	var items []*models.TrafficViolation
-	if err := r.db.SelectContext(ctx, &items, getViolationsByLicenseWallet, wallet, pq.GetOffset(), pq.GetLimit()); err != nil {
+	if err := r.db.SelectContext(ctx, &items, lc.Page(getViolationsByLicenseWallet), lc.PageArgs(pq, wallet)...); err != nil {
 		return nil, errors.Wrap(err, "GetViolationsByLicenseWallet.Select")
 	}
 
-	list.TrafficViolation = items
+	if list.TrafficViolation, list.NextCursor, err = utils.NextCursor(lc, items); err != nil {
+		return nil, errors.Wrap(err, "GetViolationsByLicenseWallet.NextCursor")
+	}
+	list.HasMore = lc.HasMore(pq, total, list.NextCursor)
 	return list, nil
 }
+
+func (r *TrafficViolationRepo) GetViolationCountByDistrict(ctx context.Context, hq *models.ViolationHotspotQuery) ([]*models.DistrictViolationCount, error) {
+	var items []*models.DistrictViolationCount
+	if err := r.db.SelectContext(ctx, &items, getViolationCountByDistrictQuery,
+		hq.From, hq.To, hq.Type, hq.Province, utils.JurisdictionFromCtx(ctx),
+	); err != nil {
+		return nil, errors.Wrap(err, "TrafficViolationRepo.GetViolationCountByDistrict.SelectContext")
+	}
+	return items, nil
+}
+
+func (r *TrafficViolationRepo) GetViolationHeatmap(ctx context.Context, hq *models.ViolationHotspotQuery) ([]*models.ViolationGridCell, error) {
+	var cells []*models.ViolationGridCell
+	if err := r.db.SelectContext(ctx, &cells, getViolationHeatmapQuery,
+		hq.CellSize, hq.BBox.MinLat, hq.BBox.MaxLat, hq.BBox.MinLng, hq.BBox.MaxLng, hq.From, hq.To, hq.Type, utils.JurisdictionFromCtx(ctx),
+	); err != nil {
+		return nil, errors.Wrap(err, "TrafficViolationRepo.GetViolationHeatmap.SelectContext")
+	}
+	return cells, nil
+}
+
+func (r *TrafficViolationRepo) GetTopRoadSegments(ctx context.Context, hq *models.ViolationHotspotQuery) ([]*models.RoadSegmentViolationCount, error) {
+	var items []*models.RoadSegmentViolationCount
+	if err := r.db.SelectContext(ctx, &items, getTopRoadSegmentsQuery,
+		hq.From, hq.To, hq.Type, hq.Province, hq.Limit, utils.JurisdictionFromCtx(ctx),
+	); err != nil {
+		return nil, errors.Wrap(err, "TrafficViolationRepo.GetTopRoadSegments.SelectContext")
+	}
+	return items, nil
+}
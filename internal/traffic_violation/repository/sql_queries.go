@@ -1,13 +1,37 @@
 package repository
 
+import "github.com/adohong4/driving-license/pkg/utils"
+
+// Filters and sorts accepted by the violation lists, filter, sort and cursor clauses are appended to the queries below
+var trafficViolationListSpec = &utils.ListSpec{
+	Fields: map[string]utils.ListField{
+		"vehicle_no":  {Column: "tv.vehicle_no", Type: utils.FilterText, Sortable: true, Keyset: true},
+		"date":        {Column: "tv.date", Type: utils.FilterDate, Sortable: true, Keyset: true},
+		"type":        {Column: "tv.type", Type: utils.FilterExact, Sortable: true, Keyset: true},
+		"address":     {Column: "tv.address", Type: utils.FilterText},
+		"province":    {Column: "tv.province", Type: utils.FilterExact, Sortable: true, Keyset: true},
+		"district":    {Column: "tv.district", Type: utils.FilterExact, Sortable: true, Keyset: true},
+		"points":      {Column: "tv.points", Type: utils.FilterNumber, Sortable: true, Keyset: true},
+		"fine_amount": {Column: "tv.fine_amount", Type: utils.FilterNumber, Sortable: true, Keyset: true},
+		"status":      {Column: "tv.status", Type: utils.FilterExact, Sortable: true, Keyset: true},
+		"expiry_date": {Column: "tv.expiry_date", Type: utils.FilterDate, Sortable: true},
+		"created_at":  {Column: "tv.created_at", Type: utils.FilterDate, Sortable: true, Keyset: true},
+		"updated_at":  {Column: "tv.updated_at", Type: utils.FilterDate, Sortable: true, Keyset: true},
+	},
+	DefaultSort: "-date",
+	IDColumn:    "tv.id",
+}
+
 const (
 	createTrafficViolationQuery = `
     INSERT INTO traffic_violations (
-        id, vehicle_no, date, type, address, description, points, fine_amount, expiry_date, status, 
+        id, vehicle_no, date, type, address, latitude, longitude, province, district,
+        description, points, fine_amount, expiry_date, status, 
         version, creator_id, modifier_id, created_at, updated_at, active
     ) VALUES (
-        $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16
-    ) RETURNING id, vehicle_no, date, type, address, description, points, fine_amount, status, 
+        $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20
+    ) RETURNING id, vehicle_no, date, type, address, latitude, longitude, province, district,
+        description, points, fine_amount, status, 
         version, creator_id, modifier_id, created_at, updated_at, active
     `
 
@@ -18,16 +42,21 @@ const (
         date = COALESCE($2, date),
         type = COALESCE(NULLIF($3, ''), type),
         address = COALESCE($4, address),
-        description = COALESCE(NULLIF($5, ''), description),
-        points = COALESCE($6, points),
-        fine_amount = COALESCE($7, fine_amount),
-        expiry_date = COALESCE($8, expiry_date),
-        status = COALESCE(NULLIF($9, ''), status),
-        modifier_id = COALESCE($10, modifier_id),
+        latitude = COALESCE($5, latitude),
+        longitude = COALESCE($6, longitude),
+        province = COALESCE(NULLIF($7, ''), province),
+        district = COALESCE(NULLIF($8, ''), district),
+        description = COALESCE(NULLIF($9, ''), description),
+        points = COALESCE($10, points),
+        fine_amount = COALESCE($11, fine_amount),
+        expiry_date = COALESCE($12, expiry_date),
+        status = COALESCE(NULLIF($13, ''), status),
+        modifier_id = COALESCE($14, modifier_id),
         version = version + 1,
-        updated_at = $11
-    WHERE id = $12
-    RETURNING id, vehicle_no, date, type, description, points, fine_amount, status, 
+        updated_at = $15
+    WHERE id = $16 AND f_in_jurisdiction($17, province, district)
+    RETURNING id, vehicle_no, date, type, address, latitude, longitude, province, district,
+        description, points, fine_amount, status, 
         version, creator_id, modifier_id, created_at, updated_at, active
     `
 
@@ -38,46 +67,59 @@ const (
         version = version + 1,
         modifier_id = $1,
         updated_at = $2
-    WHERE id = $3
-    RETURNING id, vehicle_no, date, type, description, points, fine_amount, status, 
+    WHERE id = $3 AND f_in_jurisdiction($4, province, district)
+    RETURNING id, vehicle_no, date, type, address, latitude, longitude, province, district,
+        description, points, fine_amount, status, 
         version, creator_id, modifier_id, created_at, updated_at, active
     `
 
 	getTrafficViolationByIdQuery = `
-    SELECT id, vehicle_no, date, type, description, points, fine_amount, status, 
+    SELECT id, vehicle_no, date, type, address, latitude, longitude, province, district,
+        description, points, fine_amount, status, 
         version, creator_id, modifier_id, created_at, updated_at, active
     FROM traffic_violations
-    WHERE id = $1 AND active = true
+    WHERE id = $1 AND active = true AND f_in_jurisdiction($2, province, district)
     `
 
 	getTrafficViolationTotalCount = `
-    SELECT COUNT(id)
-    FROM traffic_violations
-    WHERE active = true
+    SELECT COUNT(tv.id)
+    FROM traffic_violations tv
+    WHERE tv.active = true AND f_in_jurisdiction($1, tv.province, tv.district)
     `
 
 	getTrafficViolationQuery = `
-    SELECT id, vehicle_no, date, type, description, points, fine_amount, status, 
+    SELECT id, vehicle_no, date, type, address, latitude, longitude, province, district,
+        description, points, fine_amount, status, 
         version, creator_id, modifier_id, created_at, updated_at, active
-    FROM traffic_violations
-    WHERE active = true
-	ORDER BY updated_at, created_at OFFSET $1 LIMIT $2
+    FROM traffic_violations tv
+    WHERE tv.active = true AND f_in_jurisdiction($1, tv.province, tv.district)
     `
 
 	findVehiclePlateNoCount = `
     SELECT COUNT(*)
-    FROM traffic_violations
-    WHERE active = true
-    AND vehicle_no ILIKE '%' || $1 || '%'
+    FROM traffic_violations tv
+    WHERE tv.active = true
+    AND regexp_replace(tv.vehicle_no, '[^A-Za-z0-9]', '', 'g') ILIKE '%' || $1 || '%'
+    AND f_in_jurisdiction($2, tv.province, tv.district)
     `
 
 	searchByVehicleNo = `
-    SELECT id, vehicle_no, date, type, description, points, fine_amount, status, 
+    SELECT id, vehicle_no, date, type, address, latitude, longitude, province, district,
+        description, points, fine_amount, status, 
         version, creator_id, modifier_id, created_at, updated_at, active
-    FROM traffic_violations
-    WHERE vehicle_no ILIKE '%' || $1 || '%' AND active = true
-    ORDER BY vehicle_no
-    OFFSET $2 LIMIT $3
+    FROM traffic_violations tv
+    WHERE regexp_replace(tv.vehicle_no, '[^A-Za-z0-9]', '', 'g') ILIKE '%' || $1 || '%' AND tv.active = true
+      AND f_in_jurisdiction($2, tv.province, tv.district)
+    `
+
+	// Export, same filter as searchByVehicleNo without pagination
+	exportTrafficViolations = `
+    SELECT id, vehicle_no, date, type, address, latitude, longitude, province, district,
+        description, points, fine_amount, expiry_date, status, 
+        version, creator_id, modifier_id, created_at, updated_at, active
+    FROM traffic_violations tv
+    WHERE regexp_replace(tv.vehicle_no, '[^A-Za-z0-9]', '', 'g') ILIKE '%' || $1 || '%' AND tv.active = true
+      AND f_in_jurisdiction($2, tv.province, tv.district)
     `
 
 	findVehicleNo = `
@@ -86,61 +128,118 @@ const (
     WHERE vehicle_no = $1 AND active = true
     `
 
+	// Statistic, read from mv_violation_stats (see migrations)
 	getTrafficViolationStatsQuery = `
         SELECT 
-            COUNT(*) AS total_violations,
-            COALESCE(SUM(fine_amount), 0) AS total_fine_amount,
-            COALESCE(SUM(CASE WHEN status = 'completed' THEN fine_amount ELSE 0 END), 0) AS total_paid_fine_amount,
-            COALESCE(SUM(CASE WHEN status != 'cancelled' THEN fine_amount ELSE 0 END), 0) 
-            - COALESCE(SUM(CASE WHEN status = 'completed' THEN fine_amount ELSE 0 END), 0) AS total_unpaid_fine_amount
-        FROM traffic_violations
-        WHERE active = true
+            COALESCE(SUM(count), 0)::bigint AS total_violations,
+            COALESCE(SUM(fine_amount), 0)::bigint AS total_fine_amount,
+            COALESCE(SUM(fine_amount) FILTER (WHERE status = 'completed'), 0)::bigint AS total_paid_fine_amount,
+            (COALESCE(SUM(fine_amount) FILTER (WHERE status != 'cancelled'), 0)
+            - COALESCE(SUM(fine_amount) FILTER (WHERE status = 'completed'), 0))::bigint AS total_unpaid_fine_amount
+        FROM mv_violation_stats
+        WHERE f_in_jurisdiction($1, province, district)
     `
 
 	getTrafficViolationStatusStatsQuery = `
         SELECT 
             status,
-            COUNT(*) AS total_count,
-            COALESCE(SUM(fine_amount), 0) AS total_fine_amount,
-            COUNT(*) FILTER (WHERE expiry_date < CURRENT_DATE) AS overdue_count,
-            COALESCE(SUM(fine_amount) FILTER (WHERE expiry_date < CURRENT_DATE), 0) AS overdue_fine_amount,
-            COUNT(*) FILTER (WHERE expiry_date IS NULL OR expiry_date >= CURRENT_DATE) AS not_overdue_count,
-            COALESCE(SUM(fine_amount) FILTER (WHERE expiry_date IS NULL OR expiry_date >= CURRENT_DATE), 0) AS not_overdue_amount
-        FROM traffic_violations
-        WHERE active = true
+            SUM(count)::bigint AS total_count,
+            SUM(fine_amount)::bigint AS total_fine_amount,
+            COALESCE(SUM(count) FILTER (WHERE is_overdue), 0)::bigint AS overdue_count,
+            COALESCE(SUM(fine_amount) FILTER (WHERE is_overdue), 0)::bigint AS overdue_fine_amount,
+            COALESCE(SUM(count) FILTER (WHERE NOT is_overdue), 0)::bigint AS not_overdue_count,
+            COALESCE(SUM(fine_amount) FILTER (WHERE NOT is_overdue), 0)::bigint AS not_overdue_amount
+        FROM mv_violation_stats
+        WHERE f_in_jurisdiction($1, province, district)
         GROUP BY status
         ORDER BY status
     `
 
+	getViolationSeriesQuery = `
+        SELECT
+            date_trunc($1, date::timestamp) AS period,
+            COUNT(*) AS count,
+            COALESCE(SUM(fine_amount), 0)::bigint AS amount
+        FROM traffic_violations
+        WHERE active = true
+          AND date::timestamp >= $2 AND date::timestamp < $3
+          AND ($4 = '' OR province = $4)
+          AND ($5 = '' OR type = $5)
+          AND f_in_jurisdiction($6, province, district)
+        GROUP BY period
+        ORDER BY period
+    `
+
+	// Paid violations have no payment date, updated_at of completed rows is used instead
+	getFinesCollectedSeriesQuery = `
+        SELECT
+            date_trunc($1, updated_at::timestamp) AS period,
+            COUNT(*) AS count,
+            COALESCE(SUM(fine_amount), 0)::bigint AS amount
+        FROM traffic_violations
+        WHERE active = true
+          AND status = 'completed'
+          AND updated_at::timestamp >= $2 AND updated_at::timestamp < $3
+          AND ($4 = '' OR province = $4)
+          AND ($5 = '' OR type = $5)
+          AND f_in_jurisdiction($6, province, district)
+        GROUP BY period
+        ORDER BY period
+    `
+
 	//-----USER-------------
 	getViolationsByPlateNo = `
-        SELECT *
-        FROM traffic_violations
-        WHERE vehicle_no = $1 AND active = true
-        ORDER BY date DESC
-        OFFSET $2 LIMIT $3
+        SELECT tv.*
+        FROM traffic_violations tv
+        WHERE tv.vehicle_no = $1 AND tv.active = true
     `
 
 	getTotalByPlateNo = `
         SELECT COUNT(*)
-        FROM traffic_violations
-        WHERE vehicle_no = $1 AND active = true
+        FROM traffic_violations tv
+        WHERE tv.vehicle_no = $1 AND tv.active = true
+    `
+
+	// violations are attributed to whoever owned the plate on the violation date, the owner is the user
+	// or one of the user's licenses
+	ownedAtViolationJoin = `
+        JOIN vehicle_ownerships o ON o.vehicle_no = tv.vehicle_no
+            AND o.from_date <= tv.date AND (o.to_date IS NULL OR tv.date < o.to_date)
+    `
+
+	ownedByUserCondition = `
+        (o.owner_id = $1 OR o.identity_no = (
+            SELECT u.identity_no FROM users u WHERE u.id = $1 AND u.identity_no <> ''
+        ))
     `
 
 	getViolationsByOwnerID = `
         SELECT tv.*
-        FROM traffic_violations tv
-        JOIN vehicle_registration vr ON tv.vehicle_no = vr.vehicle_no
-        WHERE vr.owner_id = $1 AND tv.active = true AND vr.active = true
-        ORDER BY tv.date DESC
-        OFFSET $2 LIMIT $3
+        FROM traffic_violations tv` + ownedAtViolationJoin + `
+        WHERE ` + ownedByUserCondition + ` AND tv.active = true
     `
 
 	getTotalViolationsByOwnerID = `
         SELECT COUNT(*)
-        FROM traffic_violations tv
-        JOIN vehicle_registration vr ON tv.vehicle_no = vr.vehicle_no
-        WHERE vr.owner_id = $1 AND tv.active = true AND vr.active = true
+        FROM traffic_violations tv` + ownedAtViolationJoin + `
+        WHERE ` + ownedByUserCondition + ` AND tv.active = true
+    `
+
+	// province of the stolen vehicle with the plate, no rows when the vehicle is not reported stolen
+	getStolenVehicleProvince = `
+        SELECT COALESCE(f_vehicle_province(owner_id, registration_place), '')
+        FROM vehicle_registration
+        WHERE vehicle_no = $1 AND stolen = true AND active = true
+        LIMIT 1
+    `
+
+	// owner of the vehicle on the violation date
+	getViolationOwner = `
+        SELECT o.*
+        FROM traffic_violations tv` + ownedAtViolationJoin + `
+        WHERE tv.id = $1
+        ORDER BY o.from_date DESC
+        LIMIT 1
     `
 
 	getViolationsByWalletAddress = `
@@ -161,8 +260,6 @@ const (
         JOIN vehicle_registration vr ON tv.vehicle_no = vr.vehicle_no
         JOIN driver_licenses dl ON vr.owner_id = dl.creator_id
         WHERE dl.wallet_address = $1 AND tv.active = true AND vr.active = true
-        ORDER BY tv.date DESC
-        OFFSET $2 LIMIT $3
     `
 
 	getTotalViolationsByWallet = `
@@ -175,12 +272,10 @@ const (
 
 	getTrafficViolationByIDAndOwner = `
         SELECT tv.*
-        FROM traffic_violations tv
-        JOIN vehicle_registration vr ON tv.vehicle_no = vr.vehicle_no
-        WHERE tv.id = $1 
-          AND vr.owner_id = $2 
-          AND tv.active = true 
-          AND vr.active = true
+        FROM traffic_violations tv` + ownedAtViolationJoin + `
+        WHERE tv.id = $2
+          AND ` + ownedByUserCondition + `
+          AND tv.active = true
     `
 
 	getViolationsByLicenseWallet = `
@@ -192,8 +287,6 @@ const (
       AND tv.active = true 
       AND vr.active = true 
       AND dl.active = true
-    ORDER BY tv.date DESC
-    OFFSET $2 LIMIT $3
 `
 
 	getTotalViolationsByLicenseWallet = `
@@ -206,4 +299,63 @@ const (
       AND vr.active = true 
       AND dl.active = true
 `
+
+	//-----HOTSPOT ANALYTICS-------------
+	getViolationCountByDistrictQuery = `
+        SELECT
+            province,
+            district,
+            COUNT(*) AS count,
+            COALESCE(SUM(fine_amount), 0) AS total_fine_amount,
+            AVG(latitude) AS latitude,
+            AVG(longitude) AS longitude
+        FROM traffic_violations
+        WHERE active = true
+          AND district <> ''
+          AND ($1::timestamptz IS NULL OR date >= $1)
+          AND ($2::timestamptz IS NULL OR date < $2)
+          AND ($3 = '' OR type = $3)
+          AND ($4 = '' OR province = $4)
+          AND f_in_jurisdiction($5, province, district)
+        GROUP BY province, district
+        ORDER BY count DESC, province, district
+    `
+
+	getViolationHeatmapQuery = `
+        SELECT
+            FLOOR(latitude / $1)::int AS lat_idx,
+            FLOOR(longitude / $1)::int AS lng_idx,
+            COUNT(*) AS count
+        FROM traffic_violations
+        WHERE active = true
+          AND latitude BETWEEN $2 AND $3
+          AND longitude BETWEEN $4 AND $5
+          AND date >= $6 AND date < $7
+          AND ($8 = '' OR type = $8)
+          AND f_in_jurisdiction($9, province, district)
+        GROUP BY lat_idx, lng_idx
+        ORDER BY count DESC
+    `
+
+	getTopRoadSegmentsQuery = `
+        SELECT
+            MIN(btrim(address)) AS address,
+            province,
+            district,
+            type,
+            COUNT(*) AS count,
+            AVG(latitude) AS latitude,
+            AVG(longitude) AS longitude
+        FROM traffic_violations
+        WHERE active = true
+          AND address IS NOT NULL AND btrim(address) <> ''
+          AND ($1::timestamptz IS NULL OR date >= $1)
+          AND ($2::timestamptz IS NULL OR date < $2)
+          AND ($3 = '' OR type = $3)
+          AND ($4 = '' OR province = $4)
+          AND f_in_jurisdiction($6, province, district)
+        GROUP BY lower(btrim(address)), province, district, type
+        ORDER BY count DESC
+        LIMIT $5
+    `
 )
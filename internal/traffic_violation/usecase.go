@@ -2,8 +2,11 @@ package trafficviolation
 
 import (
 	"context"
+	"time"
 
 	"github.com/adohong4/driving-license/internal/models"
+	"github.com/adohong4/driving-license/pkg/export"
+	"github.com/adohong4/driving-license/pkg/geo"
 	"github.com/adohong4/driving-license/pkg/utils"
 	"github.com/google/uuid"
 )
@@ -15,10 +18,19 @@ type UseCase interface {
 	GetTrafficViolationById(ctx context.Context, Id uuid.UUID) (*models.TrafficViolation, error)
 	GetAllTrafficViolation(ctx context.Context, pq *utils.PaginationQuery) (*models.TrafficViolationList, error)
 	SearchTrafficViolation(ctx context.Context, vpn string, query *utils.PaginationQuery) (*models.TrafficViolationList, error)
-	GetTrafficViolationStats(ctx context.Context) (*models.TrafficViolationStats, error)
-	GetTrafficViolationStatusStats(ctx context.Context) ([]*models.TrafficViolationStatusStats, error)
+	ExportTrafficViolations(ctx context.Context, eq *utils.ExportQuery) *export.Source
+	GetTrafficViolationStats(ctx context.Context) (*models.TrafficViolationStats, time.Time, error)
+	GetTrafficViolationStatusStats(ctx context.Context) ([]*models.TrafficViolationStatusStats, time.Time, error)
+	GetViolationSeries(ctx context.Context, sq *utils.StatsQuery) (*models.TimeSeries, time.Time, error)
+	GetFinesCollectedSeries(ctx context.Context, sq *utils.StatsQuery) (*models.TimeSeries, time.Time, error)
 	GetMyViolations(ctx context.Context, pq *utils.PaginationQuery) (*models.TrafficViolationList, error)
 	GetViolationsByMyVehicle(ctx context.Context, vehicleID uuid.UUID, pq *utils.PaginationQuery) (*models.TrafficViolationList, error)
 	GetMyTrafficViolationByID(ctx context.Context, violationID uuid.UUID) (*models.TrafficViolation, error)
 	GetViolationsByMyLicense(ctx context.Context, pq *utils.PaginationQuery) (*models.TrafficViolationList, error)
+	GetViolationOwner(ctx context.Context, violationID uuid.UUID) (*models.ViolationOwner, error)
+	ReportDetection(ctx context.Context, d *models.VehicleDetection) (*models.VehicleDetection, error)
+
+	GetDistrictHotspots(ctx context.Context, hq *models.ViolationHotspotQuery) (*geo.FeatureCollection, error)
+	GetViolationHeatmap(ctx context.Context, hq *models.ViolationHotspotQuery) (*geo.FeatureCollection, error)
+	GetTopRoadSegments(ctx context.Context, hq *models.ViolationHotspotQuery) (*geo.FeatureCollection, error)
 }
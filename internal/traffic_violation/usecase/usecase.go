@@ -4,25 +4,49 @@ import (
 	"context"
 	"database/sql"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/adohong4/driving-license/config"
 	"github.com/adohong4/driving-license/internal/models"
+	"github.com/adohong4/driving-license/internal/notification"
+	"github.com/adohong4/driving-license/internal/stats"
 	trafficviolation "github.com/adohong4/driving-license/internal/traffic_violation"
+	"github.com/adohong4/driving-license/pkg/export"
+	"github.com/adohong4/driving-license/pkg/geo"
 	"github.com/adohong4/driving-license/pkg/httpErrors"
 	"github.com/adohong4/driving-license/pkg/logger"
+	"github.com/adohong4/driving-license/pkg/plate"
 	"github.com/adohong4/driving-license/pkg/utils"
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
 )
 
+const (
+	defaultHotspotWindow = 30 * 24 * time.Hour
+	defaultHeatmapCell   = 0.01 // ~1.1km
+	maxHeatmapCells      = 10000
+	defaultRoadLimit     = 10
+	maxRoadLimit         = 100
+
+	notiStolenVehicle = "stolen_vehicle_detected"
+	notiTypeAlert     = "vehicle_alert"
+	alertTimeout      = 30 * time.Second
+)
+
+// Roles allowed to see who owned a vehicle when a violation was recorded
+var officerRoles = map[string]bool{"admin": true, "officer": true}
+
 type TrafficViolationUC struct {
 	cfg                  *config.Config
 	TrafficViolationRepo trafficviolation.Repository
+	statsUC              stats.UseCase
+	notiUC               notification.UseCase
 	logger               logger.Logger
 }
 
-func NewTrafficViolationUseCase(cfg *config.Config, TrafficViolationRepo trafficviolation.Repository, logger logger.Logger) trafficviolation.UseCase {
-	return &TrafficViolationUC{cfg: cfg, TrafficViolationRepo: TrafficViolationRepo, logger: logger}
+func NewTrafficViolationUseCase(cfg *config.Config, TrafficViolationRepo trafficviolation.Repository, statsUC stats.UseCase, notiUC notification.UseCase, logger logger.Logger) trafficviolation.UseCase {
+	return &TrafficViolationUC{cfg: cfg, TrafficViolationRepo: TrafficViolationRepo, statsUC: statsUC, notiUC: notiUC, logger: logger}
 }
 
 func (u *TrafficViolationUC) CreateTrafficViolation(ctx context.Context, tv *models.TrafficViolation) (*models.TrafficViolation, error) {
@@ -45,6 +69,8 @@ func (u *TrafficViolationUC) CreateTrafficViolation(ctx context.Context, tv *mod
 	if err != nil {
 		return nil, err
 	}
+	u.statsUC.MarkDirty(stats.DomainViolations)
+	u.alertIfStolen(ctx, n)
 
 	return n, nil
 }
@@ -69,6 +95,7 @@ func (u *TrafficViolationUC) UpdateTrafficViolation(ctx context.Context, tv *mod
 	if err != nil {
 		return nil, err
 	}
+	u.statsUC.MarkDirty(stats.DomainViolations)
 
 	return updatedLicense, nil
 }
@@ -93,6 +120,7 @@ func (u *TrafficViolationUC) DeleteTrafficViolation(ctx context.Context, tv *mod
 	if err != nil {
 		return nil, err
 	}
+	u.statsUC.MarkDirty(stats.DomainViolations)
 
 	return DeleteReport, nil
 }
@@ -110,15 +138,59 @@ func (u *TrafficViolationUC) GetAllTrafficViolation(ctx context.Context, pq *uti
 }
 
 func (u *TrafficViolationUC) SearchTrafficViolation(ctx context.Context, vpn string, query *utils.PaginationQuery) (*models.TrafficViolationList, error) {
-	return u.TrafficViolationRepo.SearchTrafficViolation(ctx, vpn, query)
+	return u.TrafficViolationRepo.SearchTrafficViolation(ctx, plate.Compact(vpn), query)
+}
+
+// Export source for the violation list, filtered like SearchTrafficViolation
+func (u *TrafficViolationUC) ExportTrafficViolations(ctx context.Context, eq *utils.ExportQuery) *export.Source {
+	return &export.Source{
+		Name:    "violations",
+		Columns: models.TrafficViolationExportColumns,
+		Count: func(ctx context.Context) (int, error) {
+			return u.TrafficViolationRepo.CountTrafficViolations(ctx, plate.Compact(eq.Search), eq.ListQuery())
+		},
+		Rows: func(ctx context.Context, fn func(row []string) error) error {
+			return u.TrafficViolationRepo.StreamTrafficViolations(ctx, plate.Compact(eq.Search), eq.ListQuery(), func(tv *models.TrafficViolation) error {
+				return fn(tv.ExportRow())
+			})
+		},
+	}
 }
 
-func (u *TrafficViolationUC) GetTrafficViolationStats(ctx context.Context) (*models.TrafficViolationStats, error) {
-	return u.TrafficViolationRepo.GetTrafficViolationStats(ctx)
+func (u *TrafficViolationUC) GetTrafficViolationStats(ctx context.Context) (*models.TrafficViolationStats, time.Time, error) {
+	return stats.CachedView(ctx, u.statsUC, stats.DomainViolations, "totals", u.TrafficViolationRepo.GetTrafficViolationStats)
+}
+
+func (u *TrafficViolationUC) GetTrafficViolationStatusStats(ctx context.Context) ([]*models.TrafficViolationStatusStats, time.Time, error) {
+	return stats.CachedView(ctx, u.statsUC, stats.DomainViolations, "status", u.TrafficViolationRepo.GetTrafficViolationStatusStats)
+}
+
+func (u *TrafficViolationUC) GetViolationSeries(ctx context.Context, sq *utils.StatsQuery) (*models.TimeSeries, time.Time, error) {
+	if err := prepareViolationStatsQuery(sq); err != nil {
+		return nil, time.Time{}, httpErrors.NewBadRequestError(errors.WithMessage(err, "TrafficViolationUC.GetViolationSeries.prepareViolationStatsQuery"))
+	}
+
+	return stats.CachedLive(ctx, u.statsUC, stats.DomainViolations, "series:"+sq.CacheKey(), func(ctx context.Context) (*models.TimeSeries, error) {
+		points, err := u.TrafficViolationRepo.GetViolationSeries(ctx, sq)
+		if err != nil {
+			return nil, err
+		}
+		return utils.BuildTimeSeries("violations", sq, points, true), nil
+	})
 }
 
-func (u *TrafficViolationUC) GetTrafficViolationStatusStats(ctx context.Context) ([]*models.TrafficViolationStatusStats, error) {
-	return u.TrafficViolationRepo.GetTrafficViolationStatusStats(ctx)
+func (u *TrafficViolationUC) GetFinesCollectedSeries(ctx context.Context, sq *utils.StatsQuery) (*models.TimeSeries, time.Time, error) {
+	if err := prepareViolationStatsQuery(sq); err != nil {
+		return nil, time.Time{}, httpErrors.NewBadRequestError(errors.WithMessage(err, "TrafficViolationUC.GetFinesCollectedSeries.prepareViolationStatsQuery"))
+	}
+
+	return stats.CachedLive(ctx, u.statsUC, stats.DomainViolations, "fines:"+sq.CacheKey(), func(ctx context.Context) (*models.TimeSeries, error) {
+		points, err := u.TrafficViolationRepo.GetFinesCollectedSeries(ctx, sq)
+		if err != nil {
+			return nil, err
+		}
+		return utils.BuildTimeSeries("fines_collected", sq, points, true), nil
+	})
 }
 
 func (u *TrafficViolationUC) GetMyViolations(ctx context.Context, pq *utils.PaginationQuery) (*models.TrafficViolationList, error) {
@@ -180,3 +252,230 @@ func (u *TrafficViolationUC) GetViolationsByMyLicense(ctx context.Context, pq *u
 
 	return u.TrafficViolationRepo.GetViolationsByLicenseWallet(ctx, *user.UserAddress, pq)
 }
+
+func (u *TrafficViolationUC) GetDistrictHotspots(ctx context.Context, hq *models.ViolationHotspotQuery) (*geo.FeatureCollection, error) {
+	if err := prepareHotspotQuery(hq); err != nil {
+		return nil, httpErrors.NewBadRequestError(errors.WithMessage(err, "TrafficViolationUC.GetDistrictHotspots.prepareHotspotQuery"))
+	}
+
+	items, err := u.TrafficViolationRepo.GetViolationCountByDistrict(ctx, hq)
+	if err != nil {
+		return nil, err
+	}
+
+	fc := geo.NewFeatureCollection()
+	for _, item := range items {
+		// Districts without any geolocated violation have no point to render
+		if item.Latitude == nil || item.Longitude == nil {
+			continue
+		}
+		fc.Add(geo.NewPointFeature(*item.Latitude, *item.Longitude, map[string]interface{}{
+			"province":          item.Province,
+			"district":          item.District,
+			"count":             item.Count,
+			"total_fine_amount": item.TotalFineAmount,
+		}))
+	}
+	return fc, nil
+}
+
+func (u *TrafficViolationUC) GetViolationHeatmap(ctx context.Context, hq *models.ViolationHotspotQuery) (*geo.FeatureCollection, error) {
+	if hq.BBox == nil {
+		return nil, httpErrors.NewBadRequestError(errors.New("bbox is required"))
+	}
+	if hq.CellSize == 0 {
+		hq.CellSize = defaultHeatmapCell
+	}
+	if !(hq.CellSize >= geo.MinCellSize) || hq.BBox.CellCount(hq.CellSize) > maxHeatmapCells {
+		return nil, httpErrors.NewBadRequestError(errors.New("cell size is too small for the bounding box"))
+	}
+
+	// Heatmap always runs on a bounded time window
+	now := time.Now()
+	if hq.To == nil {
+		hq.To = &now
+	}
+	if hq.From == nil {
+		from := hq.To.Add(-defaultHotspotWindow)
+		hq.From = &from
+	}
+	if err := prepareHotspotQuery(hq); err != nil {
+		return nil, httpErrors.NewBadRequestError(errors.WithMessage(err, "TrafficViolationUC.GetViolationHeatmap.prepareHotspotQuery"))
+	}
+
+	cells, err := u.TrafficViolationRepo.GetViolationHeatmap(ctx, hq)
+	if err != nil {
+		return nil, err
+	}
+
+	fc := geo.NewFeatureCollection()
+	for _, cell := range cells {
+		fc.Add(geo.NewBoxFeature(geo.GridCell(cell.LatIdx, cell.LngIdx, hq.CellSize), map[string]interface{}{
+			"count": cell.Count,
+		}))
+	}
+	return fc, nil
+}
+
+func (u *TrafficViolationUC) GetTopRoadSegments(ctx context.Context, hq *models.ViolationHotspotQuery) (*geo.FeatureCollection, error) {
+	if hq.Limit <= 0 {
+		hq.Limit = defaultRoadLimit
+	}
+	if hq.Limit > maxRoadLimit {
+		hq.Limit = maxRoadLimit
+	}
+	if err := prepareHotspotQuery(hq); err != nil {
+		return nil, httpErrors.NewBadRequestError(errors.WithMessage(err, "TrafficViolationUC.GetTopRoadSegments.prepareHotspotQuery"))
+	}
+
+	items, err := u.TrafficViolationRepo.GetTopRoadSegments(ctx, hq)
+	if err != nil {
+		return nil, err
+	}
+
+	fc := geo.NewFeatureCollection()
+	for rank, item := range items {
+		props := map[string]interface{}{
+			"rank":     rank + 1,
+			"address":  item.Address,
+			"province": item.Province,
+			"district": item.District,
+			"type":     item.Type,
+			"count":    item.Count,
+		}
+		// GeoJSON allows features without geometry for segments that were never geolocated
+		if item.Latitude == nil || item.Longitude == nil {
+			fc.Add(&geo.Feature{Type: geo.TypeFeature, Properties: props})
+			continue
+		}
+		fc.Add(geo.NewPointFeature(*item.Latitude, *item.Longitude, props))
+	}
+	return fc, nil
+}
+
+// Normalize and check shared hotspot filters
+func prepareHotspotQuery(hq *models.ViolationHotspotQuery) error {
+	hq.Type = strings.TrimSpace(hq.Type)
+	hq.Province = geo.NormalizeAdminArea(hq.Province)
+	if hq.From != nil && hq.To != nil && !hq.From.Before(*hq.To) {
+		return errors.New("from must be before to")
+	}
+	return nil
+}
+
+// Violations are filtered by province and violation type only
+func prepareViolationStatsQuery(sq *utils.StatsQuery) error {
+	if sq.AgencyID != nil || sq.LicenseType != "" {
+		return errors.New("agency_id and license_type filters are not supported for violations")
+	}
+	sq.City = geo.NormalizeAdminArea(sq.City)
+	return nil
+}
+
+// Violation with the owner of the vehicle on the violation date
+func (u *TrafficViolationUC) GetViolationOwner(ctx context.Context, violationID uuid.UUID) (*models.ViolationOwner, error) {
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return nil, httpErrors.NewUnauthorizedError(err)
+	}
+	if user.Role == nil || !officerRoles[*user.Role] {
+		return nil, httpErrors.NewForbiddenError(httpErrors.PermissionDenied)
+	}
+
+	violation, err := u.TrafficViolationRepo.GetTrafficViolationById(ctx, violationID)
+	if err != nil {
+		return nil, err
+	}
+	owner, err := u.TrafficViolationRepo.GetViolationOwner(ctx, violationID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.ViolationOwner{Violation: violation, Owner: owner}, nil
+}
+
+// Plate read by a road camera. Officers are alerted in the background when the vehicle is reported stolen,
+// the response only tells whether it is
+func (u *TrafficViolationUC) ReportDetection(ctx context.Context, d *models.VehicleDetection) (*models.VehicleDetection, error) {
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return nil, httpErrors.NewUnauthorizedError(errors.WithMessage(err, "TrafficViolationUC.ReportDetection.GetUserFromCtx"))
+	}
+	if user.Role == nil || !officerRoles[*user.Role] {
+		return nil, httpErrors.NewForbiddenError(httpErrors.PermissionDenied)
+	}
+
+	if err = d.Prepare(); err != nil {
+		return nil, httpErrors.NewBadRequestError(errors.Wrap(err, "TrafficViolationUC.ReportDetection.Prepare"))
+	}
+	if err = utils.ValidateStruct(ctx, d); err != nil {
+		return nil, httpErrors.NewBadRequestError(errors.WithMessage(err, "TrafficViolationUC.ReportDetection.ValidateStruct"))
+	}
+
+	province, err := u.TrafficViolationRepo.GetStolenVehicleProvince(ctx, d.VehiclePlateNo)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return d, nil
+		}
+		return nil, err
+	}
+	d.Stolen = true
+
+	address := d.Address
+	if address == "" {
+		address = d.CameraID
+	}
+	params := models.NotificationParams{
+		"camera_id":  d.CameraID,
+		"vehicle_no": d.VehiclePlateNo,
+		"date":       d.DetectedAt.Format("2006-01-02 15:04"),
+		"address":    address,
+	}
+	u.background(ctx, func(ctx context.Context) {
+		u.alertStolen(ctx, province, &models.JurisdictionArea{Province: d.Province, District: d.District}, params)
+	})
+	return d, nil
+}
+
+// Alert the officers when the violation was recorded on a stolen vehicle. The violation is already saved,
+// the alert runs in the background and failures are only logged
+func (u *TrafficViolationUC) alertIfStolen(ctx context.Context, tv *models.TrafficViolation) {
+	if tv.VehiclePlateNo == "" {
+		return
+	}
+	u.background(ctx, func(ctx context.Context) {
+		province, err := u.TrafficViolationRepo.GetStolenVehicleProvince(ctx, tv.VehiclePlateNo)
+		if err != nil {
+			if !errors.Is(err, sql.ErrNoRows) {
+				u.logger.Errorf("TrafficViolationUC.alertIfStolen.GetStolenVehicleProvince %s: %v", tv.VehiclePlateNo, err)
+			}
+			return
+		}
+		u.alertStolen(ctx, province, &models.JurisdictionArea{Province: tv.Province, District: tv.District}, models.NotificationParams{
+			"violation_id": tv.Id.String(),
+			"vehicle_no":   tv.VehiclePlateNo,
+			"date":         tv.Date.Format("2006-01-02 15:04"),
+			"address":      tv.Address,
+		})
+	})
+}
+
+// Notify, in one insert, the officers whose jurisdiction covers the province of the vehicle or the place it was seen
+func (u *TrafficViolationUC) alertStolen(ctx context.Context, vehicleProvince string, seen *models.JurisdictionArea, params models.NotificationParams) {
+	areas := []*models.JurisdictionArea{{Province: vehicleProvince}}
+	if seen.Province != "" {
+		areas = append(areas, seen)
+	}
+	n := &models.Notification{Code: notiStolenVehicle, Type: notiTypeAlert, Params: params}
+	if _, err := u.notiUC.NotifyOfficers(ctx, n, areas); err != nil {
+		u.logger.Errorf("TrafficViolationUC.alertStolen.NotifyOfficers %s: %v", params["vehicle_no"], err)
+	}
+}
+
+// Run fn after the request returns, with the values of its context
+func (u *TrafficViolationUC) background(ctx context.Context, fn func(ctx context.Context)) {
+	bgCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), alertTimeout)
+	go func() {
+		defer cancel()
+		fn(bgCtx)
+	}()
+}
@@ -10,11 +10,21 @@ type Handlers interface {
 	GetByID() echo.HandlerFunc
 	GetAllVehicleReg() echo.HandlerFunc
 	SearchByVehiclePlateNO() echo.HandlerFunc
+	Export() echo.HandlerFunc
+	Import() echo.HandlerFunc
 	GetStatsByType() echo.HandlerFunc
 	GetStatsByBrand() echo.HandlerFunc
 	GetStatsByStatus() echo.HandlerFunc
+	GetStatsSeries() echo.HandlerFunc
 	GetMyVehicles() echo.HandlerFunc
 	GetMyVehicleByID() echo.HandlerFunc
+	GetOwnershipHistory() echo.HandlerFunc
+	Deregister() echo.HandlerFunc
+	ReportStolen() echo.HandlerFunc
+	Recover() echo.HandlerFunc
+	Seize() echo.HandlerFunc
+	Release() echo.HandlerFunc
+	GetEvents() echo.HandlerFunc
 	GetInspections() echo.HandlerFunc
 	GetInspectionByCode() echo.HandlerFunc
 }
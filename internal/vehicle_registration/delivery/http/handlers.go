@@ -4,6 +4,10 @@ import (
 	"net/http"
 
 	"github.com/adohong4/driving-license/config"
+	exportjob "github.com/adohong4/driving-license/internal/export_job"
+	exportHttp "github.com/adohong4/driving-license/internal/export_job/delivery/http"
+	importjob "github.com/adohong4/driving-license/internal/import_job"
+	importHttp "github.com/adohong4/driving-license/internal/import_job/delivery/http"
 	"github.com/adohong4/driving-license/internal/models"
 	vehicleRegistration "github.com/adohong4/driving-license/internal/vehicle_registration"
 	"github.com/adohong4/driving-license/pkg/httpErrors"
@@ -16,11 +20,13 @@ import (
 type vehicleRegHandlers struct {
 	cfg          *config.Config
 	vehicleRegUC vehicleRegistration.UseCase
+	exportJobUC  exportjob.UseCase
+	importJobUC  importjob.UseCase
 	logger       logger.Logger
 }
 
-func NewVehicleReqHandlers(cfg *config.Config, vehicleRegUC vehicleRegistration.UseCase, logger logger.Logger) vehicleRegistration.Handlers {
-	return &vehicleRegHandlers{cfg: cfg, vehicleRegUC: vehicleRegUC, logger: logger}
+func NewVehicleReqHandlers(cfg *config.Config, vehicleRegUC vehicleRegistration.UseCase, exportJobUC exportjob.UseCase, importJobUC importjob.UseCase, logger logger.Logger) vehicleRegistration.Handlers {
+	return &vehicleRegHandlers{cfg: cfg, vehicleRegUC: vehicleRegUC, exportJobUC: exportJobUC, importJobUC: importJobUC, logger: logger}
 }
 
 // Create godoc
@@ -31,9 +37,9 @@ func NewVehicleReqHandlers(cfg *config.Config, vehicleRegUC vehicleRegistration.
 // @Produce      json
 // @Param        vehicle  body      models.VehicleRegistration  true  "Vehicle registration data"
 // @Success      201      {object}  models.VehicleRegistration
-// @Failure      400      {object}  httpErrors.RestError
-// @Failure      401      {object}  httpErrors.RestError
-// @Failure      500      {object}  httpErrors.RestError
+// @Failure      400      {object}  httpErrors.Problem
+// @Failure      401      {object}  httpErrors.Problem
+// @Failure      500      {object}  httpErrors.Problem
 // @Security     JWT
 // @Router       /vehicle/create [post]
 func (h vehicleRegHandlers) Create() echo.HandlerFunc {
@@ -63,10 +69,10 @@ func (h vehicleRegHandlers) Create() echo.HandlerFunc {
 // @Param        id       path      string                      true  "Vehicle Registration ID (UUID)"
 // @Param        vehicle  body      models.VehicleRegistration  true  "Updated vehicle registration data"
 // @Success      200      {object}  models.VehicleRegistration
-// @Failure      400      {object}  httpErrors.RestError
-// @Failure      401      {object}  httpErrors.RestError
-// @Failure      404      {object}  httpErrors.RestError
-// @Failure      500      {object}  httpErrors.RestError
+// @Failure      400      {object}  httpErrors.Problem
+// @Failure      401      {object}  httpErrors.Problem
+// @Failure      404      {object}  httpErrors.Problem
+// @Failure      500      {object}  httpErrors.Problem
 // @Security     JWT
 // @Router       /vehicle/{id} [put]
 func (h vehicleRegHandlers) Update() echo.HandlerFunc {
@@ -98,15 +104,19 @@ func (h vehicleRegHandlers) Update() echo.HandlerFunc {
 // ConfirmBlockchainStorage godoc
 // @Summary      Confirm blockchain storage for a vehicle registration
 // @Description  Updates the blockchain transaction hash and sets on_blockchain to true after successful storage.
+// @Description  The transaction must be mined, succeed, target the registry contract and anchor the hash of the stored document, otherwise the code is tx_not_verified
 // @Tags         vehicle-registration
 // @Accept       json
 // @Produce      json
 // @Param        id       path      string                              true  "Vehicle Registration ID (UUID)"
 // @Param        request  body      models.ConfirmBlockchainRequest     true  "Blockchain confirmation details"
 // @Success      200      {object}  models.VehicleRegistration
-// @Failure      400      {object}  httpErrors.RestError
-// @Failure      401      {object}  httpErrors.RestError
-// @Failure      500      {object}  httpErrors.RestError
+// @Failure      400      {object}  httpErrors.Problem
+// @Failure      401      {object}  httpErrors.Problem
+// @Failure      404      {object}  httpErrors.Problem
+// @Failure      409      {object}  httpErrors.Problem
+// @Failure      500      {object}  httpErrors.Problem
+// @Failure      503      {object}  httpErrors.Problem
 // @Security     JWT
 // @Router       /vehicle/{id}/confirm-blockchain [put]
 func (h *vehicleRegHandlers) ConfirmBlockchainStorage() echo.HandlerFunc {
@@ -153,10 +163,10 @@ func (h *vehicleRegHandlers) ConfirmBlockchainStorage() echo.HandlerFunc {
 // @Produce      json
 // @Param        id  path      string  true  "Vehicle Registration ID (UUID)"
 // @Success      200  {object}  models.VehicleRegistration
-// @Failure      400  {object}  httpErrors.RestError
-// @Failure      401  {object}  httpErrors.RestError
-// @Failure      404  {object}  httpErrors.RestError
-// @Failure      500  {object}  httpErrors.RestError
+// @Failure      400  {object}  httpErrors.Problem
+// @Failure      401  {object}  httpErrors.Problem
+// @Failure      404  {object}  httpErrors.Problem
+// @Failure      500  {object}  httpErrors.Problem
 // @Security     JWT
 // @Router       /vehicle/{id} [delete]
 func (h vehicleRegHandlers) Delete() echo.HandlerFunc {
@@ -187,9 +197,10 @@ func (h vehicleRegHandlers) Delete() echo.HandlerFunc {
 // @Produce      json
 // @Param        id   path      string  true  "Vehicle Registration ID (UUID)"
 // @Success      200  {object}  models.VehicleRegistration
-// @Failure      400  {object}  httpErrors.RestError
-// @Failure      404  {object}  httpErrors.RestError
-// @Failure      500  {object}  httpErrors.RestError
+// @Failure      400  {object}  httpErrors.Problem
+// @Failure      404  {object}  httpErrors.Problem
+// @Failure      500  {object}  httpErrors.Problem
+// @Security     JWT
 // @Router       /vehicle/{id} [get]
 func (h vehicleRegHandlers) GetByID() echo.HandlerFunc {
 	return func(c echo.Context) error {
@@ -216,9 +227,13 @@ func (h vehicleRegHandlers) GetByID() echo.HandlerFunc {
 // @Produce      json
 // @Param        page   query     int  false  "Page number (default: 1)"
 // @Param        size   query     int  false  "Page size (default: 10)"
+// @Param        sort   query     string false  "Sort fields, comma separated, - prefix for descending, e.g. -created_at"
+// @Param        cursor query     string false  "Cursor of the next page, empty for the first one, switches to cursor pagination"
+// @Param        with_total query     bool   false  "Return an approximate total_count on cursor pages"
 // @Success      200    {object}  models.VehicleRegistrationList
-// @Failure      400    {object}  httpErrors.RestError
-// @Failure      500    {object}  httpErrors.RestError
+// @Failure      400    {object}  httpErrors.Problem
+// @Failure      500    {object}  httpErrors.Problem
+// @Security     JWT
 // @Router       /vehicle/getAll [get]
 func (h vehicleRegHandlers) GetAllVehicleReg() echo.HandlerFunc {
 	return func(c echo.Context) error {
@@ -239,6 +254,59 @@ func (h vehicleRegHandlers) GetAllVehicleReg() echo.HandlerFunc {
 	}
 }
 
+// Export godoc
+// @Summary      Export vehicle registrations
+// @Description  Exports active vehicle registrations as CSV or XLSX, filtered and sorted like the search endpoint. Large exports run as a background job and return 202 with the job.
+// @Tags         vehicle-registration
+// @Produce      text/csv
+// @Produce      application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param        vehicle_no  query     string  false  "Plate number (partial)"
+// @Param        sort        query     string  false  "Sort fields, comma separated, - prefix for descending, e.g. -created_at"
+// @Param        format      query     string  false  "csv or xlsx, defaults to the Accept header or csv"
+// @Param        lang        query     string  false  "Header language vi or en, defaults to Accept-Language or vi"
+// @Param        async       query     bool    false  "Always run as a background job"
+// @Success      200         {file}    file
+// @Success      202         {object}  models.ExportJob
+// @Failure      400         {object}  httpErrors.Problem
+// @Failure      401         {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /vehicle/export [get]
+func (h vehicleRegHandlers) Export() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		eq, err := utils.GetExportQueryFromCtx(c, "vehicle_no")
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(httpErrors.NewBadRequestError(err.Error())))
+		}
+
+		src := h.vehicleRegUC.ExportVehicleDocs(c.Request().Context(), eq)
+		return exportHttp.Respond(c, h.exportJobUC, eq, src, h.logger)
+	}
+}
+
+// Import godoc
+// @Summary      Import vehicle registrations
+// @Description  Bulk imports vehicle registrations from a CSV or XLSX file. Headers may be column keys or the vi/en export headers. Rows are validated like create and valid rows are inserted in batched transactions, with dry_run only the validation report is produced. Returns 202 with the import job.
+// @Tags         vehicle-registration
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        file     formData  file    true   "CSV or XLSX file"
+// @Param        dry_run  query     bool    false  "Validate only, nothing is inserted"
+// @Param        format   query     string  false  "csv or xlsx, defaults to the file extension"
+// @Param        lang     query     string  false  "Error file language vi or en, defaults to Accept-Language or vi"
+// @Success      202      {object}  models.ImportJob
+// @Failure      400      {object}  httpErrors.Problem
+// @Failure      401      {object}  httpErrors.Problem
+// @Failure      413      {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /vehicle/import [post]
+func (h vehicleRegHandlers) Import() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		target := h.vehicleRegUC.ImportVehicleDocs(c.Request().Context())
+		return importHttp.Respond(c, h.importJobUC, target, h.logger)
+	}
+}
+
 // SearchByVehiclePlateNO godoc
 // @Summary      Search vehicle registrations by plate number
 // @Description  Searches for active vehicle registrations containing the given plate number (partial match, case-insensitive).
@@ -247,9 +315,13 @@ func (h vehicleRegHandlers) GetAllVehicleReg() echo.HandlerFunc {
 // @Param        vehicle_no  query     string  true   "Plate number (partial)"
 // @Param        page        query     int     false  "Page number (default: 1)"
 // @Param        size        query     int     false  "Page size (default: 10)"
+// @Param        sort        query     string  false  "Sort fields, comma separated, - prefix for descending, e.g. -created_at"
+// @Param        cursor      query     string  false  "Cursor of the next page, empty for the first one, switches to cursor pagination"
+// @Param        with_total  query     bool    false  "Return an approximate total_count on cursor pages"
 // @Success      200         {object}  models.VehicleRegistrationList
-// @Failure      400         {object}  httpErrors.RestError
-// @Failure      500         {object}  httpErrors.RestError
+// @Failure      400         {object}  httpErrors.Problem
+// @Failure      500         {object}  httpErrors.Problem
+// @Security     JWT
 // @Router       /vehicle/search [get]
 func (h vehicleRegHandlers) SearchByVehiclePlateNO() echo.HandlerFunc {
 	return func(c echo.Context) error {
@@ -262,7 +334,7 @@ func (h vehicleRegHandlers) SearchByVehiclePlateNO() echo.HandlerFunc {
 
 		vehicleNo := c.QueryParam("vehicle_no")
 		if vehicleNo == "" {
-			return c.JSON(http.StatusBadRequest, map[string]string{"error": "vehicle_no query parameter is required"})
+			return c.JSON(httpErrors.ErrorResponse(httpErrors.NewRestError(http.StatusBadRequest, "vehicle_no query parameter is required", nil)))
 		}
 
 		newList, err := h.vehicleRegUC.FindByVehiclePlateNO(ctx, vehicleNo, pq)
@@ -281,16 +353,18 @@ func (h vehicleRegHandlers) SearchByVehiclePlateNO() echo.HandlerFunc {
 // @Tags         vehicle-registration
 // @Produce      json
 // @Success      200  {array}   models.CountItem
-// @Failure      500  {object}  httpErrors.RestError
+// @Header       200  {string}  X-Stats-As-Of  "Time the statistic data was computed (RFC3339)"
+// @Failure      500  {object}  httpErrors.Problem
 // @Router       /vehicle/stats/type [get]
 func (h vehicleRegHandlers) GetStatsByType() echo.HandlerFunc {
 	return func(c echo.Context) error {
 		ctx := c.Request().Context()
-		stats, err := h.vehicleRegUC.GetCountByType(ctx)
+		stats, asOf, err := h.vehicleRegUC.GetCountByType(ctx)
 		if err != nil {
 			utils.LogResponseError(c, h.logger, err)
 			return c.JSON(httpErrors.ErrorResponse(err))
 		}
+		utils.SetStatsAsOf(c, asOf)
 		return c.JSON(http.StatusOK, stats)
 	}
 }
@@ -301,16 +375,18 @@ func (h vehicleRegHandlers) GetStatsByType() echo.HandlerFunc {
 // @Tags         vehicle-registration
 // @Produce      json
 // @Success      200  {array}   models.CountItem
-// @Failure      500  {object}  httpErrors.RestError
+// @Header       200  {string}  X-Stats-As-Of  "Time the statistic data was computed (RFC3339)"
+// @Failure      500  {object}  httpErrors.Problem
 // @Router       /vehicle/stats/brand [get]
 func (h vehicleRegHandlers) GetStatsByBrand() echo.HandlerFunc {
 	return func(c echo.Context) error {
 		ctx := c.Request().Context()
-		stats, err := h.vehicleRegUC.GetTopBrands(ctx)
+		stats, asOf, err := h.vehicleRegUC.GetTopBrands(ctx)
 		if err != nil {
 			utils.LogResponseError(c, h.logger, err)
 			return c.JSON(httpErrors.ErrorResponse(err))
 		}
+		utils.SetStatsAsOf(c, asOf)
 		return c.JSON(http.StatusOK, stats)
 	}
 }
@@ -324,32 +400,71 @@ func (h vehicleRegHandlers) GetStatsByBrand() echo.HandlerFunc {
 // @Tags         vehicle-registration
 // @Produce      json
 // @Success      200  {array}   models.CountItem
-// @Failure      500  {object}  httpErrors.RestError
+// @Header       200  {string}  X-Stats-As-Of  "Time the statistic data was computed (RFC3339)"
+// @Failure      500  {object}  httpErrors.Problem
 // @Router       /vehicle/stats/status [get]
 func (h vehicleRegHandlers) GetStatsByStatus() echo.HandlerFunc {
 	return func(c echo.Context) error {
 		ctx := c.Request().Context()
 
-		stats, err := h.vehicleRegUC.GetCountByStatus(ctx)
+		stats, asOf, err := h.vehicleRegUC.GetCountByStatus(ctx)
 		if err != nil {
 			utils.LogResponseError(c, h.logger, err)
 			return c.JSON(httpErrors.ErrorResponse(err))
 		}
 
+		utils.SetStatsAsOf(c, asOf)
 		return c.JSON(http.StatusOK, stats)
 	}
 }
 
+// GetStatsSeries godoc
+// @Summary      Vehicle registrations over time
+// @Description  Returns number of vehicle registrations issued per day, week or month
+// @Tags         vehicle-registration
+// @Produce      json
+// @Param        from      query     string  false  "Start date (RFC3339 or YYYY-MM-DD)"
+// @Param        to        query     string  false  "End date, exclusive (RFC3339 or YYYY-MM-DD, default: end of the current period)"
+// @Param        group_by  query     string  false  "day, week or month (default: month)"
+// @Param        city      query     string  false  "City of issuer or registration place"
+// @Success      200  {object}  models.TimeSeries
+// @Header       200  {string}  X-Stats-As-Of  "Time the statistic data was computed (RFC3339)"
+// @Failure      400  {object}  httpErrors.Problem
+// @Failure      500  {object}  httpErrors.Problem
+// @Router       /vehicle/stats/series [get]
+func (h vehicleRegHandlers) GetStatsSeries() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		sq, err := utils.GetStatsQueryFromCtx(c)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(httpErrors.NewBadRequestError(err.Error())))
+		}
+
+		series, asOf, err := h.vehicleRegUC.GetRegistrationSeries(ctx, sq)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		utils.SetStatsAsOf(c, asOf)
+		return c.JSON(http.StatusOK, series)
+	}
+}
+
 // @Summary      List all vehicles owned by authenticated user
 // @Description  Returns paginated list of vehicle registrations where owner_id matches current user
 // @Tags         User
 // @Produce      json
 // @Param        page  query     int  false  "Page number (default: 1)"
 // @Param        size  query     int  false  "Page size (default: 10)"
+// @Param        sort  query     string false  "Sort fields, comma separated, - prefix for descending, e.g. -created_at"
+// @Param        cursor  query     string false  "Cursor of the next page, empty for the first one, switches to cursor pagination"
+// @Param        with_total query     bool   false  "Return an approximate total_count on cursor pages"
 // @Success      200   {object}  models.VehicleRegistrationList
-// @Failure      400   {object}  httpErrors.RestError
-// @Failure      401   {object}  httpErrors.RestError
-// @Failure      500   {object}  httpErrors.RestError
+// @Failure      400   {object}  httpErrors.Problem
+// @Failure      401   {object}  httpErrors.Problem
+// @Failure      500   {object}  httpErrors.Problem
 // @Security     JWT
 // @Router       /vehicle/me [get]
 func (h vehicleRegHandlers) GetMyVehicles() echo.HandlerFunc {
@@ -377,10 +492,10 @@ func (h vehicleRegHandlers) GetMyVehicles() echo.HandlerFunc {
 // @Produce      json
 // @Param        id    path      string  true  "Vehicle Registration ID (UUID)"
 // @Success      200   {object}  models.VehicleRegistration
-// @Failure      400   {object}  httpErrors.RestError
-// @Failure      401   {object}  httpErrors.RestError
-// @Failure      404   {object}  httpErrors.RestError
-// @Failure      500   {object}  httpErrors.RestError
+// @Failure      400   {object}  httpErrors.Problem
+// @Failure      401   {object}  httpErrors.Problem
+// @Failure      404   {object}  httpErrors.Problem
+// @Failure      500   {object}  httpErrors.Problem
 // @Security     JWT
 // @Router       /vehicle/me/{id} [get]
 func (h vehicleRegHandlers) GetMyVehicleByID() echo.HandlerFunc {
@@ -402,6 +517,200 @@ func (h vehicleRegHandlers) GetMyVehicleByID() echo.HandlerFunc {
 	}
 }
 
+// GetOwnershipHistory godoc
+// @Summary      Chain of title of a vehicle
+// @Description  Returns every owner of the vehicle, oldest first, with the ownership period, the reason and the supporting blockchain transaction. Officers read any vehicle, citizens only the vehicles they currently own.
+// @Tags         vehicle-registration
+// @Produce      json
+// @Security     JWT
+// @Param        id   path      string  true  "Vehicle ID"
+// @Success      200  {object}  models.ChainOfTitle
+// @Failure      400  {object}  httpErrors.Problem
+// @Failure      401  {object}  httpErrors.Problem
+// @Failure      404  {object}  httpErrors.Problem
+// @Failure      500  {object}  httpErrors.Problem
+// @Router       /vehicle/{id}/ownership [get]
+func (h vehicleRegHandlers) GetOwnershipHistory() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		vehicleID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		chain, err := h.vehicleRegUC.GetChainOfTitle(ctx, vehicleID)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, chain)
+	}
+}
+
+// Deregister godoc
+// @Summary      Deregister a vehicle
+// @Description  Takes a scrapped or exported vehicle out of the registry and ends its current ownership. The cause is required. Officers only.
+// @Tags         vehicle-registration
+// @Accept       json
+// @Produce      json
+// @Security     JWT
+// @Param        id       path      string                      true  "Vehicle ID"
+// @Param        request  body      models.VehicleEventRequest  true  "Reason, date and agency"
+// @Success      200      {object}  models.VehicleRegistration
+// @Failure      400      {object}  httpErrors.Problem
+// @Failure      401      {object}  httpErrors.Problem
+// @Failure      403      {object}  httpErrors.Problem
+// @Failure      404      {object}  httpErrors.Problem
+// @Failure      409      {object}  httpErrors.Problem
+// @Failure      500      {object}  httpErrors.Problem
+// @Router       /vehicle/{id}/deregister [post]
+func (h *vehicleRegHandlers) Deregister() echo.HandlerFunc {
+	return h.vehicleEvent(models.VehicleDeregistered)
+}
+
+// ReportStolen godoc
+// @Summary      Report a vehicle stolen
+// @Description  Flags the vehicle as stolen, violations recorded on its plate alert the officers. Officers only.
+// @Tags         vehicle-registration
+// @Accept       json
+// @Produce      json
+// @Security     JWT
+// @Param        id       path      string                      true  "Vehicle ID"
+// @Param        request  body      models.VehicleEventRequest  true  "Reason, date and agency"
+// @Success      200      {object}  models.VehicleRegistration
+// @Failure      400      {object}  httpErrors.Problem
+// @Failure      401      {object}  httpErrors.Problem
+// @Failure      403      {object}  httpErrors.Problem
+// @Failure      404      {object}  httpErrors.Problem
+// @Failure      409      {object}  httpErrors.Problem
+// @Failure      500      {object}  httpErrors.Problem
+// @Router       /vehicle/{id}/stolen [post]
+func (h *vehicleRegHandlers) ReportStolen() echo.HandlerFunc {
+	return h.vehicleEvent(models.VehicleStolen)
+}
+
+// Recover godoc
+// @Summary      Recover a stolen vehicle
+// @Description  Clears the stolen flag of the vehicle. Officers only.
+// @Tags         vehicle-registration
+// @Accept       json
+// @Produce      json
+// @Security     JWT
+// @Param        id       path      string                      true  "Vehicle ID"
+// @Param        request  body      models.VehicleEventRequest  true  "Reason, date and agency"
+// @Success      200      {object}  models.VehicleRegistration
+// @Failure      400      {object}  httpErrors.Problem
+// @Failure      401      {object}  httpErrors.Problem
+// @Failure      403      {object}  httpErrors.Problem
+// @Failure      404      {object}  httpErrors.Problem
+// @Failure      409      {object}  httpErrors.Problem
+// @Failure      500      {object}  httpErrors.Problem
+// @Router       /vehicle/{id}/recover [post]
+func (h *vehicleRegHandlers) Recover() echo.HandlerFunc {
+	return h.vehicleEvent(models.VehicleRecovered)
+}
+
+// Seize godoc
+// @Summary      Seize a vehicle
+// @Description  Flags the vehicle as temporarily seized. Officers only.
+// @Tags         vehicle-registration
+// @Accept       json
+// @Produce      json
+// @Security     JWT
+// @Param        id       path      string                      true  "Vehicle ID"
+// @Param        request  body      models.VehicleEventRequest  true  "Reason, date and agency"
+// @Success      200      {object}  models.VehicleRegistration
+// @Failure      400      {object}  httpErrors.Problem
+// @Failure      401      {object}  httpErrors.Problem
+// @Failure      403      {object}  httpErrors.Problem
+// @Failure      404      {object}  httpErrors.Problem
+// @Failure      409      {object}  httpErrors.Problem
+// @Failure      500      {object}  httpErrors.Problem
+// @Router       /vehicle/{id}/seize [post]
+func (h *vehicleRegHandlers) Seize() echo.HandlerFunc {
+	return h.vehicleEvent(models.VehicleSeized)
+}
+
+// Release godoc
+// @Summary      Release a seized vehicle
+// @Description  Clears the seized flag of the vehicle. Officers only.
+// @Tags         vehicle-registration
+// @Accept       json
+// @Produce      json
+// @Security     JWT
+// @Param        id       path      string                      true  "Vehicle ID"
+// @Param        request  body      models.VehicleEventRequest  true  "Reason, date and agency"
+// @Success      200      {object}  models.VehicleRegistration
+// @Failure      400      {object}  httpErrors.Problem
+// @Failure      401      {object}  httpErrors.Problem
+// @Failure      403      {object}  httpErrors.Problem
+// @Failure      404      {object}  httpErrors.Problem
+// @Failure      409      {object}  httpErrors.Problem
+// @Failure      500      {object}  httpErrors.Problem
+// @Router       /vehicle/{id}/release [post]
+func (h *vehicleRegHandlers) Release() echo.HandlerFunc {
+	return h.vehicleEvent(models.VehicleReleased)
+}
+
+// GetEvents godoc
+// @Summary      Lifecycle events of a vehicle
+// @Description  Returns the deregistration, theft, recovery, seizure and release events of the vehicle, latest first. Officers only.
+// @Tags         vehicle-registration
+// @Produce      json
+// @Security     JWT
+// @Param        id   path      string  true  "Vehicle ID"
+// @Success      200  {object}  models.VehicleEventList
+// @Failure      400  {object}  httpErrors.Problem
+// @Failure      401  {object}  httpErrors.Problem
+// @Failure      403  {object}  httpErrors.Problem
+// @Failure      500  {object}  httpErrors.Problem
+// @Router       /vehicle/{id}/events [get]
+func (h *vehicleRegHandlers) GetEvents() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		vehicleID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		events, err := h.vehicleRegUC.GetVehicleEvents(ctx, vehicleID)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, events)
+	}
+}
+
+func (h *vehicleRegHandlers) vehicleEvent(event string) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		vehicleID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		req := &models.VehicleEventRequest{}
+		if err = c.Bind(req); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		vehicle, err := h.vehicleRegUC.RecordVehicleEvent(ctx, vehicleID, event, req)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, vehicle)
+	}
+}
+
 // GetInspections godoc
 // @Summary      List all vehicle inspections
 // @Description  Returns a paginated list of active vehicle registrations that have been inspected (registration_code is not null).
@@ -409,9 +718,13 @@ func (h vehicleRegHandlers) GetMyVehicleByID() echo.HandlerFunc {
 // @Produce      json
 // @Param        page   query     int  false  "Page number (default: 1)"
 // @Param        size   query     int  false  "Page size (default: 10)"
+// @Param        sort   query     string false  "Sort fields, comma separated, - prefix for descending, e.g. -created_at"
+// @Param        cursor query     string false  "Cursor of the next page, empty for the first one, switches to cursor pagination"
+// @Param        with_total query     bool   false  "Return an approximate total_count on cursor pages"
 // @Success      200    {object}  models.VehicleRegistrationList
-// @Failure      400    {object}  httpErrors.RestError
-// @Failure      500    {object}  httpErrors.RestError
+// @Failure      400    {object}  httpErrors.Problem
+// @Failure      500    {object}  httpErrors.Problem
+// @Security     JWT
 // @Router       /vehicle/inspections [get]
 func (h vehicleRegHandlers) GetInspections() echo.HandlerFunc {
 	return func(c echo.Context) error {
@@ -439,16 +752,16 @@ func (h vehicleRegHandlers) GetInspections() echo.HandlerFunc {
 // @Produce      json
 // @Param        code   path      string  true  "Registration Code"
 // @Success      200    {object}  models.VehicleRegistration
-// @Failure      400    {object}  httpErrors.RestError
-// @Failure      404    {object}  httpErrors.RestError
-// @Failure      500    {object}  httpErrors.RestError
+// @Failure      400    {object}  httpErrors.Problem
+// @Failure      404    {object}  httpErrors.Problem
+// @Failure      500    {object}  httpErrors.Problem
 // @Router       /vehicle/inspections/{code} [get]
 func (h vehicleRegHandlers) GetInspectionByCode() echo.HandlerFunc {
 	return func(c echo.Context) error {
 		ctx := c.Request().Context()
 		code := c.Param("code")
 		if code == "" {
-			return c.JSON(http.StatusBadRequest, map[string]string{"error": "registration_code is required"})
+			return c.JSON(httpErrors.ErrorResponse(httpErrors.NewRestError(http.StatusBadRequest, "registration_code is required", nil)))
 		}
 
 		inspection, err := h.vehicleRegUC.GetInspectionByCode(ctx, code)
@@ -13,16 +13,28 @@ func MapVehicleRegistrationRoutes(vehicleRegGroup *echo.Group, h vehicleRegistra
 	vehicleRegGroup.PUT("/:id", h.Update(), mw.AuthJWTMiddleware(authUC, cfg))
 	vehicleRegGroup.PUT("/:id/confirm-blockchain", h.ConfirmBlockchainStorage(), mw.AuthJWTMiddleware(authUC, cfg))
 	vehicleRegGroup.DELETE("/:id", h.Delete(), mw.AuthJWTMiddleware(authUC, cfg))
-	vehicleRegGroup.GET("/:id", h.GetByID())
-	vehicleRegGroup.GET("/getAll", h.GetAllVehicleReg())
-	vehicleRegGroup.GET("/search", h.SearchByVehiclePlateNO())
-	vehicleRegGroup.GET("/stats/type", h.GetStatsByType())
-	vehicleRegGroup.GET("/stats/brand", h.GetStatsByBrand())
-	vehicleRegGroup.GET("/stats/status", h.GetStatsByStatus())
+	vehicleRegGroup.GET("/:id", h.GetByID(), mw.AuthJWTMiddleware(authUC, cfg))
+	vehicleRegGroup.GET("/getAll", h.GetAllVehicleReg(), mw.AuthJWTMiddleware(authUC, cfg))
+	vehicleRegGroup.GET("/search", h.SearchByVehiclePlateNO(), mw.AuthJWTMiddleware(authUC, cfg))
+	vehicleRegGroup.GET("/export", h.Export(), mw.AuthJWTMiddleware(authUC, cfg))
+	vehicleRegGroup.POST("/import", h.Import(), mw.AuthJWTMiddleware(authUC, cfg))
+	vehicleRegGroup.GET("/stats/type", h.GetStatsByType(), mw.OptionalAuthJWTMiddleware(authUC, cfg))
+	vehicleRegGroup.GET("/stats/brand", h.GetStatsByBrand(), mw.OptionalAuthJWTMiddleware(authUC, cfg))
+	vehicleRegGroup.GET("/stats/status", h.GetStatsByStatus(), mw.OptionalAuthJWTMiddleware(authUC, cfg))
+	vehicleRegGroup.GET("/stats/series", h.GetStatsSeries(), mw.OptionalAuthJWTMiddleware(authUC, cfg))
 
 	// User
 	vehicleRegGroup.GET("/me", h.GetMyVehicles(), mw.AuthJWTMiddleware(authUC, cfg))
 	vehicleRegGroup.GET("/me/:id", h.GetMyVehicleByID(), mw.AuthJWTMiddleware(authUC, cfg))
-	vehicleRegGroup.GET("/inspections", h.GetInspections())
+	vehicleRegGroup.GET("/:id/ownership", h.GetOwnershipHistory(), mw.AuthJWTMiddleware(authUC, cfg))
+
+	// Lifecycle, officers only
+	vehicleRegGroup.POST("/:id/deregister", h.Deregister(), mw.AuthJWTMiddleware(authUC, cfg))
+	vehicleRegGroup.POST("/:id/stolen", h.ReportStolen(), mw.AuthJWTMiddleware(authUC, cfg))
+	vehicleRegGroup.POST("/:id/recover", h.Recover(), mw.AuthJWTMiddleware(authUC, cfg))
+	vehicleRegGroup.POST("/:id/seize", h.Seize(), mw.AuthJWTMiddleware(authUC, cfg))
+	vehicleRegGroup.POST("/:id/release", h.Release(), mw.AuthJWTMiddleware(authUC, cfg))
+	vehicleRegGroup.GET("/:id/events", h.GetEvents(), mw.AuthJWTMiddleware(authUC, cfg))
+	vehicleRegGroup.GET("/inspections", h.GetInspections(), mw.AuthJWTMiddleware(authUC, cfg))
 	vehicleRegGroup.GET("/inspections/:code", h.GetInspectionByCode())
 }
@@ -11,17 +11,25 @@ import (
 type Repository interface {
 	CreateVehicleDoc(ctx context.Context, vehicleDoc *models.VehicleRegistration) (*models.VehicleRegistration, error)
 	UpdateVehicleDoc(ctx context.Context, veDoc *models.VehicleRegistration) (*models.VehicleRegistration, error)
-	ConfirmBlockchainStorage(ctx context.Context, v *models.VehicleRegistration) (*models.VehicleRegistration, error)
+	ConfirmBlockchainStorage(ctx context.Context, v *models.VehicleRegistration, a *models.BlockchainAnchor) (*models.VehicleRegistration, error)
 	DeleteVehicleDoc(ctx context.Context, veDoc *models.VehicleRegistration) (*models.VehicleRegistration, error)
 	GetVehicleDocs(ctx context.Context, pq *utils.PaginationQuery) (*models.VehicleRegistrationList, error)
 	GetVehicleByID(ctx context.Context, vehicleID uuid.UUID) (*models.VehicleRegistration, error)
 	SearchByVehiclePlateNO(ctx context.Context, vePlaNO string, query *utils.PaginationQuery) (*models.VehicleRegistrationList, error)
+	CreateVehicleDocs(ctx context.Context, veDocs []*models.VehicleRegistration) error
+	CountVehicleDocs(ctx context.Context, vePlaNO string, query *utils.PaginationQuery) (int, error)
+	StreamVehicleDocs(ctx context.Context, vePlaNO string, query *utils.PaginationQuery, fn func(v *models.VehicleRegistration) error) error
 	FindVehiclePlateNO(ctx context.Context, veDoc *models.VehicleRegistration) (*models.VehicleRegistration, error)
 	GetCountByType(ctx context.Context) ([]*models.CountItem, error)
 	GetTopBrands(ctx context.Context) ([]*models.CountItem, error)
 	GetRegistrationStatusStats(ctx context.Context) (*models.StatusCounts, error)
+	GetRegistrationSeries(ctx context.Context, sq *utils.StatsQuery) ([]*models.TimeSeriesPoint, error)
 	GetVehiclesByOwnerID(ctx context.Context, ownerID uuid.UUID, pq *utils.PaginationQuery) (*models.VehicleRegistrationList, error)
 	GetVehicleByIDAndOwnerID(ctx context.Context, vehicleID, ownerID uuid.UUID) (*models.VehicleRegistration, error)
+	GetOwnerships(ctx context.Context, vehicleID uuid.UUID) ([]*models.VehicleOwnership, error)
+	RecordVehicleEvent(ctx context.Context, e *models.VehicleEvent) (*models.VehicleRegistration, error)
+	GetVehicleEvents(ctx context.Context, vehicleID uuid.UUID) ([]*models.VehicleEvent, error)
+	GetAgencyName(ctx context.Context, agencyID uuid.UUID) (string, error)
 
 	GetInspections(ctx context.Context, pq *utils.PaginationQuery) (*models.VehicleRegistrationList, error)
 	GetByRegistrationCode(ctx context.Context, code string) (*models.VehicleRegistration, error)
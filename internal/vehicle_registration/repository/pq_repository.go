@@ -3,7 +3,9 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"time"
 
+	anchorRepository "github.com/adohong4/driving-license/internal/anchor/repository"
 	"github.com/adohong4/driving-license/internal/models"
 	vehiclelicense "github.com/adohong4/driving-license/internal/vehicle_registration"
 	"github.com/adohong4/driving-license/pkg/utils"
@@ -22,44 +24,155 @@ func NewVehicleDocRepository(db *sqlx.DB) vehiclelicense.Repository {
 	return &vehicleDocRepo{db: db}
 }
 
+// Insert the vehicle document and its first owner
 func (r *vehicleDocRepo) CreateVehicleDoc(ctx context.Context, veDoc *models.VehicleRegistration) (*models.VehicleRegistration, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "vehicleDocRepo.CreateVehicleDoc.BeginTxx")
+	}
+	defer tx.Rollback()
+
+	if err = checkVehicleJurisdiction(ctx, tx, veDoc.OwnerID, veDoc.RegistrationPlace); err != nil {
+		return nil, errors.Wrap(err, "vehicleDocRepo.CreateVehicleDoc")
+	}
+
 	v := &models.VehicleRegistration{}
-	if err := r.db.QueryRowxContext(ctx, createLicenseQuery,
+	if err = tx.QueryRowxContext(ctx, createLicenseQuery,
 		veDoc.ID, veDoc.OwnerID, veDoc.Brand, veDoc.TypeVehicle, veDoc.VehiclePlateNo, veDoc.ColorPlate, veDoc.ChassisNo, veDoc.EngineNo, veDoc.ColorVehicle,
 		veDoc.OwnerName, veDoc.Seats, veDoc.IssueDate, veDoc.Issuer, veDoc.RegistrationCode, veDoc.RegistrationDate, veDoc.ExpiryDate, veDoc.RegistrationPlace, veDoc.OnBlockchain, veDoc.BlockchainTxHash,
 		veDoc.Status, veDoc.Version, veDoc.CreatorId, veDoc.ModifierId, veDoc.CreatedAt, veDoc.UpdatedAt,
 	).StructScan(v); err != nil {
 		return nil, errors.Wrap(err, "vehicleDocRepo.CreateVehicleDoc.StructScan")
 	}
+	if err = openOwnership(ctx, tx, models.NewRegistrationOwnership(v)); err != nil {
+		return nil, errors.Wrap(err, "vehicleDocRepo.CreateVehicleDoc.openOwnership")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "vehicleDocRepo.CreateVehicleDoc.Commit")
+	}
 	return v, nil
 }
 
+// Insert vehicle documents in a single transaction, nothing is inserted when one row fails
+func (r *vehicleDocRepo) CreateVehicleDocs(ctx context.Context, veDocs []*models.VehicleRegistration) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "vehicleDocRepo.CreateVehicleDocs.BeginTxx")
+	}
+	defer tx.Rollback()
+
+	for _, veDoc := range veDocs {
+		if err = checkVehicleJurisdiction(ctx, tx, veDoc.OwnerID, veDoc.RegistrationPlace); err != nil {
+			return errors.Wrapf(err, "vehicleDocRepo.CreateVehicleDocs %s", veDoc.VehiclePlateNo)
+		}
+		if _, err = tx.ExecContext(ctx, createLicenseQuery,
+			veDoc.ID, veDoc.OwnerID, veDoc.Brand, veDoc.TypeVehicle, veDoc.VehiclePlateNo, veDoc.ColorPlate, veDoc.ChassisNo, veDoc.EngineNo, veDoc.ColorVehicle,
+			veDoc.OwnerName, veDoc.Seats, veDoc.IssueDate, veDoc.Issuer, veDoc.RegistrationCode, veDoc.RegistrationDate, veDoc.ExpiryDate, veDoc.RegistrationPlace, veDoc.OnBlockchain, veDoc.BlockchainTxHash,
+			veDoc.Status, veDoc.Version, veDoc.CreatorId, veDoc.ModifierId, veDoc.CreatedAt, veDoc.UpdatedAt,
+		); err != nil {
+			return errors.Wrapf(err, "vehicleDocRepo.CreateVehicleDocs.ExecContext %s", veDoc.VehiclePlateNo)
+		}
+		if err = openOwnership(ctx, tx, models.NewRegistrationOwnership(veDoc)); err != nil {
+			return errors.Wrapf(err, "vehicleDocRepo.CreateVehicleDocs.openOwnership %s", veDoc.VehiclePlateNo)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "vehicleDocRepo.CreateVehicleDocs.Commit")
+	}
+	return nil
+}
+
+// Update the vehicle document, a new owner or plate closes the current ownership and opens a corrected one
 func (r *vehicleDocRepo) UpdateVehicleDoc(ctx context.Context, veDoc *models.VehicleRegistration) (*models.VehicleRegistration, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "vehicleDocRepo.UpdateVehicleDoc.BeginTxx")
+	}
+	defer tx.Rollback()
+
+	before := &models.VehicleRegistration{}
+	if err = tx.GetContext(ctx, before, getVehicleForUpdateQuery, veDoc.ID); err != nil {
+		return nil, errors.Wrap(err, "vehicleDocRepo.UpdateVehicleDoc.getVehicleForUpdate")
+	}
+
 	v := &models.VehicleRegistration{}
-	if err := r.db.QueryRowxContext(ctx, updateLicenseQuery,
+	if err = tx.QueryRowxContext(ctx, updateLicenseQuery,
 		veDoc.OwnerID, veDoc.Brand, veDoc.TypeVehicle, veDoc.VehiclePlateNo, veDoc.ColorPlate, veDoc.ChassisNo, veDoc.EngineNo,
 		veDoc.ColorVehicle, veDoc.OwnerName, veDoc.Seats, veDoc.IssueDate, veDoc.Issuer, veDoc.RegistrationCode, veDoc.RegistrationDate, veDoc.ExpiryDate, veDoc.RegistrationPlace,
-		veDoc.Status, veDoc.ModifierId, veDoc.Active, veDoc.ID, veDoc.Version,
+		veDoc.Status, veDoc.ModifierId, veDoc.Active, veDoc.ID, utils.JurisdictionFromCtx(ctx),
 	).StructScan(v); err != nil {
 		return nil, errors.Wrap(err, "vehicleDocRepo.UpdateVehicleDoc.StructScan")
 	}
+	// a new owner or registration place must not move the vehicle out of the jurisdiction
+	if err = checkVehicleJurisdiction(ctx, tx, v.OwnerID, v.RegistrationPlace); err != nil {
+		return nil, errors.Wrap(err, "vehicleDocRepo.UpdateVehicleDoc")
+	}
+
+	if !sameOwner(before.OwnerID, v.OwnerID) || before.VehiclePlateNo != v.VehiclePlateNo {
+		now := time.Now()
+		if _, err = tx.ExecContext(ctx, closeOwnershipQuery, now, v.ID); err != nil {
+			return nil, errors.Wrap(err, "vehicleDocRepo.UpdateVehicleDoc.closeOwnership")
+		}
+		if err = openOwnership(ctx, tx, &models.VehicleOwnership{
+			Id:               uuid.New(),
+			VehicleID:        v.ID,
+			OwnerID:          v.OwnerID,
+			OwnerName:        v.OwnerName,
+			VehiclePlateNo:   v.VehiclePlateNo,
+			FromDate:         now,
+			Reason:           models.OwnershipCorrection,
+			BlockchainTxHash: v.BlockchainTxHash,
+			CreatorId:        v.ModifierId,
+			CreatedAt:        now,
+		}); err != nil {
+			return nil, errors.Wrap(err, "vehicleDocRepo.UpdateVehicleDoc.openOwnership")
+		}
+	}
+	// the anchored hash no longer matches a corrected document
+	if err = anchorRepository.ReanchorChanged(ctx, tx, models.AnchorVehicle, v.ID, before.OnBlockchain, before, v); err != nil {
+		return nil, errors.Wrap(err, "vehicleDocRepo.UpdateVehicleDoc")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "vehicleDocRepo.UpdateVehicleDoc.Commit")
+	}
 	return v, nil
 }
 
-func (r *vehicleDocRepo) ConfirmBlockchainStorage(ctx context.Context, v *models.VehicleRegistration) (*models.VehicleRegistration, error) {
+// Confirm the vehicle document at the version its transaction was verified against, together with its anchor,
+// the transaction hash also backs the current ownership. sql.ErrNoRows when the document changed or was confirmed in the meantime
+func (r *vehicleDocRepo) ConfirmBlockchainStorage(ctx context.Context, v *models.VehicleRegistration, a *models.BlockchainAnchor) (*models.VehicleRegistration, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "VehicleDocRepo.ConfirmBlockchainStorage.BeginTxx")
+	}
+	defer tx.Rollback()
+
 	d := &models.VehicleRegistration{}
-	if err := r.db.QueryRowxContext(ctx, updateBlockchainConfirmationQuery,
-		v.BlockchainTxHash, v.OnBlockchain, v.ModifierId, v.UpdatedAt, v.ID,
+	if err = tx.QueryRowxContext(ctx, updateBlockchainConfirmationQuery,
+		v.BlockchainTxHash, v.OnBlockchain, v.ModifierId, v.UpdatedAt, v.ID, v.Version,
 	).StructScan(d); err != nil {
 		return nil, errors.Wrap(err, "VehicleDocRepo.ConfirmBlockchainStorage.StructScan")
 	}
+	if _, err = tx.ExecContext(ctx, confirmOwnershipQuery, v.BlockchainTxHash, v.ID); err != nil {
+		return nil, errors.Wrap(err, "VehicleDocRepo.ConfirmBlockchainStorage.confirmOwnership")
+	}
+	if err = anchorRepository.SaveConfirmedTx(ctx, tx, a); err != nil {
+		return nil, errors.Wrap(err, "VehicleDocRepo.ConfirmBlockchainStorage")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "VehicleDocRepo.ConfirmBlockchainStorage.Commit")
+	}
 	return d, nil
 }
 
 func (r *vehicleDocRepo) DeleteVehicleDoc(ctx context.Context, veDoc *models.VehicleRegistration) (*models.VehicleRegistration, error) {
 	v := &models.VehicleRegistration{}
 	if err := r.db.QueryRowxContext(ctx, deleteLicenseQuery,
-		veDoc.ModifierId, veDoc.UpdatedAt, veDoc.ID,
+		veDoc.ModifierId, veDoc.UpdatedAt, veDoc.ID, utils.JurisdictionFromCtx(ctx),
 	).StructScan(v); err != nil {
 		return nil, errors.Wrap(err, "VehicleDocRepo.DeleteVehicle.StructScan")
 	}
@@ -67,12 +180,18 @@ func (r *vehicleDocRepo) DeleteVehicleDoc(ctx context.Context, veDoc *models.Veh
 }
 
 func (r *vehicleDocRepo) GetVehicleDocs(ctx context.Context, pq *utils.PaginationQuery) (*models.VehicleRegistrationList, error) {
-	var totalCount int
-	if err := r.db.GetContext(ctx, &totalCount, getTotalCount); err != nil {
+	lc, err := pq.ListClause(vehicleListSpec, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	agency := utils.JurisdictionFromCtx(ctx)
+	totalCount, err := lc.Total(ctx, r.db, getTotalCount, getVehicleDocuments, agency)
+	if err != nil {
 		return nil, errors.Wrap(err, "VehicleDocRepo.GetContext.totalCount")
 	}
 
-	if totalCount == 0 {
+	if lc.Empty(totalCount) {
 		return &models.VehicleRegistrationList{
 			TotalCount:      totalCount,
 			TotalPages:      utils.GetTotalPage(totalCount, pq.GetSize()),
@@ -84,7 +203,7 @@ func (r *vehicleDocRepo) GetVehicleDocs(ctx context.Context, pq *utils.Paginatio
 	}
 
 	var NewVehicleDocs = make([]*models.VehicleRegistration, 0, pq.GetSize())
-	rows, err := r.db.QueryxContext(ctx, getVehicleDocuments, pq.GetOffset(), pq.GetLimit())
+	rows, err := r.db.QueryxContext(ctx, lc.Page(getVehicleDocuments), lc.PageArgs(pq, agency)...)
 	if err != nil {
 		return nil, errors.Wrap(err, "VehicleDocRepo.GetVehicleDocs.QueryRowxContext")
 	}
@@ -100,31 +219,43 @@ func (r *vehicleDocRepo) GetVehicleDocs(ctx context.Context, pq *utils.Paginatio
 	if err = rows.Err(); err != nil {
 		return nil, errors.Wrap(err, "VehicleDocRepo.GetNews.rows.Err")
 	}
+	NewVehicleDocs, nextCursor, err := utils.NextCursor(lc, NewVehicleDocs)
+	if err != nil {
+		return nil, errors.Wrap(err, "VehicleDocRepo.GetVehicleDocs.NextCursor")
+	}
+
 	return &models.VehicleRegistrationList{
 		TotalCount:      totalCount,
 		TotalPages:      utils.GetTotalPage(totalCount, pq.GetSize()),
 		Page:            pq.GetPage(),
 		Size:            pq.GetSize(),
-		HasMore:         utils.GetHasMore(pq.GetPage(), totalCount, pq.GetSize()),
+		HasMore:         lc.HasMore(pq, totalCount, nextCursor),
+		NextCursor:      nextCursor,
 		VehicleDocument: NewVehicleDocs,
 	}, nil
 }
 
 func (r *vehicleDocRepo) GetVehicleByID(ctx context.Context, vehicleID uuid.UUID) (*models.VehicleRegistration, error) {
 	v := &models.VehicleRegistration{}
-	if err := r.db.GetContext(ctx, v, getLicenseQuery, vehicleID); err != nil {
+	if err := r.db.GetContext(ctx, v, getLicenseQuery, vehicleID, utils.JurisdictionFromCtx(ctx)); err != nil {
 		return nil, errors.Wrap(err, "VehicleDocRepo.GetVehicleByID.GetContext")
 	}
 	return v, nil
 }
 
 func (r *vehicleDocRepo) SearchByVehiclePlateNO(ctx context.Context, vePlaNO string, query *utils.PaginationQuery) (*models.VehicleRegistrationList, error) {
-	var totalCount int
-	if err := r.db.GetContext(ctx, &totalCount, findByVehiclePlateNOCount, vePlaNO); err != nil {
+	lc, err := query.ListClause(vehicleListSpec.WithDefaultSort("vehicle_no"), 2, "vehicle_no")
+	if err != nil {
+		return nil, err
+	}
+
+	agency := utils.JurisdictionFromCtx(ctx)
+	totalCount, err := lc.Total(ctx, r.db, findByVehiclePlateNOCount, searchByVehiclePlateNO, vePlaNO, agency)
+	if err != nil {
 		return nil, errors.Wrap(err, "VehicleDocRepo.FindByVehiclePlateNOCount.GetContext")
 	}
 
-	if totalCount == 0 {
+	if lc.Empty(totalCount) {
 		return &models.VehicleRegistrationList{
 			TotalCount:      totalCount,
 			TotalPages:      utils.GetTotalPage(totalCount, query.GetSize()),
@@ -136,7 +267,7 @@ func (r *vehicleDocRepo) SearchByVehiclePlateNO(ctx context.Context, vePlaNO str
 	}
 
 	var NewVehicleDocs = make([]*models.VehicleRegistration, 0, query.GetSize())
-	rows, err := r.db.QueryxContext(ctx, searchByVehiclePlateNO, vePlaNO, query.GetOffset(), query.GetLimit())
+	rows, err := r.db.QueryxContext(ctx, lc.Page(searchByVehiclePlateNO), lc.PageArgs(query, vePlaNO, agency)...)
 	if err != nil {
 		return nil, errors.Wrap(err, "NewVehicleDocs.FindByVehiclePlateNOCount.QueryxContext")
 	}
@@ -154,16 +285,63 @@ func (r *vehicleDocRepo) SearchByVehiclePlateNO(ctx context.Context, vePlaNO str
 		return nil, errors.Wrap(err, "NewVehicleDocs.FindByVehiclePlateNOCount.rows.err")
 	}
 
+	NewVehicleDocs, nextCursor, err := utils.NextCursor(lc, NewVehicleDocs)
+	if err != nil {
+		return nil, errors.Wrap(err, "VehicleDocRepo.SearchByVehiclePlateNO.NextCursor")
+	}
+
 	return &models.VehicleRegistrationList{
 		TotalCount:      totalCount,
 		TotalPages:      utils.GetTotalPage(totalCount, query.GetSize()),
 		Page:            query.GetPage(),
 		Size:            query.GetSize(),
-		HasMore:         utils.GetHasMore(query.GetPage(), totalCount, query.GetSize()),
+		HasMore:         lc.HasMore(query, totalCount, nextCursor),
+		NextCursor:      nextCursor,
 		VehicleDocument: NewVehicleDocs,
 	}, nil
 }
 
+func (r *vehicleDocRepo) CountVehicleDocs(ctx context.Context, vePlaNO string, query *utils.PaginationQuery) (int, error) {
+	lc, err := query.ListClause(vehicleListSpec, 2, "vehicle_no")
+	if err != nil {
+		return 0, err
+	}
+
+	var totalCount int
+	if err := r.db.GetContext(ctx, &totalCount, lc.Count(findByVehiclePlateNOCount), lc.Args(vePlaNO, utils.JurisdictionFromCtx(ctx))...); err != nil {
+		return 0, errors.Wrap(err, "VehicleDocRepo.CountVehicleDocs.GetContext")
+	}
+	return totalCount, nil
+}
+
+func (r *vehicleDocRepo) StreamVehicleDocs(ctx context.Context, vePlaNO string, query *utils.PaginationQuery, fn func(v *models.VehicleRegistration) error) error {
+	lc, err := query.ListClause(vehicleListSpec, 2, "vehicle_no")
+	if err != nil {
+		return err
+	}
+
+	rows, err := r.db.QueryxContext(ctx, lc.All(exportVehicleDocuments), lc.Args(vePlaNO, utils.JurisdictionFromCtx(ctx))...)
+	if err != nil {
+		return errors.Wrap(err, "VehicleDocRepo.StreamVehicleDocs.QueryxContext")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		n := &models.VehicleRegistration{}
+		if err = rows.StructScan(n); err != nil {
+			return errors.Wrap(err, "VehicleDocRepo.StreamVehicleDocs.StructScan")
+		}
+		if err = fn(n); err != nil {
+			return err
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return errors.Wrap(err, "VehicleDocRepo.StreamVehicleDocs.rows.Err")
+	}
+	return nil
+}
+
 func (r *vehicleDocRepo) FindVehiclePlateNO(ctx context.Context, veDoc *models.VehicleRegistration) (*models.VehicleRegistration, error) {
 	foundVehicleReq := &models.VehicleRegistration{}
 	err := r.db.QueryRowxContext(ctx, findVehiclePlateNO, veDoc.VehiclePlateNo).StructScan(foundVehicleReq)
@@ -178,7 +356,7 @@ func (r *vehicleDocRepo) FindVehiclePlateNO(ctx context.Context, veDoc *models.V
 
 func (r *vehicleDocRepo) GetCountByType(ctx context.Context) ([]*models.CountItem, error) {
 	var items []*models.CountItem
-	rows, err := r.db.QueryxContext(ctx, getCountByType)
+	rows, err := r.db.QueryxContext(ctx, getCountByType, utils.JurisdictionFromCtx(ctx))
 	if err != nil {
 		return nil, errors.Wrap(err, "vehicleDocRepo.GetCountByType.QueryxContext")
 	}
@@ -216,7 +394,7 @@ func (r *vehicleDocRepo) GetCountByType(ctx context.Context) ([]*models.CountIte
 
 func (r *vehicleDocRepo) GetTopBrands(ctx context.Context) ([]*models.CountItem, error) {
 	var items []*models.CountItem
-	rows, err := r.db.QueryxContext(ctx, getTopBrands)
+	rows, err := r.db.QueryxContext(ctx, getTopBrands, utils.JurisdictionFromCtx(ctx))
 	if err != nil {
 		return nil, errors.Wrap(err, "vehicleDocRepo.GetTopBrands.QueryxContext")
 	}
@@ -239,7 +417,7 @@ func (r *vehicleDocRepo) GetTopBrands(ctx context.Context) ([]*models.CountItem,
 
 	// Calculate others
 	var total int
-	if err := r.db.GetContext(ctx, &total, getTotalActiveVehicles); err != nil {
+	if err := r.db.GetContext(ctx, &total, getTotalActiveVehicles, utils.JurisdictionFromCtx(ctx)); err != nil {
 		return nil, errors.Wrap(err, "vehicleDocRepo.GetTopBrands.GetTotalActiveVehicles")
 	}
 	others := total - topSum
@@ -251,7 +429,7 @@ func (r *vehicleDocRepo) GetTopBrands(ctx context.Context) ([]*models.CountItem,
 func (r *vehicleDocRepo) GetRegistrationStatusStats(ctx context.Context) (*models.StatusCounts, error) {
 	var valid, expired, pending int
 
-	err := r.db.QueryRowxContext(ctx, getRegistrationStatusStats).Scan(
+	err := r.db.QueryRowxContext(ctx, getRegistrationStatusStats, utils.JurisdictionFromCtx(ctx)).Scan(
 		&valid,
 		&expired,
 		&pending,
@@ -269,13 +447,28 @@ func (r *vehicleDocRepo) GetRegistrationStatusStats(ctx context.Context) (*model
 	return (*models.StatusCounts)(&items), nil
 }
 
+func (r *vehicleDocRepo) GetRegistrationSeries(ctx context.Context, sq *utils.StatsQuery) ([]*models.TimeSeriesPoint, error) {
+	var points []*models.TimeSeriesPoint
+	if err := r.db.SelectContext(ctx, &points, getRegistrationSeriesQuery,
+		sq.GroupBy, sq.From, sq.To, sq.City, utils.JurisdictionFromCtx(ctx),
+	); err != nil {
+		return nil, errors.Wrap(err, "vehicleDocRepo.GetRegistrationSeries.SelectContext")
+	}
+	return points, nil
+}
+
 func (r *vehicleDocRepo) GetVehiclesByOwnerID(ctx context.Context, ownerID uuid.UUID, pq *utils.PaginationQuery) (*models.VehicleRegistrationList, error) {
-	var totalCount int
-	if err := r.db.GetContext(ctx, &totalCount, getTotalCountByOwnerID, ownerID); err != nil {
+	lc, err := pq.ListClause(vehicleListSpec, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	totalCount, err := lc.Total(ctx, r.db, getTotalCountByOwnerID, getVehiclesByOwnerID, ownerID)
+	if err != nil {
 		return nil, errors.Wrap(err, "vehicleDocRepo.GetVehiclesByOwnerID.totalCount")
 	}
 
-	if totalCount == 0 {
+	if lc.Empty(totalCount) {
 		return &models.VehicleRegistrationList{
 			TotalCount:      totalCount,
 			TotalPages:      utils.GetTotalPage(totalCount, pq.GetSize()),
@@ -287,7 +480,7 @@ func (r *vehicleDocRepo) GetVehiclesByOwnerID(ctx context.Context, ownerID uuid.
 	}
 
 	var vehicles []*models.VehicleRegistration
-	rows, err := r.db.QueryxContext(ctx, getVehiclesByOwnerID, ownerID, pq.GetOffset(), pq.GetLimit())
+	rows, err := r.db.QueryxContext(ctx, lc.Page(getVehiclesByOwnerID), lc.PageArgs(pq, ownerID)...)
 	if err != nil {
 		return nil, errors.Wrap(err, "vehicleDocRepo.GetVehiclesByOwnerID.QueryxContext")
 	}
@@ -304,12 +497,18 @@ func (r *vehicleDocRepo) GetVehiclesByOwnerID(ctx context.Context, ownerID uuid.
 		return nil, errors.Wrap(err, "vehicleDocRepo.GetVehiclesByOwnerID.rows.Err")
 	}
 
+	vehicles, nextCursor, err := utils.NextCursor(lc, vehicles)
+	if err != nil {
+		return nil, errors.Wrap(err, "vehicleDocRepo.GetVehiclesByOwnerID.NextCursor")
+	}
+
 	return &models.VehicleRegistrationList{
 		TotalCount:      totalCount,
 		TotalPages:      utils.GetTotalPage(totalCount, pq.GetSize()),
 		Page:            pq.GetPage(),
 		Size:            pq.GetSize(),
-		HasMore:         utils.GetHasMore(pq.GetPage(), totalCount, pq.GetSize()),
+		HasMore:         lc.HasMore(pq, totalCount, nextCursor),
+		NextCursor:      nextCursor,
 		VehicleDocument: vehicles,
 	}, nil
 }
@@ -327,12 +526,18 @@ func (r *vehicleDocRepo) GetVehicleByIDAndOwnerID(ctx context.Context, vehicleID
 }
 
 func (r *vehicleDocRepo) GetInspections(ctx context.Context, pq *utils.PaginationQuery) (*models.VehicleRegistrationList, error) {
-	var totalCount int
-	if err := r.db.GetContext(ctx, &totalCount, getInspectionsCount); err != nil {
+	lc, err := pq.ListClause(vehicleListSpec, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	agency := utils.JurisdictionFromCtx(ctx)
+	totalCount, err := lc.Total(ctx, r.db, getInspectionsCount, getInspections, agency)
+	if err != nil {
 		return nil, errors.Wrap(err, "vehicleDocRepo.GetInspections.totalCount")
 	}
 
-	if totalCount == 0 {
+	if lc.Empty(totalCount) {
 		return &models.VehicleRegistrationList{
 			TotalCount:      totalCount,
 			TotalPages:      utils.GetTotalPage(totalCount, pq.GetSize()),
@@ -344,7 +549,7 @@ func (r *vehicleDocRepo) GetInspections(ctx context.Context, pq *utils.Paginatio
 	}
 
 	var inspections = make([]*models.VehicleRegistration, 0, pq.GetSize())
-	rows, err := r.db.QueryxContext(ctx, getInspections, pq.GetOffset(), pq.GetLimit())
+	rows, err := r.db.QueryxContext(ctx, lc.Page(getInspections), lc.PageArgs(pq, agency)...)
 	if err != nil {
 		return nil, errors.Wrap(err, "vehicleDocRepo.GetInspections.QueryxContext")
 	}
@@ -360,12 +565,18 @@ func (r *vehicleDocRepo) GetInspections(ctx context.Context, pq *utils.Paginatio
 	if err = rows.Err(); err != nil {
 		return nil, errors.Wrap(err, "vehicleDocRepo.GetInspections.rows.Err")
 	}
+	inspections, nextCursor, err := utils.NextCursor(lc, inspections)
+	if err != nil {
+		return nil, errors.Wrap(err, "vehicleDocRepo.GetInspections.NextCursor")
+	}
+
 	return &models.VehicleRegistrationList{
 		TotalCount:      totalCount,
 		TotalPages:      utils.GetTotalPage(totalCount, pq.GetSize()),
 		Page:            pq.GetPage(),
 		Size:            pq.GetSize(),
-		HasMore:         utils.GetHasMore(pq.GetPage(), totalCount, pq.GetSize()),
+		HasMore:         lc.HasMore(pq, totalCount, nextCursor),
+		NextCursor:      nextCursor,
 		VehicleDocument: inspections,
 	}, nil
 }
@@ -381,3 +592,98 @@ func (r *vehicleDocRepo) GetByRegistrationCode(ctx context.Context, code string)
 	}
 	return v, nil
 }
+
+// Chain of title of a vehicle, oldest owner first
+func (r *vehicleDocRepo) GetOwnerships(ctx context.Context, vehicleID uuid.UUID) ([]*models.VehicleOwnership, error) {
+	owners := []*models.VehicleOwnership{}
+	if err := r.db.SelectContext(ctx, &owners, getOwnershipsQuery, vehicleID); err != nil {
+		return nil, errors.Wrap(err, "VehicleDocRepo.GetOwnerships.SelectContext")
+	}
+	return owners, nil
+}
+
+// Apply a lifecycle event to the vehicle and record it, a deregistration also ends the current ownership
+func (r *vehicleDocRepo) RecordVehicleEvent(ctx context.Context, e *models.VehicleEvent) (*models.VehicleRegistration, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "vehicleDocRepo.RecordVehicleEvent.BeginTxx")
+	}
+	defer tx.Rollback()
+
+	var row *sqlx.Row
+	switch e.Event {
+	case models.VehicleStolen, models.VehicleRecovered:
+		row = tx.QueryRowxContext(ctx, setStolenQuery, e.Event == models.VehicleStolen, e.OfficerID, e.VehicleID, utils.JurisdictionFromCtx(ctx))
+	case models.VehicleSeized, models.VehicleReleased:
+		row = tx.QueryRowxContext(ctx, setSeizedQuery, e.Event == models.VehicleSeized, e.OfficerID, e.VehicleID, utils.JurisdictionFromCtx(ctx))
+	case models.VehicleDeregistered:
+		row = tx.QueryRowxContext(ctx, deregisterQuery, e.EventDate, e.OfficerID, e.VehicleID, utils.JurisdictionFromCtx(ctx))
+	default:
+		return nil, errors.Errorf("vehicleDocRepo.RecordVehicleEvent: unknown event %q", e.Event)
+	}
+	v := &models.VehicleRegistration{}
+	if err = row.StructScan(v); err != nil {
+		return nil, errors.Wrap(err, "vehicleDocRepo.RecordVehicleEvent.StructScan")
+	}
+
+	if _, err = tx.ExecContext(ctx, createVehicleEventQuery,
+		e.Id, e.VehicleID, e.VehiclePlateNo, e.Event, e.Cause, e.Reason, e.EventDate, e.AgencyID, e.AgencyName, e.OfficerID, e.CreatedAt,
+	); err != nil {
+		return nil, errors.Wrap(err, "vehicleDocRepo.RecordVehicleEvent.createEvent")
+	}
+	if e.Event == models.VehicleDeregistered {
+		if _, err = tx.ExecContext(ctx, closeOwnershipQuery, e.EventDate, e.VehicleID); err != nil {
+			return nil, errors.Wrap(err, "vehicleDocRepo.RecordVehicleEvent.closeOwnership")
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "vehicleDocRepo.RecordVehicleEvent.Commit")
+	}
+	return v, nil
+}
+
+// Lifecycle events of a vehicle, deregistered vehicles included, latest first
+func (r *vehicleDocRepo) GetVehicleEvents(ctx context.Context, vehicleID uuid.UUID) ([]*models.VehicleEvent, error) {
+	events := []*models.VehicleEvent{}
+	if err := r.db.SelectContext(ctx, &events, getVehicleEventsQuery, vehicleID); err != nil {
+		return nil, errors.Wrap(err, "vehicleDocRepo.GetVehicleEvents.SelectContext")
+	}
+	return events, nil
+}
+
+// Name of an active agency
+func (r *vehicleDocRepo) GetAgencyName(ctx context.Context, agencyID uuid.UUID) (string, error) {
+	var name string
+	if err := r.db.GetContext(ctx, &name, getAgencyName, agencyID); err != nil {
+		return "", errors.Wrap(err, "vehicleDocRepo.GetAgencyName.GetContext")
+	}
+	return name, nil
+}
+
+// Vehicles are placed by the city of the owner's license, the registration place otherwise
+func checkVehicleJurisdiction(ctx context.Context, q sqlx.QueryerContext, ownerID *uuid.UUID, place *string) error {
+	if utils.JurisdictionFromCtx(ctx) == nil {
+		return nil
+	}
+
+	var province string
+	if err := sqlx.GetContext(ctx, q, &province, getVehicleProvince, ownerID, place); err != nil {
+		return errors.Wrap(err, "checkVehicleJurisdiction.GetContext")
+	}
+	return utils.CheckJurisdiction(ctx, q, province, nil)
+}
+
+func openOwnership(ctx context.Context, tx *sqlx.Tx, o *models.VehicleOwnership) error {
+	_, err := tx.ExecContext(ctx, openOwnershipQuery,
+		o.Id, o.VehicleID, o.OwnerID, o.OwnerName, o.IdentityNo, o.VehiclePlateNo, o.FromDate, o.Reason, o.BlockchainTxHash, o.CreatorId, o.CreatedAt,
+	)
+	return err
+}
+
+func sameOwner(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -1,5 +1,7 @@
 package repository
 
+import "github.com/adohong4/driving-license/pkg/utils"
+
 const (
 	createLicenseQuery = `
 	INSERT INTO vehicle_registration (
@@ -38,7 +40,7 @@ const (
         version = version + 1,
         updated_at = now(),
         active = COALESCE($19, active)
-    WHERE id = $20
+    WHERE id = $20 AND f_in_jurisdiction($21, f_vehicle_province(owner_id, registration_place))
     RETURNING *
 	`
 
@@ -50,10 +52,112 @@ const (
         modifier_id = COALESCE($3, modifier_id),
         version = version + 1,
         updated_at = $4
-    WHERE id = $5
+    WHERE id = $5 AND version = $6 AND on_blockchain = false
     RETURNING *
     `
 
+	// vehicle document before an update, locked until the ownership history and its re-anchor are written
+	getVehicleForUpdateQuery = `
+	SELECT *
+	FROM vehicle_registration
+	WHERE id = $1
+	FOR UPDATE
+	`
+
+	// the identity number is looked up from the owner, a license id or a user id, when not given
+	openOwnershipQuery = `
+	INSERT INTO vehicle_ownerships (
+		id, vehicle_id, owner_id, owner_name, identity_no, vehicle_no, from_date, reason, blockchain_txhash, creator_id, created_at
+	) VALUES (
+		$1, $2, $3, $4,
+		COALESCE(
+			NULLIF($5, ''),
+			(SELECT identity_no FROM driver_licenses WHERE id = $3),
+			(SELECT identity_no FROM users WHERE id = $3),
+			''
+		),
+		$6, $7, $8, $9, $10, $11
+	)
+	`
+
+	closeOwnershipQuery = `
+	UPDATE vehicle_ownerships
+	SET to_date = $1
+	WHERE vehicle_id = $2 AND to_date IS NULL
+	`
+
+	confirmOwnershipQuery = `
+	UPDATE vehicle_ownerships
+	SET blockchain_txhash = $1
+	WHERE vehicle_id = $2 AND to_date IS NULL AND $1 <> ''
+	`
+
+	// chain of title, oldest owner first
+	getOwnershipsQuery = `
+	SELECT *
+	FROM vehicle_ownerships
+	WHERE vehicle_id = $1
+	ORDER BY from_date, created_at
+	`
+
+	// area of a vehicle, vehicles have no area of their own (see f_vehicle_province in migrations)
+	getVehicleProvince = `SELECT COALESCE(f_vehicle_province($1, $2), '')`
+
+	// lifecycle flags only change from the opposite state
+	setStolenQuery = `
+	UPDATE vehicle_registration
+	SET
+		stolen = $1,
+		modifier_id = $2,
+		version = version + 1,
+		updated_at = now()
+	WHERE id = $3 AND active = true AND stolen <> $1 AND f_in_jurisdiction($4, f_vehicle_province(owner_id, registration_place))
+	RETURNING *
+	`
+
+	setSeizedQuery = `
+	UPDATE vehicle_registration
+	SET
+		seized = $1,
+		modifier_id = $2,
+		version = version + 1,
+		updated_at = now()
+	WHERE id = $3 AND active = true AND seized <> $1 AND f_in_jurisdiction($4, f_vehicle_province(owner_id, registration_place))
+	RETURNING *
+	`
+
+	// stolen or seized vehicles are recovered or released before they are deregistered
+	deregisterQuery = `
+	UPDATE vehicle_registration
+	SET
+		active = false,
+		deregistered_at = $1,
+		modifier_id = $2,
+		version = version + 1,
+		updated_at = now()
+	WHERE id = $3 AND active = true AND stolen = false AND seized = false AND f_in_jurisdiction($4, f_vehicle_province(owner_id, registration_place))
+	RETURNING *
+	`
+
+	createVehicleEventQuery = `
+	INSERT INTO vehicle_events (
+		id, vehicle_id, vehicle_no, event, cause, reason, event_date, agency_id, agency_name, officer_id, created_at
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+
+	getVehicleEventsQuery = `
+	SELECT *
+	FROM vehicle_events
+	WHERE vehicle_id = $1
+	ORDER BY event_date DESC, created_at DESC
+	`
+
+	getAgencyName = `
+	SELECT name
+	FROM gov_agencies
+	WHERE id = $1 AND active = true
+	`
+
 	deleteLicenseQuery = `
 	UPDATE vehicle_registration
 	SET
@@ -61,35 +165,35 @@ const (
 		version = version + 1,
 		modifier_id = $1,
 		updated_at = $2
-	WHERE id = $3
+	WHERE id = $3 AND f_in_jurisdiction($4, f_vehicle_province(owner_id, registration_place))
 	RETURNING *
 	`
 
 	getLicenseQuery = `
 	SELECT *
 	FROM vehicle_registration
-	WHERE id = $1 AND active = true
+	WHERE id = $1 AND active = true AND f_in_jurisdiction($2, f_vehicle_province(owner_id, registration_place))
 	`
 
 	getTotalCount = `
-	SELECT COUNT(id)
-	FROM vehicle_registration
-	WHERE active = true
+	SELECT COUNT(vr.id)
+	FROM vehicle_registration vr
+	WHERE vr.active = true AND f_in_jurisdiction($1, f_vehicle_province(vr.owner_id, vr.registration_place))
 	`
 
 	findByVehiclePlateNOCount = `
 		SELECT COUNT(*)
-		FROM vehicle_registration
-		WHERE active = true
-		AND vehicle_no ILIKE '%' || $1 || '%'
+		FROM vehicle_registration vr
+		WHERE vr.active = true
+		AND regexp_replace(vr.vehicle_no, '[^A-Za-z0-9]', '', 'g') ILIKE '%' || $1 || '%'
+		AND f_in_jurisdiction($2, f_vehicle_province(vr.owner_id, vr.registration_place))
 	`
 
 	searchByVehiclePlateNO = `
-    SELECT * 
-    FROM vehicle_registration
-    WHERE vehicle_no ILIKE '%' || $1 || '%' AND active = true	
-    ORDER BY vehicle_no
-    OFFSET $2 LIMIT $3
+    SELECT vr.*
+    FROM vehicle_registration vr
+    WHERE regexp_replace(vr.vehicle_no, '[^A-Za-z0-9]', '', 'g') ILIKE '%' || $1 || '%' AND vr.active = true
+      AND f_in_jurisdiction($2, f_vehicle_province(vr.owner_id, vr.registration_place))
 	`
 
 	getVehicleDocuments = `
@@ -117,12 +221,48 @@ const (
         vr.updated_at, 
         vr.created_at,
         vr.on_blockchain,
-        vr.blockchain_txhash
+        vr.blockchain_txhash,
+        vr.stolen,
+        vr.seized
     FROM vehicle_registration vr
     LEFT JOIN driver_licenses dl ON vr.owner_id = dl.id AND dl.active = true
-    WHERE vr.active = true
-    ORDER BY vr.updated_at DESC, vr.created_at DESC
-    OFFSET $1 LIMIT $2
+    WHERE vr.active = true AND f_in_jurisdiction($1, f_vehicle_province(vr.owner_id, vr.registration_place))
+    `
+
+	// Export, same filter as searchByVehiclePlateNO without pagination
+	exportVehicleDocuments = `
+    SELECT 
+        vr.id, 
+        vr.owner_id, 
+        vr.brand, 
+        vr.type_vehicle, 
+        vr.vehicle_no, 
+        vr.color_plate, 
+        vr.chassis_no, 
+        vr.engine_no, 
+        vr.color_vehicle,
+        COALESCE(dl.full_name, vr.owner_name) AS owner_name,
+        vr.seats,
+        vr.issue_date, 
+        vr.expiry_date, 
+        vr.issuer,
+        vr.registration_code,
+        vr.registration_date,
+        vr.registration_place,
+        vr.status, 
+        vr.version, 
+        vr.creator_id, 
+        vr.modifier_id, 
+        vr.updated_at, 
+        vr.created_at,
+        vr.on_blockchain,
+        vr.blockchain_txhash,
+        vr.stolen,
+        vr.seized
+    FROM vehicle_registration vr
+    LEFT JOIN driver_licenses dl ON vr.owner_id = dl.id AND dl.active = true
+    WHERE regexp_replace(vr.vehicle_no, '[^A-Za-z0-9]', '', 'g') ILIKE '%' || $1 || '%' AND vr.active = true
+      AND f_in_jurisdiction($2, f_vehicle_province(vr.owner_id, vr.registration_place))
     `
 
 	findVehiclePlateNO = `
@@ -131,32 +271,45 @@ const (
 	WHERE vehicle_no = $1 AND active = true
 	`
 
-	// Query for count by type_vehicle
+	// Query for count by type_vehicle, read from mv_vehicle_stats, stats are limited to the jurisdiction of the caller
 	getCountByType = `
-    SELECT type_vehicle, COUNT(*) as count
-    FROM vehicle_registration
-    WHERE active = true
+    SELECT type_vehicle, SUM(count)::int as count
+    FROM mv_vehicle_stats
+    WHERE f_in_jurisdiction($1, province)
     GROUP BY type_vehicle
     `
 
+	// Motor vehicles only (is_motor excludes motorcycles, mopeds, bicycles)
 	getRegistrationStatusStats = `
     SELECT 
-        COUNT(*) FILTER (WHERE expiry_date >= CURRENT_DATE AND expiry_date IS NOT NULL) AS valid_count,
-        COUNT(*) FILTER (WHERE expiry_date < CURRENT_DATE AND expiry_date IS NOT NULL) AS expired_count,
-        COUNT(*) FILTER (WHERE expiry_date IS NULL OR registration_date IS NULL) AS pending_count
+        COALESCE(SUM(count) FILTER (WHERE is_valid), 0)::int AS valid_count,
+        COALESCE(SUM(count) FILTER (WHERE is_expired), 0)::int AS expired_count,
+        COALESCE(SUM(count) FILTER (WHERE is_pending), 0)::int AS pending_count
+    FROM mv_vehicle_stats
+    WHERE is_motor AND f_in_jurisdiction($1, province)
+    `
+
+	// Query for registrations issued per period, city matches issuer or registration place
+	getRegistrationSeriesQuery = `
+    SELECT
+        date_trunc($1, issue_date::timestamp) AS period,
+        COUNT(*) AS count,
+        0 AS amount
     FROM vehicle_registration
     WHERE active = true
-      AND type_vehicle NOT ILIKE ANY (ARRAY[
-        '%xe máy%', '%xe mô tô%', '%xe gắn máy%', 
-        '%xe đạp%', '%xe đạp điện%', '%xe máy điện%'
-      ])
+      AND issue_date IS NOT NULL
+      AND issue_date::timestamp >= $2 AND issue_date::timestamp < $3
+      AND ($4 = '' OR issuer ILIKE '%' || $4 || '%' OR registration_place ILIKE '%' || $4 || '%')
+      AND f_in_jurisdiction($5, f_vehicle_province(owner_id, registration_place))
+    GROUP BY period
+    ORDER BY period
     `
 
 	// Query for top 5 brands
 	getTopBrands = `
-    SELECT brand, COUNT(*) as count
-    FROM vehicle_registration
-    WHERE active = true
+    SELECT brand, SUM(count)::int as count
+    FROM mv_vehicle_stats
+    WHERE f_in_jurisdiction($1, province)
     GROUP BY brand
     ORDER BY count DESC
     LIMIT 5
@@ -164,9 +317,9 @@ const (
 
 	// Total active vehicles for others calculation
 	getTotalActiveVehicles = `
-    SELECT COUNT(*)
-    FROM vehicle_registration
-    WHERE active = true
+    SELECT COALESCE(SUM(count), 0)::int
+    FROM mv_vehicle_stats
+    WHERE f_in_jurisdiction($1, province)
     `
 
 	// User - Owner ID
@@ -197,14 +350,14 @@ const (
         vr.created_at,
         vr.on_blockchain,
         vr.blockchain_txhash,
-        vr.active
+        vr.active,
+        vr.stolen,
+        vr.seized
     FROM vehicle_registration vr
     INNER JOIN driver_licenses dl ON vr.owner_id = dl.id AND dl.active = true
     INNER JOIN users u ON dl.identity_no = u.identity_no AND u.active = true
     WHERE u.id = $1
       AND vr.active = true
-    ORDER BY vr.updated_at DESC, vr.created_at DESC
-    OFFSET $2 LIMIT $3
     `
 
 	getTotalCountByOwnerID = `
@@ -247,18 +400,18 @@ const (
         vr.updated_at, 
         vr.created_at,
         vr.on_blockchain,
-        vr.blockchain_txhash
+        vr.blockchain_txhash,
+        vr.stolen,
+        vr.seized
     FROM vehicle_registration vr
     LEFT JOIN driver_licenses dl ON vr.owner_id = dl.id AND dl.active = true
-    WHERE vr.registration_code IS NOT NULL AND vr.active = true
-    ORDER BY vr.updated_at DESC, vr.created_at DESC
-    OFFSET $1 LIMIT $2
+    WHERE vr.registration_code IS NOT NULL AND vr.active = true AND f_in_jurisdiction($1, f_vehicle_province(vr.owner_id, vr.registration_place))
     `
 
 	getInspectionsCount = `
     SELECT COUNT(*)
     FROM vehicle_registration vr
-    WHERE vr.registration_code IS NOT NULL AND vr.active = true
+    WHERE vr.registration_code IS NOT NULL AND vr.active = true AND f_in_jurisdiction($1, f_vehicle_province(vr.owner_id, vr.registration_place))
     `
 
 	getByRegistrationCode = `
@@ -287,13 +440,43 @@ const (
         vr.updated_at, 
         vr.created_at,
         vr.on_blockchain,
-        vr.blockchain_txhash
+        vr.blockchain_txhash,
+        vr.stolen,
+        vr.seized
     FROM vehicle_registration vr
     LEFT JOIN driver_licenses dl ON vr.owner_id = dl.id AND dl.active = true
     WHERE vr.registration_code = $1 AND vr.active = true
     `
 )
 
+// Filters and sorts accepted by the vehicle lists, filter, sort and cursor clauses are appended to the queries above
+var vehicleListSpec = &utils.ListSpec{
+	Fields: map[string]utils.ListField{
+		"vehicle_no":         {Column: "vr.vehicle_no", Type: utils.FilterText, Sortable: true, Keyset: true},
+		"owner_id":           {Column: "vr.owner_id", Type: utils.FilterUUID},
+		"owner_name":         {Column: "vr.owner_name", Type: utils.FilterText, Sortable: true},
+		"brand":              {Column: "vr.brand", Type: utils.FilterExact, Sortable: true, Keyset: true},
+		"type_vehicle":       {Column: "vr.type_vehicle", Type: utils.FilterExact, Sortable: true, Keyset: true},
+		"color_vehicle":      {Column: "vr.color_vehicle", Type: utils.FilterExact},
+		"color_plate":        {Column: "vr.color_plate", Type: utils.FilterExact},
+		"chassis_no":         {Column: "vr.chassis_no", Type: utils.FilterText},
+		"engine_no":          {Column: "vr.engine_no", Type: utils.FilterText},
+		"seats":              {Column: "vr.seats", Type: utils.FilterNumber, Sortable: true},
+		"issuer":             {Column: "vr.issuer", Type: utils.FilterText},
+		"registration_code":  {Column: "vr.registration_code", Type: utils.FilterExact},
+		"registration_place": {Column: "vr.registration_place", Type: utils.FilterText},
+		"status":             {Column: "vr.status", Type: utils.FilterExact, Sortable: true, Keyset: true},
+		"on_blockchain":      {Column: "vr.on_blockchain", Type: utils.FilterBool},
+		"issue_date":         {Column: "vr.issue_date::date", Type: utils.FilterDate, Sortable: true},
+		"registration_date":  {Column: "vr.registration_date::date", Type: utils.FilterDate, Sortable: true},
+		"expiry_date":        {Column: "vr.expiry_date::date", Type: utils.FilterDate, Sortable: true},
+		"created_at":         {Column: "vr.created_at", Type: utils.FilterDate, Sortable: true, Keyset: true},
+		"updated_at":         {Column: "vr.updated_at", Type: utils.FilterDate, Sortable: true, Keyset: true},
+	},
+	DefaultSort: "-updated_at,-created_at",
+	IDColumn:    "vr.id",
+}
+
 var excludedVehicleTypes = []string{
 	"%xe máy%",
 	"%xe mô tô%",
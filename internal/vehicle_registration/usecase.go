@@ -2,8 +2,11 @@ package vehicleRegistration
 
 import (
 	"context"
+	"time"
 
 	"github.com/adohong4/driving-license/internal/models"
+	"github.com/adohong4/driving-license/pkg/export"
+	"github.com/adohong4/driving-license/pkg/importer"
 	"github.com/adohong4/driving-license/pkg/utils"
 	"github.com/google/uuid"
 )
@@ -16,11 +19,17 @@ type UseCase interface {
 	GetVehicleDocs(ctx context.Context, pq *utils.PaginationQuery) (*models.VehicleRegistrationList, error)
 	GetVehicleByID(ctx context.Context, vehicleID uuid.UUID) (*models.VehicleRegistration, error)
 	FindByVehiclePlateNO(ctx context.Context, vePlaNO string, query *utils.PaginationQuery) (*models.VehicleRegistrationList, error)
-	GetCountByType(ctx context.Context) (models.VehicleTypeCounts, error)
-	GetTopBrands(ctx context.Context) (models.BrandCounts, error)
-	GetCountByStatus(ctx context.Context) (models.StatusCounts, error)
+	ExportVehicleDocs(ctx context.Context, eq *utils.ExportQuery) *export.Source
+	ImportVehicleDocs(ctx context.Context) *importer.Target
+	GetCountByType(ctx context.Context) (models.VehicleTypeCounts, time.Time, error)
+	GetTopBrands(ctx context.Context) (models.BrandCounts, time.Time, error)
+	GetCountByStatus(ctx context.Context) (models.StatusCounts, time.Time, error)
+	GetRegistrationSeries(ctx context.Context, sq *utils.StatsQuery) (*models.TimeSeries, time.Time, error)
 	GetMyVehicles(ctx context.Context, pq *utils.PaginationQuery) (*models.VehicleRegistrationList, error)
 	GetMyVehicleByID(ctx context.Context, vehicleID uuid.UUID) (*models.VehicleRegistration, error)
+	GetChainOfTitle(ctx context.Context, vehicleID uuid.UUID) (*models.ChainOfTitle, error)
+	RecordVehicleEvent(ctx context.Context, vehicleID uuid.UUID, event string, req *models.VehicleEventRequest) (*models.VehicleRegistration, error)
+	GetVehicleEvents(ctx context.Context, vehicleID uuid.UUID) (*models.VehicleEventList, error)
 
 	GetInspections(ctx context.Context, pq *utils.PaginationQuery) (*models.VehicleRegistrationList, error)
 	GetInspectionByCode(ctx context.Context, code string) (*models.VehicleRegistration, error)
@@ -4,30 +4,46 @@ import (
 	"context"
 	"database/sql"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/adohong4/driving-license/config"
+	"github.com/adohong4/driving-license/internal/anchor"
 	"github.com/adohong4/driving-license/internal/models"
+	"github.com/adohong4/driving-license/internal/stats"
 	vehicleRegistration "github.com/adohong4/driving-license/internal/vehicle_registration"
+	"github.com/adohong4/driving-license/pkg/export"
 	"github.com/adohong4/driving-license/pkg/httpErrors"
+	"github.com/adohong4/driving-license/pkg/importer"
 	"github.com/adohong4/driving-license/pkg/logger"
+	"github.com/adohong4/driving-license/pkg/plate"
 	"github.com/adohong4/driving-license/pkg/utils"
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
 )
 
+// Roles allowed to record lifecycle events and to read the chain of title and events of any vehicle
+var officerRoles = map[string]bool{"admin": true, "officer": true}
+
 type vehicleRegUC struct {
 	cfg            *config.Config
 	vehicleRegRepo vehicleRegistration.Repository
+	statsUC        stats.UseCase
+	anchorUC       anchor.UseCase
 	logger         logger.Logger
 }
 
 // Vehicle Registration Usecase Constructor
-func NewVehicleRegUseCase(cfg *config.Config, vehicleRegRepo vehicleRegistration.Repository, log logger.Logger) vehicleRegistration.UseCase {
-	return &vehicleRegUC{cfg: cfg, vehicleRegRepo: vehicleRegRepo, logger: log}
+func NewVehicleRegUseCase(cfg *config.Config, vehicleRegRepo vehicleRegistration.Repository, statsUC stats.UseCase, anchorUC anchor.UseCase, log logger.Logger) vehicleRegistration.UseCase {
+	return &vehicleRegUC{cfg: cfg, vehicleRegRepo: vehicleRegRepo, statsUC: statsUC, anchorUC: anchorUC, logger: log}
 }
 
 func (v *vehicleRegUC) CreateVehicleDoc(ctx context.Context, veDoc *models.VehicleRegistration) (*models.VehicleRegistration, error) {
+	if err := veDoc.PrepareCreate(); err != nil {
+		return nil, httpErrors.NewBadRequestError(errors.Wrap(err, "vehicleRegUC.CreateVehicleDoc.PrepareCreate"))
+	}
+
 	existsVehiclePlateNO, err := v.vehicleRegRepo.FindVehiclePlateNO(ctx, veDoc)
 	if existsVehiclePlateNO != nil {
 		return nil, httpErrors.NewRestErrorWithMessage(http.StatusBadRequest, httpErrors.ErrVehicleAlreadyExists, nil)
@@ -36,10 +52,6 @@ func (v *vehicleRegUC) CreateVehicleDoc(ctx context.Context, veDoc *models.Vehic
 		return nil, errors.Wrap(err, "vehicleRegUC.CreateVehicleDoc.FindVehiclePlateNO")
 	}
 
-	if err = veDoc.PrepareCreate(); err != nil {
-		return nil, httpErrors.NewBadRequestError(errors.Wrap(err, "vehicleRegUC.CreateVehicleDoc.PrepareCreate"))
-	}
-
 	user, err := utils.GetUserFromCtx(ctx)
 	if err != nil {
 		return nil, httpErrors.NewUnauthorizedError(errors.WithMessage(err, "vehicleRegUC.Create.GetUserFromCtx"))
@@ -55,6 +67,7 @@ func (v *vehicleRegUC) CreateVehicleDoc(ctx context.Context, veDoc *models.Vehic
 	if err != nil {
 		return nil, err
 	}
+	v.statsUC.MarkDirty(stats.DomainVehicles)
 
 	return n, nil
 }
@@ -68,6 +81,10 @@ func (v *vehicleRegUC) UpdateVehicleDoc(ctx context.Context, veDoc *models.Vehic
 	veDoc.ModifierId = &user.Id
 	veDoc.UpdatedAt = time.Now()
 
+	if err = veDoc.NormalizePlate(); err != nil {
+		return nil, httpErrors.NewBadRequestError(errors.Wrap(err, "vehicleRegUC.UpdateVehicleDoc.NormalizePlate"))
+	}
+
 	if err := utils.ValidateStruct(ctx, veDoc); err != nil {
 		return nil, httpErrors.NewBadRequestError(errors.WithMessage(err, "vehicleRegUC.UpdateVehicleDoc.ValidateStruct"))
 	}
@@ -76,6 +93,7 @@ func (v *vehicleRegUC) UpdateVehicleDoc(ctx context.Context, veDoc *models.Vehic
 	if err != nil {
 		return nil, err
 	}
+	v.statsUC.MarkDirty(stats.DomainVehicles)
 
 	return updatedVeReg, nil
 }
@@ -86,20 +104,47 @@ func (u *vehicleRegUC) ConfirmBlockchainStorage(ctx context.Context, dl *models.
 		return nil, httpErrors.NewUnauthorizedError(errors.WithMessage(err, "VehicleRegistrationUC.ConfirmBlockchainStorage.GetUserFromCtx"))
 	}
 
+	dl.BlockchainTxHash = strings.TrimSpace(dl.BlockchainTxHash)
+	if dl.BlockchainTxHash == "" {
+		return nil, httpErrors.NewBadRequestError(errors.New("BlockchainTxHash is required"))
+	}
+
+	// the transaction must anchor the vehicle document as it is stored
+	current, err := u.vehicleRegRepo.GetVehicleByID(ctx, dl.ID)
+	if err != nil {
+		return nil, err
+	}
+	if current.OnBlockchain {
+		if strings.EqualFold(current.BlockchainTxHash, dl.BlockchainTxHash) {
+			return current, nil
+		}
+		return nil, httpErrors.NewRestError(http.StatusConflict, "vehicle already on the blockchain", nil)
+	}
+	recordHash, err := u.anchorUC.VerifyTransaction(ctx, models.AnchorVehicle, current.ID, current, dl.BlockchainTxHash)
+	if err != nil {
+		return nil, err
+	}
+
 	dl.ModifierId = &user.Id
 	dl.OnBlockchain = true
+	dl.Version = current.Version
 	dl.UpdatedAt = time.Now()
 
-	if dl.BlockchainTxHash == "" {
-		return nil, httpErrors.NewBadRequestError(errors.New("BlockchainTxHash is required"))
+	updatedVehicle, err := u.vehicleRegRepo.ConfirmBlockchainStorage(ctx, dl, &models.BlockchainAnchor{
+		RecordType:  models.AnchorVehicle,
+		RecordID:    current.ID,
+		RecordHash:  recordHash,
+		HashVersion: models.CanonicalVersion,
+		TxHash:      dl.BlockchainTxHash,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, httpErrors.NewRestError(http.StatusConflict, "vehicle changed or was confirmed since the transaction was verified", nil)
 	}
-
-	updatedLicense, err := u.vehicleRegRepo.ConfirmBlockchainStorage(ctx, dl)
 	if err != nil {
 		return nil, err
 	}
 
-	return updatedLicense, nil
+	return updatedVehicle, nil
 }
 
 func (v *vehicleRegUC) DeleteVehicleDoc(ctx context.Context, veDoc *models.VehicleRegistration) (*models.VehicleRegistration, error) {
@@ -119,6 +164,7 @@ func (v *vehicleRegUC) DeleteVehicleDoc(ctx context.Context, veDoc *models.Vehic
 	if err != nil {
 		return nil, err
 	}
+	v.statsUC.MarkDirty(stats.DomainVehicles)
 
 	return deletedVeReg, nil
 }
@@ -136,31 +182,188 @@ func (v *vehicleRegUC) GetVehicleByID(ctx context.Context, vehicleID uuid.UUID)
 }
 
 func (v *vehicleRegUC) FindByVehiclePlateNO(ctx context.Context, vePlaNO string, query *utils.PaginationQuery) (*models.VehicleRegistrationList, error) {
-	return v.vehicleRegRepo.SearchByVehiclePlateNO(ctx, vePlaNO, query)
+	return v.vehicleRegRepo.SearchByVehiclePlateNO(ctx, plate.Compact(vePlaNO), query)
 }
 
-func (v *vehicleRegUC) GetCountByType(ctx context.Context) (models.VehicleTypeCounts, error) {
-	items, err := v.vehicleRegRepo.GetCountByType(ctx)
-	if err != nil {
-		return nil, err
+// Import target for vehicle documents, rows pass the same checks as CreateVehicleDoc
+func (v *vehicleRegUC) ImportVehicleDocs(ctx context.Context) *importer.Target {
+	seen := make(map[string]bool)
+
+	return &importer.Target{
+		Name:     "vehicles",
+		Columns:  models.VehicleRegistrationImportColumns,
+		Required: models.VehicleRegistrationImportRequired,
+		Validate: func(ctx context.Context, row importer.Row) (any, error) {
+			veDoc, err := vehicleRegistrationFromRow(row)
+			if err != nil {
+				return nil, err
+			}
+
+			if err = veDoc.NormalizePlate(); err != nil {
+				return nil, importer.NewFieldError("vehicle_no", err.Error())
+			}
+			if seen[veDoc.VehiclePlateNo] {
+				return nil, importer.NewFieldError("vehicle_no", "duplicated in file")
+			}
+			existsVehiclePlateNO, err := v.vehicleRegRepo.FindVehiclePlateNO(ctx, veDoc)
+			if err != nil {
+				return nil, errors.Wrap(err, "vehicleRegUC.ImportVehicleDocs.FindVehiclePlateNO")
+			}
+			if existsVehiclePlateNO != nil {
+				return nil, importer.NewFieldError("vehicle_no", httpErrors.ErrVehicleAlreadyExists)
+			}
+
+			if err = veDoc.PrepareCreate(); err != nil {
+				return nil, err
+			}
+
+			user, err := utils.GetUserFromCtx(ctx)
+			if err != nil {
+				return nil, errors.WithMessage(err, "vehicleRegUC.ImportVehicleDocs.GetUserFromCtx")
+			}
+			veDoc.CreatorId = user.Id
+
+			if err = utils.ValidateStruct(ctx, veDoc); err != nil {
+				return nil, err
+			}
+
+			seen[veDoc.VehiclePlateNo] = true
+			return veDoc, nil
+		},
+		Commit: func(ctx context.Context, records []any) error {
+			veDocs := make([]*models.VehicleRegistration, 0, len(records))
+			for _, r := range records {
+				veDocs = append(veDocs, r.(*models.VehicleRegistration))
+			}
+			if err := v.vehicleRegRepo.CreateVehicleDocs(ctx, veDocs); err != nil {
+				return err
+			}
+			v.statsUC.MarkDirty(stats.DomainVehicles)
+			return nil
+		},
 	}
-	return models.VehicleTypeCounts(items), nil
 }
 
-func (v *vehicleRegUC) GetTopBrands(ctx context.Context) (models.BrandCounts, error) {
-	items, err := v.vehicleRegRepo.GetTopBrands(ctx)
-	if err != nil {
-		return nil, err
+func vehicleRegistrationFromRow(row importer.Row) (*models.VehicleRegistration, error) {
+	for _, key := range models.VehicleRegistrationImportRequired {
+		if row.Get(key) == "" {
+			return nil, importer.NewFieldError(key, "is required")
+		}
+	}
+
+	veDoc := &models.VehicleRegistration{
+		VehiclePlateNo: row.Get("vehicle_no"),
+		OwnerName:      row.Get("owner_name"),
+		Brand:          row.Get("brand"),
+		TypeVehicle:    row.Get("type_vehicle"),
+		ColorVehicle:   row.Get("color_vehicle"),
+		ColorPlate:     row.Get("color_plate"),
+		ChassisNo:      row.Get("chassis_no"),
+		EngineNo:       row.Get("engine_no"),
+		Issuer:         row.Get("issuer"),
+		Status:         row.Get("status"),
+	}
+
+	if v := row.Get("owner_id"); v != "" {
+		ownerID, err := uuid.Parse(v)
+		if err != nil {
+			return nil, importer.NewFieldError("owner_id", "invalid UUID")
+		}
+		veDoc.OwnerID = &ownerID
+	}
+	if v := row.Get("seats"); v != "" {
+		seats, err := strconv.Atoi(v)
+		if err != nil || seats < 0 {
+			return nil, importer.NewFieldError("seats", "must be a positive number")
+		}
+		veDoc.Seats = &seats
+	}
+	if v := row.Get("issue_date"); v != "" {
+		issueDate, err := importer.ParseDate(v)
+		if err != nil {
+			return nil, importer.NewFieldError("issue_date", err.Error())
+		}
+		veDoc.IssueDate = issueDate
 	}
-	return models.BrandCounts(items), nil
+	for key, dst := range map[string]**string{
+		"registration_date": &veDoc.RegistrationDate,
+		"expiry_date":       &veDoc.ExpiryDate,
+	} {
+		if v := row.Get(key); v != "" {
+			date, err := importer.ParseDate(v)
+			if err != nil {
+				return nil, importer.NewFieldError(key, err.Error())
+			}
+			*dst = &date
+		}
+	}
+	if v := row.Get("registration_code"); v != "" {
+		veDoc.RegistrationCode = &v
+	}
+	if v := row.Get("registration_place"); v != "" {
+		veDoc.RegistrationPlace = &v
+	}
+	return veDoc, nil
 }
 
-func (v *vehicleRegUC) GetCountByStatus(ctx context.Context) (models.StatusCounts, error) {
-	items, err := v.vehicleRegRepo.GetRegistrationStatusStats(ctx)
-	if err != nil {
-		return nil, err
+// Export source for the vehicle list, filtered like FindByVehiclePlateNO
+func (v *vehicleRegUC) ExportVehicleDocs(ctx context.Context, eq *utils.ExportQuery) *export.Source {
+	return &export.Source{
+		Name:    "vehicles",
+		Columns: models.VehicleRegistrationExportColumns,
+		Count: func(ctx context.Context) (int, error) {
+			return v.vehicleRegRepo.CountVehicleDocs(ctx, plate.Compact(eq.Search), eq.ListQuery())
+		},
+		Rows: func(ctx context.Context, fn func(row []string) error) error {
+			return v.vehicleRegRepo.StreamVehicleDocs(ctx, plate.Compact(eq.Search), eq.ListQuery(), func(n *models.VehicleRegistration) error {
+				return fn(n.ExportRow())
+			})
+		},
 	}
-	return *items, nil
+}
+
+func (v *vehicleRegUC) GetCountByType(ctx context.Context) (models.VehicleTypeCounts, time.Time, error) {
+	return stats.CachedView(ctx, v.statsUC, stats.DomainVehicles, "type", func(ctx context.Context) (models.VehicleTypeCounts, error) {
+		items, err := v.vehicleRegRepo.GetCountByType(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return models.VehicleTypeCounts(items), nil
+	})
+}
+
+func (v *vehicleRegUC) GetTopBrands(ctx context.Context) (models.BrandCounts, time.Time, error) {
+	return stats.CachedView(ctx, v.statsUC, stats.DomainVehicles, "brand", func(ctx context.Context) (models.BrandCounts, error) {
+		items, err := v.vehicleRegRepo.GetTopBrands(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return models.BrandCounts(items), nil
+	})
+}
+
+func (v *vehicleRegUC) GetCountByStatus(ctx context.Context) (models.StatusCounts, time.Time, error) {
+	return stats.CachedView(ctx, v.statsUC, stats.DomainVehicles, "status", func(ctx context.Context) (models.StatusCounts, error) {
+		items, err := v.vehicleRegRepo.GetRegistrationStatusStats(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return *items, nil
+	})
+}
+
+func (v *vehicleRegUC) GetRegistrationSeries(ctx context.Context, sq *utils.StatsQuery) (*models.TimeSeries, time.Time, error) {
+	if sq.AgencyID != nil || sq.LicenseType != "" || sq.ViolationType != "" {
+		return nil, time.Time{}, httpErrors.NewBadRequestError("only the city filter is supported for vehicle registrations")
+	}
+
+	return stats.CachedLive(ctx, v.statsUC, stats.DomainVehicles, "series:"+sq.CacheKey(), func(ctx context.Context) (*models.TimeSeries, error) {
+		points, err := v.vehicleRegRepo.GetRegistrationSeries(ctx, sq)
+		if err != nil {
+			return nil, err
+		}
+		return utils.BuildTimeSeries("registrations", sq, points, false), nil
+	})
 }
 
 func (v *vehicleRegUC) GetMyVehicles(ctx context.Context, pq *utils.PaginationQuery) (*models.VehicleRegistrationList, error) {
@@ -188,6 +391,136 @@ func (v *vehicleRegUC) GetMyVehicleByID(ctx context.Context, vehicleID uuid.UUID
 	return vehicle, nil
 }
 
+// Every owner of the vehicle, readable by officers and by the current owner
+func (v *vehicleRegUC) GetChainOfTitle(ctx context.Context, vehicleID uuid.UUID) (*models.ChainOfTitle, error) {
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return nil, httpErrors.NewUnauthorizedError(err)
+	}
+
+	vehicle, err := v.vehicleRegRepo.GetVehicleByID(ctx, vehicleID)
+	if err != nil {
+		return nil, err
+	}
+	owners, err := v.vehicleRegRepo.GetOwnerships(ctx, vehicleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isOfficer(user) && !currentOwner(owners, user) {
+		return nil, httpErrors.NewRestError(http.StatusNotFound, "vehicle not found or not owned by you", nil)
+	}
+	return &models.ChainOfTitle{Vehicle: vehicle, Owners: owners}, nil
+}
+
+// Deregister, report stolen, recover, seize or release a vehicle
+func (v *vehicleRegUC) RecordVehicleEvent(ctx context.Context, vehicleID uuid.UUID, event string, req *models.VehicleEventRequest) (*models.VehicleRegistration, error) {
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return nil, httpErrors.NewUnauthorizedError(errors.WithMessage(err, "vehicleRegUC.RecordVehicleEvent.GetUserFromCtx"))
+	}
+	if !isOfficer(user) {
+		return nil, httpErrors.NewForbiddenError(httpErrors.PermissionDenied)
+	}
+
+	if err = utils.ValidateStruct(ctx, req); err != nil {
+		return nil, httpErrors.NewBadRequestError(errors.WithMessage(err, "vehicleRegUC.RecordVehicleEvent.ValidateStruct"))
+	}
+	if event != models.VehicleDeregistered {
+		req.Cause = ""
+	} else if req.Cause == "" {
+		return nil, httpErrors.NewBadRequestError("a cause, scrapped or exported, is required to deregister a vehicle")
+	}
+
+	vehicle, err := v.vehicleRegRepo.GetVehicleByID(ctx, vehicleID)
+	if err != nil {
+		return nil, err
+	}
+	if msg := vehicleEventConflict(vehicle, event); msg != "" {
+		return nil, httpErrors.NewRestError(http.StatusConflict, msg, nil)
+	}
+
+	e := req.Event(vehicle, event, user.Id)
+	if e.EventDate.After(time.Now()) {
+		return nil, httpErrors.NewBadRequestError("the date can not be in the future")
+	}
+	if e.AgencyName, err = v.vehicleRegRepo.GetAgencyName(ctx, req.AgencyID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, httpErrors.NewBadRequestError("agency not found")
+		}
+		return nil, err
+	}
+
+	updated, err := v.vehicleRegRepo.RecordVehicleEvent(ctx, e)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, httpErrors.NewRestError(http.StatusConflict, "the vehicle changed meanwhile, reload it and retry", nil)
+		}
+		return nil, err
+	}
+	if event == models.VehicleDeregistered {
+		v.statsUC.MarkDirty(stats.DomainVehicles)
+	}
+	return updated, nil
+}
+
+func (v *vehicleRegUC) GetVehicleEvents(ctx context.Context, vehicleID uuid.UUID) (*models.VehicleEventList, error) {
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return nil, httpErrors.NewUnauthorizedError(err)
+	}
+	if !isOfficer(user) {
+		return nil, httpErrors.NewForbiddenError(httpErrors.PermissionDenied)
+	}
+
+	events, err := v.vehicleRegRepo.GetVehicleEvents(ctx, vehicleID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.VehicleEventList{Events: events}, nil
+}
+
+// Why the event does not apply to the vehicle in its current state, empty when it does
+func vehicleEventConflict(v *models.VehicleRegistration, event string) string {
+	switch event {
+	case models.VehicleStolen:
+		if v.Stolen {
+			return "the vehicle is already reported stolen"
+		}
+	case models.VehicleRecovered:
+		if !v.Stolen {
+			return "the vehicle is not reported stolen"
+		}
+	case models.VehicleSeized:
+		if v.Seized {
+			return "the vehicle is already seized"
+		}
+	case models.VehicleReleased:
+		if !v.Seized {
+			return "the vehicle is not seized"
+		}
+	case models.VehicleDeregistered:
+		if v.Stolen || v.Seized {
+			return "a stolen or seized vehicle must be recovered or released before it is deregistered"
+		}
+	}
+	return ""
+}
+
+func isOfficer(user *models.User) bool {
+	return user.Role != nil && officerRoles[*user.Role]
+}
+
+func currentOwner(owners []*models.VehicleOwnership, user *models.User) bool {
+	for _, o := range owners {
+		if o.ToDate != nil {
+			continue
+		}
+		return (o.OwnerID != nil && *o.OwnerID == user.Id) || (user.IdentityNo != "" && o.IdentityNo == user.IdentityNo)
+	}
+	return false
+}
+
 func (v *vehicleRegUC) GetInspections(ctx context.Context, pq *utils.PaginationQuery) (*models.VehicleRegistrationList, error) {
 	return v.vehicleRegRepo.GetInspections(ctx, pq)
 }
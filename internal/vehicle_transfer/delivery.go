@@ -0,0 +1,14 @@
+package vehicleTransfer
+
+import "github.com/labstack/echo/v4"
+
+type Handlers interface {
+	Initiate() echo.HandlerFunc
+	Accept() echo.HandlerFunc
+	Approve() echo.HandlerFunc
+	Reject() echo.HandlerFunc
+	Cancel() echo.HandlerFunc
+	GetByID() echo.HandlerFunc
+	GetTransfers() echo.HandlerFunc
+	GetMyTransfers() echo.HandlerFunc
+}
@@ -0,0 +1,330 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/adohong4/driving-license/config"
+	"github.com/adohong4/driving-license/internal/models"
+	vehicleTransfer "github.com/adohong4/driving-license/internal/vehicle_transfer"
+	"github.com/adohong4/driving-license/pkg/httpErrors"
+	"github.com/adohong4/driving-license/pkg/logger"
+	"github.com/adohong4/driving-license/pkg/utils"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type vehicleTransferHandlers struct {
+	cfg        *config.Config
+	transferUC vehicleTransfer.UseCase
+	logger     logger.Logger
+}
+
+func NewVehicleTransferHandlers(cfg *config.Config, transferUC vehicleTransfer.UseCase, logger logger.Logger) vehicleTransfer.Handlers {
+	return &vehicleTransferHandlers{cfg: cfg, transferUC: transferUC, logger: logger}
+}
+
+// Initiate godoc
+// @Summary      Initiate a vehicle ownership transfer
+// @Description  The owner of a vehicle offers it to the citizen with the given identity number, who is notified to accept. A vehicle has at most one open transfer
+// @Tags         vehicle-transfer
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.InitiateTransferRequest  true  "Vehicle and buyer"
+// @Success      201      {object}  models.VehicleTransfer
+// @Failure      400      {object}  httpErrors.Problem
+// @Failure      401      {object}  httpErrors.Problem
+// @Failure      404      {object}  httpErrors.Problem
+// @Failure      409      {object}  httpErrors.Problem
+// @Failure      500      {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /vehicle/transfers [post]
+func (h *vehicleTransferHandlers) Initiate() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		req := &models.InitiateTransferRequest{}
+		if err := c.Bind(req); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		t, err := h.transferUC.InitiateTransfer(ctx, req)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusCreated, t)
+	}
+}
+
+// Accept godoc
+// @Summary      Accept a vehicle ownership transfer
+// @Description  The buyer accepts with the identity number the seller named, the transfer then awaits an officer's approval
+// @Tags         vehicle-transfer
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                        true  "Transfer ID (UUID)"
+// @Param        request  body      models.AcceptTransferRequest  true  "Buyer identity"
+// @Success      200      {object}  models.VehicleTransfer
+// @Failure      400      {object}  httpErrors.Problem
+// @Failure      401      {object}  httpErrors.Problem
+// @Failure      403      {object}  httpErrors.Problem
+// @Failure      404      {object}  httpErrors.Problem
+// @Failure      409      {object}  httpErrors.Problem
+// @Failure      500      {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /vehicle/transfers/{id}/accept [post]
+func (h *vehicleTransferHandlers) Accept() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		transferID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		req := &models.AcceptTransferRequest{}
+		if err = c.Bind(req); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		t, err := h.transferUC.AcceptTransfer(ctx, transferID, req)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, t)
+	}
+}
+
+// Approve godoc
+// @Summary      Approve a vehicle ownership transfer
+// @Description  Officer only. The vehicle must have no unpaid violations and no expired inspection. The owner is changed atomically with the approval and both parties are notified, a new plate is issued when given
+// @Tags         vehicle-transfer
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                         true   "Transfer ID (UUID)"
+// @Param        request  body      models.ApproveTransferRequest  false  "New plate number"
+// @Success      200      {object}  models.VehicleTransfer
+// @Failure      400      {object}  httpErrors.Problem
+// @Failure      401      {object}  httpErrors.Problem
+// @Failure      403      {object}  httpErrors.Problem
+// @Failure      404      {object}  httpErrors.Problem
+// @Failure      409      {object}  httpErrors.Problem
+// @Failure      500      {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /vehicle/transfers/{id}/approve [post]
+func (h *vehicleTransferHandlers) Approve() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		transferID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		req := &models.ApproveTransferRequest{}
+		if err = c.Bind(req); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		t, err := h.transferUC.ApproveTransfer(ctx, transferID, req)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, t)
+	}
+}
+
+// Reject godoc
+// @Summary      Reject a vehicle ownership transfer
+// @Description  Officer only. Rejects an open transfer with a reason, both parties are notified
+// @Tags         vehicle-transfer
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                        true  "Transfer ID (UUID)"
+// @Param        request  body      models.TransferReasonRequest  true  "Reason"
+// @Success      200      {object}  models.VehicleTransfer
+// @Failure      400      {object}  httpErrors.Problem
+// @Failure      401      {object}  httpErrors.Problem
+// @Failure      403      {object}  httpErrors.Problem
+// @Failure      404      {object}  httpErrors.Problem
+// @Failure      409      {object}  httpErrors.Problem
+// @Failure      500      {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /vehicle/transfers/{id}/reject [post]
+func (h *vehicleTransferHandlers) Reject() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		transferID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		req := &models.TransferReasonRequest{}
+		if err = c.Bind(req); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		t, err := h.transferUC.RejectTransfer(ctx, transferID, req)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, t)
+	}
+}
+
+// Cancel godoc
+// @Summary      Cancel a vehicle ownership transfer
+// @Description  The seller or the buyer withdraws from an open transfer, the other party is notified
+// @Tags         vehicle-transfer
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                        true   "Transfer ID (UUID)"
+// @Param        request  body      models.TransferReasonRequest  false  "Reason"
+// @Success      200      {object}  models.VehicleTransfer
+// @Failure      400      {object}  httpErrors.Problem
+// @Failure      401      {object}  httpErrors.Problem
+// @Failure      404      {object}  httpErrors.Problem
+// @Failure      409      {object}  httpErrors.Problem
+// @Failure      500      {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /vehicle/transfers/{id}/cancel [post]
+func (h *vehicleTransferHandlers) Cancel() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		transferID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		req := &models.TransferReasonRequest{}
+		if err = c.Bind(req); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		t, err := h.transferUC.CancelTransfer(ctx, transferID, req)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, t)
+	}
+}
+
+// GetByID godoc
+// @Summary      Get a vehicle ownership transfer
+// @Description  Parties read their own transfers, officers read any
+// @Tags         vehicle-transfer
+// @Produce      json
+// @Param        id   path      string  true  "Transfer ID (UUID)"
+// @Success      200  {object}  models.VehicleTransfer
+// @Failure      400  {object}  httpErrors.Problem
+// @Failure      401  {object}  httpErrors.Problem
+// @Failure      404  {object}  httpErrors.Problem
+// @Failure      500  {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /vehicle/transfers/{id} [get]
+func (h *vehicleTransferHandlers) GetByID() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		transferID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		t, err := h.transferUC.GetTransferByID(ctx, transferID)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, t)
+	}
+}
+
+// GetTransfers godoc
+// @Summary      List vehicle ownership transfers
+// @Description  Officer only. Filterable by vehicle_id, vehicle_no, seller_identity_no, buyer_identity_no, status, officer_id and dates
+// @Tags         vehicle-transfer
+// @Produce      json
+// @Param        page        query     int     false  "Page number (default: 1)"
+// @Param        size        query     int     false  "Page size (default: 10)"
+// @Param        sort        query     string  false  "Sort fields, comma separated, - prefix for descending, e.g. -created_at"
+// @Param        status      query     string  false  "initiated, accepted, approved, rejected or cancelled"
+// @Param        cursor      query     string  false  "Cursor of the next page, empty for the first one, switches to cursor pagination"
+// @Param        with_total  query     bool    false  "Return an approximate total_count on cursor pages"
+// @Success      200         {object}  models.VehicleTransferList
+// @Failure      400         {object}  httpErrors.Problem
+// @Failure      401         {object}  httpErrors.Problem
+// @Failure      403         {object}  httpErrors.Problem
+// @Failure      500         {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /vehicle/transfers [get]
+func (h *vehicleTransferHandlers) GetTransfers() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		pq, err := utils.GetPaginationFromCtx(c)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		list, err := h.transferUC.GetTransfers(ctx, pq)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, list)
+	}
+}
+
+// GetMyTransfers godoc
+// @Summary      List my vehicle ownership transfers
+// @Description  Transfers the current user sells or buys
+// @Tags         User
+// @Produce      json
+// @Param        page        query     int     false  "Page number (default: 1)"
+// @Param        size        query     int     false  "Page size (default: 10)"
+// @Param        sort        query     string  false  "Sort fields, comma separated, - prefix for descending, e.g. -created_at"
+// @Param        cursor      query     string  false  "Cursor of the next page, empty for the first one, switches to cursor pagination"
+// @Param        with_total  query     bool    false  "Return an approximate total_count on cursor pages"
+// @Success      200         {object}  models.VehicleTransferList
+// @Failure      400         {object}  httpErrors.Problem
+// @Failure      401         {object}  httpErrors.Problem
+// @Failure      500         {object}  httpErrors.Problem
+// @Security     JWT
+// @Router       /vehicle/transfers/me [get]
+func (h *vehicleTransferHandlers) GetMyTransfers() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		pq, err := utils.GetPaginationFromCtx(c)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		list, err := h.transferUC.GetMyTransfers(ctx, pq)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, list)
+	}
+}
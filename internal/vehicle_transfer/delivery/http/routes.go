@@ -0,0 +1,22 @@
+package http
+
+import (
+	"github.com/adohong4/driving-license/config"
+	"github.com/adohong4/driving-license/internal/auth"
+	"github.com/adohong4/driving-license/internal/middleware"
+	vehicleTransfer "github.com/adohong4/driving-license/internal/vehicle_transfer"
+	"github.com/labstack/echo/v4"
+)
+
+var officerRoles = []string{"admin", "officer"}
+
+func MapVehicleTransferRoutes(transferGroup *echo.Group, h vehicleTransfer.Handlers, mw *middleware.MiddlewareManager, cfg *config.Config, authUC auth.UseCase) {
+	transferGroup.POST("", h.Initiate(), mw.AuthJWTMiddleware(authUC, cfg))
+	transferGroup.GET("", h.GetTransfers(), mw.AuthJWTMiddleware(authUC, cfg), mw.RoleBasedAuthMiddleware(officerRoles))
+	transferGroup.GET("/me", h.GetMyTransfers(), mw.AuthJWTMiddleware(authUC, cfg))
+	transferGroup.GET("/:id", h.GetByID(), mw.AuthJWTMiddleware(authUC, cfg))
+	transferGroup.POST("/:id/accept", h.Accept(), mw.AuthJWTMiddleware(authUC, cfg))
+	transferGroup.POST("/:id/approve", h.Approve(), mw.AuthJWTMiddleware(authUC, cfg), mw.RoleBasedAuthMiddleware(officerRoles))
+	transferGroup.POST("/:id/reject", h.Reject(), mw.AuthJWTMiddleware(authUC, cfg), mw.RoleBasedAuthMiddleware(officerRoles))
+	transferGroup.POST("/:id/cancel", h.Cancel(), mw.AuthJWTMiddleware(authUC, cfg))
+}
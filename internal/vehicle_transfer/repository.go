@@ -0,0 +1,25 @@
+package vehicleTransfer
+
+import (
+	"context"
+
+	"github.com/adohong4/driving-license/internal/models"
+	"github.com/adohong4/driving-license/pkg/utils"
+	"github.com/google/uuid"
+)
+
+type Repository interface {
+	CreateTransfer(ctx context.Context, t *models.VehicleTransfer) (*models.VehicleTransfer, error)
+	GetTransferByID(ctx context.Context, transferID uuid.UUID) (*models.VehicleTransfer, error)
+	GetOpenTransferByVehicle(ctx context.Context, vehicleID uuid.UUID) (*models.VehicleTransfer, error)
+	AcceptTransfer(ctx context.Context, t *models.VehicleTransfer) (*models.VehicleTransfer, error)
+	CloseTransfer(ctx context.Context, t *models.VehicleTransfer) (*models.VehicleTransfer, error)
+	ApproveTransfer(ctx context.Context, t *models.VehicleTransfer) (*models.VehicleTransfer, error)
+	GetTransfers(ctx context.Context, pq *utils.PaginationQuery) (*models.VehicleTransferList, error)
+	GetTransfersByIdentity(ctx context.Context, identityNo string, pq *utils.PaginationQuery) (*models.VehicleTransferList, error)
+
+	GetOwnedVehicle(ctx context.Context, vehicleID, userID uuid.UUID, identityNo string) (*models.VehicleRegistration, error)
+	GetOwnerIDByIdentity(ctx context.Context, identityNo string) (*uuid.UUID, error)
+	GetTransferBlockers(ctx context.Context, vehicleID uuid.UUID) (*models.TransferBlockers, error)
+	PlateTaken(ctx context.Context, plateNo string, vehicleID uuid.UUID) (bool, error)
+}
@@ -0,0 +1,220 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	anchorRepository "github.com/adohong4/driving-license/internal/anchor/repository"
+	"github.com/adohong4/driving-license/internal/models"
+	vehicleTransfer "github.com/adohong4/driving-license/internal/vehicle_transfer"
+	"github.com/adohong4/driving-license/pkg/utils"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+type vehicleTransferRepo struct {
+	db *sqlx.DB
+}
+
+func NewVehicleTransferRepo(db *sqlx.DB) vehicleTransfer.Repository {
+	return &vehicleTransferRepo{db: db}
+}
+
+func (r *vehicleTransferRepo) CreateTransfer(ctx context.Context, t *models.VehicleTransfer) (*models.VehicleTransfer, error) {
+	created := &models.VehicleTransfer{}
+	if err := r.db.QueryRowxContext(ctx, createTransferQuery,
+		t.Id, t.VehicleID, t.VehiclePlateNo, t.SellerID, t.SellerOwnerID, t.SellerName, t.SellerIdentityNo,
+		t.BuyerIdentityNo, t.Status, t.Note, t.CreatedAt, t.UpdatedAt,
+	).StructScan(created); err != nil {
+		return nil, errors.Wrap(err, "vehicleTransferRepo.CreateTransfer.StructScan")
+	}
+	return created, nil
+}
+
+func (r *vehicleTransferRepo) GetTransferByID(ctx context.Context, transferID uuid.UUID) (*models.VehicleTransfer, error) {
+	t := &models.VehicleTransfer{}
+	if err := r.db.GetContext(ctx, t, getTransferByID, transferID, utils.JurisdictionFromCtx(ctx)); err != nil {
+		return nil, errors.Wrap(err, "vehicleTransferRepo.GetTransferByID.GetContext")
+	}
+	return t, nil
+}
+
+// Open transfer of the vehicle, nil when there is none
+func (r *vehicleTransferRepo) GetOpenTransferByVehicle(ctx context.Context, vehicleID uuid.UUID) (*models.VehicleTransfer, error) {
+	t := &models.VehicleTransfer{}
+	err := r.db.GetContext(ctx, t, getOpenTransferByVehicle, vehicleID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "vehicleTransferRepo.GetOpenTransferByVehicle.GetContext")
+	}
+	return t, nil
+}
+
+func (r *vehicleTransferRepo) AcceptTransfer(ctx context.Context, t *models.VehicleTransfer) (*models.VehicleTransfer, error) {
+	accepted := &models.VehicleTransfer{}
+	if err := r.db.QueryRowxContext(ctx, acceptTransferQuery,
+		t.BuyerID, t.BuyerOwnerID, t.BuyerName, t.Id,
+	).StructScan(accepted); err != nil {
+		return nil, errors.Wrap(err, "vehicleTransferRepo.AcceptTransfer.StructScan")
+	}
+	return accepted, nil
+}
+
+func (r *vehicleTransferRepo) CloseTransfer(ctx context.Context, t *models.VehicleTransfer) (*models.VehicleTransfer, error) {
+	closed := &models.VehicleTransfer{}
+	if err := r.db.QueryRowxContext(ctx, closeTransferQuery,
+		t.Status, t.Reason, t.OfficerID, t.Id, utils.JurisdictionFromCtx(ctx),
+	).StructScan(closed); err != nil {
+		return nil, errors.Wrap(err, "vehicleTransferRepo.CloseTransfer.StructScan")
+	}
+	return closed, nil
+}
+
+// Approve the transfer and hand the vehicle to the buyer in one transaction.
+// sql.ErrNoRows when the transfer no longer awaits approval or the vehicle changed owner meanwhile,
+// httpErrors.OutOfJurisdiction when the vehicle is outside the jurisdiction of the officer.
+func (r *vehicleTransferRepo) ApproveTransfer(ctx context.Context, t *models.VehicleTransfer) (*models.VehicleTransfer, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "vehicleTransferRepo.ApproveTransfer.BeginTxx")
+	}
+	defer tx.Rollback()
+
+	approved := &models.VehicleTransfer{}
+	if err = tx.QueryRowxContext(ctx, approveTransferQuery, t.NewPlateNo, t.OfficerID, t.Id, utils.JurisdictionFromCtx(ctx)).StructScan(approved); err != nil {
+		return nil, errors.Wrap(err, "vehicleTransferRepo.ApproveTransfer.StructScan")
+	}
+
+	var province string
+	if err = tx.GetContext(ctx, &province, lockVehicleQuery, approved.VehicleID); err != nil {
+		return nil, errors.Wrap(err, "vehicleTransferRepo.ApproveTransfer.lockVehicle")
+	}
+	if err = utils.CheckJurisdiction(ctx, tx, province, nil); err != nil {
+		return nil, errors.Wrap(err, "vehicleTransferRepo.ApproveTransfer")
+	}
+
+	// sql.ErrNoRows when the vehicle changed owner or got blocked since the use case checked it
+	var onBlockchain bool
+	if err = tx.GetContext(ctx, &onBlockchain, transferOwnerQuery,
+		approved.BuyerOwnerID, approved.BuyerName, approved.NewPlateNo, approved.OfficerID, approved.VehicleID, approved.SellerOwnerID,
+		utils.JurisdictionFromCtx(ctx),
+	); err != nil {
+		return nil, errors.Wrap(err, "vehicleTransferRepo.ApproveTransfer.TransferOwner")
+	}
+	if onBlockchain {
+		if _, err = anchorRepository.QueueReanchorTx(ctx, tx, models.AnchorVehicle, approved.VehicleID); err != nil {
+			return nil, errors.Wrap(err, "vehicleTransferRepo.ApproveTransfer")
+		}
+	}
+
+	// the buyer owns the vehicle from the approval, under the new plate when one was issued
+	plateNo := approved.VehiclePlateNo
+	if approved.NewPlateNo != nil {
+		plateNo = *approved.NewPlateNo
+	}
+	if _, err = tx.ExecContext(ctx, closeOwnershipQuery, approved.DecidedAt, approved.VehicleID); err != nil {
+		return nil, errors.Wrap(err, "vehicleTransferRepo.ApproveTransfer.closeOwnership")
+	}
+	if _, err = tx.ExecContext(ctx, openTransferOwnershipQuery,
+		uuid.New(), approved.VehicleID, approved.BuyerOwnerID, approved.BuyerName, approved.BuyerIdentityNo, plateNo,
+		approved.DecidedAt, models.OwnershipTransfer, approved.Id, approved.OfficerID,
+	); err != nil {
+		return nil, errors.Wrap(err, "vehicleTransferRepo.ApproveTransfer.openOwnership")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "vehicleTransferRepo.ApproveTransfer.Commit")
+	}
+	return approved, nil
+}
+
+func (r *vehicleTransferRepo) GetTransfers(ctx context.Context, pq *utils.PaginationQuery) (*models.VehicleTransferList, error) {
+	return r.list(ctx, pq, getTotalTransfers, getTransfers, utils.JurisdictionFromCtx(ctx))
+}
+
+// Transfers the citizen sells or buys
+func (r *vehicleTransferRepo) GetTransfersByIdentity(ctx context.Context, identityNo string, pq *utils.PaginationQuery) (*models.VehicleTransferList, error) {
+	return r.list(ctx, pq, getTotalTransfersByIdentity, getTransfersByIdentity, identityNo)
+}
+
+func (r *vehicleTransferRepo) list(ctx context.Context, pq *utils.PaginationQuery, countQuery, listQuery string, base ...interface{}) (*models.VehicleTransferList, error) {
+	lc, err := pq.ListClause(transferListSpec, len(base))
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := lc.Total(ctx, r.db, countQuery, listQuery, base...)
+	if err != nil {
+		return nil, errors.Wrap(err, "vehicleTransferRepo.list.total")
+	}
+
+	list := &models.VehicleTransferList{
+		TotalCount: total,
+		TotalPages: utils.GetTotalPage(total, pq.GetSize()),
+		Page:       pq.GetPage(),
+		Size:       pq.GetSize(),
+		HasMore:    utils.GetHasMore(pq.GetPage(), total, pq.GetSize()),
+		Transfers:  []*models.VehicleTransfer{},
+	}
+
+	if lc.Empty(total) {
+		return list, nil
+	}
+
+	var items []*models.VehicleTransfer
+	if err := r.db.SelectContext(ctx, &items, lc.Page(listQuery), lc.PageArgs(pq, base...)...); err != nil {
+		return nil, errors.Wrap(err, "vehicleTransferRepo.list.Select")
+	}
+
+	if list.Transfers, list.NextCursor, err = utils.NextCursor(lc, items); err != nil {
+		return nil, errors.Wrap(err, "vehicleTransferRepo.list.NextCursor")
+	}
+	list.HasMore = lc.HasMore(pq, total, list.NextCursor)
+	return list, nil
+}
+
+// Active vehicle owned by the user, nil when it is not
+func (r *vehicleTransferRepo) GetOwnedVehicle(ctx context.Context, vehicleID, userID uuid.UUID, identityNo string) (*models.VehicleRegistration, error) {
+	v := &models.VehicleRegistration{}
+	err := r.db.GetContext(ctx, v, getOwnedVehicle, vehicleID, userID, identityNo)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "vehicleTransferRepo.GetOwnedVehicle.GetContext")
+	}
+	return v, nil
+}
+
+// Owner id of the citizen's latest active license, nil when the citizen holds none
+func (r *vehicleTransferRepo) GetOwnerIDByIdentity(ctx context.Context, identityNo string) (*uuid.UUID, error) {
+	var ownerID uuid.UUID
+	err := r.db.GetContext(ctx, &ownerID, getOwnerIDByIdentity, identityNo)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "vehicleTransferRepo.GetOwnerIDByIdentity.GetContext")
+	}
+	return &ownerID, nil
+}
+
+func (r *vehicleTransferRepo) GetTransferBlockers(ctx context.Context, vehicleID uuid.UUID) (*models.TransferBlockers, error) {
+	b := &models.TransferBlockers{}
+	if err := r.db.GetContext(ctx, b, getTransferBlockers, vehicleID); err != nil {
+		return nil, errors.Wrap(err, "vehicleTransferRepo.GetTransferBlockers.GetContext")
+	}
+	return b, nil
+}
+
+// Whether another active vehicle holds the plate
+func (r *vehicleTransferRepo) PlateTaken(ctx context.Context, plateNo string, vehicleID uuid.UUID) (bool, error) {
+	var taken bool
+	if err := r.db.GetContext(ctx, &taken, plateTaken, plateNo, vehicleID); err != nil {
+		return false, errors.Wrap(err, "vehicleTransferRepo.PlateTaken.GetContext")
+	}
+	return taken, nil
+}
@@ -0,0 +1,195 @@
+package repository
+
+import "github.com/adohong4/driving-license/pkg/utils"
+
+// Filters and sorts accepted by the transfer lists, filter, sort and cursor clauses are appended to the queries below
+var transferListSpec = &utils.ListSpec{
+	Fields: map[string]utils.ListField{
+		"vehicle_id":         {Column: "vt.vehicle_id", Type: utils.FilterUUID},
+		"vehicle_no":         {Column: "vt.vehicle_no", Type: utils.FilterText, Sortable: true, Keyset: true},
+		"seller_identity_no": {Column: "vt.seller_identity_no", Type: utils.FilterExact},
+		"buyer_identity_no":  {Column: "vt.buyer_identity_no", Type: utils.FilterExact},
+		"status":             {Column: "vt.status", Type: utils.FilterExact, Sortable: true, Keyset: true},
+		"officer_id":         {Column: "vt.officer_id", Type: utils.FilterUUID},
+		"decided_at":         {Column: "vt.decided_at", Type: utils.FilterDate, Sortable: true},
+		"created_at":         {Column: "vt.created_at", Type: utils.FilterDate, Sortable: true, Keyset: true},
+		"updated_at":         {Column: "vt.updated_at", Type: utils.FilterDate, Sortable: true, Keyset: true},
+	},
+	DefaultSort: "-updated_at,-created_at",
+	IDColumn:    "vt.id",
+}
+
+const (
+	createTransferQuery = `
+    INSERT INTO vehicle_transfers (
+        id, vehicle_id, vehicle_no, seller_id, seller_owner_id, seller_name, seller_identity_no,
+        buyer_identity_no, status, note, created_at, updated_at
+    ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+    RETURNING *
+    `
+
+	// transfers are scoped to the jurisdiction of their vehicle (see f_vehicle_province in migrations)
+	getTransferByID = `
+    SELECT vt.* FROM vehicle_transfers vt
+    WHERE vt.id = $1 AND f_in_jurisdiction($2, (SELECT f_vehicle_province(v.owner_id, v.registration_place) FROM vehicle_registration v WHERE v.id = vt.vehicle_id))
+    `
+
+	getOpenTransferByVehicle = `
+    SELECT * FROM vehicle_transfers
+    WHERE vehicle_id = $1 AND status IN ('initiated', 'accepted')
+    `
+
+	acceptTransferQuery = `
+    UPDATE vehicle_transfers
+    SET status = 'accepted',
+        buyer_id = $1,
+        buyer_owner_id = $2,
+        buyer_name = $3,
+        accepted_at = now(),
+        updated_at = now()
+    WHERE id = $4 AND status = 'initiated'
+    RETURNING *
+    `
+
+	// rejection by an officer or cancellation by a party, only open transfers are closed
+	closeTransferQuery = `
+    UPDATE vehicle_transfers
+    SET status = $1,
+        reason = $2,
+        officer_id = $3,
+        decided_at = now(),
+        updated_at = now()
+    WHERE id = $4 AND status IN ('initiated', 'accepted')
+      AND f_in_jurisdiction($5, (SELECT f_vehicle_province(v.owner_id, v.registration_place) FROM vehicle_registration v WHERE v.id = vehicle_transfers.vehicle_id))
+    RETURNING *
+    `
+
+	approveTransferQuery = `
+    UPDATE vehicle_transfers
+    SET status = 'approved',
+        new_vehicle_no = $1,
+        officer_id = $2,
+        decided_at = now(),
+        updated_at = now()
+    WHERE id = $3 AND status = 'accepted'
+      AND f_in_jurisdiction($4, (SELECT f_vehicle_province(v.owner_id, v.registration_place) FROM vehicle_registration v WHERE v.id = vehicle_transfers.vehicle_id))
+    RETURNING *
+    `
+
+	// the vehicle row is locked before the blockers are re-checked by transferOwnerQuery, returns its area
+	lockVehicleQuery = `
+    SELECT COALESCE(f_vehicle_province(owner_id, registration_place), '') FROM vehicle_registration
+    WHERE id = $1
+    FOR UPDATE
+    `
+
+	// the owner is changed only if nobody changed it since the transfer was initiated
+	// and nothing blocks the transfer, the same blockers as getTransferBlockers.
+	// Returns whether the vehicle was anchored, its anchor no longer matches the new owner
+	transferOwnerQuery = `
+    UPDATE vehicle_registration vr
+    SET owner_id = $1,
+        owner_name = $2,
+        vehicle_no = COALESCE($3, vr.vehicle_no),
+        modifier_id = $4,
+        version = vr.version + 1,
+        updated_at = now()
+    WHERE vr.id = $5
+      AND vr.owner_id IS NOT DISTINCT FROM $6
+      AND f_in_jurisdiction($7, f_vehicle_province(vr.owner_id, vr.registration_place))
+      AND vr.active = true
+      AND vr.stolen = false
+      AND vr.seized = false
+      AND (vr.expiry_date IS NULL OR vr.expiry_date::date >= CURRENT_DATE)
+      AND NOT EXISTS (
+          SELECT 1
+            FROM traffic_violations tv
+           WHERE tv.vehicle_no = vr.vehicle_no
+             AND tv.active = true
+             AND LOWER(COALESCE(tv.status, '')) NOT IN ('processed', 'cancelled'))
+    RETURNING vr.on_blockchain
+    `
+
+	// the seller's ownership ends when the transfer is approved
+	closeOwnershipQuery = `
+    UPDATE vehicle_ownerships
+    SET to_date = $1
+    WHERE vehicle_id = $2 AND to_date IS NULL
+    `
+
+	openTransferOwnershipQuery = `
+    INSERT INTO vehicle_ownerships (
+        id, vehicle_id, owner_id, owner_name, identity_no, vehicle_no, from_date, reason, transfer_id, creator_id, created_at
+    ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, now())
+    `
+
+	// vehicles owned by the user directly or through one of the user's licenses
+	getOwnedVehicle = `
+    SELECT vr.*
+    FROM vehicle_registration vr
+    WHERE vr.id = $1
+      AND vr.active = true
+      AND (vr.owner_id = $2 OR vr.owner_id IN (
+          SELECT dl.id FROM driver_licenses dl WHERE dl.identity_no = $3 AND dl.active = true
+      ))
+    `
+
+	// owner id of a citizen, vehicles are owned through the latest active license
+	getOwnerIDByIdentity = `
+    SELECT id FROM driver_licenses
+    WHERE identity_no = $1 AND active = true
+    ORDER BY issue_date DESC, created_at DESC
+    LIMIT 1
+    `
+
+	getTransferBlockers = `
+    SELECT
+        (SELECT COUNT(*)
+           FROM traffic_violations tv
+          WHERE tv.vehicle_no = vr.vehicle_no
+            AND tv.active = true
+            AND LOWER(COALESCE(tv.status, '')) NOT IN ('processed', 'cancelled'))               AS unpaid_violations,
+        (SELECT COALESCE(SUM(tv.fine_amount), 0)::bigint
+           FROM traffic_violations tv
+          WHERE tv.vehicle_no = vr.vehicle_no
+            AND tv.active = true
+            AND LOWER(COALESCE(tv.status, '')) NOT IN ('processed', 'cancelled'))               AS unpaid_fines,
+        vr.expiry_date::text                                                                   AS inspection_expiry,
+        vr.expiry_date IS NOT NULL AND vr.expiry_date::date < CURRENT_DATE                     AS inspection_expired,
+        vr.stolen,
+        vr.seized
+    FROM vehicle_registration vr
+    WHERE vr.id = $1
+    `
+
+	plateTaken = `
+    SELECT EXISTS (
+        SELECT 1 FROM vehicle_registration
+        WHERE vehicle_no = $1 AND id <> $2 AND active = true
+    )
+    `
+
+	getTransfers = `
+    SELECT vt.*
+    FROM vehicle_transfers vt
+    WHERE f_in_jurisdiction($1, (SELECT f_vehicle_province(v.owner_id, v.registration_place) FROM vehicle_registration v WHERE v.id = vt.vehicle_id))
+    `
+
+	getTotalTransfers = `
+    SELECT COUNT(*)
+    FROM vehicle_transfers vt
+    WHERE f_in_jurisdiction($1, (SELECT f_vehicle_province(v.owner_id, v.registration_place) FROM vehicle_registration v WHERE v.id = vt.vehicle_id))
+    `
+
+	getTransfersByIdentity = `
+    SELECT vt.*
+    FROM vehicle_transfers vt
+    WHERE (vt.seller_identity_no = $1 OR vt.buyer_identity_no = $1)
+    `
+
+	getTotalTransfersByIdentity = `
+    SELECT COUNT(*)
+    FROM vehicle_transfers vt
+    WHERE (vt.seller_identity_no = $1 OR vt.buyer_identity_no = $1)
+    `
+)
@@ -0,0 +1,20 @@
+package vehicleTransfer
+
+import (
+	"context"
+
+	"github.com/adohong4/driving-license/internal/models"
+	"github.com/adohong4/driving-license/pkg/utils"
+	"github.com/google/uuid"
+)
+
+type UseCase interface {
+	InitiateTransfer(ctx context.Context, req *models.InitiateTransferRequest) (*models.VehicleTransfer, error)
+	AcceptTransfer(ctx context.Context, transferID uuid.UUID, req *models.AcceptTransferRequest) (*models.VehicleTransfer, error)
+	ApproveTransfer(ctx context.Context, transferID uuid.UUID, req *models.ApproveTransferRequest) (*models.VehicleTransfer, error)
+	RejectTransfer(ctx context.Context, transferID uuid.UUID, req *models.TransferReasonRequest) (*models.VehicleTransfer, error)
+	CancelTransfer(ctx context.Context, transferID uuid.UUID, req *models.TransferReasonRequest) (*models.VehicleTransfer, error)
+	GetTransferByID(ctx context.Context, transferID uuid.UUID) (*models.VehicleTransfer, error)
+	GetTransfers(ctx context.Context, pq *utils.PaginationQuery) (*models.VehicleTransferList, error)
+	GetMyTransfers(ctx context.Context, pq *utils.PaginationQuery) (*models.VehicleTransferList, error)
+}
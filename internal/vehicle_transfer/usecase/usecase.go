@@ -0,0 +1,398 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/adohong4/driving-license/config"
+	"github.com/adohong4/driving-license/internal/models"
+	"github.com/adohong4/driving-license/internal/notification"
+	vehicleTransfer "github.com/adohong4/driving-license/internal/vehicle_transfer"
+	"github.com/adohong4/driving-license/pkg/httpErrors"
+	"github.com/adohong4/driving-license/pkg/logger"
+	"github.com/adohong4/driving-license/pkg/plate"
+	"github.com/adohong4/driving-license/pkg/utils"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// Notification codes of the transfer steps, rendered from the i18n catalogs
+const (
+	notiTransferRequested = "transfer_requested"
+	notiTransferAccepted  = "transfer_accepted"
+	notiTransferApproved  = "transfer_approved"
+	notiTransferRejected  = "transfer_rejected"
+	notiTransferCancelled = "transfer_cancelled"
+
+	notiTypeTransfer = "vehicle_transfer"
+)
+
+// Roles allowed to approve and reject transfers and to read every transfer
+var officerRoles = map[string]bool{"admin": true, "officer": true}
+
+type vehicleTransferUC struct {
+	cfg          *config.Config
+	transferRepo vehicleTransfer.Repository
+	notiUC       notification.UseCase
+	logger       logger.Logger
+}
+
+func NewVehicleTransferUseCase(cfg *config.Config, transferRepo vehicleTransfer.Repository, notiUC notification.UseCase, logger logger.Logger) vehicleTransfer.UseCase {
+	return &vehicleTransferUC{cfg: cfg, transferRepo: transferRepo, notiUC: notiUC, logger: logger}
+}
+
+// The owner of a vehicle offers it to the citizen with the given identity number
+func (u *vehicleTransferUC) InitiateTransfer(ctx context.Context, req *models.InitiateTransferRequest) (*models.VehicleTransfer, error) {
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return nil, httpErrors.NewUnauthorizedError(errors.WithMessage(err, "vehicleTransferUC.InitiateTransfer.GetUserFromCtx"))
+	}
+	if err = utils.ValidateStruct(ctx, req); err != nil {
+		return nil, httpErrors.NewBadRequestError(errors.WithMessage(err, "vehicleTransferUC.InitiateTransfer.ValidateStruct"))
+	}
+	if user.IdentityNo == "" {
+		return nil, httpErrors.NewBadRequestError("user identity number is missing")
+	}
+	if req.BuyerIdentityNo == user.IdentityNo {
+		return nil, httpErrors.NewBadRequestError("the buyer must be another citizen")
+	}
+
+	vehicle, err := u.transferRepo.GetOwnedVehicle(ctx, req.VehicleID, user.Id, user.IdentityNo)
+	if err != nil {
+		return nil, err
+	}
+	if vehicle == nil {
+		return nil, httpErrors.NewRestError(http.StatusNotFound, "vehicle not found or not owned by you", nil)
+	}
+
+	open, err := u.transferRepo.GetOpenTransferByVehicle(ctx, vehicle.ID)
+	if err != nil {
+		return nil, err
+	}
+	if open != nil {
+		return nil, httpErrors.NewRestError(http.StatusConflict, fmt.Sprintf("the vehicle already has an open transfer %s", open.Id), nil)
+	}
+
+	t := &models.VehicleTransfer{
+		VehicleID:        vehicle.ID,
+		VehiclePlateNo:   vehicle.VehiclePlateNo,
+		SellerID:         user.Id,
+		SellerOwnerID:    vehicle.OwnerID,
+		SellerName:       user.FullName,
+		SellerIdentityNo: user.IdentityNo,
+		BuyerIdentityNo:  req.BuyerIdentityNo,
+		Note:             req.Note,
+	}
+	if err = t.PrepareCreate(); err != nil {
+		return nil, httpErrors.NewBadRequestError(errors.Wrap(err, "vehicleTransferUC.InitiateTransfer.PrepareCreate"))
+	}
+
+	created, err := u.transferRepo.CreateTransfer(ctx, t)
+	if err != nil {
+		return nil, err
+	}
+
+	u.notify(ctx, notiTransferRequested, created.BuyerIdentityNo, created)
+	return created, nil
+}
+
+// The buyer accepts with the identity number the seller named
+func (u *vehicleTransferUC) AcceptTransfer(ctx context.Context, transferID uuid.UUID, req *models.AcceptTransferRequest) (*models.VehicleTransfer, error) {
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return nil, httpErrors.NewUnauthorizedError(errors.WithMessage(err, "vehicleTransferUC.AcceptTransfer.GetUserFromCtx"))
+	}
+	if err = utils.ValidateStruct(ctx, req); err != nil {
+		return nil, httpErrors.NewBadRequestError(errors.WithMessage(err, "vehicleTransferUC.AcceptTransfer.ValidateStruct"))
+	}
+
+	t, err := u.transferRepo.GetTransferByID(ctx, transferID)
+	if err != nil {
+		return nil, err
+	}
+	if req.IdentityNo != user.IdentityNo || req.IdentityNo != t.BuyerIdentityNo {
+		return nil, httpErrors.NewForbiddenError("the identity number does not match the buyer of the transfer")
+	}
+	if t.Status != models.TransferInitiated {
+		return nil, transferStateError(t)
+	}
+
+	// vehicles are owned through the buyer's license, citizens without one own them directly
+	ownerID, err := u.transferRepo.GetOwnerIDByIdentity(ctx, user.IdentityNo)
+	if err != nil {
+		return nil, err
+	}
+	if ownerID == nil {
+		ownerID = &user.Id
+	}
+	t.BuyerID = &user.Id
+	t.BuyerOwnerID = ownerID
+	t.BuyerName = user.FullName
+
+	accepted, err := u.transferRepo.AcceptTransfer(ctx, t)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, transferStateError(t)
+		}
+		return nil, err
+	}
+
+	u.notify(ctx, notiTransferAccepted, accepted.SellerIdentityNo, accepted)
+	return accepted, nil
+}
+
+// An officer approves an accepted transfer once the vehicle has no unpaid violations and its inspection is valid.
+// The owner changes atomically with the approval, a new plate is issued when one is given.
+// The blockers are checked again inside the approval transaction.
+func (u *vehicleTransferUC) ApproveTransfer(ctx context.Context, transferID uuid.UUID, req *models.ApproveTransferRequest) (*models.VehicleTransfer, error) {
+	user, err := u.officerFromCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := u.transferRepo.GetTransferByID(ctx, transferID)
+	if err != nil {
+		return nil, err
+	}
+	if t.Status != models.TransferAccepted {
+		return nil, transferStateError(t)
+	}
+
+	blockers, err := u.transferRepo.GetTransferBlockers(ctx, t.VehicleID)
+	if err != nil {
+		return nil, err
+	}
+	if !blockers.Clear() {
+		return nil, httpErrors.NewRestError(http.StatusConflict, httpErrors.ErrTransferBlocked, blockersDetail(blockers))
+	}
+
+	if req.NewPlateNo != "" {
+		newPlate, err := plate.Canonical(req.NewPlateNo)
+		if err != nil {
+			return nil, httpErrors.NewBadRequestError(errors.Wrap(err, "vehicleTransferUC.ApproveTransfer.Canonical"))
+		}
+		taken, err := u.transferRepo.PlateTaken(ctx, newPlate, t.VehicleID)
+		if err != nil {
+			return nil, err
+		}
+		if taken {
+			return nil, httpErrors.NewRestErrorWithMessage(http.StatusBadRequest, httpErrors.ErrVehicleAlreadyExists, nil)
+		}
+		t.NewPlateNo = &newPlate
+	}
+	t.OfficerID = &user.Id
+
+	approved, err := u.transferRepo.ApproveTransfer(ctx, t)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		// a violation, an expired inspection or a stolen/seized flag may have landed after the check above
+		if blockers, bErr := u.transferRepo.GetTransferBlockers(ctx, t.VehicleID); bErr == nil && !blockers.Clear() {
+			return nil, httpErrors.NewRestError(http.StatusConflict, httpErrors.ErrTransferBlocked, blockersDetail(blockers))
+		}
+		return nil, httpErrors.NewRestError(http.StatusConflict,
+			"the transfer no longer awaits approval or the vehicle changed owner", nil)
+	}
+
+	u.notify(ctx, notiTransferApproved, approved.SellerIdentityNo, approved)
+	u.notify(ctx, notiTransferApproved, approved.BuyerIdentityNo, approved)
+	return approved, nil
+}
+
+// An officer rejects an open transfer
+func (u *vehicleTransferUC) RejectTransfer(ctx context.Context, transferID uuid.UUID, req *models.TransferReasonRequest) (*models.VehicleTransfer, error) {
+	user, err := u.officerFromCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = utils.ValidateStruct(ctx, req); err != nil {
+		return nil, httpErrors.NewBadRequestError(errors.WithMessage(err, "vehicleTransferUC.RejectTransfer.ValidateStruct"))
+	}
+	if req.Reason == "" {
+		return nil, httpErrors.NewBadRequestError("a reason is required to reject a transfer")
+	}
+
+	t, err := u.transferRepo.GetTransferByID(ctx, transferID)
+	if err != nil {
+		return nil, err
+	}
+	if !t.Open() {
+		return nil, transferStateError(t)
+	}
+	t.Status = models.TransferRejected
+	t.Reason = req.Reason
+	t.OfficerID = &user.Id
+
+	rejected, err := u.closeTransfer(ctx, t)
+	if err != nil {
+		return nil, err
+	}
+
+	u.notify(ctx, notiTransferRejected, rejected.SellerIdentityNo, rejected)
+	u.notify(ctx, notiTransferRejected, rejected.BuyerIdentityNo, rejected)
+	return rejected, nil
+}
+
+// The seller or the buyer withdraws from an open transfer
+func (u *vehicleTransferUC) CancelTransfer(ctx context.Context, transferID uuid.UUID, req *models.TransferReasonRequest) (*models.VehicleTransfer, error) {
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return nil, httpErrors.NewUnauthorizedError(errors.WithMessage(err, "vehicleTransferUC.CancelTransfer.GetUserFromCtx"))
+	}
+	if err = utils.ValidateStruct(ctx, req); err != nil {
+		return nil, httpErrors.NewBadRequestError(errors.WithMessage(err, "vehicleTransferUC.CancelTransfer.ValidateStruct"))
+	}
+
+	t, err := u.transferRepo.GetTransferByID(ctx, transferID)
+	if err != nil {
+		return nil, err
+	}
+	seller := t.SellerID == user.Id
+	if !seller && !isBuyer(t, user) {
+		return nil, httpErrors.NewRestError(http.StatusNotFound, "transfer not found", nil)
+	}
+	if !t.Open() {
+		return nil, transferStateError(t)
+	}
+	t.Status = models.TransferCancelled
+	t.Reason = req.Reason
+
+	cancelled, err := u.closeTransfer(ctx, t)
+	if err != nil {
+		return nil, err
+	}
+
+	// the other party is told
+	if seller {
+		u.notify(ctx, notiTransferCancelled, cancelled.BuyerIdentityNo, cancelled)
+	} else {
+		u.notify(ctx, notiTransferCancelled, cancelled.SellerIdentityNo, cancelled)
+	}
+	return cancelled, nil
+}
+
+// Transfer of one of its parties, officers read any transfer
+func (u *vehicleTransferUC) GetTransferByID(ctx context.Context, transferID uuid.UUID) (*models.VehicleTransfer, error) {
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return nil, httpErrors.NewUnauthorizedError(errors.WithMessage(err, "vehicleTransferUC.GetTransferByID.GetUserFromCtx"))
+	}
+
+	t, err := u.transferRepo.GetTransferByID(ctx, transferID)
+	if err != nil {
+		return nil, err
+	}
+	if !isOfficer(user) && t.SellerID != user.Id && !isBuyer(t, user) {
+		return nil, httpErrors.NewRestError(http.StatusNotFound, "transfer not found", nil)
+	}
+	return t, nil
+}
+
+func (u *vehicleTransferUC) GetTransfers(ctx context.Context, pq *utils.PaginationQuery) (*models.VehicleTransferList, error) {
+	if _, err := u.officerFromCtx(ctx); err != nil {
+		return nil, err
+	}
+	return u.transferRepo.GetTransfers(ctx, pq)
+}
+
+// Transfers the current user sells or buys
+func (u *vehicleTransferUC) GetMyTransfers(ctx context.Context, pq *utils.PaginationQuery) (*models.VehicleTransferList, error) {
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return nil, httpErrors.NewUnauthorizedError(err)
+	}
+	if user.IdentityNo == "" {
+		return nil, httpErrors.NewBadRequestError("user identity number is missing")
+	}
+	return u.transferRepo.GetTransfersByIdentity(ctx, user.IdentityNo, pq)
+}
+
+func (u *vehicleTransferUC) closeTransfer(ctx context.Context, t *models.VehicleTransfer) (*models.VehicleTransfer, error) {
+	closed, err := u.transferRepo.CloseTransfer(ctx, t)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, httpErrors.NewRestError(http.StatusConflict, "the transfer is no longer open", nil)
+		}
+		return nil, err
+	}
+	return closed, nil
+}
+
+func (u *vehicleTransferUC) officerFromCtx(ctx context.Context) (*models.User, error) {
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return nil, httpErrors.NewUnauthorizedError(err)
+	}
+	if !isOfficer(user) {
+		return nil, httpErrors.NewForbiddenError(httpErrors.PermissionDenied)
+	}
+	return user, nil
+}
+
+// Tell a party about a transfer step, the transfer is already saved so a failure is only logged
+func (u *vehicleTransferUC) notify(ctx context.Context, code, identityNo string, t *models.VehicleTransfer) {
+	if identityNo == "" {
+		return
+	}
+
+	newPlate := t.VehiclePlateNo
+	if t.NewPlateNo != nil {
+		newPlate = *t.NewPlateNo
+	}
+	n := &models.Notification{
+		Code:       code,
+		Type:       notiTypeTransfer,
+		Target:     "personal",
+		TargetUser: identityNo,
+		Status:     "unread",
+		Params: models.NotificationParams{
+			"transfer_id":    t.Id.String(),
+			"vehicle_no":     t.VehiclePlateNo,
+			"new_vehicle_no": newPlate,
+			"seller_name":    t.SellerName,
+			"buyer_name":     t.BuyerName,
+			"reason":         t.Reason,
+		},
+	}
+	if _, err := u.notiUC.CreateNotification(ctx, n); err != nil {
+		u.logger.Errorf("vehicleTransferUC.notify %s %s: %v", code, t.Id, err)
+	}
+}
+
+func isOfficer(user *models.User) bool {
+	return user.Role != nil && officerRoles[*user.Role]
+}
+
+func isBuyer(t *models.VehicleTransfer, user *models.User) bool {
+	return user.IdentityNo != "" && t.BuyerIdentityNo == user.IdentityNo
+}
+
+func transferStateError(t *models.VehicleTransfer) error {
+	return httpErrors.NewRestError(http.StatusConflict, fmt.Sprintf("the transfer is %s", t.Status), nil)
+}
+
+// Client message of what blocks a transfer
+func blockersDetail(b *models.TransferBlockers) string {
+	var parts []string
+	if b.UnpaidViolations > 0 {
+		parts = append(parts, fmt.Sprintf("%d unpaid violations, %d VND in fines", b.UnpaidViolations, b.UnpaidFines))
+	}
+	if b.InspectionExpired {
+		expired := "inspection expired"
+		if b.InspectionExpiry != nil {
+			expired += " on " + *b.InspectionExpiry
+		}
+		parts = append(parts, expired)
+	}
+	if b.Stolen {
+		parts = append(parts, "vehicle reported stolen")
+	}
+	if b.Seized {
+		parts = append(parts, "vehicle seized")
+	}
+	return strings.Join(parts, "; ")
+}
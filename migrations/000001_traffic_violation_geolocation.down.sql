@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_traffic_violations_location_date;
+DROP INDEX IF EXISTS idx_traffic_violations_province_district;
+
+ALTER TABLE traffic_violations
+    DROP CONSTRAINT IF EXISTS traffic_violations_longitude_check,
+    DROP CONSTRAINT IF EXISTS traffic_violations_latitude_check,
+    DROP COLUMN IF EXISTS district,
+    DROP COLUMN IF EXISTS province,
+    DROP COLUMN IF EXISTS longitude,
+    DROP COLUMN IF EXISTS latitude;
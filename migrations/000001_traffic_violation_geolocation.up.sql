@@ -0,0 +1,15 @@
+ALTER TABLE traffic_violations
+    ADD COLUMN IF NOT EXISTS latitude  DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS longitude DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS province  VARCHAR(100) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS district  VARCHAR(100) NOT NULL DEFAULT '';
+
+ALTER TABLE traffic_violations
+    ADD CONSTRAINT traffic_violations_latitude_check CHECK (latitude IS NULL OR latitude BETWEEN -90 AND 90),
+    ADD CONSTRAINT traffic_violations_longitude_check CHECK (longitude IS NULL OR longitude BETWEEN -180 AND 180);
+
+CREATE INDEX IF NOT EXISTS idx_traffic_violations_province_district
+    ON traffic_violations (province, district) WHERE active = true;
+
+CREATE INDEX IF NOT EXISTS idx_traffic_violations_location_date
+    ON traffic_violations (latitude, longitude, date) WHERE active = true AND latitude IS NOT NULL;
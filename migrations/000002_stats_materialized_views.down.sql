@@ -0,0 +1,5 @@
+DROP MATERIALIZED VIEW IF EXISTS mv_violation_stats;
+DROP MATERIALIZED VIEW IF EXISTS mv_vehicle_stats;
+DROP MATERIALIZED VIEW IF EXISTS mv_license_stats;
+
+DROP TABLE IF EXISTS stats_refresh_log;
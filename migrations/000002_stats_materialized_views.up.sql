@@ -0,0 +1,55 @@
+CREATE TABLE IF NOT EXISTS stats_refresh_log (
+    view_name    VARCHAR(100) PRIMARY KEY,
+    refreshed_at TIMESTAMPTZ  NOT NULL DEFAULT now()
+);
+
+CREATE MATERIALIZED VIEW IF NOT EXISTS mv_license_stats AS
+SELECT
+    COALESCE(status, '')                       AS status,
+    COALESCE(license_type, '')                 AS license_type,
+    COALESCE(owner_city, 'Không xác định')     AS owner_city,
+    COUNT(*)                                   AS count
+FROM driver_licenses
+WHERE active = true
+GROUP BY 1, 2, 3;
+
+CREATE UNIQUE INDEX IF NOT EXISTS mv_license_stats_key
+    ON mv_license_stats (status, license_type, owner_city);
+
+CREATE MATERIALIZED VIEW IF NOT EXISTS mv_vehicle_stats AS
+SELECT
+    COALESCE(type_vehicle, '')                                        AS type_vehicle,
+    COALESCE(brand, '')                                               AS brand,
+    type_vehicle IS NOT NULL AND type_vehicle NOT ILIKE ANY (ARRAY[
+        '%xe máy%', '%xe mô tô%', '%xe gắn máy%',
+        '%xe đạp%', '%xe đạp điện%', '%xe máy điện%'
+    ])                                                                AS is_motor,
+    expiry_date IS NOT NULL AND expiry_date >= CURRENT_DATE           AS is_valid,
+    expiry_date IS NOT NULL AND expiry_date < CURRENT_DATE            AS is_expired,
+    expiry_date IS NULL OR registration_date IS NULL                  AS is_pending,
+    COUNT(*)                                                          AS count
+FROM vehicle_registration
+WHERE active = true
+GROUP BY 1, 2, 3, 4, 5, 6;
+
+CREATE UNIQUE INDEX IF NOT EXISTS mv_vehicle_stats_key
+    ON mv_vehicle_stats (type_vehicle, brand, is_motor, is_valid, is_expired, is_pending);
+
+CREATE MATERIALIZED VIEW IF NOT EXISTS mv_violation_stats AS
+SELECT
+    COALESCE(status, '')                                    AS status,
+    expiry_date IS NOT NULL AND expiry_date < CURRENT_DATE  AS is_overdue,
+    COUNT(*)                                                AS count,
+    COALESCE(SUM(fine_amount), 0)::bigint                   AS fine_amount
+FROM traffic_violations
+WHERE active = true
+GROUP BY 1, 2;
+
+CREATE UNIQUE INDEX IF NOT EXISTS mv_violation_stats_key
+    ON mv_violation_stats (status, is_overdue);
+
+INSERT INTO stats_refresh_log (view_name, refreshed_at) VALUES
+    ('mv_license_stats', now()),
+    ('mv_vehicle_stats', now()),
+    ('mv_violation_stats', now())
+ON CONFLICT (view_name) DO UPDATE SET refreshed_at = EXCLUDED.refreshed_at;
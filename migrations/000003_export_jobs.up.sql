@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS export_jobs (
+    id          UUID PRIMARY KEY,
+    user_id     UUID         NOT NULL,
+    entity      VARCHAR(50)  NOT NULL,
+    format      VARCHAR(10)  NOT NULL,
+    lang        VARCHAR(5)   NOT NULL DEFAULT 'vi',
+    search      VARCHAR(255) NOT NULL DEFAULT '',
+    status      VARCHAR(20)  NOT NULL DEFAULT 'pending',
+    row_count   INTEGER      NOT NULL DEFAULT 0,
+    file_path   TEXT         NOT NULL DEFAULT '',
+    error       TEXT         NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT now(),
+    finished_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_export_jobs_user_created ON export_jobs (user_id, created_at DESC);
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS import_jobs (
+    id              UUID PRIMARY KEY,
+    user_id         UUID         NOT NULL,
+    entity          VARCHAR(50)  NOT NULL,
+    format          VARCHAR(10)  NOT NULL,
+    lang            VARCHAR(5)   NOT NULL DEFAULT 'vi',
+    file_name       VARCHAR(255) NOT NULL DEFAULT '',
+    dry_run         BOOLEAN      NOT NULL DEFAULT false,
+    status          VARCHAR(20)  NOT NULL DEFAULT 'pending',
+    total_rows      INTEGER      NOT NULL DEFAULT 0,
+    valid_rows      INTEGER      NOT NULL DEFAULT 0,
+    imported_rows   INTEGER      NOT NULL DEFAULT 0,
+    failed_rows     INTEGER      NOT NULL DEFAULT 0,
+    file_path       TEXT         NOT NULL DEFAULT '',
+    error_file_path TEXT         NOT NULL DEFAULT '',
+    error           TEXT         NOT NULL DEFAULT '',
+    created_at      TIMESTAMPTZ  NOT NULL DEFAULT now(),
+    finished_at     TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_import_jobs_user_created ON import_jobs (user_id, created_at DESC);
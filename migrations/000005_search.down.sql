@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_vehicle_registration_search_fts;
+DROP INDEX IF EXISTS idx_vehicle_registration_search_trgm;
+DROP INDEX IF EXISTS idx_driver_licenses_search_fts;
+DROP INDEX IF EXISTS idx_driver_licenses_search_trgm;
+DROP INDEX IF EXISTS idx_users_search_fts;
+DROP INDEX IF EXISTS idx_users_search_trgm;
+
+DROP FUNCTION IF EXISTS f_search_doc(VARIADIC text[]);
+DROP FUNCTION IF EXISTS f_unaccent(text);
+
+-- the extensions may be used elsewhere and are kept
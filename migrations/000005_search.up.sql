@@ -0,0 +1,32 @@
+CREATE EXTENSION IF NOT EXISTS unaccent;
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- unaccent() is only STABLE, indexes need an IMMUTABLE wrapper with a fixed dictionary
+CREATE OR REPLACE FUNCTION f_unaccent(text) RETURNS text
+    LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT
+AS $$ SELECT public.unaccent('public.unaccent'::regdictionary, $1) $$;
+
+-- Search documents, lower-cased without diacritics, identifiers are also indexed without separators.
+-- The expressions must match the search queries of internal/search/repository.
+CREATE OR REPLACE FUNCTION f_search_doc(VARIADIC parts text[]) RETURNS text
+    LANGUAGE sql IMMUTABLE PARALLEL SAFE
+AS $$ SELECT f_unaccent(lower(array_to_string(parts, ' ', ''))) $$;
+
+CREATE INDEX IF NOT EXISTS idx_users_search_trgm
+    ON users USING gin (f_search_doc(full_name, identity_no) gin_trgm_ops) WHERE active = true;
+CREATE INDEX IF NOT EXISTS idx_users_search_fts
+    ON users USING gin (to_tsvector('simple', f_search_doc(full_name, identity_no))) WHERE active = true;
+
+CREATE INDEX IF NOT EXISTS idx_driver_licenses_search_trgm
+    ON driver_licenses USING gin (f_search_doc(license_no, full_name, identity_no) gin_trgm_ops) WHERE active = true;
+CREATE INDEX IF NOT EXISTS idx_driver_licenses_search_fts
+    ON driver_licenses USING gin (to_tsvector('simple', f_search_doc(license_no, full_name, identity_no))) WHERE active = true;
+
+CREATE INDEX IF NOT EXISTS idx_vehicle_registration_search_trgm
+    ON vehicle_registration USING gin (
+        f_search_doc(vehicle_no, regexp_replace(vehicle_no, '[^A-Za-z0-9]', '', 'g'), chassis_no, engine_no, owner_name) gin_trgm_ops
+    ) WHERE active = true;
+CREATE INDEX IF NOT EXISTS idx_vehicle_registration_search_fts
+    ON vehicle_registration USING gin (
+        to_tsvector('simple', f_search_doc(vehicle_no, regexp_replace(vehicle_no, '[^A-Za-z0-9]', '', 'g'), chassis_no, engine_no, owner_name))
+    ) WHERE active = true;
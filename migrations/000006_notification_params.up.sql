@@ -0,0 +1,2 @@
+-- Placeholder values of templated notifications, title and content are rendered per language from the code
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS params JSONB NOT NULL DEFAULT '{}'::jsonb;
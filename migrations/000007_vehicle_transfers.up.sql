@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS vehicle_transfers (
+    id                 UUID PRIMARY KEY,
+    vehicle_id         UUID         NOT NULL REFERENCES vehicle_registration (id),
+    vehicle_no         VARCHAR(20)  NOT NULL,
+    seller_id          UUID         NOT NULL,
+    seller_owner_id    UUID,
+    seller_name        VARCHAR(255) NOT NULL DEFAULT '',
+    seller_identity_no VARCHAR(20)  NOT NULL DEFAULT '',
+    buyer_identity_no  VARCHAR(20)  NOT NULL,
+    buyer_id           UUID,
+    buyer_owner_id     UUID,
+    buyer_name         VARCHAR(255) NOT NULL DEFAULT '',
+    new_vehicle_no     VARCHAR(20),
+    status             VARCHAR(20)  NOT NULL DEFAULT 'initiated',
+    note               TEXT         NOT NULL DEFAULT '',
+    reason             TEXT         NOT NULL DEFAULT '',
+    officer_id         UUID,
+    accepted_at        TIMESTAMPTZ,
+    decided_at         TIMESTAMPTZ,
+    created_at         TIMESTAMPTZ  NOT NULL DEFAULT now(),
+    updated_at         TIMESTAMPTZ  NOT NULL DEFAULT now()
+);
+
+-- a vehicle has at most one open transfer
+CREATE UNIQUE INDEX IF NOT EXISTS idx_vehicle_transfers_open
+    ON vehicle_transfers (vehicle_id) WHERE status IN ('initiated', 'accepted');
+
+CREATE INDEX IF NOT EXISTS idx_vehicle_transfers_vehicle_created ON vehicle_transfers (vehicle_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_vehicle_transfers_seller ON vehicle_transfers (seller_identity_no);
+CREATE INDEX IF NOT EXISTS idx_vehicle_transfers_buyer ON vehicle_transfers (buyer_identity_no);
+CREATE INDEX IF NOT EXISTS idx_vehicle_transfers_status_updated ON vehicle_transfers (status, updated_at DESC);
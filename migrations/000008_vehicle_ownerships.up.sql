@@ -0,0 +1,67 @@
+CREATE EXTENSION IF NOT EXISTS pgcrypto;
+
+-- Chain of title, one row per owner of a vehicle, to_date is null for the current owner
+CREATE TABLE IF NOT EXISTS vehicle_ownerships (
+    id                UUID PRIMARY KEY,
+    vehicle_id        UUID         NOT NULL REFERENCES vehicle_registration (id),
+    owner_id          UUID,
+    owner_name        VARCHAR(255) NOT NULL DEFAULT '',
+    identity_no       VARCHAR(20)  NOT NULL DEFAULT '',
+    vehicle_no        VARCHAR(20)  NOT NULL,
+    from_date         TIMESTAMPTZ  NOT NULL,
+    to_date           TIMESTAMPTZ,
+    reason            VARCHAR(20)  NOT NULL,
+    transfer_id       UUID REFERENCES vehicle_transfers (id),
+    blockchain_txhash VARCHAR(100) NOT NULL DEFAULT '',
+    creator_id        UUID,
+    created_at        TIMESTAMPTZ  NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_vehicle_ownerships_current
+    ON vehicle_ownerships (vehicle_id) WHERE to_date IS NULL;
+CREATE INDEX IF NOT EXISTS idx_vehicle_ownerships_vehicle_from ON vehicle_ownerships (vehicle_id, from_date);
+CREATE INDEX IF NOT EXISTS idx_vehicle_ownerships_plate_from ON vehicle_ownerships (vehicle_no, from_date);
+CREATE INDEX IF NOT EXISTS idx_vehicle_ownerships_owner ON vehicle_ownerships (owner_id);
+CREATE INDEX IF NOT EXISTS idx_vehicle_ownerships_identity ON vehicle_ownerships (identity_no);
+
+-- issue dates are free text on older rows, only ISO dates are trusted
+CREATE OR REPLACE FUNCTION f_issue_date(d text) RETURNS timestamptz AS $$
+    SELECT CASE WHEN d ~ '^\d{4}-\d{2}-\d{2}' THEN LEFT(d, 10)::timestamptz END
+$$ LANGUAGE sql STABLE;
+
+-- Backfill, sellers of approved transfers owned the vehicle from the previous transfer or the registration
+WITH approved AS (
+    SELECT vt.*,
+           LAG(vt.id) OVER w          AS prev_id,
+           LAG(vt.decided_at) OVER w  AS prev_at
+    FROM vehicle_transfers vt
+    WHERE vt.status = 'approved'
+    WINDOW w AS (PARTITION BY vt.vehicle_id ORDER BY vt.decided_at)
+)
+INSERT INTO vehicle_ownerships (id, vehicle_id, owner_id, owner_name, identity_no, vehicle_no, from_date, to_date, reason, transfer_id)
+SELECT gen_random_uuid(), a.vehicle_id, a.seller_owner_id, a.seller_name, a.seller_identity_no, a.vehicle_no,
+       COALESCE(a.prev_at, f_issue_date(vr.issue_date::text), vr.created_at), a.decided_at,
+       CASE WHEN a.prev_id IS NULL THEN 'registration' ELSE 'transfer' END, a.prev_id
+FROM approved a
+JOIN vehicle_registration vr ON vr.id = a.vehicle_id;
+
+-- current owners, since the last approved transfer or the registration
+INSERT INTO vehicle_ownerships (id, vehicle_id, owner_id, owner_name, identity_no, vehicle_no, from_date, reason, transfer_id, blockchain_txhash, creator_id)
+SELECT gen_random_uuid(), vr.id, vr.owner_id, COALESCE(vr.owner_name, ''),
+       COALESCE(dl.identity_no, u.identity_no, ''), vr.vehicle_no,
+       COALESCE(last.decided_at, f_issue_date(vr.issue_date::text), vr.created_at),
+       CASE WHEN last.id IS NULL THEN 'registration' ELSE 'transfer' END, last.id,
+       COALESCE(vr.blockchain_txhash, ''), vr.creator_id
+FROM vehicle_registration vr
+LEFT JOIN driver_licenses dl ON dl.id = vr.owner_id
+LEFT JOIN users u ON u.id = vr.owner_id
+LEFT JOIN LATERAL (
+    SELECT vt.id, vt.decided_at
+    FROM vehicle_transfers vt
+    WHERE vt.vehicle_id = vr.id AND vt.status = 'approved'
+    ORDER BY vt.decided_at DESC
+    LIMIT 1
+) last ON true
+WHERE vr.active = true;
+
+DROP FUNCTION f_issue_date(text);
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS vehicle_events;
+
+DROP INDEX IF EXISTS idx_vehicle_registration_stolen;
+
+ALTER TABLE vehicle_registration
+    DROP COLUMN IF EXISTS deregistered_at,
+    DROP COLUMN IF EXISTS seized,
+    DROP COLUMN IF EXISTS stolen;
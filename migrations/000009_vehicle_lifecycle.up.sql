@@ -0,0 +1,25 @@
+-- Stolen and seized flags, deregistered vehicles are taken out of the registry
+ALTER TABLE vehicle_registration
+    ADD COLUMN IF NOT EXISTS stolen          BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS seized          BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS deregistered_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_vehicle_registration_stolen
+    ON vehicle_registration (vehicle_no) WHERE stolen = true AND active = true;
+
+-- Lifecycle operations of a vehicle: deregistration, theft reports and recoveries, seizures and releases
+CREATE TABLE IF NOT EXISTS vehicle_events (
+    id          UUID PRIMARY KEY,
+    vehicle_id  UUID         NOT NULL REFERENCES vehicle_registration (id),
+    vehicle_no  VARCHAR(20)  NOT NULL,
+    event       VARCHAR(20)  NOT NULL,
+    cause       VARCHAR(20)  NOT NULL DEFAULT '',
+    reason      TEXT         NOT NULL DEFAULT '',
+    event_date  TIMESTAMPTZ  NOT NULL,
+    agency_id   UUID         NOT NULL REFERENCES gov_agencies (id),
+    agency_name VARCHAR(255) NOT NULL DEFAULT '',
+    officer_id  UUID         NOT NULL,
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_vehicle_events_vehicle_date ON vehicle_events (vehicle_id, event_date DESC);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS license_applications;
+
+DROP INDEX IF EXISTS idx_driver_licenses_previous;
+
+ALTER TABLE driver_licenses DROP COLUMN IF EXISTS previous_license_id;
@@ -0,0 +1,35 @@
+-- Licenses replaced by a renewal, upgrade or reissue point to the license they replace
+ALTER TABLE driver_licenses
+    ADD COLUMN IF NOT EXISTS previous_license_id UUID REFERENCES driver_licenses (id);
+
+CREATE INDEX IF NOT EXISTS idx_driver_licenses_previous ON driver_licenses (previous_license_id);
+
+CREATE TABLE IF NOT EXISTS license_applications (
+    id                     UUID PRIMARY KEY,
+    kind                   VARCHAR(20)  NOT NULL,
+    license_id             UUID         NOT NULL REFERENCES driver_licenses (id),
+    license_no             VARCHAR(50)  NOT NULL,
+    applicant_id           UUID         NOT NULL,
+    identity_no            VARCHAR(20)  NOT NULL,
+    full_name              VARCHAR(255) NOT NULL DEFAULT '',
+    license_type           VARCHAR(10)  NOT NULL,
+    requested_license_type VARCHAR(10)  NOT NULL,
+    reissue_cause          VARCHAR(20)  NOT NULL DEFAULT '',
+    note                   TEXT         NOT NULL DEFAULT '',
+    documents              JSONB        NOT NULL DEFAULT '[]',
+    agency_id              UUID         NOT NULL,
+    status                 VARCHAR(20)  NOT NULL DEFAULT 'pending',
+    reviewer_id            UUID,
+    review_note            TEXT         NOT NULL DEFAULT '',
+    new_license_id         UUID REFERENCES driver_licenses (id),
+    decided_at             TIMESTAMPTZ,
+    created_at             TIMESTAMPTZ  NOT NULL DEFAULT now(),
+    updated_at             TIMESTAMPTZ  NOT NULL DEFAULT now()
+);
+
+-- a license has at most one pending application
+CREATE UNIQUE INDEX IF NOT EXISTS idx_license_applications_pending
+    ON license_applications (license_id) WHERE status = 'pending';
+
+CREATE INDEX IF NOT EXISTS idx_license_applications_identity ON license_applications (identity_no, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_license_applications_agency_status ON license_applications (agency_id, status, updated_at DESC);
@@ -0,0 +1,16 @@
+DROP MATERIALIZED VIEW IF EXISTS mv_license_stats;
+
+CREATE MATERIALIZED VIEW mv_license_stats AS
+SELECT
+    COALESCE(status, '')                       AS status,
+    COALESCE(license_type, '')                 AS license_type,
+    COALESCE(owner_city, 'Không xác định')     AS owner_city,
+    COUNT(*)                                   AS count
+FROM driver_licenses
+WHERE active = true
+GROUP BY 1, 2, 3;
+
+CREATE UNIQUE INDEX IF NOT EXISTS mv_license_stats_key
+    ON mv_license_stats (status, license_type, owner_city);
+
+DROP TABLE IF EXISTS license_endorsements;
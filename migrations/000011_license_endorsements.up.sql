@@ -0,0 +1,65 @@
+-- Classes a license card is endorsed for, each one with its own issue date, expiry and status
+CREATE TABLE IF NOT EXISTS license_endorsements (
+    id           UUID PRIMARY KEY,
+    license_id   UUID        NOT NULL REFERENCES driver_licenses (id),
+    license_type VARCHAR(10) NOT NULL,
+    issue_date   DATE        NOT NULL,
+    expiry_date  DATE,
+    status       VARCHAR(20) NOT NULL DEFAULT 'active',
+    authority_id UUID,
+    creator_id   UUID,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    active       BOOLEAN     NOT NULL DEFAULT true
+);
+
+-- a card holds a class at most once
+CREATE UNIQUE INDEX IF NOT EXISTS idx_license_endorsements_class
+    ON license_endorsements (license_id, license_type) WHERE active = true;
+
+CREATE INDEX IF NOT EXISTS idx_license_endorsements_type ON license_endorsements (license_type, status);
+
+-- every existing license is endorsed for its own class
+CREATE OR REPLACE FUNCTION f_iso_date(d text) RETURNS date AS $$
+    SELECT CASE WHEN d ~ '^\d{4}-\d{2}-\d{2}' THEN substr(d, 1, 10)::date END
+$$ LANGUAGE sql IMMUTABLE;
+
+INSERT INTO license_endorsements (
+    id, license_id, license_type, issue_date, expiry_date, status, authority_id, creator_id, created_at, updated_at
+)
+SELECT md5(dl.id::text || dl.license_type)::uuid, dl.id, upper(dl.license_type),
+       COALESCE(f_iso_date(dl.issue_date::text), dl.created_at::date), f_iso_date(dl.expiry_date::text),
+       COALESCE(NULLIF(dl.status, ''), 'active'), dl.authority_id, dl.creator_id, dl.created_at, dl.updated_at
+FROM driver_licenses dl
+WHERE dl.active = true AND COALESCE(dl.license_type, '') <> ''
+ON CONFLICT DO NOTHING;
+
+DROP FUNCTION f_iso_date(text);
+
+-- license statistics count cards by status and city and endorsements by class
+DROP MATERIALIZED VIEW IF EXISTS mv_license_stats;
+
+CREATE MATERIALIZED VIEW mv_license_stats AS
+SELECT
+    'license'                                  AS source,
+    COALESCE(status, '')                       AS status,
+    ''                                         AS license_type,
+    COALESCE(owner_city, 'Không xác định')     AS owner_city,
+    COUNT(*)                                   AS count
+FROM driver_licenses
+WHERE active = true
+GROUP BY 1, 2, 3, 4
+UNION ALL
+SELECT
+    'endorsement',
+    COALESCE(le.status, ''),
+    le.license_type,
+    COALESCE(dl.owner_city, 'Không xác định'),
+    COUNT(*)
+FROM license_endorsements le
+JOIN driver_licenses dl ON dl.id = le.license_id
+WHERE le.active = true AND dl.active = true AND COALESCE(dl.status, '') <> 'superseded'
+GROUP BY 1, 2, 3, 4;
+
+CREATE UNIQUE INDEX IF NOT EXISTS mv_license_stats_key
+    ON mv_license_stats (source, status, license_type, owner_city);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS exam_bookings;
+
+DROP TABLE IF EXISTS exam_sessions;
+
+DROP TABLE IF EXISTS school_enrollments;
@@ -0,0 +1,66 @@
+-- Learners enrolled at a driving school for a license class
+CREATE TABLE IF NOT EXISTS school_enrollments (
+    id                  UUID PRIMARY KEY,
+    school_id           UUID         NOT NULL REFERENCES gov_agencies (id),
+    school_name         VARCHAR(255) NOT NULL DEFAULT '',
+    learner_id          UUID,
+    identity_no         VARCHAR(20)  NOT NULL,
+    full_name           VARCHAR(255) NOT NULL,
+    dob                 DATE         NOT NULL,
+    license_type        VARCHAR(10)  NOT NULL,
+    status              VARCHAR(20)  NOT NULL DEFAULT 'enrolled',
+    theory_passed_at    TIMESTAMPTZ,
+    practical_passed_at TIMESTAMPTZ,
+    license_id          UUID REFERENCES driver_licenses (id),
+    creator_id          UUID         NOT NULL,
+    created_at          TIMESTAMPTZ  NOT NULL DEFAULT now(),
+    updated_at          TIMESTAMPTZ  NOT NULL DEFAULT now()
+);
+
+-- a learner is enrolled once per class until licensed or withdrawn
+CREATE UNIQUE INDEX IF NOT EXISTS idx_school_enrollments_open
+    ON school_enrollments (identity_no, license_type) WHERE status = 'enrolled';
+
+CREATE INDEX IF NOT EXISTS idx_school_enrollments_school ON school_enrollments (school_id, status, created_at DESC);
+
+-- Theory and practical exam sessions held at an agency
+CREATE TABLE IF NOT EXISTS exam_sessions (
+    id           UUID PRIMARY KEY,
+    agency_id    UUID         NOT NULL REFERENCES gov_agencies (id),
+    agency_name  VARCHAR(255) NOT NULL DEFAULT '',
+    kind         VARCHAR(20)  NOT NULL,
+    license_type VARCHAR(10)  NOT NULL,
+    starts_at    TIMESTAMPTZ  NOT NULL,
+    location     VARCHAR(255) NOT NULL DEFAULT '',
+    capacity     INT          NOT NULL CHECK (capacity > 0),
+    booked       INT          NOT NULL DEFAULT 0,
+    status       VARCHAR(20)  NOT NULL DEFAULT 'scheduled',
+    creator_id   UUID         NOT NULL,
+    created_at   TIMESTAMPTZ  NOT NULL DEFAULT now(),
+    updated_at   TIMESTAMPTZ  NOT NULL DEFAULT now(),
+    CHECK (booked >= 0 AND booked <= capacity)
+);
+
+CREATE INDEX IF NOT EXISTS idx_exam_sessions_agency_start ON exam_sessions (agency_id, starts_at);
+CREATE INDEX IF NOT EXISTS idx_exam_sessions_type_start ON exam_sessions (license_type, kind, starts_at);
+
+-- Seats of candidates in exam sessions and their results
+CREATE TABLE IF NOT EXISTS exam_bookings (
+    id            UUID PRIMARY KEY,
+    session_id    UUID        NOT NULL REFERENCES exam_sessions (id),
+    enrollment_id UUID        NOT NULL REFERENCES school_enrollments (id),
+    kind          VARCHAR(20) NOT NULL,
+    status        VARCHAR(20) NOT NULL DEFAULT 'booked',
+    score         INT,
+    note          TEXT        NOT NULL DEFAULT '',
+    examiner_id   UUID,
+    recorded_at   TIMESTAMPTZ,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- a candidate holds one seat per exam kind at a time
+CREATE UNIQUE INDEX IF NOT EXISTS idx_exam_bookings_open
+    ON exam_bookings (enrollment_id, kind) WHERE status = 'booked';
+
+CREATE INDEX IF NOT EXISTS idx_exam_bookings_session ON exam_bookings (session_id, status);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS appointments;
+
+DROP TABLE IF EXISTS appointment_slots;
+
+DROP TABLE IF EXISTS agency_services;
@@ -0,0 +1,63 @@
+-- Services an agency books appointments for
+CREATE TABLE IF NOT EXISTS agency_services (
+    id               UUID PRIMARY KEY,
+    agency_id        UUID         NOT NULL REFERENCES gov_agencies (id),
+    kind             VARCHAR(30)  NOT NULL,
+    name             VARCHAR(255) NOT NULL,
+    duration_minutes INT          NOT NULL CHECK (duration_minutes > 0),
+    creator_id       UUID         NOT NULL,
+    created_at       TIMESTAMPTZ  NOT NULL DEFAULT now(),
+    updated_at       TIMESTAMPTZ  NOT NULL DEFAULT now(),
+    active           BOOLEAN      NOT NULL DEFAULT true
+);
+
+CREATE INDEX IF NOT EXISTS idx_agency_services_agency ON agency_services (agency_id) WHERE active = true;
+
+-- Time slots of a service, booked up to their capacity
+CREATE TABLE IF NOT EXISTS appointment_slots (
+    id         UUID PRIMARY KEY,
+    service_id UUID        NOT NULL REFERENCES agency_services (id),
+    agency_id  UUID        NOT NULL REFERENCES gov_agencies (id),
+    starts_at  TIMESTAMPTZ NOT NULL,
+    ends_at    TIMESTAMPTZ NOT NULL,
+    capacity   INT         NOT NULL CHECK (capacity > 0),
+    booked     INT         NOT NULL DEFAULT 0,
+    status     VARCHAR(20) NOT NULL DEFAULT 'open',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK (booked >= 0 AND booked <= capacity),
+    CHECK (ends_at > starts_at)
+);
+
+-- publishing a calendar twice keeps the slots already published
+CREATE UNIQUE INDEX IF NOT EXISTS idx_appointment_slots_service_start ON appointment_slots (service_id, starts_at);
+
+-- Appointments of citizens
+CREATE TABLE IF NOT EXISTS appointments (
+    id            UUID PRIMARY KEY,
+    slot_id       UUID         NOT NULL REFERENCES appointment_slots (id),
+    service_id    UUID         NOT NULL REFERENCES agency_services (id),
+    agency_id     UUID         NOT NULL REFERENCES gov_agencies (id),
+    agency_name   VARCHAR(255) NOT NULL DEFAULT '',
+    service_kind  VARCHAR(30)  NOT NULL,
+    service_name  VARCHAR(255) NOT NULL DEFAULT '',
+    starts_at     TIMESTAMPTZ  NOT NULL,
+    user_id       UUID         NOT NULL,
+    identity_no   VARCHAR(20)  NOT NULL DEFAULT '',
+    full_name     VARCHAR(255) NOT NULL DEFAULT '',
+    note          TEXT         NOT NULL DEFAULT '',
+    status        VARCHAR(20)  NOT NULL DEFAULT 'booked',
+    reminded_at   TIMESTAMPTZ,
+    checked_in_at TIMESTAMPTZ,
+    checked_in_by UUID,
+    created_at    TIMESTAMPTZ  NOT NULL DEFAULT now(),
+    updated_at    TIMESTAMPTZ  NOT NULL DEFAULT now()
+);
+
+-- a citizen holds one appointment per service at a time
+CREATE UNIQUE INDEX IF NOT EXISTS idx_appointments_open
+    ON appointments (user_id, service_id) WHERE status = 'booked';
+
+CREATE INDEX IF NOT EXISTS idx_appointments_user ON appointments (user_id, starts_at DESC);
+CREATE INDEX IF NOT EXISTS idx_appointments_agency_start ON appointments (agency_id, starts_at);
+CREATE INDEX IF NOT EXISTS idx_appointments_reminder ON appointments (starts_at) WHERE status = 'booked' AND reminded_at IS NULL;
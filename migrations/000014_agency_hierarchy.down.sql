@@ -0,0 +1,49 @@
+DROP MATERIALIZED VIEW IF EXISTS mv_violation_stats;
+
+CREATE MATERIALIZED VIEW mv_violation_stats AS
+SELECT
+    COALESCE(status, '')                                    AS status,
+    expiry_date IS NOT NULL AND expiry_date < CURRENT_DATE  AS is_overdue,
+    COUNT(*)                                                AS count,
+    COALESCE(SUM(fine_amount), 0)::bigint                   AS fine_amount
+FROM traffic_violations
+WHERE active = true
+GROUP BY 1, 2;
+
+CREATE UNIQUE INDEX IF NOT EXISTS mv_violation_stats_key
+    ON mv_violation_stats (status, is_overdue);
+
+DROP MATERIALIZED VIEW IF EXISTS mv_vehicle_stats;
+
+CREATE MATERIALIZED VIEW mv_vehicle_stats AS
+SELECT
+    COALESCE(type_vehicle, '')                                        AS type_vehicle,
+    COALESCE(brand, '')                                               AS brand,
+    type_vehicle IS NOT NULL AND type_vehicle NOT ILIKE ANY (ARRAY[
+        '%xe máy%', '%xe mô tô%', '%xe gắn máy%',
+        '%xe đạp%', '%xe đạp điện%', '%xe máy điện%'
+    ])                                                                AS is_motor,
+    expiry_date IS NOT NULL AND expiry_date >= CURRENT_DATE           AS is_valid,
+    expiry_date IS NOT NULL AND expiry_date < CURRENT_DATE            AS is_expired,
+    expiry_date IS NULL OR registration_date IS NULL                  AS is_pending,
+    COUNT(*)                                                          AS count
+FROM vehicle_registration
+WHERE active = true
+GROUP BY 1, 2, 3, 4, 5, 6;
+
+CREATE UNIQUE INDEX IF NOT EXISTS mv_vehicle_stats_key
+    ON mv_vehicle_stats (type_vehicle, brand, is_motor, is_valid, is_expired, is_pending);
+
+DROP FUNCTION IF EXISTS f_vehicle_province(UUID, TEXT);
+
+DROP FUNCTION IF EXISTS f_in_jurisdiction(UUID, TEXT, TEXT);
+
+DROP FUNCTION IF EXISTS f_jurisdiction(UUID);
+
+ALTER TABLE users DROP COLUMN IF EXISTS agency_id;
+
+DROP TABLE IF EXISTS agency_jurisdictions;
+
+ALTER TABLE gov_agencies
+    DROP COLUMN IF EXISTS level,
+    DROP COLUMN IF EXISTS parent_id;
@@ -0,0 +1,98 @@
+-- Agencies form a tree, ministry > provincial department > district office
+ALTER TABLE gov_agencies
+    ADD COLUMN IF NOT EXISTS parent_id UUID REFERENCES gov_agencies (id),
+    ADD COLUMN IF NOT EXISTS level     VARCHAR(20) NOT NULL DEFAULT 'district';
+
+CREATE INDEX IF NOT EXISTS idx_gov_agencies_parent ON gov_agencies (parent_id) WHERE active = true;
+
+-- Areas an agency is responsible for, an empty district covers the whole province
+CREATE TABLE IF NOT EXISTS agency_jurisdictions (
+    id         UUID PRIMARY KEY,
+    agency_id  UUID         NOT NULL REFERENCES gov_agencies (id),
+    province   VARCHAR(100) NOT NULL,
+    district   VARCHAR(100) NOT NULL DEFAULT '',
+    creator_id UUID         NOT NULL,
+    created_at TIMESTAMPTZ  NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_agency_jurisdictions_area ON agency_jurisdictions (agency_id, province, district);
+
+-- Agency staff, their reads and writes are bounded by the jurisdiction of the agency
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS agency_id UUID REFERENCES gov_agencies (id);
+
+-- Areas of the agency and of every active agency below it
+CREATE OR REPLACE FUNCTION f_jurisdiction(agency UUID)
+RETURNS TABLE (province VARCHAR, district VARCHAR)
+LANGUAGE sql STABLE AS $$
+    WITH RECURSIVE tree AS (
+        SELECT id FROM gov_agencies WHERE id = agency AND active = true
+        UNION
+        SELECT a.id FROM gov_agencies a JOIN tree t ON a.parent_id = t.id WHERE a.active = true
+    )
+    SELECT DISTINCT j.province, j.district
+    FROM agency_jurisdictions j
+    JOIN tree t ON t.id = j.agency_id
+$$;
+
+-- Whether a record of the area is visible to the agency, no agency and ministries see every area.
+-- Records without a district (licenses, vehicles) are matched on the province alone
+CREATE OR REPLACE FUNCTION f_in_jurisdiction(agency UUID, area_province TEXT, area_district TEXT DEFAULT NULL)
+RETURNS BOOLEAN
+LANGUAGE sql STABLE AS $$
+    SELECT agency IS NULL
+        OR EXISTS (SELECT 1 FROM gov_agencies WHERE id = agency AND level = 'ministry' AND active = true)
+        OR EXISTS (
+            SELECT 1
+            FROM f_jurisdiction(agency) j
+            WHERE j.province = area_province
+              AND (j.district = '' OR area_district IS NULL OR j.district = area_district)
+        )
+$$;
+
+-- Vehicles have no area of their own, the city of the owner's license is used, the registration place otherwise
+CREATE OR REPLACE FUNCTION f_vehicle_province(owner UUID, place TEXT)
+RETURNS TEXT
+LANGUAGE sql STABLE AS $$
+    SELECT COALESCE((SELECT owner_city FROM driver_licenses WHERE id = owner AND active = true), place)
+$$;
+
+-- Stats views carry the area so that they can be scoped like the tables
+DROP MATERIALIZED VIEW IF EXISTS mv_vehicle_stats;
+
+CREATE MATERIALIZED VIEW mv_vehicle_stats AS
+SELECT
+    COALESCE(f_vehicle_province(owner_id, registration_place), '')    AS province,
+    COALESCE(type_vehicle, '')                                        AS type_vehicle,
+    COALESCE(brand, '')                                               AS brand,
+    type_vehicle IS NOT NULL AND type_vehicle NOT ILIKE ANY (ARRAY[
+        '%xe máy%', '%xe mô tô%', '%xe gắn máy%',
+        '%xe đạp%', '%xe đạp điện%', '%xe máy điện%'
+    ])                                                                AS is_motor,
+    expiry_date IS NOT NULL AND expiry_date >= CURRENT_DATE           AS is_valid,
+    expiry_date IS NOT NULL AND expiry_date < CURRENT_DATE            AS is_expired,
+    expiry_date IS NULL OR registration_date IS NULL                  AS is_pending,
+    COUNT(*)                                                          AS count
+FROM vehicle_registration
+WHERE active = true
+GROUP BY 1, 2, 3, 4, 5, 6, 7;
+
+CREATE UNIQUE INDEX IF NOT EXISTS mv_vehicle_stats_key
+    ON mv_vehicle_stats (province, type_vehicle, brand, is_motor, is_valid, is_expired, is_pending);
+
+DROP MATERIALIZED VIEW IF EXISTS mv_violation_stats;
+
+CREATE MATERIALIZED VIEW mv_violation_stats AS
+SELECT
+    COALESCE(province, '')                                  AS province,
+    COALESCE(district, '')                                  AS district,
+    COALESCE(status, '')                                    AS status,
+    expiry_date IS NOT NULL AND expiry_date < CURRENT_DATE  AS is_overdue,
+    COUNT(*)                                                AS count,
+    COALESCE(SUM(fine_amount), 0)::bigint                   AS fine_amount
+FROM traffic_violations
+WHERE active = true
+GROUP BY 1, 2, 3, 4;
+
+CREATE UNIQUE INDEX IF NOT EXISTS mv_violation_stats_key
+    ON mv_violation_stats (province, district, status, is_overdue);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS agency_staff_actions;
+DROP TABLE IF EXISTS agency_staff;
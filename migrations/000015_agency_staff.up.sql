@@ -0,0 +1,46 @@
+-- Staff of the agencies, invited by an admin or a head/manager of the agency and active once accepted.
+-- users.agency_id follows the active membership of the user
+CREATE TABLE IF NOT EXISTS agency_staff (
+    id             UUID PRIMARY KEY,
+    agency_id      UUID         NOT NULL REFERENCES gov_agencies (id),
+    user_id        UUID         NOT NULL REFERENCES users (id),
+    position       VARCHAR(100) NOT NULL DEFAULT '',
+    role           VARCHAR(20)  NOT NULL DEFAULT 'clerk',   -- head, manager, clerk
+    status         VARCHAR(20)  NOT NULL DEFAULT 'invited', -- invited, active, declined, inactive
+    inviter_id     UUID         NOT NULL,
+    invited_at     TIMESTAMPTZ  NOT NULL DEFAULT now(),
+    joined_at      TIMESTAMPTZ,
+    deactivated_by UUID,
+    deactivated_at TIMESTAMPTZ,
+    version        INT          NOT NULL DEFAULT 1,
+    created_at     TIMESTAMPTZ  NOT NULL DEFAULT now(),
+    updated_at     TIMESTAMPTZ  NOT NULL DEFAULT now()
+);
+
+-- a user works for one agency at a time and has one open invitation per agency
+CREATE UNIQUE INDEX IF NOT EXISTS uq_agency_staff_active_user ON agency_staff (user_id) WHERE status = 'active';
+CREATE UNIQUE INDEX IF NOT EXISTS uq_agency_staff_open ON agency_staff (agency_id, user_id) WHERE status IN ('invited', 'active');
+CREATE INDEX IF NOT EXISTS idx_agency_staff_agency ON agency_staff (agency_id, status);
+
+-- Users attached to an agency before memberships become its active clerks
+INSERT INTO agency_staff (id, agency_id, user_id, status, inviter_id, invited_at, joined_at, created_at, updated_at)
+SELECT gen_random_uuid(), u.agency_id, u.id, 'active', u.id, now(), now(), now(), now()
+FROM users u
+WHERE u.agency_id IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+-- Writes made by the staff, the staff member acting on behalf of the agency
+CREATE TABLE IF NOT EXISTS agency_staff_actions (
+    id          UUID PRIMARY KEY,
+    agency_id   UUID         NOT NULL REFERENCES gov_agencies (id),
+    staff_id    UUID         NOT NULL REFERENCES agency_staff (id),
+    user_id     UUID         NOT NULL,
+    action      VARCHAR(200) NOT NULL, -- method and route, e.g. PUT /v1/api/licenses/:id
+    resource_id VARCHAR(100) NOT NULL DEFAULT '',
+    status      INT          NOT NULL,
+    request_id  VARCHAR(100) NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_agency_staff_actions_agency ON agency_staff_actions (agency_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_agency_staff_actions_staff ON agency_staff_actions (staff_id, created_at DESC);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS blockchain_anchors;
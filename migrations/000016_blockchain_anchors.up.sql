@@ -0,0 +1,26 @@
+-- Outbox of the records written to the chain by the anchoring worker. Records with on_blockchain = false are
+-- queued, their hash is submitted and the record is confirmed once the transaction has enough confirmations
+CREATE TABLE IF NOT EXISTS blockchain_anchors (
+    id              UUID PRIMARY KEY,
+    record_type     VARCHAR(20)  NOT NULL,                   -- license, vehicle
+    record_id       UUID         NOT NULL,
+    record_hash     VARCHAR(66)  NOT NULL DEFAULT '',
+    status          VARCHAR(20)  NOT NULL DEFAULT 'pending', -- pending, submitted, confirmed, failed
+    tx_hash         VARCHAR(100) NOT NULL DEFAULT '',
+    block_number    BIGINT,
+    confirmations   INT          NOT NULL DEFAULT 0,
+    attempts        INT          NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ  NOT NULL DEFAULT now(),
+    last_error      TEXT         NOT NULL DEFAULT '',
+    submitted_at    TIMESTAMPTZ,
+    confirmed_at    TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ  NOT NULL DEFAULT now(),
+    updated_at      TIMESTAMPTZ  NOT NULL DEFAULT now()
+);
+
+-- one open anchor per record, failed anchors stay open until retried
+CREATE UNIQUE INDEX IF NOT EXISTS uq_blockchain_anchors_open
+    ON blockchain_anchors (record_type, record_id) WHERE status IN ('pending', 'submitted', 'failed');
+CREATE INDEX IF NOT EXISTS idx_blockchain_anchors_due
+    ON blockchain_anchors (status, next_attempt_at) WHERE status IN ('pending', 'submitted');
+CREATE INDEX IF NOT EXISTS idx_blockchain_anchors_record ON blockchain_anchors (record_type, record_id);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS integrity_flags;
+DROP INDEX IF EXISTS idx_blockchain_anchors_confirmed;
+ALTER TABLE blockchain_anchors DROP COLUMN IF EXISTS reanchor;
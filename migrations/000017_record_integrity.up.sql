@@ -0,0 +1,25 @@
+-- Re-anchoring writes a record already on the chain again, after it changed on purpose
+ALTER TABLE blockchain_anchors ADD COLUMN IF NOT EXISTS reanchor BOOLEAN NOT NULL DEFAULT false;
+
+CREATE INDEX IF NOT EXISTS idx_blockchain_anchors_confirmed
+    ON blockchain_anchors (record_type, record_id, confirmed_at DESC) WHERE status = 'confirmed';
+
+-- Records whose canonical hash differs from the hash of their last confirmed anchor, kept by the integrity scanner.
+-- A flag is resolved when the hashes match again, after the record is re-anchored or the edit is reverted
+CREATE TABLE IF NOT EXISTS integrity_flags (
+    id            UUID PRIMARY KEY,
+    record_type   VARCHAR(20) NOT NULL,                -- license, vehicle
+    record_id     UUID        NOT NULL,
+    anchor_id     UUID        NOT NULL REFERENCES blockchain_anchors (id),
+    anchored_hash VARCHAR(66) NOT NULL,
+    current_hash  VARCHAR(66) NOT NULL,
+    status        VARCHAR(20) NOT NULL DEFAULT 'open', -- open, resolved
+    detected_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    checked_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    resolved_at   TIMESTAMPTZ
+);
+
+-- one open flag per record
+CREATE UNIQUE INDEX IF NOT EXISTS uq_integrity_flags_open
+    ON integrity_flags (record_type, record_id) WHERE status = 'open';
+CREATE INDEX IF NOT EXISTS idx_integrity_flags_status ON integrity_flags (status, detected_at DESC);
@@ -0,0 +1 @@
+ALTER TABLE blockchain_anchors DROP COLUMN IF EXISTS hash_version;
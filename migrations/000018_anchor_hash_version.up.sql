@@ -0,0 +1,3 @@
+-- Version of the canonical form record_hash was computed with. Anchors written before the canonical form
+-- hashed the full record, they stay at 0 and are re-anchored by the integrity scanner instead of being flagged
+ALTER TABLE blockchain_anchors ADD COLUMN IF NOT EXISTS hash_version INT NOT NULL DEFAULT 0;
@@ -0,0 +1 @@
+ALTER TABLE school_enrollments DROP COLUMN IF EXISTS issuing_at;
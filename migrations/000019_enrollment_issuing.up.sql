@@ -0,0 +1,2 @@
+-- Lease on an enrollment while its license is issued, so one caller issues it
+ALTER TABLE school_enrollments ADD COLUMN IF NOT EXISTS issuing_at TIMESTAMPTZ;
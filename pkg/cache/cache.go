@@ -0,0 +1,48 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Key-value cache holding JSON encoded values
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	DeletePrefix(ctx context.Context, prefix string) error
+}
+
+// Cached value with the time its data was produced
+type entry[T any] struct {
+	Value T         `json:"value"`
+	AsOf  time.Time `json:"as_of"`
+}
+
+// Return cached value for key or load, store and return it.
+// asOf is called after a successful load and stamps how fresh the loaded data is.
+func ReadThrough[T any](ctx context.Context, c Cache, key string, ttl time.Duration, asOf func() time.Time, load func(ctx context.Context) (T, error)) (T, time.Time, error) {
+	var e entry[T]
+	if raw, ok, err := c.Get(ctx, key); err == nil && ok {
+		if err = json.Unmarshal(raw, &e); err == nil {
+			return e.Value, e.AsOf, nil
+		}
+	}
+
+	value, err := load(ctx)
+	if err != nil {
+		return value, time.Time{}, err
+	}
+
+	e = entry[T]{Value: value, AsOf: asOf()}
+	raw, err := json.Marshal(e)
+	if err != nil {
+		return value, e.AsOf, errors.Wrap(err, "cache.ReadThrough.Marshal")
+	}
+	// a failing cache must not fail the read
+	_ = c.Set(ctx, key, raw, ttl)
+
+	return value, e.AsOf, nil
+}
@@ -0,0 +1,90 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultLRUSize = 1024
+
+type lruItem struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// In-process LRU cache with per key TTL
+type lruCache struct {
+	mu    sync.Mutex
+	size  int
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+// LRU cache constructor, size <= 0 uses the default size
+func NewLRUCache(size int) Cache {
+	if size <= 0 {
+		size = defaultLRUSize
+	}
+	return &lruCache{size: size, ll: list.New(), items: make(map[string]*list.Element)}
+}
+
+func (l *lruCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	el, ok := l.items[key]
+	if !ok {
+		return nil, false, nil
+	}
+	item := el.Value.(*lruItem)
+	if !item.expiresAt.IsZero() && time.Now().After(item.expiresAt) {
+		l.remove(el)
+		return nil, false, nil
+	}
+	l.ll.MoveToFront(el)
+	return item.value, true, nil
+}
+
+func (l *lruCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl)
+	}
+
+	if el, ok := l.items[key]; ok {
+		item := el.Value.(*lruItem)
+		item.value, item.expiresAt = value, expiresAt
+		l.ll.MoveToFront(el)
+		return nil
+	}
+
+	l.items[key] = l.ll.PushFront(&lruItem{key: key, value: value, expiresAt: expiresAt})
+	for l.ll.Len() > l.size {
+		l.remove(l.ll.Back())
+	}
+	return nil
+}
+
+func (l *lruCache) DeletePrefix(ctx context.Context, prefix string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for key, el := range l.items {
+		if strings.HasPrefix(key, prefix) {
+			l.remove(el)
+		}
+	}
+	return nil
+}
+
+func (l *lruCache) remove(el *list.Element) {
+	l.ll.Remove(el)
+	delete(l.items, el.Value.(*lruItem).key)
+}
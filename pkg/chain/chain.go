@@ -0,0 +1,51 @@
+package chain
+
+import (
+	"context"
+	"errors"
+)
+
+// Drivers of the chain client. The simulated chain is for tests, the server refuses it
+const (
+	DriverEVM       = "evm"
+	DriverSimulated = "simulated"
+)
+
+// ErrTxNotFound is returned by Receipt while the transaction is not mined, or was dropped
+var ErrTxNotFound = errors.New("chain: transaction not found")
+
+// Errors of Verify, the transaction does not anchor the record
+var (
+	ErrTxFailed      = errors.New("chain: transaction failed")
+	ErrWrongContract = errors.New("chain: transaction does not target the registry contract")
+	ErrHashMismatch  = errors.New("chain: anchored hash does not match the record")
+)
+
+// Hash of a record written to the chain
+type Anchor struct {
+	RecordType string // license, vehicle
+	RecordID   string
+	Hash       string // hex encoded sha256 of the canonical form of the record, never of the full record
+}
+
+// Outcome of a mined transaction
+type Receipt struct {
+	TxHash        string
+	BlockNumber   uint64
+	Confirmations uint64 // blocks mined on top of the transaction block, itself included
+	Success       bool   // false when the transaction reverted
+}
+
+// ChainClient writes record hashes to the chain and reads the receipts of the transactions
+type ChainClient interface {
+	// Send the anchor, returns the hash of the transaction
+	Submit(ctx context.Context, a *Anchor) (string, error)
+	// Receipt of a sent transaction, ErrTxNotFound while it is pending
+	Receipt(ctx context.Context, txHash string) (*Receipt, error)
+}
+
+// Verifier checks a transaction sent by a client anchors the record
+type Verifier interface {
+	// Nil when the transaction is mined, succeeded and wrote the anchor hash to the registry
+	Verify(ctx context.Context, txHash string, a *Anchor) error
+}
@@ -0,0 +1,275 @@
+package chain
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Transaction as returned by eth_getTransactionByHash, quantities are hex strings
+type EVMTransaction struct {
+	Hash        string  `json:"hash"`
+	From        string  `json:"from"`
+	To          string  `json:"to"`
+	BlockNumber *string `json:"blockNumber"` // nil while pending
+	Input       string  `json:"input"`
+}
+
+// Receipt as returned by eth_getTransactionReceipt
+type EVMReceipt struct {
+	TransactionHash string   `json:"transactionHash"`
+	BlockNumber     string   `json:"blockNumber"`
+	To              string   `json:"to"`
+	Status          string   `json:"status"` // 0x1 on success, 0x0 when reverted
+	Logs            []EVMLog `json:"logs"`
+}
+
+// Event emitted by a transaction
+type EVMLog struct {
+	Address string   `json:"address"`
+	Topics  []string `json:"topics"`
+	Data    string   `json:"data"`
+}
+
+// Transaction sent by eth_sendTransaction, signed by the node with the From account
+type EVMCall struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Data string `json:"data"`
+}
+
+// EVMClient talks to an EVM node over JSON-RPC
+type EVMClient interface {
+	// Transaction with the hash, ErrTxNotFound when the node does not know it
+	TransactionByHash(ctx context.Context, txHash string) (*EVMTransaction, error)
+	// Receipt of the transaction, ErrTxNotFound while it is pending
+	TransactionReceipt(ctx context.Context, txHash string) (*EVMReceipt, error)
+	// Number of the last mined block
+	BlockNumber(ctx context.Context) (uint64, error)
+	// Send a transaction from an account unlocked on the node, returns its hash
+	SendTransaction(ctx context.Context, call *EVMCall) (string, error)
+}
+
+// Error object of a JSON-RPC response
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("chain: rpc error %d: %s", e.Code, e.Message)
+}
+
+type rpcRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      uint64        `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+type rpcResponse struct {
+	ID     uint64          `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *RPCError       `json:"error"`
+}
+
+type rpcClient struct {
+	url    string
+	client *http.Client
+	nextID atomic.Uint64
+}
+
+// JSON-RPC client of the node at url, calls time out after timeout
+func NewRPCClient(url string, timeout time.Duration) EVMClient {
+	return &rpcClient{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+func (c *rpcClient) TransactionByHash(ctx context.Context, txHash string) (*EVMTransaction, error) {
+	var tx *EVMTransaction
+	if err := c.call(ctx, "eth_getTransactionByHash", &tx, txHash); err != nil {
+		return nil, err
+	}
+	if tx == nil {
+		return nil, ErrTxNotFound
+	}
+	return tx, nil
+}
+
+func (c *rpcClient) TransactionReceipt(ctx context.Context, txHash string) (*EVMReceipt, error) {
+	var r *EVMReceipt
+	if err := c.call(ctx, "eth_getTransactionReceipt", &r, txHash); err != nil {
+		return nil, err
+	}
+	if r == nil {
+		return nil, ErrTxNotFound
+	}
+	return r, nil
+}
+
+func (c *rpcClient) BlockNumber(ctx context.Context) (uint64, error) {
+	var n string
+	if err := c.call(ctx, "eth_blockNumber", &n); err != nil {
+		return 0, err
+	}
+	return parseQuantity(n)
+}
+
+func (c *rpcClient) SendTransaction(ctx context.Context, call *EVMCall) (string, error) {
+	var txHash string
+	if err := c.call(ctx, "eth_sendTransaction", &txHash, call); err != nil {
+		return "", err
+	}
+	if txHash == "" {
+		return "", fmt.Errorf("chain: eth_sendTransaction: empty transaction hash")
+	}
+	return txHash, nil
+}
+
+// Call the method, a null result leaves result untouched
+func (c *rpcClient) call(ctx context.Context, method string, result interface{}, params ...interface{}) error {
+	if params == nil {
+		params = []interface{}{}
+	}
+	body, err := json.Marshal(&rpcRequest{JSONRPC: "2.0", ID: c.nextID.Add(1), Method: method, Params: params})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("chain: %s: %w", method, err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("chain: %s: unexpected status %d", method, res.StatusCode)
+	}
+
+	var out rpcResponse
+	if err := json.NewDecoder(res.Body).Decode(&out); err != nil {
+		return fmt.Errorf("chain: %s: %w", method, err)
+	}
+	if out.Error != nil {
+		return out.Error
+	}
+	if len(out.Result) == 0 || string(out.Result) == "null" {
+		return nil
+	}
+	return json.Unmarshal(out.Result, result)
+}
+
+// Client writing anchors to the registry contract from an account unlocked on the node. The call data is
+// the 4 byte selector of the anchor method followed by the record hash as a 32 byte word
+type EVMSubmitter struct {
+	client   EVMClient
+	registry string
+	from     string
+	selector string
+}
+
+// Submitter calling the method with the selector on the registry contract, from the account from
+func NewEVMSubmitter(client EVMClient, registry, from, selector string) *EVMSubmitter {
+	return &EVMSubmitter{client: client, registry: registry, from: from, selector: strings.TrimPrefix(strings.ToLower(selector), "0x")}
+}
+
+func (s *EVMSubmitter) Submit(ctx context.Context, a *Anchor) (string, error) {
+	hash := strings.TrimPrefix(strings.ToLower(a.Hash), "0x")
+	if len(hash) != 64 {
+		return "", fmt.Errorf("chain: anchor hash must be 32 bytes, got %d hex digits", len(hash))
+	}
+	return s.client.SendTransaction(ctx, &EVMCall{From: s.from, To: s.registry, Data: "0x" + s.selector + hash})
+}
+
+func (s *EVMSubmitter) Receipt(ctx context.Context, txHash string) (*Receipt, error) {
+	r, err := s.client.TransactionReceipt(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+	block, err := parseQuantity(r.BlockNumber)
+	if err != nil {
+		return nil, err
+	}
+	head, err := s.client.BlockNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var confirmations uint64
+	if head >= block {
+		confirmations = head - block + 1
+	}
+	return &Receipt{TxHash: txHash, BlockNumber: block, Confirmations: confirmations, Success: r.Status == "0x1"}, nil
+}
+
+// Verifier of transactions sent to the registry contract. The anchor event has the topic eventTopic
+// and carries the record hash as the first 32 byte word of its data
+type EVMVerifier struct {
+	client     EVMClient
+	registry   string
+	eventTopic string
+}
+
+// Verifier of the registry contract at the registry address, any event of the registry matches when eventTopic is empty
+func NewEVMVerifier(client EVMClient, registry, eventTopic string) *EVMVerifier {
+	return &EVMVerifier{client: client, registry: registry, eventTopic: eventTopic}
+}
+
+func (v *EVMVerifier) Verify(ctx context.Context, txHash string, a *Anchor) error {
+	tx, err := v.client.TransactionByHash(ctx, txHash)
+	if err != nil {
+		return err
+	}
+	if !sameHex(tx.To, v.registry) {
+		return ErrWrongContract
+	}
+	if tx.BlockNumber == nil {
+		return ErrTxNotFound
+	}
+
+	receipt, err := v.client.TransactionReceipt(ctx, txHash)
+	if err != nil {
+		return err
+	}
+	if receipt.Status != "0x1" {
+		return ErrTxFailed
+	}
+
+	want := "0x" + strings.TrimPrefix(strings.ToLower(a.Hash), "0x")
+	for _, l := range receipt.Logs {
+		if !sameHex(l.Address, v.registry) {
+			continue
+		}
+		if v.eventTopic != "" && (len(l.Topics) == 0 || !sameHex(l.Topics[0], v.eventTopic)) {
+			continue
+		}
+		if data := strings.ToLower(l.Data); len(data) >= len(want) && data[:len(want)] == want {
+			return nil
+		}
+	}
+	return ErrHashMismatch
+}
+
+// Hex encoded quantity, e.g. 0x1b4
+func parseQuantity(q string) (uint64, error) {
+	n, err := strconv.ParseUint(strings.TrimPrefix(q, "0x"), 16, 64)
+	if err != nil {
+		return 0, fmt.Errorf("chain: bad quantity %q: %w", q, err)
+	}
+	return n, nil
+}
+
+// Hex strings equal ignoring case, addresses are checksummed by some nodes
+func sameHex(a, b string) bool {
+	return a != "" && strings.EqualFold(a, b)
+}
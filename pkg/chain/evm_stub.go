@@ -0,0 +1,100 @@
+package chain
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// JSON-RPC node answering eth_getTransactionByHash and eth_getTransactionReceipt from memory,
+// for tests and local development. Serve it with httptest.NewServer or http.ListenAndServe
+type StubRPCServer struct {
+	mu       sync.Mutex
+	txs      map[string]*EVMTransaction
+	receipts map[string]*EVMReceipt
+}
+
+func NewStubRPCServer() *StubRPCServer {
+	return &StubRPCServer{
+		txs:      make(map[string]*EVMTransaction),
+		receipts: make(map[string]*EVMReceipt),
+	}
+}
+
+// Add a transaction, a nil receipt leaves it pending
+func (s *StubRPCServer) AddTransaction(tx *EVMTransaction, receipt *EVMReceipt) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	hash := strings.ToLower(tx.Hash)
+	s.txs[hash] = tx
+	if receipt != nil {
+		s.receipts[hash] = receipt
+	}
+}
+
+// Transaction to the registry emitting the anchor event with the record hash, mined in block
+func (s *StubRPCServer) AddAnchorTransaction(txHash, registry, eventTopic, recordHash string, success bool) {
+	block := "0x1"
+	status := "0x0"
+	var logs []EVMLog
+	if success {
+		status = "0x1"
+		logs = []EVMLog{{
+			Address: registry,
+			Topics:  []string{eventTopic},
+			Data:    "0x" + strings.TrimPrefix(strings.ToLower(recordHash), "0x"),
+		}}
+	}
+	s.AddTransaction(
+		&EVMTransaction{Hash: txHash, To: registry, BlockNumber: &block},
+		&EVMReceipt{TransactionHash: txHash, BlockNumber: block, To: registry, Status: status, Logs: logs},
+	)
+}
+
+func (s *StubRPCServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+		Params []string        `json:"params"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeRPC(w, nil, nil, &RPCError{Code: -32700, Message: "parse error"})
+		return
+	}
+	if len(req.Params) == 0 {
+		writeRPC(w, req.ID, nil, &RPCError{Code: -32602, Message: "missing transaction hash"})
+		return
+	}
+	hash := strings.ToLower(req.Params[0])
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch req.Method {
+	case "eth_getTransactionByHash":
+		if tx, ok := s.txs[hash]; ok {
+			writeRPC(w, req.ID, tx, nil)
+			return
+		}
+	case "eth_getTransactionReceipt":
+		if receipt, ok := s.receipts[hash]; ok {
+			writeRPC(w, req.ID, receipt, nil)
+			return
+		}
+	default:
+		writeRPC(w, req.ID, nil, &RPCError{Code: -32601, Message: "method not found"})
+		return
+	}
+	writeRPC(w, req.ID, nil, nil)
+}
+
+func writeRPC(w http.ResponseWriter, id json.RawMessage, result interface{}, rpcErr *RPCError) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(struct {
+		JSONRPC string          `json:"jsonrpc"`
+		ID      json.RawMessage `json:"id"`
+		Result  interface{}     `json:"result"`
+		Error   *RPCError       `json:"error,omitempty"`
+	}{JSONRPC: "2.0", ID: id, Result: result, Error: rpcErr})
+}
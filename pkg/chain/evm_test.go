@@ -0,0 +1,73 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testRegistry = "0x5FbDB2315678afecb367f032d93F642f64180aa3"
+	testTopic    = "0x8f9a1a4d3a1c8c6e3b7e1b0d6d2b7c5b2a6f3e4d1c0b9a8f7e6d5c4b3a291807"
+	testHash     = "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+)
+
+func TestEVMVerifierVerify(t *testing.T) {
+	stub := NewStubRPCServer()
+	srv := httptest.NewServer(stub)
+	defer srv.Close()
+
+	otherContract := "0x0000000000000000000000000000000000000bad"
+	otherTopic := "0x" + strings.Repeat("ab", 32)
+	otherHash := strings.Repeat("11", 32)
+
+	stub.AddAnchorTransaction("0xa1", testRegistry, testTopic, testHash, true)
+	stub.AddAnchorTransaction("0xa2", testRegistry, testTopic, testHash, false)
+	stub.AddAnchorTransaction("0xa3", otherContract, testTopic, testHash, true)
+	stub.AddAnchorTransaction("0xa4", testRegistry, otherTopic, testHash, true)
+	stub.AddAnchorTransaction("0xa5", testRegistry, testTopic, otherHash, true)
+	stub.AddAnchorTransaction("0xa6", strings.ToLower(testRegistry), testTopic, "0x"+strings.ToUpper(testHash), true)
+	stub.AddTransaction(&EVMTransaction{Hash: "0xa7", To: testRegistry}, nil)
+
+	v := NewEVMVerifier(NewRPCClient(srv.URL, 5*time.Second), testRegistry, testTopic)
+
+	tests := []struct {
+		name string
+		tx   string
+		want error
+	}{
+		{"matching hash", "0xa1", nil},
+		{"checksummed address and upper case hash", "0xa6", nil},
+		{"missing transaction", "0xdead", ErrTxNotFound},
+		{"pending transaction", "0xa7", ErrTxNotFound},
+		{"failed receipt", "0xa2", ErrTxFailed},
+		{"wrong contract", "0xa3", ErrWrongContract},
+		{"wrong topic", "0xa4", ErrHashMismatch},
+		{"mismatched hash", "0xa5", ErrHashMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := v.Verify(context.Background(), tt.tx, &Anchor{RecordType: "license", RecordID: "1", Hash: testHash})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Verify(%s) = %v, want %v", tt.tx, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestEVMVerifierWithoutTopic(t *testing.T) {
+	stub := NewStubRPCServer()
+	srv := httptest.NewServer(stub)
+	defer srv.Close()
+
+	stub.AddAnchorTransaction("0xb1", testRegistry, "0x"+strings.Repeat("cd", 32), testHash, true)
+
+	// any event of the registry matches
+	v := NewEVMVerifier(NewRPCClient(srv.URL, 5*time.Second), testRegistry, "")
+	if err := v.Verify(context.Background(), "0xb1", &Anchor{Hash: "0x" + testHash}); err != nil {
+		t.Fatalf("Verify = %v, want nil", err)
+	}
+}
@@ -0,0 +1,142 @@
+package chain
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// In-memory chain for tests, it writes nothing to a real chain. A block is mined every blockTime, and on every Mine call.
+// Sent transactions are included in the next block
+type SimulatedChain struct {
+	mu        sync.Mutex
+	blockTime time.Duration
+	start     time.Time
+	mined     uint64
+	nonce     uint64
+	failures  int
+	txs       map[string]*simulatedTx
+}
+
+type simulatedTx struct {
+	anchor   Anchor
+	block    uint64
+	reverted bool
+}
+
+// Chain mining a block every blockTime, blocks are only mined by Mine when blockTime is 0
+func NewSimulatedChain(blockTime time.Duration) *SimulatedChain {
+	return &SimulatedChain{
+		blockTime: blockTime,
+		start:     time.Now(),
+		txs:       make(map[string]*simulatedTx),
+	}
+}
+
+func (c *SimulatedChain) Submit(ctx context.Context, a *Anchor) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.failures > 0 {
+		c.failures--
+		return "", errors.New("chain: simulated submit failure")
+	}
+
+	c.nonce++
+	sum := sha256.Sum256([]byte(a.RecordType + ":" + a.RecordID + ":" + a.Hash + ":" + strconv.FormatUint(c.nonce, 10)))
+	txHash := "0x" + hex.EncodeToString(sum[:])
+	c.txs[txHash] = &simulatedTx{anchor: *a, block: c.head() + 1}
+	return txHash, nil
+}
+
+func (c *SimulatedChain) Receipt(ctx context.Context, txHash string) (*Receipt, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	tx, ok := c.txs[txHash]
+	head := c.head()
+	if !ok || head < tx.block {
+		return nil, ErrTxNotFound
+	}
+	return &Receipt{
+		TxHash:        txHash,
+		BlockNumber:   tx.block,
+		Confirmations: head - tx.block + 1,
+		Success:       !tx.reverted,
+	}, nil
+}
+
+// Mined transaction that wrote the anchor, the simulated chain has no contracts
+func (c *SimulatedChain) Verify(ctx context.Context, txHash string, a *Anchor) error {
+	receipt, err := c.Receipt(ctx, txHash)
+	if err != nil {
+		return err
+	}
+	if !receipt.Success {
+		return ErrTxFailed
+	}
+	sent, _ := c.Transaction(txHash)
+	if sent.RecordType != a.RecordType || sent.RecordID != a.RecordID || !strings.EqualFold(sent.Hash, a.Hash) {
+		return ErrHashMismatch
+	}
+	return nil
+}
+
+// Mine n blocks now
+func (c *SimulatedChain) Mine(n uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.mined += n
+}
+
+// Fail the next n Submit calls
+func (c *SimulatedChain) FailSubmits(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.failures = n
+}
+
+// Make the transaction revert, its receipt reports a failure
+func (c *SimulatedChain) Revert(txHash string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	tx, ok := c.txs[txHash]
+	if ok {
+		tx.reverted = true
+	}
+	return ok
+}
+
+// Anchor sent by the transaction
+func (c *SimulatedChain) Transaction(txHash string) (*Anchor, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	tx, ok := c.txs[txHash]
+	if !ok {
+		return nil, false
+	}
+	a := tx.anchor
+	return &a, true
+}
+
+// Number of the last mined block
+func (c *SimulatedChain) head() uint64 {
+	h := c.mined
+	if c.blockTime > 0 {
+		h += uint64(time.Since(c.start) / c.blockTime)
+	}
+	return h
+}
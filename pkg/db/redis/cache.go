@@ -0,0 +1,64 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/adohong4/driving-license/pkg/cache"
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+const scanBatch = 200
+
+// Distributed cache backed by redis
+type redisCache struct {
+	client *redis.Client
+	prefix string
+}
+
+// Redis cache constructor, every key is stored under prefix
+func NewRedisCache(client *redis.Client, prefix string) cache.Cache {
+	return &redisCache{client: client, prefix: prefix}
+}
+
+func (r *redisCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	raw, err := r.client.Get(ctx, r.prefix+key).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, false, nil
+		}
+		return nil, false, errors.Wrap(err, "redisCache.Get")
+	}
+	return raw, true, nil
+}
+
+func (r *redisCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if err := r.client.Set(ctx, r.prefix+key, value, ttl).Err(); err != nil {
+		return errors.Wrap(err, "redisCache.Set")
+	}
+	return nil
+}
+
+func (r *redisCache) DeletePrefix(ctx context.Context, prefix string) error {
+	iter := r.client.Scan(ctx, 0, r.prefix+prefix+"*", scanBatch).Iterator()
+	keys := make([]string, 0, scanBatch)
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+		if len(keys) == scanBatch {
+			if err := r.client.Del(ctx, keys...).Err(); err != nil {
+				return errors.Wrap(err, "redisCache.DeletePrefix.Del")
+			}
+			keys = keys[:0]
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return errors.Wrap(err, "redisCache.DeletePrefix.Scan")
+	}
+	if len(keys) > 0 {
+		if err := r.client.Del(ctx, keys...).Err(); err != nil {
+			return errors.Wrap(err, "redisCache.DeletePrefix.Del")
+		}
+	}
+	return nil
+}
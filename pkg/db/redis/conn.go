@@ -1 +1,27 @@
 package redis
+
+import (
+	"time"
+
+	"github.com/adohong4/driving-license/config"
+	"github.com/redis/go-redis/v9"
+)
+
+// Returns new redis client
+func NewRedisClient(cfg *config.Config) *redis.Client {
+	redisHost := cfg.Redis.RedisAddr
+	if redisHost == "" {
+		redisHost = ":6379"
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr:         redisHost,
+		MinIdleConns: cfg.Redis.MinIdleConns,
+		PoolSize:     cfg.Redis.PoolSize,
+		PoolTimeout:  time.Duration(cfg.Redis.PoolTimeout) * time.Second,
+		Password:     cfg.Redis.Password,
+		DB:           cfg.Redis.DB,
+	})
+
+	return client
+}
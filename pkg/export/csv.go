@@ -0,0 +1,67 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+// Byte order mark so Excel opens UTF-8 (Vietnamese) text correctly
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+const csvFlushEvery = 500
+
+type csvWriter struct {
+	w       io.Writer
+	cw      *csv.Writer
+	started bool
+	rows    int
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	return &csvWriter{w: w, cw: csv.NewWriter(w)}
+}
+
+func (c *csvWriter) WriteRow(row []string) error {
+	if !c.started {
+		c.started = true
+		if _, err := c.w.Write(utf8BOM); err != nil {
+			return err
+		}
+	}
+
+	safe := make([]string, len(row))
+	for i, v := range row {
+		safe[i] = escapeFormula(v)
+	}
+	if err := c.cw.Write(safe); err != nil {
+		return err
+	}
+
+	c.rows++
+	if c.rows%csvFlushEvery == 0 {
+		c.cw.Flush()
+		return c.cw.Error()
+	}
+	return nil
+}
+
+func (c *csvWriter) Close() error {
+	c.cw.Flush()
+	return c.cw.Error()
+}
+
+// Prevent spreadsheet formula injection from user supplied values
+func escapeFormula(v string) string {
+	if v == "" {
+		return v
+	}
+	switch v[0] {
+	case '=', '+', '@', '\t', '\r':
+		return "'" + v
+	case '-':
+		if len(v) > 1 && (v[1] < '0' || v[1] > '9') {
+			return "'" + v
+		}
+	}
+	return v
+}
@@ -0,0 +1,138 @@
+package export
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"github.com/adohong4/driving-license/pkg/i18n"
+	"github.com/pkg/errors"
+)
+
+// Export file formats
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// Header languages
+const (
+	LangVI = i18n.VI
+	LangEN = i18n.EN
+)
+
+const (
+	ContentTypeCSV  = "text/csv; charset=utf-8"
+	ContentTypeXLSX = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported export format, use csv or xlsx")
+
+// Exported column with localized headers
+type Column struct {
+	Key string
+	VI  string
+	EN  string
+}
+
+// Localized header row
+func Headers(columns []Column, lang string) []string {
+	headers := make([]string, len(columns))
+	for i, col := range columns {
+		headers[i] = col.VI
+		if lang == LangEN {
+			headers[i] = col.EN
+		}
+	}
+	return headers
+}
+
+// Rows to export, Rows streams every matching row to fn
+type Source struct {
+	Name    string
+	Columns []Column
+	Count   func(ctx context.Context) (int, error)
+	Rows    func(ctx context.Context, fn func(row []string) error) error
+}
+
+// Streaming spreadsheet writer
+type RowWriter interface {
+	WriteRow(row []string) error
+	Close() error
+}
+
+// Create writer of the given format
+func NewRowWriter(format string, w io.Writer) (RowWriter, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w), nil
+	case FormatXLSX:
+		return newXLSXWriter(w)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+// Write header and every source row to w, returns number of data rows
+func Write(ctx context.Context, src *Source, format, lang string, w io.Writer) (int, error) {
+	rw, err := NewRowWriter(format, w)
+	if err != nil {
+		return 0, err
+	}
+
+	if err = rw.WriteRow(Headers(src.Columns, lang)); err != nil {
+		return 0, errors.Wrap(err, "export.Write.Header")
+	}
+
+	count := 0
+	err = src.Rows(ctx, func(row []string) error {
+		count++
+		return rw.WriteRow(row)
+	})
+	if err != nil {
+		return count, errors.Wrap(err, "export.Write.Rows")
+	}
+
+	if err = rw.Close(); err != nil {
+		return count, errors.Wrap(err, "export.Write.Close")
+	}
+	return count, nil
+}
+
+// Parse format name, empty when unknown
+func ParseFormat(v string) string {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case FormatCSV:
+		return FormatCSV
+	case FormatXLSX, "excel":
+		return FormatXLSX
+	}
+	return ""
+}
+
+// Format from an Accept header, empty when none matches
+func FormatFromAccept(accept string) string {
+	switch {
+	case strings.Contains(accept, "spreadsheetml"), strings.Contains(accept, "application/vnd.ms-excel"):
+		return FormatXLSX
+	case strings.Contains(accept, "text/csv"):
+		return FormatCSV
+	}
+	return ""
+}
+
+// Header language from an Accept-Language header, Vietnamese by default
+func LangFromAcceptLanguage(v string) string {
+	return i18n.Match(v)
+}
+
+func ContentType(format string) string {
+	if format == FormatXLSX {
+		return ContentTypeXLSX
+	}
+	return ContentTypeCSV
+}
+
+func FileName(name, format string) string {
+	return name + "." + format
+}
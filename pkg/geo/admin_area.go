@@ -0,0 +1,43 @@
+package geo
+
+import (
+	"strings"
+)
+
+// Administrative prefixes stripped from province / district names
+var adminPrefixes = []string{
+	"thành phố trực thuộc trung ương",
+	"thành phố",
+	"tp.",
+	"tp ",
+	"tỉnh",
+	"quận",
+	"huyện",
+	"thị xã",
+	"tx.",
+	"q.",
+	"h.",
+}
+
+// Normalize province or district name: "TP. Hồ Chí Minh" -> "Hồ Chí Minh", "quận  1" -> "1"
+func NormalizeAdminArea(name string) string {
+	s := strings.Join(strings.Fields(name), " ")
+	lower := strings.ToLower(s)
+	for _, p := range adminPrefixes {
+		if strings.HasPrefix(lower, p) {
+			s = strings.TrimSpace(s[len(p):])
+			break
+		}
+	}
+	if s == "" {
+		return ""
+	}
+
+	words := strings.Fields(s)
+	for i, w := range words {
+		r := []rune(strings.ToLower(w))
+		r[0] = []rune(strings.ToUpper(string(r[0])))[0]
+		words[i] = string(r)
+	}
+	return strings.Join(words, " ")
+}
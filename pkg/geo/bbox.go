@@ -0,0 +1,85 @@
+package geo
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Bounding box in WGS84 degrees
+type BoundingBox struct {
+	MinLat float64 `json:"min_lat"`
+	MinLng float64 `json:"min_lng"`
+	MaxLat float64 `json:"max_lat"`
+	MaxLng float64 `json:"max_lng"`
+}
+
+// Parse bounding box from "minLng,minLat,maxLng,maxLat" (GeoJSON bbox order)
+func ParseBoundingBox(s string) (BoundingBox, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		return BoundingBox{}, errors.New("bbox must be minLng,minLat,maxLng,maxLat")
+	}
+
+	var v [4]float64
+	for i, p := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return BoundingBox{}, errors.New("bbox contains an invalid number")
+		}
+		v[i] = f
+	}
+
+	box := BoundingBox{MinLng: v[0], MinLat: v[1], MaxLng: v[2], MaxLat: v[3]}
+	if err := box.Validate(); err != nil {
+		return BoundingBox{}, err
+	}
+	return box, nil
+}
+
+// Validate bounding box coordinates
+func (b BoundingBox) Validate() error {
+	if !ValidLatitude(b.MinLat) || !ValidLatitude(b.MaxLat) {
+		return errors.New("bbox latitude out of range")
+	}
+	if !ValidLongitude(b.MinLng) || !ValidLongitude(b.MaxLng) {
+		return errors.New("bbox longitude out of range")
+	}
+	if b.MinLat >= b.MaxLat || b.MinLng >= b.MaxLng {
+		return errors.New("bbox min must be lower than max")
+	}
+	return nil
+}
+
+// Smallest grid cell in degrees, about 11 m
+const MinCellSize = 1e-4
+
+// Number of grid cells covering the box for the given cell size, as a float so tiny cells
+// cannot overflow. +Inf when the cell size is below MinCellSize or not a number
+func (b BoundingBox) CellCount(cellSize float64) float64 {
+	if !(cellSize >= MinCellSize) || math.IsInf(cellSize, 0) {
+		return math.Inf(1)
+	}
+	rows := math.Ceil((b.MaxLat - b.MinLat) / cellSize)
+	cols := math.Ceil((b.MaxLng - b.MinLng) / cellSize)
+	return rows * cols
+}
+
+// Bounding box of a grid cell by its integer index
+func GridCell(latIdx, lngIdx int, cellSize float64) BoundingBox {
+	return BoundingBox{
+		MinLat: float64(latIdx) * cellSize,
+		MinLng: float64(lngIdx) * cellSize,
+		MaxLat: float64(latIdx+1) * cellSize,
+		MaxLng: float64(lngIdx+1) * cellSize,
+	}
+}
+
+func ValidLatitude(lat float64) bool {
+	return lat >= -90 && lat <= 90
+}
+
+func ValidLongitude(lng float64) bool {
+	return lng >= -180 && lng <= 180
+}
@@ -0,0 +1,62 @@
+package geo
+
+const (
+	TypeFeatureCollection = "FeatureCollection"
+	TypeFeature           = "Feature"
+	TypePoint             = "Point"
+	TypePolygon           = "Polygon"
+)
+
+// GeoJSON feature collection (RFC 7946)
+type FeatureCollection struct {
+	Type     string     `json:"type"`
+	Features []*Feature `json:"features"`
+}
+
+// GeoJSON feature
+type Feature struct {
+	Type       string                 `json:"type"`
+	Geometry   *Geometry              `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// GeoJSON geometry, coordinates are [longitude, latitude]
+type Geometry struct {
+	Type        string      `json:"type"`
+	Coordinates interface{} `json:"coordinates"`
+}
+
+// New empty feature collection
+func NewFeatureCollection() *FeatureCollection {
+	return &FeatureCollection{Type: TypeFeatureCollection, Features: make([]*Feature, 0)}
+}
+
+// Append feature into collection
+func (fc *FeatureCollection) Add(f *Feature) {
+	fc.Features = append(fc.Features, f)
+}
+
+// New point feature
+func NewPointFeature(lat, lng float64, props map[string]interface{}) *Feature {
+	return &Feature{
+		Type:       TypeFeature,
+		Geometry:   &Geometry{Type: TypePoint, Coordinates: []float64{lng, lat}},
+		Properties: props,
+	}
+}
+
+// New rectangle polygon feature from a bounding box
+func NewBoxFeature(box BoundingBox, props map[string]interface{}) *Feature {
+	ring := [][]float64{
+		{box.MinLng, box.MinLat},
+		{box.MaxLng, box.MinLat},
+		{box.MaxLng, box.MaxLat},
+		{box.MinLng, box.MaxLat},
+		{box.MinLng, box.MinLat},
+	}
+	return &Feature{
+		Type:       TypeFeature,
+		Geometry:   &Geometry{Type: TypePolygon, Coordinates: [][][]float64{ring}},
+		Properties: props,
+	}
+}
@@ -23,6 +23,9 @@ const (
 	ErrUnauthorized             = "Unauthorized"
 	ErrForbidden                = "Forbidden"
 	ErrBadQueryParams           = "Invalid query params"
+	ErrTransferBlocked          = "Vehicle transfer blocked"
+	ErrOutOfJurisdiction        = "Out of jurisdiction"
+	ErrTxNotVerified            = "Blockchain transaction not verified"
 )
 
 var (
@@ -44,6 +47,7 @@ var (
 	InvalidJWTClaims      = errors.New("Invalid JWT claims")
 	NotAllowedImageHeader = errors.New("Not allowed image header")
 	NoCookie              = errors.New("not found cookie header")
+	OutOfJurisdiction     = errors.New(ErrOutOfJurisdiction)
 )
 
 // Rest error interface
@@ -55,9 +59,36 @@ type RestErr interface {
 
 // Rest error struct
 type RestError struct {
-	ErrStatus int         `json:"status,omitempty"`
-	ErrError  string      `json:"error,omitempty"`
-	ErrCauses interface{} `json:"-"`
+	ErrStatus int          `json:"status,omitempty"`
+	ErrError  string       `json:"error,omitempty"`
+	ErrFields []FieldError `json:"fields,omitempty"`
+	ErrCauses interface{}  `json:"-"`
+}
+
+// Validation failure of a single request field, Field is the json name and Rule the failed rule
+type FieldError struct {
+	Field   string            `json:"field"`
+	Rule    string            `json:"rule,omitempty"`
+	Message string            `json:"message"`
+	Params  map[string]string `json:"-"`
+}
+
+// Struct validation error with a message per failed field
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		msgs = append(msgs, f.Field+": "+f.Message)
+	}
+	return "validation failed: " + strings.Join(msgs, "; ")
+}
+
+// New Validation Error
+func NewValidationError(fields []FieldError) error {
+	return &ValidationError{Fields: fields}
 }
 
 // Error  Error() interface method
@@ -102,11 +133,12 @@ func NewRestErrorFromBytes(bytes []byte) (RestErr, error) {
 	return apiErr, nil
 }
 
-// New Bad Request Error
+// New Bad Request Error, field errors of a validation cause are kept in the response
 func NewBadRequestError(causes interface{}) RestErr {
 	return RestError{
 		ErrStatus: http.StatusBadRequest,
 		ErrError:  BadRequest.Error(),
+		ErrFields: validationFields(causes),
 		ErrCauses: causes,
 	}
 }
@@ -150,9 +182,16 @@ func NewInternalServerError(causes interface{}) RestErr {
 
 // Parser of error string messages returns RestError
 func ParseErrors(err error) RestErr {
+	var restErr RestError
 	switch {
+	case errors.As(err, &restErr) && len(restErr.ErrFields) > 0:
+		return restErr
+	case validationFields(err) != nil:
+		return NewBadRequestError(err)
 	case errors.Is(err, sql.ErrNoRows):
 		return NewRestError(http.StatusNotFound, NotFound.Error(), err)
+	case errors.Is(err, OutOfJurisdiction):
+		return NewRestError(http.StatusForbidden, ErrOutOfJurisdiction, err)
 	case errors.Is(err, context.DeadlineExceeded):
 		return NewRestError(http.StatusRequestTimeout, RequestTimeoutError.Error(), err)
 	case strings.Contains(err.Error(), "SQLSTATE"):
@@ -177,6 +216,14 @@ func ParseErrors(err error) RestErr {
 	}
 }
 
+func validationFields(causes interface{}) []FieldError {
+	var ve *ValidationError
+	if err, ok := causes.(error); ok && errors.As(err, &ve) {
+		return ve.Fields
+	}
+	return nil
+}
+
 func parseSqlErrors(err error) RestErr {
 	if strings.Contains(err.Error(), "23505") {
 		return NewRestError(http.StatusBadRequest, ExistsEmailError.Error(), err)
@@ -197,7 +244,8 @@ func parseValidatorError(err error) RestErr {
 	return NewRestError(http.StatusBadRequest, BadRequest.Error(), err)
 }
 
-// Error response
+// Error response, the status and the problem details of the error
 func ErrorResponse(err error) (int, interface{}) {
-	return ParseErrors(err).Status(), ParseErrors(err)
+	restErr := ParseErrors(err)
+	return restErr.Status(), NewProblem(restErr)
 }
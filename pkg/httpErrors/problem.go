@@ -0,0 +1,156 @@
+package httpErrors
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/adohong4/driving-license/pkg/i18n"
+)
+
+// Content type of error responses
+const ContentTypeProblem = "application/problem+json"
+
+// Stable error codes of the problem responses, clients branch on these instead of the message
+const (
+	CodeBadRequest         = "bad_request"
+	CodeValidation         = "validation_failed"
+	CodeInvalidQueryParams = "invalid_query_params"
+	CodeUnauthorized       = "unauthorized"
+	CodeWrongCredentials   = "wrong_credentials"
+	CodeForbidden          = "forbidden"
+	CodeNotFound           = "not_found"
+	CodeUserNotFound       = "user_not_found"
+	CodeIdentityExists     = "identity_exists"
+	CodeUserAddressExists  = "user_address_exists"
+	CodeUserAddressLinked  = "user_address_linked"
+	CodeVehicleExists      = "vehicle_exists"
+	CodeLicenseExists      = "license_exists"
+	CodeDuplicate          = "duplicate"
+	CodeConflict           = "conflict"
+	CodeTransferBlocked    = "transfer_blocked"
+	CodeOutOfJurisdiction  = "out_of_jurisdiction"
+	CodeTxNotVerified      = "tx_not_verified"
+	CodeRequestTimeout     = "request_timeout"
+	CodePayloadTooLarge    = "payload_too_large"
+	CodeTooManyRequests    = "too_many_requests"
+	CodeInternal           = "internal_error"
+	CodeUnavailable        = "service_unavailable"
+)
+
+// Codes of the error messages of RestError, messages with a detail are matched by the part before ": "
+var messageCodes = map[string]string{
+	ErrBadRequest:               CodeBadRequest,
+	ErrBadQueryParams:           CodeInvalidQueryParams,
+	ErrTransferBlocked:          CodeTransferBlocked,
+	ErrOutOfJurisdiction:        CodeOutOfJurisdiction,
+	ErrTxNotVerified:            CodeTxNotVerified,
+	ErrIdentityAlreadyExists:    CodeIdentityExists,
+	ErrUserAddressAlreadyExists: CodeUserAddressExists,
+	ErrUserAddressLinked:        CodeUserAddressLinked,
+	ErrVehicleAlreadyExists:     CodeVehicleExists,
+	ErrLicenseAlreadyExists:     CodeLicenseExists,
+	ErrNoSuchUser:               CodeUserNotFound,
+	ErrWrongCredentials:         CodeWrongCredentials,
+	ErrNotFound:                 CodeNotFound,
+	ErrUnauthorized:             CodeUnauthorized,
+	ErrForbidden:                CodeForbidden,
+	ExistsEmailError.Error():    CodeDuplicate,
+	RequestTimeoutError.Error(): CodeRequestTimeout,
+	InternalServerError.Error(): CodeInternal,
+	PermissionDenied.Error():    CodeForbidden,
+}
+
+// Codes of statuses without a known message
+var statusCodes = map[int]string{
+	http.StatusBadRequest:            CodeBadRequest,
+	http.StatusUnauthorized:          CodeUnauthorized,
+	http.StatusForbidden:             CodeForbidden,
+	http.StatusNotFound:              CodeNotFound,
+	http.StatusRequestTimeout:        CodeRequestTimeout,
+	http.StatusConflict:              CodeConflict,
+	http.StatusRequestEntityTooLarge: CodePayloadTooLarge,
+	http.StatusUnprocessableEntity:   CodeValidation,
+	http.StatusTooManyRequests:       CodeTooManyRequests,
+	http.StatusInternalServerError:   CodeInternal,
+	http.StatusServiceUnavailable:    CodeUnavailable,
+}
+
+// RFC 7807 problem details, the body of every error response
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      string       `json:"code"`
+	Message   string       `json:"message"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// Problem of a rest error, instance and request id are set when the response is written
+func NewProblem(err RestErr) *Problem {
+	status := err.Status()
+	p := &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+	}
+
+	var message string
+	if restErr, ok := err.(RestError); ok {
+		message = restErr.ErrError
+		p.Errors = append([]FieldError(nil), restErr.ErrFields...)
+		// plain string causes are messages written for the client
+		if cause, ok := restErr.ErrCauses.(string); ok && status < http.StatusInternalServerError {
+			p.Detail = cause
+		}
+	}
+	if p.Detail == "" && status < http.StatusInternalServerError && !strings.EqualFold(message, http.StatusText(status)) {
+		p.Detail = message
+	}
+
+	p.Code = ErrorCode(status, message, len(p.Errors) > 0)
+	p.Localize(i18n.Default)
+	return p
+}
+
+// Localize the message and the field messages, codes without a catalog message keep the status title
+func (p *Problem) Localize(lang string) {
+	p.Message = p.Title
+	if key := "error." + p.Code; i18n.Has(key) {
+		p.Message = i18n.T(lang, key)
+	}
+	for i, f := range p.Errors {
+		if f.Rule != "" {
+			p.Errors[i].Message = FieldMessage(lang, f.Rule, f.Params)
+		}
+	}
+}
+
+// Localized message of a failed validation rule
+func FieldMessage(lang, rule string, params map[string]string) string {
+	if key := "validation." + rule; i18n.Has(key) {
+		return i18n.Render(lang, key, params)
+	}
+	return i18n.Render(lang, "validation.default", map[string]string{"rule": rule})
+}
+
+// Stable code of an error response
+func ErrorCode(status int, message string, hasFields bool) string {
+	if hasFields {
+		return CodeValidation
+	}
+	if code, ok := messageCodes[message]; ok {
+		return code
+	}
+	if i := strings.Index(message, ": "); i > 0 {
+		if code, ok := messageCodes[message[:i]]; ok {
+			return code
+		}
+	}
+	if code, ok := statusCodes[status]; ok {
+		return code
+	}
+	return strings.ToLower(strings.ReplaceAll(http.StatusText(status), " ", "_"))
+}
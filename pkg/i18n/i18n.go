@@ -0,0 +1,142 @@
+// Package i18n localizes API messages from the Vietnamese and English catalogs shipped in the binary.
+// Keys are grouped by prefix, error.<code>, validation.<rule>, <enum>.<value>, notification.<code>.title/content and dashboard.<code>.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Supported languages
+const (
+	VI = "vi"
+	EN = "en"
+)
+
+// Language of requests without a supported Accept-Language
+const Default = VI
+
+// Enum groups of Label
+const (
+	LicenseStatus   = "license_status"
+	ViolationStatus = "violation_status"
+	ViolationType   = "violation_type"
+	VehicleType     = "vehicle_type"
+	TransferStatus  = "transfer_status"
+	OwnershipReason = "ownership_reason"
+	VehicleEvent    = "vehicle_event"
+	DeregisterCause = "deregister_cause"
+
+	ApplicationKind   = "application_kind"
+	ApplicationStatus = "application_status"
+
+	EnrollmentStatus = "enrollment_status"
+	ExamKind         = "exam_kind"
+	BookingStatus    = "booking_status"
+
+	ServiceKind       = "service_kind"
+	AppointmentStatus = "appointment_status"
+)
+
+//go:embed locales/*.json
+var locales embed.FS
+
+var catalogs = map[string]map[string]string{}
+
+func init() {
+	for _, lang := range []string{VI, EN} {
+		b, err := locales.ReadFile("locales/" + lang + ".json")
+		if err != nil {
+			panic(fmt.Sprintf("i18n: catalog %s: %v", lang, err))
+		}
+		catalog := map[string]string{}
+		if err = json.Unmarshal(b, &catalog); err != nil {
+			panic(fmt.Sprintf("i18n: catalog %s: %v", lang, err))
+		}
+		catalogs[lang] = catalog
+	}
+}
+
+// Values implementing Localizer are localized before they are written as a response
+type Localizer interface {
+	Localize(lang string)
+}
+
+// Best supported language of an Accept-Language header such as "en-US,en;q=0.9,vi;q=0.8", Default when none matches
+func Match(acceptLanguage string) string {
+	type tag struct {
+		lang string
+		q    float64
+	}
+	var tags []tag
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		lang := strings.ToLower(strings.TrimSpace(fields[0]))
+		if i := strings.IndexByte(lang, '-'); i > 0 {
+			lang = lang[:i]
+		}
+		q := 1.0
+		for _, f := range fields[1:] {
+			if v, ok := strings.CutPrefix(strings.TrimSpace(f), "q="); ok {
+				if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		if _, ok := catalogs[lang]; ok && q > 0 {
+			tags = append(tags, tag{lang: lang, q: q})
+		}
+	}
+	if len(tags) == 0 {
+		return Default
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+	return tags[0].lang
+}
+
+// Message of a key, falls back to the Default catalog and then to the key itself
+func T(lang, key string) string {
+	if msg, ok := lookup(lang, key); ok {
+		return msg
+	}
+	return key
+}
+
+// Message of a key with its {name} placeholders replaced by params
+func Render(lang, key string, params map[string]string) string {
+	msg := T(lang, key)
+	for name, value := range params {
+		msg = strings.ReplaceAll(msg, "{"+name+"}", value)
+	}
+	return msg
+}
+
+// Whether a catalog has the key
+func Has(key string) bool {
+	_, ok := lookup(Default, key)
+	return ok
+}
+
+// Label of an enum value, e.g. Label(EN, LicenseStatus, "active"). Values without a label are returned as is
+func Label(lang, group, value string) string {
+	v := strings.ToLower(strings.TrimSpace(value))
+	if v == "" {
+		return value
+	}
+	if msg, ok := lookup(lang, group+"."+v); ok {
+		return msg
+	}
+	return value
+}
+
+func lookup(lang, key string) (string, bool) {
+	if msg, ok := catalogs[lang][key]; ok {
+		return msg, true
+	}
+	msg, ok := catalogs[Default][key]
+	return msg, ok
+}
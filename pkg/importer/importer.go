@@ -0,0 +1,239 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/adohong4/driving-license/pkg/export"
+	"github.com/adohong4/driving-license/pkg/httpErrors"
+	"github.com/go-playground/validator/v10"
+	"github.com/pkg/errors"
+)
+
+// Rows committed per transaction
+const DefaultBatchSize = 500
+
+var (
+	ErrEmptyFile      = errors.New("file has no header row")
+	ErrMissingColumns = errors.New("missing required columns")
+)
+
+// One data row keyed by column key
+type Row map[string]string
+
+func (r Row) Get(key string) string {
+	return strings.TrimSpace(r[key])
+}
+
+// Rows to import, Validate is called for every row and Commit once per batch of valid records
+type Target struct {
+	Name     string
+	Columns  []export.Column
+	Required []string
+	Validate func(ctx context.Context, row Row) (any, error)
+	Commit   func(ctx context.Context, records []any) error
+}
+
+// Validation error of a single field
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+func NewFieldError(field, message string) error {
+	return &FieldError{Field: field, Message: message}
+}
+
+// Error of one file row, Row counts from 1 with the header as row 1
+type RowError struct {
+	Row     int
+	Field   string
+	Message string
+}
+
+// Header of the error report
+var ErrorColumns = []export.Column{
+	{Key: "row", VI: "Dòng", EN: "Row"},
+	{Key: "field", VI: "Trường", EN: "Field"},
+	{Key: "message", VI: "Lỗi", EN: "Error"},
+}
+
+func (e RowError) Strings() []string {
+	return []string{fmt.Sprint(e.Row), e.Field, e.Message}
+}
+
+// Import counters
+type Result struct {
+	TotalRows    int
+	ValidRows    int
+	ImportedRows int
+	FailedRows   int
+}
+
+// Read every row, validate it and commit valid records in batches unless dryRun,
+// onError receives each rejected row
+func Run(ctx context.Context, t *Target, rr RowReader, dryRun bool, batchSize int, onError func(RowError) error) (Result, error) {
+	var res Result
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+
+	header, err := rr.ReadRow()
+	if err == io.EOF {
+		return res, ErrEmptyFile
+	}
+	if err != nil {
+		return res, errors.Wrap(err, "importer.Run.ReadHeader")
+	}
+
+	keys, err := mapHeader(t, header)
+	if err != nil {
+		return res, err
+	}
+
+	var (
+		batch     []any
+		batchRows []int
+	)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if !dryRun {
+			if err := t.Commit(ctx, batch); err != nil {
+				// the whole transaction is rolled back
+				for _, n := range batchRows {
+					res.FailedRows++
+					if err := onError(RowError{Row: n, Message: err.Error()}); err != nil {
+						return err
+					}
+				}
+				res.ValidRows -= len(batch)
+			} else {
+				res.ImportedRows += len(batch)
+			}
+		}
+		batch, batchRows = batch[:0], batchRows[:0]
+		return nil
+	}
+
+	line := 1
+	for {
+		if err = ctx.Err(); err != nil {
+			return res, err
+		}
+
+		values, err := rr.ReadRow()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			return res, errors.Wrapf(err, "importer.Run.ReadRow %d", line)
+		}
+		if isBlank(values) {
+			continue
+		}
+		res.TotalRows++
+
+		row := make(Row, len(keys))
+		for i, key := range keys {
+			if key != "" && i < len(values) {
+				row[key] = values[i]
+			}
+		}
+
+		record, err := t.Validate(ctx, row)
+		if err != nil {
+			res.FailedRows++
+			for _, re := range rowErrors(line, err) {
+				if err := onError(re); err != nil {
+					return res, err
+				}
+			}
+			continue
+		}
+
+		res.ValidRows++
+		batch, batchRows = append(batch, record), append(batchRows, line)
+		if len(batch) >= batchSize {
+			if err = flush(); err != nil {
+				return res, err
+			}
+		}
+	}
+
+	if err = flush(); err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+// Column key of every header cell, headers match the key or either localized name
+func mapHeader(t *Target, header []string) ([]string, error) {
+	keys := make([]string, len(header))
+	found := make(map[string]bool, len(header))
+	for i, h := range header {
+		h = strings.ToLower(strings.TrimSpace(h))
+		for _, col := range t.Columns {
+			if h == col.Key || h == strings.ToLower(col.VI) || h == strings.ToLower(col.EN) {
+				keys[i] = col.Key
+				found[col.Key] = true
+				break
+			}
+		}
+	}
+
+	var missing []string
+	for _, key := range t.Required {
+		if !found[key] {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, errors.Wrap(ErrMissingColumns, strings.Join(missing, ", "))
+	}
+	return keys, nil
+}
+
+func rowErrors(line int, err error) []RowError {
+	var fe *FieldError
+	if errors.As(err, &fe) {
+		return []RowError{{Row: line, Field: fe.Field, Message: fe.Message}}
+	}
+
+	var fields *httpErrors.ValidationError
+	if errors.As(err, &fields) {
+		out := make([]RowError, 0, len(fields.Fields))
+		for _, f := range fields.Fields {
+			out = append(out, RowError{Row: line, Field: f.Field, Message: f.Message})
+		}
+		return out
+	}
+
+	var ve validator.ValidationErrors
+	if errors.As(err, &ve) {
+		out := make([]RowError, 0, len(ve))
+		for _, e := range ve {
+			out = append(out, RowError{Row: line, Field: e.Field(), Message: "failed on the '" + e.Tag() + "' rule"})
+		}
+		return out
+	}
+
+	return []RowError{{Row: line, Message: err.Error()}}
+}
+
+func isBlank(values []string) bool {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,65 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"io"
+	"os"
+
+	"github.com/adohong4/driving-license/pkg/export"
+)
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// Streaming spreadsheet reader, ReadRow returns io.EOF after the last row
+type RowReader interface {
+	ReadRow() ([]string, error)
+	Close() error
+}
+
+// Open a stored upload of the given format
+func Open(path, format string) (RowReader, error) {
+	switch format {
+	case export.FormatCSV:
+		return openCSV(path)
+	case export.FormatXLSX:
+		return openXLSX(path)
+	default:
+		return nil, export.ErrUnsupportedFormat
+	}
+}
+
+type csvReader struct {
+	f *os.File
+	r *csv.Reader
+}
+
+func openCSV(path string) (RowReader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	br := bufio.NewReader(f)
+	if head, err := br.Peek(len(utf8BOM)); err == nil && bytes.Equal(head, utf8BOM) {
+		_, _ = br.Discard(len(utf8BOM))
+	}
+
+	r := csv.NewReader(br)
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	return &csvReader{f: f, r: r}, nil
+}
+
+func (c *csvReader) ReadRow() ([]string, error) {
+	row, err := c.r.Read()
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+	return row, err
+}
+
+func (c *csvReader) Close() error {
+	return c.f.Close()
+}
@@ -0,0 +1,31 @@
+package importer
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const DateLayout = "2006-01-02"
+
+var dateLayouts = []string{DateLayout, "02/01/2006", "2/1/2006", "02-01-2006", "2006/01/02", time.RFC3339, "2006-01-02 15:04:05"}
+
+// Excel stores dates as days since 1899-12-30
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// Parse a date cell written as YYYY-MM-DD, DD/MM/YYYY or an Excel serial number, returns YYYY-MM-DD
+func ParseDate(v string) (string, error) {
+	v = strings.TrimSpace(v)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, v); err == nil {
+			return t.Format(DateLayout), nil
+		}
+	}
+
+	if serial, err := strconv.ParseFloat(v, 64); err == nil && serial > 0 && serial < 2958466 {
+		return excelEpoch.AddDate(0, 0, int(serial)).Format(DateLayout), nil
+	}
+	return "", errors.Errorf("invalid date %q, use YYYY-MM-DD or DD/MM/YYYY", v)
+}
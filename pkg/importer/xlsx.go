@@ -0,0 +1,334 @@
+package importer
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var errNoWorksheet = errors.New("xlsx has no worksheet")
+
+// Streaming reader of the first worksheet, only cell values are read
+type xlsxReader struct {
+	zr      *zip.ReadCloser
+	sheet   io.ReadCloser
+	dec     *xml.Decoder
+	shared  []string
+	nextRow int // 1 based number of the next row to return
+	pending []string
+	gap     int // empty rows to return before pending
+}
+
+func openXLSX(name string) (RowReader, error) {
+	zr, err := zip.OpenReader(name)
+	if err != nil {
+		return nil, errors.Wrap(err, "xlsx.Open")
+	}
+
+	x := &xlsxReader{zr: zr, nextRow: 1}
+	if x.shared, err = readSharedStrings(zr); err != nil {
+		zr.Close()
+		return nil, err
+	}
+
+	sheet := firstSheet(zr)
+	if sheet == nil {
+		zr.Close()
+		return nil, errNoWorksheet
+	}
+	if x.sheet, err = sheet.Open(); err != nil {
+		zr.Close()
+		return nil, errors.Wrap(err, "xlsx.OpenSheet")
+	}
+	x.dec = xml.NewDecoder(x.sheet)
+	return x, nil
+}
+
+func (x *xlsxReader) ReadRow() ([]string, error) {
+	if x.gap > 0 {
+		x.gap--
+		x.nextRow++
+		return []string{}, nil
+	}
+	if x.pending != nil {
+		row := x.pending
+		x.pending = nil
+		x.nextRow++
+		return row, nil
+	}
+
+	for {
+		tok, err := x.dec.Token()
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "xlsx.ReadRow")
+		}
+
+		se, ok := tok.(xml.StartElement)
+		if !ok || se.Name.Local != "row" {
+			continue
+		}
+
+		num := x.nextRow
+		if v := attr(se, "r"); v != "" {
+			if n, err := strconv.Atoi(v); err == nil {
+				num = n
+			}
+		}
+		row, err := x.readCells()
+		if err != nil {
+			return nil, err
+		}
+
+		// rows without cells are left out of the sheet, keep numbering aligned
+		if num > x.nextRow {
+			x.gap = num - x.nextRow - 1
+			x.pending = row
+			x.nextRow++
+			return []string{}, nil
+		}
+		x.nextRow++
+		return row, nil
+	}
+}
+
+// Cells of the current <row>, missing cells are returned as empty strings
+func (x *xlsxReader) readCells() ([]string, error) {
+	var row []string
+	for {
+		tok, err := x.dec.Token()
+		if err != nil {
+			return nil, errors.Wrap(err, "xlsx.readCells")
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if t.Name.Local != "c" {
+				continue
+			}
+			col := len(row)
+			if ref := attr(t, "r"); ref != "" {
+				col = columnIndex(ref)
+			}
+			value, err := x.readCell(t)
+			if err != nil {
+				return nil, err
+			}
+			for len(row) <= col {
+				row = append(row, "")
+			}
+			row[col] = value
+		case xml.EndElement:
+			if t.Name.Local == "row" {
+				return row, nil
+			}
+		}
+	}
+}
+
+func (x *xlsxReader) readCell(c xml.StartElement) (string, error) {
+	var (
+		kind = attr(c, "t")
+		text strings.Builder
+		in   bool
+	)
+	for {
+		tok, err := x.dec.Token()
+		if err != nil {
+			return "", errors.Wrap(err, "xlsx.readCell")
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			in = t.Name.Local == "v" || t.Name.Local == "t"
+		case xml.CharData:
+			if in {
+				text.Write(t)
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "v", "t":
+				in = false
+			case "c":
+				value := text.String()
+				switch kind {
+				case "s":
+					i, err := strconv.Atoi(value)
+					if err != nil || i < 0 || i >= len(x.shared) {
+						return "", errors.Errorf("xlsx: bad shared string index %q", value)
+					}
+					return x.shared[i], nil
+				case "b":
+					if value == "1" {
+						return "TRUE", nil
+					}
+					return "FALSE", nil
+				}
+				return value, nil
+			}
+		}
+	}
+}
+
+func (x *xlsxReader) Close() error {
+	if x.sheet != nil {
+		x.sheet.Close()
+	}
+	return x.zr.Close()
+}
+
+func readSharedStrings(zr *zip.ReadCloser) ([]string, error) {
+	f := findFile(zr, "xl/sharedStrings.xml")
+	if f == nil {
+		return nil, nil
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, errors.Wrap(err, "xlsx.readSharedStrings")
+	}
+	defer rc.Close()
+
+	var (
+		out  []string
+		text strings.Builder
+		in   bool
+		dec  = xml.NewDecoder(rc)
+	)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return out, nil
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "xlsx.readSharedStrings")
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "si":
+				text.Reset()
+			case "t":
+				in = true
+			case "rPh": // phonetic hints are not part of the value
+				if err = dec.Skip(); err != nil {
+					return nil, errors.Wrap(err, "xlsx.readSharedStrings")
+				}
+			}
+		case xml.CharData:
+			if in {
+				text.Write(t)
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				in = false
+			case "si":
+				out = append(out, text.String())
+			}
+		}
+	}
+}
+
+// First worksheet in workbook order, falls back to the first sheet file by name
+func firstSheet(zr *zip.ReadCloser) *zip.File {
+	if target := firstSheetTarget(zr); target != "" {
+		if f := findFile(zr, target); f != nil {
+			return f
+		}
+	}
+
+	var sheets []*zip.File
+	for _, f := range zr.File {
+		if strings.HasPrefix(f.Name, "xl/worksheets/") && strings.HasSuffix(f.Name, ".xml") {
+			sheets = append(sheets, f)
+		}
+	}
+	if len(sheets) == 0 {
+		return nil
+	}
+	sort.Slice(sheets, func(i, j int) bool { return sheets[i].Name < sheets[j].Name })
+	return sheets[0]
+}
+
+func firstSheetTarget(zr *zip.ReadCloser) string {
+	var wb struct {
+		Sheets []struct {
+			RID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	var rels struct {
+		Rels []struct {
+			Id     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if !decodeFile(zr, "xl/workbook.xml", &wb) || len(wb.Sheets) == 0 {
+		return ""
+	}
+	if !decodeFile(zr, "xl/_rels/workbook.xml.rels", &rels) {
+		return ""
+	}
+
+	for _, r := range rels.Rels {
+		if r.Id != wb.Sheets[0].RID {
+			continue
+		}
+		if strings.HasPrefix(r.Target, "/") {
+			return strings.TrimPrefix(r.Target, "/")
+		}
+		return path.Join("xl", r.Target)
+	}
+	return ""
+}
+
+func decodeFile(zr *zip.ReadCloser, name string, v any) bool {
+	f := findFile(zr, name)
+	if f == nil {
+		return false
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return false
+	}
+	defer rc.Close()
+	return xml.NewDecoder(rc).Decode(v) == nil
+}
+
+func findFile(zr *zip.ReadCloser, name string) *zip.File {
+	for _, f := range zr.File {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+func attr(se xml.StartElement, name string) string {
+	for _, a := range se.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// Zero based column of a cell reference like "AB12"
+func columnIndex(ref string) int {
+	col := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		col = col*26 + int(r-'A'+1)
+	}
+	return col - 1
+}
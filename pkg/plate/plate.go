@@ -0,0 +1,115 @@
+// Package plate parses Vietnamese license plates into their canonical form,
+// e.g. "30a 12345", "30A-123.45" and "30A12345" are all "30A-123.45".
+package plate
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Plate classes, told by the series
+const (
+	ClassCivil           = "civil"
+	ClassDiplomatic      = "diplomatic"       // NG, diplomatic missions
+	ClassForeign         = "foreign"          // NN, foreign organisations and individuals
+	ClassInternational   = "international"    // QT, international organisations
+	ClassForeignInvested = "foreign_invested" // LD, foreign invested enterprises
+	ClassEconomic        = "economic"         // KT, enterprises of the army
+	ClassTrailer         = "trailer"          // R, trailers and semi-trailers
+	ClassTemporary       = "temporary"        // T, temporary plates
+	ClassMachinery       = "machinery"        // MK, special purpose machinery
+	ClassMilitary        = "military"         // two letter unit code without province, red plate
+)
+
+var ErrInvalid = errors.New("invalid license plate")
+
+var seriesClasses = map[string]string{
+	"NG": ClassDiplomatic,
+	"NN": ClassForeign,
+	"QT": ClassInternational,
+	"LD": ClassForeignInvested,
+	"KT": ClassEconomic,
+	"R":  ClassTrailer,
+	"T":  ClassTemporary,
+	"MK": ClassMachinery,
+}
+
+var (
+	civilRe    = regexp.MustCompile(`^(\d{2})([A-Z]{1,2})(\d?)(\d{4,5})$`)
+	militaryRe = regexp.MustCompile(`^([A-Z]{2})(\d{4,5})$`)
+	// a separator after the series digit of motorbike plates, e.g. "29-B1 123.45"
+	seriesDigitRe = regexp.MustCompile(`^\d{2}[\s.\-]*[A-Z]{1,2}\d[\s.\-]+\d`)
+)
+
+// Parsed plate
+type Plate struct {
+	Province string // two digit province code, empty on military plates
+	Series   string // series letters with the series digit of motorbikes, e.g. A, LD, B1
+	Number   string // 4 or 5 digits
+	Class    string
+}
+
+// Parse a plate written with any case, spaces, dots or dashes.
+// Without separators a letter-only series is preferred, "29B11234" is the car plate 29B-112.34.
+func Parse(s string) (*Plate, error) {
+	upper := strings.ToUpper(strings.TrimSpace(s))
+	compact := Compact(upper)
+
+	if m := militaryRe.FindStringSubmatch(compact); m != nil {
+		return &Plate{Series: m[1], Number: m[2], Class: ClassMilitary}, nil
+	}
+
+	m := civilRe.FindStringSubmatch(compact)
+	if m == nil {
+		return nil, errors.Wrapf(ErrInvalid, "%q", s)
+	}
+	province, letters, digit, number := m[1], m[2], m[3], m[4]
+	if code, _ := strconv.Atoi(province); code < 11 {
+		return nil, errors.Wrapf(ErrInvalid, "%q: unknown province code %s", s, province)
+	}
+
+	series := letters + digit
+	if digit != "" && len(number) == 4 && !seriesDigitRe.MatchString(upper) {
+		// no separator tells the series digit apart, read it as part of the number
+		series, number = letters, digit+number
+	}
+
+	class := ClassCivil
+	if c, ok := seriesClasses[letters]; ok {
+		class = c
+	}
+	return &Plate{Province: province, Series: series, Number: number, Class: class}, nil
+}
+
+// Canonical form, the province and series, a dash and the number with a dot before the last two of five digits
+func (p *Plate) String() string {
+	number := p.Number
+	if len(number) == 5 {
+		number = number[:3] + "." + number[3:]
+	}
+	return p.Province + p.Series + "-" + number
+}
+
+// Canonical form of a plate
+func Canonical(s string) (string, error) {
+	p, err := Parse(s)
+	if err != nil {
+		return "", err
+	}
+	return p.String(), nil
+}
+
+// Upper-cased letters and digits of a plate, compared by partial plate searches
+func Compact(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range strings.ToUpper(s) {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
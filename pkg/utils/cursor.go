@@ -0,0 +1,194 @@
+package utils
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adohong4/driving-license/pkg/httpErrors"
+	"github.com/jmoiron/sqlx"
+	"github.com/jmoiron/sqlx/reflectx"
+)
+
+// Reads sort key values of listed models by their db tag
+var cursorMapper = reflectx.NewMapperFunc("db", sqlx.NameMapper)
+
+// Opaque cursor, the sort key values of the last row of a page and the sort they belong to
+type cursorToken struct {
+	Sort   string   `json:"s"`
+	Values []string `json:"v"`
+}
+
+// Seek past the cursor, the list is sorted by the keyset fields of the sort and the id tiebreak
+func (lc *ListClause) setCursor(spec *ListSpec, q *PaginationQuery) error {
+	if spec.IDColumn == "" {
+		return httpErrors.NewRestError(http.StatusBadRequest,
+			fmt.Sprintf("%s: cursor pagination is not supported by this list", httpErrors.ErrBadQueryParams), nil)
+	}
+	keys := append(lc.keys, sortKey{name: "id", column: spec.IDColumn})
+	lc.cursor, lc.keys, lc.size, lc.withTotal = true, keys, q.GetSize(), q.WithTotal
+	if q.Cursor == "" {
+		return nil
+	}
+
+	values, err := decodeCursor(q.Cursor, sortSignature(keys))
+	if err != nil {
+		return err
+	}
+
+	// k1 > v1 OR (k1 = v1 AND (k2 > v2 OR (k2 = v2 AND ...))), built from the last key
+	params := make([]string, len(keys))
+	for i, v := range values {
+		lc.seekArgs = append(lc.seekArgs, v)
+		params[i] = fmt.Sprintf("$%d", lc.argN+len(lc.args)+len(lc.seekArgs))
+	}
+	var cond string
+	for i := len(keys) - 1; i >= 0; i-- {
+		op := ">"
+		if keys[i].desc {
+			op = "<"
+		}
+		seek := keys[i].column + " " + op + " " + params[i]
+		if cond != "" {
+			seek = "(" + seek + " OR (" + keys[i].column + " = " + params[i] + " AND " + cond + "))"
+		}
+		cond = seek
+	}
+	lc.seek = " AND " + cond
+	return nil
+}
+
+// Trim the extra row of a cursor page, returns the cursor of the next page or "" on the last one.
+// Pages read by offset are returned as is.
+func NextCursor[T any](lc *ListClause, items []T) ([]T, string, error) {
+	if !lc.cursor || len(items) <= lc.size {
+		return items, "", nil
+	}
+	items = items[:lc.size]
+	if len(items) == 0 {
+		return items, "", nil
+	}
+
+	last := reflect.Indirect(reflect.ValueOf(items[len(items)-1]))
+	values := make([]string, 0, len(lc.keys))
+	for _, k := range lc.keys {
+		fv := cursorMapper.FieldByName(last, k.name)
+		if !fv.IsValid() {
+			return nil, "", fmt.Errorf("NextCursor: %s has no %q field", last.Type(), k.name)
+		}
+		values = append(values, cursorValue(fv))
+	}
+
+	b, err := json.Marshal(cursorToken{Sort: sortSignature(lc.keys), Values: values})
+	if err != nil {
+		return nil, "", err
+	}
+	return items, base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Total of the filtered list, cursor pages skip the count unless with_total is set and then only estimate it
+func (lc *ListClause) Total(ctx context.Context, db sqlx.QueryerContext, countQuery, listQuery string, base ...interface{}) (int, error) {
+	var total int
+	if !lc.cursor {
+		err := sqlx.GetContext(ctx, db, &total, lc.Count(countQuery), lc.Args(base...)...)
+		return total, err
+	}
+	if !lc.withTotal {
+		return 0, nil
+	}
+
+	var plan []byte
+	if err := sqlx.GetContext(ctx, db, &plan, lc.Estimate(listQuery), lc.Args(base...)...); err != nil {
+		return 0, err
+	}
+	return RowEstimate(plan)
+}
+
+// Whether another page follows, told by the next cursor on cursor pages
+func (lc *ListClause) HasMore(pq *PaginationQuery, totalCount int, nextCursor string) bool {
+	if lc.cursor {
+		return nextCursor != ""
+	}
+	return GetHasMore(pq.GetPage(), totalCount, pq.GetSize())
+}
+
+// Whether the list can stop at an empty count, cursor pages read rows without counting them
+func (lc *ListClause) Empty(totalCount int) bool {
+	return !lc.cursor && totalCount == 0
+}
+
+// Query of the row estimate of the filtered list, read by RowEstimate
+func (lc *ListClause) Estimate(base string) string {
+	return "EXPLAIN (FORMAT JSON) " + base + lc.Where
+}
+
+// Approximate row count of the planner, avoids a COUNT(*) over large tables
+func RowEstimate(plan []byte) (int, error) {
+	var explain []struct {
+		Plan struct {
+			Rows float64 `json:"Plan Rows"`
+		} `json:"Plan"`
+	}
+	if err := json.Unmarshal(plan, &explain); err != nil {
+		return 0, err
+	}
+	if len(explain) == 0 {
+		return 0, nil
+	}
+	return int(explain[0].Plan.Rows), nil
+}
+
+func decodeCursor(cursor, sort string) ([]string, error) {
+	invalid := httpErrors.NewRestError(http.StatusBadRequest, fmt.Sprintf("%s: invalid cursor", httpErrors.ErrBadQueryParams), nil)
+
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, invalid
+	}
+	var token cursorToken
+	if err = json.Unmarshal(b, &token); err != nil {
+		return nil, invalid
+	}
+	if token.Sort != sort {
+		return nil, httpErrors.NewRestError(http.StatusBadRequest,
+			fmt.Sprintf("%s: cursor does not match sort %q", httpErrors.ErrBadQueryParams, sort), nil)
+	}
+	if len(token.Values) != len(strings.Split(sort, ",")) {
+		return nil, invalid
+	}
+	return token.Values, nil
+}
+
+func sortSignature(keys []sortKey) string {
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		if k.desc {
+			parts = append(parts, "-"+k.name)
+			continue
+		}
+		parts = append(parts, k.name)
+	}
+	return strings.Join(parts, ",")
+}
+
+// Text form of a sort key value, parsed back by postgres as the column type
+func cursorValue(v reflect.Value) string {
+	v = reflect.Indirect(v)
+	switch x := v.Interface().(type) {
+	case time.Time:
+		return x.Format(time.RFC3339Nano)
+	case fmt.Stringer:
+		return x.String()
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	}
+	return fmt.Sprint(v.Interface())
+}
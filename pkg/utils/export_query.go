@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/adohong4/driving-license/pkg/export"
+	"github.com/labstack/echo/v4"
+)
+
+// Params of the export itself, every other param is a list filter
+var exportParams = map[string]bool{"format": true, "lang": true, "async": true}
+
+// Export query params, Search, Filters and Sort hold the same filters and sort as the matching list/search endpoint
+type ExportQuery struct {
+	Format  string            `json:"format"`
+	Lang    string            `json:"lang"`
+	Search  string            `json:"search,omitempty"`
+	Sort    string            `json:"sort,omitempty"`
+	Filters map[string]string `json:"filters,omitempty"`
+	Async   bool              `json:"async,omitempty"`
+}
+
+// List query of the export, validated per list by ListClause like the list endpoint
+func (q *ExportQuery) ListQuery() *PaginationQuery {
+	return &PaginationQuery{Sort: q.Sort, Filters: q.Filters}
+}
+
+// Get export query from format/lang/async query params and the list filters and sort, falling back to Accept and Accept-Language headers
+func GetExportQueryFromCtx(c echo.Context, searchParam string) (*ExportQuery, error) {
+	q := &ExportQuery{
+		Search: strings.TrimSpace(c.QueryParam(searchParam)),
+		Sort:   c.QueryParam("sort"),
+	}
+	if q.Sort == "" {
+		q.Sort = c.QueryParam("orderBy")
+	}
+
+	params := c.QueryParams()
+	q.Filters = make(map[string]string, len(params))
+	for key, values := range params {
+		if exportParams[key] || paginationParams[key] || len(values) == 0 {
+			continue
+		}
+		q.Filters[key] = values[0]
+	}
+
+	if f := c.QueryParam("format"); f != "" {
+		q.Format = export.ParseFormat(f)
+	} else if q.Format = export.FormatFromAccept(c.Request().Header.Get(echo.HeaderAccept)); q.Format == "" {
+		q.Format = export.FormatCSV
+	}
+	if q.Format == "" {
+		return nil, export.ErrUnsupportedFormat
+	}
+
+	q.Lang = export.LangFromAcceptLanguage(c.Request().Header.Get("Accept-Language"))
+	if lang := c.QueryParam("lang"); lang != "" {
+		q.Lang = export.LangFromAcceptLanguage(lang)
+	}
+
+	if async := c.QueryParam("async"); async != "" {
+		v, err := strconv.ParseBool(async)
+		if err != nil {
+			return nil, err
+		}
+		q.Async = v
+	}
+	return q, nil
+}
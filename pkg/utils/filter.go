@@ -0,0 +1,348 @@
+package utils
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adohong4/driving-license/pkg/httpErrors"
+	"github.com/google/uuid"
+)
+
+// Filter param suffixes of range filters
+const (
+	suffixFrom = "_from"
+	suffixTo   = "_to"
+	suffixMin  = "_min"
+	suffixMax  = "_max"
+
+	maxFilterValues = 50
+)
+
+// How a whitelisted field is filtered
+type FilterType int
+
+const (
+	FilterExact  FilterType = iota // equality, comma separated values match any of them
+	FilterText                     // case-insensitive contains
+	FilterNumber                   // equality, <field>_min and <field>_max
+	FilterDate                     // <field>_from and <field>_to, a date-only _to includes the whole day
+	FilterBool                     // true or false
+	FilterUUID                     // equality
+)
+
+// Whitelisted list field, Column is the SQL expression it maps to.
+// Keyset fields are NOT NULL columns usable as cursor keys, their param name is the db tag of the listed model.
+type ListField struct {
+	Column   string
+	Type     FilterType
+	Sortable bool
+	Keyset   bool
+}
+
+// Filterable and sortable fields of one list, keyed by query param name
+type ListSpec struct {
+	Fields      map[string]ListField
+	DefaultSort string // sort expression applied after the requested one, e.g. "-updated_at,-created_at"
+	IDColumn    string // unique tiebreak of cursor pages, cursor pagination is disabled when empty
+}
+
+// Filter and sort clauses of a list query, appended to a base query ending in a WHERE condition
+type ListClause struct {
+	Where   string
+	OrderBy string
+	args    []interface{}
+	argN    int
+	keys    []sortKey
+
+	// cursor mode
+	cursor    bool
+	withTotal bool
+	seek      string
+	seekArgs  []interface{}
+	size      int
+}
+
+// Column of the ORDER BY clause
+type sortKey struct {
+	name   string // param name, "id" for the tiebreak
+	column string
+	desc   bool
+}
+
+// Build the clauses of the query filters and sort, argN is the number of args used by the base query.
+// Params listed in ignore are handled by the caller.
+func (q *PaginationQuery) ListClause(spec *ListSpec, argN int, ignore ...string) (*ListClause, error) {
+	lc := &ListClause{argN: argN}
+
+	names := make([]string, 0, len(q.Filters))
+	for key := range q.Filters {
+		names = append(names, key)
+	}
+	sort.Strings(names) // stable SQL text for identical queries
+
+	var where strings.Builder
+	for _, key := range names {
+		if contains(ignore, key) {
+			continue
+		}
+		value := strings.TrimSpace(q.Filters[key])
+		if value == "" {
+			continue
+		}
+
+		cond, err := lc.condition(spec, key, value)
+		if err != nil {
+			return nil, err
+		}
+		where.WriteString(" AND ")
+		where.WriteString(cond)
+	}
+	lc.Where = where.String()
+
+	var err error
+	if lc.keys, err = sortKeys(spec, q.Sort, q.CursorMode); err != nil {
+		return nil, err
+	}
+	if q.CursorMode {
+		if err = lc.setCursor(spec, q); err != nil {
+			return nil, err
+		}
+	}
+	lc.OrderBy = orderByClause(lc.keys)
+	return lc, nil
+}
+
+// Count query of the filtered list
+func (lc *ListClause) Count(base string) string {
+	return base + lc.Where
+}
+
+// Query of every row of the filtered list, in sort order
+func (lc *ListClause) All(base string) string {
+	return base + lc.Where + lc.OrderBy
+}
+
+// Page query of the filtered list, OFFSET and LIMIT are the last two args.
+// In cursor mode rows after the cursor are read and LIMIT is the last arg.
+func (lc *ListClause) Page(base string) string {
+	n := lc.argN + len(lc.args)
+	if lc.cursor {
+		return fmt.Sprintf("%s%s%s%s LIMIT $%d", base, lc.Where, lc.seek, lc.OrderBy, n+len(lc.seekArgs)+1)
+	}
+	return fmt.Sprintf("%s%s%s OFFSET $%d LIMIT $%d", base, lc.Where, lc.OrderBy, n+1, n+2)
+}
+
+// Args of the count query
+func (lc *ListClause) Args(base ...interface{}) []interface{} {
+	return append(append([]interface{}{}, base...), lc.args...)
+}
+
+// Args of the page query
+func (lc *ListClause) PageArgs(pq *PaginationQuery, base ...interface{}) []interface{} {
+	if lc.cursor {
+		// one more row tells whether another page follows
+		return append(append(lc.Args(base...), lc.seekArgs...), lc.size+1)
+	}
+	return append(lc.Args(base...), pq.GetOffset(), pq.GetLimit())
+}
+
+func (lc *ListClause) arg(v interface{}) string {
+	lc.args = append(lc.args, v)
+	return fmt.Sprintf("$%d", lc.argN+len(lc.args))
+}
+
+func (lc *ListClause) condition(spec *ListSpec, key, value string) (string, error) {
+	if f, ok := spec.Fields[key]; ok {
+		switch f.Type {
+		case FilterExact:
+			values := strings.Split(value, ",")
+			if len(values) > maxFilterValues {
+				return "", filterError(key, fmt.Sprintf("at most %d values", maxFilterValues))
+			}
+			if len(values) == 1 {
+				return f.Column + " = " + lc.arg(value), nil
+			}
+			params := make([]string, 0, len(values))
+			for _, v := range values {
+				params = append(params, lc.arg(strings.TrimSpace(v)))
+			}
+			return f.Column + " IN (" + strings.Join(params, ", ") + ")", nil
+		case FilterText:
+			return f.Column + " ILIKE '%' || " + lc.arg(value) + " || '%'", nil
+		case FilterNumber:
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return "", filterError(key, "must be a number")
+			}
+			return f.Column + " = " + lc.arg(n), nil
+		case FilterBool:
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return "", filterError(key, "must be true or false")
+			}
+			return f.Column + " = " + lc.arg(b), nil
+		case FilterUUID:
+			id, err := uuid.Parse(value)
+			if err != nil {
+				return "", filterError(key, "must be a UUID")
+			}
+			return f.Column + " = " + lc.arg(id), nil
+		case FilterDate:
+			return "", filterError(key, "use "+key+suffixFrom+" or "+key+suffixTo)
+		}
+	}
+
+	// range filters
+	for _, suffix := range []string{suffixFrom, suffixTo, suffixMin, suffixMax} {
+		name := strings.TrimSuffix(key, suffix)
+		if name == key {
+			continue
+		}
+		f, ok := spec.Fields[name]
+		if !ok {
+			f, ok = spec.Fields[name+"_at"] // created_from filters created_at
+		}
+		if !ok {
+			continue
+		}
+
+		switch {
+		case f.Type == FilterDate && (suffix == suffixFrom || suffix == suffixTo):
+			t, err := ParseTimeParam(value)
+			if err != nil {
+				return "", filterError(key, "must be YYYY-MM-DD or RFC3339")
+			}
+			if suffix == suffixFrom {
+				return f.Column + " >= " + lc.arg(*t), nil
+			}
+			if len(value) == len("2006-01-02") {
+				*t = t.Add(24 * time.Hour)
+			}
+			return f.Column + " < " + lc.arg(*t), nil
+		case f.Type == FilterNumber && (suffix == suffixMin || suffix == suffixMax):
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return "", filterError(key, "must be a number")
+			}
+			if suffix == suffixMin {
+				return f.Column + " >= " + lc.arg(n), nil
+			}
+			return f.Column + " <= " + lc.arg(n), nil
+		}
+	}
+
+	return "", httpErrors.NewRestError(http.StatusBadRequest,
+		fmt.Sprintf("%s: unknown filter %q, allowed: %s", httpErrors.ErrBadQueryParams, key, strings.Join(spec.filterNames(), ", ")), nil)
+}
+
+// Sort keys of the requested sort followed by the default sort, "-" prefix sorts descending.
+// Cursor pages only sort by keyset fields.
+func sortKeys(spec *ListSpec, requested string, keyset bool) ([]sortKey, error) {
+	var (
+		keys []sortKey
+		used = make(map[string]bool)
+	)
+
+	add := func(expr string, strict bool) error {
+		for _, item := range strings.Split(expr, ",") {
+			item = strings.TrimSpace(item)
+			if item == "" {
+				continue
+			}
+			desc := false
+			switch item[0] {
+			case '-':
+				desc, item = true, item[1:]
+			case '+':
+				item = item[1:]
+			}
+
+			f, ok := spec.Fields[item]
+			if !ok || !f.Sortable || (keyset && !f.Keyset) {
+				if strict {
+					return httpErrors.NewRestError(http.StatusBadRequest,
+						fmt.Sprintf("%s: cannot sort by %q, allowed: %s", httpErrors.ErrBadQueryParams, item, strings.Join(spec.sortNames(keyset), ", ")), nil)
+				}
+				continue
+			}
+			if used[item] {
+				continue
+			}
+			used[item] = true
+			keys = append(keys, sortKey{name: item, column: f.Column, desc: desc})
+		}
+		return nil
+	}
+
+	if err := add(requested, true); err != nil {
+		return nil, err
+	}
+	_ = add(spec.DefaultSort, false)
+	return keys, nil
+}
+
+// ORDER BY clause of the sort keys
+func orderByClause(keys []sortKey) string {
+	if len(keys) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		dir := "ASC"
+		if k.desc {
+			dir = "DESC"
+		}
+		parts = append(parts, k.column+" "+dir)
+	}
+	return " ORDER BY " + strings.Join(parts, ", ")
+}
+
+// Copy of the spec with another default sort, for lists of the same table with their own order
+func (s *ListSpec) WithDefaultSort(defaultSort string) *ListSpec {
+	return &ListSpec{Fields: s.Fields, DefaultSort: defaultSort, IDColumn: s.IDColumn}
+}
+
+func (s *ListSpec) filterNames() []string {
+	names := make([]string, 0, len(s.Fields))
+	for name, f := range s.Fields {
+		switch f.Type {
+		case FilterDate:
+			name = strings.TrimSuffix(name, "_at")
+			names = append(names, name+suffixFrom, name+suffixTo)
+		case FilterNumber:
+			names = append(names, name, name+suffixMin, name+suffixMax)
+		default:
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *ListSpec) sortNames(keyset bool) []string {
+	names := make([]string, 0, len(s.Fields))
+	for name, f := range s.Fields {
+		if f.Sortable && (!keyset || f.Keyset) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func filterError(key, msg string) error {
+	return httpErrors.NewRestError(http.StatusBadRequest, fmt.Sprintf("%s: %s %s", httpErrors.ErrBadQueryParams, key, msg), nil)
+}
+
+func contains(values []string, v string) bool {
+	for _, s := range values {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
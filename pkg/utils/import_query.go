@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/adohong4/driving-license/pkg/export"
+	"github.com/labstack/echo/v4"
+)
+
+// Import query params for an uploaded file
+type ImportQuery struct {
+	Format   string `json:"format"`
+	Lang     string `json:"lang"`
+	FileName string `json:"file_name"`
+	DryRun   bool   `json:"dry_run"`
+}
+
+// Get import query from format/lang/dry_run query params, format defaults to the file extension
+func GetImportQueryFromCtx(c echo.Context, fileName string) (*ImportQuery, error) {
+	q := &ImportQuery{
+		FileName: filepath.Base(fileName),
+		Format:   export.ParseFormat(strings.TrimPrefix(filepath.Ext(fileName), ".")),
+	}
+	if f := c.QueryParam("format"); f != "" {
+		q.Format = export.ParseFormat(f)
+	}
+	if q.Format == "" {
+		return nil, export.ErrUnsupportedFormat
+	}
+
+	q.Lang = export.LangFromAcceptLanguage(c.Request().Header.Get("Accept-Language"))
+	if lang := c.QueryParam("lang"); lang != "" {
+		q.Lang = export.LangFromAcceptLanguage(lang)
+	}
+
+	if dryRun := c.QueryParam("dry_run"); dryRun != "" {
+		v, err := strconv.ParseBool(dryRun)
+		if err != nil {
+			return nil, err
+		}
+		q.DryRun = v
+	}
+	return q, nil
+}
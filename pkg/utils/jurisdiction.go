@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"context"
+
+	"github.com/adohong4/driving-license/internal/models"
+	"github.com/adohong4/driving-license/pkg/httpErrors"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// Same rule as the scoped queries, see f_in_jurisdiction in migrations
+const inJurisdictionQuery = `SELECT f_in_jurisdiction($1, $2, $3)`
+
+// Agency whose jurisdiction bounds the records the caller reads and writes. Nil when unbounded: admins,
+// users not attached to an agency and calls without a user such as the background workers
+func JurisdictionFromCtx(ctx context.Context) *uuid.UUID {
+	user, ok := ctx.Value(UserCtxKey{}).(*models.User)
+	if !ok || user.AgencyID == nil || (user.Role != nil && *user.Role == "admin") {
+		return nil
+	}
+	return user.AgencyID
+}
+
+// Fails with httpErrors.OutOfJurisdiction when a record of the area is outside the jurisdiction of the caller,
+// district is nil for records only known by their province
+func CheckJurisdiction(ctx context.Context, q sqlx.QueryerContext, province string, district *string) error {
+	agency := JurisdictionFromCtx(ctx)
+	if agency == nil {
+		return nil
+	}
+
+	var in bool
+	if err := sqlx.GetContext(ctx, q, &in, inJurisdictionQuery, agency, province, district); err != nil {
+		return errors.Wrap(err, "CheckJurisdiction.GetContext")
+	}
+	if !in {
+		return httpErrors.OutOfJurisdiction
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package utils
 import (
 	"fmt"
 	"math"
+	"net/url"
 	"strconv"
 
 	"github.com/labstack/echo/v4"
@@ -12,11 +13,20 @@ const (
 	defaultSize = 10
 )
 
-// Pagination query params
+// Params read by the pagination query itself, every other param is kept as a filter
+var paginationParams = map[string]bool{"page": true, "size": true, "orderBy": true, "sort": true, "cursor": true, "with_total": true}
+
+// Pagination query params, Filters and Sort are validated per list by ListClause.
+// A cursor param, empty for the first page, switches to keyset pagination on lists that support it.
 type PaginationQuery struct {
-	Size    int    `json:"size,omitempty"`
-	Page    int    `json:"page,omitempty"`
-	OrderBy string `json:"orderBy,omitempty"`
+	Size       int               `json:"size,omitempty"`
+	Page       int               `json:"page,omitempty"`
+	OrderBy    string            `json:"orderBy,omitempty"`
+	Sort       string            `json:"sort,omitempty"`
+	Filters    map[string]string `json:"filters,omitempty"`
+	Cursor     string            `json:"cursor,omitempty"`
+	CursorMode bool              `json:"-"`
+	WithTotal  bool              `json:"-"` // cursor pages only count rows on request, the count is an estimate
 }
 
 // Set page size
@@ -81,6 +91,44 @@ func (q *PaginationQuery) GetSize() int {
 	return q.Size
 }
 
+// Set sort, orderBy is accepted as an alias
+func (q *PaginationQuery) SetSort(sortQuery string) {
+	q.Sort = sortQuery
+	if q.Sort == "" {
+		q.Sort = q.OrderBy
+	}
+}
+
+// Set filters from every non pagination param
+func (q *PaginationQuery) SetFilters(params url.Values) {
+	q.Filters = make(map[string]string, len(params))
+	for key, values := range params {
+		if paginationParams[key] || len(values) == 0 {
+			continue
+		}
+		q.Filters[key] = values[0]
+	}
+}
+
+// Set cursor, switches to keyset pagination
+func (q *PaginationQuery) SetCursor(cursorQuery string) {
+	q.Cursor = cursorQuery
+	q.CursorMode = true
+}
+
+// Set whether cursor pages return an approximate total
+func (q *PaginationQuery) SetWithTotal(withTotalQuery string) error {
+	if withTotalQuery == "" {
+		return nil
+	}
+	b, err := strconv.ParseBool(withTotalQuery)
+	if err != nil {
+		return err
+	}
+	q.WithTotal = b
+	return nil
+}
+
 // Get query string
 func (q *PaginationQuery) GetQueryString() string {
 	return fmt.Sprintf("page=%v&size=%v&orderBy=%s", q.GetPage(), q.GetSize(), q.GetOrderBy())
@@ -97,17 +145,31 @@ func GetPaginationFromCtx(c echo.Context) (*PaginationQuery, error) {
 		return nil, err
 	}
 	q.SetOrderBy(c.QueryParam("orderBy"))
+	q.SetSort(c.QueryParam("sort"))
+	q.SetFilters(c.QueryParams())
+	if params := c.QueryParams(); params.Has("cursor") {
+		q.SetCursor(params.Get("cursor"))
+	}
+	if err := q.SetWithTotal(c.QueryParam("with_total")); err != nil {
+		return nil, err
+	}
 
 	return q, nil
 }
 
 // Get total page int
 func GetTotalPage(totalCount int, pageSize int) int {
+	if pageSize <= 0 {
+		return 0
+	}
 	d := float64(totalCount) / float64(pageSize)
 	return int(math.Ceil(d))
 }
 
 // Get has more
 func GetHasMore(currentPage int, totalCount int, pageSize int) bool {
-	return currentPage < totalCount/pageSize
+	if currentPage < 1 {
+		currentPage = 1 // page 0 reads the first page
+	}
+	return currentPage < GetTotalPage(totalCount, pageSize)
 }
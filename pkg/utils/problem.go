@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/adohong4/driving-license/pkg/httpErrors"
+	"github.com/adohong4/driving-license/pkg/i18n"
+	"github.com/labstack/echo/v4"
+)
+
+const HeaderContentLanguage = "Content-Language"
+
+// JSON serializer of the API responses. Rest errors passed to c.JSON are written as RFC 7807 problem+json
+// with the request path and id, values implementing i18n.Localizer are localized by the Accept-Language header.
+type JSONSerializer struct {
+	echo.DefaultJSONSerializer
+}
+
+func (s JSONSerializer) Serialize(c echo.Context, i interface{}, indent string) error {
+	if restErr, ok := i.(httpErrors.RestErr); ok {
+		i = httpErrors.NewProblem(restErr)
+	}
+	if p, ok := i.(*httpErrors.Problem); ok {
+		p.Instance = c.Request().URL.Path
+		p.RequestID = GetRequestId(c)
+		c.Response().Header().Set(echo.HeaderContentType, httpErrors.ContentTypeProblem)
+	}
+	if l, ok := i.(i18n.Localizer); ok {
+		lang := GetLang(c)
+		l.Localize(lang)
+		c.Response().Header().Set(HeaderContentLanguage, lang)
+	}
+	return s.DefaultJSONSerializer.Serialize(c, i, indent)
+}
+
+// Echo error handler, unmatched routes, binding and middleware errors are answered as problems too
+func HTTPErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	var restErr httpErrors.RestErr
+	var he *echo.HTTPError
+	if errors.As(err, &he) {
+		restErr = httpErrors.NewRestError(he.Code, fmt.Sprint(he.Message), he.Internal)
+	} else {
+		restErr = httpErrors.ParseErrors(err)
+	}
+
+	if c.Request().Method == http.MethodHead {
+		_ = c.NoContent(restErr.Status())
+		return
+	}
+	_ = c.JSON(restErr.Status(), httpErrors.NewProblem(restErr))
+}
+
+// Response language of a request, from the Accept-Language header
+func GetLang(c echo.Context) string {
+	return i18n.Match(c.Request().Header.Get("Accept-Language"))
+}
@@ -0,0 +1,216 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/adohong4/driving-license/internal/models"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	GroupByDay   = "day"
+	GroupByWeek  = "week"
+	GroupByMonth = "month"
+
+	// Response header carrying the time statistic data was computed
+	HeaderStatsAsOf = "X-Stats-As-Of"
+
+	maxStatsBuckets = 400
+	dateLayout      = "2006-01-02"
+)
+
+// Stats query params shared by time-series statistic endpoints
+type StatsQuery struct {
+	From          time.Time  `json:"from"`
+	To            time.Time  `json:"to"` // exclusive
+	GroupBy       string     `json:"group_by"`
+	City          string     `json:"city,omitempty"`
+	AgencyID      *uuid.UUID `json:"agency_id,omitempty"`
+	LicenseType   string     `json:"license_type,omitempty"`
+	ViolationType string     `json:"violation_type,omitempty"`
+}
+
+// Get stats query struct from request query params
+func GetStatsQueryFromCtx(c echo.Context) (*StatsQuery, error) {
+	q := &StatsQuery{
+		GroupBy:       strings.ToLower(strings.TrimSpace(c.QueryParam("group_by"))),
+		City:          strings.TrimSpace(c.QueryParam("city")),
+		LicenseType:   strings.TrimSpace(c.QueryParam("license_type")),
+		ViolationType: strings.TrimSpace(c.QueryParam("violation_type")),
+	}
+
+	if agency := c.QueryParam("agency_id"); agency != "" {
+		id, err := uuid.Parse(agency)
+		if err != nil {
+			return nil, err
+		}
+		q.AgencyID = &id
+	}
+
+	from, err := ParseTimeParam(c.QueryParam("from"))
+	if err != nil {
+		return nil, err
+	}
+	to, err := ParseTimeParam(c.QueryParam("to"))
+	if err != nil {
+		return nil, err
+	}
+
+	if err = q.setRange(from, to); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// Apply defaults and check the time range
+func (q *StatsQuery) setRange(from, to *time.Time) error {
+	if q.GroupBy == "" {
+		q.GroupBy = GroupByMonth
+	}
+	if q.GroupBy != GroupByDay && q.GroupBy != GroupByWeek && q.GroupBy != GroupByMonth {
+		return errors.New("group_by must be one of day, week, month")
+	}
+
+	// Default to the end of the current period, the range and its cache key then stay the same for the whole period
+	q.To = q.nextPeriod(q.TruncPeriod(time.Now()))
+	if to != nil {
+		q.To = to.UTC()
+	}
+
+	switch {
+	case from != nil:
+		q.From = from.UTC()
+	case q.GroupBy == GroupByDay:
+		q.From = q.To.AddDate(0, 0, -30)
+	case q.GroupBy == GroupByWeek:
+		q.From = q.To.AddDate(0, 0, -7*12)
+	default:
+		q.From = q.To.AddDate(-1, 0, 0)
+	}
+
+	if !q.From.Before(q.To) {
+		return errors.New("from must be before to")
+	}
+	if len(q.Periods()) > maxStatsBuckets {
+		return errors.New("time range is too large for the requested grouping")
+	}
+	return nil
+}
+
+// Truncate time to the start of its period (weeks start on Monday, as in Postgres date_trunc)
+func (q *StatsQuery) TruncPeriod(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch q.GroupBy {
+	case GroupByDay:
+		return day
+	case GroupByWeek:
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	default:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// Next period start
+func (q *StatsQuery) nextPeriod(t time.Time) time.Time {
+	switch q.GroupBy {
+	case GroupByDay:
+		return t.AddDate(0, 0, 1)
+	case GroupByWeek:
+		return t.AddDate(0, 0, 7)
+	default:
+		return t.AddDate(0, 1, 0)
+	}
+}
+
+// All period starts covering [From, To)
+func (q *StatsQuery) Periods() []time.Time {
+	periods := make([]time.Time, 0)
+	for p := q.TruncPeriod(q.From); p.Before(q.To); p = q.nextPeriod(p) {
+		periods = append(periods, p)
+		if len(periods) > maxStatsBuckets {
+			break
+		}
+	}
+	return periods
+}
+
+// Chart label of a period
+func (q *StatsQuery) PeriodLabel(t time.Time) string {
+	if q.GroupBy == GroupByMonth {
+		return t.Format("2006-01")
+	}
+	return t.Format(dateLayout)
+}
+
+// Cache key identifying the query
+func (q *StatsQuery) CacheKey() string {
+	agency := ""
+	if q.AgencyID != nil {
+		agency = q.AgencyID.String()
+	}
+	return fmt.Sprintf("%s|%d|%d|%s|%s|%s|%s",
+		q.GroupBy, q.From.Unix(), q.To.Unix(), q.City, agency, q.LicenseType, q.ViolationType)
+}
+
+// Build chart series from grouped rows, missing periods are filled with zero
+func BuildTimeSeries(metric string, q *StatsQuery, points []*models.TimeSeriesPoint, withAmount bool) *models.TimeSeries {
+	byPeriod := make(map[time.Time]*models.TimeSeriesPoint, len(points))
+	for _, p := range points {
+		byPeriod[q.TruncPeriod(p.Period)] = p
+	}
+
+	periods := q.Periods()
+	ts := &models.TimeSeries{
+		Metric:  metric,
+		GroupBy: q.GroupBy,
+		From:    q.From,
+		To:      q.To,
+		Labels:  make([]string, 0, len(periods)),
+		Counts:  make([]int64, 0, len(periods)),
+	}
+	if withAmount {
+		ts.Amounts = make([]int64, 0, len(periods))
+	}
+
+	for _, period := range periods {
+		var count, amount int64
+		if p, ok := byPeriod[period]; ok {
+			count, amount = p.Count, p.Amount
+		}
+		ts.Labels = append(ts.Labels, q.PeriodLabel(period))
+		ts.Counts = append(ts.Counts, count)
+		ts.Total += count
+		if withAmount {
+			ts.Amounts = append(ts.Amounts, amount)
+			ts.TotalAmount += amount
+		}
+	}
+	return ts
+}
+
+// Parse RFC3339 timestamp or plain YYYY-MM-DD date, empty value returns nil
+func ParseTimeParam(v string) (*time.Time, error) {
+	if v == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		if t, err = time.Parse(dateLayout, v); err != nil {
+			return nil, err
+		}
+	}
+	return &t, nil
+}
+
+// Set staleness header of a statistic response
+func SetStatsAsOf(c echo.Context, asOf time.Time) {
+	if !asOf.IsZero() {
+		c.Response().Header().Set(HeaderStatsAsOf, asOf.UTC().Format(time.RFC3339))
+	}
+}
@@ -2,18 +2,212 @@ package utils
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
 
+	"github.com/adohong4/driving-license/pkg/httpErrors"
+	"github.com/adohong4/driving-license/pkg/i18n"
 	"github.com/go-playground/validator/v10"
 )
 
 // Use a single instance of Validate, it caches struct info
 var validate *validator.Validate
 
+const isoDateLayout = "2006-01-02"
+
+// Minimum age of the holder by license class, the classes of the 2008 road traffic law and of the 2024 law
+var drivingAges = map[string]int{
+	"A1": 18, "A2": 18, "A3": 18, "A4": 18, "A": 18, "B1": 18, "B2": 18, "B": 18, "C1": 18,
+	"C": 21, "BE": 21, "FB2": 21,
+	"D1": 24, "D2": 24, "C1E": 24, "CE": 24, "D": 24, "FC": 24,
+	"D1E": 27, "D2E": 27, "DE": 27, "E": 27, "FD": 27, "FE": 27,
+}
+
+// CCCD province codes, the first three digits
+var cccdProvinces = map[string]bool{
+	"001": true, "002": true, "004": true, "006": true, "008": true, "010": true, "011": true, "012": true,
+	"014": true, "015": true, "017": true, "019": true, "020": true, "022": true, "024": true, "025": true,
+	"026": true, "027": true, "030": true, "031": true, "033": true, "034": true, "035": true, "036": true,
+	"037": true, "038": true, "040": true, "042": true, "044": true, "045": true, "046": true, "048": true,
+	"049": true, "051": true, "052": true, "054": true, "056": true, "058": true, "060": true, "062": true,
+	"064": true, "066": true, "067": true, "068": true, "070": true, "072": true, "074": true, "075": true,
+	"077": true, "079": true, "080": true, "082": true, "083": true, "084": true, "086": true, "087": true,
+	"089": true, "091": true, "092": true, "093": true, "094": true, "095": true, "096": true,
+}
+
+var genders = map[string]string{"male": "male", "nam": "male", "female": "female", "nữ": "female", "nu": "female"}
+
 func init() {
 	validate = validator.New()
+
+	// report fields by their json name
+	validate.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name := strings.SplitN(f.Tag.Get("json"), ",", 2)[0]
+		if name == "-" || name == "" {
+			return f.Name
+		}
+		return name
+	})
+
+	_ = validate.RegisterValidation("isodate", validateISODate)
+	_ = validate.RegisterValidation("birthdate", validateBirthDate)
+	_ = validate.RegisterValidation("cccd", validateCCCD)
+	_ = validate.RegisterValidation("cccd_birth", validateCCCDBirth)
+	_ = validate.RegisterValidation("cccd_gender", validateCCCDGender)
+	_ = validate.RegisterValidation("gender", validateGender)
+	_ = validate.RegisterValidation("nationality", validateNationality)
+	_ = validate.RegisterValidation("license_type", validateLicenseType)
+	_ = validate.RegisterValidation("driving_age", validateDrivingAge)
 }
 
-// validate struct fields
+// validate struct fields, failures come back as *httpErrors.ValidationError with a message per field
 func ValidateStruct(ctx context.Context, s interface{}) error {
-	return validate.StructCtx(ctx, s)
+	err := validate.StructCtx(ctx, s)
+	var ve validator.ValidationErrors
+	if !errors.As(err, &ve) {
+		return err
+	}
+
+	fields := make([]httpErrors.FieldError, 0, len(ve))
+	for _, fe := range ve {
+		params := ruleParams(fe)
+		fields = append(fields, httpErrors.FieldError{
+			Field:   fe.Field(),
+			Rule:    fe.Tag(),
+			Message: httpErrors.FieldMessage(i18n.Default, fe.Tag(), params),
+			Params:  params,
+		})
+	}
+	return httpErrors.NewValidationError(fields)
+}
+
+// Minimum age of the holder of a license class, false on an unknown class
+func DrivingAge(licenseType string) (int, bool) {
+	age, ok := drivingAges[strings.ToUpper(strings.TrimSpace(licenseType))]
+	return age, ok
+}
+
+// Placeholders of the validation messages of the i18n catalogs
+func ruleParams(fe validator.FieldError) map[string]string {
+	params := map[string]string{"param": fe.Param()}
+	if fe.Tag() == "driving_age" {
+		licenseType := fmt.Sprint(fe.Value())
+		age, _ := DrivingAge(licenseType)
+		params["age"] = strconv.Itoa(age)
+		params["license_type"] = licenseType
+	}
+	return params
+}
+
+func parseISODate(s string) (time.Time, bool) {
+	t, err := time.Parse(isoDateLayout, strings.TrimSpace(s))
+	return t, err == nil
+}
+
+// Value of a sibling string field named by the tag param, "" when missing
+func siblingString(fl validator.FieldLevel, name string) string {
+	field, kind, _, ok := fl.GetStructFieldOKAdvanced2(fl.Parent(), name)
+	if !ok || kind != reflect.String {
+		return ""
+	}
+	return strings.TrimSpace(field.String())
+}
+
+func validateISODate(fl validator.FieldLevel) bool {
+	_, ok := parseISODate(fl.Field().String())
+	return ok
+}
+
+func validateBirthDate(fl validator.FieldLevel) bool {
+	t, ok := parseISODate(fl.Field().String())
+	return ok && t.Year() >= 1900 && !t.After(time.Now())
+}
+
+// 12 digits, the province code, the century-gender digit and the last two digits of the birth year
+func validateCCCD(fl validator.FieldLevel) bool {
+	s := fl.Field().String()
+	if len(s) != 12 {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return cccdProvinces[s[:3]]
+}
+
+// Birth year of the CCCD against the date of birth field named by the param, e.g. cccd_birth=DateOfBirth.
+// Either value missing or malformed is left to the cccd and birthdate rules.
+func validateCCCDBirth(fl validator.FieldLevel) bool {
+	s := fl.Field().String()
+	dob, ok := parseISODate(siblingString(fl, fl.Param()))
+	if len(s) != 12 || !ok {
+		return true
+	}
+	// century-gender digit, 0/1 born in the 1900s, 2/3 in the 2000s, and so on every century
+	century := 1900 + int(s[3]-'0')/2*100
+	year := century + int(s[4]-'0')*10 + int(s[5]-'0')
+	return year == dob.Year()
+}
+
+// Gender digit of the CCCD against the gender field named by the param, even for male and odd for female
+func validateCCCDGender(fl validator.FieldLevel) bool {
+	s := fl.Field().String()
+	gender, ok := genders[strings.ToLower(siblingString(fl, fl.Param()))]
+	if len(s) != 12 || !ok || s[3] < '0' || s[3] > '9' {
+		return true
+	}
+	female := (s[3]-'0')%2 == 1
+	return female == (gender == "female")
+}
+
+func validateGender(fl validator.FieldLevel) bool {
+	_, ok := genders[strings.ToLower(strings.TrimSpace(fl.Field().String()))]
+	return ok
+}
+
+// Country names such as "Việt Nam" or "Hàn Quốc"
+func validateNationality(fl validator.FieldLevel) bool {
+	s := strings.TrimSpace(fl.Field().String())
+	if n := len([]rune(s)); n < 2 || n > 50 {
+		return false
+	}
+	for _, r := range s {
+		if !unicode.IsLetter(r) && r != ' ' && r != '-' && r != '\'' {
+			return false
+		}
+	}
+	return true
+}
+
+func validateLicenseType(fl validator.FieldLevel) bool {
+	_, ok := DrivingAge(fl.Field().String())
+	return ok
+}
+
+// Age of the holder against the license class, the param names the date of birth field and optionally
+// the issue date field the age is reached by, e.g. driving_age=DOB IssueDate. Without an issue date today is used.
+func validateDrivingAge(fl validator.FieldLevel) bool {
+	minAge, ok := DrivingAge(fl.Field().String())
+	params := strings.Fields(fl.Param())
+	if !ok || len(params) == 0 {
+		return true
+	}
+	dob, ok := parseISODate(siblingString(fl, params[0]))
+	if !ok {
+		return true
+	}
+	at := time.Now()
+	if len(params) > 1 {
+		if issued, ok := parseISODate(siblingString(fl, params[1])); ok {
+			at = issued
+		}
+	}
+	return !dob.AddDate(minAge, 0, 0).After(at)
 }